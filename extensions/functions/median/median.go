@@ -0,0 +1,78 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+)
+
+// medianFunc calculates the median of a numeric column in a group by the aggregate state contract.
+// The state is the slice of the non-nil values of the group.
+type medianFunc struct{}
+
+func (f *medianFunc) Validate(args []any) error {
+	if len(args) != 1 {
+		return fmt.Errorf("median function only supports 1 parameter but got %d", len(args))
+	}
+	return nil
+}
+
+func (f *medianFunc) Exec(ctx api.FunctionContext, args []any) (any, bool) {
+	return modules.ExecAggregate(ctx, f, args)
+}
+
+func (f *medianFunc) IsAggregate() bool {
+	return true
+}
+
+func (f *medianFunc) Init(_ api.FunctionContext) (any, error) {
+	return make([]float64, 0), nil
+}
+
+func (f *medianFunc) Accumulate(_ api.FunctionContext, state any, args []any) (any, error) {
+	if args[0] == nil {
+		return state, nil
+	}
+	v, err := cast.ToFloat64(args[0], cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, fmt.Errorf("median function requires numeric argument: %v", err)
+	}
+	return append(state.([]float64), v), nil
+}
+
+func (f *medianFunc) Merge(_ api.FunctionContext, state1 any, state2 any) (any, error) {
+	return append(state1.([]float64), state2.([]float64)...), nil
+}
+
+func (f *medianFunc) Finalize(_ api.FunctionContext, state any) (any, error) {
+	values := state.([]float64)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2, nil
+	}
+	return values[mid], nil
+}
+
+var Median medianFunc
@@ -0,0 +1,77 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// clientConf is the connection config shared by the source and the sink
+type clientConf struct {
+	URL string `json:"url"`
+	// ReconnectInterval is the interval to reconnect after the connection or the channel is closed
+	ReconnectInterval cast.DurationConf `json:"reconnectInterval"`
+}
+
+func (c *clientConf) validate() error {
+	if c.URL == "" {
+		return errors.New("missing url")
+	}
+	if _, err := amqp.ParseURI(c.URL); err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+	if c.ReconnectInterval <= 0 {
+		return errors.New("reconnectInterval must be positive")
+	}
+	return nil
+}
+
+// session is a channel with its own connection. The channel and the connection are closed together.
+type session struct {
+	*amqp.Channel
+	conn *amqp.Connection
+}
+
+// newSession dials the broker and opens a channel. The amqp client does not recover the connection, so a new
+// session is created by the connectors after the broker restarts.
+func (c *clientConf) newSession() (*session, error) {
+	conn, err := amqp.Dial(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &session{Channel: ch, conn: conn}, nil
+}
+
+func (s *session) Close() error {
+	_ = s.Channel.Close()
+	return s.conn.Close()
+}
+
+func defaultClientConf() clientConf {
+	return clientConf{
+		ReconnectInterval: cast.DurationConf(5 * time.Second),
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+// publisher is the subset of the amqp channel used by the sink
+type publisher interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	IsClosed() bool
+	Close() error
+}
+
+type sinkConf struct {
+	Exchange string `json:"exchange"`
+	// RoutingKey could be a data template
+	RoutingKey  string `json:"routingKey"`
+	Persistent  bool   `json:"persistent"`
+	ContentType string `json:"contentType"`
+}
+
+type amqpSink struct {
+	cc  clientConf
+	sc  *sinkConf
+	sch api.StatusChangeHandler
+	pub publisher
+	// dial creates the publisher, it is replaced in test
+	dial func() (publisher, error)
+}
+
+func (s *amqpSink) Provision(_ api.StreamContext, configs map[string]any) error {
+	cc := defaultClientConf()
+	if err := cast.MapToStruct(configs, &cc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if err := cc.validate(); err != nil {
+		return err
+	}
+	sc := &sinkConf{}
+	if err := cast.MapToStruct(configs, sc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if sc.Exchange == "" && sc.RoutingKey == "" {
+		return errors.New("missing routingKey, it is required to publish to the default exchange")
+	}
+	s.cc = cc
+	s.sc = sc
+	s.dial = func() (publisher, error) {
+		ses, err := s.cc.newSession()
+		if err != nil {
+			return nil, err
+		}
+		return ses, nil
+	}
+	return nil
+}
+
+func (s *amqpSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	s.sch = sch
+	return s.reconnect(ctx)
+}
+
+// reconnect replaces the closed publisher with a new one
+func (s *amqpSink) reconnect(ctx api.StreamContext) error {
+	if s.pub != nil {
+		_ = s.pub.Close()
+		s.pub = nil
+	}
+	pub, err := s.dial()
+	if err != nil {
+		s.sch(api.ConnectionDisconnected, err.Error())
+		return fmt.Errorf("amqp sink fails to connect: %v", err)
+	}
+	s.pub = pub
+	s.sch(api.ConnectionConnected, "")
+	ctx.GetLogger().Infof("amqp sink connected to exchange %s", s.sc.Exchange)
+	return nil
+}
+
+// Collect publishes the message. If the channel is closed such as the broker restarts, it reconnects before publishing.
+// The failed results are reported as the connection errors, so they can be retried and cached.
+func (s *amqpSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	if s.pub == nil || s.pub.IsClosed() {
+		ctx.GetLogger().Warnf("amqp sink channel is closed, reconnecting")
+		if err := s.reconnect(ctx); err != nil {
+			return errorx.NewConnectionError(err)
+		}
+	}
+	key, msg := s.buildMsg(item)
+	if err := s.pub.PublishWithContext(ctx, s.sc.Exchange, key, false, false, msg); err != nil {
+		ctx.GetLogger().Errorf("publish to amqp error %v", err)
+		return errorx.NewConnectionError(err)
+	}
+	return nil
+}
+
+// buildMsg renders the routing key by the data if it is a template
+func (s *amqpSink) buildMsg(item api.RawTuple) (string, amqp.Publishing) {
+	key := s.sc.RoutingKey
+	if dp, ok := item.(api.HasDynamicProps); ok && key != "" {
+		if v, ok := dp.DynamicProps(key); ok {
+			key = v
+		}
+	}
+	msg := amqp.Publishing{
+		ContentType:  s.sc.ContentType,
+		DeliveryMode: amqp.Transient,
+		Body:         item.Raw(),
+	}
+	if s.sc.Persistent {
+		msg.DeliveryMode = amqp.Persistent
+	}
+	return key, msg
+}
+
+func (s *amqpSink) Close(ctx api.StreamContext) error {
+	if s.pub != nil {
+		_ = s.pub.Close()
+	}
+	ctx.GetLogger().Infof("amqp sink closed")
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &amqpSink{}
+}
+
+var _ api.BytesCollector = &amqpSink{}
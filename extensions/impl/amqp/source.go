@@ -0,0 +1,288 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amqp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// consumer is the subset of the amqp channel used by the source
+type consumer interface {
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Close() error
+}
+
+type sourceConf struct {
+	Queue string `json:"datasource"`
+	// Prefetch is the max number of the unacknowledged messages delivered to the source, 0 means unlimited
+	Prefetch int `json:"prefetch"`
+}
+
+type amqpSource struct {
+	cc  clientConf
+	sc  *sourceConf
+	sch api.StatusChangeHandler
+	// dial creates the consumer with the prefetch set, it is replaced in test
+	dial func() (consumer, error)
+	// checkpointAck defers the acknowledgement until the checkpoint completes
+	checkpointAck bool
+
+	mu sync.Mutex
+	ch consumer
+	// session increases when the channel is replaced, the delivery tags are only valid in the channel delivering them
+	session int64
+	// acker and lastTag are of the last ingested delivery in the session, ackedTag is the last acknowledged one
+	acker    amqp.Acknowledger
+	lastTag  uint64
+	ackedTag uint64
+}
+
+func (s *amqpSource) Provision(_ api.StreamContext, configs map[string]any) error {
+	cc := defaultClientConf()
+	if err := cast.MapToStruct(configs, &cc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if err := cc.validate(); err != nil {
+		return err
+	}
+	sc := &sourceConf{Prefetch: 10}
+	if err := cast.MapToStruct(configs, sc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if sc.Queue == "" {
+		return errors.New("missing queue, set it in the datasource of the stream")
+	}
+	if sc.Prefetch < 0 {
+		return errors.New("prefetch must not be negative")
+	}
+	s.cc = cc
+	s.sc = sc
+	s.dial = func() (consumer, error) {
+		ses, err := s.cc.newSession()
+		if err != nil {
+			return nil, err
+		}
+		if s.sc.Prefetch > 0 {
+			if err := ses.Qos(s.sc.Prefetch, 0, false); err != nil {
+				_ = ses.Close()
+				return nil, err
+			}
+		}
+		return ses, nil
+	}
+	return nil
+}
+
+func (s *amqpSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	s.sch = sch
+	ch, err := s.dial()
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return fmt.Errorf("amqp source fails to connect: %v", err)
+	}
+	s.setChannel(ch)
+	sch(api.ConnectionConnected, "")
+	ctx.GetLogger().Infof("amqp source connected to consume queue %s", s.sc.Queue)
+	return nil
+}
+
+// Subscribe consumes the queue until the rule stops. The deliveries are closed once the connection or the channel is
+// closed such as the broker restarts, then the source reconnects and consumes again.
+func (s *amqpSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	for {
+		deliveries, err := s.getChannel().Consume(s.sc.Queue, "", false, false, false, false, nil)
+		if err != nil {
+			ingestError(ctx, fmt.Errorf("amqp source consume %s error: %v", s.sc.Queue, err))
+		} else {
+			s.consume(ctx, deliveries, ingest)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.sch(api.ConnectionDisconnected, "amqp channel is closed")
+		if !s.recover(ctx) {
+			return nil
+		}
+	}
+}
+
+// consume ingests the deliveries until they are closed. If checkpoint is enabled, the messages are acknowledged when the
+// checkpoint covering them completes. Otherwise, each message is acknowledged after it is ingested. The unacknowledged
+// messages are redelivered by the broker if the source stops or the connection is lost.
+func (s *amqpSource) consume(ctx api.StreamContext, deliveries <-chan amqp.Delivery, ingest api.BytesIngest) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d, ok := <-deliveries:
+			if !ok {
+				return
+			}
+			ingest(ctx, d.Body, msgMeta(d), timex.GetNow())
+			if s.checkpointAck {
+				// Record the tag after the message is sent out so that a checkpoint never covers the message not sent yet
+				s.mu.Lock()
+				s.acker = d.Acknowledger
+				s.lastTag = d.DeliveryTag
+				s.mu.Unlock()
+				continue
+			}
+			if err := d.Ack(false); err != nil {
+				ctx.GetLogger().Warnf("amqp source fails to ack message %d: %v", d.DeliveryTag, err)
+			}
+		}
+	}
+}
+
+// recover reconnects by the interval until it succeeds or the rule stops
+func (s *amqpSource) recover(ctx api.StreamContext) bool {
+	if ch := s.getChannel(); ch != nil {
+		_ = ch.Close()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Duration(s.cc.ReconnectInterval)):
+		}
+		ch, err := s.dial()
+		if err != nil {
+			ctx.GetLogger().Warnf("amqp source fails to reconnect: %v", err)
+			s.sch(api.ConnectionDisconnected, err.Error())
+			continue
+		}
+		s.setChannel(ch)
+		s.sch(api.ConnectionConnected, "")
+		ctx.GetLogger().Infof("amqp source reconnected to consume queue %s", s.sc.Queue)
+		return true
+	}
+}
+
+// setChannel replaces the channel and starts a new session of delivery tags
+func (s *amqpSource) setChannel(ch consumer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ch = ch
+	s.session++
+	s.acker = nil
+	s.lastTag = 0
+	s.ackedTag = 0
+}
+
+func (s *amqpSource) getChannel() consumer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ch
+}
+
+// Rewind does nothing. The messages not acknowledged by the completed checkpoints are redelivered by the broker.
+func (s *amqpSource) Rewind(offset any) error {
+	return nil
+}
+
+func (s *amqpSource) ResetOffset(input map[string]any) error {
+	return errors.New("amqp source not support reset offset")
+}
+
+// GetOffset returns the last ingested delivery tag of the channel, {"session": session, "tag": tag}
+func (s *amqpSource) GetOffset() (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]any{"session": s.session, "tag": s.lastTag}, nil
+}
+
+func (s *amqpSource) EnableCheckpointCommit() {
+	s.checkpointAck = true
+}
+
+// CommitOffset acknowledges the messages up to the delivery tag of a completed checkpoint. The tags of a closed channel
+// are skipped since the broker redelivers those messages anyway.
+func (s *amqpSource) CommitOffset(ctx api.StreamContext, offset any) error {
+	m, ok := offset.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%v can't be set as amqp offset", offset)
+	}
+	session, err := cast.ToInt64(m["session"], cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return fmt.Errorf("invalid session in amqp offset %v", offset)
+	}
+	tag, err := cast.ToUint64(m["tag"], cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return fmt.Errorf("invalid tag in amqp offset %v", offset)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session != s.session || s.acker == nil || tag <= s.ackedTag {
+		return nil
+	}
+	if err := s.acker.Ack(tag, true); err != nil {
+		return fmt.Errorf("amqp source fails to ack messages up to %d: %v", tag, err)
+	}
+	s.ackedTag = tag
+	return nil
+}
+
+func msgMeta(d amqp.Delivery) map[string]any {
+	meta := map[string]any{
+		"exchange":    d.Exchange,
+		"routingKey":  d.RoutingKey,
+		"deliveryTag": d.DeliveryTag,
+		"redelivered": d.Redelivered,
+	}
+	if d.MessageId != "" {
+		meta["messageId"] = d.MessageId
+	}
+	if d.ContentType != "" {
+		meta["contentType"] = d.ContentType
+	}
+	if !d.Timestamp.IsZero() {
+		meta["timestamp"] = d.Timestamp.UnixMilli()
+	}
+	if len(d.Headers) > 0 {
+		h := make(map[string]any, len(d.Headers))
+		for k, v := range d.Headers {
+			h[k] = v
+		}
+		meta["headers"] = h
+	}
+	return meta
+}
+
+func (s *amqpSource) Close(ctx api.StreamContext) error {
+	if ch := s.getChannel(); ch != nil {
+		_ = ch.Close()
+	}
+	ctx.GetLogger().Infof("amqp source closed")
+	return nil
+}
+
+func GetSource() api.Source {
+	return &amqpSource{}
+}
+
+var (
+	_ api.BytesSource       = &amqpSource{}
+	_ model.OffsetCommitter = &amqpSource{}
+)
@@ -0,0 +1,234 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"                //nolint:staticcheck
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic" //nolint:staticcheck
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/lf-edge/ekuiper/v2/internal/converter/protobuf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// c is the configuration for grpc sink
+type c struct {
+	// The address of the grpc server in the format of host:port
+	Addr string `json:"addr"`
+	// The name of the protobuf schema in the schema registry which defines the service
+	SchemaId string `json:"schemaId"`
+	// The service name. It can be omitted if there is only one service in the schema
+	Service string `json:"service"`
+	// The unary method to invoke
+	Method string `json:"method"`
+	// The deadline of each call
+	Timeout cast.DurationConf `json:"timeout"`
+	// The metadata sent with each call
+	Metadata map[string]string `json:"metadata"`
+}
+
+// sink invokes the unary rpc for each tuple. The tuple is encoded as the request message by the field names.
+// The connection is created once and reused by all the calls.
+type sink struct {
+	conf   *c
+	method *desc.MethodDescriptor
+	creds  credentials.TransportCredentials
+	md     metadata.MD
+	conn   *ggrpc.ClientConn
+	stub   grpcdynamic.Stub
+}
+
+func (s *sink) Provision(_ api.StreamContext, props map[string]any) error {
+	conf := &c{
+		Timeout: cast.DurationConf(defaultTimeout),
+	}
+	err := cast.MapToStruct(props, conf)
+	if err != nil {
+		return fmt.Errorf("error configuring grpc sink: %s", err)
+	}
+	if conf.Addr == "" {
+		return errors.New("addr is required")
+	}
+	if conf.SchemaId == "" {
+		return errors.New("schemaId is required")
+	}
+	if conf.Method == "" {
+		return errors.New("method is required")
+	}
+	if conf.Timeout < 0 {
+		return fmt.Errorf("timeout must be greater than or equal to 0")
+	}
+	ffs, err := schema.GetSchemaFile(def.PROTOBUF, conf.SchemaId)
+	if err != nil {
+		return err
+	}
+	if ffs.SchemaFile == "" {
+		return fmt.Errorf("schema %s does not have the proto file", conf.SchemaId)
+	}
+	fd, err := protobuf.ParseSchemaFile(ffs.SchemaFile)
+	if err != nil {
+		return err
+	}
+	s.method, err = findMethod(fd, conf.Service, conf.Method)
+	if err != nil {
+		return err
+	}
+	tlsConf, err := cert.GenTLSConfig(props, "grpc-sink")
+	if err != nil {
+		return fmt.Errorf("error configuring tls: %s", err)
+	}
+	if tlsConf != nil {
+		s.creds = credentials.NewTLS(tlsConf)
+	} else {
+		s.creds = insecure.NewCredentials()
+	}
+	if len(conf.Metadata) > 0 {
+		s.md = metadata.New(conf.Metadata)
+	}
+	s.conf = conf
+	return nil
+}
+
+// findMethod finds the unary method in the service. If the service is not specified, the schema must have only one service.
+func findMethod(fd *desc.FileDescriptor, service string, method string) (*desc.MethodDescriptor, error) {
+	var sd *desc.ServiceDescriptor
+	if service == "" {
+		services := fd.GetServices()
+		if len(services) != 1 {
+			return nil, fmt.Errorf("service is required when the schema has %d services", len(services))
+		}
+		sd = services[0]
+	} else {
+		for _, s := range fd.GetServices() {
+			if s.GetName() == service || s.GetFullyQualifiedName() == service {
+				sd = s
+				break
+			}
+		}
+		if sd == nil {
+			return nil, fmt.Errorf("service %s not found", service)
+		}
+	}
+	md := sd.FindMethodByName(method)
+	if md == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", method, sd.GetName())
+	}
+	if md.IsClientStreaming() || md.IsServerStreaming() {
+		return nil, fmt.Errorf("method %s must be unary", method)
+	}
+	return md, nil
+}
+
+func (s *sink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	conn, err := ggrpc.NewClient(s.conf.Addr, ggrpc.WithTransportCredentials(s.creds))
+	if err != nil {
+		return fmt.Errorf("error creating grpc client for %s: %v", s.conf.Addr, err)
+	}
+	// The connection is established in background and reconnected automatically by grpc
+	conn.Connect()
+	s.conn = conn
+	s.stub = grpcdynamic.NewStub(conn)
+	ctx.GetLogger().Infof("grpc sink connected to %s", s.conf.Addr)
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *sink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	return s.invoke(ctx, item.ToMap())
+}
+
+// CollectList invokes the rpc for each tuple. It returns immediately when a retryable error happens so that the
+// whole list is retried. The permanent errors of the tuples are returned together.
+func (s *sink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	var errs []error
+	items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+		err := s.invoke(ctx, tuple.ToMap())
+		if err != nil {
+			errs = append(errs, err)
+			return !errorx.IsIOError(err)
+		}
+		return true
+	})
+	if len(errs) > 0 && errorx.IsIOError(errs[len(errs)-1]) {
+		return errs[len(errs)-1]
+	}
+	return errors.Join(errs...)
+}
+
+func (s *sink) invoke(ctx api.StreamContext, data map[string]any) error {
+	msg, err := protobuf.GetFieldConverter().EncodeMap(s.method.GetInputType(), data)
+	if err != nil {
+		return fmt.Errorf("grpc sink fails to encode the data: %v", err)
+	}
+	var callCtx context.Context = ctx
+	if s.conf.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, time.Duration(s.conf.Timeout))
+		defer cancel()
+	}
+	if s.md != nil {
+		callCtx = metadata.NewOutgoingContext(callCtx, s.md)
+	}
+	resp, err := s.stub.InvokeRpc(callCtx, s.method, msg)
+	if err != nil {
+		return classifyErr(s.method.GetName(), err)
+	}
+	ctx.GetLogger().Debugf("grpc sink invoked %s with response %v", s.method.GetName(), resp)
+	return nil
+}
+
+// classifyErr maps the grpc status to the retry decision. The temporary failures are IOError so that they are
+// retried by the cache. The others like INVALID_ARGUMENT will fail again, so they are returned as normal errors.
+func classifyErr(method string, err error) error {
+	st, _ := status.FromError(err)
+	msg := fmt.Sprintf("grpc sink fails to invoke %s: code=%s message=%s", method, st.Code(), st.Message())
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return errorx.NewIOErr(msg)
+	default:
+		return errors.New(msg)
+	}
+}
+
+func (s *sink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("grpc sink close")
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &sink{}
+}
+
+var _ api.TupleCollector = &sink{}
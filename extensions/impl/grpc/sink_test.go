@@ -0,0 +1,258 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/jhump/protoreflect/desc"    //nolint:staticcheck
+	"github.com/jhump/protoreflect/dynamic" //nolint:staticcheck
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/schema"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+const testProto = `syntax = "proto3";
+package test;
+
+service Alarm {
+  rpc Report(AlarmRequest) returns (AlarmReply) {}
+  rpc Watch(AlarmRequest) returns (stream AlarmReply) {}
+}
+
+service Other {
+  rpc Report(AlarmRequest) returns (AlarmReply) {}
+}
+
+message AlarmRequest {
+  string device = 1;
+  double temperature = 2;
+}
+
+message AlarmReply {
+  string message = 1;
+}
+`
+
+func setupSchema(t *testing.T) {
+	dataDir, err := conf.GetDataLoc()
+	require.NoError(t, err)
+	require.NoError(t, store.SetupDefault(dataDir))
+	require.NoError(t, schema.InitRegistry())
+	require.NoError(t, schema.CreateOrUpdateSchema(&schema.Info{Type: def.PROTOBUF, Name: "grpcAlarm", Content: testProto}))
+}
+
+func TestProvision(t *testing.T) {
+	setupSchema(t)
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "missing addr",
+			props: map[string]any{"schemaId": "grpcAlarm", "method": "Report"},
+			err:   "addr is required",
+		},
+		{
+			name:  "missing schema",
+			props: map[string]any{"addr": "localhost:50051", "method": "Report"},
+			err:   "schemaId is required",
+		},
+		{
+			name:  "missing method",
+			props: map[string]any{"addr": "localhost:50051", "schemaId": "grpcAlarm"},
+			err:   "method is required",
+		},
+		{
+			name:  "schema not found",
+			props: map[string]any{"addr": "localhost:50051", "schemaId": "notExist", "service": "Alarm", "method": "Report"},
+			err:   "schema type protobuf, file notExist not found",
+		},
+		{
+			name:  "ambiguous service",
+			props: map[string]any{"addr": "localhost:50051", "schemaId": "grpcAlarm", "method": "Report"},
+			err:   "service is required when the schema has 2 services",
+		},
+		{
+			name:  "service not found",
+			props: map[string]any{"addr": "localhost:50051", "schemaId": "grpcAlarm", "service": "Notify", "method": "Report"},
+			err:   "service Notify not found",
+		},
+		{
+			name:  "method not found",
+			props: map[string]any{"addr": "localhost:50051", "schemaId": "grpcAlarm", "service": "Alarm", "method": "Send"},
+			err:   "method Send not found in service Alarm",
+		},
+		{
+			name:  "streaming method",
+			props: map[string]any{"addr": "localhost:50051", "schemaId": "grpcAlarm", "service": "Alarm", "method": "Watch"},
+			err:   "method Watch must be unary",
+		},
+		{
+			name:  "valid",
+			props: map[string]any{"addr": "localhost:50051", "schemaId": "grpcAlarm", "service": "test.Alarm", "method": "Report", "timeout": "1s"},
+		},
+	}
+	ctx := mockContext.NewMockContext("testProvision", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GetSink()
+			err := s.Provision(ctx, tt.props)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+// startServer starts a grpc server of the Alarm service which handles the dynamic messages
+func startServer(t *testing.T, md *desc.MethodDescriptor, handler func(ctx context.Context, req *dynamic.Message) (*dynamic.Message, error)) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := ggrpc.NewServer()
+	server.RegisterService(&ggrpc.ServiceDesc{
+		ServiceName: md.GetService().GetFullyQualifiedName(),
+		HandlerType: (*any)(nil),
+		Methods: []ggrpc.MethodDesc{
+			{
+				MethodName: md.GetName(),
+				Handler: func(_ any, ctx context.Context, dec func(any) error, _ ggrpc.UnaryServerInterceptor) (any, error) {
+					req := dynamic.NewMessage(md.GetInputType())
+					if err := dec(req); err != nil {
+						return nil, err
+					}
+					return handler(ctx, req)
+				},
+			},
+		},
+	}, struct{}{})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestCollect(t *testing.T) {
+	setupSchema(t)
+	var (
+		lock     sync.Mutex
+		received []map[string]any
+		code     = codes.OK
+	)
+	ctx := mockContext.NewMockContext("testCollect", "op")
+	s := GetSink().(*sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":     "127.0.0.1:0",
+		"schemaId": "grpcAlarm",
+		"service":  "Alarm",
+		"method":   "Report",
+		"timeout":  "1s",
+		"metadata": map[string]any{"token": "abc"},
+	}))
+	md := s.method
+	s.conf.Addr = startServer(t, md, func(ctx context.Context, req *dynamic.Message) (*dynamic.Message, error) {
+		meta, _ := metadata.FromIncomingContext(ctx)
+		assert.Equal(t, []string{"abc"}, meta.Get("token"))
+		if code != codes.OK {
+			return nil, status.Error(code, "mock error")
+		}
+		lock.Lock()
+		received = append(received, map[string]any{
+			"device":      req.GetFieldByName("device"),
+			"temperature": req.GetFieldByName("temperature"),
+		})
+		lock.Unlock()
+		reply := dynamic.NewMessage(md.GetOutputType())
+		reply.SetFieldByName("message", "ok")
+		return reply, nil
+	})
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+
+	t.Run("single", func(t *testing.T) {
+		received = nil
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"device": "d1", "temperature": 20.5}})
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{{"device": "d1", "temperature": 20.5}}, received)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		received = nil
+		tuples := &xsql.WindowTuples{
+			Content: []xsql.Row{
+				&xsql.Tuple{Message: map[string]any{"device": "d1", "temperature": 21.0}},
+				&xsql.Tuple{Message: map[string]any{"device": "d2", "temperature": "hot"}},
+				&xsql.Tuple{Message: map[string]any{"device": "d3", "temperature": 30.0}},
+			},
+		}
+		err := s.CollectList(ctx, tuples)
+		require.Error(t, err)
+		assert.False(t, errorx.IsIOError(err))
+		assert.Contains(t, err.Error(), "grpc sink fails to encode the data")
+		assert.Equal(t, []map[string]any{{"device": "d1", "temperature": 21.0}, {"device": "d3", "temperature": 30.0}}, received)
+	})
+
+	t.Run("retryable error", func(t *testing.T) {
+		code = codes.Unavailable
+		defer func() { code = codes.OK }()
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"device": "d1", "temperature": 1.0}})
+		require.Error(t, err)
+		assert.True(t, errorx.IsIOError(err))
+		assert.EqualError(t, err, "grpc sink fails to invoke Report: code=Unavailable message=mock error")
+	})
+
+	t.Run("retryable error in batch", func(t *testing.T) {
+		code = codes.DeadlineExceeded
+		defer func() { code = codes.OK }()
+		tuples := &xsql.WindowTuples{
+			Content: []xsql.Row{
+				&xsql.Tuple{Message: map[string]any{"device": "d1", "temperature": 1.0}},
+				&xsql.Tuple{Message: map[string]any{"device": "d2", "temperature": 2.0}},
+			},
+		}
+		err := s.CollectList(ctx, tuples)
+		require.Error(t, err)
+		assert.True(t, errorx.IsIOError(err))
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		code = codes.InvalidArgument
+		defer func() { code = codes.OK }()
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"device": "d1", "temperature": 1.0}})
+		require.Error(t, err)
+		assert.False(t, errorx.IsIOError(err))
+		assert.EqualError(t, err, "grpc sink fails to invoke Report: code=InvalidArgument message=mock error")
+	})
+}
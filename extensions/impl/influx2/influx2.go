@@ -17,11 +17,16 @@ package influx2
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	client "github.com/influxdata/influxdb-client-go/v2"
+	ihttp "github.com/influxdata/influxdb-client-go/v2/api/http"
 	"github.com/influxdata/influxdb-client-go/v2/api/write"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -44,8 +49,9 @@ type c struct {
 	// http connection
 	// tls conf in cert.go
 	// write options
-	UseLineProtocol bool   `json:"useLineProtocol"` // 0: json, 1: line protocol
-	Measurement     string `json:"measurement"`
+	UseLineProtocol bool `json:"useLineProtocol"` // 0: json, 1: line protocol
+	// The measurement name, which can be a data template like {{.name}} to use the field value
+	Measurement string `json:"measurement"`
 	tspoint.WriteOptions
 	BatchSize int `json:"batchSize"`
 }
@@ -172,7 +178,7 @@ func (m *influxSink2) collect(ctx api.StreamContext, data any) error {
 		err = writeAPI.WritePoint(ctx, pts...)
 		if err != nil {
 			logger.Errorf("influx2 sink error: %v", err)
-			return errorx.NewIOErr(fmt.Sprintf(`influx2 sink fails to send out the data . %v`, err))
+			return writeErr(err, len(pts))
 		}
 	} else {
 		lines, err := m.transformLines(ctx, data)
@@ -182,7 +188,7 @@ func (m *influxSink2) collect(ctx api.StreamContext, data any) error {
 		err = writeAPI.WriteRecord(ctx, lines...)
 		if err != nil {
 			logger.Errorf("influx2 sink error: %v", err)
-			return errorx.NewIOErr(fmt.Sprintf(`influx2 sink fails to send out the data . %v`, err.Error()))
+			return writeErr(err, len(lines))
 		}
 	}
 	logger.Debug("insert data into influxdb2 success")
@@ -203,11 +209,43 @@ func (m *influxSink2) transformPoints(ctx api.StreamContext, data any) ([]*write
 	}
 	pts := make([]*write.Point, 0, len(rawPts))
 	for _, rawPt := range rawPts {
-		pts = append(pts, client.NewPoint(m.conf.Measurement, rawPt.Tags, rawPt.Fields, rawPt.Tt))
+		mm, err := m.measurement(ctx, rawPt)
+		if err != nil {
+			return nil, err
+		}
+		pts = append(pts, client.NewPoint(mm, rawPt.Tags, rawPt.Fields, rawPt.Tt))
 	}
 	return pts, nil
 }
 
+// measurement returns the measurement name of the point. The measurement can be a template of the point fields.
+func (m *influxSink2) measurement(ctx api.StreamContext, rawPt *tspoint.RawPoint) (string, error) {
+	if !strings.Contains(m.conf.Measurement, "{{") {
+		return m.conf.Measurement, nil
+	}
+	v, err := ctx.ParseTemplate(m.conf.Measurement, rawPt.Fields)
+	if err != nil {
+		return "", fmt.Errorf("parse measurement template %s failed, err:%v", m.conf.Measurement, err)
+	}
+	return v, nil
+}
+
+// writeErr converts the write error. The data rejected by the server is not retried, while the other errors are
+// IO errors so that the data can be resent by the sink retry and cache.
+func writeErr(err error, count int) error {
+	var he *ihttp.Error
+	if errors.As(err, &he) {
+		switch he.StatusCode {
+		case http.StatusBadRequest:
+			// The message has the line which cannot be parsed or conflicts with the field type
+			return fmt.Errorf("influx2 sink data rejected: %s", he.Message)
+		case http.StatusRequestEntityTooLarge:
+			return fmt.Errorf("influx2 sink data rejected: the batch of %d points is too large, please decrease the batchSize", count)
+		}
+	}
+	return errorx.NewIOErr(fmt.Sprintf(`influx2 sink fails to send out the data . %v`, err))
+}
+
 func (m *influxSink2) transformLines(ctx api.StreamContext, data any) ([]string, error) {
 	rawPts, err := tspoint.SinkTransform(ctx, data, &m.conf.WriteOptions)
 	if err != nil {
@@ -216,47 +254,83 @@ func (m *influxSink2) transformLines(ctx api.StreamContext, data any) ([]string,
 	}
 	lines := make([]string, 0, len(rawPts))
 	for _, rawPt := range rawPts {
-		lines = append(lines, m.rawPtToLine(rawPt))
+		mm, err := m.measurement(ctx, rawPt)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, rawPtToLine(mm, rawPt))
 	}
 	return lines, nil
 }
 
-func (m *influxSink2) rawPtToLine(rawPt *tspoint.RawPoint) string {
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringEscaper      = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// rawPtToLine converts the point to line protocol. The tags and fields are sorted by key
+func rawPtToLine(measurement string, rawPt *tspoint.RawPoint) string {
 	var builder strings.Builder
-	builder.WriteString(m.conf.Measurement)
+	builder.WriteString(measurementEscaper.Replace(measurement))
 
-	for k, v := range rawPt.Tags {
+	tagKeys := make([]string, 0, len(rawPt.Tags))
+	for k := range rawPt.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
 		builder.WriteString(",")
-		builder.WriteString(k)
+		builder.WriteString(keyEscaper.Replace(k))
 		builder.WriteString("=")
-		builder.WriteString(v)
+		builder.WriteString(keyEscaper.Replace(rawPt.Tags[k]))
 	}
 	builder.WriteString(" ")
-	c := 0
 
+	fieldKeys := make([]string, 0, len(rawPt.Fields))
 	for k, v := range rawPt.Fields {
-		c = writeLine(c, &builder, k, v)
+		// null field is not supported in line protocol
+		if v != nil {
+			fieldKeys = append(fieldKeys, k)
+		}
+	}
+	sort.Strings(fieldKeys)
+	for i, k := range fieldKeys {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(keyEscaper.Replace(k))
+		builder.WriteString("=")
+		writeFieldValue(&builder, rawPt.Fields[k])
 	}
 
 	builder.WriteString(" ")
-	builder.WriteString(fmt.Sprintf("%d", rawPt.Ts))
+	builder.WriteString(strconv.FormatInt(rawPt.Ts, 10))
 	return builder.String()
 }
 
-func writeLine(c int, builder *strings.Builder, k string, v any) int {
-	if c > 0 {
-		builder.WriteString(",")
-	}
-	c++
-	builder.WriteString(k)
-	builder.WriteString("=")
+// writeFieldValue writes the field value. Integers have the i suffix to distinguish from floats in line protocol.
+func writeFieldValue(builder *strings.Builder, v any) {
 	switch value := v.(type) {
 	case string:
-		builder.WriteString(fmt.Sprintf("\"%s\"", value))
+		builder.WriteString(`"`)
+		builder.WriteString(stringEscaper.Replace(value))
+		builder.WriteString(`"`)
+	case int, int8, int16, int32, int64:
+		builder.WriteString(fmt.Sprintf("%di", value))
+	case uint, uint8, uint16, uint32, uint64:
+		builder.WriteString(fmt.Sprintf("%du", value))
+	case float32:
+		builder.WriteString(strconv.FormatFloat(float64(value), 'f', -1, 32))
+	case float64:
+		builder.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	case bool:
+		builder.WriteString(strconv.FormatBool(value))
 	default:
-		builder.WriteString(fmt.Sprintf("%v", value))
+		builder.WriteString(`"`)
+		builder.WriteString(stringEscaper.Replace(fmt.Sprintf("%v", value)))
+		builder.WriteString(`"`)
 	}
-	return c
 }
 
 func GetSink() api.Sink {
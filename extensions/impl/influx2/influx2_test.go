@@ -15,15 +15,18 @@
 package influx2
 
 import (
-	"reflect"
+	"errors"
+	"net/http"
 	"testing"
 	"time"
 
 	client "github.com/influxdata/influxdb-client-go/v2"
+	ihttp "github.com/influxdata/influxdb-client-go/v2/api/http"
 	"github.com/influxdata/influxdb-client-go/v2/api/write"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/tspoint"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
@@ -452,11 +455,10 @@ func TestCollectPointsError(t *testing.T) {
 func TestCollectLines(t *testing.T) {
 	timex.Set(10)
 	tests := []struct {
-		name    string
-		conf    c
-		data    any
-		result  []string
-		result2 []string
+		name   string
+		conf   c
+		data   any
+		result []string
 	}{
 		{
 			name: "normal",
@@ -493,7 +495,7 @@ func TestCollectLines(t *testing.T) {
 					"humidity": 60,
 				},
 			},
-			result: []string{"test2,tag2=value2 temperature=20 10000000", "test2,tag2=value2 humidity=60 10000000"},
+			result: []string{"test2,tag2=value2 temperature=20i 10000000", "test2,tag2=value2 humidity=60i 10000000"},
 		},
 		{
 			name: "normal batch sendSingle",
@@ -514,7 +516,7 @@ func TestCollectLines(t *testing.T) {
 					"temperature": 30,
 				},
 			},
-			result: []string{"test3,tag1=value1 humidity=50 10000", "test3,tag1=value1 temperature=30 10000"},
+			result: []string{"test3,tag1=value1 humidity=50i 10000", "test3,tag1=value1 temperature=30i 10000"},
 		},
 		{
 			name: "single with fields",
@@ -532,8 +534,33 @@ func TestCollectLines(t *testing.T) {
 				"humidity": 50,
 				"ts":       100,
 			},
-			result:  []string{"test5,tag2=50 humidity=50,ts=100 100"},
-			result2: []string{"test5,tag2=50 ts=100,humidity=50 100"},
+			result: []string{"test5,tag2=50 humidity=50i,ts=100i 100"},
+		},
+		{
+			name: "types and escape",
+			conf: c{
+				Measurement: "{{.type}} data",
+				WriteOptions: tspoint.WriteOptions{
+					Tags: map[string]string{
+						"b tag": "v,1",
+						"a":     "x=y",
+					},
+					PrecisionStr: "s",
+					TsFieldName:  "ts",
+				},
+			},
+			data: map[string]any{
+				"type":  "sensor",
+				"int":   int64(-3),
+				"uint":  uint32(3),
+				"float": 2.0,
+				"small": 0.000001,
+				"bool":  true,
+				"str":   `say "hi" \`,
+				"null":  nil,
+				"ts":    100,
+			},
+			result: []string{`sensor\ data,a=x\=y,b\ tag=v\,1 bool=true,float=2,int=-3i,small=0.000001,str="say \"hi\" \\",ts=100i,type="sensor",uint=3u 100`},
 		},
 	}
 
@@ -545,11 +572,7 @@ func TestCollectLines(t *testing.T) {
 			ctx := mockContext.NewMockContext(test.name, "op")
 			lines, err := ifsink.transformLines(ctx, test.data)
 			assert.NoError(t, err)
-			if test.result2 == nil {
-				assert.Equal(t, test.result, lines)
-			} else {
-				assert.True(t, reflect.DeepEqual(test.result, lines) || reflect.DeepEqual(test.result2, lines))
-			}
+			assert.Equal(t, test.result, lines)
 		})
 	}
 }
@@ -627,3 +650,43 @@ func TestCollectLinesError(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteErr(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		msg   string
+		ioErr bool
+	}{
+		{
+			name: "bad request",
+			err:  &ihttp.Error{StatusCode: http.StatusBadRequest, Code: "invalid", Message: "unable to parse 'test1 name=': missing field value"},
+			msg:  "influx2 sink data rejected: unable to parse 'test1 name=': missing field value",
+		},
+		{
+			name: "too large",
+			err:  &ihttp.Error{StatusCode: http.StatusRequestEntityTooLarge, Code: "request too large"},
+			msg:  "influx2 sink data rejected: the batch of 3 points is too large, please decrease the batchSize",
+		},
+		{
+			name:  "server error",
+			err:   &ihttp.Error{StatusCode: http.StatusServiceUnavailable},
+			ioErr: true,
+		},
+		{
+			name:  "connection error",
+			err:   errors.New("connection refused"),
+			msg:   "influx2 sink fails to send out the data . connection refused",
+			ioErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := writeErr(test.err, 3)
+			assert.Equal(t, test.ioErr, errorx.IsIOError(err))
+			if test.msg != "" {
+				assert.EqualError(t, err, test.msg)
+			}
+		})
+	}
+}
@@ -21,7 +21,8 @@ import (
 )
 
 const (
-	LblTarget = "target"
+	LblTarget    = "target"
+	LblPartition = "partition"
 )
 
 var (
@@ -39,9 +40,17 @@ var (
 		Help:      "Sink Historgram Duration of IO",
 		Buckets:   prometheus.ExponentialBuckets(10, 2, 20), // 10us ~ 5s
 	}, []string{metrics.LblType, LblTarget, metrics.LblRuleIDType, metrics.LblOpIDType})
+
+	KafkaSourceLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "kuiper",
+		Subsystem: "kafka_source",
+		Name:      "lag",
+		Help:      "Lag of the Kafka Source of each partition",
+	}, []string{LblPartition, metrics.LblRuleIDType, metrics.LblOpIDType})
 )
 
 func init() {
 	prometheus.MustRegister(KafkaSinkCounter)
 	prometheus.MustRegister(KafkaSinkCollectDurationHist)
+	prometheus.MustRegister(KafkaSourceLagGauge)
 }
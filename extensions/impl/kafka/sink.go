@@ -18,7 +18,9 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -47,6 +49,9 @@ const (
 	LblSend      = "send"
 )
 
+// noValue is the output of the template when the field does not exist in the data
+const noValue = "<no value>"
+
 type KafkaSink struct {
 	writer         *kafkago.Writer
 	kc             *kafkaConf
@@ -62,6 +67,9 @@ type KafkaSink struct {
 	ruleID         string
 	opID           string
 	statManager    metric.StatManager
+	// partitions is the cached partition count of the topic to validate the explicit partition
+	partitionLock sync.Mutex
+	partitions    int
 }
 
 func (k *KafkaSink) setStatManager(ctx api.StreamContext) {
@@ -99,6 +107,17 @@ type kafkaConf struct {
 
 	// write config
 	Compression string `json:"compression"`
+
+	// KeyField is the field whose value is used as the message key. It has higher priority than key
+	KeyField string `json:"keyField"`
+	// PartitionField is the field whose value is used as the partition of the message. It has higher priority than the key hash
+	PartitionField string `json:"partitionField"`
+	// Idempotent adds the idempotency key to the message header so that the consumers can drop the replayed messages
+	Idempotent bool `json:"idempotent"`
+	// DedupField is the field used as the idempotency key. If not set, the hash of the payload is used
+	DedupField string `json:"dedupField"`
+	// DedupHeader is the header name of the idempotency key
+	DedupHeader string `json:"dedupHeader"`
 }
 
 type kafkaWriterConf struct {
@@ -114,6 +133,14 @@ func (c *kafkaConf) validate() error {
 	if len(c.Brokers) < 1 {
 		return fmt.Errorf("brokers can not be empty")
 	}
+	if c.Idempotent && c.DedupHeader == "" {
+		return fmt.Errorf("dedupHeader can not be empty")
+	}
+	switch strings.ToLower(c.Compression) {
+	case "", "none", "gzip", "snappy", "lz4", "zstd":
+	default:
+		return fmt.Errorf("compression %s is not supported, must be one of none, gzip, snappy, lz4 and zstd", c.Compression)
+	}
 	return nil
 }
 
@@ -201,11 +228,15 @@ func (k *KafkaSink) ping(address string) error {
 
 func (k *KafkaSink) buildKafkaWriter() error {
 	brokers := strings.Split(k.kc.Brokers, ",")
+	// kafka java-client default balancer
+	var balancer kafkago.Balancer = &kafkago.Murmur2Balancer{}
+	if k.kc.PartitionField != "" {
+		balancer = &partitionBalancer{fallback: balancer}
+	}
 	w := &kafkago.Writer{
-		Addr:  kafkago.TCP(brokers...),
-		Topic: k.kc.Topic,
-		// kafka java-client default balancer
-		Balancer:               &kafkago.Murmur2Balancer{},
+		Addr:                   kafkago.TCP(brokers...),
+		Topic:                  k.kc.Topic,
+		Balancer:               balancer,
 		Async:                  false,
 		AllowAutoTopicCreation: true,
 		MaxAttempts:            k.kc.MaxAttempts,
@@ -336,6 +367,38 @@ func (k *KafkaSink) collect(ctx api.StreamContext, item api.RawTuple) error {
 	return nil
 }
 
+// Templates returns the templates of the key field and partition field so that they are calculated by the data.
+// The fields are only accessible when sending the data one by one.
+func (k *KafkaSink) Templates(props map[string]any) []string {
+	if ss, err := cast.ToBool(props["sendSingle"], cast.CONVERT_ALL); err != nil || !ss {
+		return nil
+	}
+	var result []string
+	for _, name := range []string{"keyField", "partitionField", "dedupField"} {
+		if f, ok := props[name].(string); ok && f != "" {
+			result = append(result, fieldTemplate(f))
+		}
+	}
+	return result
+}
+
+func fieldTemplate(field string) string {
+	return fmt.Sprintf("{{index . %q}}", field)
+}
+
+// fieldValue returns the value of the field calculated by the template. The bool is false if the field does not exist.
+func fieldValue(item api.RawTuple, field string) (string, bool) {
+	dp, ok := item.(api.HasDynamicProps)
+	if !ok {
+		return "", false
+	}
+	v, ok := dp.DynamicProps(fieldTemplate(field))
+	if !ok || v == "" || v == noValue {
+		return "", false
+	}
+	return v, true
+}
+
 func (k *KafkaSink) buildMsg(ctx api.StreamContext, item api.RawTuple) (kafkago.Message, error) {
 	msg := kafkago.Message{Value: item.Raw()}
 	if len(k.kc.Key) > 0 {
@@ -348,14 +411,110 @@ func (k *KafkaSink) buildMsg(ctx api.StreamContext, item api.RawTuple) (kafkago.
 		}
 		msg.Key = []byte(newKey)
 	}
+	if k.kc.KeyField != "" {
+		if v, ok := fieldValue(item, k.kc.KeyField); ok {
+			msg.Key = []byte(v)
+		}
+	}
+	if k.kc.PartitionField != "" {
+		p, err := k.parsePartition(ctx, item)
+		if err != nil {
+			return kafkago.Message{}, err
+		}
+		msg.Partition = p
+	}
 	headers, err := k.parseHeaders(ctx, item)
 	if err != nil {
 		return kafkago.Message{}, fmt.Errorf("parse kafka headers error: %v", err)
 	}
 	msg.Headers = headers
+	if k.kc.Idempotent {
+		id, err := k.dedupKey(item)
+		if err != nil {
+			return kafkago.Message{}, err
+		}
+		msg.Headers = append(msg.Headers, kafkago.Header{Key: k.kc.DedupHeader, Value: []byte(id)})
+	}
 	return msg, nil
 }
 
+// dedupKey returns the value of the dedup field or the hash of the payload. The replayed message has the same key.
+func (k *KafkaSink) dedupKey(item api.RawTuple) (string, error) {
+	if k.kc.DedupField == "" {
+		return util.HashKey(item.Raw()), nil
+	}
+	v, ok := fieldValue(item, k.kc.DedupField)
+	if !ok {
+		return "", fmt.Errorf("dedup field %s does not exist in data", k.kc.DedupField)
+	}
+	return v, nil
+}
+
+// parsePartition returns the explicit partition of the message, or -1 if the partition field does not exist
+func (k *KafkaSink) parsePartition(ctx api.StreamContext, item api.RawTuple) (int, error) {
+	v, ok := fieldValue(item, k.kc.PartitionField)
+	if !ok {
+		return -1, nil
+	}
+	p, err := strconv.Atoi(v)
+	if err != nil {
+		return -1, fmt.Errorf("invalid partition %s of field %s, it must be an integer", v, k.kc.PartitionField)
+	}
+	if p < 0 {
+		return -1, fmt.Errorf("invalid partition %d of field %s, it must not be negative", p, k.kc.PartitionField)
+	}
+	n := k.getPartitionCount(ctx, p)
+	if n > 0 && p >= n {
+		return -1, fmt.Errorf("invalid partition %d of field %s, topic %s only has %d partitions", p, k.kc.PartitionField, k.kc.Topic, n)
+	}
+	return p, nil
+}
+
+// getPartitionCount returns the cached partition count of the topic. The count is refreshed if the partition exceeds
+// it in case the partitions are added. Return 0 if the count is unknown.
+func (k *KafkaSink) getPartitionCount(ctx api.StreamContext, p int) int {
+	k.partitionLock.Lock()
+	defer k.partitionLock.Unlock()
+	if k.partitions > 0 && p < k.partitions {
+		return k.partitions
+	}
+	client := &kafkago.Client{
+		Addr:    kafkago.TCP(strings.Split(k.kc.Brokers, ",")...),
+		Timeout: 5 * time.Second,
+		Transport: &kafkago.Transport{
+			SASL: k.mechanism,
+			TLS:  k.tlsConfig,
+		},
+	}
+	resp, err := client.Metadata(ctx, &kafkago.MetadataRequest{Topics: []string{k.kc.Topic}})
+	if err != nil {
+		ctx.GetLogger().Warnf("get partitions of kafka topic %s error: %v", k.kc.Topic, err)
+		return k.partitions
+	}
+	for _, t := range resp.Topics {
+		if t.Name == k.kc.Topic && t.Error == nil {
+			k.partitions = len(t.Partitions)
+		}
+	}
+	return k.partitions
+}
+
+// partitionBalancer uses the explicit partition of the message if it is set, otherwise uses the fallback balancer
+type partitionBalancer struct {
+	fallback kafkago.Balancer
+}
+
+func (b *partitionBalancer) Balance(msg kafkago.Message, partitions ...int) int {
+	if msg.Partition >= 0 {
+		for _, p := range partitions {
+			if p == msg.Partition {
+				return p
+			}
+		}
+	}
+	return b.fallback.Balance(msg, partitions...)
+}
+
 func (k *KafkaSink) setHeaders() error {
 	if k.kc.Headers == nil {
 		return nil
@@ -467,12 +626,15 @@ var (
 	_ api.BytesCollector = &KafkaSink{}
 	_ util.PingableConn  = &KafkaSink{}
 	_ model.SinkInfoNode = &KafkaSink{}
+	_ model.TemplateSink = &KafkaSink{}
+	_ kafkago.Balancer   = &partitionBalancer{}
 )
 
 func getDefaultKafkaConf() *kafkaConf {
 	c := &kafkaConf{
 		RequiredACKs: 1,
 		MaxAttempts:  3,
+		DedupHeader:  "ekuiper-dedup-key",
 	}
 	c.kafkaWriterConf = kafkaWriterConf{
 		BatchSize:    1,
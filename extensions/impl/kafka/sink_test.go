@@ -174,6 +174,10 @@ func TestToCompression(t *testing.T) {
 			c:      "",
 			expect: 0,
 		},
+		{
+			c:      "none",
+			expect: 0,
+		},
 	}
 	for _, tc := range testcase {
 		e := toCompression(tc.c)
@@ -186,3 +190,141 @@ func TestKafkaSinkEmpty(t *testing.T) {
 	ctx := mockContext.NewMockContext("1", "2")
 	ks.send(ctx)
 }
+
+func TestKafkaSinkKeyAndPartition(t *testing.T) {
+	configs := map[string]any{
+		"topic":          "t",
+		"brokers":        "localhost:9092",
+		"key":            "defaultKey",
+		"keyField":       "id",
+		"partitionField": "p",
+		"sendSingle":     true,
+	}
+	ks := &KafkaSink{}
+	require.Equal(t, []string{`{{index . "id"}}`, `{{index . "p"}}`}, ks.Templates(configs))
+	require.Nil(t, ks.Templates(map[string]any{"keyField": "id"}))
+	ctx := mockContext.NewMockContext("1", "2")
+	require.NoError(t, ks.Provision(ctx, configs))
+	require.NoError(t, ks.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer ks.Close(ctx)
+	_, ok := ks.writer.Balancer.(*partitionBalancer)
+	require.True(t, ok)
+	// mock the partition count to avoid connecting to kafka
+	ks.partitions = 3
+	tests := []struct {
+		name      string
+		template  map[string]string
+		key       string
+		partition int
+		err       string
+	}{
+		{
+			name:      "key and partition",
+			template:  map[string]string{`{{index . "id"}}`: "dev1", `{{index . "p"}}`: "2"},
+			key:       "dev1",
+			partition: 2,
+		},
+		{
+			name:      "missing fields",
+			template:  map[string]string{`{{index . "id"}}`: "<no value>", `{{index . "p"}}`: "<no value>"},
+			key:       "defaultKey",
+			partition: -1,
+		},
+		{
+			name:     "invalid partition",
+			template: map[string]string{`{{index . "id"}}`: "dev1", `{{index . "p"}}`: "a"},
+			err:      "invalid partition a of field p, it must be an integer",
+		},
+		{
+			name:     "negative partition",
+			template: map[string]string{`{{index . "id"}}`: "dev1", `{{index . "p"}}`: "-1"},
+			err:      "invalid partition -1 of field p, it must not be negative",
+		},
+		{
+			name:     "out of range partition",
+			template: map[string]string{`{{index . "id"}}`: "dev1", `{{index . "p"}}`: "5"},
+			err:      "invalid partition 5 of field p, topic t only has 3 partitions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ks.buildMsg(ctx, &testx.MockRawTuple{Content: []byte(`{}`), Template: tt.template})
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []byte(tt.key), msg.Key)
+			require.Equal(t, tt.partition, msg.Partition)
+		})
+	}
+}
+
+func TestPartitionBalancer(t *testing.T) {
+	b := &partitionBalancer{fallback: &kafkago.Murmur2Balancer{}}
+	require.Equal(t, 2, b.Balance(kafkago.Message{Partition: 2, Key: []byte("a")}, 0, 1, 2))
+	expected := (&kafkago.Murmur2Balancer{}).Balance(kafkago.Message{Key: []byte("a")}, 0, 1, 2)
+	require.Equal(t, expected, b.Balance(kafkago.Message{Partition: -1, Key: []byte("a")}, 0, 1, 2))
+	// not exist partition falls back to the key hash
+	require.Equal(t, expected, b.Balance(kafkago.Message{Partition: 5, Key: []byte("a")}, 0, 1, 2))
+}
+
+func TestKafkaSinkCompression(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	ks := &KafkaSink{}
+	require.EqualError(t, ks.Provision(ctx, map[string]any{
+		"topic":       "t",
+		"brokers":     "localhost:9092",
+		"compression": "zlib",
+	}), "compression zlib is not supported, must be one of none, gzip, snappy, lz4 and zstd")
+}
+
+func TestKafkaSinkIdempotent(t *testing.T) {
+	ctx := mockContext.NewMockContext("1", "2")
+	ks := &KafkaSink{}
+	require.EqualError(t, ks.Provision(ctx, map[string]any{
+		"topic":       "t",
+		"brokers":     "localhost:9092",
+		"idempotent":  true,
+		"dedupHeader": "",
+	}), "dedupHeader can not be empty")
+
+	configs := map[string]any{
+		"topic":      "t",
+		"brokers":    "localhost:9092",
+		"idempotent": true,
+		"dedupField": "id",
+		"sendSingle": true,
+	}
+	require.Equal(t, []string{`{{index . "id"}}`}, ks.Templates(configs))
+	require.NoError(t, ks.Provision(ctx, configs))
+	require.NoError(t, ks.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer ks.Close(ctx)
+	msg, err := ks.buildMsg(ctx, &testx.MockRawTuple{Content: []byte(`{"id":"a1"}`), Template: map[string]string{`{{index . "id"}}`: "a1"}})
+	require.NoError(t, err)
+	require.Equal(t, []kafkago.Header{{Key: "ekuiper-dedup-key", Value: []byte("a1")}}, msg.Headers)
+	_, err = ks.buildMsg(ctx, &testx.MockRawTuple{Content: []byte(`{}`), Template: map[string]string{`{{index . "id"}}`: "<no value>"}})
+	require.EqualError(t, err, "dedup field id does not exist in data")
+
+	// the replayed payload has the same hash
+	ks2 := &KafkaSink{}
+	require.NoError(t, ks2.Provision(ctx, map[string]any{
+		"topic":       "t",
+		"brokers":     "localhost:9092",
+		"idempotent":  true,
+		"dedupHeader": "msgId",
+	}))
+	m1, err := ks2.buildMsg(ctx, &testx.MockRawTuple{Content: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	m2, err := ks2.buildMsg(ctx, &testx.MockRawTuple{Content: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	m3, err := ks2.buildMsg(ctx, &testx.MockRawTuple{Content: []byte(`{"a":2}`)})
+	require.NoError(t, err)
+	require.Equal(t, "msgId", m1.Headers[0].Key)
+	require.Equal(t, m1.Headers, m2.Headers)
+	require.NotEqual(t, m1.Headers, m3.Headers)
+}
@@ -15,10 +15,13 @@
 package kafka
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -33,16 +36,30 @@ import (
 	"github.com/lf-edge/ekuiper/v2/metrics"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
+// kafkaReader is the subset of the kafka reader used by the source
+type kafkaReader interface {
+	ReadMessage(ctx context.Context) (kafkago.Message, error)
+	FetchMessage(ctx context.Context) (kafkago.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafkago.Message) error
+	SetOffset(offset int64) error
+	Close() error
+}
+
 type KafkaSource struct {
-	reader    *kafkago.Reader
-	offset    int64
+	reader    kafkaReader
 	tlsConfig *tls.Config
 	sc        *kafkaSourceConf
 	saslConf  *saslConf
 	mechanism sasl.Mechanism
+	// manualCommit commits the offsets only when the checkpoint completes
+	manualCommit bool
+	mu           sync.Mutex
+	// offsets are the next offsets to read of each partition
+	offsets map[int]int64
 }
 
 type kafkaSourceConf struct {
@@ -52,8 +69,17 @@ type kafkaSourceConf struct {
 	Partition   int    `json:"partition"`
 	MaxAttempts int    `json:"maxAttempts"`
 	MaxBytes    int    `json:"maxBytes"`
+	// StartOffset is where to start reading if there is no checkpoint or committed offset. It could be earliest,
+	// latest or a specific offset
+	StartOffset any `json:"startOffset"`
+	startOffset int64
 }
 
+const (
+	startOffsetEarliest = "earliest"
+	startOffsetLatest   = "latest"
+)
+
 func (c *kafkaSourceConf) validate() error {
 	if c.Topic == "" {
 		return fmt.Errorf("kafkaSourceConf topic is required")
@@ -61,6 +87,35 @@ func (c *kafkaSourceConf) validate() error {
 	if len(c.Brokers) < 1 {
 		return fmt.Errorf("brokers can not be empty")
 	}
+	switch v := c.StartOffset.(type) {
+	case nil:
+		c.startOffset = kafkago.LastOffset
+	case string:
+		switch strings.ToLower(v) {
+		case startOffsetEarliest:
+			c.startOffset = kafkago.FirstOffset
+		case startOffsetLatest, "":
+			c.startOffset = kafkago.LastOffset
+		default:
+			o, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("startOffset must be earliest, latest or an offset, but got %s", v)
+			}
+			c.startOffset = o
+		}
+	default:
+		o, err := cast.ToInt64(v, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return fmt.Errorf("startOffset must be earliest, latest or an offset, but got %v", v)
+		}
+		c.startOffset = o
+	}
+	if c.startOffset < kafkago.FirstOffset {
+		return fmt.Errorf("startOffset must not be negative")
+	}
+	if c.startOffset >= 0 && c.GroupID != "" {
+		return fmt.Errorf("startOffset of a specific offset is not supported with groupID, use earliest or latest")
+	}
 	return nil
 }
 
@@ -72,6 +127,8 @@ func (c *kafkaSourceConf) GetReaderConfig() kafkago.ReaderConfig {
 		Partition:   c.Partition,
 		MaxBytes:    c.MaxBytes,
 		MaxAttempts: c.MaxAttempts,
+		// Only used for the consumer group without committed offset
+		StartOffset: c.startOffset,
 	}
 }
 
@@ -159,6 +216,9 @@ func (k *KafkaSource) ping(address string) error {
 }
 
 func (k *KafkaSource) Close(ctx api.StreamContext) error {
+	if k.reader == nil {
+		return nil
+	}
 	return k.reader.Close()
 }
 
@@ -173,12 +233,16 @@ func (k *KafkaSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler
 	}
 	reader := kafkago.NewReader(readerConfig)
 	k.reader = reader
-	err := k.reader.SetOffset(kafkago.LastOffset)
-	if err != nil {
-		sch(api.ConnectionDisconnected, err.Error())
-	} else {
-		sch(api.ConnectionConnected, "")
+	k.offsets = make(map[int]int64)
+	// The consumer group starts from the committed offsets, or the start offset if not committed
+	if k.sc.GroupID == "" {
+		err := k.reader.SetOffset(k.sc.startOffset)
+		if err != nil {
+			sch(api.ConnectionDisconnected, err.Error())
+			return nil
+		}
 	}
+	sch(api.ConnectionConnected, "")
 	return nil
 }
 
@@ -189,42 +253,125 @@ func (k *KafkaSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, i
 			return nil
 		default:
 		}
-		msg, err := k.reader.ReadMessage(ctx)
+		var (
+			msg kafkago.Message
+			err error
+		)
+		if k.manualCommit {
+			// Fetch without committing, the offsets are committed when the checkpoint completes
+			msg, err = k.reader.FetchMessage(ctx)
+		} else {
+			msg, err = k.reader.ReadMessage(ctx)
+		}
 		if err != nil {
 			ingestError(ctx, err)
 			continue
 		}
 		metrics.IOCounter.WithLabelValues(LblKafka, metrics.LblSourceIO, LblMsg, ctx.GetRuleId(), ctx.GetOpId()).Inc()
+		if msg.HighWaterMark > 0 {
+			KafkaSourceLagGauge.WithLabelValues(strconv.Itoa(msg.Partition), ctx.GetRuleId(), ctx.GetOpId()).Set(float64(msg.HighWaterMark - msg.Offset - 1))
+		}
 		ingest(ctx, msg.Value, nil, timex.GetNow())
+		// Update the offset after the message is sent out so that a checkpoint never covers the message not sent yet
+		k.mu.Lock()
+		k.offsets[msg.Partition] = msg.Offset + 1
+		k.mu.Unlock()
 	}
 }
 
+// Rewind sets the offset of the checkpoint. The consumer group starts from the committed offsets which are already
+// aligned with the checkpoint, so it is only needed for the reader of a single partition.
 func (k *KafkaSource) Rewind(offset interface{}) error {
 	conf.Log.Infof("set kafka source offset: %v", offset)
-	offsetV := k.offset //nolint:staticcheck
+	offsets, err := toOffsets(offset)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	for p, o := range offsets {
+		k.offsets[p] = o
+	}
+	k.mu.Unlock()
+	if k.sc.GroupID != "" {
+		return nil
+	}
+	o, ok := offsets[k.sc.Partition]
+	if !ok {
+		return nil
+	}
+	if err := k.reader.SetOffset(o); err != nil {
+		conf.Log.Errorf("kafka offset error: %v", err)
+		return fmt.Errorf("set kafka offset failed, err:%v", err)
+	}
+	return nil
+}
+
+// toOffsets converts the offset state to the offsets of each partition. The state restored from the checkpoint is a
+// map of partition to offset, or a single offset of the legacy state.
+func toOffsets(offset any) (map[int]int64, error) {
 	switch v := offset.(type) {
+	case map[string]any:
+		offsets := make(map[int]int64, len(v))
+		for ps, ov := range v {
+			p, err := strconv.Atoi(ps)
+			if err != nil {
+				return nil, fmt.Errorf("invalid partition %s in offset %v", ps, offset)
+			}
+			o, err := cast.ToInt64(ov, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return nil, fmt.Errorf("invalid offset %v of partition %s", ov, ps)
+			}
+			offsets[p] = o
+		}
+		return offsets, nil
 	case int64:
-		offsetV = v
+		return map[int]int64{0: v}, nil
 	case int:
-		offsetV = int64(v)
+		return map[int]int64{0: int64(v)}, nil
 	case float64:
-		offsetV = int64(v)
+		return map[int]int64{0: int64(v)}, nil
 	default:
-		return fmt.Errorf("%v can't be set as offset", offset)
-	}
-	if err := k.reader.SetOffset(offsetV); err != nil {
-		conf.Log.Errorf("kafka offset error: %v", err)
-		return fmt.Errorf("set kafka offset failed, err:%v", err)
+		return nil, fmt.Errorf("%v can't be set as offset", offset)
 	}
-	return nil
 }
 
 func (k *KafkaSource) ResetOffset(input map[string]interface{}) error {
 	return errors.New("kafka source not support reset offset")
 }
 
+// GetOffset returns the next offsets to read of each partition, {"partition": offset}
 func (k *KafkaSource) GetOffset() (interface{}, error) {
-	return k.offset, nil
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	offsets := make(map[string]any, len(k.offsets))
+	for p, o := range k.offsets {
+		offsets[strconv.Itoa(p)] = o
+	}
+	return offsets, nil
+}
+
+func (k *KafkaSource) EnableCheckpointCommit() {
+	k.manualCommit = true
+}
+
+// CommitOffset commits the offsets of a completed checkpoint to the consumer group
+func (k *KafkaSource) CommitOffset(ctx api.StreamContext, offset any) error {
+	if k.sc.GroupID == "" {
+		return nil
+	}
+	offsets, err := toOffsets(offset)
+	if err != nil {
+		return err
+	}
+	msgs := make([]kafkago.Message, 0, len(offsets))
+	for p, o := range offsets {
+		// The committed offset is the offset of the message plus one
+		msgs = append(msgs, kafkago.Message{Topic: k.sc.Topic, Partition: p, Offset: o - 1})
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return k.reader.CommitMessages(ctx, msgs...)
 }
 
 const (
@@ -306,11 +453,30 @@ func mockKakfaSourceErr(v, exp int) error {
 	return nil
 }
 
+// PartitionProps makes the parallel readers join the same consumer group so that kafka balances the partitions among
+// them. Without the group id, the reader of index reads the partition next to the configured one by index.
+func (k *KafkaSource) PartitionProps(props map[string]any, index int, _ int) (map[string]any, error) {
+	c, err := getSourceConf(props)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]any, len(props)+1)
+	for key, v := range props {
+		result[key] = v
+	}
+	if c.GroupID == "" {
+		result["partition"] = c.Partition + index
+	}
+	return result, nil
+}
+
 func GetSource() api.Source {
 	return &KafkaSource{}
 }
 
 var (
-	_ api.BytesSource   = &KafkaSource{}
-	_ util.PingableConn = &KafkaSource{}
+	_ api.BytesSource         = &KafkaSource{}
+	_ util.PingableConn       = &KafkaSource{}
+	_ model.OffsetCommitter   = &KafkaSource{}
+	_ model.PartitionedSource = &KafkaSource{}
 )
@@ -15,10 +15,15 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/pingcap/failpoint"
+	kafkago "github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/require"
 
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
@@ -116,3 +121,184 @@ func TestKafkaPassword(t *testing.T) {
 		require.Equal(t, tc.expectPassword, sconf.SaslPassword)
 	}
 }
+
+func TestStartOffset(t *testing.T) {
+	testcases := []struct {
+		offset  any
+		groupID string
+		exp     int64
+		err     string
+	}{
+		{offset: nil, exp: kafkago.LastOffset},
+		{offset: "earliest", exp: kafkago.FirstOffset},
+		{offset: "Latest", exp: kafkago.LastOffset},
+		{offset: "100", exp: 100},
+		{offset: 100, exp: 100},
+		{offset: "earliest", groupID: "g", exp: kafkago.FirstOffset},
+		{offset: "first", err: "startOffset must be earliest, latest or an offset, but got first"},
+		{offset: -5, err: "startOffset must not be negative"},
+		{offset: 100, groupID: "g", err: "startOffset of a specific offset is not supported with groupID, use earliest or latest"},
+	}
+	for i, tc := range testcases {
+		c := &kafkaSourceConf{Topic: "t", Brokers: "localhost:9092", GroupID: tc.groupID, StartOffset: tc.offset}
+		err := c.validate()
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err, i)
+		} else {
+			require.NoError(t, err, i)
+			require.Equal(t, tc.exp, c.startOffset, i)
+		}
+	}
+}
+
+func TestPartitionProps(t *testing.T) {
+	k := &KafkaSource{}
+	props := map[string]any{"datasource": "t", "brokers": "localhost:9092", "partition": 2}
+	for i := 0; i < 3; i++ {
+		p, err := k.PartitionProps(props, i, 3)
+		require.NoError(t, err)
+		require.Equal(t, 2+i, p["partition"])
+	}
+	// the original props are not changed
+	require.Equal(t, 2, props["partition"])
+	// kafka balances the partitions among the readers of the same group
+	props = map[string]any{"datasource": "t", "brokers": "localhost:9092", "groupID": "g"}
+	p, err := k.PartitionProps(props, 1, 3)
+	require.NoError(t, err)
+	require.Equal(t, props, p)
+}
+
+// mockBroker keeps the messages of the topic and the committed offsets of the group
+type mockBroker struct {
+	sync.Mutex
+	msgs      []kafkago.Message
+	committed map[int]int64
+}
+
+type mockReader struct {
+	b   *mockBroker
+	pos int64
+	// limit is the position to stop fetching
+	limit int64
+	set   []int64
+}
+
+func newMockReader(b *mockBroker) *mockReader {
+	b.Lock()
+	defer b.Unlock()
+	return &mockReader{b: b, pos: b.committed[0]}
+}
+
+func (r *mockReader) FetchMessage(ctx context.Context) (kafkago.Message, error) {
+	r.b.Lock()
+	if r.pos < r.limit && r.pos < int64(len(r.b.msgs)) {
+		m := r.b.msgs[r.pos]
+		r.pos++
+		r.b.Unlock()
+		return m, nil
+	}
+	r.b.Unlock()
+	<-ctx.Done()
+	return kafkago.Message{}, ctx.Err()
+}
+
+func (r *mockReader) ReadMessage(ctx context.Context) (kafkago.Message, error) {
+	m, err := r.FetchMessage(ctx)
+	if err != nil {
+		return m, err
+	}
+	return m, r.CommitMessages(ctx, m)
+}
+
+func (r *mockReader) CommitMessages(_ context.Context, msgs ...kafkago.Message) error {
+	r.b.Lock()
+	defer r.b.Unlock()
+	for _, m := range msgs {
+		r.b.committed[m.Partition] = m.Offset + 1
+	}
+	return nil
+}
+
+func (r *mockReader) SetOffset(offset int64) error {
+	r.set = append(r.set, offset)
+	return nil
+}
+
+func (r *mockReader) Close() error {
+	return nil
+}
+
+// consume subscribes the source until n messages are received and returns the payloads
+func consume(t *testing.T, ks *KafkaSource, n int) []string {
+	r := ks.reader.(*mockReader)
+	r.b.Lock()
+	r.limit = r.pos + int64(n)
+	r.b.Unlock()
+	ctx, cancel := mockContext.NewMockContext("1", "2").WithCancel()
+	var result []string
+	exited := make(chan struct{})
+	go func() {
+		_ = ks.Subscribe(ctx, func(_ api.StreamContext, payload []byte, _ map[string]any, _ time.Time) {
+			result = append(result, string(payload))
+		}, func(_ api.StreamContext, _ error) {})
+		close(exited)
+	}()
+	// Wait for the offset of the last message to be updated
+	require.Eventually(t, func() bool {
+		o, _ := ks.GetOffset()
+		return o.(map[string]any)["0"] == r.limit
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-exited
+	return result
+}
+
+func TestCheckpointCommit(t *testing.T) {
+	b := &mockBroker{committed: map[int]int64{}}
+	for i := 0; i < 5; i++ {
+		b.msgs = append(b.msgs, kafkago.Message{Partition: 0, Offset: int64(i), HighWaterMark: 5, Value: []byte(fmt.Sprintf("m%d", i))})
+	}
+	ctx := mockContext.NewMockContext("1", "2")
+	ks := &KafkaSource{sc: &kafkaSourceConf{Topic: "t", GroupID: "g"}, reader: newMockReader(b), offsets: map[int]int64{}}
+	ks.EnableCheckpointCommit()
+	require.Equal(t, []string{"m0", "m1"}, consume(t, ks, 2))
+	// Nothing is committed before the checkpoint completes
+	require.Empty(t, b.committed)
+	ckOffset, err := ks.GetOffset()
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"0": int64(2)}, ckOffset)
+	require.Equal(t, []string{"m2", "m3"}, consume(t, ks, 2))
+	// The checkpoint completes
+	require.NoError(t, ks.CommitOffset(ctx, ckOffset))
+	require.Equal(t, map[int]int64{0: 2}, b.committed)
+	// Crash before the next checkpoint completes, the messages after the checkpoint are replayed after restart
+	ks = &KafkaSource{sc: &kafkaSourceConf{Topic: "t", GroupID: "g"}, reader: newMockReader(b), offsets: map[int]int64{}}
+	ks.EnableCheckpointCommit()
+	require.NoError(t, ks.Rewind(ckOffset))
+	require.Equal(t, []string{"m2", "m3", "m4"}, consume(t, ks, 3))
+}
+
+func TestAutoCommit(t *testing.T) {
+	b := &mockBroker{committed: map[int]int64{}}
+	for i := 0; i < 3; i++ {
+		b.msgs = append(b.msgs, kafkago.Message{Partition: 0, Offset: int64(i), Value: []byte(fmt.Sprintf("m%d", i))})
+	}
+	ks := &KafkaSource{sc: &kafkaSourceConf{Topic: "t", GroupID: "g"}, reader: newMockReader(b), offsets: map[int]int64{}}
+	require.Equal(t, []string{"m0", "m1", "m2"}, consume(t, ks, 3))
+	// Committed by reading without checkpoint
+	require.Equal(t, map[int]int64{0: 3}, b.committed)
+}
+
+func TestRewindPartition(t *testing.T) {
+	r := &mockReader{b: &mockBroker{committed: map[int]int64{}}}
+	ks := &KafkaSource{sc: &kafkaSourceConf{Topic: "t", Partition: 1}, reader: r, offsets: map[int]int64{}}
+	require.NoError(t, ks.Rewind(map[string]any{"1": float64(7)}))
+	require.Equal(t, []int64{7}, r.set)
+	o, err := ks.GetOffset()
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"1": int64(7)}, o)
+	// The reader without group does not commit
+	require.NoError(t, ks.CommitOffset(mockContext.NewMockContext("1", "2"), o))
+	require.Empty(t, r.b.committed)
+	require.EqualError(t, ks.Rewind("abc"), "abc can't be set as offset")
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/s2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages of the remote write protocol v1. They are encoded by hand to avoid depending on the prometheus module.
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	value float64
+	// timestamp in milliseconds
+	timestamp int64
+}
+
+type timeSeries struct {
+	labels  []label
+	samples []sample
+}
+
+// seriesKey identifies the series by its sorted labels
+func seriesKey(labels []label) string {
+	var sb strings.Builder
+	for _, l := range labels {
+		sb.WriteString(l.name)
+		sb.WriteByte(0xff)
+		sb.WriteString(l.value)
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
+
+// buildSeries groups the samples of the same labels into one series. The labels of each series are sorted by name
+// and the samples are sorted by timestamp as required by the protocol.
+func buildSeries(labels [][]label, samples []sample) []*timeSeries {
+	index := make(map[string]*timeSeries, len(labels))
+	result := make([]*timeSeries, 0, len(labels))
+	for i, ls := range labels {
+		sort.Slice(ls, func(a, b int) bool {
+			return ls[a].name < ls[b].name
+		})
+		k := seriesKey(ls)
+		ts, ok := index[k]
+		if !ok {
+			ts = &timeSeries{labels: ls}
+			index[k] = ts
+			result = append(result, ts)
+		}
+		ts.samples = append(ts.samples, samples[i])
+	}
+	for _, ts := range result {
+		sort.SliceStable(ts.samples, func(a, b int) bool {
+			return ts.samples[a].timestamp < ts.samples[b].timestamp
+		})
+	}
+	return result
+}
+
+// encodeWriteRequest encodes the series as a snappy compressed WriteRequest
+func encodeWriteRequest(series []*timeSeries) []byte {
+	var b []byte
+	for _, ts := range series {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeTimeSeries(ts))
+	}
+	return s2.EncodeSnappy(nil, b)
+}
+
+func encodeTimeSeries(ts *timeSeries) []byte {
+	var b []byte
+	for _, l := range ts.labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.value)
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+	for _, s := range ts.samples {
+		var sb []byte
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.value))
+		sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.timestamp))
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	return b
+}
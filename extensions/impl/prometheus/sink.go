@@ -0,0 +1,283 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+const defaultTimeout = 5 * time.Second
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// c is the configuration for prometheus remote write sink
+type c struct {
+	Url string `json:"url"`
+	// The static metric name. It is used when metricNameField is not set or the field is absent in the tuple
+	MetricName string `json:"metricName"`
+	// The field to read the metric name from
+	MetricNameField string `json:"metricNameField"`
+	// The fields to be sent as labels
+	LabelFields []string `json:"labelFields"`
+	// The static labels added to all series
+	Labels map[string]string `json:"labels"`
+	// The field of the sample value, it must be numeric
+	ValueField string `json:"valueField"`
+	// The field of the sample timestamp in milliseconds. If not set, the tuple timestamp is used
+	TsFieldName string            `json:"tsFieldName"`
+	Headers     map[string]string `json:"headers"`
+	Timeout     cast.DurationConf `json:"timeout"`
+}
+
+// sink writes the tuples as samples by prometheus remote write protocol.
+// Each tuple is mapped to one sample. The batch is done by the sink node side, and the samples of a batch are grouped
+// into series by the labels and sent out in one request.
+type sink struct {
+	conf *c
+	cli  *http.Client
+}
+
+func (s *sink) Provision(_ api.StreamContext, props map[string]any) error {
+	conf := &c{
+		Timeout: cast.DurationConf(defaultTimeout),
+	}
+	err := cast.MapToStruct(props, conf)
+	if err != nil {
+		return fmt.Errorf("error configuring prometheus sink: %s", err)
+	}
+	if conf.Url == "" {
+		return errors.New("url is required")
+	}
+	if conf.MetricName == "" && conf.MetricNameField == "" {
+		return errors.New("either metricName or metricNameField is required")
+	}
+	if conf.MetricName != "" && !metricNameRE.MatchString(conf.MetricName) {
+		return fmt.Errorf("invalid metricName %s", conf.MetricName)
+	}
+	if conf.ValueField == "" {
+		return errors.New("valueField is required")
+	}
+	for _, f := range conf.LabelFields {
+		if err := validateLabelName(f); err != nil {
+			return err
+		}
+	}
+	for k, v := range conf.Labels {
+		if err := validateLabelName(k); err != nil {
+			return err
+		}
+		if !utf8.ValidString(v) {
+			return fmt.Errorf("value of label %s is not valid utf8", k)
+		}
+	}
+	if conf.Timeout < 0 {
+		return fmt.Errorf("timeout must be greater than or equal to 0")
+	}
+	tlsConf, err := cert.GenTLSConfig(props, "prometheus-sink")
+	if err != nil {
+		return fmt.Errorf("error configuring tls: %s", err)
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConf
+	s.cli = &http.Client{
+		Transport: tr,
+		Timeout:   time.Duration(conf.Timeout),
+	}
+	s.conf = conf
+	return nil
+}
+
+func (s *sink) Connect(_ api.StreamContext, sch api.StatusChangeHandler) error {
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *sink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	lbs, smp, err := s.toSample(item)
+	if err != nil {
+		return err
+	}
+	return s.write(ctx, [][]label{lbs}, []sample{smp})
+}
+
+// CollectList sends the valid samples of the list. The conversion errors of the invalid tuples are returned together
+// after sending so that a bad tuple does not block the others.
+func (s *sink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	var (
+		labels  = make([][]label, 0, items.Len())
+		samples = make([]sample, 0, items.Len())
+		errs    []error
+	)
+	items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+		lbs, smp, err := s.toSample(tuple)
+		if err != nil {
+			errs = append(errs, err)
+			return true
+		}
+		labels = append(labels, lbs)
+		samples = append(samples, smp)
+		return true
+	})
+	if len(samples) > 0 {
+		if err := s.write(ctx, labels, samples); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// toSample converts the tuple to the labels and the sample
+func (s *sink) toSample(tuple api.MessageTuple) ([]label, sample, error) {
+	name := s.conf.MetricName
+	if s.conf.MetricNameField != "" {
+		if v, ok := tuple.Value(s.conf.MetricNameField, ""); ok && v != nil {
+			n, err := cast.ToString(v, cast.CONVERT_SAMEKIND)
+			if err != nil {
+				return nil, sample{}, fmt.Errorf("metric name field %s must be a string but got %v", s.conf.MetricNameField, v)
+			}
+			name = n
+		}
+	}
+	if name == "" {
+		return nil, sample{}, fmt.Errorf("metric name field %s does not exist", s.conf.MetricNameField)
+	}
+	if !metricNameRE.MatchString(name) {
+		return nil, sample{}, fmt.Errorf("invalid metric name %s", name)
+	}
+	lbs := make([]label, 0, len(s.conf.Labels)+len(s.conf.LabelFields)+1)
+	lbs = append(lbs, label{name: "__name__", value: name})
+	for k, v := range s.conf.Labels {
+		lbs = append(lbs, label{name: k, value: v})
+	}
+	for _, f := range s.conf.LabelFields {
+		v, ok := tuple.Value(f, "")
+		if !ok || v == nil {
+			return nil, sample{}, fmt.Errorf("label field %s does not exist", f)
+		}
+		lv, err := cast.ToString(v, cast.CONVERT_ALL)
+		if err != nil {
+			return nil, sample{}, fmt.Errorf("label field %s must be a string but got %v", f, v)
+		}
+		if !utf8.ValidString(lv) {
+			return nil, sample{}, fmt.Errorf("value of label %s is not valid utf8", f)
+		}
+		// empty label value is the same as the label not exist in prometheus
+		if lv == "" {
+			continue
+		}
+		lbs = appendLabel(lbs, f, lv)
+	}
+	v, ok := tuple.Value(s.conf.ValueField, "")
+	if !ok || v == nil {
+		return nil, sample{}, fmt.Errorf("value field %s does not exist", s.conf.ValueField)
+	}
+	fv, err := cast.ToFloat64(v, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, sample{}, fmt.Errorf("value field %s must be numeric but got %v", s.conf.ValueField, v)
+	}
+	smp := sample{value: fv}
+	if s.conf.TsFieldName != "" {
+		tv, ok := tuple.Value(s.conf.TsFieldName, "")
+		if !ok || tv == nil {
+			return nil, sample{}, fmt.Errorf("timestamp field %s does not exist", s.conf.TsFieldName)
+		}
+		ts, err := cast.ToInt64(tv, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return nil, sample{}, fmt.Errorf("timestamp field %s must be an int but got %v", s.conf.TsFieldName, tv)
+		}
+		smp.timestamp = ts
+	} else if mi, ok := tuple.(api.MetaInfo); ok && !mi.Created().IsZero() {
+		smp.timestamp = mi.Created().UnixMilli()
+	} else {
+		smp.timestamp = time.Now().UnixMilli()
+	}
+	return lbs, smp, nil
+}
+
+// appendLabel sets the label value. The label from the field overrides the static one.
+func appendLabel(lbs []label, name, value string) []label {
+	for i := range lbs {
+		if lbs[i].name == name {
+			lbs[i].value = value
+			return lbs
+		}
+	}
+	return append(lbs, label{name: name, value: value})
+}
+
+func (s *sink) write(ctx api.StreamContext, labels [][]label, samples []sample) error {
+	body := encodeWriteRequest(buildSeries(labels, samples))
+	headers := make(map[string]string, len(s.conf.Headers)+3)
+	for k, v := range s.conf.Headers {
+		headers[k] = v
+	}
+	headers["Content-Type"] = "application/x-protobuf"
+	headers["Content-Encoding"] = "snappy"
+	headers["X-Prometheus-Remote-Write-Version"] = "0.1.0"
+	resp, err := httpx.Send(ctx.GetLogger(), s.cli, "binary", http.MethodPost, s.conf.Url, headers, body)
+	if err != nil {
+		return errorx.NewIOErr(fmt.Sprintf("prometheus sink fails to send out the data: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		ctx.GetLogger().Debugf("prometheus sink sent %d samples", len(samples))
+		return nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	msg := fmt.Sprintf("prometheus sink fails to send out the data: status=%d response_body=%s", resp.StatusCode, strings.TrimSpace(string(b)))
+	// Server errors and rate limiting are recoverable by retry. The other errors like bad request will fail again.
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return errorx.NewIOErr(msg)
+	}
+	return errors.New(msg)
+}
+
+func (s *sink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("prometheus sink close")
+	if s.cli != nil {
+		s.cli.CloseIdleConnections()
+	}
+	return nil
+}
+
+func validateLabelName(name string) error {
+	if !labelNameRE.MatchString(name) || strings.HasPrefix(name, "__") {
+		return fmt.Errorf("invalid label name %s", name)
+	}
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &sink{}
+}
+
+var _ api.TupleCollector = &sink{}
@@ -0,0 +1,271 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestProvision(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "missing url",
+			props: map[string]any{"metricName": "m", "valueField": "v"},
+			err:   "url is required",
+		},
+		{
+			name:  "missing metric name",
+			props: map[string]any{"url": "http://localhost", "valueField": "v"},
+			err:   "either metricName or metricNameField is required",
+		},
+		{
+			name:  "invalid metric name",
+			props: map[string]any{"url": "http://localhost", "metricName": "1m", "valueField": "v"},
+			err:   "invalid metricName 1m",
+		},
+		{
+			name:  "missing value field",
+			props: map[string]any{"url": "http://localhost", "metricName": "m"},
+			err:   "valueField is required",
+		},
+		{
+			name:  "invalid label field",
+			props: map[string]any{"url": "http://localhost", "metricName": "m", "valueField": "v", "labelFields": []any{"a-b"}},
+			err:   "invalid label name a-b",
+		},
+		{
+			name:  "reserved label",
+			props: map[string]any{"url": "http://localhost", "metricName": "m", "valueField": "v", "labels": map[string]any{"__name__": "x"}},
+			err:   "invalid label name __name__",
+		},
+		{
+			name:  "valid",
+			props: map[string]any{"url": "http://localhost", "metricName": "m", "valueField": "v", "labelFields": []any{"a"}, "labels": map[string]any{"b": "c"}},
+		},
+	}
+	ctx := mockContext.NewMockContext("testProvision", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GetSink()
+			err := s.Provision(ctx, tt.props)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+type recvSeries struct {
+	Labels  map[string]string
+	Samples []sample
+}
+
+// decodeWriteRequest decodes the snappy compressed WriteRequest for test verification
+func decodeWriteRequest(t *testing.T, body []byte) []recvSeries {
+	b, err := s2.Decode(nil, body)
+	require.NoError(t, err)
+	var result []recvSeries
+	forEachField(t, b, func(num protowire.Number, v []byte) {
+		require.Equal(t, protowire.Number(1), num)
+		ts := recvSeries{Labels: map[string]string{}}
+		forEachField(t, v, func(num protowire.Number, v []byte) {
+			switch num {
+			case 1:
+				var name, value string
+				forEachField(t, v, func(num protowire.Number, v []byte) {
+					if num == 1 {
+						name = string(v)
+					} else {
+						value = string(v)
+					}
+				})
+				ts.Labels[name] = value
+			case 2:
+				var smp sample
+				for len(v) > 0 {
+					num, typ, n := protowire.ConsumeTag(v)
+					require.True(t, n > 0)
+					v = v[n:]
+					switch typ {
+					case protowire.Fixed64Type:
+						fv, n := protowire.ConsumeFixed64(v)
+						require.Equal(t, protowire.Number(1), num)
+						smp.value = math.Float64frombits(fv)
+						v = v[n:]
+					case protowire.VarintType:
+						iv, n := protowire.ConsumeVarint(v)
+						require.Equal(t, protowire.Number(2), num)
+						smp.timestamp = int64(iv)
+						v = v[n:]
+					}
+				}
+				ts.Samples = append(ts.Samples, smp)
+			}
+		})
+		result = append(result, ts)
+	})
+	return result
+}
+
+func forEachField(t *testing.T, b []byte, f func(num protowire.Number, v []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.True(t, n > 0)
+		require.Equal(t, protowire.BytesType, typ)
+		b = b[n:]
+		v, n := protowire.ConsumeBytes(b)
+		require.True(t, n > 0)
+		b = b[n:]
+		f(num, v)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		received [][]recvSeries
+		status   = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, decodeWriteRequest(t, body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	ctx := mockContext.NewMockContext("testCollect", "op")
+	s := GetSink().(*sink)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":             server.URL,
+		"metricName":      "temperature",
+		"metricNameField": "metric",
+		"labelFields":     []any{"device"},
+		"labels":          map[string]any{"site": "s1"},
+		"valueField":      "value",
+		"headers":         map[string]any{"Authorization": "Bearer abc"},
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+	now := time.UnixMilli(1700000000000)
+
+	t.Run("single", func(t *testing.T) {
+		received = nil
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"device": "d1", "value": 20.5}, Timestamp: now})
+		require.NoError(t, err)
+		require.Len(t, received, 1)
+		assert.Equal(t, []recvSeries{
+			{
+				Labels:  map[string]string{"__name__": "temperature", "device": "d1", "site": "s1"},
+				Samples: []sample{{value: 20.5, timestamp: 1700000000000}},
+			},
+		}, received[0])
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		received = nil
+		tuples := &xsql.WindowTuples{
+			Content: []xsql.Row{
+				&xsql.Tuple{Message: map[string]any{"device": "d1", "value": 21}, Timestamp: now.Add(time.Second)},
+				&xsql.Tuple{Message: map[string]any{"device": "d2", "value": int64(30)}, Timestamp: now},
+				&xsql.Tuple{Message: map[string]any{"device": "d1", "value": 20.5}, Timestamp: now},
+				&xsql.Tuple{Message: map[string]any{"device": "d1", "value": 1, "metric": "humidity"}, Timestamp: now},
+			},
+		}
+		err := s.CollectList(ctx, tuples)
+		require.NoError(t, err)
+		require.Len(t, received, 1)
+		assert.Equal(t, []recvSeries{
+			{
+				Labels:  map[string]string{"__name__": "temperature", "device": "d1", "site": "s1"},
+				Samples: []sample{{value: 20.5, timestamp: 1700000000000}, {value: 21, timestamp: 1700000001000}},
+			},
+			{
+				Labels:  map[string]string{"__name__": "temperature", "device": "d2", "site": "s1"},
+				Samples: []sample{{value: 30, timestamp: 1700000000000}},
+			},
+			{
+				Labels:  map[string]string{"__name__": "humidity", "device": "d1", "site": "s1"},
+				Samples: []sample{{value: 1, timestamp: 1700000000000}},
+			},
+		}, received[0])
+	})
+
+	t.Run("invalid tuples", func(t *testing.T) {
+		received = nil
+		tuples := &xsql.WindowTuples{
+			Content: []xsql.Row{
+				&xsql.Tuple{Message: map[string]any{"device": "d1", "value": "abc"}, Timestamp: now},
+				&xsql.Tuple{Message: map[string]any{"value": 1}, Timestamp: now},
+				&xsql.Tuple{Message: map[string]any{"device": "d1", "value": 1, "metric": "a-b"}, Timestamp: now},
+				&xsql.Tuple{Message: map[string]any{"device": "d3", "value": 3}, Timestamp: now},
+			},
+		}
+		err := s.CollectList(ctx, tuples)
+		assert.EqualError(t, err, "value field value must be numeric but got abc\nlabel field device does not exist\ninvalid metric name a-b")
+		require.Len(t, received, 1)
+		assert.Equal(t, []recvSeries{
+			{
+				Labels:  map[string]string{"__name__": "temperature", "device": "d3", "site": "s1"},
+				Samples: []sample{{value: 3, timestamp: 1700000000000}},
+			},
+		}, received[0])
+	})
+
+	t.Run("retriable error", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		defer func() { status = http.StatusNoContent }()
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"device": "d1", "value": 1}, Timestamp: now})
+		require.Error(t, err)
+		assert.True(t, errorx.IsIOError(err))
+	})
+
+	t.Run("bad request", func(t *testing.T) {
+		status = http.StatusBadRequest
+		defer func() { status = http.StatusNoContent }()
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"device": "d1", "value": 1}, Timestamp: now})
+		require.Error(t, err)
+		assert.False(t, errorx.IsIOError(err))
+	})
+}
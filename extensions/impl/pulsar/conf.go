@@ -0,0 +1,63 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pulsar
+
+import (
+	"errors"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// clientConf is the connection config shared by the source and the sink
+type clientConf struct {
+	URL               string            `json:"url"`
+	Token             string            `json:"token"`
+	ConnectionTimeout cast.DurationConf `json:"connectionTimeout"`
+	OperationTimeout  cast.DurationConf `json:"operationTimeout"`
+}
+
+func (c *clientConf) validate() error {
+	if c.URL == "" {
+		return errors.New("missing url")
+	}
+	if c.ConnectionTimeout < 0 || c.OperationTimeout < 0 {
+		return errors.New("connectionTimeout and operationTimeout must not be negative")
+	}
+	return nil
+}
+
+// newClient creates the pulsar client. The client reconnects the brokers automatically, and the producers and
+// consumers created by it are recreated after the reconnection.
+func (c *clientConf) newClient() (pulsar.Client, error) {
+	opts := pulsar.ClientOptions{
+		URL:               c.URL,
+		ConnectionTimeout: time.Duration(c.ConnectionTimeout),
+		OperationTimeout:  time.Duration(c.OperationTimeout),
+	}
+	if c.Token != "" {
+		opts.Authentication = pulsar.NewAuthenticationToken(c.Token)
+	}
+	return pulsar.NewClient(opts)
+}
+
+func defaultClientConf() clientConf {
+	return clientConf{
+		ConnectionTimeout: cast.DurationConf(5 * time.Second),
+		OperationTimeout:  cast.DurationConf(30 * time.Second),
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build pulsar
+
+package pulsar
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+// TestPulsarIntegration requires a running pulsar broker, such as the standalone one started by
+// `docker run -p 6650:6650 apachepulsar/pulsar bin/pulsar standalone`. The url is read from PULSAR_URL.
+func TestPulsarIntegration(t *testing.T) {
+	url := os.Getenv("PULSAR_URL")
+	if url == "" {
+		url = "pulsar://localhost:6650"
+	}
+	topic := fmt.Sprintf("ekuiper-test-%d", time.Now().UnixNano())
+	ctx, cancel := mockContext.NewMockContext("testPulsar", "op").WithCancel()
+	defer cancel()
+	sch := func(status string, message string) {
+		assert.Equal(t, api.ConnectionConnected, status, message)
+	}
+
+	src := GetSource()
+	require.NoError(t, src.Provision(ctx, map[string]any{
+		"url":             url,
+		"datasource":      topic,
+		"subscription":    "ekuiper",
+		"initialPosition": "earliest",
+	}))
+	require.NoError(t, src.Connect(ctx, sch))
+	defer src.Close(ctx)
+
+	sink := GetSink().(*pulsarSink)
+	require.NoError(t, sink.Provision(ctx, map[string]any{
+		"url":        url,
+		"topic":      topic,
+		"key":        "{{.id}}",
+		"properties": map[string]any{"source": "ekuiper"},
+	}))
+	require.NoError(t, sink.Connect(ctx, sch))
+	defer sink.Close(ctx)
+	require.NoError(t, sink.Collect(ctx, &testx.MockRawTuple{
+		Content:  []byte(`{"id":"a","temperature":20}`),
+		Template: map[string]string{"{{.id}}": "a"},
+	}))
+
+	received := make(chan map[string]any, 1)
+	go func() {
+		_ = src.(api.BytesSource).Subscribe(ctx, func(_ api.StreamContext, payload []byte, meta map[string]any, _ time.Time) {
+			meta["payload"] = string(payload)
+			received <- meta
+		}, func(_ api.StreamContext, err error) {
+			t.Log(err)
+		})
+	}()
+	select {
+	case meta := <-received:
+		assert.Equal(t, `{"id":"a","temperature":20}`, meta["payload"])
+		assert.Equal(t, "a", meta["key"])
+		assert.Equal(t, map[string]any{"source": "ekuiper"}, meta["properties"])
+	case <-time.After(30 * time.Second):
+		t.Fatal("receive timeout")
+	}
+}
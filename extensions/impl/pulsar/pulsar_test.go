@@ -0,0 +1,212 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pulsar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestProvision(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     api.Source
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no url",
+			props: map[string]any{"datasource": "t", "subscription": "s"},
+			err:   "missing url",
+		},
+		{
+			name:  "no topic",
+			props: map[string]any{"url": "pulsar://localhost:6650", "subscription": "s"},
+			err:   "missing topic, set it in the datasource of the stream",
+		},
+		{
+			name:  "no subscription",
+			props: map[string]any{"url": "pulsar://localhost:6650", "datasource": "t"},
+			err:   "missing subscription",
+		},
+		{
+			name:  "invalid subscription type",
+			props: map[string]any{"url": "pulsar://localhost:6650", "datasource": "t", "subscription": "s", "subscriptionType": "all"},
+			err:   "invalid subscriptionType all, must be exclusive, shared, failover or keyShared",
+		},
+		{
+			name:  "invalid initial position",
+			props: map[string]any{"url": "pulsar://localhost:6650", "datasource": "t", "subscription": "s", "initialPosition": "first"},
+			err:   "invalid initialPosition first, must be latest or earliest",
+		},
+	}
+	ctx := mockContext.NewMockContext("testProvision", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, GetSource().Provision(ctx, tt.props), tt.err)
+		})
+	}
+	s := &pulsarSource{}
+	require.NoError(t, s.Provision(ctx, map[string]any{"url": "pulsar://localhost:6650", "datasource": "t", "subscription": "s", "subscriptionType": "keyShared", "token": "abc"}))
+	assert.Equal(t, pulsar.KeyShared, s.sc.subType)
+	assert.Equal(t, pulsar.SubscriptionPositionLatest, s.sc.initialPosition)
+	assert.Equal(t, "abc", s.cc.Token)
+	assert.EqualError(t, GetSink().Provision(ctx, map[string]any{"url": "pulsar://localhost:6650"}), "missing topic")
+}
+
+type mockID struct {
+	pulsar.MessageID
+	id string
+}
+
+func (m mockID) String() string {
+	return m.id
+}
+
+type mockMessage struct {
+	pulsar.Message
+	id      string
+	payload []byte
+	key     string
+	props   map[string]string
+}
+
+func (m *mockMessage) Topic() string                 { return "persistent://public/default/demo" }
+func (m *mockMessage) Key() string                   { return m.key }
+func (m *mockMessage) ID() pulsar.MessageID          { return mockID{id: m.id} }
+func (m *mockMessage) Payload() []byte               { return m.payload }
+func (m *mockMessage) Properties() map[string]string { return m.props }
+func (m *mockMessage) PublishTime() time.Time        { return time.UnixMilli(1000) }
+func (m *mockMessage) EventTime() time.Time          { return time.Time{} }
+
+type mockConsumer struct {
+	msgs  chan pulsar.Message
+	acked []string
+}
+
+func (m *mockConsumer) Receive(ctx context.Context) (pulsar.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-m.msgs:
+		if msg == nil {
+			return nil, errors.New("connection closed")
+		}
+		return msg, nil
+	}
+}
+
+func (m *mockConsumer) Ack(msg pulsar.Message) error {
+	m.acked = append(m.acked, msg.ID().String())
+	return nil
+}
+
+func (m *mockConsumer) Close() {}
+
+func TestSubscribe(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("testSubscribe", "op").WithCancel()
+	mc := &mockConsumer{msgs: make(chan pulsar.Message, 3)}
+	mc.msgs <- &mockMessage{id: "1:0", payload: []byte(`{"a":1}`), key: "k1", props: map[string]string{"device": "d1"}}
+	mc.msgs <- nil
+	mc.msgs <- &mockMessage{id: "1:1", payload: []byte(`{"a":2}`)}
+	s := &pulsarSource{consumer: mc}
+	var (
+		payloads []string
+		metas    []map[string]any
+		errs     []string
+	)
+	done := make(chan struct{})
+	go func() {
+		_ = s.Subscribe(ctx, func(ctx api.StreamContext, payload []byte, meta map[string]any, ts time.Time) {
+			payloads = append(payloads, string(payload))
+			metas = append(metas, meta)
+			if len(payloads) == 2 {
+				cancel()
+			}
+		}, func(ctx api.StreamContext, err error) {
+			errs = append(errs, err.Error())
+		})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("subscribe timeout")
+	}
+	assert.Equal(t, []string{`{"a":1}`, `{"a":2}`}, payloads)
+	assert.Equal(t, []string{"pulsar source receive error: connection closed"}, errs)
+	assert.Equal(t, []string{"1:0", "1:1"}, mc.acked)
+	assert.Equal(t, map[string]any{
+		"topic":       "persistent://public/default/demo",
+		"key":         "k1",
+		"messageId":   "1:0",
+		"publishTime": int64(1000),
+		"properties":  map[string]any{"device": "d1"},
+	}, metas[0])
+}
+
+type mockProducer struct {
+	msgs []*pulsar.ProducerMessage
+	err  error
+}
+
+func (m *mockProducer) Send(_ context.Context, msg *pulsar.ProducerMessage) (pulsar.MessageID, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.msgs = append(m.msgs, msg)
+	return nil, nil
+}
+
+func (m *mockProducer) Close() {}
+
+func TestCollect(t *testing.T) {
+	ctx := mockContext.NewMockContext("testCollect", "op")
+	s := &pulsarSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":        "pulsar://localhost:6650",
+		"topic":      "demo",
+		"key":        "{{.id}}",
+		"properties": map[string]any{"source": "ekuiper", "device": "{{.device}}"},
+	}))
+	mp := &mockProducer{}
+	s.producer = mp
+	require.NoError(t, s.Collect(ctx, &testx.MockRawTuple{
+		Content:  []byte(`{"id":"a"}`),
+		Template: map[string]string{"{{.id}}": "a", "{{.device}}": "d1"},
+	}))
+	assert.Equal(t, []*pulsar.ProducerMessage{
+		{
+			Payload:    []byte(`{"id":"a"}`),
+			Key:        "a",
+			Properties: map[string]string{"source": "ekuiper", "device": "d1"},
+		},
+	}, mp.msgs)
+	s.producer = &mockProducer{err: errors.New("broker unavailable")}
+	err := s.Collect(ctx, &testx.MockRawTuple{Content: []byte(`{}`)})
+	assert.EqualError(t, err, "broker unavailable")
+	assert.True(t, errorx.IsIOError(err))
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pulsar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+// producer is the subset of the pulsar producer used by the sink
+type producer interface {
+	Send(ctx context.Context, msg *pulsar.ProducerMessage) (pulsar.MessageID, error)
+	Close()
+}
+
+type sinkConf struct {
+	Topic string `json:"topic"`
+	// Key and the values of Properties could be data templates
+	Key        string            `json:"key"`
+	Properties map[string]string `json:"properties"`
+}
+
+type pulsarSink struct {
+	cc       clientConf
+	sc       *sinkConf
+	client   pulsar.Client
+	producer producer
+}
+
+func (s *pulsarSink) Provision(_ api.StreamContext, configs map[string]any) error {
+	cc := defaultClientConf()
+	if err := cast.MapToStruct(configs, &cc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if err := cc.validate(); err != nil {
+		return err
+	}
+	sc := &sinkConf{}
+	if err := cast.MapToStruct(configs, sc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if sc.Topic == "" {
+		return errors.New("missing topic")
+	}
+	s.cc = cc
+	s.sc = sc
+	return nil
+}
+
+func (s *pulsarSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) (err error) {
+	defer func() {
+		if err != nil {
+			sch(api.ConnectionDisconnected, err.Error())
+		} else {
+			sch(api.ConnectionConnected, "")
+		}
+	}()
+	s.client, err = s.cc.newClient()
+	if err != nil {
+		return fmt.Errorf("pulsar sink fails to create client: %v", err)
+	}
+	s.producer, err = s.client.CreateProducer(pulsar.ProducerOptions{Topic: s.sc.Topic})
+	if err != nil {
+		return fmt.Errorf("pulsar sink fails to create producer of %s: %v", s.sc.Topic, err)
+	}
+	ctx.GetLogger().Infof("pulsar sink connected to topic %s", s.sc.Topic)
+	return nil
+}
+
+func (s *pulsarSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	msg := s.buildMsg(item)
+	if _, err := s.producer.Send(ctx, msg); err != nil {
+		ctx.GetLogger().Errorf("send to pulsar error %v", err)
+		return errorx.NewIOErr(err.Error())
+	}
+	return nil
+}
+
+// buildMsg renders the key and the properties by the data if they are templates
+func (s *pulsarSink) buildMsg(item api.RawTuple) *pulsar.ProducerMessage {
+	msg := &pulsar.ProducerMessage{Payload: item.Raw(), Key: s.sc.Key}
+	dp, hasDynamic := item.(api.HasDynamicProps)
+	if hasDynamic && s.sc.Key != "" {
+		if v, ok := dp.DynamicProps(s.sc.Key); ok {
+			msg.Key = v
+		}
+	}
+	if len(s.sc.Properties) > 0 {
+		msg.Properties = make(map[string]string, len(s.sc.Properties))
+		for k, tpl := range s.sc.Properties {
+			v := tpl
+			if hasDynamic {
+				if nv, ok := dp.DynamicProps(tpl); ok {
+					v = nv
+				}
+			}
+			msg.Properties[k] = v
+		}
+	}
+	return msg
+}
+
+func (s *pulsarSink) Close(ctx api.StreamContext) error {
+	if s.producer != nil {
+		s.producer.Close()
+	}
+	if s.client != nil {
+		s.client.Close()
+	}
+	ctx.GetLogger().Infof("pulsar sink closed")
+	return nil
+}
+
+func GetSink() api.Sink {
+	return &pulsarSink{}
+}
+
+var _ api.BytesCollector = &pulsarSink{}
@@ -0,0 +1,186 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pulsar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// consumer is the subset of the pulsar consumer used by the source
+type consumer interface {
+	Receive(ctx context.Context) (pulsar.Message, error)
+	Ack(msg pulsar.Message) error
+	Close()
+}
+
+type sourceConf struct {
+	Topic            string `json:"datasource"`
+	Subscription     string `json:"subscription"`
+	SubscriptionType string `json:"subscriptionType"`
+	// InitialPosition is where to start consuming if the subscription is new, latest or earliest
+	InitialPosition string `json:"initialPosition"`
+	subType         pulsar.SubscriptionType
+	initialPosition pulsar.SubscriptionInitialPosition
+}
+
+var subscriptionTypes = map[string]pulsar.SubscriptionType{
+	"exclusive": pulsar.Exclusive,
+	"shared":    pulsar.Shared,
+	"failover":  pulsar.Failover,
+	"keyshared": pulsar.KeyShared,
+}
+
+func (c *sourceConf) validate() error {
+	if c.Topic == "" {
+		return errors.New("missing topic, set it in the datasource of the stream")
+	}
+	if c.Subscription == "" {
+		return errors.New("missing subscription")
+	}
+	t, ok := subscriptionTypes[strings.ToLower(c.SubscriptionType)]
+	if !ok {
+		return fmt.Errorf("invalid subscriptionType %s, must be exclusive, shared, failover or keyShared", c.SubscriptionType)
+	}
+	c.subType = t
+	switch strings.ToLower(c.InitialPosition) {
+	case "latest":
+		c.initialPosition = pulsar.SubscriptionPositionLatest
+	case "earliest":
+		c.initialPosition = pulsar.SubscriptionPositionEarliest
+	default:
+		return fmt.Errorf("invalid initialPosition %s, must be latest or earliest", c.InitialPosition)
+	}
+	return nil
+}
+
+type pulsarSource struct {
+	cc       clientConf
+	sc       *sourceConf
+	client   pulsar.Client
+	consumer consumer
+}
+
+func (s *pulsarSource) Provision(_ api.StreamContext, configs map[string]any) error {
+	cc := defaultClientConf()
+	if err := cast.MapToStruct(configs, &cc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if err := cc.validate(); err != nil {
+		return err
+	}
+	sc := &sourceConf{
+		SubscriptionType: "shared",
+		InitialPosition:  "latest",
+	}
+	if err := cast.MapToStruct(configs, sc); err != nil {
+		return fmt.Errorf("read properties %v fail with error: %v", configs, err)
+	}
+	if err := sc.validate(); err != nil {
+		return err
+	}
+	s.cc = cc
+	s.sc = sc
+	return nil
+}
+
+func (s *pulsarSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) (err error) {
+	defer func() {
+		if err != nil {
+			sch(api.ConnectionDisconnected, err.Error())
+		} else {
+			sch(api.ConnectionConnected, "")
+		}
+	}()
+	s.client, err = s.cc.newClient()
+	if err != nil {
+		return fmt.Errorf("pulsar source fails to create client: %v", err)
+	}
+	s.consumer, err = s.client.Subscribe(pulsar.ConsumerOptions{
+		Topic:                       s.sc.Topic,
+		SubscriptionName:            s.sc.Subscription,
+		Type:                        s.sc.subType,
+		SubscriptionInitialPosition: s.sc.initialPosition,
+	})
+	if err != nil {
+		return fmt.Errorf("pulsar source fails to subscribe %s: %v", s.sc.Topic, err)
+	}
+	ctx.GetLogger().Infof("pulsar source subscribed topic %s with subscription %s", s.sc.Topic, s.sc.Subscription)
+	return nil
+}
+
+// Subscribe receives the messages until the rule stops. The message is acknowledged after it is ingested, so the
+// message not processed yet is redelivered after the restart.
+func (s *pulsarSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
+	for {
+		msg, err := s.consumer.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			ingestError(ctx, fmt.Errorf("pulsar source receive error: %v", err))
+			continue
+		}
+		ingest(ctx, msg.Payload(), msgMeta(msg), timex.GetNow())
+		if err := s.consumer.Ack(msg); err != nil {
+			ctx.GetLogger().Warnf("pulsar source fails to ack message %s: %v", msg.ID(), err)
+		}
+	}
+}
+
+func msgMeta(msg pulsar.Message) map[string]any {
+	meta := map[string]any{
+		"topic":       msg.Topic(),
+		"key":         msg.Key(),
+		"messageId":   msg.ID().String(),
+		"publishTime": msg.PublishTime().UnixMilli(),
+	}
+	if !msg.EventTime().IsZero() {
+		meta["eventTime"] = msg.EventTime().UnixMilli()
+	}
+	if props := msg.Properties(); len(props) > 0 {
+		p := make(map[string]any, len(props))
+		for k, v := range props {
+			p[k] = v
+		}
+		meta["properties"] = p
+	}
+	return meta
+}
+
+func (s *pulsarSource) Close(ctx api.StreamContext) error {
+	if s.consumer != nil {
+		s.consumer.Close()
+	}
+	if s.client != nil {
+		s.client.Close()
+	}
+	ctx.GetLogger().Infof("pulsar source closed")
+	return nil
+}
+
+func GetSource() api.Source {
+	return &pulsarSource{}
+}
+
+var _ api.BytesSource = &pulsarSource{}
@@ -45,6 +45,9 @@ type SQLSinkConnector struct {
 	conn          *client.SQLConnection
 	props         map[string]any
 	needReconnect bool
+	// insertPrefix and insertSuffix wrap the insert statement to ignore the duplicate rows in idempotent mode
+	insertPrefix string
+	insertSuffix string
 }
 
 type sqlSinkConfig struct {
@@ -53,6 +56,12 @@ type sqlSinkConfig struct {
 	Fields       []string `json:"fields"`
 	RowKindField string   `json:"rowKindField"`
 	KeyField     string   `json:"keyField"`
+	// Idempotent ignores the inserted row which violates the unique constraint of the dedup column
+	Idempotent bool `json:"idempotent"`
+	// DedupField is the column with the unique constraint. If not set, the hash of the tuple is written to the
+	// DedupColumn which should have the unique constraint
+	DedupField  string `json:"dedupField"`
+	DedupColumn string `json:"dedupColumn"`
 }
 
 func (c *sqlSinkConfig) buildInsertSql(ctx api.StreamContext, mapData map[string]interface{}) ([]string, string, error) {
@@ -129,11 +138,67 @@ func (s *SQLSinkConnector) Provision(ctx api.StreamContext, configs map[string]a
 	if c.RowKindField != "" && c.KeyField == "" {
 		return fmt.Errorf("keyField is required when rowKindField is set")
 	}
+	if c.Idempotent {
+		if err = s.initIdempotent(c); err != nil {
+			return err
+		}
+	}
 	s.config = c
 	s.props = configs
 	return nil
 }
 
+// initIdempotent sets the insert statement to skip the duplicate rows by the unique constraint. A replayed tuple
+// has the same dedup value, so it will not be inserted twice.
+func (s *SQLSinkConnector) initIdempotent(c *sqlSinkConfig) error {
+	driver, err := client.ParseDriver(c.DBUrl)
+	if err != nil {
+		return err
+	}
+	if c.DedupField == "" {
+		if c.DedupColumn == "" {
+			c.DedupColumn = "dedup_key"
+		}
+		if len(c.Fields) > 0 {
+			c.Fields = append(c.Fields, c.DedupColumn)
+		}
+	}
+	// Only the duplicate key conflict is skipped, other errors like truncation are still returned
+	switch strings.ToLower(driver) {
+	case "mysql":
+		col := c.DedupField
+		if col == "" {
+			col = c.DedupColumn
+		}
+		s.insertPrefix, s.insertSuffix = "INSERT INTO", fmt.Sprintf(" ON DUPLICATE KEY UPDATE %[1]s=%[1]s", col)
+	case "postgres", "sqlite", "sqlite3":
+		s.insertPrefix, s.insertSuffix = "INSERT INTO", " ON CONFLICT DO NOTHING"
+	default:
+		return fmt.Errorf("idempotent is not supported for %s", driver)
+	}
+	return nil
+}
+
+// withDedup adds the hash of the tuple as the dedup column if the dedup field is not set
+func (s *SQLSinkConnector) withDedup(data map[string]any) (map[string]any, error) {
+	if !s.config.Idempotent {
+		return data, nil
+	}
+	id, err := util.IdempotencyKey(data, s.config.DedupField)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.DedupField != "" {
+		return data, nil
+	}
+	result := make(map[string]any, len(data)+1)
+	for k, v := range data {
+		result[k] = v
+	}
+	result[s.config.DedupColumn] = id
+	return result, nil
+}
+
 func (s *SQLSinkConnector) Connect(ctx api.StreamContext, sc api.StatusChangeHandler) error {
 	ctx.GetLogger().Infof("Connecting to sql server")
 	var err error
@@ -174,13 +239,17 @@ func (s *SQLSinkConnector) collect(ctx api.StreamContext, item map[string]any) (
 		var keys []string = nil
 		var values []string = nil
 		var vars string
+		item, err = s.withDedup(item)
+		if err != nil {
+			return err
+		}
 		keys, vars, err = s.config.buildInsertSql(ctx, item)
 		if err != nil {
 			return err
 		}
 		values = append(values, vars)
 		if keys != nil {
-			sqlStr := buildInsertSQL(s.config.Table, keys, values)
+			sqlStr := s.buildInsertSQL(s.config.Table, keys, values)
 			return s.writeToDB(ctx, sqlStr)
 		}
 		return nil
@@ -204,6 +273,10 @@ func (s *SQLSinkConnector) collectList(ctx api.StreamContext, items []map[string
 	var vars string
 	if len(s.config.RowKindField) < 1 {
 		for _, mapData := range items {
+			mapData, err = s.withDedup(mapData)
+			if err != nil {
+				return err
+			}
 			keys, vars, err = s.config.buildInsertSql(ctx, mapData)
 			if err != nil {
 				return err
@@ -211,7 +284,7 @@ func (s *SQLSinkConnector) collectList(ctx api.StreamContext, items []map[string
 			values = append(values, vars)
 		}
 		if keys != nil {
-			sqlStr := buildInsertSQL(s.config.Table, keys, values)
+			sqlStr := s.buildInsertSQL(s.config.Table, keys, values)
 			return s.writeToDB(ctx, sqlStr)
 		}
 		return nil
@@ -241,13 +314,17 @@ func (s *SQLSinkConnector) save(ctx api.StreamContext, table string, data map[st
 	var sqlStr string
 	switch rowkind {
 	case ast.RowkindInsert:
+		data, err := s.withDedup(data)
+		if err != nil {
+			return err
+		}
 		keys, vars, err := s.config.buildInsertSql(ctx, data)
 		if err != nil {
 			return err
 		}
 		values := []string{vars}
 		if keys != nil {
-			sqlStr = buildInsertSQL(table, keys, values)
+			sqlStr = s.buildInsertSQL(table, keys, values)
 		}
 	case ast.RowkindUpdate:
 		keyval, ok := data[s.config.KeyField]
@@ -319,6 +396,13 @@ func buildInsertSQL(table string, keys []string, values []string) string {
 	return sql
 }
 
+func (s *SQLSinkConnector) buildInsertSQL(table string, keys []string, values []string) string {
+	if s.insertPrefix == "" {
+		return buildInsertSQL(table, keys, values)
+	}
+	return fmt.Sprintf("%s %s (%s) values ", s.insertPrefix, table, strings.Join(keys, ",")) + strings.Join(values, ",") + s.insertSuffix + ";"
+}
+
 func GetSink() api.Sink {
 	return &SQLSinkConnector{}
 }
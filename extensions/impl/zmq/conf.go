@@ -16,20 +16,39 @@ package zmq
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
+const (
+	patternSub  = "sub"
+	patternPull = "pull"
+	patternPub  = "pub"
+	patternPush = "push"
+
+	modeBind    = "bind"
+	modeConnect = "connect"
+)
+
 type c struct {
 	Topic  string `json:"datasource"`
 	Server string `json:"server"`
 	Topic2 string `json:"topic"`
+	// Pattern is the socket type. The source supports sub and pull, the sink supports pub and push
+	Pattern string `json:"pattern"`
+	// Mode is whether to bind the socket to the server address or connect to it
+	Mode string `json:"mode"`
+	// Hwm is the high water mark of the queued messages. 0 means the zmq default
+	Hwm int `json:"hwm"`
 }
 
-func validate(_ api.StreamContext, props map[string]any) (*c, error) {
-	sc := &c{}
+// validate reads the props with the default pattern and mode. The source connects to a sub socket and the sink binds
+// a pub socket by default.
+func validate(_ api.StreamContext, props map[string]any, pattern string, mode string, patterns ...string) (*c, error) {
+	sc := &c{Pattern: pattern, Mode: mode}
 	err := cast.MapToStruct(props, sc)
 	if err != nil {
 		return nil, err
@@ -37,5 +56,21 @@ func validate(_ api.StreamContext, props map[string]any) (*c, error) {
 	if sc.Server == "" {
 		return nil, errors.New("missing server address")
 	}
+	valid := false
+	for _, p := range patterns {
+		if sc.Pattern == p {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid pattern %s, must be one of %v", sc.Pattern, patterns)
+	}
+	if sc.Mode != modeBind && sc.Mode != modeConnect {
+		return nil, fmt.Errorf("invalid mode %s, must be bind or connect", sc.Mode)
+	}
+	if sc.Hwm < 0 {
+		return nil, errors.New("hwm must not be negative")
+	}
 	return sc, nil
 }
@@ -34,10 +34,51 @@ func TestValidate(t *testing.T) {
 				"datasource": "t1",
 			},
 			c: &c{
-				Server: "tcp://127.0.0.1:5563",
-				Topic:  "t1",
+				Server:  "tcp://127.0.0.1:5563",
+				Topic:   "t1",
+				Pattern: patternSub,
+				Mode:    modeConnect,
 			},
 		},
+		{
+			n: "pull bind",
+			p: map[string]any{
+				"server":  "tcp://*:5563",
+				"pattern": "pull",
+				"mode":    "bind",
+				"hwm":     100,
+			},
+			c: &c{
+				Server:  "tcp://*:5563",
+				Pattern: patternPull,
+				Mode:    modeBind,
+				Hwm:     100,
+			},
+		},
+		{
+			n: "invalid pattern",
+			p: map[string]any{
+				"server":  "tcp://127.0.0.1:5563",
+				"pattern": "push",
+			},
+			e: "invalid pattern push, must be one of [sub pull]",
+		},
+		{
+			n: "invalid mode",
+			p: map[string]any{
+				"server": "tcp://127.0.0.1:5563",
+				"mode":   "listen",
+			},
+			e: "invalid mode listen, must be bind or connect",
+		},
+		{
+			n: "invalid hwm",
+			p: map[string]any{
+				"server": "tcp://127.0.0.1:5563",
+				"hwm":    -1,
+			},
+			e: "hwm must not be negative",
+		},
 		{
 			n: "wrong type",
 			p: map[string]any{
@@ -54,7 +95,7 @@ func TestValidate(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.n, func(t *testing.T) {
-			r, err := validate(nil, test.p)
+			r, err := validate(nil, test.p, patternSub, modeConnect, patternSub, patternPull)
 			if test.e != "" {
 				assert.EqualError(t, err, test.e)
 			} else {
@@ -17,7 +17,9 @@
 package zmq
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	zmq "github.com/pebbe/zmq4"
@@ -25,16 +27,24 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 )
 
+// sendTimeout is the time to wait when the push socket has no peer or reaches the high water mark. The message is
+// kept for the sink retry or cache after the timeout. It is also the time to flush the pending messages when closing.
+const sendTimeout = time.Second
+
 type zmqSink struct {
 	publisher *zmq.Socket
+	zctx      *zmq.Context
 	sc        *c
 }
 
 func (m *zmqSink) Provision(ctx api.StreamContext, configs map[string]any) error {
-	sc, err := validate(ctx, configs)
+	sc, err := validate(ctx, configs, patternPub, modeBind, patternPub, patternPush)
 	if err != nil {
 		return err
 	}
+	if sc.Pattern == patternPush && sc.Topic2 != "" {
+		return errors.New("topic is only supported by the pub pattern")
+	}
 	m.sc = sc
 	return nil
 }
@@ -47,13 +57,39 @@ func (m *zmqSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) (e
 			sch(api.ConnectionConnected, "")
 		}
 	}()
-	m.publisher, err = zmq.NewSocket(zmq.PUB)
+	m.zctx, err = zmq.NewContext()
+	if err != nil {
+		return fmt.Errorf("zmq sink fails to create context: %v", err)
+	}
+	t := zmq.PUB
+	if m.sc.Pattern == patternPush {
+		t = zmq.PUSH
+	}
+	m.publisher, err = m.zctx.NewSocket(t)
 	if err != nil {
 		return fmt.Errorf("zmq sink fails to create socket: %v", err)
 	}
-	err = m.publisher.Bind(m.sc.Server)
+	err = m.publisher.SetLinger(sendTimeout)
+	if err != nil {
+		return fmt.Errorf("zmq sink fails to set linger: %v", err)
+	}
+	err = m.publisher.SetSndtimeo(sendTimeout)
 	if err != nil {
-		return fmt.Errorf("zmq sink fails to bind to %s: %v", m.sc.Server, err)
+		return fmt.Errorf("zmq sink fails to set send timeout: %v", err)
+	}
+	if m.sc.Hwm > 0 {
+		err = m.publisher.SetSndhwm(m.sc.Hwm)
+		if err != nil {
+			return fmt.Errorf("zmq sink fails to set hwm: %v", err)
+		}
+	}
+	if m.sc.Mode == modeConnect {
+		err = m.publisher.Connect(m.sc.Server)
+	} else {
+		err = m.publisher.Bind(m.sc.Server)
+	}
+	if err != nil {
+		return fmt.Errorf("zmq sink fails to %s to %s: %v", m.sc.Mode, m.sc.Server, err)
 	}
 	ctx.GetLogger().Debugf("zmq sink open")
 	return nil
@@ -63,13 +99,14 @@ func (m *zmqSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 	return m.sendToZmq(ctx, item.Raw())
 }
 
+// sendToZmq sends the topic as the first frame so that the subscribers can filter by the topic prefix
 func (m *zmqSink) sendToZmq(ctx api.StreamContext, v []byte) error {
 	var err error
 	if m.sc.Topic2 == "" {
 		_, err = m.publisher.SendBytes(v, 0)
 	} else {
 		msgs := [][]byte{
-			[]byte(m.sc.Topic),
+			[]byte(m.sc.Topic2),
 			v,
 		}
 		_, err = m.publisher.SendMessage(msgs)
@@ -81,10 +118,14 @@ func (m *zmqSink) sendToZmq(ctx api.StreamContext, v []byte) error {
 	return nil
 }
 
-func (m *zmqSink) Close(_ api.StreamContext) error {
+func (m *zmqSink) Close(ctx api.StreamContext) error {
 	if m.publisher != nil {
-		return m.publisher.Close()
+		_ = m.publisher.Close()
+	}
+	if m.zctx != nil {
+		_ = m.zctx.Term()
 	}
+	ctx.GetLogger().Infof("zmq sink closed")
 	return nil
 }
 
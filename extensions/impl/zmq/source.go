@@ -32,10 +32,12 @@ type zmqSource struct {
 	subscriber *zmq.Socket
 	zctx       *zmq.Context
 	sc         *c
+	// done is closed when the receiving goroutine exits, so the socket can be closed safely
+	done chan struct{}
 }
 
 func (s *zmqSource) Provision(ctx api.StreamContext, configs map[string]any) error {
-	sc, err := validate(ctx, configs)
+	sc, err := validate(ctx, configs, patternSub, modeConnect, patternSub, patternPull)
 	if err != nil {
 		return err
 	}
@@ -58,55 +60,76 @@ func (s *zmqSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler)
 		return fmt.Errorf("zmq source fails to create context: %v", err)
 	}
 	s.zctx = zctx
-	s.subscriber, err = zctx.NewSocket(zmq.SUB)
+	t := zmq.SUB
+	if s.sc.Pattern == patternPull {
+		t = zmq.PULL
+	}
+	s.subscriber, err = zctx.NewSocket(t)
 	if err != nil {
 		return fmt.Errorf("zmq source fails to create socket: %v", err)
 	}
-	err = s.subscriber.Connect(s.sc.Server)
+	// Drop the pending messages when closing, otherwise the context termination blocks
+	err = s.subscriber.SetLinger(0)
+	if err != nil {
+		return fmt.Errorf("zmq source fails to set linger: %v", err)
+	}
+	if s.sc.Hwm > 0 {
+		err = s.subscriber.SetRcvhwm(s.sc.Hwm)
+		if err != nil {
+			return fmt.Errorf("zmq source fails to set hwm: %v", err)
+		}
+	}
+	if s.sc.Mode == modeBind {
+		err = s.subscriber.Bind(s.sc.Server)
+	} else {
+		err = s.subscriber.Connect(s.sc.Server)
+	}
 	if err != nil {
-		return fmt.Errorf("zmq source fails to connect to %s: %v", s.sc.Server, err)
+		return fmt.Errorf("zmq source fails to %s to %s: %v", s.sc.Mode, s.sc.Server, err)
 	}
 	return nil
 }
 
 func (s *zmqSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
-	ctx.GetLogger().Debugf("zmq source subscribe to topic %s", s.sc.Topic)
-	err := s.subscriber.SetSubscribe(s.sc.Topic)
-	if err != nil {
-		return err
+	withTopic := s.sc.Pattern == patternSub && s.sc.Topic != ""
+	if s.sc.Pattern == patternSub {
+		ctx.GetLogger().Debugf("zmq source subscribe to topic %s", s.sc.Topic)
+		err := s.subscriber.SetSubscribe(s.sc.Topic)
+		if err != nil {
+			return err
+		}
 	}
-	err = s.subscriber.SetRcvtimeo(time.Second)
+	err := s.subscriber.SetRcvtimeo(time.Second)
 	if err != nil {
 		return err
 	}
+	s.done = make(chan struct{})
 	go infra.SafeRun(func() error {
+		defer close(s.done)
 		for {
 			msgs, e := s.subscriber.RecvMessageBytes(0)
 			if e != nil {
-				if zmq.AsErrno(e) == zmq.Errno(syscall.EAGAIN) {
-					continue
+				if zmq.AsErrno(e) != zmq.Errno(syscall.EAGAIN) {
+					id, _ := s.subscriber.GetIdentity()
+					ingestError(ctx, fmt.Errorf("zmq source getting message %s error: %v", id, zmq.AsErrno(e)))
 				}
-				id, _ := s.subscriber.GetIdentity()
-				ingestError(ctx, fmt.Errorf("zmq source getting message %s error: %v", id, zmq.AsErrno(e)))
 			} else {
 				rcvTime := timex.GetNow()
 				var m []byte
 				for i, msg := range msgs {
-					if i == 0 && s.sc.Topic != "" {
+					if i == 0 && withTopic {
 						continue
 					}
 					m = append(m, msg...)
 				}
 				meta := make(map[string]any)
-				if s.sc.Topic != "" {
+				if withTopic {
 					meta["topic"] = string(msgs[0])
 				}
 				ingest(ctx, m, meta, rcvTime)
 			}
 			select {
 			case <-ctx.Done():
-				s.subscriber.Close()
-				s.zctx.Term()
 				return nil
 			default:
 			}
@@ -115,7 +138,19 @@ func (s *zmqSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ing
 	return nil
 }
 
-func (s *zmqSource) Close(_ api.StreamContext) error {
+// Close is called after the rule stops. It waits for the receiving goroutine to exit because the socket is not
+// thread safe.
+func (s *zmqSource) Close(ctx api.StreamContext) error {
+	if s.done != nil {
+		<-s.done
+	}
+	if s.subscriber != nil {
+		_ = s.subscriber.Close()
+	}
+	if s.zctx != nil {
+		_ = s.zctx.Term()
+	}
+	ctx.GetLogger().Infof("zmq source closed")
 	return nil
 }
 
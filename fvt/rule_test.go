@@ -33,7 +33,7 @@ func TestRuleSuite(t *testing.T) {
 
 func (s *RuleTestSuite) TestRuleDisableBufferFullDiscard() {
 	topic := "test1"
-	subCh := pubsub.CreateSub(topic, nil, topic, 1024)
+	subCh := pubsub.CreateSub(topic, topic, 1024)
 	defer pubsub.CloseSourceConsumerChannel(topic, topic)
 	data := []map[string]any{
 		{
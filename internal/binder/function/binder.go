@@ -23,6 +23,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/binder"
 	"github.com/lf-edge/ekuiper/v2/internal/plugin"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 )
 
 var ( // init once and read only
@@ -61,12 +62,24 @@ func Function(name string) (api.Function, error) {
 			errs = errors.Join(errs, fmt.Errorf("%s:%v", funcFactoriesNames[i], err))
 		}
 		if r != nil {
+			if af, ok := r.(modules.AggregateFunction); ok && af.IsAggregate() {
+				return &aggregateExecutor{AggregateFunction: af}, errs
+			}
 			return r, errs
 		}
 	}
 	return nil, errs
 }
 
+// aggregateExecutor drives the user defined aggregate function by its state contract for each group
+type aggregateExecutor struct {
+	modules.AggregateFunction
+}
+
+func (a *aggregateExecutor) Exec(ctx api.FunctionContext, args []any) (any, bool) {
+	return modules.ExecAggregate(ctx, a.AggregateFunction, args)
+}
+
 func GetFunctionPlugin(name string) (plugin.EXTENSION_TYPE, string, string) {
 	for _, sf := range funcFactories {
 		t, s1, s2 := sf.FunctionPluginInfo(name)
@@ -19,9 +19,19 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/lf-edge/ekuiper/v2/internal/binder"
 	"github.com/lf-edge/ekuiper/v2/internal/binder/mock"
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 )
 
 func TestBinding(t *testing.T) {
@@ -160,3 +170,96 @@ func TestFunction(t *testing.T) {
 		})
 	}
 }
+
+// weightedSumFunc sums the first argument multiplied by the second argument by the aggregate state contract
+type weightedSumFunc struct{}
+
+func (f *weightedSumFunc) Validate(_ []any) error {
+	return nil
+}
+
+func (f *weightedSumFunc) Exec(_ api.FunctionContext, _ []any) (any, bool) {
+	return errors.New("should be executed by the state contract"), false
+}
+
+func (f *weightedSumFunc) IsAggregate() bool {
+	return true
+}
+
+func (f *weightedSumFunc) Init(_ api.FunctionContext) (any, error) {
+	return float64(0), nil
+}
+
+func (f *weightedSumFunc) Accumulate(_ api.FunctionContext, state any, args []any) (any, error) {
+	if args[0] == nil {
+		return state, nil
+	}
+	v, err := cast.ToFloat64(args[0], cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, err
+	}
+	w, err := cast.ToFloat64(args[1], cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return nil, err
+	}
+	return state.(float64) + v*w, nil
+}
+
+func (f *weightedSumFunc) Merge(_ api.FunctionContext, state1 any, state2 any) (any, error) {
+	return state1.(float64) + state2.(float64), nil
+}
+
+func (f *weightedSumFunc) Finalize(_ api.FunctionContext, state any) (any, error) {
+	return state, nil
+}
+
+func TestAggregateFunction(t *testing.T) {
+	modules.RegisterFunc("weighted_sum", func() api.Function {
+		return &weightedSumFunc{}
+	})
+	f, err := Function("weighted_sum")
+	require.NoError(t, err)
+	assert.True(t, f.IsAggregate())
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 1)
+	tests := []struct {
+		name   string
+		args   []any
+		result any
+		ok     bool
+	}{
+		{
+			name:   "rows",
+			args:   []any{[]any{1, 2, 3}, []any{1.0, 0.5, 2.0}},
+			result: 8.0,
+			ok:     true,
+		},
+		{
+			name:   "constant arg",
+			args:   []any{[]any{1, nil, 3}, 2},
+			result: 8.0,
+			ok:     true,
+		},
+		{
+			name:   "empty",
+			args:   []any{[]any{}, 2},
+			result: 0.0,
+			ok:     true,
+		},
+		{
+			name:   "accumulate error",
+			args:   []any{[]any{1, "a"}, 2},
+			result: errors.New("cannot convert string(a) to float64"),
+			ok:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := f.Exec(fctx, tt.args)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.result, r)
+		})
+	}
+}
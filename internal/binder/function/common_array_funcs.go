@@ -15,6 +15,7 @@
 package function
 
 import (
+	"container/list"
 	"fmt"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
@@ -246,6 +247,29 @@ func sliceStringMin(s []interface{}, min string) (string, error) {
 	return min, nil
 }
 
+// dedupLast returns the rows in order whose key is not one of the last n distinct keys seen. A key is seen again when a
+// duplicate arrives, so the most recent keys are kept. If n is -1, all the keys are kept.
+func dedupLast(r []interface{}, col []interface{}, n int) []interface{} {
+	seen := make(map[string]*list.Element)
+	recent := list.New()
+	result := make([]interface{}, 0)
+	for i, m := range col {
+		key := fmt.Sprintf("%v", m)
+		if e, ok := seen[key]; ok {
+			recent.MoveToBack(e)
+			continue
+		}
+		result = append(result, r[i])
+		seen[key] = recent.PushBack(key)
+		if n > 0 && recent.Len() > n {
+			oldest := recent.Front()
+			recent.Remove(oldest)
+			delete(seen, oldest.Value.(string))
+		}
+	}
+	return result
+}
+
 func dedup(r []interface{}, col []interface{}, all bool) (interface{}, error) {
 	keyset := make(map[string]bool)
 	result := make([]interface{}, 0)
@@ -16,6 +16,8 @@ package function
 
 import (
 	"fmt"
+	"math"
+	"sort"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/montanaflynn/stats"
@@ -113,16 +115,47 @@ func registerAggFunc() {
 		val:   ValidateOneNumberArg,
 		check: returnNilIfHasAnyNil,
 	}
-	builtins["collect"] = builtinFunc{
+	collectFunc := builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
-			if len(args) > 0 {
+			if len(args) == 0 {
+				return make([]interface{}, 0), true
+			}
+			if len(args) < 2 {
 				return args[0], true
 			}
-			return make([]interface{}, 0), true
+			arg0, ok := args[0].([]interface{})
+			if !ok {
+				return args[0], true
+			}
+			args1, ok := args[1].([]interface{})
+			if !ok {
+				return fmt.Errorf("the second argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[1]), false
+			}
+			ignoreNull, _ := getFirstValidArg(args1).(bool)
+			if !ignoreNull {
+				return arg0, true
+			}
+			result := make([]interface{}, 0, len(arg0))
+			for _, v := range arg0 {
+				if v != nil {
+					result = append(result, v)
+				}
+			}
+			return result, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 {
+				return ValidateOneArg(ctx, args)
+			}
+			if !ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "bool")
+			}
+			return nil
 		},
-		val: ValidateOneArg,
 	}
+	builtins["collect"] = collectFunc
+	builtins["array_agg"] = collectFunc
 	builtins["merge_agg"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -175,6 +208,35 @@ func registerAggFunc() {
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["dedup"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v1, ok1 := args[0].([]interface{})
+			v2, ok2 := args[1].([]interface{})
+			v3a, ok3 := args[2].([]interface{})
+			if ok1 && ok2 && ok3 && len(v3a) > 0 {
+				n, err := cast.ToInt(getFirstValidArg(v3a), cast.STRICT)
+				if err != nil {
+					return err, false
+				}
+				return dedupLast(v1, v2, n), true
+			}
+			return fmt.Errorf("Invalid argument type found."), false
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if !ast.IsIntegerArg(args[1]) {
+				return ProduceErrInfo(1, "int")
+			}
+			if n, ok := args[1].(*ast.IntegerLiteral); ok && n.Val != -1 && n.Val <= 0 {
+				return fmt.Errorf("the second parameter of dedup must be a positive integer or -1, but got %d", n.Val)
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["stddev"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -270,75 +332,50 @@ func registerAggFunc() {
 	builtins["percentile_cont"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
-			if err := ValidateLen(2, len(args)); err != nil {
-				return err, false
-			}
-			var arg1Float64 float64 = 1
-			arg0 := args[0].([]interface{})
-			arg1 := args[1].([]interface{})
-			if len(arg1) > 0 {
-				v1 := getFirstValidArg(arg1)
-				val, err := cast.ToFloat64(v1, cast.CONVERT_SAMEKIND)
-				if err != nil {
-					return fmt.Errorf("the second parameter requires float64 but found %[1]T(%[1]v)", arg1), false
-				}
-				arg1Float64 = val
-			}
-
-			if len(arg0) > 0 {
-				float64Slice, err := cast.ToFloat64Slice(arg0, cast.CONVERT_SAMEKIND, cast.IGNORE_NIL)
-				if err != nil {
-					return fmt.Errorf("requires float64 slice but found %[1]T(%[1]v)", arg0), false
-				}
-				deviation, err := stats.Percentile(float64Slice, arg1Float64*100)
-				if err != nil {
-					if err == stats.EmptyInputErr {
-						return nil, true
-					}
-					return fmt.Errorf("percentile exec with error: %v", err), false
-				}
-				return deviation, true
-			}
-			return nil, true
+			return percentileExec(args, percentileCont)
 		},
-		val:   ValidateTwoNumberArg,
+		val:   validatePercentile,
 		check: returnNilIfHasAnyNil,
 	}
 	builtins["percentile_disc"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
-			if err := ValidateLen(2, len(args)); err != nil {
-				return err, false
+			return percentileExec(args, percentileDisc)
+		},
+		val:   validatePercentile,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["approx_count_distinct"] = builtinFunc{
+		fType: ast.FuncTypeAgg,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0, ok := args[0].([]interface{})
+			if !ok {
+				return fmt.Errorf("the first argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[0]), false
 			}
-			var arg1Float64 float64 = 1
-			arg0 := args[0].([]interface{})
-			arg1 := args[1].([]interface{})
-			if len(arg1) > 0 {
-				v1 := getFirstValidArg(arg1)
-				val, err := cast.ToFloat64(v1, cast.CONVERT_SAMEKIND)
-				if err != nil {
-					return fmt.Errorf("the second parameter requires float64 but found %[1]T(%[1]v)", arg1), false
+			precision := defaultHllPrecision
+			if len(args) > 1 {
+				args1, ok := args[1].([]interface{})
+				if !ok {
+					return fmt.Errorf("the second argument to the aggregate function should be []interface but found %[1]T(%[1]v)", args[1]), false
 				}
-				arg1Float64 = val
-			}
-			if len(arg0) > 0 {
-				float64Slice, err := cast.ToFloat64Slice(arg0, cast.CONVERT_SAMEKIND, cast.IGNORE_NIL)
+				p, err := cast.ToInt(getFirstValidArg(args1), cast.CONVERT_SAMEKIND)
 				if err != nil {
-					return fmt.Errorf("requires float64 slice but found %[1]T(%[1]v)", arg0), false
+					return fmt.Errorf("the second parameter requires int but found %[1]T(%[1]v)", getFirstValidArg(args1)), false
 				}
-				deviation, err := stats.PercentileNearestRank(float64Slice, arg1Float64*100)
-				if err != nil {
-					if err == stats.EmptyInputErr {
-						return nil, true
-					}
-					return fmt.Errorf("PopulationVariance exec with error: %v", err), false
+				precision = p
+			}
+			h, err := newHll(precision)
+			if err != nil {
+				return err, false
+			}
+			for _, v := range arg0 {
+				if v != nil {
+					h.add(v)
 				}
-				return deviation, true
 			}
-			return nil, true
+			return h.count(), true
 		},
-		val:   ValidateTwoNumberArg,
-		check: returnNilIfHasAnyNil,
+		val: validateApproxCountDistinct,
 	}
 	builtins["last_value"] = builtinFunc{
 		fType: ast.FuncTypeAgg,
@@ -379,3 +416,91 @@ func registerAggFunc() {
 		check: returnNilIfHasAnyNil,
 	}
 }
+
+// validatePercentile validates the percentile literal at plan time
+func validatePercentile(ctx api.FunctionContext, args []ast.Expr) error {
+	if err := ValidateTwoNumberArg(ctx, args); err != nil {
+		return err
+	}
+	var p float64
+	switch v := args[1].(type) {
+	case *ast.NumberLiteral:
+		p = v.Val
+	case *ast.IntegerLiteral:
+		p = float64(v.Val)
+	default:
+		return nil
+	}
+	if p < 0 || p > 1 {
+		return fmt.Errorf("the percentile must be within [0, 1], but got %v", p)
+	}
+	return nil
+}
+
+func percentileExec(args []interface{}, f func(sorted []float64, p float64) float64) (interface{}, bool) {
+	if err := ValidateLen(2, len(args)); err != nil {
+		return err, false
+	}
+	var p float64 = 1
+	arg0 := args[0].([]interface{})
+	arg1 := args[1].([]interface{})
+	if len(arg1) > 0 {
+		v1 := getFirstValidArg(arg1)
+		val, err := cast.ToFloat64(v1, cast.CONVERT_SAMEKIND)
+		if err != nil {
+			return fmt.Errorf("the second parameter requires float64 but found %[1]T(%[1]v)", arg1), false
+		}
+		p = val
+	}
+	if p < 0 || p > 1 {
+		return fmt.Errorf("the percentile must be within [0, 1], but got %v", p), false
+	}
+	if len(arg0) == 0 {
+		return nil, true
+	}
+	float64Slice, err := cast.ToFloat64Slice(arg0, cast.CONVERT_SAMEKIND, cast.IGNORE_NIL)
+	if err != nil {
+		return fmt.Errorf("requires float64 slice but found %[1]T(%[1]v)", arg0), false
+	}
+	if len(float64Slice) == 0 {
+		return nil, true
+	}
+	sort.Float64s(float64Slice)
+	return f(float64Slice, p), true
+}
+
+// percentileCont interpolates linearly between the two nearest values of the rank p*(n-1)
+func percentileCont(sorted []float64, p float64) float64 {
+	rn := p * float64(len(sorted)-1)
+	lo := math.Floor(rn)
+	hi := math.Ceil(rn)
+	if lo == hi {
+		return sorted[int(lo)]
+	}
+	return sorted[int(lo)] + (rn-lo)*(sorted[int(hi)]-sorted[int(lo)])
+}
+
+// percentileDisc returns the first value whose cumulative distribution is not less than p
+func percentileDisc(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func validateApproxCountDistinct(_ api.FunctionContext, args []ast.Expr) error {
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("Expect 1 or 2 arguments but found %d.", len(args))
+	}
+	if len(args) == 2 {
+		p, ok := args[1].(*ast.IntegerLiteral)
+		if !ok {
+			return ProduceErrInfo(1, "int")
+		}
+		if p.Val < minHllPrecision || p.Val > maxHllPrecision {
+			return fmt.Errorf("precision must be between %d and %d, but got %d", minHllPrecision, maxHllPrecision, p.Val)
+		}
+	}
+	return nil
+}
@@ -243,7 +243,7 @@ func TestPercentileExec(t *testing.T) {
 				},
 				[]interface{}{0.5, 0.5, 0.5},
 			},
-			pCont: float64(125),
+			pCont: float64(150),
 			pDisc: float64(150),
 		},
 		{ // 3
@@ -255,7 +255,7 @@ func TestPercentileExec(t *testing.T) {
 				},
 				[]interface{}{0.5, 0.5, 0.5},
 			},
-			pCont: float64(125),
+			pCont: float64(150),
 			pDisc: float64(150),
 		},
 		{ // 4
@@ -265,7 +265,7 @@ func TestPercentileExec(t *testing.T) {
 				},
 				[]interface{}{0.5, 0.5, 0.5},
 			},
-			pCont: float64(125),
+			pCont: float64(150),
 			pDisc: float64(150),
 		},
 		{ // 5
@@ -283,9 +283,65 @@ func TestPercentileExec(t *testing.T) {
 				},
 				[]interface{}{0.5, 0.5, 0.5},
 			},
-			pCont: float64(125),
+			pCont: float64(150),
 			pDisc: float64(150),
 		},
+		{ // 7
+			args: []interface{}{
+				[]interface{}{42},
+				[]interface{}{0.9},
+			},
+			pCont: float64(42),
+			pDisc: float64(42),
+		},
+		{ // 8
+			args: []interface{}{
+				[]interface{}{5, 5, 5, 5},
+				[]interface{}{0.3, 0.3, 0.3, 0.3},
+			},
+			pCont: float64(5),
+			pDisc: float64(5),
+		},
+		{ // 9
+			args: []interface{}{
+				[]interface{}{4, 1, 3, 2},
+				[]interface{}{0.25, 0.25, 0.25, 0.25},
+			},
+			pCont: float64(1.75),
+			pDisc: float64(1),
+		},
+		{ // 10
+			args: []interface{}{
+				[]interface{}{3, 1, 2},
+				[]interface{}{0, 0, 0},
+			},
+			pCont: float64(1),
+			pDisc: float64(1),
+		},
+		{ // 11
+			args: []interface{}{
+				[]interface{}{3, 1, 2},
+				[]interface{}{1, 1, 1},
+			},
+			pCont: float64(3),
+			pDisc: float64(3),
+		},
+		{ // 12
+			args: []interface{}{
+				[]interface{}{nil, nil},
+				[]interface{}{0.5, 0.5},
+			},
+			pCont: nil,
+			pDisc: nil,
+		},
+		{ // 13
+			args: []interface{}{
+				[]interface{}{1, 2},
+				[]interface{}{1.5, 1.5},
+			},
+			pCont: fmt.Errorf("the percentile must be within [0, 1], but got 1.5"),
+			pDisc: fmt.Errorf("the percentile must be within [0, 1], but got 1.5"),
+		},
 	}
 	for i, tt := range tests {
 		rCont, _ := pCont.exec(fctx, tt.args)
@@ -426,7 +482,7 @@ func TestAggFuncNil(t *testing.T) {
 			r, b = function.check([]interface{}{nil})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
 			require.Nil(t, r, fmt.Sprintf("%v failed", name))
-		case "collect":
+		case "collect", "array_agg":
 			r, b := function.exec(fctx, []interface{}{nil})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
 			require.Nil(t, r, fmt.Sprintf("%v failed", name))
@@ -447,6 +503,13 @@ func TestAggFuncNil(t *testing.T) {
 			r, b = function.check([]interface{}{nil})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
 			require.Nil(t, r, fmt.Sprintf("%v failed", name))
+		case "approx_count_distinct":
+			r, b := function.exec(fctx, []interface{}{[]interface{}{nil}})
+			require.True(t, b, fmt.Sprintf("%v failed", name))
+			require.Equal(t, int64(0), r, fmt.Sprintf("%v failed", name))
+			r, b = function.exec(fctx, []interface{}{[]interface{}{1, 2, 1, nil}})
+			require.True(t, b, fmt.Sprintf("%v failed", name))
+			require.Equal(t, int64(2), r, fmt.Sprintf("%v failed", name))
 		default:
 			r, b := function.check([]interface{}{nil})
 			require.True(t, b, fmt.Sprintf("%v failed", name))
@@ -667,3 +730,321 @@ func TestLastValueValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestArrayAgg(t *testing.T) {
+	f, ok := builtins["array_agg"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			args: []interface{}{
+				[]interface{}{"foo", nil, "bar"},
+			},
+			result: []interface{}{"foo", nil, "bar"},
+		},
+		{
+			args: []interface{}{
+				[]interface{}{"foo", nil, "bar"},
+				[]interface{}{false, false, false},
+			},
+			result: []interface{}{"foo", nil, "bar"},
+		},
+		{
+			args: []interface{}{
+				[]interface{}{"foo", nil, "bar"},
+				[]interface{}{true, true, true},
+			},
+			result: []interface{}{"foo", "bar"},
+		},
+		{
+			args: []interface{}{
+				[]interface{}{nil, nil},
+				[]interface{}{true, true},
+			},
+			result: []interface{}{},
+		},
+		{
+			args: []interface{}{
+				[]interface{}{1},
+				true,
+			},
+			result: fmt.Errorf("the second argument to the aggregate function should be []interface but found bool(true)"),
+		},
+	}
+	for i, tt := range tests {
+		r, _ := f.exec(fctx, tt.args)
+		if !reflect.DeepEqual(r, tt.result) {
+			t.Errorf("%d result mismatch,\ngot:\t%v \nwant:\t%v", i, r, tt.result)
+		}
+	}
+}
+
+func TestArrayAggValidation(t *testing.T) {
+	f, ok := builtins["array_agg"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	tests := []struct {
+		args []ast.Expr
+		err  error
+	}{
+		{
+			args: []ast.Expr{},
+			err:  fmt.Errorf("Expect 1 arguments but found 0."),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.FieldRef{Name: "bar"},
+			},
+			err: fmt.Errorf("Expect bool type for parameter 2"),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+			},
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.BooleanLiteral{Val: true},
+			},
+		},
+	}
+	for i, tt := range tests {
+		err := f.val(nil, tt.args)
+		if !reflect.DeepEqual(err, tt.err) {
+			t.Errorf("%d result mismatch,\ngot:\t%v \nwant:\t%v", i, err, tt.err)
+		}
+	}
+}
+
+func TestDedupExec(t *testing.T) {
+	f, ok := builtins["dedup"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	rows := []interface{}{
+		map[string]interface{}{"a": "a", "i": 0},
+		map[string]interface{}{"a": "b", "i": 1},
+		map[string]interface{}{"a": "a", "i": 2},
+		map[string]interface{}{"a": "c", "i": 3},
+		map[string]interface{}{"a": "a", "i": 4},
+		map[string]interface{}{"a": "b", "i": 5},
+	}
+	col := []interface{}{"a", "b", "a", "c", "a", "b"}
+	tests := []struct {
+		name   string
+		n      interface{}
+		result interface{}
+	}{
+		{
+			name:   "unbounded",
+			n:      int64(-1),
+			result: []interface{}{rows[0], rows[1], rows[3]},
+		},
+		{
+			// b is evicted when c arrives because a is seen again in the middle
+			name:   "last 2",
+			n:      int64(2),
+			result: []interface{}{rows[0], rows[1], rows[3], rows[5]},
+		},
+		{
+			name:   "last 1",
+			n:      int64(1),
+			result: []interface{}{rows[0], rows[1], rows[2], rows[3], rows[4], rows[5]},
+		},
+		{
+			name:   "invalid n",
+			n:      "2",
+			result: fmt.Errorf("cannot convert string(2) to int"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := f.exec(fctx, []interface{}{rows, col, []interface{}{tt.n, tt.n, tt.n, tt.n, tt.n, tt.n}})
+			assert.Equal(t, tt.result, r)
+		})
+	}
+}
+
+func TestDedupValidation(t *testing.T) {
+	f, ok := builtins["dedup"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	tests := []struct {
+		args []ast.Expr
+		err  error
+	}{
+		{
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+			},
+			err: fmt.Errorf("Expect 2 arguments but found 1."),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.BooleanLiteral{Val: true},
+			},
+			err: fmt.Errorf("Expect int type for parameter 2"),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: 0},
+			},
+			err: fmt.Errorf("the second parameter of dedup must be a positive integer or -1, but got 0"),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: -1},
+			},
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: 10},
+			},
+		},
+	}
+	for i, tt := range tests {
+		err := f.val(nil, tt.args)
+		assert.Equal(t, tt.err, err, i)
+	}
+}
+
+func TestPercentileValidation(t *testing.T) {
+	for _, name := range []string{"percentile_cont", "percentile_disc"} {
+		f, ok := builtins[name]
+		if !ok {
+			t.Fatal("builtin not found")
+		}
+		tests := []struct {
+			args []ast.Expr
+			err  error
+		}{
+			{
+				args: []ast.Expr{
+					&ast.FieldRef{Name: "foo"},
+					&ast.StringLiteral{Val: "0.5"},
+				},
+				err: fmt.Errorf("Expect number - float or int type for parameter 2"),
+			}, {
+				args: []ast.Expr{
+					&ast.FieldRef{Name: "foo"},
+					&ast.NumberLiteral{Val: 1.01},
+				},
+				err: fmt.Errorf("the percentile must be within [0, 1], but got 1.01"),
+			}, {
+				args: []ast.Expr{
+					&ast.FieldRef{Name: "foo"},
+					&ast.IntegerLiteral{Val: -1},
+				},
+				err: fmt.Errorf("the percentile must be within [0, 1], but got -1"),
+			}, {
+				args: []ast.Expr{
+					&ast.FieldRef{Name: "foo"},
+					&ast.IntegerLiteral{Val: 1},
+				},
+			}, {
+				args: []ast.Expr{
+					&ast.FieldRef{Name: "foo"},
+					&ast.NumberLiteral{Val: 0.95},
+				},
+			},
+		}
+		for i, tt := range tests {
+			err := f.val(nil, tt.args)
+			assert.Equal(t, tt.err, err, "%s %d", name, i)
+		}
+	}
+}
+
+func TestApproxCountDistinct(t *testing.T) {
+	f, ok := builtins["approx_count_distinct"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 1)
+	tests := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			args:   []interface{}{[]interface{}{"a", "b", "a", "c", nil}},
+			result: int64(3),
+		},
+		{
+			args:   []interface{}{[]interface{}{1, 2, 2, 3, 4}, []interface{}{14, 14, 14, 14, 14}},
+			result: int64(4),
+		},
+		{
+			args:   []interface{}{[]interface{}{}},
+			result: int64(0),
+		},
+		{
+			args:   []interface{}{[]interface{}{1}, []interface{}{20}},
+			result: fmt.Errorf("precision must be between 4 and 16, but got 20"),
+		},
+		{
+			args:   []interface{}{1},
+			result: fmt.Errorf("the first argument to the aggregate function should be []interface but found int(1)"),
+		},
+	}
+	for i, tt := range tests {
+		r, _ := f.exec(fctx, tt.args)
+		assert.Equal(t, tt.result, r, i)
+	}
+}
+
+func TestApproxCountDistinctValidation(t *testing.T) {
+	f, ok := builtins["approx_count_distinct"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	tests := []struct {
+		args []ast.Expr
+		err  error
+	}{
+		{
+			args: []ast.Expr{},
+			err:  fmt.Errorf("Expect 1 or 2 arguments but found 0."),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.StringLiteral{Val: "12"},
+			},
+			err: fmt.Errorf("Expect int type for parameter 2"),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: 3},
+			},
+			err: fmt.Errorf("precision must be between 4 and 16, but got 3"),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+			},
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: 16},
+			},
+		},
+	}
+	for i, tt := range tests {
+		err := f.val(nil, tt.args)
+		assert.Equal(t, tt.err, err, i)
+	}
+}
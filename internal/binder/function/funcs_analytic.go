@@ -153,7 +153,7 @@ func registerAnalyticFunc() {
 			if v == nil {
 				size := 0
 				var dftVal interface{} = nil
-				if paraLen == 3 {
+				if paraLen >= 3 {
 					dftVal = args[2]
 				}
 				if paraLen == 1 {
@@ -211,6 +211,31 @@ func registerAnalyticFunc() {
 		},
 	}
 
+	// lead is evaluated by the lookahead buffer of the analytic operator because the result depends on the following rows
+	builtins["lead"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return fmt.Errorf("lead can only be evaluated by the analytic operator"), false
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			l := len(args)
+			if l != 1 && l != 2 && l != 3 {
+				return fmt.Errorf("expect one two or three args but got %d", l)
+			}
+			if l >= 2 {
+				if ast.IsFloatArg(args[1]) || ast.IsTimeArg(args[1]) || ast.IsBooleanArg(args[1]) || ast.IsStringArg(args[1]) || ast.IsFieldRefArg(args[1]) {
+					return ProduceErrInfo(1, "int")
+				}
+				if s, ok := args[1].(*ast.IntegerLiteral); ok {
+					if s.Val < 0 {
+						return fmt.Errorf("the index should not be a nagtive integer")
+					}
+				}
+			}
+			return nil
+		},
+	}
+
 	builtins["latest"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -825,6 +825,52 @@ func TestLagValidation(t *testing.T) {
 	}
 }
 
+func TestLeadValidation(t *testing.T) {
+	f, ok := builtins["lead"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	tests := []struct {
+		args []ast.Expr
+		err  error
+	}{
+		{
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: 2},
+				&ast.IntegerLiteral{Val: 0},
+			},
+			err: nil,
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.FieldRef{Name: "bar"},
+			},
+			err: fmt.Errorf("Expect int type for parameter 2"),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: -1},
+			},
+			err: fmt.Errorf("the index should not be a nagtive integer"),
+		}, {
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "foo"},
+				&ast.IntegerLiteral{Val: 1},
+				&ast.IntegerLiteral{Val: 0},
+				&ast.BooleanLiteral{Val: true},
+			},
+			err: fmt.Errorf("expect one two or three args but got 4"),
+		},
+	}
+	for i, tt := range tests {
+		err := f.val(nil, tt.args)
+		if !reflect.DeepEqual(err, tt.err) {
+			t.Errorf("%d result mismatch,\ngot:\t%v \nwant:\t%v", i, err, tt.err)
+		}
+	}
+}
+
 func TestLagExec(t *testing.T) {
 	f, ok := builtins["lag"]
 	if !ok {
@@ -847,7 +893,7 @@ func TestLagExec(t *testing.T) {
 				true,
 				"self",
 			},
-			result: nil,
+			result: "default",
 		},
 		{ // 2
 			args: []interface{}{
@@ -975,6 +1021,40 @@ func TestLagPartition(t *testing.T) {
 	}
 }
 
+func TestLagPartitionDefault(t *testing.T) {
+	f, ok := builtins["lag"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		// each partition returns the default until it has offset rows
+		{args: []interface{}{1, 2, 0, true, "a"}, result: 0},
+		{args: []interface{}{2, 2, 0, true, "a"}, result: 0},
+		{args: []interface{}{10, 2, 0, true, "b"}, result: 0},
+		{args: []interface{}{3, 2, 0, true, "a"}, result: 1},
+		{args: []interface{}{20, 2, 0, true, "b"}, result: 0},
+		{args: []interface{}{30, 2, 0, true, "b"}, result: 10},
+		// the invalid row does not move the history
+		{args: []interface{}{4, 2, 0, false, "a"}, result: 2},
+		{args: []interface{}{5, 2, 0, true, "a"}, result: 2},
+		{args: []interface{}{6, 2, 0, true, "a"}, result: 3},
+		// the default is a null value
+		{args: []interface{}{100, 1, nil, true, "c"}, result: nil},
+		{args: []interface{}{200, 1, nil, true, "c"}, result: 100},
+	}
+	for i, tt := range tests {
+		result, _ := f.exec(fctx, tt.args)
+		assert.Equal(t, tt.result, result, "case %d", i)
+	}
+}
+
 func TestLagExecWithWhen(t *testing.T) {
 	f, ok := builtins["lag"]
 	if !ok {
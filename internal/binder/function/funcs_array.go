@@ -29,7 +29,6 @@ import (
 
 var (
 	errorArrayFirstArgumentNotArrayError   = fmt.Errorf("first argument should be array of interface{}")
-	errorArrayIndex                        = fmt.Errorf("index out of range")
 	errorArraySecondArgumentNotArrayError  = fmt.Errorf("second argument should be array of interface{}")
 	errorArrayFirstArgumentNotIntError     = fmt.Errorf("first argument should be int")
 	errorArrayFirstArgumentNotStringError  = fmt.Errorf("first argument should be string")
@@ -61,6 +60,40 @@ func registerArrayFunc() {
 			return nil
 		},
 	}
+	builtins["array"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			r, err := unifyArrayElements(args)
+			if err != nil {
+				return err, false
+			}
+			return r, true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			kind := ""
+			for i, arg := range args {
+				var k string
+				switch {
+				case ast.IsNumericArg(arg):
+					k = "number"
+				case ast.IsStringArg(arg):
+					k = "string"
+				case ast.IsBooleanArg(arg):
+					k = "bool"
+				case ast.IsTimeArg(arg):
+					k = "datetime"
+				default:
+					continue
+				}
+				if kind == "" {
+					kind = k
+				} else if kind != k {
+					return ProduceErrInfo(i, kind)
+				}
+			}
+			return nil
+		},
+	}
 	builtins["array_position"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -92,8 +125,9 @@ func registerArrayFunc() {
 				if err != nil {
 					return err, false
 				}
+				// 0-based index, return nil if out of range
 				if index >= len(array) || -index > len(array) {
-					return errorArrayIndex, false
+					return nil, true
 				}
 				if index >= 0 {
 					return array[index], true
@@ -706,3 +740,62 @@ func registerArrayFunc() {
 		check: returnNilIfHasAnyNil,
 	}
 }
+
+// unifyArrayElements creates an array whose elements are of the same type. The integers are converted to float if
+// any element is a float. The nil elements are kept.
+func unifyArrayElements(args []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, len(args))
+	kind, hasFloat := "", false
+	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
+		var k string
+		switch arg.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			k = "number"
+		case float32, float64:
+			k, hasFloat = "number", true
+		case string:
+			k = "string"
+		case bool:
+			k = "bool"
+		case []byte:
+			k = "bytea"
+		case map[string]interface{}:
+			k = "object"
+		default:
+			switch reflect.ValueOf(arg).Kind() {
+			case reflect.Slice, reflect.Array:
+				k = "array"
+			case reflect.Map:
+				k = "object"
+			default:
+				k = reflect.TypeOf(arg).String()
+			}
+		}
+		if kind == "" {
+			kind = k
+		} else if kind != k {
+			return nil, fmt.Errorf("array elements must be of the same type, but element %d is %s while the previous elements are %s", i+1, k, kind)
+		}
+		result[i] = arg
+	}
+	if kind == "number" {
+		for i, arg := range result {
+			if arg == nil {
+				continue
+			}
+			var err error
+			if hasFloat {
+				result[i], err = cast.ToFloat64(arg, cast.CONVERT_SAMEKIND)
+			} else {
+				result[i], err = cast.ToInt64(arg, cast.CONVERT_SAMEKIND)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
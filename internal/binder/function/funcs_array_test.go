@@ -49,6 +49,45 @@ func TestArrayCommonFunctions(t *testing.T) {
 				1, "2", 3,
 			},
 		},
+		{
+			name: "array",
+			args: []interface{}{
+				1, nil, int64(3),
+			},
+			result: []interface{}{
+				int64(1), nil, int64(3),
+			},
+		},
+		{
+			name: "array",
+			args: []interface{}{
+				1, 2.5,
+			},
+			result: []interface{}{
+				1.0, 2.5,
+			},
+		},
+		{
+			name: "array",
+			args: []interface{}{
+				map[string]interface{}{"a": 1}, map[string]interface{}{"b": []interface{}{"c"}},
+			},
+			result: []interface{}{
+				map[string]interface{}{"a": 1}, map[string]interface{}{"b": []interface{}{"c"}},
+			},
+		},
+		{
+			name:   "array",
+			args:   []interface{}{},
+			result: []interface{}{},
+		},
+		{
+			name: "array",
+			args: []interface{}{
+				1, "2",
+			},
+			result: fmt.Errorf("array elements must be of the same type, but element 2 is string while the previous elements are number"),
+		},
 		{
 			name: "array_position",
 			args: []interface{}{
@@ -89,16 +128,16 @@ func TestArrayCommonFunctions(t *testing.T) {
 		{
 			name: "element_at",
 			args: []interface{}{
-				[]interface{}{1, 2, 3}, 4,
+				[]interface{}{1, 2, 3}, 3,
 			},
-			result: errorArrayIndex,
+			result: nil,
 		},
 		{
 			name: "element_at",
 			args: []interface{}{
 				[]interface{}{1, 2, 3}, -4,
 			},
-			result: errorArrayIndex,
+			result: nil,
 		},
 		{
 			name: "element_at",
@@ -966,6 +1005,24 @@ func TestArrayFuncVal(t *testing.T) {
 			},
 			err: fmt.Errorf("Expect 1 arguments but found 2."),
 		},
+		{
+			name:     "array mixed literals",
+			funcName: "array",
+			args: []ast.Expr{
+				&ast.IntegerLiteral{Val: 1},
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "b"},
+			},
+			err: fmt.Errorf("Expect number type for parameter 3"),
+		},
+		{
+			name:     "array literals",
+			funcName: "array",
+			args: []ast.Expr{
+				&ast.IntegerLiteral{Val: 1},
+				&ast.NumberLiteral{Val: 1.5},
+			},
+		},
 	}
 
 	for _, tt := range tests {
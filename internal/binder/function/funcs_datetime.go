@@ -16,6 +16,8 @@ package function
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -422,6 +424,55 @@ func registerDateTimeFunc() {
 			return nil
 		},
 	}
+	builtins["time_bucket"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			interval, err := parseBucketInterval(args[0])
+			if err != nil {
+				return err, false
+			}
+			t, err := cast.InterfaceToTime(args[1], "")
+			if err != nil {
+				return err, false
+			}
+			var offset time.Duration
+			if len(args) > 2 {
+				offset, err = parseBucketOffset(args[2])
+				if err != nil {
+					return err, false
+				}
+			}
+			return timeBucket(t.UnixMilli(), interval.Milliseconds(), offset.Milliseconds()), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 && len(args) != 3 {
+				return fmt.Errorf("Expect two or three arguments but found %d.", len(args))
+			}
+			if ast.IsNumericArg(args[0]) || ast.IsBooleanArg(args[0]) || ast.IsTimeArg(args[0]) {
+				return ProduceErrInfo(0, "string")
+			}
+			if sl, ok := args[0].(*ast.StringLiteral); ok {
+				if _, err := parseBucketInterval(sl.Val); err != nil {
+					return err
+				}
+			}
+			if ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "datetime")
+			}
+			if len(args) > 2 {
+				if ast.IsNumericArg(args[2]) || ast.IsBooleanArg(args[2]) || ast.IsTimeArg(args[2]) {
+					return ProduceErrInfo(2, "string")
+				}
+				if sl, ok := args[2].(*ast.StringLiteral); ok {
+					if _, err := parseBucketOffset(sl.Val); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["second"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -444,6 +495,56 @@ func registerDateTimeFunc() {
 	}
 }
 
+// parseBucketInterval parses the positive bucket interval. Besides the units of the go duration such as 5m or 1h30m,
+// the day unit d and the week unit w are supported for an integer like 1d or 2w.
+func parseBucketInterval(arg interface{}) (time.Duration, error) {
+	str := cast.ToStringAlways(arg)
+	d, err := parseBucketDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time_bucket interval %s, %v", str, err)
+	}
+	if d < time.Millisecond {
+		return 0, fmt.Errorf("time_bucket interval %s must be at least 1ms", str)
+	}
+	return d, nil
+}
+
+// parseBucketOffset parses the offset to shift the bucket boundaries, which could be negative
+func parseBucketOffset(arg interface{}) (time.Duration, error) {
+	str := cast.ToStringAlways(arg)
+	d, err := parseBucketDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time_bucket offset %s, %v", str, err)
+	}
+	return d, nil
+}
+
+func parseBucketDuration(str string) (time.Duration, error) {
+	if n := len(str); n > 1 && (str[n-1] == 'd' || str[n-1] == 'w') {
+		v, err := strconv.ParseInt(str[:n-1], 10, 64)
+		if err != nil {
+			return 0, errors.New("the day or week unit only supports integer")
+		}
+		unit := 24 * time.Hour
+		if str[n-1] == 'w' {
+			unit *= 7
+		}
+		return time.Duration(v) * unit, nil
+	}
+	return time.ParseDuration(str)
+}
+
+// timeBucket returns the start of the bucket which the timestamp falls in. The buckets are aligned to the unix epoch
+// shifted by the offset, and all the values are in milliseconds.
+func timeBucket(ts, interval, offset int64) int64 {
+	d := ts - offset
+	q := d / interval
+	if d%interval < 0 {
+		q--
+	}
+	return q*interval + offset
+}
+
 func execGetCurrentDate() funcExe {
 	return func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 		formatted, err := cast.FormatTime(time.Now(), "yyyy-MM-dd")
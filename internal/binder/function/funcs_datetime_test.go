@@ -918,3 +918,141 @@ func TestValidateFsp(t *testing.T) {
 	err := f(fctx, []ast.Expr{})
 	require.NoError(t, err)
 }
+
+func TestTimeBucket(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	f, ok := builtins["time_bucket"]
+	require.True(t, ok)
+	// 2023-08-14 06:38:25.123 UTC
+	ts := int64(1691995105123)
+	tests := []struct {
+		name string
+		args []interface{}
+		exp  interface{}
+	}{
+		{
+			name: "minutes",
+			args: []interface{}{"5m", ts},
+			exp:  int64(1691994900000),
+		},
+		{
+			name: "hour",
+			args: []interface{}{"1h", ts},
+			exp:  int64(1691992800000),
+		},
+		{
+			name: "day",
+			args: []interface{}{"1d", ts},
+			exp:  int64(1691971200000),
+		},
+		{
+			name: "week",
+			args: []interface{}{"1w", int64(0)},
+			exp:  int64(0),
+		},
+		{
+			name: "mixed units",
+			args: []interface{}{"1h30m", ts},
+			exp:  int64(1691992800000),
+		},
+		{
+			name: "datetime",
+			args: []interface{}{"10s", time.UnixMilli(ts)},
+			exp:  int64(1691995100000),
+		},
+		{
+			name: "offset",
+			args: []interface{}{"1d", ts, "8h"},
+			exp:  int64(1691913600000),
+		},
+		{
+			name: "negative offset",
+			args: []interface{}{"1h", ts, "-15m"},
+			exp:  int64(1691991900000),
+		},
+		{
+			name: "before epoch",
+			args: []interface{}{"1m", int64(-1)},
+			exp:  int64(-60000),
+		},
+		{
+			name: "invalid interval",
+			args: []interface{}{"1x", ts},
+			exp:  errors.New("invalid time_bucket interval 1x, time: unknown unit \"x\" in duration \"1x\""),
+		},
+		{
+			name: "zero interval",
+			args: []interface{}{"0s", ts},
+			exp:  errors.New("time_bucket interval 0s must be at least 1ms"),
+		},
+		{
+			name: "fraction day",
+			args: []interface{}{"1.5d", ts},
+			exp:  errors.New("invalid time_bucket interval 1.5d, the day or week unit only supports integer"),
+		},
+		{
+			name: "invalid offset",
+			args: []interface{}{"1h", ts, "abc"},
+			exp:  errors.New("invalid time_bucket offset abc, time: invalid duration \"abc\""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := f.exec(fctx, tt.args)
+			require.Equal(t, tt.exp, result)
+		})
+	}
+	// the buckets are usable as group keys, the timestamps in the same bucket have the same start
+	r1, _ := f.exec(fctx, []interface{}{"5m", ts})
+	r2, _ := f.exec(fctx, []interface{}{"5m", ts + 60000})
+	require.Equal(t, r1, r2)
+
+	valTests := []struct {
+		name string
+		args []ast.Expr
+		err  string
+	}{
+		{
+			name: "valid",
+			args: []ast.Expr{&ast.StringLiteral{Val: "5m"}, &ast.FieldRef{Name: "ts"}, &ast.StringLiteral{Val: "1m"}},
+		},
+		{
+			name: "too few args",
+			args: []ast.Expr{&ast.StringLiteral{Val: "5m"}},
+			err:  "Expect two or three arguments but found 1.",
+		},
+		{
+			name: "numeric interval",
+			args: []ast.Expr{&ast.IntegerLiteral{Val: 5}, &ast.FieldRef{Name: "ts"}},
+			err:  "Expect string type for parameter 1",
+		},
+		{
+			name: "invalid interval literal",
+			args: []ast.Expr{&ast.StringLiteral{Val: "-5m"}, &ast.FieldRef{Name: "ts"}},
+			err:  "time_bucket interval -5m must be at least 1ms",
+		},
+		{
+			name: "boolean timestamp",
+			args: []ast.Expr{&ast.StringLiteral{Val: "5m"}, &ast.BooleanLiteral{Val: true}},
+			err:  "Expect datetime type for parameter 2",
+		},
+		{
+			name: "invalid offset literal",
+			args: []ast.Expr{&ast.StringLiteral{Val: "5m"}, &ast.FieldRef{Name: "ts"}, &ast.StringLiteral{Val: "1y"}},
+			err:  "invalid time_bucket offset 1y, time: unknown unit \"y\" in duration \"1y\"",
+		},
+	}
+	for _, tt := range valTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := f.val(fctx, tt.args)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
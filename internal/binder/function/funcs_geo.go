@@ -0,0 +1,314 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// earthRadius is the mean radius of the earth in meters
+const earthRadius = 6371008.8
+
+const maxPolygonCacheSize = 1024
+
+var (
+	polygonCache     sync.Map
+	polygonCacheSize atomic.Int32
+)
+
+func registerGeoFunc() {
+	builtins["haversine"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			lat1, lon1, err := toLatLon(args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			lat2, lon2, err := toLatLon(args[2], args[3])
+			if err != nil {
+				return err, false
+			}
+			return haversine(lat1, lon1, lat2, lon2), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(4, len(args)); err != nil {
+				return err
+			}
+			for i, arg := range args {
+				if ast.IsStringArg(arg) || ast.IsTimeArg(arg) || ast.IsBooleanArg(arg) {
+					return ProduceErrInfo(i, "number - float or int")
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["within_polygon"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			lat, lon, err := toLatLon(args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			mp, err := getPolygon(args[2])
+			if err != nil {
+				return err, false
+			}
+			return mp.contains(lat, lon), true
+		},
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			for i, arg := range args[:2] {
+				if ast.IsStringArg(arg) || ast.IsTimeArg(arg) || ast.IsBooleanArg(arg) {
+					return ProduceErrInfo(i, "number - float or int")
+				}
+			}
+			if ast.IsNumericArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2]) {
+				return ProduceErrInfo(2, "string")
+			}
+			// Parse the constant polygon in plan time so that it is only parsed once
+			if sl, ok := args[2].(*ast.StringLiteral); ok {
+				if _, err := parsePolygon(sl.Val); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
+}
+
+// haversine returns the great-circle distance in meters
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := (lat2 - lat1) * DegToRad
+	dLon := (lon2 - lon1) * DegToRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*DegToRad)*math.Cos(lat2*DegToRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func toLatLon(latArg, lonArg any) (float64, float64, error) {
+	lat, err := cast.ToFloat64(latArg, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude: %v", err)
+	}
+	lon, err := cast.ToFloat64(lonArg, cast.CONVERT_SAMEKIND)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude: %v", err)
+	}
+	if err := validateLatLon(lat, lon); err != nil {
+		return 0, 0, err
+	}
+	return lat, lon, nil
+}
+
+func validateLatLon(lat, lon float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("invalid latitude %v, must be in [-90, 90]", lat)
+	}
+	if math.IsNaN(lon) || lon < -180 || lon > 180 {
+		return fmt.Errorf("invalid longitude %v, must be in [-180, 180]", lon)
+	}
+	return nil
+}
+
+// ring is a closed linear ring of [lon, lat] positions. The longitudes are unwrapped so that the ring crossing the
+// antimeridian is continuous, thus some longitudes may be out of [-180, 180].
+type ring [][2]float64
+
+// polygon is the outer ring and the holes
+type polygon []ring
+
+type multiPolygon []polygon
+
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+}
+
+// getPolygon returns the polygon of the GeoJSON string or object. The object such as a field of the event is parsed each
+// time.
+func getPolygon(arg any) (multiPolygon, error) {
+	switch v := arg.(type) {
+	case string:
+		return parsePolygon(v)
+	case []byte:
+		return parsePolygon(string(v))
+	case map[string]any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON polygon: %v", err)
+		}
+		return decodePolygon(b)
+	default:
+		return nil, fmt.Errorf("invalid GeoJSON polygon, must be a string or an object but got %v", arg)
+	}
+}
+
+// parsePolygon parses the GeoJSON string and caches it so that a polygon is parsed only once
+func parsePolygon(s string) (multiPolygon, error) {
+	if mp, ok := polygonCache.Load(s); ok {
+		return mp.(multiPolygon), nil
+	}
+	mp, err := decodePolygon([]byte(s))
+	if err != nil {
+		return nil, err
+	}
+	if polygonCacheSize.Load() < maxPolygonCacheSize {
+		if _, loaded := polygonCache.LoadOrStore(s, mp); !loaded {
+			polygonCacheSize.Add(1)
+		}
+	}
+	return mp, nil
+}
+
+// decodePolygon decodes the GeoJSON Polygon, MultiPolygon or the Feature of them
+func decodePolygon(b []byte) (multiPolygon, error) {
+	g := &geoJSON{}
+	if err := json.Unmarshal(b, g); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON polygon: %v", err)
+	}
+	if g.Type == "Feature" {
+		if g.Geometry == nil {
+			return nil, errors.New("invalid GeoJSON polygon: the geometry of the feature is missing")
+		}
+		g = g.Geometry
+	}
+	var mp [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var p [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON polygon coordinates: %v", err)
+		}
+		mp = [][][][]float64{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &mp); err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON polygon coordinates: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type %q, only Polygon and MultiPolygon are supported", g.Type)
+	}
+	if len(mp) == 0 {
+		return nil, errors.New("invalid GeoJSON polygon: the coordinates are empty")
+	}
+	result := make(multiPolygon, 0, len(mp))
+	for _, p := range mp {
+		if len(p) == 0 {
+			return nil, errors.New("invalid GeoJSON polygon: the polygon has no ring")
+		}
+		rp := make(polygon, 0, len(p))
+		for _, r := range p {
+			rr, err := toRing(r)
+			if err != nil {
+				return nil, err
+			}
+			rp = append(rp, rr)
+		}
+		result = append(result, rp)
+	}
+	return result, nil
+}
+
+func toRing(positions [][]float64) (ring, error) {
+	if len(positions) < 4 {
+		return nil, fmt.Errorf("invalid GeoJSON polygon: the linear ring must have at least 4 positions but got %d", len(positions))
+	}
+	first, last := positions[0], positions[len(positions)-1]
+	if len(first) < 2 || len(last) < 2 || first[0] != last[0] || first[1] != last[1] {
+		return nil, errors.New("invalid GeoJSON polygon: the linear ring must be closed")
+	}
+	r := make(ring, 0, len(positions))
+	for _, p := range positions {
+		if len(p) < 2 {
+			return nil, fmt.Errorf("invalid GeoJSON polygon: the position must be [longitude, latitude] but got %v", p)
+		}
+		if err := validateLatLon(p[1], p[0]); err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON polygon: %v", err)
+		}
+		lon := p[0]
+		// Unwrap the longitude so that the edge crossing the antimeridian is the shorter one
+		if len(r) > 0 {
+			prev := r[len(r)-1][0]
+			for lon-prev > 180 {
+				lon -= 360
+			}
+			for prev-lon > 180 {
+				lon += 360
+			}
+		}
+		r = append(r, [2]float64{lon, p[1]})
+	}
+	if r[0][0] != r[len(r)-1][0] {
+		return nil, errors.New("invalid GeoJSON polygon: the linear ring must not wind around the pole")
+	}
+	return r, nil
+}
+
+func (mp multiPolygon) contains(lat, lon float64) bool {
+	for _, p := range mp {
+		if p.contains(lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p polygon) contains(lat, lon float64) bool {
+	if !p[0].contains(lat, lon) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.contains(lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains checks the point by ray casting. The unwrapped ring may be shifted by 360 degrees, so the point is also
+// checked by the equivalent longitudes.
+func (r ring) contains(lat, lon float64) bool {
+	for _, l := range [3]float64{lon, lon - 360, lon + 360} {
+		if r.rayCast(lat, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r ring) rayCast(lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > lat) != (yj > lat) && lon < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,235 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+const (
+	squareWithHole = `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2,2],[2,8],[8,8],[8,2],[2,2]]]}`
+	// crossAntimeridian is the zone from the longitude 170 to -170 across the antimeridian
+	crossAntimeridian = `{"type":"Polygon","coordinates":[[[170,-10],[-170,-10],[-170,10],[170,10],[170,-10]]]}`
+	twoSquares        = `{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,1],[0,0]]],[[[20,20],[21,20],[21,21],[20,21],[20,20]]]]}}`
+)
+
+func TestHaversine(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	f, ok := builtins["haversine"]
+	require.True(t, ok)
+	tests := []struct {
+		name   string
+		args   []any
+		result float64
+	}{
+		{
+			name:   "paris to london",
+			args:   []any{48.8566, 2.3522, 51.5074, -0.1278},
+			result: 343556.53,
+		},
+		{
+			name:   "across antimeridian",
+			args:   []any{0, 179.5, 0, -179.5},
+			result: 111195.08,
+		},
+		{
+			name:   "pole to pole",
+			args:   []any{90, 0, -90, int64(0)},
+			result: 20015114.44,
+		},
+		{
+			name:   "same point",
+			args:   []any{30.5, 120.1, 30.5, 120.1},
+			result: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := f.exec(fctx, tt.args)
+			require.True(t, ok)
+			assert.InDelta(t, tt.result, result, 0.01)
+		})
+	}
+	result, ok := f.exec(fctx, []any{91, 0, 0, 0})
+	require.False(t, ok)
+	assert.EqualError(t, result.(error), "invalid latitude 91, must be in [-90, 90]")
+	result, ok = f.exec(fctx, []any{0, 0, 0, -180.5})
+	require.False(t, ok)
+	assert.EqualError(t, result.(error), "invalid longitude -180.5, must be in [-180, 180]")
+	err := f.val(fctx, []ast.Expr{&ast.NumberLiteral{Val: 1}, &ast.NumberLiteral{Val: 1}, &ast.NumberLiteral{Val: 1}, &ast.StringLiteral{Val: "1"}})
+	assert.EqualError(t, err, "Expect number - float or int type for parameter 4")
+	err = f.val(fctx, []ast.Expr{&ast.NumberLiteral{Val: 1}})
+	assert.EqualError(t, err, "Expect 4 arguments but found 1.")
+}
+
+func TestWithinPolygon(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	f, ok := builtins["within_polygon"]
+	require.True(t, ok)
+	tests := []struct {
+		name    string
+		lat     any
+		lon     any
+		polygon any
+		result  bool
+	}{
+		{name: "inside", lat: 1, lon: 1, polygon: squareWithHole, result: true},
+		{name: "outside", lat: 5, lon: 15, polygon: squareWithHole, result: false},
+		{name: "in the hole", lat: 5, lon: 5, polygon: squareWithHole, result: false},
+		{name: "east of antimeridian", lat: 0, lon: 175, polygon: crossAntimeridian, result: true},
+		{name: "west of antimeridian", lat: 0, lon: -175, polygon: crossAntimeridian, result: true},
+		{name: "on antimeridian", lat: 5.5, lon: 180, polygon: crossAntimeridian, result: true},
+		{name: "out of the zone across antimeridian", lat: 0, lon: 165, polygon: crossAntimeridian, result: false},
+		{name: "opposite of the zone across antimeridian", lat: 0, lon: 0, polygon: crossAntimeridian, result: false},
+		{name: "second polygon of the feature", lat: 20.5, lon: 20.5, polygon: twoSquares, result: true},
+		{name: "between the polygons", lat: 10, lon: 10, polygon: twoSquares, result: false},
+		{
+			name: "object",
+			lat:  0.5,
+			lon:  0.5,
+			polygon: map[string]any{
+				"type":        "Polygon",
+				"coordinates": []any{[]any{[]any{0, 0}, []any{1, 0}, []any{1, 1}, []any{0, 1}, []any{0, 0}}},
+			},
+			result: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := f.exec(fctx, []any{tt.lat, tt.lon, tt.polygon})
+			require.True(t, ok, result)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+
+	errTests := []struct {
+		name    string
+		lat     any
+		lon     any
+		polygon any
+		err     string
+	}{
+		{
+			name:    "invalid latitude",
+			lat:     -90.1,
+			lon:     0,
+			polygon: squareWithHole,
+			err:     "invalid latitude -90.1, must be in [-90, 90]",
+		},
+		{
+			name:    "invalid json",
+			lat:     0,
+			lon:     0,
+			polygon: `{"type":"Polygon"`,
+			err:     "invalid GeoJSON polygon: unexpected end of JSON input",
+		},
+		{
+			name:    "not polygon",
+			lat:     0,
+			lon:     0,
+			polygon: `{"type":"Point","coordinates":[0,0]}`,
+			err:     `unsupported GeoJSON type "Point", only Polygon and MultiPolygon are supported`,
+		},
+		{
+			name:    "too few positions",
+			lat:     0,
+			lon:     0,
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`,
+			err:     "invalid GeoJSON polygon: the linear ring must have at least 4 positions but got 3",
+		},
+		{
+			name:    "not closed",
+			lat:     0,
+			lon:     0,
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`,
+			err:     "invalid GeoJSON polygon: the linear ring must be closed",
+		},
+		{
+			name:    "invalid position",
+			lat:     0,
+			lon:     0,
+			polygon: `{"type":"Polygon","coordinates":[[[0,0],[1,100],[1,1],[0,0]]]}`,
+			err:     "invalid GeoJSON polygon: invalid latitude 100, must be in [-90, 90]",
+		},
+		{
+			name:    "around the pole",
+			lat:     85,
+			lon:     0,
+			polygon: `{"type":"Polygon","coordinates":[[[0,80],[120,80],[-120,80],[0,80]]]}`,
+			err:     "invalid GeoJSON polygon: the linear ring must not wind around the pole",
+		},
+		{
+			name:    "feature without geometry",
+			lat:     0,
+			lon:     0,
+			polygon: `{"type":"Feature"}`,
+			err:     "invalid GeoJSON polygon: the geometry of the feature is missing",
+		},
+		{
+			name:    "invalid type",
+			lat:     0,
+			lon:     0,
+			polygon: 1,
+			err:     "invalid GeoJSON polygon, must be a string or an object but got 1",
+		},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := f.exec(fctx, []any{tt.lat, tt.lon, tt.polygon})
+			require.False(t, ok)
+			assert.EqualError(t, result.(error), tt.err)
+		})
+	}
+}
+
+func TestWithinPolygonVal(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	f, ok := builtins["within_polygon"]
+	require.True(t, ok)
+	lat, lon := &ast.FieldRef{Name: "lat"}, &ast.FieldRef{Name: "lon"}
+	p := `{"type":"Polygon","coordinates":[[[30,30],[31,30],[31,31],[30,31],[30,30]]]}`
+	require.NoError(t, f.val(fctx, []ast.Expr{lat, lon, &ast.StringLiteral{Val: p}}))
+	// the constant polygon is parsed in plan time
+	_, cached := polygonCache.Load(p)
+	assert.True(t, cached)
+	require.NoError(t, f.val(fctx, []ast.Expr{lat, lon, &ast.FieldRef{Name: "zone"}}))
+
+	err := f.val(fctx, []ast.Expr{lat, lon, &ast.StringLiteral{Val: `{"type":"LineString"}`}})
+	assert.EqualError(t, err, `unsupported GeoJSON type "LineString", only Polygon and MultiPolygon are supported`)
+	err = f.val(fctx, []ast.Expr{lat, lon, &ast.IntegerLiteral{Val: 1}})
+	assert.EqualError(t, err, "Expect string type for parameter 3")
+	err = f.val(fctx, []ast.Expr{&ast.StringLiteral{Val: "a"}, lon, &ast.StringLiteral{Val: p}})
+	assert.EqualError(t, err, "Expect number - float or int type for parameter 1")
+	err = f.val(fctx, []ast.Expr{lat, lon})
+	assert.EqualError(t, err, "Expect 3 arguments but found 2.")
+}
@@ -25,14 +25,15 @@ import (
 )
 
 var supportedIncAggFunc = map[string]struct{}{
-	"count":      {},
-	"avg":        {},
-	"max":        {},
-	"min":        {},
-	"sum":        {},
-	"merge_agg":  {},
-	"collect":    {},
-	"last_value": {},
+	"count":                 {},
+	"avg":                   {},
+	"max":                   {},
+	"min":                   {},
+	"sum":                   {},
+	"merge_agg":             {},
+	"collect":               {},
+	"last_value":            {},
+	"approx_count_distinct": {},
 }
 
 func IsSupportedIncAgg(name string) bool {
@@ -144,6 +145,25 @@ func registerIncAggFunc() {
 		val:   ValidateOneNumberArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["inc_approx_count_distinct"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			precision := defaultHllPrecision
+			if len(args) > 1 {
+				p, err := cast.ToInt(args[1], cast.CONVERT_SAMEKIND)
+				if err != nil {
+					return fmt.Errorf("the second parameter requires int but found %[1]T(%[1]v)", args[1]), false
+				}
+				precision = p
+			}
+			result, err := incrementalApproxCountDistinct(ctx, args[0], precision)
+			if err != nil {
+				return err, false
+			}
+			return result, true
+		},
+		val: validateApproxCountDistinct,
+	}
 	builtins["inc_last_value"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -163,6 +183,33 @@ func registerIncAggFunc() {
 	}
 }
 
+// incrementalApproxCountDistinct keeps the registers of the sketch in the state, so the state size is bounded by the
+// precision.
+func incrementalApproxCountDistinct(ctx api.FunctionContext, arg interface{}, precision int) (int64, error) {
+	failpoint.Inject("inc_err", func() {
+		failpoint.Return(0, fmt.Errorf("inc err"))
+	})
+	key := fmt.Sprintf("%v_inc_approx_count_distinct", ctx.GetFuncId())
+	v, err := ctx.GetState(key)
+	if err != nil {
+		return 0, err
+	}
+	var h *hll
+	if registers, ok := v.([]byte); ok {
+		h = hllFromRegisters(registers)
+	} else {
+		h, err = newHll(precision)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if arg != nil {
+		h.add(arg)
+	}
+	ctx.PutState(key, h.registers)
+	return h.count(), nil
+}
+
 func incrementalLastValue(ctx api.FunctionContext, arg interface{}, ignoreNil bool) (interface{}, error) {
 	failpoint.Inject("inc_err", func() {
 		failpoint.Return(nil, fmt.Errorf("inc err"))
@@ -92,6 +92,20 @@ func TestIncAggFunction(t *testing.T) {
 			args2:    []interface{}{2, true},
 			output2:  2,
 		},
+		{
+			funcName: "inc_approx_count_distinct",
+			args1:    []interface{}{"a"},
+			output1:  int64(1),
+			args2:    []interface{}{"b"},
+			output2:  int64(2),
+		},
+		{
+			funcName: "inc_approx_count_distinct",
+			args1:    []interface{}{"a", 10},
+			output1:  int64(1),
+			args2:    []interface{}{"a", 10},
+			output2:  int64(1),
+		},
 	}
 	for index, tc := range testcases {
 		ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
@@ -149,6 +163,10 @@ func TestIncAggFunctionErr(t *testing.T) {
 			funcName: "inc_last_value",
 			args1:    []interface{}{1, true},
 		},
+		{
+			funcName: "inc_approx_count_distinct",
+			args1:    []interface{}{1},
+		},
 	}
 	for index, tc := range testcases {
 		ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
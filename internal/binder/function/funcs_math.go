@@ -200,6 +200,30 @@ func registerMathFunc() {
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["shiftleft"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v, n, err := shiftArgs(args)
+			if err != nil {
+				return err, false
+			}
+			return v << n, true
+		},
+		val:   ValidateTwoIntArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["shiftright"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			v, n, err := shiftArgs(args)
+			if err != nil {
+				return err, false
+			}
+			return v >> n, true
+		},
+		val:   ValidateTwoIntArg,
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["ceiling"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -689,3 +713,19 @@ Loop:
 	}
 	return s[:validLen]
 }
+
+// shiftArgs converts the operand and the shift count which must be in [0, 63]
+func shiftArgs(args []interface{}) (int, uint, error) {
+	v, err := cast.ToInt(args[0], cast.STRICT)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Expect int type for the first operand but got %v", args[0])
+	}
+	n, err := cast.ToInt(args[1], cast.STRICT)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Expect int type for the second operand but got %v", args[1])
+	}
+	if n < 0 || n > 63 {
+		return 0, 0, fmt.Errorf("the shift count must be between 0 and 63 but got %d", n)
+	}
+	return v, uint(n), nil
+}
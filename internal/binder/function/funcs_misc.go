@@ -32,6 +32,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/spaolacci/murmur3"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/keyedstate"
@@ -74,21 +75,24 @@ func registerMiscFunc() {
 			newType := args[1]
 			return cast.ToType(value, newType)
 		},
-		val: func(_ api.FunctionContext, args []ast.Expr) error {
-			if err := ValidateLen(2, len(args)); err != nil {
-				return err
-			}
-			a := args[1]
-			if ast.IsNumericArg(a) || ast.IsTimeArg(a) || ast.IsBooleanArg(a) {
-				return ProduceErrInfo(0, "string")
+		val:   validateCast,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["try_cast"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			newType, ok := args[1].(string)
+			if !ok || !isCastType(newType) {
+				return fmt.Errorf("Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea."), false
 			}
-			if av, ok := a.(*ast.StringLiteral); ok {
-				if !(av.Val == "bigint" || av.Val == "float" || av.Val == "string" || av.Val == "boolean" || av.Val == "datetime" || av.Val == "bytea") {
-					return fmt.Errorf("Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea.")
-				}
+			// The conversion failure returns null so that the dirty data does not fail the rule
+			r, ok := cast.ToType(args[0], newType)
+			if !ok {
+				return nil, true
 			}
-			return nil
+			return r, true
 		},
+		val:   validateCast,
 		check: returnNilIfHasAnyNil,
 	}
 	builtins["convert_tz"] = builtinFunc{
@@ -385,6 +389,22 @@ func registerMiscFunc() {
 		val:   ValidateOneStrArg,
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["murmur3"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return int64(murmur3.Sum32(hashBytes(args[0]))), true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["murmur2"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return int64(murmur2(hashBytes(args[0])) & 0x7fffffff), true
+		},
+		val:   ValidateOneArg,
+		check: returnNilIfHasAnyNil,
+	}
 	builtinStatfulFuncs["compress"] = func() api.Function {
 		conf.Log.Infof("initializing compress function")
 		return &compressFunc{}
@@ -424,9 +444,40 @@ func registerMiscFunc() {
 			if len(args) == 0 {
 				return fmt.Errorf("The arguments should be at least one.")
 			}
+			if _, err := ast.ArgsType(args); err != nil {
+				return fmt.Errorf("invalid argument %v", err)
+			}
 			return nil
 		},
 	}
+	builtins["nullif"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			if nullifEqual(args[0], args[1]) {
+				return nil, true
+			}
+			return args[0], true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			if _, err := ast.ArgsType(args); err != nil {
+				return fmt.Errorf("invalid argument %v", err)
+			}
+			return nil
+		},
+		check: func(args []interface{}) (interface{}, bool) {
+			// null is returned for null, and a non-null value is never equal to null
+			if args[0] == nil {
+				return nil, true
+			}
+			if args[1] == nil {
+				return args[0], true
+			}
+			return nil, false
+		},
+	}
 	builtins["newuuid"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -569,6 +620,56 @@ func registerMiscFunc() {
 		},
 		val: ValidateJsonFunc,
 	}
+	builtins["json_extract"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			result, err := jsonExtract(ctx, args[0], args[1])
+			if err != nil {
+				return err, false
+			}
+			return result, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(2, len(args)); err != nil {
+				return err
+			}
+			return validateJsonPathArg(args[1])
+		},
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["json_length"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			var jp interface{} = "$"
+			if len(args) > 1 {
+				jp = args[1]
+			}
+			result, err := jsonExtract(ctx, args[0], jp)
+			if err != nil {
+				return err, false
+			}
+			switch rt := result.(type) {
+			case nil:
+				return nil, true
+			case []interface{}:
+				return len(rt), true
+			case map[string]interface{}:
+				return len(rt), true
+			default:
+				return 1, true
+			}
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 1 && len(args) != 2 {
+				return fmt.Errorf("Expect 1 or 2 arguments but found %d.", len(args))
+			}
+			if len(args) == 2 {
+				return validateJsonPathArg(args[1])
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["window_start"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec:  nil, // directly return in the valuer
@@ -584,6 +685,18 @@ func registerMiscFunc() {
 		exec:  nil, // directly return in the valuer
 		val:   ValidateNoArg,
 	}
+	builtins["ingest_time"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec:  nil, // directly return in the valuer
+		val:   ValidateNoArg,
+	}
+	builtins["processing_time"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			return timex.GetNowInMilli(), true
+		},
+		val: ValidateNoArg,
+	}
 
 	builtins["delay"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
@@ -690,6 +803,51 @@ func jsonCall(ctx api.StreamContext, args []interface{}) (interface{}, error) {
 	return ctx.ParseJsonPath(jp, args[0])
 }
 
+// jsonExtract evaluates the json path on the json string or the decoded value. The compiled path is cached in the
+// context. A path which does not match the data returns nil instead of an error.
+func jsonExtract(ctx api.StreamContext, data interface{}, path interface{}) (interface{}, error) {
+	jp, ok := path.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid jsonPath, must be a string but got %v", path)
+	}
+	switch dt := data.(type) {
+	case string, []byte:
+		var text []byte
+		if s, ok := dt.(string); ok {
+			text = cast.StringToBytes(s)
+		} else {
+			text = dt.([]byte)
+		}
+		var v interface{}
+		if err := json.Unmarshal(text, &v); err != nil {
+			return nil, fmt.Errorf("fail to parse json: %v", err)
+		}
+		data = v
+	}
+	result, err := ctx.ParseJsonPath(jp, data)
+	if err != nil {
+		// Only report the malformed path, the unmatched path is null
+		if _, perr := conf.GetJsonPathEval(jp); perr != nil {
+			return nil, fmt.Errorf("invalid jsonPath %s: %v", jp, perr)
+		}
+		return nil, nil
+	}
+	return result, nil
+}
+
+// validateJsonPathArg reports the malformed json path at plan time if it is a literal
+func validateJsonPathArg(arg ast.Expr) error {
+	if ast.IsNumericArg(arg) || ast.IsTimeArg(arg) || ast.IsBooleanArg(arg) {
+		return ProduceErrInfo(1, "string")
+	}
+	if sl, ok := arg.(*ast.StringLiteral); ok {
+		if _, err := conf.GetJsonPathEval(sl.Val); err != nil {
+			return fmt.Errorf("invalid jsonPath %s: %v", sl.Val, err)
+		}
+	}
+	return nil
+}
+
 // page Rotate storage for in memory cache
 // Not thread safe!
 type ringqueue struct {
@@ -758,3 +916,76 @@ func (p *ringqueue) peek() (interface{}, bool) {
 func (p *ringqueue) isFull() bool {
 	return p.l == p.size
 }
+
+// nullifEqual compares the values after unifying the numeric types, so that nullif(1, 1.0) is null
+func nullifEqual(a, b interface{}) bool {
+	fa, err := cast.ToFloat64(a, cast.CONVERT_SAMEKIND)
+	if err == nil {
+		fb, err := cast.ToFloat64(b, cast.CONVERT_SAMEKIND)
+		return err == nil && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isCastType(t string) bool {
+	return t == "bigint" || t == "float" || t == "string" || t == "boolean" || t == "datetime" || t == "bytea"
+}
+
+func validateCast(_ api.FunctionContext, args []ast.Expr) error {
+	if err := ValidateLen(2, len(args)); err != nil {
+		return err
+	}
+	a := args[1]
+	if ast.IsNumericArg(a) || ast.IsTimeArg(a) || ast.IsBooleanArg(a) {
+		return ProduceErrInfo(0, "string")
+	}
+	if av, ok := a.(*ast.StringLiteral); ok {
+		if !isCastType(av.Val) {
+			return fmt.Errorf("Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea.")
+		}
+	}
+	return nil
+}
+
+// hashBytes returns the bytes to hash. The bytes are hashed directly and the others are hashed by the string form.
+func hashBytes(arg interface{}) []byte {
+	if b, ok := arg.([]byte); ok {
+		return b
+	}
+	return []byte(cast.ToStringAlways(arg))
+}
+
+// murmur2 is the 32-bit murmur2 hash used by the default partitioner of Kafka
+func murmur2(data []byte) uint32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
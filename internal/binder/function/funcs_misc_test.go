@@ -32,6 +32,7 @@ import (
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -101,6 +102,57 @@ func TestCoalesceExec(t *testing.T) {
 	}
 }
 
+func TestNullifExec(t *testing.T) {
+	f, ok := builtins["nullif"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			args:   []interface{}{"foo", "foo"},
+			result: nil,
+		},
+		{
+			args:   []interface{}{"foo", "bar"},
+			result: "foo",
+		},
+		{
+			args:   []interface{}{int64(1), 1.0},
+			result: nil,
+		},
+		{
+			args:   []interface{}{int64(1), "1"},
+			result: int64(1),
+		},
+		{
+			args:   []interface{}{[]interface{}{1, 2}, []interface{}{1, 2}},
+			result: nil,
+		},
+		{
+			args:   []interface{}{nil, nil},
+			result: nil,
+		},
+		{
+			args:   []interface{}{int64(1), nil},
+			result: int64(1),
+		},
+	}
+	for i, tt := range tests {
+		result, skip := f.check(tt.args)
+		if !skip {
+			result, _ = f.exec(fctx, tt.args)
+		}
+		assert.Equal(t, tt.result, result, "case %d", i)
+	}
+}
+
 func TestToSeconds(t *testing.T) {
 	f, ok := builtins["to_seconds"]
 	if !ok {
@@ -229,6 +281,124 @@ func TestFromJson(t *testing.T) {
 	}
 }
 
+func TestJsonExtract(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	payload := `{"a":{"b":[{"c":1.5},{"c":"x"}],"d":true,"e":{"f":null,"g":"h"}}}`
+	tests := []struct {
+		name   string
+		fn     string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "nested",
+			fn:     "json_extract",
+			args:   []interface{}{payload, "$.a.b[0].c"},
+			result: 1.5,
+		},
+		{
+			name:   "string",
+			fn:     "json_extract",
+			args:   []interface{}{payload, "$.a.b[1].c"},
+			result: "x",
+		},
+		{
+			name:   "bool",
+			fn:     "json_extract",
+			args:   []interface{}{payload, "$.a.d"},
+			result: true,
+		},
+		{
+			name:   "object",
+			fn:     "json_extract",
+			args:   []interface{}{payload, "$.a.e"},
+			result: map[string]interface{}{"f": nil, "g": "h"},
+		},
+		{
+			name:   "decoded value",
+			fn:     "json_extract",
+			args:   []interface{}{map[string]interface{}{"a": []interface{}{1, 2}}, "$.a[1]"},
+			result: 2,
+		},
+		{
+			name:   "missing key",
+			fn:     "json_extract",
+			args:   []interface{}{payload, "$.a.z"},
+			result: nil,
+		},
+		{
+			name:   "out of range",
+			fn:     "json_extract",
+			args:   []interface{}{payload, "$.a.b[5]"},
+			result: nil,
+		},
+		{
+			name:   "invalid json",
+			fn:     "json_extract",
+			args:   []interface{}{"key1", "$.a"},
+			result: fmt.Errorf("fail to parse json: invalid character 'k' looking for beginning of value"),
+		},
+		{
+			name:   "length array",
+			fn:     "json_length",
+			args:   []interface{}{payload, "$.a.b"},
+			result: 2,
+		},
+		{
+			name:   "length object",
+			fn:     "json_length",
+			args:   []interface{}{payload, "$.a.e"},
+			result: 2,
+		},
+		{
+			name:   "length root",
+			fn:     "json_length",
+			args:   []interface{}{payload},
+			result: 1,
+		},
+		{
+			name:   "length scalar",
+			fn:     "json_length",
+			args:   []interface{}{payload, "$.a.d"},
+			result: 1,
+		},
+		{
+			name:   "length missing",
+			fn:     "json_length",
+			args:   []interface{}{payload, "$.x"},
+			result: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := builtins[tt.fn]
+			require.True(t, ok)
+			result, _ := f.exec(fctx, tt.args)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestJsonExtractValidation(t *testing.T) {
+	f, ok := builtins["json_extract"]
+	require.True(t, ok)
+	require.NoError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.StringLiteral{Val: "$.a.b[0]"}}))
+	require.NoError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.FieldRef{Name: "p"}}))
+	assert.EqualError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}}), "Expect 2 arguments but found 1.")
+	assert.EqualError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.IntegerLiteral{Val: 1}}), "Expect string type for parameter 2")
+	err := f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.StringLiteral{Val: "$.a["}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid jsonPath $.a[")
+
+	f, ok = builtins["json_length"]
+	require.True(t, ok)
+	require.NoError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}}))
+	assert.EqualError(t, f.val(nil, []ast.Expr{&ast.FieldRef{Name: "a"}, &ast.StringLiteral{Val: "$"}, &ast.StringLiteral{Val: "$"}}), "Expect 1 or 2 arguments but found 3.")
+}
+
 func TestConvertTZ(t *testing.T) {
 	f, ok := builtins["convert_tz"]
 	if !ok {
@@ -510,7 +680,7 @@ func TestMiscFuncNil(t *testing.T) {
 	for name, function := range builtins {
 		switch name {
 		case "compress", "decompress", "newuuid", "tstamp", "rule_id", "rule_start", "window_start", "window_end", "event_time",
-			"json_path_query", "json_path_query_first", "coalesce", "meta", "json_path_exists", "bypass":
+			"ingest_time", "processing_time", "json_path_query", "json_path_query_first", "coalesce", "meta", "json_path_exists", "bypass":
 			continue
 		case "isnull":
 			v, b := function.exec(fctx, []interface{}{nil})
@@ -651,6 +821,93 @@ func TestCast(t *testing.T) {
 	}
 }
 
+func TestTryCast(t *testing.T) {
+	f, ok := builtins["try_cast"]
+	if !ok {
+		t.Fatal("builtin not found")
+	}
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+	}{
+		{
+			name:   "bigint",
+			args:   []interface{}{"12", "bigint"},
+			result: 12,
+		},
+		{
+			name:   "invalid bigint",
+			args:   []interface{}{"abc", "bigint"},
+			result: nil,
+		},
+		{
+			name:   "float",
+			args:   []interface{}{"1.5", "float"},
+			result: 1.5,
+		},
+		{
+			name:   "invalid float",
+			args:   []interface{}{"1.5x", "float"},
+			result: nil,
+		},
+		{
+			name:   "string",
+			args:   []interface{}{1, "string"},
+			result: "1",
+		},
+		{
+			name:   "boolean",
+			args:   []interface{}{"true", "boolean"},
+			result: true,
+		},
+		{
+			name:   "invalid boolean",
+			args:   []interface{}{"yes", "boolean"},
+			result: nil,
+		},
+		{
+			name:   "datetime",
+			args:   []interface{}{int64(1000), "datetime"},
+			result: cast.TimeFromUnixMilli(1000),
+		},
+		{
+			name:   "invalid datetime",
+			args:   []interface{}{"not a time", "datetime"},
+			result: nil,
+		},
+		{
+			name:   "bytea",
+			args:   []interface{}{"Ynl0ZWE=", "bytea"},
+			result: []byte("bytea"),
+		},
+		{
+			name:   "invalid bytea",
+			args:   []interface{}{1, "bytea"},
+			result: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := f.exec(fctx, tt.args)
+			assert.True(t, ok)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+	// the invalid type is still an error
+	result, ok := f.exec(fctx, []interface{}{1, "int"})
+	assert.False(t, ok)
+	assert.EqualError(t, result.(error), "Expect one of following value for the 2nd parameter: bigint, float, string, boolean, datetime, bytea.")
+	assert.NoError(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "foo"}, &ast.StringLiteral{Val: "bigint"}}))
+	assert.Error(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "foo"}, &ast.StringLiteral{Val: "test"}}))
+	assert.Error(t, f.val(fctx, []ast.Expr{&ast.FieldRef{Name: "foo"}}))
+}
+
 func TestProps(t *testing.T) {
 	f, ok := builtins["props"]
 	if !ok {
@@ -193,6 +193,34 @@ func registerObjectFunc() {
 			return nil
 		},
 	}
+	builtins["map"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			if len(args)%2 != 0 {
+				return fmt.Errorf("map expects key value pairs, but got %d arguments", len(args)), false
+			}
+			result := make(map[string]interface{}, len(args)/2)
+			for i := 0; i < len(args); i += 2 {
+				s, ok := args[i].(string)
+				if !ok {
+					return fmt.Errorf("key %v of map is not a string", args[i]), false
+				}
+				result[s] = args[i+1]
+			}
+			return result, true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if len(args)%2 != 0 {
+				return fmt.Errorf("map expects key value pairs, but got %d arguments", len(args))
+			}
+			for i := 0; i < len(args); i += 2 {
+				if ast.IsNumericArg(args[i]) || ast.IsTimeArg(args[i]) || ast.IsBooleanArg(args[i]) {
+					return ProduceErrInfo(i, "string")
+				}
+			}
+			return nil
+		},
+	}
 	builtins["erase"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
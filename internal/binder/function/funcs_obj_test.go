@@ -134,6 +134,41 @@ func TestToMapVal(t *testing.T) {
 	}
 }
 
+func TestMapVal(t *testing.T) {
+	f, ok := builtins["map"]
+	require.True(t, ok)
+	tests := []struct {
+		args []ast.Expr
+		err  error
+	}{
+		{
+			args: []ast.Expr{
+				&ast.StringLiteral{Val: "foo"},
+				&ast.FieldRef{Name: "bar"},
+			},
+		}, {
+			args: []ast.Expr{
+				&ast.StringLiteral{Val: "foo"},
+				&ast.StringLiteral{Val: "bar"},
+				&ast.StringLiteral{Val: "baz"},
+			},
+			err: fmt.Errorf("map expects key value pairs, but got 3 arguments"),
+		}, {
+			args: []ast.Expr{
+				&ast.IntegerLiteral{Val: 1},
+				&ast.StringLiteral{Val: "baz"},
+			},
+			err: fmt.Errorf("Expect string type for parameter 1"),
+		},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := f.val(nil, tt.args)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestObjectFunctions(t *testing.T) {
 	contextLogger := conf.Log.WithField("rule", "testExec")
 	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
@@ -159,6 +194,26 @@ func TestObjectFunctions(t *testing.T) {
 			args:   []interface{}{1, 2},
 			result: fmt.Errorf("the argument should be map[string]interface{}"),
 		},
+		{
+			name: "map",
+			args: []interface{}{
+				"k1", []interface{}{1, 2}, "k2", map[string]interface{}{"k3": nil},
+			},
+			result: map[string]interface{}{
+				"k1": []interface{}{1, 2},
+				"k2": map[string]interface{}{"k3": nil},
+			},
+		},
+		{
+			name:   "map",
+			args:   []interface{}{1, 2},
+			result: fmt.Errorf("key 1 of map is not a string"),
+		},
+		{
+			name:   "map",
+			args:   []interface{}{"k1"},
+			result: fmt.Errorf("map expects key value pairs, but got 1 arguments"),
+		},
 		{
 			name: "values",
 			args: []interface{}{
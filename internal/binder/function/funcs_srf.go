@@ -25,13 +25,37 @@ func registerSetReturningFunc() {
 		fType: ast.FuncTypeSrf,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			arg := args[0]
+			// If keepEmpty is true, the empty or null array produces one row with null value instead of being dropped
+			keepEmpty := len(args) > 1 && args[1] == true
+			if arg == nil {
+				if keepEmpty {
+					return []interface{}{nil}, true
+				}
+				return nil, true
+			}
 			argArray, ok := arg.([]interface{})
 			if !ok {
 				return arg, true
 			}
+			if len(argArray) == 0 && keepEmpty {
+				return []interface{}{nil}, true
+			}
 			return argArray, true
 		},
-		val:   ValidateOneArg,
-		check: returnNilIfHasAnyNil,
+		val: func(ctx api.FunctionContext, args []ast.Expr) error {
+			if len(args) != 2 {
+				return ValidateOneArg(ctx, args)
+			}
+			if !ast.IsBooleanArg(args[1]) {
+				return ProduceErrInfo(1, "bool")
+			}
+			return nil
+		},
+		check: func(args []interface{}) (interface{}, bool) {
+			if args[0] == nil && (len(args) < 2 || args[1] != true) {
+				return nil, true
+			}
+			return nil, false
+		},
 	}
 }
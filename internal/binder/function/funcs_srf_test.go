@@ -70,6 +70,36 @@ func TestUnnestFunctions(t *testing.T) {
 				},
 			},
 		},
+		{
+			args: []interface{}{
+				[]interface{}{[]interface{}{1, 2}, []interface{}{3}},
+			},
+			result: []interface{}{[]interface{}{1, 2}, []interface{}{3}},
+		},
+		{
+			args: []interface{}{
+				[]interface{}{},
+			},
+			result: []interface{}{},
+		},
+		{
+			args: []interface{}{
+				[]interface{}{}, true,
+			},
+			result: []interface{}{nil},
+		},
+		{
+			args: []interface{}{
+				nil, true,
+			},
+			result: []interface{}{nil},
+		},
+		{
+			args: []interface{}{
+				[]interface{}{1}, false,
+			},
+			result: []interface{}{1},
+		},
 	}
 	for i, tt := range tests {
 		result, _ := f.exec(fctx, tt.args)
@@ -92,3 +122,12 @@ func TestUnnestFunctionsNil(t *testing.T) {
 		require.Nil(t, r, fmt.Sprintf("%v failed", name))
 	}
 }
+
+func TestUnnestCheck(t *testing.T) {
+	f, ok := builtins["unnest"]
+	require.True(t, ok)
+	_, b := f.check([]interface{}{nil, true})
+	require.False(t, b)
+	_, b = f.check([]interface{}{nil, false})
+	require.True(t, b)
+}
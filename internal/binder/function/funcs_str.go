@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
 
@@ -32,6 +34,50 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
+// maxRegexpCacheSize limits the compiled patterns to cache in case the pattern is a dynamic field
+const maxRegexpCacheSize = 1024
+
+var (
+	regexpCache     sync.Map
+	regexpCacheSize atomic.Int32
+)
+
+var errSplitPartIndex = errors.New("the index of split_part starts from 1, and must not be 0")
+
+// compileRegexp compiles the pattern and caches it so that a pattern is compiled only once
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if regexpCacheSize.Load() < maxRegexpCacheSize {
+		if _, loaded := regexpCache.LoadOrStore(pattern, re); !loaded {
+			regexpCacheSize.Add(1)
+		}
+	}
+	return re, nil
+}
+
+// validateRegexpArg compiles the pattern in plan time if it is a string literal
+func validateRegexpArg(arg ast.Expr) error {
+	if sl, ok := arg.(*ast.StringLiteral); ok {
+		if _, err := compileRegexp(sl.Val); err != nil {
+			return fmt.Errorf("invalid regular expression %s: %v", sl.Val, err)
+		}
+	}
+	return nil
+}
+
+func validateRegexpTwoStrArg(ctx api.FunctionContext, args []ast.Expr) error {
+	if err := ValidateTwoStrArg(ctx, args); err != nil {
+		return err
+	}
+	return validateRegexpArg(args[1])
+}
+
 func registerStrFunc() {
 	builtins["concat"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
@@ -136,20 +182,20 @@ func registerStrFunc() {
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			arg0, arg1 := cast.ToStringAlways(args[0]), cast.ToStringAlways(args[1])
-			if matched, err := regexp.MatchString(arg1, arg0); err != nil {
+			if re, err := compileRegexp(arg1); err != nil {
 				return err, false
 			} else {
-				return matched, true
+				return re.MatchString(arg0), true
 			}
 		},
-		val:   ValidateTwoStrArg,
+		val:   validateRegexpTwoStrArg,
 		check: returnFalseIfHasAnyNil,
 	}
 	builtins["regexp_replace"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			arg0, arg1, arg2 := cast.ToStringAlways(args[0]), cast.ToStringAlways(args[1]), cast.ToStringAlways(args[2])
-			if re, err := regexp.Compile(arg1); err != nil {
+			if re, err := compileRegexp(arg1); err != nil {
 				return err, false
 			} else {
 				return re.ReplaceAllString(arg0, arg2), true
@@ -164,7 +210,7 @@ func registerStrFunc() {
 					return ProduceErrInfo(i, "string")
 				}
 			}
-			return nil
+			return validateRegexpArg(args[1])
 		},
 		check: returnNilIfHasAnyNil,
 	}
@@ -172,13 +218,61 @@ func registerStrFunc() {
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
 			arg0, arg1 := cast.ToStringAlways(args[0]), cast.ToStringAlways(args[1])
-			if re, err := regexp.Compile(arg1); err != nil {
+			if re, err := compileRegexp(arg1); err != nil {
 				return err, false
 			} else {
 				return re.FindString(arg0), true
 			}
 		},
-		val:   ValidateTwoStrArg,
+		val:   validateRegexpTwoStrArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["regexp_extract"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0, arg1 := cast.ToStringAlways(args[0]), cast.ToStringAlways(args[1])
+			idx, err := cast.ToInt(args[2], cast.STRICT)
+			if err != nil {
+				return err, false
+			}
+			re, err := compileRegexp(arg1)
+			if err != nil {
+				return err, false
+			}
+			if idx < 0 || idx > re.NumSubexp() {
+				return fmt.Errorf("group index %d is out of range, the regular expression %s has %d groups", idx, arg1, re.NumSubexp()), false
+			}
+			m := re.FindStringSubmatch(arg0)
+			if m == nil {
+				return "", true
+			}
+			return m[idx], true
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			for i := 0; i < 2; i++ {
+				if ast.IsNumericArg(args[i]) || ast.IsTimeArg(args[i]) || ast.IsBooleanArg(args[i]) {
+					return ProduceErrInfo(i, "string")
+				}
+			}
+			if ast.IsFloatArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2]) || ast.IsStringArg(args[2]) {
+				return ProduceErrInfo(2, "int")
+			}
+			if err := validateRegexpArg(args[1]); err != nil {
+				return err
+			}
+			if sl, ok := args[1].(*ast.StringLiteral); ok {
+				if il, ok := args[2].(*ast.IntegerLiteral); ok {
+					re, _ := compileRegexp(sl.Val)
+					if il.Val < 0 || int(il.Val) > re.NumSubexp() {
+						return fmt.Errorf("group index %d is out of range, the regular expression %s has %d groups", il.Val, sl.Val, re.NumSubexp())
+					}
+				}
+			}
+			return nil
+		},
 		check: returnNilIfHasAnyNil,
 	}
 	builtins["reverse"] = builtinFunc{
@@ -339,6 +433,76 @@ func registerStrFunc() {
 		},
 		check: returnNilIfHasAnyNil,
 	}
+	builtins["split"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0, arg1 := cast.ToStringAlways(args[0]), cast.ToStringAlways(args[1])
+			// An empty string has no segment
+			if arg0 == "" {
+				return []interface{}{}, true
+			}
+			// The whole string is the only segment for the empty delimiter
+			if arg1 == "" {
+				return []interface{}{arg0}, true
+			}
+			ss := strings.Split(arg0, arg1)
+			result := make([]interface{}, len(ss))
+			for i, v := range ss {
+				result[i] = v
+			}
+			return result, true
+		},
+		val:   ValidateTwoStrArg,
+		check: returnNilIfHasAnyNil,
+	}
+	builtins["split_part"] = builtinFunc{
+		fType: ast.FuncTypeScalar,
+		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
+			arg0, arg1 := cast.ToStringAlways(args[0]), cast.ToStringAlways(args[1])
+			n, err := cast.ToInt(args[2], cast.STRICT)
+			if err != nil {
+				return err, false
+			}
+			if n == 0 {
+				return errSplitPartIndex, false
+			}
+			if arg1 == "" {
+				// The whole string is the only segment
+				if n == 1 || n == -1 {
+					return arg0, true
+				}
+				return nil, true
+			}
+			ss := strings.Split(arg0, arg1)
+			// 1-based index, the negative index counts from the end
+			switch {
+			case n > len(ss) || -n > len(ss):
+				return nil, true
+			case n > 0:
+				return ss[n-1], true
+			default:
+				return ss[len(ss)+n], true
+			}
+		},
+		val: func(_ api.FunctionContext, args []ast.Expr) error {
+			if err := ValidateLen(3, len(args)); err != nil {
+				return err
+			}
+			for i := 0; i < 2; i++ {
+				if ast.IsNumericArg(args[i]) || ast.IsTimeArg(args[i]) || ast.IsBooleanArg(args[i]) {
+					return ProduceErrInfo(i, "string")
+				}
+			}
+			if ast.IsFloatArg(args[2]) || ast.IsTimeArg(args[2]) || ast.IsBooleanArg(args[2]) || ast.IsStringArg(args[2]) {
+				return ProduceErrInfo(2, "int")
+			}
+			if s, ok := args[2].(*ast.IntegerLiteral); ok && s.Val == 0 {
+				return errSplitPartIndex
+			}
+			return nil
+		},
+		check: returnNilIfHasAnyNil,
+	}
 	builtins["trim"] = builtinFunc{
 		fType: ast.FuncTypeScalar,
 		exec: func(ctx api.FunctionContext, args []interface{}) (interface{}, bool) {
@@ -109,6 +109,107 @@ func TestSplitValueFunctions(t *testing.T) {
 	}
 }
 
+func TestSplitFunctions(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		result interface{}
+		ok     bool
+	}{
+		{
+			name:   "split",
+			args:   []interface{}{"a,b,,c", ","},
+			result: []interface{}{"a", "b", "", "c"},
+			ok:     true,
+		},
+		{
+			name:   "split",
+			args:   []interface{}{"a::b::c", "::"},
+			result: []interface{}{"a", "b", "c"},
+			ok:     true,
+		},
+		{
+			name:   "split",
+			args:   []interface{}{"", ","},
+			result: []interface{}{},
+			ok:     true,
+		},
+		{
+			name:   "split",
+			args:   []interface{}{"a,b", ""},
+			result: []interface{}{"a,b"},
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a::b::c", "::", 1},
+			result: "a",
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a::b::c", "::", 3},
+			result: "c",
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a::b::c", "::", -1},
+			result: "c",
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a::b::c", "::", 4},
+			result: nil,
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a::b::c", "::", -4},
+			result: nil,
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"", ",", 1},
+			result: "",
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a,b", "", 1},
+			result: "a,b",
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a,b", "", 2},
+			result: nil,
+			ok:     true,
+		},
+		{
+			name:   "split_part",
+			args:   []interface{}{"a,b", ",", 0},
+			result: errSplitPartIndex,
+			ok:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := builtins[tt.name]
+			require.True(t, ok)
+			result, ok := f.exec(fctx, tt.args)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.result, result)
+		})
+	}
+}
+
 func TestStrFunc(t *testing.T) {
 	contextLogger := conf.Log.WithField("rule", "testExec")
 	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
@@ -346,6 +447,103 @@ func TestStringFuncVal(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name:     "regexp_matches invalid pattern",
+			funcName: "regexp_matches",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "a("},
+			},
+			err: fmt.Errorf("invalid regular expression a(: error parsing regexp: missing closing ): `a(`"),
+		},
+		{
+			name:     "regexp_replace invalid pattern",
+			funcName: "regexp_replace",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "[a"},
+				&ast.StringLiteral{Val: "b"},
+			},
+			err: fmt.Errorf("invalid regular expression [a: error parsing regexp: missing closing ]: `[a`"),
+		},
+		{
+			name:     "regexp_extract success",
+			funcName: "regexp_extract",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "(a+)(b+)"},
+				&ast.IntegerLiteral{Val: 2},
+			},
+			err: nil,
+		},
+		{
+			name:     "regexp_extract dynamic pattern",
+			funcName: "regexp_extract",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.FieldRef{Name: "b"},
+				&ast.IntegerLiteral{Val: 3},
+			},
+			err: nil,
+		},
+		{
+			name:     "regexp_extract wrong arity",
+			funcName: "regexp_extract",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "(a+)"},
+			},
+			err: fmt.Errorf("Expect 3 arguments but found 2."),
+		},
+		{
+			name:     "regexp_extract invalid group type",
+			funcName: "regexp_extract",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "(a+)"},
+				&ast.StringLiteral{Val: "1"},
+			},
+			err: fmt.Errorf("Expect int type for parameter 3"),
+		},
+		{
+			name:     "split_part index type",
+			funcName: "split_part",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: ","},
+				&ast.StringLiteral{Val: "1"},
+			},
+			err: fmt.Errorf("Expect int type for parameter 3"),
+		},
+		{
+			name:     "split_part zero index",
+			funcName: "split_part",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: ","},
+				&ast.IntegerLiteral{Val: 0},
+			},
+			err: errSplitPartIndex,
+		},
+		{
+			name:     "split delimiter type",
+			funcName: "split",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.IntegerLiteral{Val: 1},
+			},
+			err: fmt.Errorf("Expect string type for parameter 2"),
+		},
+		{
+			name:     "regexp_extract group out of range",
+			funcName: "regexp_extract",
+			args: []ast.Expr{
+				&ast.FieldRef{Name: "a"},
+				&ast.StringLiteral{Val: "(a+)"},
+				&ast.IntegerLiteral{Val: 2},
+			},
+			err: fmt.Errorf("group index 2 is out of range, the regular expression (a+) has 1 groups"),
+		},
 	}
 
 	registerStrFunc()
@@ -359,3 +557,81 @@ func TestStringFuncVal(t *testing.T) {
 		})
 	}
 }
+
+func TestRegexpFunc(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "testExec")
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, contextLogger)
+	tempStore, _ := state.CreateStore("mockRule0", def.AtMostOnce)
+	fctx := kctx.NewDefaultFuncContext(ctx.WithMeta("mockRule0", "test", tempStore), 2)
+	registerStrFunc()
+	tests := []struct {
+		name     string
+		funcName string
+		args     []any
+		result   any
+		ok       bool
+	}{
+		{
+			name:     "extract group",
+			funcName: "regexp_extract",
+			args:     []any{"user:123", "([a-z]+):([0-9]+)", 2},
+			result:   "123",
+			ok:       true,
+		},
+		{
+			name:     "extract whole match",
+			funcName: "regexp_extract",
+			args:     []any{"id=user:123", "([a-z]+):([0-9]+)", 0},
+			result:   "user:123",
+			ok:       true,
+		},
+		{
+			name:     "extract no match",
+			funcName: "regexp_extract",
+			args:     []any{"user-123", "([a-z]+):([0-9]+)", 1},
+			result:   "",
+			ok:       true,
+		},
+		{
+			name:     "extract out of range",
+			funcName: "regexp_extract",
+			args:     []any{"user:123", "([a-z]+)", 2},
+			result:   errors.New("group index 2 is out of range, the regular expression ([a-z]+) has 1 groups"),
+			ok:       false,
+		},
+		{
+			name:     "extract invalid pattern",
+			funcName: "regexp_extract",
+			args:     []any{"user:123", "([a-z]+", 1},
+			result:   errors.New("error parsing regexp: missing closing ): `([a-z]+`"),
+			ok:       false,
+		},
+		{
+			name:     "replace backreference",
+			funcName: "regexp_replace",
+			args:     []any{"user:123, admin:456", "([a-z]+):([0-9]+)", "$2@${1}"},
+			result:   "123@user, 456@admin",
+			ok:       true,
+		},
+		{
+			name:     "matches",
+			funcName: "regexp_matches",
+			args:     []any{"user:123", "[0-9]+"},
+			result:   true,
+			ok:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := builtins[tt.funcName]
+			require.True(t, ok)
+			result, ok := f.exec(fctx, tt.args)
+			require.Equal(t, tt.ok, ok)
+			if err, isErr := tt.result.(error); isErr {
+				require.EqualError(t, result.(error), err.Error())
+			} else {
+				require.Equal(t, tt.result, result)
+			}
+		})
+	}
+}
@@ -51,6 +51,7 @@ func init() {
 	registerAggFunc()
 	registerIncAggFunc()
 	registerMathFunc()
+	registerGeoFunc()
 	registerStrFunc()
 	registerMiscFunc()
 	registerAnalyticFunc()
@@ -71,6 +72,7 @@ func init() {
 
 var analyticFuncs = map[string]struct{}{
 	"lag":         {},
+	"lead":        {},
 	"changed_col": {},
 	"had_changed": {},
 	"latest":      {},
@@ -0,0 +1,88 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/spaolacci/murmur3"
+)
+
+const (
+	minHllPrecision = 4
+	maxHllPrecision = 16
+	// defaultHllPrecision uses 4096 registers of 4KB with the standard error of 1.04/sqrt(4096) = 1.625%
+	defaultHllPrecision = 12
+)
+
+// hll is a HyperLogLog sketch to estimate the cardinality. It uses 2^precision registers of one byte each regardless
+// of the number of the added values.
+type hll struct {
+	p         uint8
+	registers []byte
+}
+
+func newHll(precision int) (*hll, error) {
+	if precision < minHllPrecision || precision > maxHllPrecision {
+		return nil, fmt.Errorf("precision must be between %d and %d, but got %d", minHllPrecision, maxHllPrecision, precision)
+	}
+	return &hll{p: uint8(precision), registers: make([]byte, 1<<precision)}, nil
+}
+
+// hllFromRegisters restores the sketch from the registers saved in the state
+func hllFromRegisters(registers []byte) *hll {
+	return &hll{p: uint8(bits.TrailingZeros(uint(len(registers)))), registers: registers}
+}
+
+func (h *hll) add(v any) {
+	x := murmur3.Sum64(hashBytes(v))
+	idx := x >> (64 - h.p)
+	// The remaining bits with a sentinel bit so that the rank is at most 64-p+1
+	w := x<<h.p | 1<<(h.p-1)
+	rank := byte(bits.LeadingZeros64(w) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+func (h *hll) count() int64 {
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	var alpha float64
+	switch len(h.registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	e := alpha * m * m / sum
+	// Use linear counting for the small cardinality
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return int64(e + 0.5)
+}
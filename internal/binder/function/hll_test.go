@@ -0,0 +1,56 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHllAccuracy(t *testing.T) {
+	for _, n := range []int{1000, 100000} {
+		h, err := newHll(defaultHllPrecision)
+		require.NoError(t, err)
+		for i := 0; i < n; i++ {
+			h.add(fmt.Sprintf("value%d", i))
+			// duplicates do not change the estimation
+			h.add(fmt.Sprintf("value%d", i))
+		}
+		got := h.count()
+		assert.LessOrEqual(t, math.Abs(float64(got-int64(n)))/float64(n), 0.05, "count %d but got %d", n, got)
+	}
+}
+
+func TestHllRestore(t *testing.T) {
+	h, err := newHll(10)
+	require.NoError(t, err)
+	for i := 0; i < 500; i++ {
+		h.add(i)
+	}
+	r := hllFromRegisters(h.registers)
+	assert.Equal(t, uint8(10), r.p)
+	assert.Equal(t, h.count(), r.count())
+}
+
+func TestHllPrecision(t *testing.T) {
+	_, err := newHll(3)
+	assert.EqualError(t, err, "precision must be between 4 and 16, but got 3")
+	_, err = newHll(17)
+	assert.EqualError(t, err, "precision must be between 4 and 16, but got 17")
+}
@@ -19,10 +19,14 @@ package io
 import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/amqp"
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/grpc"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/image"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/influx"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/influx2"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/kafka"
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/prometheus"
+	"github.com/lf-edge/ekuiper/v2/extensions/impl/pulsar"
 	sql2 "github.com/lf-edge/ekuiper/v2/extensions/impl/sql"
 	"github.com/lf-edge/ekuiper/v2/extensions/impl/video"
 	"github.com/lf-edge/ekuiper/v2/pkg/modules"
@@ -32,9 +36,15 @@ func init() {
 	modules.RegisterSource("video", func() api.Source { return video.GetSource() })
 	modules.RegisterSource("kafka", func() api.Source { return kafka.GetSource() })
 	modules.RegisterSink("kafka", func() api.Sink { return kafka.GetSink() })
+	modules.RegisterSource("pulsar", pulsar.GetSource)
+	modules.RegisterSink("pulsar", pulsar.GetSink)
+	modules.RegisterSource("amqp", amqp.GetSource)
+	modules.RegisterSink("amqp", amqp.GetSink)
 	modules.RegisterSink("image", func() api.Sink { return image.GetSink() })
 	modules.RegisterSink("influx", func() api.Sink { return influx.GetSink() })
 	modules.RegisterSink("influx2", func() api.Sink { return influx2.GetSink() })
+	modules.RegisterSink("prometheus", prometheus.GetSink)
+	modules.RegisterSink("grpc", grpc.GetSink)
 	modules.RegisterSource("sql", sql2.GetSource)
 	modules.RegisterLookupSource("sql", sql2.GetLookupSource)
 	modules.RegisterSink("sql", sql2.GetSink)
@@ -22,8 +22,11 @@ import (
 )
 
 func init() {
+	modules.RegisterConnection("redis", redis.CreateConnection)
+	modules.RegisterSource("redis", redis.GetSource)
 	modules.RegisterLookupSource("redis", redis.GetLookupSource)
 	modules.RegisterSink("redis", redis.GetSink)
 	modules.RegisterSink("redisPub", redis.RedisPub)
 	modules.RegisterSource("redisSub", redis.RedisSub)
+	modules.RegisterSource("redisStream", redis.GetStreamSource)
 }
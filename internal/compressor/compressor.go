@@ -21,6 +21,9 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
+// NONE is the same as not setting the compression
+const NONE = "none"
+
 type CompressorInstantiator func(name string) (message.Compressor, error)
 
 var compressors = map[string]CompressorInstantiator{}
@@ -19,16 +19,18 @@ package compressor
 import (
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/flate"
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/gzip"
+	"github.com/lf-edge/ekuiper/v2/internal/compressor/snappy"
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/zlib"
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/zstd"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
 const (
-	ZLIB  = "zlib"
-	GZIP  = "gzip"
-	FLATE = "flate"
-	ZSTD  = "zstd"
+	ZLIB   = "zlib"
+	GZIP   = "gzip"
+	FLATE  = "flate"
+	ZSTD   = "zstd"
+	SNAPPY = "snappy"
 )
 
 func init() {
@@ -44,7 +46,11 @@ func init() {
 	compressors[ZSTD] = func(name string) (message.Compressor, error) {
 		return zstd.NewZstdCompressor()
 	}
+	compressors[SNAPPY] = func(name string) (message.Compressor, error) {
+		return snappy.NewSnappyCompressor()
+	}
 
 	compressWriters[GZIP] = gzip.NewWriter
 	compressWriters[ZSTD] = zstd.NewWriter
+	compressWriters[SNAPPY] = snappy.NewWriter
 }
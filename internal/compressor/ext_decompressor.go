@@ -19,6 +19,7 @@ package compressor
 import (
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/flate"
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/gzip"
+	"github.com/lf-edge/ekuiper/v2/internal/compressor/snappy"
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/zlib"
 	"github.com/lf-edge/ekuiper/v2/internal/compressor/zstd"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
@@ -37,7 +38,11 @@ func init() {
 	decompressors[ZSTD] = func(name string) (message.Decompressor, error) {
 		return zstd.NewzstdDecompressor()
 	}
+	decompressors[SNAPPY] = func(name string) (message.Decompressor, error) {
+		return snappy.NewSnappyDecompressor()
+	}
 
 	decompressReaders[GZIP] = gzip.NewReader
 	decompressReaders[ZSTD] = zstd.NewReader
+	decompressReaders[SNAPPY] = snappy.NewReader
 }
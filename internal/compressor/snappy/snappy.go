@@ -0,0 +1,72 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snappy
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/klauspost/compress/s2"
+)
+
+// NewSnappyCompressor compresses in the snappy framing format, so the payload and the file written by the stream
+// writer can be decompressed in the same way.
+func NewSnappyCompressor() (*snappyCompressor, error) {
+	return &snappyCompressor{
+		writer: s2.NewWriter(nil, s2.WriterSnappyCompat()),
+	}, nil
+}
+
+type snappyCompressor struct {
+	writer *s2.Writer
+	buffer bytes.Buffer
+}
+
+func (s *snappyCompressor) Compress(data []byte) ([]byte, error) {
+	s.buffer.Reset()
+	s.writer.Reset(&s.buffer)
+	_, err := s.writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = s.writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return s.buffer.Bytes(), nil
+}
+
+func NewSnappyDecompressor() (*snappyDecompressor, error) {
+	return &snappyDecompressor{
+		reader: s2.NewReader(nil),
+	}, nil
+}
+
+type snappyDecompressor struct {
+	reader *s2.Reader
+}
+
+func (s *snappyDecompressor) Decompress(data []byte) ([]byte, error) {
+	s.reader.Reset(bytes.NewReader(data))
+	return io.ReadAll(s.reader)
+}
+
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(s2.NewReader(r)), nil
+}
+
+func NewWriter(w io.Writer) (io.Writer, error) {
+	return s2.NewWriter(w, s2.WriterSnappyCompat()), nil
+}
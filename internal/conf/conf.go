@@ -68,6 +68,8 @@ type SinkConf struct {
 	ResendPriority       int               `json:"resendPriority" yaml:"resendPriority"`
 	ResendIndicatorField string            `json:"resendIndicatorField" yaml:"resendIndicatorField"`
 	ResendDestination    string            `json:"resendDestination" yaml:"resendDestination"`
+	// DeadLetter saves the results which the sink fails to deliver permanently so that they can be replayed
+	DeadLetter bool `json:"deadLetter" yaml:"deadLetter"`
 }
 
 // Validate the configuration and reset to the default value for invalid values.
@@ -197,6 +199,7 @@ type KuiperConf struct {
 		GracefulShutdownTimeout cast.DurationConf `yaml:"gracefulShutdownTimeout"`
 		EnableResourceProfiling bool              `yaml:"enableResourceProfiling"`
 		MetricsDumpConfig       MetricsDumpConfig `yaml:"metricsDumpConfig"`
+		SecretsPath             string            `yaml:"secretsPath"`
 	}
 	Rule   def.RuleOption
 	Sink   *SinkConf
@@ -217,6 +220,19 @@ type KuiperConf struct {
 		Fdb struct {
 			Path string `yaml:"path"`
 		}
+		CheckpointStore struct {
+			Type string `yaml:"type"`
+			S3   struct {
+				Endpoint     string            `yaml:"endpoint"`
+				Region       string            `yaml:"region"`
+				Bucket       string            `yaml:"bucket"`
+				AccessKey    string            `yaml:"accessKey"`
+				SecretKey    string            `yaml:"secretKey"`
+				Prefix       string            `yaml:"prefix"`
+				UsePathStyle bool              `yaml:"usePathStyle"`
+				Timeout      cast.DurationConf `yaml:"timeout"`
+			} `yaml:"s3"`
+		} `yaml:"checkpointStore"`
 	}
 	Portable struct {
 		PythonBin   string            `yaml:"pythonBin"`
@@ -243,6 +259,8 @@ type OpenTelemetry struct {
 	RemoteEndpoint        string `yaml:"remoteEndpoint"`
 	LocalTraceCapacity    int    `yaml:"localTraceCapacity"`
 	EnableLocalStorage    bool   `yaml:"enableLocalStorage"`
+	// SamplingRate is the ratio of the traces to be sampled in (0, 1]
+	SamplingRate float64 `yaml:"samplingRate"`
 }
 
 func SetLogLevel(level string, debug bool) {
@@ -446,6 +464,13 @@ func InitConf() {
 		Config.OpenTelemetry.LocalTraceCapacity = 2048
 	}
 
+	if Config.OpenTelemetry.SamplingRate <= 0 || Config.OpenTelemetry.SamplingRate > 1 {
+		if Config.OpenTelemetry.SamplingRate != 0 {
+			Log.Warnf("openTelemetry samplingRate %v is out of range (0, 1], set to 1", Config.OpenTelemetry.SamplingRate)
+		}
+		Config.OpenTelemetry.SamplingRate = 1
+	}
+
 	_ = ValidateRuleOption(&Config.Rule)
 }
 
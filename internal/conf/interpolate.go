@@ -0,0 +1,146 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	varEnv    = "env"
+	varSecret = "secret"
+)
+
+var varRegex = regexp.MustCompile(`\$\{(env|secret):([^}]*)}`)
+
+// ResolveProps returns a copy of the props in which the ${env:VAR} and ${secret:name} variables of the string values
+// are replaced by the environment variables and the secrets. The props are not modified so that the caller can log
+// them without exposing the resolved values.
+func ResolveProps(props map[string]any) (map[string]any, error) {
+	if props == nil {
+		return nil, nil
+	}
+	r, err := resolveValue(props)
+	if err != nil {
+		return nil, err
+	}
+	return r.(map[string]any), nil
+}
+
+func resolveValue(v any) (any, error) {
+	switch vt := v.(type) {
+	case string:
+		return resolveString(vt)
+	case map[string]any:
+		result := make(map[string]any, len(vt))
+		for k, val := range vt {
+			r, err := resolveValue(val)
+			if err != nil {
+				return nil, fmt.Errorf("fail to resolve property %s: %v", k, err)
+			}
+			result[k] = r
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(vt))
+		for i, val := range vt {
+			r, err := resolveValue(val)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = r
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+func resolveString(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var err error
+	r := varRegex.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return m
+		}
+		sub := varRegex.FindStringSubmatch(m)
+		var val string
+		val, err = lookupVar(sub[1], sub[2])
+		if err != nil {
+			err = fmt.Errorf("unresolved variable %s: %v", m, err)
+		}
+		return val
+	})
+	if err != nil {
+		return "", err
+	}
+	return r, nil
+}
+
+func lookupVar(kind string, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty %s name", kind)
+	}
+	switch kind {
+	case varEnv:
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+	case varSecret:
+		return readSecret(name)
+	default:
+		return "", fmt.Errorf("unknown variable type %s", kind)
+	}
+}
+
+// readSecret reads the secret from the file with the same name in the secrets directory. The trailing new line is
+// removed, so the secrets mounted by docker or kubernetes can be used directly.
+func readSecret(name string) (string, error) {
+	if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name %s", name)
+	}
+	dir, err := getSecretsLoc()
+	if err != nil {
+		return "", err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("secret %s is not found", name)
+		}
+		return "", fmt.Errorf("fail to read secret %s: %v", name, err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// getSecretsLoc returns the configured secrets path, or the secrets folder in the data directory by default
+func getSecretsLoc() (string, error) {
+	if Config != nil && Config.Basic.SecretsPath != "" {
+		return Config.Basic.SecretsPath, nil
+	}
+	dataDir, err := GetDataLoc()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "secrets"), nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveProps(t *testing.T) {
+	InitConf()
+	old := Config.Basic.SecretsPath
+	dir := t.TempDir()
+	Config.Basic.SecretsPath = dir
+	defer func() {
+		Config.Basic.SecretsPath = old
+	}()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "redisPwd"), []byte("p@ss\n"), 0o600))
+	t.Setenv("REDIS_HOST", "10.0.0.1")
+
+	props := map[string]any{
+		"addr":     "${env:REDIS_HOST}:6379",
+		"password": "${secret:redisPwd}",
+		"db":       0,
+		"other":    "$100 {env:REDIS_HOST}",
+		"nested": map[string]any{
+			"servers": []any{"${env:REDIS_HOST}", 1},
+		},
+	}
+	r, err := ResolveProps(props)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"addr":     "10.0.0.1:6379",
+		"password": "p@ss",
+		"db":       0,
+		"other":    "$100 {env:REDIS_HOST}",
+		"nested": map[string]any{
+			"servers": []any{"10.0.0.1", 1},
+		},
+	}, r)
+	// The original props are not changed
+	assert.Equal(t, "${secret:redisPwd}", props["password"])
+	assert.Equal(t, "${env:REDIS_HOST}", props["nested"].(map[string]any)["servers"].([]any)[0])
+
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "env not set",
+			props: map[string]any{"addr": "${env:KUIPER_NOT_EXIST}:6379"},
+			err:   "fail to resolve property addr: unresolved variable ${env:KUIPER_NOT_EXIST}: environment variable KUIPER_NOT_EXIST is not set",
+		},
+		{
+			name:  "secret not found",
+			props: map[string]any{"nested": map[string]any{"password": "${secret:notExist}"}},
+			err:   "fail to resolve property nested: fail to resolve property password: unresolved variable ${secret:notExist}: secret notExist is not found",
+		},
+		{
+			name:  "invalid secret name",
+			props: map[string]any{"password": "${secret:../redisPwd}"},
+			err:   "fail to resolve property password: unresolved variable ${secret:../redisPwd}: invalid secret name ../redisPwd",
+		},
+		{
+			name:  "empty name",
+			props: map[string]any{"password": "${env:}"},
+			err:   "fail to resolve property password: unresolved variable ${env:}: empty env name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveProps(tt.props)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -23,22 +23,39 @@ import (
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
 )
 
-type Converter struct{}
+// Converter passes the payload through verbatim. The payload is decoded into a single bytea field and the field is
+// written as is when encoding.
+type Converter struct {
+	// Field is the field to hold the payload, default to self
+	Field string `json:"binaryField"`
+}
 
-var converter = &Converter{}
+var converter = &Converter{Field: message.DefaultField}
 
 func GetConverter() (message.Converter, error) {
 	return converter, nil
 }
 
+// NewConverter creates the converter with the payload field set by the binaryField property
+func NewConverter(props map[string]any) (message.Converter, error) {
+	c := &Converter{}
+	if err := cast.MapToStruct(props, c); err != nil {
+		return nil, err
+	}
+	if c.Field == "" {
+		return converter, nil
+	}
+	return c, nil
+}
+
 func (c *Converter) Encode(ctx api.StreamContext, d any) (b []byte, err error) {
 	switch dt := d.(type) {
 	case map[string]any:
-		bb, ok := dt[message.DefaultField]
+		bb, ok := dt[c.Field]
 		if ok {
 			return cast.ToByteA(bb, cast.CONVERT_SAMEKIND)
 		} else {
-			return nil, fmt.Errorf("field %s not exist", message.DefaultField)
+			return nil, fmt.Errorf("field %s not exist", c.Field)
 		}
 	}
 	return nil, fmt.Errorf("unsupported type %v, must be a map", d)
@@ -46,6 +63,6 @@ func (c *Converter) Encode(ctx api.StreamContext, d any) (b []byte, err error) {
 
 func (c *Converter) Decode(ctx api.StreamContext, b []byte) (m any, err error) {
 	result := make(map[string]interface{})
-	result[message.DefaultField] = b
+	result[c.Field] = b
 	return result, nil
 }
@@ -66,3 +66,24 @@ func TestError(t *testing.T) {
 	_, err = converter.Encode(ctx, m3)
 	assert.EqualError(t, err, "cannot convert int(23) to bytea")
 }
+
+func TestBinaryField(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	conv, err := NewConverter(map[string]any{"binaryField": "raw"})
+	require.NoError(t, err)
+	payload := []byte{0x00, 0x01, 0xfe, 0xff}
+	result, err := conv.Decode(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"raw": payload}, result)
+	pp, err := conv.Encode(ctx, result)
+	require.NoError(t, err)
+	require.Equal(t, payload, pp)
+	_, err = conv.Encode(ctx, map[string]any{"self": payload})
+	assert.EqualError(t, err, "field raw not exist")
+
+	conv, err = NewConverter(map[string]any{})
+	require.NoError(t, err)
+	result, err = conv.Decode(ctx, payload)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"self": payload}, result)
+}
@@ -0,0 +1,300 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+)
+
+const (
+	// RaggedError returns error if the column count of a row does not match the header
+	RaggedError = "error"
+	// RaggedSkip drops the ragged rows
+	RaggedSkip = "skip"
+	// RaggedPad ignores the extra values and leaves the missing columns unset
+	RaggedPad = "pad"
+)
+
+type conf struct {
+	Delimiter string `json:"delimiter"`
+	HasHeader bool   `json:"hasHeader"`
+	// Columns are the column names when there is no header in decode, or the column order in encode
+	Columns []string `json:"columns"`
+	// Fields is the fields property of the sink, it is used as the column order in encode if columns is not set
+	Fields []string `json:"fields"`
+	// RaggedRows is the policy to handle the rows whose column count does not match, could be error, skip or pad
+	RaggedRows string `json:"raggedRows"`
+}
+
+// Converter is the csv format codec. Each csv row is decoded as a map keyed by the header or column names.
+// If the stream schema is defined, only the columns in the schema are decoded and converted to the defined type.
+// Otherwise, all the columns are decoded as string.
+type Converter struct {
+	sync.RWMutex
+	conf
+	comma  rune
+	schema map[string]*ast.JsonStreamField
+}
+
+func NewConverter(schema map[string]*ast.JsonStreamField, props map[string]any) (message.Converter, error) {
+	c := &Converter{
+		conf: conf{
+			Delimiter:  ",",
+			RaggedRows: RaggedError,
+		},
+		schema: schema,
+	}
+	err := cast.MapToStruct(props, &c.conf)
+	if err != nil {
+		return nil, err
+	}
+	if c.Delimiter == "" {
+		c.Delimiter = ","
+	}
+	if len(c.Columns) == 0 {
+		c.Columns = c.Fields
+	}
+	if utf8.RuneCountInString(c.Delimiter) != 1 {
+		return nil, fmt.Errorf("csv delimiter must be a single character, but got %s", c.Delimiter)
+	}
+	c.comma, _ = utf8.DecodeRuneInString(c.Delimiter)
+	if c.comma == '"' || c.comma == '\r' || c.comma == '\n' {
+		return nil, fmt.Errorf("invalid csv delimiter %q", c.Delimiter)
+	}
+	switch c.RaggedRows {
+	case RaggedError, RaggedSkip, RaggedPad:
+	default:
+		return nil, fmt.Errorf("raggedRows must be one of error, skip or pad, but got %s", c.RaggedRows)
+	}
+	return c, nil
+}
+
+func (c *Converter) ResetSchema(schema map[string]*ast.JsonStreamField) {
+	c.Lock()
+	defer c.Unlock()
+	c.schema = schema
+}
+
+// Encode writes the map or map list as csv rows. The nested maps are flattened with dot separated keys.
+// If no columns defined, the column order is sorted by the flattened keys of the first row.
+func (c *Converter) Encode(_ api.StreamContext, d any) (b []byte, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	var rows []map[string]any
+	switch m := d.(type) {
+	case map[string]any:
+		rows = []map[string]any{flatten(m)}
+	case []map[string]any:
+		rows = make([]map[string]any, len(m))
+		for i, mm := range m {
+			rows[i] = flatten(mm)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %v, must be a map or map list", d)
+	}
+	cols := c.Columns
+	if len(cols) == 0 && len(rows) > 0 {
+		cols = make([]string, 0, len(rows[0]))
+		for k := range rows[0] {
+			cols = append(cols, k)
+		}
+		sort.Strings(cols)
+	}
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Comma = c.comma
+	if c.HasHeader && len(cols) > 0 {
+		if err := w.Write(cols); err != nil {
+			return nil, err
+		}
+	}
+	record := make([]string, len(cols))
+	for _, row := range rows {
+		for i, col := range cols {
+			record[i] = toString(row[col])
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Decode reads the csv rows. If there is only one row, a map is returned, otherwise a map list is returned.
+// If the header and the columns are not set, the default key name is col0, col1, col2...
+func (c *Converter) Decode(_ api.StreamContext, b []byte) (ma any, err error) {
+	defer func() {
+		if err != nil {
+			err = errorx.NewWithCode(errorx.CovnerterErr, err.Error())
+		}
+	}()
+	c.RLock()
+	defer c.RUnlock()
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = c.comma
+	// The column count is checked by the ragged rows policy
+	r.FieldsPerRecord = -1
+	cols := c.Columns
+	if c.HasHeader {
+		header, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("csv header is missing")
+			}
+			return nil, err
+		}
+		// remove the utf8 bom of the excel exported csv
+		if len(header) > 0 {
+			header[0] = string(bytes.TrimPrefix([]byte(header[0]), []byte("\xef\xbb\xbf")))
+		}
+		cols = header
+	}
+	var result []map[string]any
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(cols) > 0 && len(record) != len(cols) {
+			switch c.RaggedRows {
+			case RaggedSkip:
+				continue
+			case RaggedError:
+				return nil, fmt.Errorf("csv row %d has %d columns, but expect %d", line, len(record), len(cols))
+			}
+		}
+		m := make(map[string]any, len(record))
+		for i, v := range record {
+			var key string
+			if len(cols) == 0 {
+				key = "col" + strconv.Itoa(i)
+			} else if i < len(cols) {
+				key = cols[i]
+			} else {
+				break
+			}
+			f, ok := c.schema[key]
+			if c.schema != nil && !ok {
+				continue
+			}
+			m[key], err = convert(key, v, f)
+			if err != nil {
+				return nil, fmt.Errorf("csv row %d: %v", line, err)
+			}
+		}
+		result = append(result, m)
+	}
+	if len(result) == 1 {
+		return result[0], nil
+	}
+	if result == nil {
+		result = []map[string]any{}
+	}
+	return result, nil
+}
+
+// convert casts the string value to the type defined in the schema field. Empty value of non string type is converted to nil.
+func convert(key string, v string, f *ast.JsonStreamField) (any, error) {
+	if f == nil {
+		return v, nil
+	}
+	var (
+		r   any
+		err error
+	)
+	switch f.Type {
+	case "bigint":
+		if v == "" {
+			return nil, nil
+		}
+		r, err = cast.ToInt64(v, cast.CONVERT_ALL)
+	case "float":
+		if v == "" {
+			return nil, nil
+		}
+		r, err = cast.ToFloat64(v, cast.CONVERT_ALL)
+	case "boolean":
+		if v == "" {
+			return nil, nil
+		}
+		r, err = cast.ToBool(v, cast.CONVERT_ALL)
+	default:
+		return v, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert value %s of column %s to %s", v, key, f.Type)
+	}
+	return r, nil
+}
+
+// flatten flattens the nested maps with dot separated keys
+func flatten(m map[string]any) map[string]any {
+	r := make(map[string]any, len(m))
+	flattenTo(r, "", m)
+	return r
+}
+
+func flattenTo(r map[string]any, prefix string, m map[string]any) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if mm, ok := v.(map[string]any); ok {
+			flattenTo(r, k, mm)
+		} else {
+			r[k] = v
+		}
+	}
+}
+
+func toString(v any) string {
+	switch vt := v.(type) {
+	case nil:
+		return ""
+	case []any, []map[string]any:
+		b, err := json.Marshal(vt)
+		if err == nil {
+			return string(b)
+		}
+	}
+	s, _ := cast.ToString(v, cast.CONVERT_ALL)
+	return s
+}
@@ -0,0 +1,254 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestNewConverter(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "default",
+			props: map[string]any{},
+		},
+		{
+			name:  "multiple char delimiter",
+			props: map[string]any{"delimiter": "::"},
+			err:   "csv delimiter must be a single character, but got ::",
+		},
+		{
+			name:  "invalid delimiter",
+			props: map[string]any{"delimiter": "\""},
+			err:   "invalid csv delimiter \"\\\"\"",
+		},
+		{
+			name:  "invalid ragged rows",
+			props: map[string]any{"raggedRows": "fix"},
+			err:   "raggedRows must be one of error, skip or pad, but got fix",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(nil, tt.props)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		m     any
+		r     string
+		e     string
+	}{
+		{
+			name:  "normal",
+			props: map[string]any{},
+			m:     map[string]any{"id": 1, "name": "test"},
+			r:     "1,test",
+		},
+		{
+			name:  "header",
+			props: map[string]any{"hasHeader": true},
+			m:     map[string]any{"id": 1, "name": "test"},
+			r:     "id,name\n1,test",
+		},
+		{
+			name:  "quote",
+			props: map[string]any{"hasHeader": true, "columns": []string{"name", "id", "desc"}},
+			m:     map[string]any{"id": 1, "name": "Doe, John", "desc": `say "hi"`},
+			r:     "name,id,desc\n\"Doe, John\",1,\"say \"\"hi\"\"\"",
+		},
+		{
+			name:  "nested",
+			props: map[string]any{"hasHeader": true, "delimiter": ";"},
+			m: map[string]any{
+				"id":      7,
+				"hobbies": map[string]any{"indoor": []any{"Chess"}, "outdoor": "Basketball"},
+				"valid":   nil,
+			},
+			r: "hobbies.indoor;hobbies.outdoor;id;valid\n\"[\"\"Chess\"\"]\";Basketball;7;",
+		},
+		{
+			name:  "list",
+			props: map[string]any{"fields": []string{"name", "id"}},
+			m: []map[string]any{
+				{"id": 12, "name": "test"},
+				{"id": 14, "name": "test2", "other": true},
+			},
+			r: "test,12\ntest2,14",
+		},
+		{
+			name:  "unsupported",
+			props: map[string]any{},
+			m:     "test",
+			e:     "unsupported type test, must be a map or map list",
+		},
+	}
+	ctx := mockContext.NewMockContext("test", "op1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(nil, tt.props)
+			require.NoError(t, err)
+			r, err := c.Encode(ctx, tt.m)
+			if tt.e != "" {
+				assert.EqualError(t, err, tt.e)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.r, string(r))
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	schema := map[string]*ast.JsonStreamField{
+		"id":    {Type: "bigint"},
+		"name":  {Type: "string"},
+		"temp":  {Type: "float"},
+		"valid": {Type: "boolean"},
+	}
+	tests := []struct {
+		name   string
+		props  map[string]any
+		schema map[string]*ast.JsonStreamField
+		b      string
+		m      any
+		e      string
+	}{
+		{
+			name:  "no header",
+			props: map[string]any{},
+			b:     "1,test",
+			m:     map[string]any{"col0": "1", "col1": "test"},
+		},
+		{
+			name:  "columns",
+			props: map[string]any{"columns": []string{"id", "name"}, "delimiter": "\t"},
+			b:     "1\ttest",
+			m:     map[string]any{"id": "1", "name": "test"},
+		},
+		{
+			name:   "header with schema",
+			props:  map[string]any{"hasHeader": true},
+			schema: schema,
+			b:      "\xef\xbb\xbfid,name,temp,valid,other\n1,\"Doe, John\",23.5,true,x\n2,\"say \"\"hi\"\"\",,false,y",
+			m: []map[string]any{
+				{"id": int64(1), "name": "Doe, John", "temp": 23.5, "valid": true},
+				{"id": int64(2), "name": `say "hi"`, "temp": nil, "valid": false},
+			},
+		},
+		{
+			name:  "multiline value",
+			props: map[string]any{"hasHeader": true},
+			b:     "id,desc\n1,\"line1\nline2\"",
+			m:     map[string]any{"id": "1", "desc": "line1\nline2"},
+		},
+		{
+			name:  "ragged error",
+			props: map[string]any{"hasHeader": true},
+			b:     "id,name\n1,test\n2",
+			e:     "csv row 2 has 1 columns, but expect 2",
+		},
+		{
+			name:  "ragged skip",
+			props: map[string]any{"hasHeader": true, "raggedRows": "skip"},
+			b:     "id,name\n1,test\n2\n3,test3,extra",
+			m:     map[string]any{"id": "1", "name": "test"},
+		},
+		{
+			name:  "ragged pad",
+			props: map[string]any{"hasHeader": true, "raggedRows": "pad"},
+			b:     "id,name\n1,test\n2\n3,test3,extra",
+			m: []map[string]any{
+				{"id": "1", "name": "test"},
+				{"id": "2"},
+				{"id": "3", "name": "test3"},
+			},
+		},
+		{
+			name:  "header only",
+			props: map[string]any{"hasHeader": true},
+			b:     "id,name",
+			m:     []map[string]any{},
+		},
+		{
+			name:  "missing header",
+			props: map[string]any{"hasHeader": true},
+			b:     "",
+			e:     "csv header is missing",
+		},
+		{
+			name:   "invalid type",
+			props:  map[string]any{"columns": []string{"id", "name"}},
+			schema: schema,
+			b:      "abc,test",
+			e:      "csv row 1: cannot convert value abc of column id to bigint",
+		},
+		{
+			name:  "invalid quote",
+			props: map[string]any{},
+			b:     "1,te\"st",
+			e:     "bare \" in non-quoted-field",
+		},
+	}
+	ctx := mockContext.NewMockContext("test", "op1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(tt.schema, tt.props)
+			require.NoError(t, err)
+			m, err := c.Decode(ctx, []byte(tt.b))
+			if tt.e != "" {
+				assert.ErrorContains(t, err, tt.e)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.m, m)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	props := map[string]any{"hasHeader": true, "columns": []string{"id", "desc"}}
+	c, err := NewConverter(map[string]*ast.JsonStreamField{"id": {Type: "bigint"}, "desc": {Type: "string"}}, props)
+	require.NoError(t, err)
+	data := []map[string]any{
+		{"id": int64(1), "desc": "a,b"},
+		{"id": int64(2), "desc": "quote \" and\nnew line"},
+	}
+	b, err := c.Encode(ctx, data)
+	require.NoError(t, err)
+	m, err := c.Decode(ctx, b)
+	require.NoError(t, err)
+	assert.Equal(t, data, m)
+}
@@ -37,6 +37,10 @@ type FastJsonConverter struct {
 type FastJsonConverterConf struct {
 	UseInt64        bool              `json:"useInt64ForWholeNumber"`
 	ColAliasMapping map[string]string `json:"colAliasMapping"`
+	// NullAsMissing decodes the null value of an object key as missing key. The null in arrays are kept to keep the index
+	NullAsMissing bool `json:"nullAsMissing"`
+	// OmitNull drops the null value keys of the objects when encoding
+	OmitNull bool `json:"omitNull"`
 }
 
 func NewFastJsonConverter(schema map[string]*ast.JsonStreamField, props map[string]any) *FastJsonConverter {
@@ -61,9 +65,41 @@ func (f *FastJsonConverter) ResetSchema(schema map[string]*ast.JsonStreamField)
 }
 
 func (f *FastJsonConverter) Encode(ctx api.StreamContext, d any) (b []byte, err error) {
+	if f.OmitNull {
+		d = omitNull(d)
+	}
 	return json.Marshal(d)
 }
 
+// omitNull returns a copy of the data without the null value keys in all levels
+func omitNull(d any) any {
+	switch dt := d.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(dt))
+		for k, v := range dt {
+			if v == nil {
+				continue
+			}
+			m[k] = omitNull(v)
+		}
+		return m
+	case []map[string]any:
+		r := make([]map[string]any, len(dt))
+		for i, v := range dt {
+			r[i] = omitNull(v).(map[string]any)
+		}
+		return r
+	case []any:
+		r := make([]any, len(dt))
+		for i, v := range dt {
+			r[i] = omitNull(v)
+		}
+		return r
+	default:
+		return d
+	}
+}
+
 func (f *FastJsonConverter) Decode(ctx api.StreamContext, b []byte) (m any, err error) {
 	defer func() {
 		if err != nil {
@@ -224,6 +260,14 @@ func (f *FastJsonConverter) decodeObject(obj *fastjson.Object, schema map[string
 		var ok bool
 		switch v.Type() {
 		case fastjson.TypeNull:
+			if f.NullAsMissing {
+				return
+			}
+			if isOuter && len(f.ColAliasMapping) > 0 {
+				if alias, ok := f.ColAliasMapping[key]; ok {
+					key = alias
+				}
+			}
 			m[key] = nil
 		case fastjson.TypeObject:
 			add, valid := f.checkSchema(key, "struct", schema)
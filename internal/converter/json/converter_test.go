@@ -784,3 +784,99 @@ func TestIssue3441(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, map[string]interface{}{"id": 17952926683484.44}, m)
 }
+
+func TestNullAsMissing(t *testing.T) {
+	payload := []byte(`{"a":null,"b":{"c":null,"d":{"e":null,"f":1}},"g":[null,{"h":null,"i":2},[null]]}`)
+	ctx := mockContext.NewMockContext("test", "op1")
+	tests := []struct {
+		name   string
+		props  map[string]any
+		exp    map[string]any
+		encode string
+	}{
+		{
+			name:  "default",
+			props: nil,
+			exp: map[string]any{
+				"a": nil,
+				"b": map[string]any{"c": nil, "d": map[string]any{"e": nil, "f": float64(1)}},
+				"g": []any{nil, map[string]any{"h": nil, "i": float64(2)}, []any{nil}},
+			},
+			encode: `{"a":null,"b":{"c":null,"d":{"e":null,"f":1}},"g":[null,{"h":null,"i":2},[null]]}`,
+		},
+		{
+			name:  "null as missing",
+			props: map[string]any{"nullAsMissing": true},
+			exp: map[string]any{
+				"b": map[string]any{"d": map[string]any{"f": float64(1)}},
+				"g": []any{nil, map[string]any{"i": float64(2)}, []any{nil}},
+			},
+			encode: `{"b":{"d":{"f":1}},"g":[null,{"i":2},[null]]}`,
+		},
+		{
+			name:  "omit null",
+			props: map[string]any{"omitNull": true},
+			exp: map[string]any{
+				"a": nil,
+				"b": map[string]any{"c": nil, "d": map[string]any{"e": nil, "f": float64(1)}},
+				"g": []any{nil, map[string]any{"h": nil, "i": float64(2)}, []any{nil}},
+			},
+			encode: `{"b":{"d":{"f":1}},"g":[null,{"i":2},[null]]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFastJsonConverter(nil, tt.props)
+			m, err := f.Decode(ctx, payload)
+			require.NoError(t, err)
+			require.Equal(t, tt.exp, m)
+			b, err := f.Encode(ctx, m)
+			require.NoError(t, err)
+			require.Equal(t, tt.encode, string(b))
+			// round trip
+			m2, err := f.Decode(ctx, b)
+			require.NoError(t, err)
+			b2, err := f.Encode(ctx, m2)
+			require.NoError(t, err)
+			require.Equal(t, tt.encode, string(b2))
+		})
+	}
+}
+
+func TestOmitNullEncode(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	f := NewFastJsonConverter(nil, map[string]any{"omitNull": true})
+	data := []map[string]any{
+		{"a": nil, "b": 1},
+		{"a": 2, "b": []map[string]any{{"c": nil}}},
+	}
+	b, err := f.Encode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, `[{"b":1},{"a":2,"b":[{}]}]`, string(b))
+	// the original data must not be changed
+	require.Equal(t, map[string]any{"a": nil, "b": 1}, data[0])
+}
+
+func TestNestedEncodeRoundTrip(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "op1")
+	f := NewFastJsonConverter(nil, nil)
+	// the nested result built by the map and array functions
+	data := map[string]any{
+		"device":   "d1",
+		"readings": []any{21.5, nil, 65.0},
+		"location": map[string]any{
+			"lat":  31.2,
+			"tags": []any{"a", "b"},
+			"points": []any{
+				map[string]any{"x": 1.0, "ok": true},
+				[]any{2.0, 3.0},
+			},
+		},
+	}
+	b, err := f.Encode(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, `{"device":"d1","location":{"lat":31.2,"points":[{"ok":true,"x":1},[2,3]],"tags":["a","b"]},"readings":[21.5,null,65]}`, string(b))
+	m, err := f.Decode(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, data, m)
+}
@@ -40,6 +40,15 @@ func init() {
 	protoParser = &protoparse.Parser{ImportPaths: []string{etcDir, dataDir}}
 }
 
+// ParseSchemaFile parses the proto file with the import paths of the schema registry
+func ParseSchemaFile(schemaFile string) (*desc.FileDescriptor, error) {
+	fds, err := protoParser.ParseFiles(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed: %s", schemaFile, err)
+	}
+	return fds[0], nil
+}
+
 func NewConverter(schemaFile string, soFile string, messageName string) (message.Converter, error) {
 	if soFile != "" {
 		return static.LoadStaticConverter(soFile, messageName)
@@ -67,7 +76,7 @@ func (c *Converter) Encode(ctx api.StreamContext, d any) (b []byte, err error) {
 	}()
 	switch m := d.(type) {
 	case map[string]interface{}:
-		msg, err := c.fc.encodeMap(c.descriptor, m)
+		msg, err := c.fc.EncodeMap(c.descriptor, m)
 		if err != nil {
 			return nil, err
 		}
@@ -64,7 +64,7 @@ func GetFieldConverter() *FieldConverter {
 	return fieldConverterIns
 }
 
-func (fc *FieldConverter) encodeMap(im *desc.MessageDescriptor, i interface{}) (*dynamic.Message, error) {
+func (fc *FieldConverter) EncodeMap(im *desc.MessageDescriptor, i interface{}) (*dynamic.Message, error) {
 	result := mf.NewDynamicMessage(im)
 	fields := im.GetFields()
 	if m, ok := i.(map[string]interface{}); ok {
@@ -145,7 +145,7 @@ func (fc *FieldConverter) EncodeField(field *desc.FieldDescriptor, v interface{}
 			result, err = cast.ToTypedSlice(v, func(input interface{}, sn cast.Strictness) (interface{}, error) {
 				r, err := cast.ToStringMap(input)
 				if err == nil {
-					return fc.encodeMap(field.GetMessageType(), r)
+					return fc.EncodeMap(field.GetMessageType(), r)
 				} else {
 					return nil, fmt.Errorf("invalid type for map type field '%s': %v", fn, err)
 				}
@@ -237,7 +237,7 @@ func (fc *FieldConverter) encodeSingleField(field *desc.FieldDescriptor, v inter
 	case dpb.FieldDescriptorProto_TYPE_MESSAGE:
 		r, err := cast.ToStringMap(v)
 		if err == nil {
-			return fc.encodeMap(field.GetMessageType(), r)
+			return fc.EncodeMap(field.GetMessageType(), r)
 		} else {
 			return nil, fmt.Errorf("invalid type for map type field '%s': %v", fn, err)
 		}
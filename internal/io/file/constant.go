@@ -24,8 +24,9 @@ const (
 )
 
 const (
-	GZIP = "gzip"
-	ZSTD = "zstd"
+	GZIP   = "gzip"
+	ZSTD   = "zstd"
+	SNAPPY = "snappy"
 )
 
 var fileTypes = map[FileType]struct{}{
@@ -36,6 +37,14 @@ var fileTypes = map[FileType]struct{}{
 }
 
 var compressionTypes = map[string]struct{}{
-	GZIP: {},
-	ZSTD: {},
+	GZIP:   {},
+	ZSTD:   {},
+	SNAPPY: {},
+}
+
+// compressionExts are the file extensions appended to the compressed files
+var compressionExts = map[string]string{
+	GZIP:   ".gz",
+	ZSTD:   ".zst",
+	SNAPPY: ".sz",
 }
@@ -36,6 +36,7 @@ type fileWriter struct {
 	Hook       writerHooks
 	Start      time.Time
 	Count      int
+	Size       int64
 	Compress   string
 	fileBuffer *writer.BufioWrapWriter
 	// Whether the file has written any data. It is only used to determine if new line is needed when writing data.
@@ -0,0 +1,165 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// ReplayWrapper replays the recorded records of the file. The original inter-arrival timing is preserved by sleeping
+// between the records for the difference of their tsField values divided by the speed.
+type ReplayWrapper struct {
+	f   *Source
+	eof api.EOFIngest
+}
+
+func (r *ReplayWrapper) SetEofIngest(eof api.EOFIngest) {
+	r.eof = eof
+}
+
+func (r *ReplayWrapper) Provision(ctx api.StreamContext, configs map[string]any) error {
+	return r.f.Provision(ctx, configs)
+}
+
+func (r *ReplayWrapper) Close(ctx api.StreamContext) error {
+	return r.f.Close(ctx)
+}
+
+func (r *ReplayWrapper) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	return r.f.Connect(ctx, sch)
+}
+
+func (r *ReplayWrapper) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	go func() {
+		err := infra.SafeRun(func() error {
+			r.replay(ctx, ingest, ingestError)
+			return nil
+		})
+		if err != nil {
+			ingestError(ctx, err)
+		}
+	}()
+	return nil
+}
+
+func (r *ReplayWrapper) replay(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	cfg := r.f.config
+	for round := 0; ; round++ {
+		// Each round replays from the first record, and the first record is sent without delay
+		p := &pacer{tsField: cfg.TsField, tsFormat: cfg.TsFormat, speed: cfg.Speed}
+		r.f.Load(ctx, func(ctx api.StreamContext, data any, meta map[string]any, _ time.Time) {
+			if !p.wait(ctx, data) {
+				return
+			}
+			ingest(ctx, data, meta, timex.GetNow())
+		}, ingestError)
+		if ctx.Err() != nil {
+			return
+		}
+		if !cfg.Loop {
+			break
+		}
+		ctx.GetLogger().Debugf("file replay round %d finished, restart", round)
+		// read all the files of the directory again
+		r.f.rewindMeta = &FileDirSourceRewindMeta{}
+	}
+	ctx.GetLogger().Infof("file replay %s finished", r.f.file)
+	if r.eof != nil {
+		r.eof(ctx)
+	}
+}
+
+// pacer delays the records according to the timestamp of the previous record
+type pacer struct {
+	tsField  string
+	tsFormat string
+	speed    float64
+	last     int64
+	started  bool
+}
+
+// wait sleeps until the record is due. It returns false if the rule is stopped during the wait.
+func (p *pacer) wait(ctx api.StreamContext, data any) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	ts, err := p.timestamp(data)
+	if err != nil {
+		ctx.GetLogger().Warnf("replay record without delay, %v", err)
+		return true
+	}
+	if !p.started {
+		p.started = true
+		p.last = ts
+		return true
+	}
+	d := time.Duration(float64(ts-p.last) * float64(time.Millisecond) / p.speed)
+	p.last = ts
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (p *pacer) timestamp(data any) (int64, error) {
+	var m map[string]any
+	switch dt := data.(type) {
+	case map[string]any:
+		m = dt
+	case []byte:
+		// the lines file type is not decoded yet, only parse the json line to get the timestamp
+		if err := json.Unmarshal(dt, &m); err != nil {
+			return 0, fmt.Errorf("invalid json line: %v", err)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported record type %T", data)
+	}
+	v, ok := m[p.tsField]
+	if !ok || v == nil {
+		return 0, fmt.Errorf("tsField %s not found", p.tsField)
+	}
+	// the values of the csv file type are strings
+	if s, ok := v.(string); ok {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	ts, err := cast.InterfaceToUnixMilli(v, p.tsFormat)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tsField %s value %v: %v", p.tsField, v, err)
+	}
+	return ts, nil
+}
+
+var (
+	_ api.TupleSource = &ReplayWrapper{}
+	_ api.Bounded     = &ReplayWrapper{}
+)
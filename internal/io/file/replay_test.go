@@ -0,0 +1,172 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestReplayConfig(t *testing.T) {
+	path, err := os.Getwd()
+	require.NoError(t, err)
+	path = filepath.Join(path, "test")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "not stream reader",
+			props: map[string]any{"path": path, "fileType": "json", "datasource": "json", "replay": true, "tsField": "ts"},
+			err:   "replay mode does not support json file type",
+		},
+		{
+			name:  "no tsField",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "replay": true},
+			err:   "tsField is required in replay mode",
+		},
+		{
+			name:  "invalid speed",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "replay": true, "tsField": "ts", "speed": 0},
+			err:   "speed must be greater than 0",
+		},
+		{
+			name:  "tail",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "replay": true, "tsField": "ts", "tail": true},
+			err:   "replay mode cannot be used with tail mode",
+		},
+		{
+			name:  "send interval",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "replay": true, "tsField": "ts", "sendInterval": "1s"},
+			err:   "interval and sendInterval are not supported in replay mode",
+		},
+		{
+			name:  "loop with action after read",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "replay": true, "tsField": "ts", "loop": true, "actionAfterRead": 1},
+			err:   "actionAfterRead is not supported in loop replay mode",
+		},
+	}
+	ctx := mockContext.NewMockContext("testReplay", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GetSource().Provision(ctx, tt.props)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+	s := &Source{}
+	require.NoError(t, s.Provision(ctx, map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "replay": true, "tsField": "ts"}))
+	assert.Equal(t, &ReplayWrapper{f: s}, s.TransformType())
+}
+
+type replayCollector struct {
+	sync.Mutex
+	data  []any
+	times []time.Time
+}
+
+func (c *replayCollector) ingest(_ api.StreamContext, data any, _ map[string]any, _ time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	c.data = append(c.data, data)
+	c.times = append(c.times, time.Now())
+}
+
+func (c *replayCollector) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.data)
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "replay.csv"), []byte("id,ts\n1,1000\n2,1200\n3,1200\n4,1600\n"), 0o644))
+	ctx, cancel := mockContext.NewMockContext("testReplay", "op").WithCancel()
+	defer cancel()
+	s := &Source{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"path":       dir,
+		"fileType":   "csv",
+		"datasource": "replay.csv",
+		"hasHeader":  true,
+		"replay":     true,
+		"tsField":    "ts",
+		"speed":      2,
+	}))
+	r := s.TransformType().(*ReplayWrapper)
+	eof := make(chan struct{})
+	r.SetEofIngest(func(ctx api.StreamContext) {
+		close(eof)
+	})
+	c := &replayCollector{}
+	require.NoError(t, r.Subscribe(ctx, c.ingest, func(ctx api.StreamContext, err error) {
+		t.Error(err)
+	}))
+	select {
+	case <-eof:
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay timeout")
+	}
+	require.Equal(t, []any{
+		map[string]any{"id": "1", "ts": "1000"},
+		map[string]any{"id": "2", "ts": "1200"},
+		map[string]any{"id": "3", "ts": "1200"},
+		map[string]any{"id": "4", "ts": "1600"},
+	}, c.data)
+	// The gaps are 200ms, 0 and 400ms in the record, and halved by the speed
+	for i, exp := range []time.Duration{100 * time.Millisecond, 0, 200 * time.Millisecond} {
+		d := c.times[i+1].Sub(c.times[i])
+		assert.GreaterOrEqual(t, d, exp, "gap %d", i)
+		assert.Less(t, d, exp+80*time.Millisecond, "gap %d", i)
+	}
+}
+
+func TestReplayLoop(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "replay.lines"), []byte("{\"id\":1,\"ts\":1000}\n{\"id\":2,\"ts\":1010}\n"), 0o644))
+	ctx, cancel := mockContext.NewMockContext("testReplayLoop", "op").WithCancel()
+	s := &Source{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"path":       dir,
+		"fileType":   "lines",
+		"datasource": "replay.lines",
+		"replay":     true,
+		"tsField":    "ts",
+		"loop":       true,
+	}))
+	r := s.TransformType().(*ReplayWrapper)
+	c := &replayCollector{}
+	require.NoError(t, r.Subscribe(ctx, c.ingest, func(ctx api.StreamContext, err error) {
+		t.Error(err)
+	}))
+	require.Eventually(t, func() bool {
+		return c.len() >= 6
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	c.Lock()
+	defer c.Unlock()
+	assert.Equal(t, []byte(`{"id":1,"ts":1000}`), c.data[0])
+	assert.Equal(t, []byte(`{"id":2,"ts":1010}`), c.data[1])
+	assert.Equal(t, []byte(`{"id":1,"ts":1000}`), c.data[2])
+}
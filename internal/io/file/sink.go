@@ -19,6 +19,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/internal/compressor"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
@@ -37,6 +39,8 @@ import (
 type sinkConf struct {
 	RollingInterval    cast.DurationConf `json:"rollingInterval"`
 	RollingCount       int               `json:"rollingCount"`
+	RollingSize        int64             `json:"rollingSize"`        // the max bytes of data written to a file
+	RollingMaxFiles    int               `json:"rollingMaxFiles"`    // the max count of rolled files to retain, the oldest are deleted
 	RollingNamePattern string            `json:"rollingNamePattern"` // where to add the timestamp to the file name
 	RollingHook        string            `json:"rollingHook"`
 	RollingHookProps   map[string]any    `json:"rollingHookProps"`
@@ -58,6 +62,10 @@ type fileSink struct {
 	fws      map[string]*fileWriter
 	rollHook modules.RollHook
 	headers  string
+	// the last timestamp used in the file name of each path to make sure the rolled file names are unique
+	lastTs map[string]int64
+	// the rolled files of each path in order, only used when rollingMaxFiles is set
+	rolled map[string][]string
 }
 
 func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}) error {
@@ -76,12 +84,18 @@ func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}
 	if c.RollingCount < 0 {
 		return fmt.Errorf("rollingCount must be positive")
 	}
+	if c.RollingSize < 0 {
+		return fmt.Errorf("rollingSize must be positive")
+	}
+	if c.RollingMaxFiles < 0 {
+		return fmt.Errorf("rollingMaxFiles must be positive")
+	}
 
 	if c.CheckInterval < 0 {
 		return fmt.Errorf("checkInterval must be positive")
 	}
-	if c.RollingInterval == 0 && c.RollingCount == 0 {
-		return fmt.Errorf("one of rollingInterval and rollingCount must be set")
+	if c.RollingInterval == 0 && c.RollingCount == 0 && c.RollingSize == 0 {
+		return fmt.Errorf("one of rollingInterval, rollingCount and rollingSize must be set")
 	}
 	if c.RollingInterval > 0 && c.RollingInterval < c.CheckInterval {
 		c.CheckInterval = c.RollingInterval
@@ -106,8 +120,11 @@ func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}
 		}
 	}
 
+	if c.Compression == compressor.NONE {
+		c.Compression = ""
+	}
 	if _, ok := compressionTypes[c.Compression]; !ok && c.Compression != "" {
-		return fmt.Errorf("compression must be one of gzip, zstd")
+		return fmt.Errorf("compression must be one of gzip, zstd, snappy")
 	}
 	if c.RollingHook != "" {
 		h, ok := modules.GetFileRollHook(c.RollingHook)
@@ -122,6 +139,8 @@ func (m *fileSink) Provision(ctx api.StreamContext, props map[string]interface{}
 	}
 	m.c = c
 	m.fws = make(map[string]*fileWriter)
+	m.lastTs = make(map[string]int64)
+	m.rolled = make(map[string][]string)
 	return nil
 }
 
@@ -181,11 +200,24 @@ func (m *fileSink) Collect(ctx api.StreamContext, tuple api.RawTuple) error {
 
 	m.mux.Lock()
 	defer m.mux.Unlock()
+	line := fw.Hook.Line()
+	// Roll before writing to make sure the file does not exceed the size. An item larger than the size is written to a file alone.
+	if m.c.RollingSize > 0 && fw.Written && fw.Size+int64(len(line)+len(item)) > m.c.RollingSize {
+		err = m.roll(ctx, fn, fw)
+		if err != nil {
+			return err
+		}
+		fw, err = m.getOrCreateFws(ctx, fn)
+		if err != nil {
+			return err
+		}
+	}
 	if fw.Written {
-		_, e := fw.Writer.Write(fw.Hook.Line())
+		_, e := fw.Writer.Write(line)
 		if e != nil {
 			return e
 		}
+		fw.Size += int64(len(line))
 	} else {
 		fw.Written = true
 	}
@@ -193,6 +225,10 @@ func (m *fileSink) Collect(ctx api.StreamContext, tuple api.RawTuple) error {
 	if e != nil {
 		return e
 	}
+	fw.Size += int64(len(item))
+	if m.c.RollingSize > 0 && fw.Size >= m.c.RollingSize {
+		return m.roll(ctx, fn, fw)
+	}
 	if m.c.RollingCount > 0 {
 		fw.Count++
 		if fw.Count >= m.c.RollingCount {
@@ -237,9 +273,28 @@ func (m *fileSink) roll(ctx api.StreamContext, k string, v *fileWriter) error {
 	delete(m.fws, k)
 	// The file will be created when the next item comes
 	v.Written = false
+	m.cleanRolled(ctx, k, v.File.Name())
 	return nil
 }
 
+// cleanRolled deletes the oldest rolled files of the path if the count exceeds rollingMaxFiles.
+// Only the files with timestamp in the name are counted because the others are overwritten.
+func (m *fileSink) cleanRolled(ctx api.StreamContext, k string, fn string) {
+	if m.c.RollingMaxFiles <= 0 || (m.c.RollingNamePattern != "prefix" && m.c.RollingNamePattern != "suffix") {
+		return
+	}
+	files := append(m.rolled[k], fn)
+	for len(files) > m.c.RollingMaxFiles {
+		ctx.GetLogger().Infof("delete rolled file %s", files[0])
+		err := os.Remove(files[0])
+		if err != nil && !os.IsNotExist(err) {
+			ctx.GetLogger().Errorf("file sink fails to delete rolled file %s: %v", files[0], err)
+		}
+		files = files[1:]
+	}
+	m.rolled[k] = files
+}
+
 // GetFws returns the file writer for the given file name, if the file writer does not exist, it will create one
 // The item is used to get the csv header if needed
 func (m *fileSink) GetFws(ctx api.StreamContext, fn string, item []byte) (*fileWriter, []byte, error) {
@@ -264,6 +319,12 @@ func (m *fileSink) GetFws(ctx api.StreamContext, fn string, item []byte) (*fileW
 			}
 		}
 	}
+	fws, err := m.getOrCreateFws(ctx, fn)
+	return fws, item, err
+}
+
+// getOrCreateFws returns the file writer of the file name or creates one. The caller must hold the lock.
+func (m *fileSink) getOrCreateFws(ctx api.StreamContext, fn string) (*fileWriter, error) {
 	fws, ok := m.fws[fn]
 	if !ok {
 		var e error
@@ -274,23 +335,38 @@ func (m *fileSink) GetFws(ctx api.StreamContext, fn string, item []byte) (*fileW
 			fileName := filepath.Base(fn)
 			switch m.c.RollingNamePattern {
 			case "prefix":
-				newFile = fmt.Sprintf("%d-%s", timex.GetNowInMilli(), fileName)
+				newFile = fmt.Sprintf("%d-%s", m.nextTs(fn), fileName)
 			case "suffix":
 				ext := filepath.Ext(fn)
-				newFile = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(fileName, ext), timex.GetNowInMilli(), ext)
+				newFile = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(fileName, ext), m.nextTs(fn), ext)
 			default:
 				newFile = fileName
 			}
+			// The rolled segments with the generated names are compressed files of their own, add the extension
+			if ext, ok := compressionExts[m.c.Compression]; ok && m.c.RollingNamePattern != "none" && !strings.HasSuffix(newFile, ext) {
+				newFile += ext
+			}
 			nfn = filepath.Join(fileDir, newFile)
 		}
 
 		fws, e = m.createFileWriter(ctx, nfn, m.c.FileType, m.headers, m.c.Compression, m.c.Encryption)
 		if e != nil {
-			return nil, item, e
+			return nil, e
 		}
 		m.fws[fn] = fws
 	}
-	return fws, item, nil
+	return fws, nil
+}
+
+// nextTs returns the timestamp for the file name. When rolling by size, the files may be rolled
+// in the same millisecond, so the timestamp is increased to avoid overwriting the previous file.
+func (m *fileSink) nextTs(fn string) int64 {
+	ts := timex.GetNowInMilli()
+	if last, ok := m.lastTs[fn]; ok && ts <= last {
+		ts = last + 1
+	}
+	m.lastTs[fn] = ts
+	return ts
 }
 
 func GetSink() api.Sink {
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -282,6 +283,13 @@ func TestFileSink_Collect(t *testing.T) {
 			content:  []byte("key\n{\"key\":\"value1\"}\n{\"key\":\"value2\"}"),
 			compress: ZSTD,
 		},
+		{
+			name:     "lines",
+			ft:       LINES_TYPE,
+			fname:    "test_lines",
+			content:  []byte("{\"key\":\"value1\"}\n{\"key\":\"value2\"}"),
+			compress: SNAPPY,
+		},
 	}
 
 	ctx := mockContext.NewMockContext("test1", "test")
@@ -425,7 +433,8 @@ func TestFileSinkRolling_Collect(t *testing.T) {
 		if err != nil {
 			return err
 		}
-		if filepath.Ext(path) == ".log" {
+		// the rolled compressed files have the extension of the compression after .log
+		if filepath.Ext(path) == ".log" || filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path))) == ".log" {
 			fmt.Println("Deleting file:", path)
 			return os.Remove(path)
 		}
@@ -502,6 +511,16 @@ func TestFileSinkRolling_Collect(t *testing.T) {
 			},
 			compress: ZSTD,
 		},
+		{
+			name:  "lines",
+			ft:    LINES_TYPE,
+			fname: "test_lines_snappy.log",
+			contents: [2][]byte{
+				[]byte("{\"key\":\"value0\",\"ts\":460}\n{\"key\":\"value1\",\"ts\":910}\n{\"key\":\"value2\",\"ts\":1360}"),
+				[]byte("{\"key\":\"value3\",\"ts\":1810}\n{\"key\":\"value4\",\"ts\":2260}"),
+			},
+			compress: SNAPPY,
+		},
 	}
 
 	// Create a stream context for testing
@@ -550,7 +569,7 @@ func TestFileSinkRolling_Collect(t *testing.T) {
 				// Read the contents of the temporary file and check if they match the collected items
 				var fn string
 				if tt.compress != "" {
-					fn = fmt.Sprintf("test_%s_%s-%d.log", tt.ft, tt.compress, 460+1350*i)
+					fn = fmt.Sprintf("test_%s_%s-%d.log%s", tt.ft, tt.compress, 460+1350*i, compressionExts[tt.compress])
 				} else {
 					fn = fmt.Sprintf("test_%s-%d.log", tt.ft, 460+1350*i)
 				}
@@ -575,6 +594,43 @@ func TestFileSinkRolling_Collect(t *testing.T) {
 	}
 }
 
+func TestFileSinkRollingSize(t *testing.T) {
+	conf.IsTesting = true
+	dir := t.TempDir()
+	ctx := mockContext.NewMockContext("rule", "testRollingSize")
+	sink := &fileSink{}
+	err := sink.Provision(ctx, map[string]interface{}{
+		"path":               filepath.Join(dir, "test_size.log"),
+		"fileType":           LINES_TYPE,
+		"rollingCount":       0,
+		"rollingSize":        25,
+		"rollingMaxFiles":    2,
+		"rollingNamePattern": "suffix",
+	})
+	assert.NoError(t, err)
+	mockclock.ResetClock(10)
+	assert.NoError(t, sink.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	items := []string{"0123456789", "0123456789", "abcdefghij", "oversized item which exceeds the rolling size", "klmnopqrst"}
+	for _, item := range items {
+		assert.NoError(t, sink.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(item)}))
+	}
+	assert.NoError(t, sink.Close(ctx))
+	// The files are rolled in the same millisecond, so the timestamps are increased.
+	// Only the newest 2 files are retained
+	for i, exp := range []string{"", "", "oversized item which exceeds the rolling size", "klmnopqrst"} {
+		fn := filepath.Join(dir, fmt.Sprintf("test_size-%d.log", 10+i))
+		contents, err := os.ReadFile(fn)
+		if exp == "" {
+			assert.True(t, os.IsNotExist(err), fn)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, exp, string(contents))
+	}
+}
+
 func TestFileSinkReopen(t *testing.T) {
 	// Remove existing files
 	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"os"
 	"path/filepath"
@@ -48,6 +49,20 @@ type SourceConfig struct {
 	MoveTo           string            `json:"moveTo"`
 	IgnoreStartLines int               `json:"ignoreStartLines"`
 	IgnoreEndLines   int               `json:"ignoreEndLines"`
+	// QuietPeriod is the duration a new file of the watched dir must stay unchanged before it is read
+	QuietPeriod cast.DurationConf `json:"quietPeriod"`
+	// Tail keeps following the file after EOF to read the appended lines
+	Tail           bool              `json:"tail"`
+	FollowInterval cast.DurationConf `json:"followInterval"`
+	// Replay sends the records paced by the difference of their timestamps in the TsField
+	Replay   bool    `json:"replay"`
+	TsField  string  `json:"tsField"`
+	TsFormat string  `json:"tsFormat"`
+	Speed    float64 `json:"speed"`
+	Loop     bool    `json:"loop"`
+	// PartitionIndex and Partitions are set by the concurrency so that each reader only reads its files of the dir
+	PartitionIndex int `json:"partitionIndex"`
+	Partitions     int `json:"partitions"`
 	// Only use for planning
 	Decompression string `json:"decompression"`
 	// state
@@ -72,7 +87,9 @@ type Source struct {
 
 func (fs *Source) Provision(ctx api.StreamContext, props map[string]any) error {
 	cfg := &SourceConfig{
-		FileType: "json",
+		FileType:       "json",
+		FollowInterval: cast.DurationConf(time.Second),
+		Speed:          1,
 	}
 	err := cast.MapToStruct(props, cfg)
 	if err != nil {
@@ -146,6 +163,30 @@ func (fs *Source) Provision(ctx api.StreamContext, props map[string]any) error {
 			}
 		}
 	}
+	if cfg.QuietPeriod < 0 {
+		return fmt.Errorf("quietPeriod must not be negative")
+	}
+	if cfg.QuietPeriod > 0 && (!fs.isDir || cfg.Interval > 0 || cfg.Replay) {
+		return fmt.Errorf("quietPeriod is only supported when watching a directory")
+	}
+	if cfg.Tail {
+		if err := validateTail(cfg, fs.isDir); err != nil {
+			return err
+		}
+	}
+	if cfg.Replay {
+		if err := validateReplay(cfg, fs.reader); err != nil {
+			return err
+		}
+	}
+	if cfg.Partitions > 1 {
+		if !fs.isDir {
+			return fmt.Errorf("concurrency is only supported when reading a directory")
+		}
+		if cfg.Replay {
+			return fmt.Errorf("concurrency is not supported in replay mode")
+		}
+	}
 	fs.config = cfg
 	decorator, ok := modules.GetFileStreamDecorator(ctx, cfg.FileType)
 	if ok {
@@ -159,6 +200,50 @@ func (fs *Source) Provision(ctx api.StreamContext, props map[string]any) error {
 	return nil
 }
 
+func validateTail(cfg *SourceConfig, isDir bool) error {
+	if cfg.FileType != string(LINES_TYPE) {
+		return fmt.Errorf("tail mode only supports lines file type")
+	}
+	if isDir {
+		return fmt.Errorf("tail mode does not support directory")
+	}
+	if cfg.Interval > 0 {
+		return fmt.Errorf("interval is not supported in tail mode")
+	}
+	if cfg.ActionAfterRead != 0 {
+		return fmt.Errorf("actionAfterRead is not supported in tail mode")
+	}
+	if cfg.IgnoreStartLines > 0 || cfg.IgnoreEndLines > 0 {
+		return fmt.Errorf("ignoreStartLines and ignoreEndLines are not supported in tail mode")
+	}
+	if cfg.FollowInterval <= 0 {
+		return fmt.Errorf("followInterval must be greater than 0")
+	}
+	return nil
+}
+
+func validateReplay(cfg *SourceConfig, reader modules.FileStreamReader) error {
+	if reader == nil {
+		return fmt.Errorf("replay mode does not support %s file type", cfg.FileType)
+	}
+	if cfg.TsField == "" {
+		return fmt.Errorf("tsField is required in replay mode")
+	}
+	if cfg.Speed <= 0 {
+		return fmt.Errorf("speed must be greater than 0")
+	}
+	if cfg.Tail {
+		return fmt.Errorf("replay mode cannot be used with tail mode")
+	}
+	if cfg.Interval > 0 || cfg.SendInterval > 0 {
+		return fmt.Errorf("interval and sendInterval are not supported in replay mode")
+	}
+	if cfg.Loop && cfg.ActionAfterRead != 0 {
+		return fmt.Errorf("actionAfterRead is not supported in loop replay mode")
+	}
+	return nil
+}
+
 func (fs *Source) Connect(_ api.StreamContext, sch api.StatusChangeHandler) error {
 	sch(api.ConnectionConnected, "")
 	return nil
@@ -223,6 +308,9 @@ func (fs *Source) Load(ctx api.StreamContext, ingest api.TupleIngest, ingestErro
 				continue
 			}
 			path := filepath.Join(fs.file, fileName)
+			if !fs.ownFile(path) {
+				continue
+			}
 			willRead, _, err := fs.checkFileRead(path)
 			if err != nil {
 				ingestError(ctx, err)
@@ -242,6 +330,28 @@ func (fs *Source) Load(ctx api.StreamContext, ingest api.TupleIngest, ingestErro
 	}
 }
 
+// ownFile returns whether the file belongs to the partition of the reader. The files are assigned by the hash of
+// their names, so each file is read by exactly one reader.
+func (fs *Source) ownFile(file string) bool {
+	if fs.config.Partitions <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(filepath.Base(file)))
+	return int(h.Sum32()%uint32(fs.config.Partitions)) == fs.config.PartitionIndex
+}
+
+// PartitionProps splits the files of the dir among the parallel readers
+func (fs *Source) PartitionProps(props map[string]any, index int, total int) (map[string]any, error) {
+	result := make(map[string]any, len(props)+2)
+	for k, v := range props {
+		result[k] = v
+	}
+	result["partitionIndex"] = index
+	result["partitions"] = total
+	return result, nil
+}
+
 func (fs *Source) parseFile(ctx api.StreamContext, file string, ingest api.TupleIngest, ingestError api.ErrorIngest) {
 	var (
 		err error
@@ -406,6 +516,12 @@ func (fs *Source) Info() (i model.NodeInfo) {
 
 // TransformType must call after provision
 func (fs *Source) TransformType() api.Source {
+	if fs.config.Tail {
+		return &TailWrapper{f: fs}
+	}
+	if fs.config.Replay {
+		return &ReplayWrapper{f: fs}
+	}
 	// If interval is not set, use watch source
 	if fs.config.Interval == 0 {
 		return &WatchWrapper{f: fs}
@@ -469,7 +585,8 @@ var (
 	// ingest possibly []byte and tuple
 	_ api.PullTupleSource = &Source{}
 	// if interval is not set, it uses inotify
-	_ api.Bounded    = &Source{}
-	_ model.InfoNode = &Source{}
-	_ api.Rewindable = &Source{}
+	_ api.Bounded             = &Source{}
+	_ model.InfoNode          = &Source{}
+	_ api.Rewindable          = &Source{}
+	_ model.PartitionedSource = &Source{}
 )
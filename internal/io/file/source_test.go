@@ -24,8 +24,10 @@ import (
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/mock"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
@@ -67,9 +69,11 @@ func TestSourceProvision(t *testing.T) {
 				"path":       path,
 			},
 			c: &SourceConfig{
-				FileName: name,
-				Path:     path,
-				FileType: string(JSON_TYPE),
+				FileName:       name,
+				Path:           path,
+				FileType:       string(JSON_TYPE),
+				FollowInterval: cast.DurationConf(time.Second),
+				Speed:          1,
 			},
 		},
 		{
@@ -135,6 +139,8 @@ func TestSourceProvision(t *testing.T) {
 				FileName:         "",
 				Path:             relPath,
 				FileType:         string(JSON_TYPE),
+				FollowInterval:   cast.DurationConf(time.Second),
+				Speed:            1,
 				IgnoreStartLines: 0,
 				IgnoreEndLines:   0,
 			},
@@ -179,6 +185,8 @@ func TestSourceProvision(t *testing.T) {
 				FileName:        name,
 				Path:            path,
 				FileType:        string(JSON_TYPE),
+				FollowInterval:  cast.DurationConf(time.Second),
+				Speed:           1,
 				ActionAfterRead: 2,
 				MoveTo:          filepath.Join(path, "ddd"),
 			},
@@ -383,3 +391,36 @@ func TestIntervalAndDir(t *testing.T) {
 		}
 	})
 }
+
+func TestPartitionDir(t *testing.T) {
+	dir := t.TempDir()
+	names := make([]string, 20)
+	for i := range names {
+		names[i] = filepath.Join(dir, fmt.Sprintf("f%d.json", i))
+		require.NoError(t, os.WriteFile(names[i], []byte(`{"a":1}`), 0o644))
+	}
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	props := map[string]any{"path": dir, "fileType": "json", "interval": "1s"}
+	owners := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		fs := &Source{}
+		p, err := fs.PartitionProps(props, i, 3)
+		require.NoError(t, err)
+		require.NoError(t, fs.Provision(ctx, p))
+		for _, name := range names {
+			if fs.ownFile(name) {
+				owners[name]++
+			}
+		}
+	}
+	// each file is read by exactly one reader
+	require.Len(t, owners, len(names))
+	for _, name := range names {
+		require.Equal(t, 1, owners[name], name)
+	}
+
+	fs := &Source{}
+	p, err := fs.PartitionProps(map[string]any{"path": dir, "datasource": "f0.json", "fileType": "json"}, 0, 2)
+	require.NoError(t, err)
+	require.EqualError(t, fs.Provision(ctx, p), "concurrency is only supported when reading a directory")
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// TailWrapper follows the growing file like `tail -F`. It reads the file from the beginning and keeps the file open
+// after EOF to emit the newly appended lines. The file is polled by the followInterval to detect the truncation and
+// the rotation.
+type TailWrapper struct {
+	f *Source
+}
+
+func (t *TailWrapper) Provision(ctx api.StreamContext, configs map[string]any) error {
+	return t.f.Provision(ctx, configs)
+}
+
+func (t *TailWrapper) Close(ctx api.StreamContext) error {
+	return t.f.Close(ctx)
+}
+
+func (t *TailWrapper) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	return t.f.Connect(ctx, sch)
+}
+
+func (t *TailWrapper) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	tl := &tailer{path: t.f.file}
+	if err := tl.open(); err != nil {
+		return err
+	}
+	meta := map[string]any{"file": t.f.file}
+	go func() {
+		err := infra.SafeRun(func() error {
+			tl.follow(ctx, time.Duration(t.f.config.FollowInterval), func(line []byte) {
+				ingest(ctx, line, meta, timex.GetNow())
+			}, func(err error) {
+				ingestError(ctx, err)
+			})
+			return nil
+		})
+		if err != nil {
+			ingestError(ctx, err)
+		}
+	}()
+	return nil
+}
+
+// tailer reads the lines of the file incrementally
+type tailer struct {
+	path   string
+	file   *os.File
+	info   os.FileInfo
+	reader *bufio.Reader
+	// the read position of the file, used to detect truncation
+	offset int64
+	// the trailing line which has no newline yet
+	partial []byte
+}
+
+func (t *tailer) open() error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	t.file = f
+	t.info = info
+	t.reader = bufio.NewReader(f)
+	t.offset = 0
+	t.partial = nil
+	return nil
+}
+
+func (t *tailer) close() {
+	if t.file != nil {
+		_ = t.file.Close()
+		t.file = nil
+	}
+}
+
+func (t *tailer) follow(ctx api.StreamContext, interval time.Duration, emit func([]byte), emitErr func(error)) {
+	defer t.close()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := t.readLines(ctx, emit); err != nil {
+			emitErr(err)
+		}
+		select {
+		case <-ctx.Done():
+			ctx.GetLogger().Infof("file tail %s exit", t.path)
+			return
+		case <-ticker.C:
+		}
+		if err := t.checkFile(ctx, emit); err != nil {
+			emitErr(err)
+		}
+	}
+}
+
+// readLines emits all the complete lines until EOF. The incomplete trailing line is buffered until the newline arrives.
+func (t *tailer) readLines(ctx api.StreamContext, emit func([]byte)) error {
+	if t.file == nil {
+		return nil
+	}
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		b, err := t.reader.ReadBytes('\n')
+		t.offset += int64(len(b))
+		if err != nil {
+			if err == io.EOF {
+				t.partial = append(t.partial, b...)
+				return nil
+			}
+			return err
+		}
+		if len(t.partial) > 0 {
+			b = append(t.partial, b...)
+			t.partial = nil
+		}
+		emit(bytes.TrimRight(b, "\r\n"))
+	}
+}
+
+// checkFile reopens the file if it is rotated and reads from the beginning if it is truncated
+func (t *tailer) checkFile(ctx api.StreamContext, emit func([]byte)) error {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		// The file is rotated and the new file is not created yet, continue to read the old one
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if t.file == nil || !os.SameFile(info, t.info) {
+		ctx.GetLogger().Infof("file %s is rotated, reopen it", t.path)
+		// Read the rest of the old file. The trailing line is complete as the old file won't grow anymore.
+		if err := t.readLines(ctx, emit); err != nil {
+			ctx.GetLogger().Warnf("read the rest of the rotated file %s error: %v", t.path, err)
+		}
+		if len(t.partial) > 0 {
+			emit(bytes.TrimRight(t.partial, "\r"))
+			t.partial = nil
+		}
+		t.close()
+		return t.open()
+	}
+	if info.Size() < t.offset {
+		ctx.GetLogger().Infof("file %s is truncated, read from the beginning", t.path)
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		t.reader.Reset(t.file)
+		t.offset = 0
+		t.partial = nil
+	}
+	return nil
+}
+
+var _ api.TupleSource = &TailWrapper{}
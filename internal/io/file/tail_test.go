@@ -0,0 +1,132 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestTailConfig(t *testing.T) {
+	path, err := os.Getwd()
+	require.NoError(t, err)
+	path = filepath.Join(path, "test")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "not lines",
+			props: map[string]any{"path": path, "fileType": "csv", "datasource": "csv", "tail": true},
+			err:   "tail mode only supports lines file type",
+		},
+		{
+			name:  "dir",
+			props: map[string]any{"path": path, "fileType": "lines", "tail": true},
+			err:   "tail mode does not support directory",
+		},
+		{
+			name:  "interval",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "tail": true, "interval": "1s"},
+			err:   "interval is not supported in tail mode",
+		},
+		{
+			name:  "action after read",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "tail": true, "actionAfterRead": 1},
+			err:   "actionAfterRead is not supported in tail mode",
+		},
+		{
+			name:  "ignore lines",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "tail": true, "ignoreStartLines": 1},
+			err:   "ignoreStartLines and ignoreEndLines are not supported in tail mode",
+		},
+		{
+			name:  "follow interval",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "tail": true, "followInterval": "0s"},
+			err:   "followInterval must be greater than 0",
+		},
+	}
+	ctx := mockContext.NewMockContext("testTail", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GetSource().Provision(ctx, tt.props)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+	s := &Source{}
+	require.NoError(t, s.Provision(ctx, map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "tail": true}))
+	assert.Equal(t, &TailWrapper{f: s}, s.TransformType())
+}
+
+func TestTailFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(file, []byte("line1\nline2\n"), 0o644))
+	appendFile := func(content string) {
+		f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		defer f.Close()
+		_, err = f.WriteString(content)
+		require.NoError(t, err)
+	}
+
+	meta := map[string]any{
+		"file": file,
+	}
+	timex.Set(123456)
+	exp := []api.MessageTuple{
+		model.NewDefaultRawTuple([]byte("line1"), meta, timex.GetNow()),
+		model.NewDefaultRawTuple([]byte("line2"), meta, timex.GetNow()),
+		// appended with partial line
+		model.NewDefaultRawTuple([]byte("line3"), meta, timex.GetNow()),
+		model.NewDefaultRawTuple([]byte("line4"), meta, timex.GetNow()),
+		// truncated
+		model.NewDefaultRawTuple([]byte("new1"), meta, timex.GetNow()),
+		// rotated, the rest of the old file is read before reopen
+		model.NewDefaultRawTuple([]byte("new2"), meta, timex.GetNow()),
+		model.NewDefaultRawTuple([]byte("rotated1"), meta, timex.GetNow()),
+	}
+	r := &TailWrapper{f: &Source{}}
+	mock.TestSourceConnector(t, r, map[string]any{
+		"path":           dir,
+		"fileType":       "lines",
+		"datasource":     "app.log",
+		"tail":           true,
+		"followInterval": "10ms",
+	}, exp, func() {
+		time.Sleep(100 * time.Millisecond)
+		appendFile("line3\nli")
+		time.Sleep(50 * time.Millisecond)
+		appendFile("ne4\n")
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, os.WriteFile(file, []byte("new1\n"), 0o644))
+		time.Sleep(50 * time.Millisecond)
+		appendFile("new2")
+		require.NoError(t, os.Rename(file, filepath.Join(dir, "app.log.1")))
+		require.NoError(t, os.WriteFile(file, []byte("rotated1\n"), 0o644))
+	})
+}
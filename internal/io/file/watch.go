@@ -15,6 +15,10 @@
 package file
 
 import (
+	"os"
+	"sort"
+	"time"
+
 	"github.com/fsnotify/fsnotify"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 )
@@ -51,24 +55,7 @@ func (f *WatchWrapper) Subscribe(ctx api.StreamContext, ingest api.TupleIngest,
 		if err != nil {
 			return err
 		}
-		go func() {
-			defer watcher.Close()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case event := <-watcher.Events:
-					switch {
-					// case event.Has(fsnotify.Write):
-					case event.Has(fsnotify.Create):
-						ctx.GetLogger().Debugf("file watch receive creat event")
-						f.f.parseFile(ctx, event.Name, ingest, ingestError)
-					}
-				case err = <-watcher.Errors:
-					ctx.GetLogger().Errorf("file watch err:%v", err.Error())
-				}
-			}
-		}()
+		go f.watch(ctx, watcher, ingest, ingestError)
 	} else {
 		ctx.GetLogger().Infof("file watch exit")
 		if f.f != nil && f.f.eof != nil {
@@ -78,6 +65,97 @@ func (f *WatchWrapper) Subscribe(ctx api.StreamContext, ingest api.TupleIngest,
 	return nil
 }
 
+// watch reads the files created in the dir. If the quiet period is set, a new file is read after it has not been
+// written for the period, so that the file which is still being written is not read partially.
+func (f *WatchWrapper) watch(ctx api.StreamContext, watcher *fsnotify.Watcher, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	defer watcher.Close()
+	quiet := time.Duration(f.f.config.QuietPeriod)
+	var (
+		// the files waiting for the quiet period and their last write time
+		pending map[string]time.Time
+		tickC   <-chan time.Time
+	)
+	if quiet > 0 {
+		pending = make(map[string]time.Time)
+		ticker := time.NewTicker(quiet)
+		defer ticker.Stop()
+		tickC = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-watcher.Events:
+			if !f.f.ownFile(event.Name) {
+				continue
+			}
+			switch {
+			case event.Has(fsnotify.Create):
+				ctx.GetLogger().Debugf("file watch receive create event for %s", event.Name)
+				if quiet > 0 {
+					pending[event.Name] = time.Now()
+				} else {
+					f.read(ctx, event.Name, ingest, ingestError)
+				}
+			case event.Has(fsnotify.Write):
+				// Only the writes of the new files are tracked. The changes of the read files are ignored
+				if _, ok := pending[event.Name]; ok {
+					pending[event.Name] = time.Now()
+				}
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				delete(pending, event.Name)
+			}
+		case now := <-tickC:
+			for _, file := range quietFiles(pending, quiet, now) {
+				f.read(ctx, file, ingest, ingestError)
+			}
+		case err := <-watcher.Errors:
+			ctx.GetLogger().Errorf("file watch err:%v", err.Error())
+		}
+	}
+}
+
+// read parses the file and records its modified time, so that it is not read again after the rule restarts
+func (f *WatchWrapper) read(ctx api.StreamContext, file string, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	fi, err := os.Stat(file)
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	if fi.IsDir() {
+		return
+	}
+	f.f.parseFile(ctx, file, ingest, ingestError)
+	f.f.updateRewindMeta(file, fi.ModTime())
+}
+
+// quietFiles removes the files which have not been changed for the quiet period from the pending files and returns
+// them in the order of the modified time. The files which no longer exist are dropped.
+func quietFiles(pending map[string]time.Time, quiet time.Duration, now time.Time) []string {
+	files := make(WithTimeSlice, 0)
+	for file, lastWrite := range pending {
+		fi, err := os.Stat(file)
+		if err != nil || fi.IsDir() {
+			delete(pending, file)
+			continue
+		}
+		if fi.ModTime().After(lastWrite) {
+			lastWrite = fi.ModTime()
+		}
+		if now.Sub(lastWrite) < quiet {
+			continue
+		}
+		delete(pending, file)
+		files = append(files, WithTime{name: file, modifyTime: fi.ModTime()})
+	}
+	sort.Sort(files)
+	result := make([]string, len(files))
+	for i, file := range files {
+		result[i] = file.name
+	}
+	return result
+}
+
 var (
 	_ api.TupleSource = &WatchWrapper{}
 	_ api.Bounded     = &WatchWrapper{}
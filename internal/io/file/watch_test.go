@@ -15,6 +15,7 @@
 package file
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/mock"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
@@ -107,3 +109,104 @@ func TestWatchDir(t *testing.T) {
 		// do nothing
 	})
 }
+
+func TestWatchDirQuietPeriod(t *testing.T) {
+	path, err := os.Getwd()
+	require.NoError(t, err)
+	wpath := filepath.Join(path, "watchQuiet")
+	require.NoError(t, os.MkdirAll(wpath, os.ModePerm))
+	defer os.RemoveAll(wpath)
+	mpath := filepath.Join(path, "watchQuietMoved")
+	defer os.RemoveAll(mpath)
+	meta := map[string]any{
+		"file": filepath.Join(wpath, "slow.lines"),
+	}
+	exp := []api.MessageTuple{
+		model.NewDefaultRawTupleIgnoreTs([]byte("{\"id\": 1}"), meta),
+		model.NewDefaultRawTupleIgnoreTs([]byte("{\"id\": 2}"), meta),
+		model.NewDefaultRawTupleIgnoreTs([]byte("{\"id\": 3}"), meta),
+	}
+	r := &WatchWrapper{f: &Source{}}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		// the file is written slowly, it must be read once as a whole
+		dest, err := os.Create(filepath.Join(wpath, "slow.lines"))
+		assert.NoError(t, err)
+		defer dest.Close()
+		for i := 1; i <= 3; i++ {
+			_, err = dest.WriteString(fmt.Sprintf("{\"id\": %d}\n", i))
+			assert.NoError(t, err)
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+	mock.TestSourceConnector(t, r, map[string]any{
+		"path":            wpath,
+		"fileType":        "lines",
+		"quietPeriod":     "500ms",
+		"actionAfterRead": 2,
+		"moveTo":          mpath,
+		// the test lasts for the quiet period, do not rely on the shared mock clock
+		"ignoreTs": true,
+	}, exp, func() {
+		// do nothing
+	})
+	// the file is moved after all lines are sent
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(mpath, "slow.lines"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestQuietPeriodConfig(t *testing.T) {
+	path, err := os.Getwd()
+	require.NoError(t, err)
+	path = filepath.Join(path, "test")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "negative",
+			props: map[string]any{"path": path, "fileType": "lines", "quietPeriod": "-1s"},
+			err:   "quietPeriod must not be negative",
+		},
+		{
+			name:  "file",
+			props: map[string]any{"path": path, "fileType": "lines", "datasource": "test.lines", "quietPeriod": "1s"},
+			err:   "quietPeriod is only supported when watching a directory",
+		},
+		{
+			name:  "interval",
+			props: map[string]any{"path": path, "fileType": "lines", "interval": "1s", "quietPeriod": "1s"},
+			err:   "quietPeriod is only supported when watching a directory",
+		},
+	}
+	ctx := mockContext.NewMockContext("testQuiet", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GetSource().Provision(ctx, tt.props)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestQuietFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old")
+	recent := filepath.Join(dir, "recent")
+	require.NoError(t, os.WriteFile(old, []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(recent, []byte("b"), 0o644))
+	now := time.Now()
+	require.NoError(t, os.Chtimes(old, now.Add(-time.Minute), now.Add(-time.Minute)))
+	pending := map[string]time.Time{
+		old:                            now.Add(-time.Minute),
+		recent:                         now.Add(-time.Minute),
+		filepath.Join(dir, "notExist"): now.Add(-time.Minute),
+	}
+	// recent is modified after the last write event, so it is not quiet yet
+	assert.Equal(t, []string{old}, quietFiles(pending, time.Second, now))
+	assert.Equal(t, map[string]time.Time{recent: now.Add(-time.Minute)}, pending)
+	assert.Equal(t, []string{recent}, quietFiles(pending, time.Second, now.Add(2*time.Second)))
+	assert.Empty(t, pending)
+}
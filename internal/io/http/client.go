@@ -197,6 +197,9 @@ func (cc *ClientConf) InitConf(device string, props map[string]interface{}) erro
 		Timeout:   time.Duration(c.Timeout),
 	}
 	cc.config = c
+	if c.Compression == compressor.NONE {
+		c.Compression = ""
+	}
 	// that means payload need compression and decompression, so we need initialize compressor and decompressor
 	if c.Compression != "" {
 		cc.decompressor, err = compressor.GetDecompressor(c.Compression)
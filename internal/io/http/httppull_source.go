@@ -15,28 +15,68 @@
 package http
 
 import (
+	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 )
 
 type HttpPullSource struct {
 	*ClientConf
 	lastMD5 string
+	pc      *pullSourceConfig
+	// the count of the continuous retriable failures and the time to pull again
+	failures  int
+	nextRetry time.Time
 }
 
 func (hps *HttpPullSource) Pull(ctx api.StreamContext, trigger time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	if !hps.nextRetry.IsZero() && trigger.Before(hps.nextRetry) {
+		ctx.GetLogger().Debugf("http pull source is backing off after %d failures, skip pulling until %v", hps.failures, hps.nextRetry)
+		return
+	}
 	results, err := hps.doPull(ctx)
 	if err != nil {
+		if errorx.IsIOError(err) {
+			hps.backoff(ctx, trigger)
+		}
 		ingestError(ctx, err)
 		return
 	}
+	if hps.failures > 0 {
+		ctx.GetLogger().Debugf("http pull source recovered after %d failures, reset backoff", hps.failures)
+		hps.failures = 0
+		hps.nextRetry = time.Time{}
+	}
 	ingest(ctx, results, nil, trigger)
 }
 
+// backoff calculates the next retry time exponentially with jitter
+func (hps *HttpPullSource) backoff(ctx api.StreamContext, trigger time.Time) {
+	if hps.pc.Backoff != "exponential" {
+		return
+	}
+	hps.failures++
+	delay := time.Duration(hps.pc.BackoffInitial)
+	for i := 1; i < hps.failures && delay < time.Duration(hps.pc.BackoffMax); i++ {
+		delay *= 2
+	}
+	if delay > time.Duration(hps.pc.BackoffMax) {
+		delay = time.Duration(hps.pc.BackoffMax)
+	}
+	if hps.pc.BackoffJitter > 0 {
+		// random in [1-jitter, 1+jitter) of the delay
+		delay = time.Duration(float64(delay) * (1 + hps.pc.BackoffJitter*(2*rand.Float64()-1)))
+	}
+	hps.nextRetry = trigger.Add(delay)
+	ctx.GetLogger().Debugf("http pull source failed %d times, retry after %v", hps.failures, delay)
+}
+
 func (hps *HttpPullSource) Close(ctx api.StreamContext) error {
 	return nil
 }
@@ -48,13 +88,37 @@ func (hps *HttpPullSource) Connect(ctx api.StreamContext, sch api.StatusChangeHa
 
 type pullSourceConfig struct {
 	Path string `json:"datasource"`
+	// Backoff is the strategy when the request fails or returns 5xx, could be none or exponential
+	Backoff        string            `json:"backoff"`
+	BackoffInitial cast.DurationConf `json:"backoffInitial"`
+	BackoffMax     cast.DurationConf `json:"backoffMax"`
+	// BackoffJitter is the factor to randomize the backoff delay, in range [0, 1]
+	BackoffJitter float64 `json:"backoffJitter"`
 }
 
 func (hps *HttpPullSource) Provision(ctx api.StreamContext, configs map[string]any) error {
-	pc := &pullSourceConfig{}
+	pc := &pullSourceConfig{
+		Backoff:        "none",
+		BackoffInitial: cast.DurationConf(time.Second),
+		BackoffMax:     cast.DurationConf(time.Minute),
+		BackoffJitter:  0.2,
+	}
 	if err := cast.MapToStruct(configs, pc); err != nil {
 		return err
 	}
+	if pc.Backoff != "none" && pc.Backoff != "exponential" {
+		return fmt.Errorf("backoff must be none or exponential, but got %s", pc.Backoff)
+	}
+	if pc.BackoffInitial <= 0 {
+		return fmt.Errorf("backoffInitial must be positive")
+	}
+	if pc.BackoffMax < pc.BackoffInitial {
+		return fmt.Errorf("backoffMax must not be less than backoffInitial")
+	}
+	if pc.BackoffJitter < 0 || pc.BackoffJitter > 1 {
+		return fmt.Errorf("backoffJitter must be in range [0, 1]")
+	}
+	hps.pc = pc
 	if hps.ClientConf == nil {
 		hps.ClientConf = &ClientConf{}
 	}
@@ -81,10 +145,14 @@ func doPull(ctx api.StreamContext, c *ClientConf, lastMD5 string) ([]map[string]
 	}
 	resp, err := httpx.Send(ctx.GetLogger(), c.client, c.config.BodyType, c.config.Method, c.config.Url, headers, []byte(newBody))
 	if err != nil {
-		return nil, "", err
+		// The request failure and server error are retriable
+		return nil, "", errorx.NewIOErr(err.Error())
 	}
 	results, newMD5, err := c.parseResponse(ctx, resp, lastMD5, true, false)
 	if err != nil {
+		if resp.StatusCode >= 500 {
+			return nil, "", errorx.NewIOErr(err.Error())
+		}
 		return nil, "", err
 	}
 	return results, newMD5, nil
@@ -18,10 +18,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
@@ -218,3 +220,96 @@ func TestSourceIncremental(t *testing.T) {
 	}, func(ctx api.StreamContext, err error) {})
 	require.Nil(t, <-dataCh)
 }
+
+func TestSourceBackoff(t *testing.T) {
+	var (
+		failing  atomic.Bool
+		requests atomic.Int32
+	)
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		handleGet(w, r)
+	}))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("1", "2")
+	source := &HttpPullSource{}
+	require.NoError(t, source.Provision(ctx, map[string]any{
+		"url":            server.URL,
+		"method":         "get",
+		"backoff":        "exponential",
+		"backoffInitial": "1s",
+		"backoffMax":     "3s",
+		"backoffJitter":  0,
+	}))
+	require.NoError(t, source.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer source.Close(ctx)
+	var (
+		data []any
+		errs []error
+	)
+	pull := func(trigger time.Time) {
+		source.Pull(ctx, trigger, func(ctx api.StreamContext, d any, meta map[string]any, ts time.Time) {
+			data = append(data, d)
+		}, func(ctx api.StreamContext, err error) {
+			errs = append(errs, err)
+		})
+	}
+	now := time.Now()
+	pull(now)
+	assert.Equal(t, now.Add(time.Second), source.nextRetry)
+	// skipped in backoff
+	pull(now.Add(500 * time.Millisecond))
+	assert.Equal(t, int32(1), requests.Load())
+	pull(now.Add(time.Second))
+	assert.Equal(t, now.Add(3*time.Second), source.nextRetry)
+	pull(now.Add(3 * time.Second))
+	// capped by the max
+	assert.Equal(t, now.Add(6*time.Second), source.nextRetry)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Len(t, errs, 3)
+	// reset after success
+	failing.Store(false)
+	pull(now.Add(6 * time.Second))
+	assert.Len(t, data, 1)
+	assert.Equal(t, 0, source.failures)
+	assert.True(t, source.nextRetry.IsZero())
+}
+
+func TestSourceBackoffConf(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid backoff",
+			props: map[string]any{"backoff": "linear"},
+			err:   "backoff must be none or exponential, but got linear",
+		},
+		{
+			name:  "invalid max",
+			props: map[string]any{"backoff": "exponential", "backoffInitial": "10s", "backoffMax": "1s"},
+			err:   "backoffMax must not be less than backoffInitial",
+		},
+		{
+			name:  "invalid jitter",
+			props: map[string]any{"backoff": "exponential", "backoffJitter": 2},
+			err:   "backoffJitter must be in range [0, 1]",
+		},
+	}
+	ctx := mockContext.NewMockContext("1", "2")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.props["url"] = "http://localhost:9090"
+			source := &HttpPullSource{}
+			assert.EqualError(t, source.Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
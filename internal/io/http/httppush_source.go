@@ -92,7 +92,7 @@ func (h *HttpPushSource) Connect(ctx api.StreamContext, sch api.StatusChangeHand
 }
 
 func (h *HttpPushSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
-	ch := pubsub.CreateSub(h.topic, nil, h.sourceID, 1024)
+	ch := pubsub.CreateSub(h.topic, h.sourceID, 1024)
 	h.ch = ch
 	go func(ctx api.StreamContext) {
 		for {
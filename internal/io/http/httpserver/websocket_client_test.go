@@ -43,7 +43,7 @@ func TestWebsocketClient(t *testing.T) {
 	data := []byte("123")
 	pubsub.ProduceAny(ctx, st, data)
 	require.Equal(t, data, <-tc.recvCh)
-	ch := pubsub.CreateSub(rt, nil, "", 1024)
+	ch := pubsub.CreateSub(rt, "", 1024)
 	defer func() {
 		pubsub.CloseSourceConsumerChannel(rt, "")
 	}()
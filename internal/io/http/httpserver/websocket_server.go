@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -30,8 +31,13 @@ import (
 
 const (
 	WebsocketTopicPrefix = "$$websocket/"
+	// writeTimeout is the deadline to write a frame. The connection is regarded as dead if exceeded.
+	writeTimeout = 5 * time.Second
 )
 
+// BinaryMessage is the data to be sent as a binary frame. Plain []byte is sent as a text frame.
+type BinaryMessage []byte
+
 func recvTopic(endpoint string, isServer bool) string {
 	if isServer {
 		return fmt.Sprintf("%s/server/recv/%s", WebsocketTopicPrefix, endpoint)
@@ -84,17 +90,32 @@ func sendProcess(ctx api.StreamContext, topic, sourceID string, c *websocket.Con
 		c.Close()
 		wg.Done()
 	}()
-	ch := pubsub.CreateSub(topic, nil, sourceID, 1024)
+	ch := pubsub.CreateSub(topic, sourceID, 1024)
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case d := <-ch:
-			data := d.([]byte)
-			if err := c.WriteMessage(websocket.TextMessage, data); err != nil {
-				if websocket.IsCloseError(err) || strings.Contains(err.Error(), "close") {
-					return
-				}
+		case d, ok := <-ch:
+			if !ok {
+				return
+			}
+			var (
+				msgType int
+				data    []byte
+			)
+			switch dt := d.(type) {
+			case []byte:
+				msgType, data = websocket.TextMessage, dt
+			case BinaryMessage:
+				msgType, data = websocket.BinaryMessage, dt
+			default:
+				continue
+			}
+			_ = c.SetWriteDeadline(time.Now().Add(writeTimeout))
+			// The connection is broken after any write error, close it to release the resources
+			if err := c.WriteMessage(msgType, data); err != nil {
+				ctx.GetLogger().Infof("websocket connection closed by write error: %v", err)
+				return
 			}
 		}
 	}
@@ -113,11 +134,12 @@ func recvProcess(ctx api.StreamContext, topic string, c *websocket.Conn, cancel
 		default:
 		}
 		msgType, data, err := c.ReadMessage()
+		// The read error is permanent, subsequent reads return the same error
 		if err != nil {
-			if websocket.IsCloseError(err) || strings.Contains(err.Error(), "close") {
-				return
+			if !websocket.IsCloseError(err) && !strings.Contains(err.Error(), "close") {
+				ctx.GetLogger().Infof("websocket connection closed by read error: %v", err)
 			}
-			continue
+			return
 		}
 		switch msgType {
 		case websocket.TextMessage:
@@ -35,7 +35,7 @@ func TestWebsocketServerRecvData(t *testing.T) {
 	endpint := "/e1"
 	rTopic, _, err := RegisterWebSocketEndpoint(ctx, endpint)
 	require.NoError(t, err)
-	subCh := pubsub.CreateSub(rTopic, nil, "test", 1024)
+	subCh := pubsub.CreateSub(rTopic, "test", 1024)
 	defer pubsub.CloseSourceConsumerChannel(rTopic, "test")
 	conn, err := testx.CreateWebsocketClient(ip, port, endpint)
 	require.NoError(t, err)
@@ -0,0 +1,173 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+const (
+	defaultExpiryDelta = 10 * time.Second
+	authStyleBasic     = "basic"
+	authStyleBody      = "body"
+)
+
+// OAuth2Conf is the configuration of the OAuth2 client credentials grant
+type OAuth2Conf struct {
+	TokenUrl     string   `json:"tokenUrl"`
+	ClientId     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes"`
+	// How to send the client credentials, basic for the Authorization header and body for the form parameters
+	AuthStyle string `json:"authStyle"`
+	// Refresh the token before it expires in the specified duration
+	ExpiryDelta cast.DurationConf `json:"expiryDelta"`
+}
+
+func (c *OAuth2Conf) validate() error {
+	if c.TokenUrl == "" {
+		return errors.New("oauth2 tokenUrl is required")
+	}
+	if err := httpx.IsHttpUrl(c.TokenUrl); err != nil {
+		return fmt.Errorf("invalid oauth2 tokenUrl: %v", err)
+	}
+	if c.ClientId == "" {
+		return errors.New("oauth2 clientId is required")
+	}
+	switch c.AuthStyle {
+	case "":
+		c.AuthStyle = authStyleBasic
+	case authStyleBasic, authStyleBody:
+	default:
+		return fmt.Errorf("invalid oauth2 authStyle %s, must be basic or body", c.AuthStyle)
+	}
+	if c.ExpiryDelta < 0 {
+		return errors.New("oauth2 expiryDelta must be greater than or equal to 0")
+	}
+	return nil
+}
+
+type tokenResp struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   any    `json:"expires_in"`
+}
+
+// tokenSource fetches the access token by client credentials and caches it until it is about to expire.
+// It is shared by all the requests of the sink, so it is protected by the lock.
+type tokenSource struct {
+	conf   *OAuth2Conf
+	client *http.Client
+
+	sync.Mutex
+	token    string
+	expireAt time.Time
+}
+
+func newTokenSource(conf *OAuth2Conf, client *http.Client) *tokenSource {
+	return &tokenSource{conf: conf, client: client}
+}
+
+// authHeader returns the value of the Authorization header. If force is true, a new token is fetched even if the cached
+// one is not expired, which is used when the server rejects the token.
+func (ts *tokenSource) authHeader(ctx api.StreamContext, force bool) (string, error) {
+	ts.Lock()
+	defer ts.Unlock()
+	if !force && ts.token != "" && (ts.expireAt.IsZero() || timex.GetNow().Before(ts.expireAt)) {
+		return ts.token, nil
+	}
+	tk, err := ts.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	ts.token = tk
+	return ts.token, nil
+}
+
+func (ts *tokenSource) fetch(ctx context.Context) (string, error) {
+	params := url.Values{}
+	params.Set("grant_type", "client_credentials")
+	if len(ts.conf.Scopes) > 0 {
+		params.Set("scope", strings.Join(ts.conf.Scopes, " "))
+	}
+	if ts.conf.AuthStyle == authStyleBody {
+		params.Set("client_id", ts.conf.ClientId)
+		params.Set("client_secret", ts.conf.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.conf.TokenUrl, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("fail to create oauth2 token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if ts.conf.AuthStyle == authStyleBasic {
+		req.SetBasicAuth(url.QueryEscape(ts.conf.ClientId), url.QueryEscape(ts.conf.ClientSecret))
+	}
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		// The token server is unreachable, retry later
+		return "", errorx.NewIOErr(fmt.Sprintf("fail to get oauth2 token from %s: %v", ts.conf.TokenUrl, err))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errorx.NewIOErr(fmt.Sprintf("fail to read oauth2 token response: %v", err))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := fmt.Sprintf("fail to get oauth2 token from %s: status %d, body %s", ts.conf.TokenUrl, resp.StatusCode, body)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return "", errorx.NewIOErr(msg)
+		}
+		return "", errors.New(msg)
+	}
+	tr := &tokenResp{}
+	if err := json.Unmarshal(body, tr); err != nil {
+		return "", fmt.Errorf("fail to parse oauth2 token response: %v", err)
+	}
+	if tr.AccessToken == "" {
+		return "", errors.New("oauth2 token response does not have access_token")
+	}
+	ts.expireAt = time.Time{}
+	if tr.ExpiresIn != nil {
+		expiresIn, err := cast.ToInt(tr.ExpiresIn, cast.CONVERT_ALL)
+		if err != nil {
+			return "", fmt.Errorf("fail to parse oauth2 token expires_in %v: %v", tr.ExpiresIn, err)
+		}
+		if expiresIn > 0 {
+			ts.expireAt = timex.GetNow().Add(time.Duration(expiresIn)*time.Second - time.Duration(ts.conf.ExpiryDelta))
+		}
+	}
+	tokenType := tr.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + tr.AccessToken, nil
+}
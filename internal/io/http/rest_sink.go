@@ -17,18 +17,48 @@ package http
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/pingcap/failpoint"
 
+	"github.com/lf-edge/ekuiper/v2/internal/converter"
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/httpx"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/tracenode"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 type RestSink struct {
 	*ClientConf
 	noHeaderTemplate bool
+	tokenSource      *tokenSource
+	sc               *restSinkConf
+	// respDecoder decodes the response body published to the response topic
+	respDecoder message.Converter
+}
+
+type restSinkConf struct {
+	OAuth2 *OAuth2Conf `json:"oauth2"`
+	// ResponseTopic is the memory topic to publish the status code and the body of the responses to
+	ResponseTopic string `json:"responseTopic"`
+	// ResponseFormat is the format to decode the response body, the body is published as a string if it fails to decode
+	ResponseFormat string `json:"responseFormat"`
+	// SuccessCodes are the status codes besides 2xx which mean the data is sent successfully
+	SuccessCodes []int `json:"successCodes"`
+	// RetryCodes are the status codes besides 5xx, 408 and 429 which mean the data could be resent
+	RetryCodes []int `json:"retryCodes"`
+}
+
+// handleResp reports whether the response is handled by the response topic or the customized status codes
+func (sc *restSinkConf) handleResp() bool {
+	return sc.ResponseTopic != "" || len(sc.SuccessCodes) > 0 || len(sc.RetryCodes) > 0
 }
 
 var bodyTypeFormat = map[string]string{
@@ -36,6 +66,14 @@ var bodyTypeFormat = map[string]string{
 	"form": "urlencoded",
 }
 
+// contentEncodings are the Content-Encoding header values of the compressions. The header is not set for the
+// compressions without a registered content coding
+var contentEncodings = map[string]string{
+	"gzip": "gzip",
+	"zstd": "zstd",
+	"zlib": "deflate",
+}
+
 func (r *RestSink) Provision(ctx api.StreamContext, configs map[string]any) error {
 	r.ClientConf = &ClientConf{}
 	err := r.InitConf("", configs)
@@ -48,19 +86,60 @@ func (r *RestSink) Provision(ctx api.StreamContext, configs map[string]any) erro
 	if rf, ok := bodyTypeFormat[r.ClientConf.config.BodyType]; ok && r.ClientConf.config.Format != rf {
 		return fmt.Errorf("format must be %s if bodyType is %s", rf, r.ClientConf.config.BodyType)
 	}
+	sc := &restSinkConf{ResponseFormat: "json"}
+	if err := cast.MapToStruct(configs, sc); err != nil {
+		return fmt.Errorf("fail to parse the properties: %v", err)
+	}
+	if sc.handleResp() && r.ClientConf.config.ResponseType != "code" {
+		return errors.New("responseTopic, successCodes and retryCodes are only supported when responseType is code")
+	}
+	if sc.ResponseTopic != "" {
+		if err := pubsub.ValidateTopic(sc.ResponseTopic); err != nil {
+			return fmt.Errorf("invalid responseTopic: %v", err)
+		}
+		r.respDecoder, err = converter.GetOrCreateConverter(ctx, sc.ResponseFormat, "", nil, nil)
+		if err != nil {
+			return fmt.Errorf("invalid responseFormat: %v", err)
+		}
+	}
+	r.sc = sc
+	if sc.OAuth2 != nil {
+		if sc.OAuth2.ExpiryDelta == 0 {
+			sc.OAuth2.ExpiryDelta = cast.DurationConf(defaultExpiryDelta)
+		}
+		if err := sc.OAuth2.validate(); err != nil {
+			return err
+		}
+		r.tokenSource = newTokenSource(sc.OAuth2, r.client)
+	}
 	return nil
 }
 
 func (r *RestSink) Close(ctx api.StreamContext) error {
+	if r.sc != nil && r.sc.ResponseTopic != "" {
+		pubsub.RemovePub(r.sc.ResponseTopic)
+	}
 	return nil
 }
 
 func (r *RestSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	if r.sc != nil && r.sc.ResponseTopic != "" {
+		pubsub.CreatePub(r.sc.ResponseTopic)
+	}
 	sch(api.ConnectionConnected, "")
 	return nil
 }
 
 func (r *RestSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	traced, span := tracenode.TraceExternalCall(ctx, item, "http")
+	err := r.collect(ctx, item)
+	if traced {
+		tracenode.EndExternalCall(span, err)
+	}
+	return err
+}
+
+func (r *RestSink) collect(ctx api.StreamContext, item api.RawTuple) error {
 	logger := ctx.GetLogger()
 	headers := r.config.Headers
 	bodyType := r.config.BodyType
@@ -95,38 +174,41 @@ func (r *RestSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 		}
 	}
 
-	switch r.config.Compression {
-	case "zstd":
+	if ce, ok := contentEncodings[r.config.Compression]; ok {
 		if headers == nil {
 			headers = make(map[string]string)
 		}
-		headers["Content-Encoding"] = "zstd"
-	case "gzip":
-		if headers == nil {
-			headers = make(map[string]string)
-		}
-		headers["Content-Encoding"] = "gzip"
+		headers["Content-Encoding"] = ce
 	}
 
-	resp, err := httpx.Send(ctx.GetLogger(), r.client, bodyType, method, u, headers, item.Raw())
+	var resp *http.Response
+	var err error
+	if r.tokenSource != nil {
+		resp, err = r.sendWithToken(ctx, bodyType, method, u, headers, item.Raw())
+	} else {
+		resp, err = httpx.Send(ctx.GetLogger(), r.client, bodyType, method, u, headers, item.Raw())
+	}
 	failpoint.Inject("recoverAbleErr", func() {
 		err = errors.New("connection reset by peer")
 	})
 	if err != nil {
 		originErr := err
-		recoverAble := errorx.IsRecoverAbleError(originErr)
+		// the token fetching error may be retryable
+		recoverAble := errorx.IsRecoverAbleError(originErr) || errorx.IsIOError(originErr)
 		if recoverAble {
 			logger.Errorf("rest sink meet error:%v, recoverAble:%v, ruleID:%v", originErr.Error(), recoverAble, ctx.GetRuleId())
-			return errorx.NewIOErr(fmt.Sprintf(`rest sink fails to send out the data:err=%s recoverAble=%v method=%s path="%s" request_body="%s"`,
+			return errorx.NewConnectionError(fmt.Errorf(`rest sink fails to send out the data:err=%s recoverAble=%v method=%s path="%s" request_body="%s"`,
 				originErr.Error(),
 				recoverAble,
 				method,
 				u, string(item.Raw())))
 		}
-		return fmt.Errorf(`rest sink fails to send out the data:err=%s recoverAble=%v method=%s path="%s" request_body="%s"`,
+		return errorx.NewValidationError(fmt.Errorf(`rest sink fails to send out the data:err=%s recoverAble=%v method=%s path="%s" request_body="%s"`,
 			originErr.Error(),
 			recoverAble,
-			method, u, string(item.Raw()))
+			method, u, string(item.Raw())))
+	} else if r.sc != nil && r.sc.handleResp() {
+		return r.handleResponse(ctx, resp, method, u)
 	} else {
 		logger.Debugf("rest sink got response %v", resp)
 		_, b, err := r.parseResponse(ctx, resp, "", r.config.DebugResp, false)
@@ -135,13 +217,13 @@ func (r *RestSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 			if strings.HasPrefix(err.Error(), BODY_ERR) {
 				logger.Warnf("rest sink response body error: %v", err)
 			} else {
-				return fmt.Errorf(`parse response error: %s. | method=%s path="%s" status=%d response_body="%s"`,
+				return statusErr(resp.StatusCode, fmt.Errorf(`parse response error: %s. | method=%s path="%s" status=%d response_body="%s"`,
 					err,
 					method,
 					u,
 					resp.StatusCode,
 					b,
-				)
+				))
 			}
 		}
 		if r.config.DebugResp {
@@ -151,6 +233,99 @@ func (r *RestSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 	return nil
 }
 
+// handleResponse publishes the response to the response topic and categorizes the status code by the customized codes
+func (r *RestSink) handleResponse(ctx api.StreamContext, resp *http.Response, method string, u string) error {
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ctx.GetLogger().Warnf("rest sink response body error: %v", err)
+	}
+	if r.config.DebugResp {
+		ctx.GetLogger().Infof("Response raw content: %s\n", b)
+	}
+	code := resp.StatusCode
+	if r.sc.ResponseTopic != "" {
+		r.publishResponse(ctx, code, b, method, u)
+	}
+	if (code >= 200 && code <= 299) || containsCode(r.sc.SuccessCodes, code) {
+		return nil
+	}
+	e := fmt.Errorf(`parse response error: %s: %d. | method=%s path="%s" status=%d response_body="%s"`, CODE_ERR, code, method, u, code, b)
+	if containsCode(r.sc.RetryCodes, code) {
+		return errorx.NewTransientError(e)
+	}
+	return statusErr(code, e)
+}
+
+// publishResponse sends the response to the memory topic so that the other rules can consume it by a memory source
+func (r *RestSink) publishResponse(ctx api.StreamContext, code int, b []byte, method string, u string) {
+	var body any
+	if len(b) > 0 {
+		v, err := r.respDecoder.Decode(ctx, b)
+		if err != nil {
+			ctx.GetLogger().Warnf("rest sink fails to decode the response body, publish it as a string: %v", err)
+			body = string(b)
+		} else {
+			body = v
+		}
+	}
+	pubsub.Produce(ctx, r.sc.ResponseTopic, &xsql.Tuple{
+		Message: map[string]any{
+			"statusCode": code,
+			"body":       body,
+		},
+		Metadata: map[string]any{
+			"topic":  r.sc.ResponseTopic,
+			"method": method,
+			"url":    u,
+		},
+		Timestamp: timex.GetNow(),
+	})
+}
+
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// statusErr categorizes the error of the response by the status code. The server errors, the request timeout and
+// the rate limit are transient so that the data could be resent, while the other rejections are validation errors.
+func statusErr(code int, err error) error {
+	if code >= http.StatusInternalServerError || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests {
+		return errorx.NewTransientError(err)
+	}
+	return errorx.NewValidationError(err)
+}
+
+// sendWithToken sends the request with the OAuth2 access token. If the server responds 401, the token may be revoked
+// before it expires, so force to refresh the token and retry once.
+func (r *RestSink) sendWithToken(ctx api.StreamContext, bodyType string, method string, u string, headers map[string]string, data []byte) (*http.Response, error) {
+	send := func(force bool) (*http.Response, error) {
+		auth, err := r.tokenSource.authHeader(ctx, force)
+		if err != nil {
+			return nil, err
+		}
+		h := make(map[string]string, len(headers)+1)
+		for k, v := range headers {
+			h[k] = v
+		}
+		h["Authorization"] = auth
+		return httpx.Send(ctx.GetLogger(), r.client, bodyType, method, u, h, data)
+	}
+	resp, err := send(false)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	ctx.GetLogger().Infof("rest sink got 401 response, refresh the oauth2 token and retry")
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return send(true)
+}
+
 func GetSink() api.Sink {
 	return &RestSink{}
 }
@@ -20,16 +20,21 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pingcap/failpoint"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 type request struct {
@@ -94,6 +99,37 @@ func TestRestSink_Apply(t *testing.T) {
 				ContentType:     "application/json",
 				ContentEncoding: "zstd",
 			}},
+		}, {
+			name: "3",
+			config: map[string]interface{}{
+				"method":      "post",
+				"sendSingle":  true,
+				"compression": "zlib",
+			},
+			data: []map[string]interface{}{{
+				"ab": "hello1",
+			}},
+			result: []request{{
+				Method:          "POST",
+				Body:            []byte(`{"ab":"hello1"}`),
+				ContentType:     "application/json",
+				ContentEncoding: "deflate",
+			}},
+		}, {
+			name: "4",
+			config: map[string]interface{}{
+				"method":      "post",
+				"sendSingle":  true,
+				"compression": "none",
+			},
+			data: []map[string]interface{}{{
+				"ab": "hello1",
+			}},
+			result: []request{{
+				Method:      "POST",
+				Body:        []byte(`{"ab":"hello1"}`),
+				ContentType: "application/json",
+			}},
 		}, {
 			name: "6",
 			config: map[string]interface{}{
@@ -277,6 +313,8 @@ func TestRestSinkRecoverErr(t *testing.T) {
 	err := sErr.Collect(ctx, data)
 	require.Error(t, err)
 	require.False(t, errorx.IsIOError(err))
+	c, _ := errorx.GetCategory(err)
+	require.Equal(t, errorx.CategoryValidation, c)
 	s := &RestSink{}
 	require.NoError(t, s.Provision(ctx, map[string]any{
 		"url":    fmt.Sprintf("%s/get", server.URL),
@@ -290,4 +328,273 @@ func TestRestSinkRecoverErr(t *testing.T) {
 	err = s.Collect(ctx, data)
 	require.Error(t, err)
 	require.True(t, errorx.IsIOError(err))
+	c, _ = errorx.GetCategory(err)
+	require.Equal(t, errorx.CategoryConnection, c)
+	require.True(t, errorx.IsRetryable(err))
+}
+
+func TestRestSinkStatusErr(t *testing.T) {
+	var code int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("1", "2")
+	s := &RestSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":    server.URL,
+		"method": "post",
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	tests := []struct {
+		code      int
+		category  errorx.Category
+		retryable bool
+	}{
+		{code: http.StatusServiceUnavailable, category: errorx.CategoryTransient, retryable: true},
+		{code: http.StatusTooManyRequests, category: errorx.CategoryTransient, retryable: true},
+		{code: http.StatusRequestTimeout, category: errorx.CategoryTransient, retryable: true},
+		{code: http.StatusBadRequest, category: errorx.CategoryValidation},
+		{code: http.StatusNotFound, category: errorx.CategoryValidation},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d", tt.code), func(t *testing.T) {
+			code = tt.code
+			err := s.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(`{"a":1}`)})
+			require.Error(t, err)
+			c, ok := errorx.GetCategory(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.category, c)
+			assert.Equal(t, tt.retryable, errorx.IsRetryable(err))
+			assert.False(t, errorx.IsIOError(err))
+		})
+	}
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestRestSinkResponse(t *testing.T) {
+	var (
+		code int
+		body string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	ctx := mockContext.NewMockContext("1", "2")
+	s := &RestSink{}
+	require.EqualError(t, s.Provision(ctx, map[string]any{
+		"url":           server.URL,
+		"responseType":  "body",
+		"responseTopic": "resp",
+	}), "responseTopic, successCodes and retryCodes are only supported when responseType is code")
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":           server.URL,
+		"method":        "post",
+		"responseTopic": "resp",
+		"successCodes":  []int{http.StatusConflict},
+		"retryCodes":    []int{http.StatusLocked},
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	ch := pubsub.CreateSub("resp", "testResp", 10)
+	defer pubsub.CloseSourceConsumerChannel("resp", "testResp")
+	tests := []struct {
+		name      string
+		code      int
+		body      string
+		err       bool
+		retryable bool
+		result    map[string]any
+	}{
+		{
+			name:   "success",
+			code:   http.StatusOK,
+			body:   `{"ackId":"a1"}`,
+			result: map[string]any{"statusCode": http.StatusOK, "body": map[string]any{"ackId": "a1"}},
+		},
+		{
+			name:   "success code",
+			code:   http.StatusConflict,
+			body:   `{"ackId":"a2"}`,
+			result: map[string]any{"statusCode": http.StatusConflict, "body": map[string]any{"ackId": "a2"}},
+		},
+		{
+			name:      "retry code",
+			code:      http.StatusLocked,
+			body:      "locked",
+			err:       true,
+			retryable: true,
+			result:    map[string]any{"statusCode": http.StatusLocked, "body": "locked"},
+		},
+		{
+			name:   "fail",
+			code:   http.StatusBadRequest,
+			err:    true,
+			result: map[string]any{"statusCode": http.StatusBadRequest, "body": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body = tt.code, tt.body
+			err := s.Collect(ctx, &xsql.RawTuple{Rawdata: []byte(`{"a":1}`)})
+			if tt.err {
+				require.Error(t, err)
+				assert.Equal(t, tt.retryable, errorx.IsRetryable(err))
+			} else {
+				require.NoError(t, err)
+			}
+			select {
+			case d := <-ch:
+				tuple, ok := d.(*xsql.Tuple)
+				require.True(t, ok)
+				assert.Equal(t, tt.result, map[string]any(tuple.Message))
+				assert.Equal(t, server.URL, tuple.Metadata["url"])
+			case <-time.After(time.Second):
+				t.Fatal("response is not published")
+			}
+		})
+	}
+	require.NoError(t, s.Close(ctx))
+}
+
+func TestRestSinkOAuth2Provision(t *testing.T) {
+	tests := []struct {
+		name  string
+		oauth map[string]any
+		err   string
+	}{
+		{
+			name:  "missing token url",
+			oauth: map[string]any{"clientId": "id"},
+			err:   "oauth2 tokenUrl is required",
+		},
+		{
+			name:  "invalid token url",
+			oauth: map[string]any{"tokenUrl": "ftp://localhost/token", "clientId": "id"},
+			err:   "invalid oauth2 tokenUrl: Invalid scheme ftp",
+		},
+		{
+			name:  "missing client id",
+			oauth: map[string]any{"tokenUrl": "http://localhost/token"},
+			err:   "oauth2 clientId is required",
+		},
+		{
+			name:  "invalid auth style",
+			oauth: map[string]any{"tokenUrl": "http://localhost/token", "clientId": "id", "authStyle": "header"},
+			err:   "invalid oauth2 authStyle header, must be basic or body",
+		},
+		{
+			name:  "invalid expiry delta",
+			oauth: map[string]any{"tokenUrl": "http://localhost/token", "clientId": "id", "expiryDelta": "-1s"},
+			err:   "oauth2 expiryDelta must be greater than or equal to 0",
+		},
+	}
+	ctx := mockContext.NewMockContext("testOAuth2", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RestSink{}
+			err := s.Provision(ctx, map[string]any{
+				"url":    "http://localhost/test",
+				"oauth2": tt.oauth,
+			})
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+	s := &RestSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":    "http://localhost/test",
+		"oauth2": map[string]any{"tokenUrl": "http://localhost/token", "clientId": "id", "clientSecret": "secret"},
+	}))
+	assert.Equal(t, &OAuth2Conf{
+		TokenUrl:     "http://localhost/token",
+		ClientId:     "id",
+		ClientSecret: "secret",
+		AuthStyle:    "basic",
+		ExpiryDelta:  cast.DurationConf(10 * time.Second),
+	}, s.tokenSource.conf)
+}
+
+func TestRestSinkOAuth2(t *testing.T) {
+	var (
+		lock       sync.Mutex
+		tokenCount int
+		tokenCode  = http.StatusOK
+		revoked    string
+		auths      []string
+	)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		id, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "id", id)
+		assert.Equal(t, "secret", secret)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+		if tokenCode != http.StatusOK {
+			w.WriteHeader(tokenCode)
+			return
+		}
+		tokenCount++
+		_, _ = fmt.Fprintf(w, `{"access_token":"token%d","token_type":"bearer","expires_in":60}`, tokenCount)
+	}))
+	defer tokenServer.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		auth := r.Header.Get("Authorization")
+		auths = append(auths, auth)
+		if auth == revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := mockContext.NewMockContext("testOAuth2", "op")
+	s := &RestSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":    server.URL,
+		"method": "post",
+		"oauth2": map[string]any{
+			"tokenUrl":     tokenServer.URL,
+			"clientId":     "id",
+			"clientSecret": "secret",
+			"scopes":       []any{"read", "write"},
+		},
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	data := &xsql.RawTuple{Rawdata: []byte(`{"a":1}`)}
+	// fetch the token at the first request and cache it
+	require.NoError(t, s.Collect(ctx, data))
+	require.NoError(t, s.Collect(ctx, data))
+	// refresh the token before it expires
+	timex.Add(55 * time.Second)
+	require.NoError(t, s.Collect(ctx, data))
+	// the token is revoked, refresh and retry once
+	revoked = "Bearer token2"
+	require.NoError(t, s.Collect(ctx, data))
+	assert.Equal(t, 3, tokenCount)
+	assert.Equal(t, []string{"Bearer token1", "Bearer token1", "Bearer token2", "Bearer token2", "Bearer token3"}, auths)
+	// the token server is unavailable
+	tokenCode = http.StatusServiceUnavailable
+	timex.Add(time.Minute)
+	err := s.Collect(ctx, data)
+	require.Error(t, err)
+	assert.True(t, errorx.IsIOError(err))
+	// the credentials are rejected
+	tokenCode = http.StatusBadRequest
+	err = s.Collect(ctx, data)
+	require.Error(t, err)
+	assert.False(t, errorx.IsIOError(err))
+	require.NoError(t, s.Close(ctx))
 }
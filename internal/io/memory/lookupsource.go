@@ -16,13 +16,13 @@ package memory
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/io/memory/store"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
 )
 
 type lc struct {
@@ -33,16 +33,15 @@ type lc struct {
 // lookupsource is a lookup source that reads data from memory
 // The memory lookup table reads a global memory store for data
 type lookupsource struct {
-	topic      string
-	topicRegex *regexp.Regexp
-	table      *store.Table
-	key        string
+	topic string
+	table *store.Table
+	key   string
 }
 
 func (s *lookupsource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
 	ctx.GetLogger().Infof("lookup source %s is opened with key %v", s.topic, s.key)
 	var err error
-	s.table, err = store.Reg(s.topic, s.topicRegex, s.key)
+	s.table, err = store.Reg(s.topic, s.key)
 	if err != nil {
 		sch(api.ConnectionDisconnected, err.Error())
 		return err
@@ -60,12 +59,10 @@ func (s *lookupsource) Provision(ctx api.StreamContext, props map[string]any) er
 	if cfg.Topic == "" {
 		return fmt.Errorf("datasource(topic) is required")
 	}
-	if strings.ContainsAny(cfg.Topic, "+#") {
-		r, err := getRegexp(cfg.Topic)
-		if err != nil {
+	if pubsub.IsWildcard(cfg.Topic) {
+		if err := pubsub.ValidateTopic(cfg.Topic); err != nil {
 			return err
 		}
-		s.topicRegex = r
 	}
 	if cfg.Key == "" {
 		return fmt.Errorf("key is required for lookup source")
@@ -88,6 +85,17 @@ func (s *lookupsource) Lookup(ctx api.StreamContext, _ []string, keys []string,
 	return r, nil
 }
 
+// Scan reads all the rows of the memory table
+func (s *lookupsource) Scan(ctx api.StreamContext) ([]map[string]any, error) {
+	ctx.GetLogger().Debugf("lookup source %s is scanning all rows", s.topic)
+	tuples := s.table.All()
+	r := make([]map[string]any, len(tuples))
+	for i, t := range tuples {
+		r[i] = t.ToMap()
+	}
+	return r, nil
+}
+
 func (s *lookupsource) Close(ctx api.StreamContext) error {
 	ctx.GetLogger().Infof("lookup source %s is closing", s.topic)
 	return store.Unreg(s.topic, s.key)
@@ -96,3 +104,5 @@ func (s *lookupsource) Close(ctx api.StreamContext) error {
 func GetLookupSource() api.Source {
 	return &lookupsource{}
 }
+
+var _ model.ScanLookupSource = &lookupsource{}
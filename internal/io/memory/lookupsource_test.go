@@ -27,6 +27,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
 )
 
 func produceUpdatable(ctx api.StreamContext, topic string, data pubsub.MemTuple, rowkind string, keyval any) {
@@ -175,3 +176,30 @@ func TestLookup(t *testing.T) {
 	err = ls.Close(ctx)
 	assert.NoError(t, err)
 }
+
+func TestScan(t *testing.T) {
+	contextLogger := conf.Log.WithField("rule", "test3")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	ls := GetLookupSource().(api.LookupSource)
+	err := ls.Provision(ctx, map[string]any{"datasource": "test3", "key": "ff"})
+	assert.NoError(t, err)
+	err = ls.Connect(ctx, func(status string, message string) {
+		// do nothing
+	})
+	assert.NoError(t, err)
+	// wait for the source to be ready
+	time.Sleep(100 * time.Millisecond)
+	pubsub.Produce(ctx, "test3", &xsql.Tuple{Message: map[string]any{"ff": "value1", "gg": "value2"}})
+	pubsub.Produce(ctx, "test3", &xsql.Tuple{Message: map[string]any{"ff": "value2", "gg": "value3"}})
+	pubsub.Produce(ctx, "test3", &xsql.Tuple{Message: map[string]any{"ff": "value1", "gg": "value4"}})
+	// wait for table accumulation
+	time.Sleep(100 * time.Millisecond)
+	result, err := ls.(model.ScanLookupSource).Scan(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []map[string]any{
+		{"ff": "value1", "gg": "value4"},
+		{"ff": "value2", "gg": "value3"},
+	}, result)
+	err = ls.Close(ctx)
+	assert.NoError(t, err)
+}
@@ -367,3 +367,36 @@ func TestMultipleTopics(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestWildcardInmemoryNode(t *testing.T) {
+	pubsub.Reset()
+	contextLogger := conf.Log.WithField("rule", "test")
+	ctx1 := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	ctx, cancel := ctx1.WithCancel()
+	defer cancel()
+	src := GetSource()
+	assert.NoError(t, src.Provision(ctx, map[string]any{"datasource": "result/+/alerts"}))
+	var (
+		mu     sync.Mutex
+		topics []string
+	)
+	assert.NoError(t, src.Subscribe(ctx, func(ctx api.StreamContext, res any, meta map[string]any, ts time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		topics = append(topics, res.(*xsql.Tuple).Metadata["topic"].(string))
+	}, nil))
+	for _, topic := range []string{"result/r1/alerts", "result/r2/info", "result/r2/alerts"} {
+		snk := GetSink()
+		assert.NoError(t, snk.Provision(ctx, map[string]any{"topic": topic}))
+		assert.NoError(t, snk.Connect(ctx, func(status string, message string) {
+			// do nothing
+		}))
+		assert.NoError(t, snk.Collect(ctx, &xsql.Tuple{Message: map[string]any{"temp": 20}}))
+	}
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(topics) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"result/r1/alerts", "result/r2/alerts"}, topics)
+}
@@ -15,7 +15,6 @@
 package pubsub
 
 import (
-	"regexp"
 	"sync"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -32,14 +31,16 @@ type pubConsumers struct {
 }
 
 type subChan struct {
-	regex *regexp.Regexp
-	ch    chan any
+	wildcard string
+	ch       chan any
 }
 
 var (
 	pubTopics = make(map[string]*pubConsumers)
 	subExps   = make(map[string]*subChan)
-	mu        = sync.RWMutex{}
+	// subTrie indexes the wildcard subscriptions in subExps to find the matched ones of a topic
+	subTrie = newTopicTrie()
+	mu      = sync.RWMutex{}
 )
 
 func CreatePub(topic string) {
@@ -50,34 +51,41 @@ func CreatePub(topic string) {
 		c.count += 1
 		return
 	}
+	c := newPubConsumers(topic)
+	c.count = 1
+}
+
+// newPubConsumers creates the consumers of the topic with the matched wildcard subscriptions
+func newPubConsumers(topic string) *pubConsumers {
 	c := &pubConsumers{
-		count:     1,
 		consumers: make(map[string]chan any),
 	}
+	subTrie.match(topic, func(sourceId string, ch chan any) {
+		c.consumers[sourceId] = ch
+	})
 	pubTopics[topic] = c
-	for sourceId, sc := range subExps {
-		if sc.regex.MatchString(topic) {
-			addPubConsumer(topic, sourceId, sc.ch)
-		}
-	}
+	return c
 }
 
-func CreateSub(wildcard string, regex *regexp.Regexp, sourceId string, bufferLength int) chan any {
+// CreateSub subscribes to the topic which can have MQTT style wildcards. The wildcard topic must be validated by
+// ValidateTopic before.
+func CreateSub(topic string, sourceId string, bufferLength int) chan any {
 	mu.Lock()
 	defer mu.Unlock()
 	ch := make(chan any, bufferLength)
-	if regex != nil {
+	if IsWildcard(topic) {
 		subExps[sourceId] = &subChan{
-			regex: regex,
-			ch:    ch,
+			wildcard: topic,
+			ch:       ch,
 		}
-		for topic := range pubTopics {
-			if regex.MatchString(topic) {
-				addPubConsumer(topic, sourceId, ch)
+		subTrie.insert(topic, sourceId, ch)
+		for t := range pubTopics {
+			if MatchTopic(topic, t) {
+				addPubConsumer(t, sourceId, ch)
 			}
 		}
 	} else {
-		addPubConsumer(wildcard, sourceId, ch)
+		addPubConsumer(topic, sourceId, ch)
 	}
 	return ch
 }
@@ -89,8 +97,9 @@ func CloseSourceConsumerChannel(topic string, sourceId string) {
 	if sc, exists := subExps[sourceId]; exists {
 		close(sc.ch)
 		delete(subExps, sourceId)
-		for _, c := range pubTopics {
-			removePubConsumer(topic, sourceId, c)
+		subTrie.remove(sc.wildcard, sourceId)
+		for t, c := range pubTopics {
+			removePubConsumer(t, sourceId, c)
 		}
 	} else {
 		if sinkConsumerChannels, exists := pubTopics[topic]; exists {
@@ -115,6 +124,11 @@ func ProduceAny(ctx api.StreamContext, topic string, data any) {
 	doProduce(ctx, topic, data)
 }
 
+// TryProduceAny produces the data like ProduceAny and returns the count of the consumers which drop the data due to full buffer
+func TryProduceAny(ctx api.StreamContext, topic string, data any) int {
+	return doProduce(ctx, topic, data)
+}
+
 func Produce(ctx api.StreamContext, topic string, data MemTuple) {
 	doProduce(ctx, topic, data)
 }
@@ -127,16 +141,11 @@ func ProduceError(ctx api.StreamContext, topic string, err error) {
 	doProduce(ctx, topic, err)
 }
 
-func doProduce(ctx api.StreamContext, topic string, data any) {
-	c, exists := pubTopics[topic]
-	if !exists {
-		return
-	}
+func doProduce(ctx api.StreamContext, topic string, data any) (dropped int) {
 	logger := ctx.GetLogger()
 	mu.RLock()
 	defer mu.RUnlock()
-	// broadcast to all consumers
-	for name, out := range c.consumers {
+	send := func(name string, out chan any) {
 		select {
 		case out <- data:
 			logger.Debugf("memory source broadcast from topic %s to %s done", topic, name)
@@ -144,8 +153,20 @@ func doProduce(ctx api.StreamContext, topic string, data any) {
 			// rule stop so stop waiting
 		default:
 			logger.Errorf("memory source topic %s drop message to %s", topic, name)
+			dropped++
 		}
 	}
+	c, exists := pubTopics[topic]
+	if !exists {
+		// The topic is not created by a pub such as the dynamic topic of the sink, only the wildcard subscriptions may match
+		subTrie.match(topic, send)
+		return
+	}
+	// broadcast to all consumers
+	for name, out := range c.consumers {
+		send(name, out)
+	}
+	return
 }
 
 func addPubConsumer(topic string, sourceId string, ch chan any) {
@@ -153,10 +174,7 @@ func addPubConsumer(topic string, sourceId string, ch chan any) {
 	if c, exists := pubTopics[topic]; exists {
 		sinkConsumerChannels = c
 	} else {
-		sinkConsumerChannels = &pubConsumers{
-			consumers: make(map[string]chan any),
-		}
-		pubTopics[topic] = sinkConsumerChannels
+		sinkConsumerChannels = newPubConsumers(topic)
 	}
 	if _, exists := sinkConsumerChannels.consumers[sourceId]; exists {
 		conf.Log.Warnf("create memory source consumer for %s which is already exists", sourceId)
@@ -197,4 +215,5 @@ func removePubConsumer(topic string, sourceId string, c *pubConsumers) {
 func Reset() {
 	pubTopics = make(map[string]*pubConsumers)
 	subExps = make(map[string]*subChan)
+	subTrie = newTopicTrie()
 }
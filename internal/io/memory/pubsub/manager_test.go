@@ -17,12 +17,12 @@ package pubsub
 import (
 	"fmt"
 	"reflect"
-	"regexp"
-	"strings"
 	"testing"
 
 	"github.com/gdexlab/go-render/render"
 	"github.com/stretchr/testify/assert"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
 func TestCreateAndClose(t *testing.T) {
@@ -34,18 +34,7 @@ func TestCreateAndClose(t *testing.T) {
 	)
 	for i, topic := range sinkTopics {
 		CreatePub(topic)
-		var (
-			r   *regexp.Regexp
-			err error
-		)
-		if strings.ContainsAny(sourceTopics[i], "+#") {
-			r, err = getRegexp(sourceTopics[i])
-			if err != nil {
-				t.Error(err)
-				return
-			}
-		}
-		c := CreateSub(sourceTopics[i], r, fmt.Sprintf("%d", i), 100)
+		c := CreateSub(sourceTopics[i], fmt.Sprintf("%d", i), 100)
 		chans = append(chans, c)
 	}
 
@@ -111,30 +100,15 @@ func TestCreateAndClose(t *testing.T) {
 	}
 }
 
-func getRegexp(topic string) (*regexp.Regexp, error) {
-	if len(topic) == 0 {
-		return nil, fmt.Errorf("invalid empty topic")
-	}
-
-	levels := strings.Split(topic, "/")
-	for i, level := range levels {
-		if level == "#" && i != len(levels)-1 {
-			return nil, fmt.Errorf("invalid topic %s: # must at the last level", topic)
-		}
-	}
-	regstr := strings.Replace(strings.ReplaceAll(topic, "+", "([^/]+)"), "#", ".", 1)
-	return regexp.Compile(regstr)
-}
-
 func TestCreateBeforeDelete(t *testing.T) {
 	Reset()
 	var chans []chan any
 	CreatePub("test")
 	// create first sub
-	c := CreateSub("test", nil, "source1", 100)
+	c := CreateSub("test", "source1", 100)
 	chans = append(chans, c)
 	// create sub again
-	c2 := CreateSub("test", nil, "source1", 100)
+	c2 := CreateSub("test", "source1", 100)
 	chans = append(chans, c2)
 	CloseSourceConsumerChannel("test", "source1")
 	expPub := map[string]*pubConsumers{
@@ -149,7 +123,7 @@ func TestCreateBeforeDelete(t *testing.T) {
 	assert.Equal(t, expPub, pubTopics)
 
 	CloseSourceConsumerChannel("test", "source1")
-	c3 := CreateSub("test", nil, "source1", 100)
+	c3 := CreateSub("test", "source1", 100)
 	expPub = map[string]*pubConsumers{
 		"test": {
 			count: 1,
@@ -161,7 +135,7 @@ func TestCreateBeforeDelete(t *testing.T) {
 	}
 	assert.Equal(t, expPub, pubTopics)
 
-	c4 := CreateSub("test", nil, "source1", 100)
+	c4 := CreateSub("test", "source1", 100)
 	CloseSourceConsumerChannel("test", "source1")
 
 	expPub = map[string]*pubConsumers{
@@ -175,3 +149,72 @@ func TestCreateBeforeDelete(t *testing.T) {
 	}
 	assert.Equal(t, expPub, pubTopics)
 }
+
+func TestWildcardProduce(t *testing.T) {
+	Reset()
+	ctx := mockContext.NewMockContext("testWildcard", "op")
+	all := CreateSub("result/#", "all", 10)
+	alerts := CreateSub("result/+/alerts", "alerts", 10)
+	exact := CreateSub("result/r1/alerts", "exact", 10)
+	// The pub is created after the wildcard subscription
+	CreatePub("result/r1/alerts")
+	ProduceAny(ctx, "result/r1/alerts", 1)
+	// The dynamic topic which has no pub is matched by the trie
+	ProduceAny(ctx, "result/r2/alerts", 2)
+	ProduceAny(ctx, "result/r2/info", 3)
+	ProduceAny(ctx, "other/r2/alerts", 4)
+	assert.Len(t, all, 3)
+	assert.Len(t, alerts, 2)
+	assert.Len(t, exact, 1)
+
+	CloseSourceConsumerChannel("result/+/alerts", "alerts")
+	ProduceAny(ctx, "result/r3/alerts", 5)
+	assert.Len(t, all, 4)
+	assert.Equal(t, &topicTrie{
+		children: map[string]*topicTrie{
+			"result": {
+				children: map[string]*topicTrie{
+					"#": {children: map[string]*topicTrie{}, subs: map[string]chan any{"all": all}},
+				},
+				subs: map[string]chan any{},
+			},
+		},
+		subs: map[string]chan any{},
+	}, subTrie)
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		wildcard string
+		topic    string
+		match    bool
+	}{
+		{"a/+/c", "a/b/c", true},
+		{"a/+/c", "a/b/d", false},
+		{"a/+/c", "a/b/c/d", false},
+		{"a/#", "a", true},
+		{"a/#", "a/b/c", true},
+		{"#", "a/b", true},
+		{"a/+", "a", false},
+		{"+/+", "a/b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wildcard+" "+tt.topic, func(t *testing.T) {
+			assert.Equal(t, tt.match, MatchTopic(tt.wildcard, tt.topic))
+			matched := false
+			trie := newTopicTrie()
+			trie.insert(tt.wildcard, "test", nil)
+			trie.match(tt.topic, func(string, chan any) {
+				matched = true
+			})
+			assert.Equal(t, tt.match, matched)
+		})
+	}
+}
+
+func TestValidateTopic(t *testing.T) {
+	assert.NoError(t, ValidateTopic("a/+/b/#"))
+	assert.EqualError(t, ValidateTopic(""), "invalid empty topic")
+	assert.EqualError(t, ValidateTopic("a/#/b"), "invalid topic a/#/b: # must at the last level")
+	assert.EqualError(t, ValidateTopic("a/b+"), "invalid topic a/b+: wildcard must occupy an entire level")
+}
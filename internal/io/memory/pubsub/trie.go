@@ -0,0 +1,135 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	singleLevelWildcard = "+"
+	multiLevelWildcard  = "#"
+)
+
+// IsWildcard returns true if the topic has MQTT style wildcards
+func IsWildcard(topic string) bool {
+	return strings.ContainsAny(topic, singleLevelWildcard+multiLevelWildcard)
+}
+
+// ValidateTopic validates the wildcard topic. The wildcard must take a whole level and # must be the last level.
+func ValidateTopic(topic string) error {
+	if len(topic) == 0 {
+		return fmt.Errorf("invalid empty topic")
+	}
+	levels := strings.Split(topic, "/")
+	for i, level := range levels {
+		if level == multiLevelWildcard && i != len(levels)-1 {
+			return fmt.Errorf("invalid topic %s: # must at the last level", topic)
+		}
+		if level != singleLevelWildcard && level != multiLevelWildcard && IsWildcard(level) {
+			return fmt.Errorf("invalid topic %s: wildcard must occupy an entire level", topic)
+		}
+	}
+	return nil
+}
+
+// MatchTopic returns true if the topic matches the wildcard
+func MatchTopic(wildcard string, topic string) bool {
+	wl := strings.Split(wildcard, "/")
+	tl := strings.Split(topic, "/")
+	for i, w := range wl {
+		if w == multiLevelWildcard {
+			return true
+		}
+		if i >= len(tl) {
+			return false
+		}
+		if w != singleLevelWildcard && w != tl[i] {
+			return false
+		}
+	}
+	return len(wl) == len(tl)
+}
+
+// topicTrie indexes the wildcard subscriptions by topic levels. Finding the subscriptions of a topic only walks the
+// levels of the topic instead of checking all the subscriptions.
+type topicTrie struct {
+	children map[string]*topicTrie
+	// The subscriptions end at this node, [sourceId]chan
+	subs map[string]chan any
+}
+
+func newTopicTrie() *topicTrie {
+	return &topicTrie{
+		children: make(map[string]*topicTrie),
+		subs:     make(map[string]chan any),
+	}
+}
+
+func (t *topicTrie) insert(wildcard string, sourceId string, ch chan any) {
+	n := t
+	for _, level := range strings.Split(wildcard, "/") {
+		c, ok := n.children[level]
+		if !ok {
+			c = newTopicTrie()
+			n.children[level] = c
+		}
+		n = c
+	}
+	n.subs[sourceId] = ch
+}
+
+func (t *topicTrie) remove(wildcard string, sourceId string) {
+	t.doRemove(strings.Split(wildcard, "/"), sourceId)
+}
+
+// doRemove returns true if the node is empty and can be pruned
+func (t *topicTrie) doRemove(levels []string, sourceId string) bool {
+	if len(levels) == 0 {
+		delete(t.subs, sourceId)
+	} else if c, ok := t.children[levels[0]]; ok {
+		if c.doRemove(levels[1:], sourceId) {
+			delete(t.children, levels[0])
+		}
+	}
+	return len(t.subs) == 0 && len(t.children) == 0
+}
+
+// match calls the fn for each subscription which matches the topic
+func (t *topicTrie) match(topic string, fn func(sourceId string, ch chan any)) {
+	t.doMatch(strings.Split(topic, "/"), fn)
+}
+
+func (t *topicTrie) doMatch(levels []string, fn func(sourceId string, ch chan any)) {
+	// # also matches the parent level
+	if c, ok := t.children[multiLevelWildcard]; ok {
+		for id, ch := range c.subs {
+			fn(id, ch)
+		}
+	}
+	if len(levels) == 0 {
+		for id, ch := range t.subs {
+			fn(id, ch)
+		}
+		return
+	}
+	if c, ok := t.children[levels[0]]; ok {
+		c.doMatch(levels[1:], fn)
+	}
+	if c, ok := t.children[singleLevelWildcard]; ok {
+		c.doMatch(levels[1:], fn)
+	}
+}
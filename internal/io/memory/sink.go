@@ -82,7 +82,7 @@ func (s *sink) Collect(ctx api.StreamContext, data api.MessageTuple) error {
 		spanCtx = dt.GetTracerCtx()
 	}
 	var (
-		t   pubsub.MemTuple = &xsql.Tuple{Message: data.ToMap(), Metadata: s.meta, Timestamp: timex.GetNow(), Ctx: spanCtx}
+		t   pubsub.MemTuple = &xsql.Tuple{Message: data.ToMap(), Metadata: s.getMeta(topic), Timestamp: timex.GetNow(), Ctx: spanCtx}
 		err error
 	)
 	if s.rowkindField != "" {
@@ -95,6 +95,16 @@ func (s *sink) Collect(ctx api.StreamContext, data api.MessageTuple) error {
 	return nil
 }
 
+// getMeta returns the metadata with the published topic so that the wildcard subscriber can know the matched topic
+func (s *sink) getMeta(topic string) map[string]any {
+	if topic == s.topic {
+		return s.meta
+	}
+	return map[string]any{
+		"topic": topic,
+	}
+}
+
 func (s *sink) wrapUpdatable(el pubsub.MemTuple) (pubsub.MemTuple, error) {
 	c, ok := el.Value(s.rowkindField, "")
 	var rowkind string
@@ -133,8 +143,9 @@ func (s *sink) CollectList(ctx api.StreamContext, tuples api.MessageTupleList) e
 		}
 	}
 	result := make([]pubsub.MemTuple, tuples.Len())
+	meta := s.getMeta(topic)
 	tuples.RangeOfTuples(func(index int, tuple api.MessageTuple) bool {
-		t := &xsql.Tuple{Message: tuple.ToMap(), Metadata: s.meta, Timestamp: timex.GetNow(), Ctx: spanCtx}
+		t := &xsql.Tuple{Message: tuple.ToMap(), Metadata: meta, Timestamp: timex.GetNow(), Ctx: spanCtx}
 		if s.rowkindField != "" {
 			st, err := s.wrapUpdatable(t)
 			if err != nil {
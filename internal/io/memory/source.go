@@ -16,8 +16,6 @@ package memory
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -33,8 +31,7 @@ type c struct {
 }
 
 type source struct {
-	topicRegex *regexp.Regexp
-	c          *c
+	c *c
 }
 
 func (s *source) Provision(ctx api.StreamContext, props map[string]any) error {
@@ -48,12 +45,10 @@ func (s *source) Provision(ctx api.StreamContext, props map[string]any) error {
 	if cfg.Topic == "" {
 		return fmt.Errorf("topic is required")
 	}
-	if strings.ContainsAny(cfg.Topic, "+#") {
-		r, err := getRegexp(cfg.Topic)
-		if err != nil {
+	if pubsub.IsWildcard(cfg.Topic) {
+		if err := pubsub.ValidateTopic(cfg.Topic); err != nil {
 			return err
 		}
-		s.topicRegex = r
 	}
 	s.c = cfg
 	return nil
@@ -66,7 +61,7 @@ func (s *source) Connect(_ api.StreamContext, sch api.StatusChangeHandler) error
 
 // Subscribe For memory source, it can receive a source tuple directly. So just pass it through
 func (s *source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestErr api.ErrorIngest) error {
-	ch := pubsub.CreateSub(s.c.Topic, s.topicRegex, fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId()), s.c.BufferLength)
+	ch := pubsub.CreateSub(s.c.Topic, fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId()), s.c.BufferLength)
 	ctx.GetLogger().Infof("Subscribe to topic %s", s.c.Topic)
 	go func() {
 		for {
@@ -87,21 +82,6 @@ func (s *source) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingest
 	return nil
 }
 
-func getRegexp(topic string) (*regexp.Regexp, error) {
-	if len(topic) == 0 {
-		return nil, fmt.Errorf("invalid empty topic")
-	}
-
-	levels := strings.Split(topic, "/")
-	for i, level := range levels {
-		if level == "#" && i != len(levels)-1 {
-			return nil, fmt.Errorf("invalid topic %s: # must at the last level", topic)
-		}
-	}
-	regstr := strings.Replace(strings.ReplaceAll(topic, "+", "([^/]+)"), "#", ".", 1)
-	return regexp.Compile(regstr)
-}
-
 func (s *source) Close(ctx api.StreamContext) error {
 	ctx.GetLogger().Debugf("closing memory source")
 	pubsub.CloseSourceConsumerChannel(s.c.Topic, fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId()))
@@ -19,6 +19,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/testx"
 )
 
@@ -72,7 +73,7 @@ func TestTopic(t *testing.T) {
 	}
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
 	for i, tt := range tests {
-		r, err := getRegexp(tt.wildcard)
+		err := pubsub.ValidateTopic(tt.wildcard)
 		if err != nil {
 			if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
 				t.Errorf("%d: error mismatch:\n  exp=%s\n  got=%s\n\n", i, tt.err, err)
@@ -81,7 +82,7 @@ func TestTopic(t *testing.T) {
 		}
 		results := make([]bool, len(tt.topics))
 		for j, topic := range tt.topics {
-			results[j] = r.MatchString(topic)
+			results[j] = pubsub.MatchTopic(tt.wildcard, topic)
 		}
 		if !reflect.DeepEqual(tt.results, results) {
 			t.Errorf("%d\n\nstmt mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.results, results)
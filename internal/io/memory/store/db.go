@@ -176,6 +176,17 @@ func (t *Table) Read(keys []string, values []interface{}) ([]pubsub.MemTuple, er
 	return result, nil
 }
 
+// All returns all the rows of the table
+func (t *Table) All() []pubsub.MemTuple {
+	t.RLock()
+	defer t.RUnlock()
+	result := make([]pubsub.MemTuple, 0, len(t.datamap))
+	for _, v := range t.datamap {
+		result = append(result, v)
+	}
+	return result
+}
+
 var db = &database{
 	tables: make(map[string]*tableCount),
 }
@@ -17,7 +17,6 @@ package store
 import (
 	"context"
 	"fmt"
-	"regexp"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
@@ -26,10 +25,10 @@ import (
 
 // Reg registers a topic to save it to memory store
 // Create a new go routine to listen to the topic and save the data to memory
-func Reg(topic string, topicRegex *regexp.Regexp, key string) (*Table, error) {
+func Reg(topic string, key string) (*Table, error) {
 	t, isNew := db.addTable(topic, key)
 	if isNew {
-		go runTable(topic, topicRegex, t)
+		go runTable(topic, t)
 	}
 	return t, nil
 }
@@ -37,9 +36,9 @@ func Reg(topic string, topicRegex *regexp.Regexp, key string) (*Table, error) {
 // runTable should only run in a single instance.
 // This go routine is used to accumulate data in memory
 // If the go routine close, the go routine exits but the data will be kept until table dropped
-func runTable(topic string, topicRegex *regexp.Regexp, t *Table) {
+func runTable(topic string, t *Table) {
 	conf.Log.Infof("runTable %s", topic)
-	ch := pubsub.CreateSub(topic, topicRegex, fmt.Sprintf("store_%s", topic), 1024)
+	ch := pubsub.CreateSub(topic, fmt.Sprintf("store_%s", topic), 1024)
 	ctx, cancel := context.WithCancel(context.Background())
 	t.cancel = cancel
 	for {
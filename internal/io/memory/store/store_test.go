@@ -23,12 +23,12 @@ func TestReg(t *testing.T) {
 	db = &database{
 		tables: make(map[string]*tableCount),
 	}
-	reg1, err := Reg("test", nil, "a")
+	reg1, err := Reg("test", "a")
 	if err != nil {
 		t.Errorf("register test error: %v", err)
 		return
 	}
-	_, err2 := Reg("test", nil, "a")
+	_, err2 := Reg("test", "a")
 	if err2 != nil {
 		t.Errorf("register test error: %v", err2)
 		return
@@ -15,7 +15,12 @@
 package client
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
 // Client is the interface for mqtt client. There are two implementations v4 and v5
@@ -38,6 +43,37 @@ type CommonConfig struct {
 	PVersion string `json:"protocolVersion"`
 }
 
+// WillConfig is the Last Will and Testament of the connection. The broker publishes the will message when the
+// connection is lost unexpectedly. It is sent in each connect packet so that it is registered again after reconnection.
+type WillConfig struct {
+	WillTopic    string `json:"willTopic"`
+	WillPayload  string `json:"willPayload"`
+	WillQos      byte   `json:"willQos"`
+	WillRetained bool   `json:"willRetained"`
+}
+
+// ParseWillConfig parses and validates the will config. It returns nil if the will is not set.
+func ParseWillConfig(props map[string]any) (*WillConfig, error) {
+	w := &WillConfig{}
+	err := cast.MapToStruct(props, w)
+	if err != nil {
+		return nil, err
+	}
+	if w.WillTopic == "" {
+		if w.WillPayload != "" || w.WillQos != 0 || w.WillRetained {
+			return nil, fmt.Errorf("willTopic is required to set the will message")
+		}
+		return nil, nil
+	}
+	if strings.ContainsAny(w.WillTopic, "#+") {
+		return nil, fmt.Errorf("willTopic shouldn't contain # or +")
+	}
+	if w.WillQos > 2 {
+		return nil, fmt.Errorf("invalid willQos value %v, the value could be only int 0 or 1 or 2", w.WillQos)
+	}
+	return w, nil
+}
+
 type (
 	ConnectHandler      func(ctx api.StreamContext)
 	ConnectErrorHandler func(ctx api.StreamContext, e error)
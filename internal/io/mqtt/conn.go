@@ -16,6 +16,7 @@ package mqtt
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -208,14 +209,35 @@ func (conn *Connection) ParseMsg(ctx api.StreamContext, msg any) ([]byte, map[st
 
 const (
 	dataSourceProp = "datasource"
+	sharedPrefix   = "$share/"
 )
 
+// getTopicFromProps returns the topic filter to subscribe. It is the shared topic if shared subscription is enabled.
 func getTopicFromProps(props map[string]any) (string, error) {
 	v, ok := props[dataSourceProp]
-	if ok {
-		return v.(string), nil
+	if !ok {
+		return "", fmt.Errorf("topic or datasource not defined")
 	}
-	return "", fmt.Errorf("topic or datasource not defined")
+	topic := v.(string)
+	if shared, _ := cast.ToBool(props["sharedSubscription"], cast.CONVERT_ALL); shared {
+		group, _ := props["group"].(string)
+		return sharedTopic(topic, group)
+	}
+	return topic, nil
+}
+
+// sharedTopic builds the shared subscription topic filter $share/{group}/{topic}
+func sharedTopic(topic string, group string) (string, error) {
+	if strings.HasPrefix(topic, sharedPrefix) {
+		return "", fmt.Errorf("datasource %s is already a shared subscription, do not set sharedSubscription", topic)
+	}
+	if group == "" {
+		return "", fmt.Errorf("group is required when sharedSubscription is true")
+	}
+	if strings.ContainsAny(group, "/+#") {
+		return "", fmt.Errorf("invalid group %s, it must not contain /, + or #", group)
+	}
+	return sharedPrefix + group + "/" + topic, nil
 }
 
 var _ modules.StatefulDialer = &Connection{}
@@ -16,6 +16,7 @@ package mqtt
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
@@ -23,8 +24,10 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node/tracenode"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/transform"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
 )
 
 // AdConf is the advanced configuration for the mqtt sink
@@ -35,8 +38,19 @@ type AdConf struct {
 	SelId    string            `json:"connectionSelector"`
 	Props    map[string]string `json:"properties"`
 	PVersion string            `json:"protocolVersion"`
+	// TpcTemplate is the topic template calculated by the data. The static topic is used if it is not resolved
+	TpcTemplate string `json:"topicTemplate"`
+	// QosField is the column of the data to set the qos of each message. The static qos is used if it is absent
+	QosField string `json:"qosField"`
+	// RetainedField is the column of the data to set the retain flag of each message. The static retained is used if it is absent
+	RetainedField string `json:"retainedField"`
+	// PropertyFields are the columns of the data to set as the user properties of each message. Only for mqtt v5
+	PropertyFields []string `json:"propertyFields"`
 }
 
+// noValue is the output of the template when the field does not exist in the data
+const noValue = "<no value>"
+
 type Sink struct {
 	id     string
 	cw     *connection.ConnWrapper
@@ -55,18 +69,23 @@ func (ms *Sink) Provision(ctx api.StreamContext, ps map[string]any) error {
 	if err != nil {
 		return err
 	}
-	if adconf.Tpc == "" {
+	if adconf.Tpc == "" && adconf.TpcTemplate == "" {
 		return fmt.Errorf("mqtt sink is missing property topic")
 	}
 	if err := validateMQTTSinkTopic(adconf.Tpc); err != nil {
 		return err
 	}
+	if adconf.TpcTemplate != "" {
+		if _, err := transform.GenTp(adconf.TpcTemplate); err != nil {
+			return fmt.Errorf("invalid topicTemplate %s: %v", adconf.TpcTemplate, err)
+		}
+	}
 	if adconf.Qos != 0 && adconf.Qos != 1 && adconf.Qos != 2 {
 		return fmt.Errorf("invalid qos value %v, the value could be only int 0 or 1 or 2", adconf.Qos)
 	}
 	ms.config = ps
 	ms.adconf = adconf
-	if adconf.PVersion != "5" && adconf.Props != nil {
+	if adconf.PVersion != "5" && (adconf.Props != nil || len(adconf.PropertyFields) > 0) {
 		ctx.GetLogger().Warnf("Only mqtt v5 supports properties, ignore the properties setting")
 	}
 	return nil
@@ -100,21 +119,96 @@ func validateMQTTSinkTopic(topic string) error {
 	return nil
 }
 
+// Templates returns the template of the qos and retained fields so that the qos and retain flag of each message are
+// calculated by the data. The fields are only accessible when sending the data one by one.
+func (ms *Sink) Templates(props map[string]any) []string {
+	if ss, err := cast.ToBool(props["sendSingle"], cast.CONVERT_ALL); err != nil || !ss {
+		return nil
+	}
+	var result []string
+	for _, k := range []string{"qosField", "retainedField"} {
+		if f, ok := props[k].(string); ok && f != "" {
+			result = append(result, fieldTemplate(f))
+		}
+	}
+	if fields, err := cast.ToStringSlice(props["propertyFields"], cast.CONVERT_SAMEKIND); err == nil {
+		for _, f := range fields {
+			if f != "" {
+				result = append(result, fieldTemplate(f))
+			}
+		}
+	}
+	return result
+}
+
+func fieldTemplate(field string) string {
+	return fmt.Sprintf("{{index . %q}}", field)
+}
+
 func (ms *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 	tpc := ms.adconf.Tpc
-	props := ms.adconf.Props
+	qos := ms.adconf.Qos
+	retained := ms.adconf.Retained
+	// Copy the properties so that the templates are calculated for each message
+	var props map[string]string
+	if len(ms.adconf.Props) > 0 {
+		props = make(map[string]string, len(ms.adconf.Props))
+		for k, v := range ms.adconf.Props {
+			props[k] = v
+		}
+	}
 	// If tpc supports dynamic props(template), planner will guarantee the result has the parsed dynamic props
 	if dp, ok := item.(api.HasDynamicProps); ok {
 		temp, transformed := dp.DynamicProps(tpc)
 		if transformed {
 			tpc = temp
 		}
+		if ms.adconf.TpcTemplate != "" {
+			temp, transformed = dp.DynamicProps(ms.adconf.TpcTemplate)
+			// Fall back to the static topic if the fields are absent
+			if transformed && temp != "" && !strings.Contains(temp, noValue) {
+				if err := validateMQTTSinkTopic(temp); err != nil {
+					return err
+				}
+				tpc = temp
+			}
+		}
+		if ms.adconf.QosField != "" {
+			temp, transformed = dp.DynamicProps(fieldTemplate(ms.adconf.QosField))
+			if transformed && temp != "" && temp != noValue {
+				q, err := strconv.Atoi(temp)
+				if err != nil || q < 0 || q > 2 {
+					return fmt.Errorf("invalid qos value %s of field %s, the value could be only int 0 or 1 or 2", temp, ms.adconf.QosField)
+				}
+				qos = byte(q)
+			}
+		}
+		if ms.adconf.RetainedField != "" {
+			temp, transformed = dp.DynamicProps(fieldTemplate(ms.adconf.RetainedField))
+			if transformed && temp != "" && temp != noValue {
+				r, err := strconv.ParseBool(temp)
+				if err != nil {
+					return fmt.Errorf("invalid retained value %s of field %s, the value could be only bool", temp, ms.adconf.RetainedField)
+				}
+				retained = r
+			}
+		}
 		for k, v := range props {
 			nv, ok := dp.DynamicProps(v)
 			if ok {
 				props[k] = nv
 			}
 		}
+		for _, f := range ms.adconf.PropertyFields {
+			temp, transformed = dp.DynamicProps(fieldTemplate(f))
+			// The absent fields are not set
+			if transformed && temp != noValue {
+				if props == nil {
+					props = make(map[string]string)
+				}
+				props[f] = temp
+			}
+		}
 	}
 	traced, _, span := tracenode.TraceInput(ctx, item, fmt.Sprintf("%s_emit", ctx.GetOpId()))
 	if traced {
@@ -126,8 +220,11 @@ func (ms *Sink) Collect(ctx api.StreamContext, item api.RawTuple) error {
 		}
 		props["traceparent"] = tracenode.BuildTraceParentId(traceID, spanID)
 	}
-	ctx.GetLogger().Debugf("publishing to topic %s", tpc)
-	return ms.cli.Publish(ctx, tpc, ms.adconf.Qos, ms.adconf.Retained, item.Raw(), props)
+	if tpc == "" {
+		return fmt.Errorf("mqtt sink topic is not resolved by topicTemplate %s", ms.adconf.TpcTemplate)
+	}
+	ctx.GetLogger().Debugf("publishing to topic %s with qos %d and retained %v", tpc, qos, retained)
+	return ms.cli.Publish(ctx, tpc, qos, retained, item.Raw(), props)
 }
 
 func (ms *Sink) Close(ctx api.StreamContext) error {
@@ -155,4 +252,5 @@ func GetSink() api.Sink {
 var (
 	_ api.BytesCollector = &Sink{}
 	_ util.PingableConn  = &Sink{}
+	_ model.TemplateSink = &Sink{}
 )
@@ -18,11 +18,14 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/io/mqtt/client"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
 	"github.com/lf-edge/ekuiper/v2/pkg/connection"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
@@ -80,6 +83,73 @@ func TestSinkConfigure(t *testing.T) {
 			},
 			expectedErr: "mqtt sink topic shouldn't contain # or +",
 		},
+		{
+			name: "Invalid topic template",
+			input: map[string]interface{}{
+				"server":        "123",
+				"topicTemplate": "devices/{{.deviceId",
+			},
+			expectedErr: "invalid topicTemplate devices/{{.deviceId: template: sink:1: unclosed action",
+		},
+		{
+			name: "Topic template only",
+			input: map[string]interface{}{
+				"server":        "123",
+				"topicTemplate": "devices/{{.deviceId}}/telemetry",
+				"qosField":      "q",
+			},
+			expectedAdConf: &AdConf{
+				TpcTemplate: "devices/{{.deviceId}}/telemetry",
+				QosField:    "q",
+			},
+		},
+		{
+			name: "Will without topic",
+			input: map[string]interface{}{
+				"server":      "123",
+				"topic":       "testTopic",
+				"willPayload": "offline",
+			},
+			expectedErr: "willTopic is required to set the will message",
+		},
+		{
+			name: "Invalid will qos",
+			input: map[string]interface{}{
+				"server":      "123",
+				"topic":       "testTopic",
+				"willTopic":   "status/ekuiper",
+				"willPayload": "offline",
+				"willQos":     3,
+			},
+			expectedErr: "invalid willQos value 3, the value could be only int 0 or 1 or 2",
+		},
+		{
+			name: "Wrong will topic",
+			input: map[string]interface{}{
+				"server":    "123",
+				"topic":     "testTopic",
+				"willTopic": "status/#",
+			},
+			expectedErr: "willTopic shouldn't contain # or +",
+		},
+		{
+			name: "Retained field and will",
+			input: map[string]interface{}{
+				"server":        "123",
+				"topic":         "testTopic",
+				"retained":      true,
+				"retainedField": "r",
+				"willTopic":     "status/ekuiper",
+				"willPayload":   "offline",
+				"willQos":       1,
+				"willRetained":  true,
+			},
+			expectedAdConf: &AdConf{
+				Tpc:           "testTopic",
+				Retained:      true,
+				RetainedField: "r",
+			},
+		},
 		{
 			name: "Invalid QoS",
 			input: map[string]interface{}{
@@ -171,3 +241,119 @@ func TestValidateMQTTSinkConf(t *testing.T) {
 		}
 	}
 }
+
+func TestSinkTemplates(t *testing.T) {
+	ms := &Sink{}
+	assert.Nil(t, ms.Templates(map[string]any{"topic": "demo"}))
+	assert.Nil(t, ms.Templates(map[string]any{"topic": "demo", "qosField": "q"}))
+	assert.Equal(t, []string{`{{index . "q"}}`}, ms.Templates(map[string]any{"topic": "demo", "qosField": "q", "sendSingle": true}))
+	assert.Equal(t, []string{`{{index . "q"}}`, `{{index . "r"}}`}, ms.Templates(map[string]any{"topic": "demo", "qosField": "q", "retainedField": "r", "sendSingle": true}))
+	assert.Equal(t, []string{`{{index . "r"}}`}, ms.Templates(map[string]any{"topic": "demo", "retainedField": "r", "sendSingle": true}))
+	assert.Equal(t, []string{`{{index . "traceId"}}`, `{{index . "contentType"}}`}, ms.Templates(map[string]any{"topic": "demo", "propertyFields": []any{"traceId", "contentType"}, "sendSingle": true}))
+}
+
+type mockPubClient struct {
+	client.Client
+	props []map[string]string
+}
+
+func (m *mockPubClient) Publish(_ api.StreamContext, _ string, _ byte, _ bool, _ []byte, properties map[string]string) error {
+	m.props = append(m.props, properties)
+	return nil
+}
+
+func TestSinkCollectProperties(t *testing.T) {
+	ctx := mockContext.NewMockContext("testsinkcollect", "sink1")
+	ms := &Sink{}
+	require.NoError(t, ms.Provision(ctx, map[string]any{
+		"server":          "123",
+		"topic":           "demo",
+		"protocolVersion": "5",
+		"properties":      map[string]any{"source": "ekuiper", "device": "{{.deviceId}}"},
+		"propertyFields":  []any{"traceId", "contentType"},
+	}))
+	mc := &mockPubClient{}
+	ms.cli = &Connection{Client: mc}
+	ms.cli.connected.Store(true)
+	items := []api.RawTuple{
+		&testx.MockRawTuple{Template: map[string]string{
+			"{{.deviceId}}":             "d1",
+			`{{index . "traceId"}}`:     "t1",
+			`{{index . "contentType"}}`: "application/json",
+		}},
+		// The absent fields are not set
+		&testx.MockRawTuple{Template: map[string]string{
+			"{{.deviceId}}":             "d2",
+			`{{index . "traceId"}}`:     "t2",
+			`{{index . "contentType"}}`: "<no value>",
+		}},
+	}
+	for _, item := range items {
+		require.NoError(t, ms.Collect(ctx, item))
+	}
+	assert.Equal(t, []map[string]string{
+		{"source": "ekuiper", "device": "d1", "traceId": "t1", "contentType": "application/json"},
+		{"source": "ekuiper", "device": "d2", "traceId": "t2"},
+	}, mc.props)
+	// The configured templates are kept
+	assert.Equal(t, "{{.deviceId}}", ms.adconf.Props["device"])
+}
+
+func TestSinkCollectTemplateErr(t *testing.T) {
+	ctx := mockContext.NewMockContext("testsinkcollect", "sink1")
+	tests := []struct {
+		name  string
+		props map[string]any
+		item  api.RawTuple
+		err   string
+	}{
+		{
+			name:  "invalid qos",
+			props: map[string]any{"server": "123", "topic": "demo", "qosField": "q"},
+			item: &testx.MockRawTuple{Template: map[string]string{
+				`{{index . "q"}}`: "3",
+			}},
+			err: "invalid qos value 3 of field q, the value could be only int 0 or 1 or 2",
+		},
+		{
+			name:  "invalid qos type",
+			props: map[string]any{"server": "123", "topic": "demo", "qosField": "q"},
+			item: &testx.MockRawTuple{Template: map[string]string{
+				`{{index . "q"}}`: "high",
+			}},
+			err: "invalid qos value high of field q, the value could be only int 0 or 1 or 2",
+		},
+		{
+			name:  "invalid retained",
+			props: map[string]any{"server": "123", "topic": "demo", "retainedField": "r"},
+			item: &testx.MockRawTuple{Template: map[string]string{
+				`{{index . "r"}}`: "yes",
+			}},
+			err: "invalid retained value yes of field r, the value could be only bool",
+		},
+		{
+			name:  "unresolved topic",
+			props: map[string]any{"server": "123", "topicTemplate": "{{.deviceId}}"},
+			item: &testx.MockRawTuple{Template: map[string]string{
+				"{{.deviceId}}": "<no value>",
+			}},
+			err: "mqtt sink topic is not resolved by topicTemplate {{.deviceId}}",
+		},
+		{
+			name:  "wrong resolved topic",
+			props: map[string]any{"server": "123", "topicTemplate": "devices/{{.deviceId}}"},
+			item: &testx.MockRawTuple{Template: map[string]string{
+				"devices/{{.deviceId}}": "devices/#",
+			}},
+			err: "mqtt sink topic shouldn't contain # or +",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &Sink{}
+			require.NoError(t, ms.Provision(ctx, tt.props))
+			err := ms.Collect(ctx, tt.item)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
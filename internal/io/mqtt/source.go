@@ -18,12 +18,14 @@ import (
 	"bytes"
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
@@ -45,6 +47,11 @@ type Conf struct {
 	Qos        int    `json:"qos"`
 	SelId      string `json:"connectionSelector"`
 	EofMessage string `json:"eofMessage"`
+	// SharedSubscription subscribes to the topic as $share/{group}/{topic} so that the messages are load balanced in the group
+	SharedSubscription bool   `json:"sharedSubscription"`
+	Group              string `json:"group"`
+	// PartitionIndex is the index of the parallel reader set by the concurrency
+	PartitionIndex int `json:"partitionIndex"`
 }
 
 func (ms *SourceConnector) Provision(ctx api.StreamContext, props map[string]any) error {
@@ -67,9 +74,13 @@ func (ms *SourceConnector) Provision(ctx api.StreamContext, props map[string]any
 		}
 		ctx.GetLogger().Infof("Set eof message to %x", ms.eofPayload)
 	}
+	tpc, err := getTopicFromProps(props)
+	if err != nil {
+		return err
+	}
 	ms.props = props
 	ms.cfg = cfg
-	ms.tpc = cfg.Topic
+	ms.tpc = tpc
 	return nil
 }
 
@@ -88,6 +99,10 @@ func (ms *SourceConnector) Connect(ctx api.StreamContext, sch api.StatusChangeHa
 	var cli *Connection
 	var err error
 	id := fmt.Sprintf("%s-%s-%s-mqtt-source", ctx.GetRuleId(), ctx.GetOpId(), ms.tpc)
+	// Each parallel reader has its own connection so that the shared subscription balances the messages among them
+	if ms.cfg.PartitionIndex > 0 {
+		id = fmt.Sprintf("%s-%d", id, ms.cfg.PartitionIndex)
+	}
 	cw, err := connection.FetchConnection(ctx, id, "mqtt", ms.props, sch)
 	if err != nil {
 		return err
@@ -118,11 +133,16 @@ func (ms *SourceConnector) onMessage(ctx api.StreamContext, msg any, ingest api.
 		ms.eof(ctx)
 		return
 	}
-	// extract trace id
-	if props != nil {
+	// extract trace id and the user properties of mqtt v5
+	if len(props) > 0 {
 		if tid, ok := props["traceparent"]; ok {
 			meta["traceId"] = tid
 		}
+		up := make(map[string]any, len(props))
+		for k, v := range props {
+			up[k] = v
+		}
+		meta["properties"] = up
 	}
 	ingest(ctx, payload, meta, rcvTime)
 }
@@ -135,6 +155,27 @@ func (ms *SourceConnector) Close(ctx api.StreamContext) error {
 	return connection.DetachConnection(ctx, ms.conId)
 }
 
+// PartitionProps balances the messages among the parallel readers by the shared subscription. Each reader has its own
+// connection, so the client id is suffixed by the index of the reader.
+func (ms *SourceConnector) PartitionProps(props map[string]any, index int, _ int) (map[string]any, error) {
+	shared, _ := cast.ToBool(props["sharedSubscription"], cast.CONVERT_ALL)
+	if tpc, _ := props[dataSourceProp].(string); !shared && !strings.HasPrefix(tpc, sharedPrefix) {
+		return nil, fmt.Errorf("concurrency requires the shared subscription, otherwise each reader receives all the messages")
+	}
+	if eof, _ := props["eofMessage"].(string); eof != "" {
+		return nil, fmt.Errorf("eofMessage is not supported with concurrency because only one reader receives it")
+	}
+	result := make(map[string]any, len(props)+1)
+	for k, v := range props {
+		result[k] = v
+	}
+	result["partitionIndex"] = index
+	if cid, _ := props["clientid"].(string); cid != "" && index > 0 {
+		result["clientid"] = fmt.Sprintf("%s_%d", cid, index)
+	}
+	return result, nil
+}
+
 func (ms *SourceConnector) SetEofIngest(eof api.EOFIngest) {
 	ms.eof = eof
 }
@@ -144,7 +185,8 @@ func GetSource() api.Source {
 }
 
 var (
-	_ api.BytesSource   = &SourceConnector{}
-	_ api.Bounded       = &SourceConnector{}
-	_ util.PingableConn = &SourceConnector{}
+	_ api.BytesSource         = &SourceConnector{}
+	_ api.Bounded             = &SourceConnector{}
+	_ util.PingableConn       = &SourceConnector{}
+	_ model.PartitionedSource = &SourceConnector{}
 )
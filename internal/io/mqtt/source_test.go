@@ -16,15 +16,18 @@ package mqtt
 
 import (
 	"encoding/base64"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/eclipse/paho.golang/paho"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/io/mqtt/v5client"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
 	"github.com/lf-edge/ekuiper/v2/internal/testx"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -79,6 +82,44 @@ func TestProvision(t *testing.T) {
 			},
 			err: "illegal base64 data at input byte 0",
 		},
+		{
+			name: "shared subscription",
+			props: map[string]any{
+				"server":             url,
+				"datasource":         "demo",
+				"sharedSubscription": true,
+				"group":              "g1",
+			},
+		},
+		{
+			name: "shared subscription without group",
+			props: map[string]any{
+				"server":             url,
+				"datasource":         "demo",
+				"sharedSubscription": true,
+			},
+			err: "group is required when sharedSubscription is true",
+		},
+		{
+			name: "shared subscription with invalid group",
+			props: map[string]any{
+				"server":             url,
+				"datasource":         "demo",
+				"sharedSubscription": true,
+				"group":              "g/1",
+			},
+			err: "invalid group g/1, it must not contain /, + or #",
+		},
+		{
+			name: "shared subscription with shared topic",
+			props: map[string]any{
+				"server":             url,
+				"datasource":         "$share/g1/demo",
+				"sharedSubscription": true,
+				"group":              "g1",
+			},
+			err: "datasource $share/g1/demo is already a shared subscription, do not set sharedSubscription",
+		},
 	}
 	sc := &SourceConnector{}
 	ctx := mockContext.NewMockContext("testprov", "source")
@@ -168,3 +209,130 @@ loop:
 
 	assert.Equal(t, data[:3], result)
 }
+
+func TestSharedSubscription(t *testing.T) {
+	url, cancel, err := testx.InitBroker("TestSharedSubscription")
+	require.NoError(t, err)
+	defer func() {
+		cancel()
+	}()
+	ctx, cancel := mockContext.NewMockContext("ruleShared", "op1").WithCancel()
+	defer cancel()
+	resultCh := make(chan []byte, 10)
+	for i := 0; i < 2; i++ {
+		r := &SourceConnector{}
+		err = r.Provision(ctx, map[string]any{
+			"server":             url,
+			"datasource":         "shareddemo",
+			"qos":                1,
+			"sharedSubscription": true,
+			"group":              "g1",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "$share/g1/shareddemo", r.tpc)
+		subCtx := mockContext.NewMockContext(fmt.Sprintf("ruleShared%d", i), "op1")
+		err = r.Connect(subCtx, func(status string, message string) {
+			// do nothing
+		})
+		require.NoError(t, err)
+		err = r.Subscribe(subCtx, func(ctx api.StreamContext, payload []byte, meta map[string]any, ts time.Time) {
+			resultCh <- payload
+		}, nil)
+		require.NoError(t, err)
+		defer r.Close(subCtx)
+	}
+	data := [][]byte{
+		[]byte("{\"humidity\":50,\"status\":\"green\",\"temperature\":22}"),
+		[]byte("{\"humidity\":82,\"status\":\"wet\",\"temperature\":25}"),
+		[]byte("{\"humidity\":60,\"status\":\"hot\",\"temperature\":33}"),
+	}
+	go func() {
+		sk := &Sink{}
+		err := mock.RunBytesSinkCollect(sk, data, map[string]any{
+			"server":   url,
+			"topic":    "shareddemo",
+			"qos":      1,
+			"retained": false,
+		})
+		assert.NoError(t, err)
+	}()
+	// Each message is only received by one of the subscribers in the group
+	var result [][]byte
+	ticker := timex.GetTicker(10 * time.Second)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case received := <-resultCh:
+			result = append(result, received)
+			if len(result) == len(data) {
+				break loop
+			}
+		case <-ticker.C:
+			assert.Fail(t, "time out")
+			break loop
+		}
+	}
+	select {
+	case received := <-resultCh:
+		assert.Fail(t, "receive duplicate message", string(received))
+	case <-time.After(500 * time.Millisecond):
+	}
+	assert.ElementsMatch(t, data, result)
+}
+
+func TestPartitionProps(t *testing.T) {
+	ms := &SourceConnector{}
+	_, err := ms.PartitionProps(map[string]any{"datasource": "demo"}, 0, 2)
+	require.EqualError(t, err, "concurrency requires the shared subscription, otherwise each reader receives all the messages")
+	_, err = ms.PartitionProps(map[string]any{"datasource": "$share/g1/demo", "eofMessage": "ZW9m"}, 0, 2)
+	require.EqualError(t, err, "eofMessage is not supported with concurrency because only one reader receives it")
+	props := map[string]any{"datasource": "demo", "sharedSubscription": true, "group": "g1", "clientid": "c"}
+	p, err := ms.PartitionProps(props, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, "c", p["clientid"])
+	require.Equal(t, 0, p["partitionIndex"])
+	p, err = ms.PartitionProps(props, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, "c_1", p["clientid"])
+	require.Equal(t, 1, p["partitionIndex"])
+	require.Equal(t, "c", props["clientid"])
+}
+
+func TestUserProperties(t *testing.T) {
+	ctx := mockContext.NewMockContext("testUserProps", "op")
+	ms := &SourceConnector{cli: &Connection{Client: &v5client.Client{}}}
+	var metas []map[string]any
+	ingest := func(_ api.StreamContext, _ []byte, meta map[string]any, _ time.Time) {
+		metas = append(metas, meta)
+	}
+	ms.onMessage(ctx, &paho.Publish{
+		Topic:   "demo",
+		Payload: []byte("{}"),
+		Properties: &paho.PublishProperties{
+			User: []paho.UserProperty{
+				{Key: "traceparent", Value: "00-abc-01"},
+				{Key: "contentType", Value: "application/json"},
+			},
+		},
+	}, ingest)
+	// No user property
+	ms.onMessage(ctx, &paho.Publish{Topic: "demo", Payload: []byte("{}")}, ingest)
+	assert.Equal(t, []map[string]any{
+		{
+			"topic":     "demo",
+			"qos":       byte(0),
+			"messageId": uint16(0),
+			"traceId":   "00-abc-01",
+			"properties": map[string]any{
+				"traceparent": "00-abc-01",
+				"contentType": "application/json",
+			},
+		},
+		{
+			"topic":     "demo",
+			"qos":       byte(0),
+			"messageId": uint16(0),
+		},
+	}, metas)
+}
@@ -42,6 +42,7 @@ type ConnectionConfig struct {
 	Password string `json:"password"`
 	pversion uint   // 3 or 4
 	tls      *tls.Config
+	will     *client.WillConfig
 }
 
 func Provision(ctx api.StreamContext, props map[string]any, onConnect client.ConnectHandler, onConnectLost client.ConnectErrorHandler, onReconnect client.ConnectHandler) (*Client, error) {
@@ -57,6 +58,10 @@ func Provision(ctx api.StreamContext, props map[string]any, onConnect client.Con
 	if c.Password != "" {
 		opts = opts.SetPassword(c.Password)
 	}
+	// The will is kept in the options, so it is sent again when auto reconnecting
+	if c.will != nil {
+		opts = opts.SetWill(c.will.WillTopic, c.will.WillPayload, c.will.WillQos, c.will.WillRetained)
+	}
 
 	opts.OnConnect = func(_ pahoMqtt.Client) {
 		onConnect(ctx)
@@ -126,6 +131,10 @@ func ValidateConfig(props map[string]any) (*ConnectionConfig, error) {
 	if c.ClientId == "" {
 		c.ClientId = uuid.New().String()
 	}
+	c.will, err = client.ParseWillConfig(props)
+	if err != nil {
+		return nil, err
+	}
 	// Default to MQTT 3.1.1 or NanoMQ cannot connect
 	switch c.PVersion {
 	case "3.1":
@@ -48,6 +48,7 @@ type ConnectionConfig struct {
 	Password  string `json:"password"`
 	serverUrl *url.URL
 	tls       *tls.Config
+	will      *client.WillConfig
 }
 
 func Provision(ctx api.StreamContext, props map[string]any, onConnect client.ConnectHandler, onConnectLost client.ConnectErrorHandler, _ client.ConnectHandler) (*Client, error) {
@@ -106,6 +107,15 @@ func Provision(ctx api.StreamContext, props map[string]any, onConnect client.Con
 	if cc.Password != "" {
 		cliCfg.ConnectPassword = []byte(cc.Password)
 	}
+	// The will is set in the connect packet of every connection attempt, so it is registered again after reconnection
+	if cc.will != nil {
+		cliCfg.WillMessage = &paho.WillMessage{
+			Topic:   cc.will.WillTopic,
+			Payload: []byte(cc.will.WillPayload),
+			QoS:     cc.will.WillQos,
+			Retain:  cc.will.WillRetained,
+		}
+	}
 	cm, err := autopaho.NewConnection(ctx, cliCfg) // starts process; will reconnect until context cancelled
 	if err != nil {
 		return nil, err
@@ -251,6 +261,10 @@ func ValidateConfig(props map[string]any) (*ConnectionConfig, error) {
 	if c.ClientId == "" {
 		c.ClientId = uuid.New().String()
 	}
+	c.will, err = client.ParseWillConfig(props)
+	if err != nil {
+		return nil, err
+	}
 	tlsConfig, err := cert.GenTLSConfig(props, "mqtt")
 	if err != nil {
 		return nil, err
@@ -0,0 +1,89 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package neuron
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/valyala/fastjson"
+
+	"github.com/lf-edge/ekuiper/v2/metrics"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+var NeuronSourceFilteredCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "kuiper",
+	Subsystem: "neuron_source",
+	Name:      "filtered_counter",
+	Help:      "counter of Neuron source messages dropped by the node and group filter",
+}, []string{metrics.LblRuleIDType, metrics.LblOpIDType})
+
+func init() {
+	prometheus.MustRegister(NeuronSourceFilteredCounter)
+}
+
+// filterConf is the node and group filter of the Neuron source. Each item is a name or a glob pattern like `modbus*`.
+// Empty filter matches all.
+type filterConf struct {
+	NodeFilter  []string `json:"nodeFilter"`
+	GroupFilter []string `json:"groupFilter"`
+}
+
+type filter struct {
+	nodes  []string
+	groups []string
+	parser fastjson.Parser
+}
+
+func newFilter(props map[string]any) (*filter, error) {
+	fc := &filterConf{}
+	if err := cast.MapToStruct(props, fc); err != nil {
+		return nil, fmt.Errorf("read properties %v fail with error: %v", props, err)
+	}
+	for _, p := range append(fc.NodeFilter, fc.GroupFilter...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %s: %v", p, err)
+		}
+	}
+	if len(fc.NodeFilter) == 0 && len(fc.GroupFilter) == 0 {
+		return nil, nil
+	}
+	return &filter{nodes: fc.NodeFilter, groups: fc.GroupFilter}, nil
+}
+
+// match only reads the node_name and group_name of the raw message to decide whether to emit it, so that the
+// dropped messages are never decoded into tuples. The message which cannot be parsed is passed to the decoder
+// to report the error.
+func (f *filter) match(data []byte) bool {
+	v, err := f.parser.ParseBytes(data)
+	if err != nil {
+		return true
+	}
+	return matchAny(f.nodes, string(v.GetStringBytes("node_name"))) && matchAny(f.groups, string(v.GetStringBytes("group_name")))
+}
+
+func matchAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
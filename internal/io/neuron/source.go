@@ -45,10 +45,11 @@ var (
 )
 
 type source struct {
-	c     *nng.SockConf
-	cli   *nng.Sock
-	props map[string]any
-	conId string
+	c      *nng.SockConf
+	cli    *nng.Sock
+	props  map[string]any
+	conId  string
+	filter *filter
 }
 
 func (s *source) Provision(_ api.StreamContext, props map[string]any) error {
@@ -57,8 +58,13 @@ func (s *source) Provision(_ api.StreamContext, props map[string]any) error {
 	if err != nil {
 		return err
 	}
+	f, err := newFilter(props)
+	if err != nil {
+		return err
+	}
 	s.c = sc
 	s.props = props
+	s.filter = f
 	return nil
 }
 
@@ -106,6 +112,10 @@ func (s *source) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingest
 						connected = true
 						ctx.GetLogger().Debugf("nng received message %s", string(msg))
 						rawData, meta := extractTraceMeta(ctx, msg)
+						if s.filter != nil && !s.filter.match(rawData) {
+							NeuronSourceFilteredCounter.WithLabelValues(ctx.GetRuleId(), ctx.GetOpId()).Inc()
+							continue
+						}
 						ingest(ctx, rawData, meta, timex.GetNow())
 					} else if err == mangos.ErrClosed {
 						if connected {
@@ -15,9 +15,12 @@
 package neuron
 
 import (
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	_ "go.nanomsg.org/mangos/v3/transport/ipc"
@@ -45,6 +48,76 @@ func TestRun(t *testing.T) {
 	})
 }
 
+func TestRunWithFilter(t *testing.T) {
+	exp := []api.MessageTuple{
+		model.NewDefaultRawTuple(data[0], nil, timex.GetNow()),
+		model.NewDefaultRawTuple(data[1], nil, timex.GetNow()),
+		model.NewDefaultRawTuple(data[2], nil, timex.GetNow()),
+	}
+	s := GetSource()
+	server, _ := mockNeuron(true, false, DefaultNeuronUrl)
+	defer server.Close()
+	mock.TestSourceConnector(t, s, map[string]any{
+		"datasource":  "new",
+		"url":         DefaultNeuronUrl,
+		"nodeFilter":  []any{"modbus", "node*"},
+		"groupFilter": []any{"group1"},
+	}, exp, func() {
+		// do nothing
+	})
+}
+
+func TestDropByFilter(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("testDropByFilter", "op").WithCancel()
+	defer cancel()
+	s := GetSource().(*source)
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"url":        DefaultNeuronUrl,
+		"nodeFilter": []any{"node2"},
+	}))
+	server, _ := mockNeuron(true, false, DefaultNeuronUrl)
+	defer server.Close()
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+	var received atomic.Int32
+	require.NoError(t, s.Subscribe(ctx, func(ctx api.StreamContext, payload []byte, meta map[string]any, ts time.Time) {
+		received.Add(1)
+	}, func(ctx api.StreamContext, err error) {
+		// do nothing
+	}))
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(NeuronSourceFilteredCounter.WithLabelValues("testDropByFilter", "op")) == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(0), received.Load())
+}
+
+func TestFilter(t *testing.T) {
+	_, err := newFilter(map[string]any{"nodeFilter": []any{"[a-"}})
+	assert.EqualError(t, err, "invalid filter pattern [a-: syntax error in pattern")
+	f, err := newFilter(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	f, err = newFilter(map[string]any{"nodeFilter": []any{"modbus*", "opcua"}, "groupFilter": []any{"grp?"}})
+	require.NoError(t, err)
+	tests := []struct {
+		data  string
+		match bool
+	}{
+		{data: `{"node_name": "modbus1", "group_name": "grp1", "values": {"a": 1}}`, match: true},
+		{data: `{"node_name": "opcua", "group_name": "grp2", "values": {"a": 1}}`, match: true},
+		{data: `{"node_name": "opcua2", "group_name": "grp2", "values": {"a": 1}}`, match: false},
+		{data: `{"node_name": "modbus1", "group_name": "group1", "values": {"a": 1}}`, match: false},
+		{data: `{"values": {"a": 1}}`, match: false},
+		{data: `invalid`, match: true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, f.match([]byte(tt.data)), tt.data)
+	}
+}
+
 func TestProvision(t *testing.T) {
 	ctx := mockContext.NewMockContext("t", "tt")
 	s := GetSource()
@@ -0,0 +1,360 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cert"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// connConf is the configuration to connect to the redis server
+type connConf struct {
+	// host:port address.
+	Addr     string `json:"addr,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Optional password. Must match the password specified in the
+	Password string `json:"password,omitempty"`
+	// Database to be selected after connecting to the server.
+	Db int `json:"db,omitempty"`
+	// Mode of the redis deployment, could be single, cluster or sentinel
+	Mode string `json:"mode,omitempty"`
+	// Comma separated host:port addresses of the cluster nodes or the sentinels
+	Addrs string `json:"addrs,omitempty"`
+	// The master name and sentinel password of sentinel mode
+	MasterName       string `json:"masterName,omitempty"`
+	SentinelPassword string `json:"sentinelPassword,omitempty"`
+	// TlsEnabled enables tls even if no certification is configured
+	TlsEnabled         bool   `json:"tlsEnabled,omitempty"`
+	CertFile           string `json:"certificationPath,omitempty"`
+	KeyFile            string `json:"privateKeyPath,omitempty"`
+	CaFile             string `json:"rootCaPath,omitempty"`
+	CertificationRaw   string `json:"certificationRaw,omitempty"`
+	PrivateKeyRaw      string `json:"privateKeyRaw,omitempty"`
+	RootCARaw          string `json:"rootCARaw,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	// HealthCheckInterval is the interval to ping the server in background. The connection is reported as
+	// disconnected if the ping fails. 0 disables the health check
+	HealthCheckInterval cast.DurationConf `json:"healthCheckInterval,omitempty"`
+	// Protocol is the RESP protocol version, could be 2 or 3
+	Protocol int `json:"protocol,omitempty"`
+	// PoolSize is the maximum number of the socket connections. 0 uses 10 connections per CPU
+	PoolSize     int `json:"poolSize,omitempty"`
+	MinIdleConns int `json:"minIdleConns,omitempty"`
+	// ReadTimeout and WriteTimeout are the timeouts of each command to read the reply and write the request
+	ReadTimeout  cast.DurationConf `json:"readTimeout,omitempty"`
+	WriteTimeout cast.DurationConf `json:"writeTimeout,omitempty"`
+}
+
+// validateConnConf parses the connection related properties. The db range is validated by the caller.
+func validateConnConf(props map[string]any) (*connConf, *tls.Config, error) {
+	c := &connConf{
+		Mode:                "single",
+		HealthCheckInterval: cast.DurationConf(10 * time.Second),
+		Protocol:            3,
+		ReadTimeout:         cast.DurationConf(3 * time.Second),
+		WriteTimeout:        cast.DurationConf(3 * time.Second),
+	}
+	err := cast.MapToStruct(props, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.HealthCheckInterval < 0 {
+		return nil, nil, errors.New("healthCheckInterval must not be negative")
+	}
+	if c.Protocol != 2 && c.Protocol != 3 {
+		return nil, nil, fmt.Errorf("redis protocol only support 2 or 3, but got %d", c.Protocol)
+	}
+	if c.PoolSize < 0 {
+		return nil, nil, errors.New("poolSize must not be negative")
+	}
+	if c.MinIdleConns < 0 {
+		return nil, nil, errors.New("minIdleConns must not be negative")
+	}
+	if c.PoolSize > 0 && c.MinIdleConns > c.PoolSize {
+		return nil, nil, fmt.Errorf("minIdleConns %d must not be larger than poolSize %d", c.MinIdleConns, c.PoolSize)
+	}
+	if c.ReadTimeout <= 0 {
+		return nil, nil, errors.New("readTimeout must be positive")
+	}
+	if c.WriteTimeout <= 0 {
+		return nil, nil, errors.New("writeTimeout must be positive")
+	}
+	switch c.Mode {
+	case "single":
+	case "cluster":
+		if c.Addrs == "" {
+			return nil, nil, errors.New("addrs is required when redis mode is cluster")
+		}
+		if c.Db != 0 {
+			return nil, nil, errors.New("redis only support db 0 when mode is cluster")
+		}
+	case "sentinel":
+		if c.Addrs == "" {
+			return nil, nil, errors.New("addrs is required when redis mode is sentinel")
+		}
+		if c.MasterName == "" {
+			return nil, nil, errors.New("masterName is required when redis mode is sentinel")
+		}
+	default:
+		return nil, nil, errors.New("redis mode only support single, cluster or sentinel")
+	}
+	if (c.CertFile != "" && c.KeyFile == "") || (c.CertificationRaw != "" && c.PrivateKeyRaw == "") {
+		return nil, nil, errors.New("private key is required when redis certification is set")
+	}
+	tlsConf, err := cert.GenTLSConfig(props, "redis")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error configuring tls: %s", err)
+	}
+	if tlsConf == nil && c.TlsEnabled {
+		tlsConf = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return c, tlsConf, nil
+}
+
+// newClient creates the client according to the mode
+func newClient(c *connConf, tlsConf *tls.Config) redis.UniversalClient {
+	switch c.Mode {
+	case "cluster":
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        splitAddrs(c.Addrs),
+			Username:     c.Username,
+			Password:     c.Password,
+			TLSConfig:    tlsConf,
+			Protocol:     c.Protocol,
+			PoolSize:     c.PoolSize,
+			MinIdleConns: c.MinIdleConns,
+			ReadTimeout:  time.Duration(c.ReadTimeout),
+			WriteTimeout: time.Duration(c.WriteTimeout),
+		})
+	case "sentinel":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       c.MasterName,
+			SentinelAddrs:    splitAddrs(c.Addrs),
+			SentinelPassword: c.SentinelPassword,
+			Username:         c.Username,
+			Password:         c.Password,
+			DB:               c.Db,
+			TLSConfig:        tlsConf,
+			Protocol:         c.Protocol,
+			PoolSize:         c.PoolSize,
+			MinIdleConns:     c.MinIdleConns,
+			ReadTimeout:      time.Duration(c.ReadTimeout),
+			WriteTimeout:     time.Duration(c.WriteTimeout),
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:         c.Addr,
+			Username:     c.Username,
+			Password:     c.Password,
+			DB:           c.Db, // use default DB
+			TLSConfig:    tlsConf,
+			Protocol:     c.Protocol,
+			PoolSize:     c.PoolSize,
+			MinIdleConns: c.MinIdleConns,
+			ReadTimeout:  time.Duration(c.ReadTimeout),
+			WriteTimeout: time.Duration(c.WriteTimeout),
+		})
+	}
+}
+
+func splitAddrs(addrs string) []string {
+	parts := strings.Split(addrs, ",")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// buildConnID builds the anonymous connection id so that the connectors with the same connection
+// configuration share the same client. The password is hashed to avoid exposing it in the id.
+func buildConnID(c *connConf) string {
+	b, _ := json.Marshal(c)
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	addr := c.Addr
+	if c.Mode != "single" {
+		addr = c.Addrs
+	}
+	return fmt.Sprintf("$$redis/%s/%s/%d/%x", c.Mode, addr, c.Db, h.Sum64())
+}
+
+// Connection is the redis client shared in the connection pool. It checks the health of the connection in background
+// and rebuilds the client once the connection is dropped, the status changes are reported to the connectors.
+type Connection struct {
+	id      string
+	c       *connConf
+	tlsConf *tls.Config
+	// cli is the current client which is replaced after the reconnection
+	cli    atomic.Pointer[redis.UniversalClient]
+	status atomic.Value
+	// scHandler is set by the connection pool and called by the health check goroutine, guarded by scLock
+	scLock    sync.Mutex
+	scHandler api.StatusChangeHandler
+	startOnce sync.Once
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (conn *Connection) Provision(_ api.StreamContext, conId string, props map[string]any) error {
+	c, tlsConf, err := validateConnConf(props)
+	if err != nil {
+		return err
+	}
+	if c.Db < 0 || c.Db > 15 {
+		return fmt.Errorf("redis db should be in range 0-15")
+	}
+	conn.id = conId
+	conn.c = c
+	conn.tlsConf = tlsConf
+	conn.done = make(chan struct{})
+	conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionConnecting})
+	return nil
+}
+
+func (conn *Connection) Dial(ctx api.StreamContext) error {
+	cli := newClient(conn.c, conn.tlsConf)
+	err := cli.Ping(ctx).Err()
+	if err != nil {
+		_ = cli.Close()
+		// the pool notifies the dial failure
+		conn.status.Store(modules.ConnectionStatus{Status: api.ConnectionDisconnected, ErrMsg: err.Error()})
+		return errorx.NewConnectionError(fmt.Errorf("found error when connecting to redis: %w", err))
+	}
+	if old := conn.cli.Swap(&cli); old != nil {
+		_ = (*old).Close()
+	}
+	conn.setStatus(api.ConnectionConnected, "")
+	ctx.GetLogger().Infof("new redis client created")
+	if conn.c.HealthCheckInterval > 0 {
+		conn.startOnce.Do(func() {
+			go conn.healthCheck(ctx)
+		})
+	}
+	return nil
+}
+
+// healthCheck pings the server periodically and reports the status transitions. The client is kept since it is
+// shared by the connectors and it reconnects by itself once the server is back.
+func (conn *Connection) healthCheck(ctx api.StreamContext) {
+	interval := time.Duration(conn.c.HealthCheckInterval)
+	ticker := timex.GetTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.done:
+			return
+		case <-ticker.C:
+		}
+		pingCtx, cancel := context.WithTimeout(context.Background(), interval)
+		err := conn.Client().Ping(pingCtx).Err()
+		cancel()
+		switch {
+		case err != nil && conn.IsConnected():
+			ctx.GetLogger().Warnf("redis connection %s is dropped: %v", conn.id, err)
+			conn.setStatus(api.ConnectionDisconnected, err.Error())
+		case err == nil && !conn.IsConnected():
+			ctx.GetLogger().Infof("redis connection %s is reconnected", conn.id)
+			conn.setStatus(api.ConnectionConnected, "")
+		}
+	}
+}
+
+func (conn *Connection) setStatus(status string, msg string) {
+	conn.scLock.Lock()
+	defer conn.scLock.Unlock()
+	conn.status.Store(modules.ConnectionStatus{Status: status, ErrMsg: msg})
+	if conn.scHandler != nil {
+		conn.scHandler(status, msg)
+	}
+}
+
+// Client returns the current client. It must be called after the connection is dialed successfully.
+func (conn *Connection) Client() redis.UniversalClient {
+	return *conn.cli.Load()
+}
+
+// IsConnected reports whether the last health check succeeds
+func (conn *Connection) IsConnected() bool {
+	return conn.status.Load().(modules.ConnectionStatus).Status == api.ConnectionConnected
+}
+
+func (conn *Connection) Status(_ api.StreamContext) modules.ConnectionStatus {
+	return conn.status.Load().(modules.ConnectionStatus)
+}
+
+func (conn *Connection) SetStatusChangeHandler(ctx api.StreamContext, sch api.StatusChangeHandler) {
+	conn.scLock.Lock()
+	defer conn.scLock.Unlock()
+	st := conn.Status(ctx)
+	sch(st.Status, st.ErrMsg)
+	conn.scHandler = sch
+}
+
+func (conn *Connection) GetId(_ api.StreamContext) string {
+	return conn.id
+}
+
+func (conn *Connection) Ping(ctx api.StreamContext) error {
+	if cli := conn.cli.Load(); cli != nil {
+		return (*cli).Ping(ctx).Err()
+	}
+	cli := newClient(conn.c, conn.tlsConf)
+	defer cli.Close()
+	return cli.Ping(ctx).Err()
+}
+
+func (conn *Connection) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("closing redis connection %s", conn.id)
+	var err error
+	conn.closeOnce.Do(func() {
+		if conn.done != nil {
+			close(conn.done)
+		}
+		if cli := conn.cli.Load(); cli != nil {
+			err = (*cli).Close()
+		}
+	})
+	return err
+}
+
+func CreateConnection(_ api.StreamContext) modules.Connection {
+	return &Connection{}
+}
+
+var (
+	_ modules.Connection     = &Connection{}
+	_ modules.StatefulDialer = &Connection{}
+)
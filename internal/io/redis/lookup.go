@@ -15,6 +15,7 @@
 package redis
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
 )
 
 type conf struct {
@@ -35,12 +37,18 @@ type conf struct {
 	Password string `json:"password,omitempty"`
 	DataType string `json:"dataType,omitempty"`
 	DB       string `json:"datasource,omitempty"`
+	// KeyPrefix is prepended to the lookup value to form the redis key
+	KeyPrefix string `json:"keyPrefix,omitempty"`
 }
 
 type lookupSource struct {
-	c   *conf
-	db  int
-	cli *redis.Client
+	c         *conf
+	db        int
+	cc        *connConf
+	tlsConf   *tls.Config
+	connProps map[string]any
+	cw        *connection.ConnWrapper
+	conn      *Connection
 }
 
 func (s *lookupSource) Ping(ctx api.StreamContext, props map[string]any) error {
@@ -48,14 +56,9 @@ func (s *lookupSource) Ping(ctx api.StreamContext, props map[string]any) error {
 	if err != nil {
 		return err
 	}
-	s.cli = redis.NewClient(&redis.Options{
-		Addr:     s.c.Addr,
-		Username: s.c.Username,
-		Password: s.c.Password,
-		DB:       s.db, // use default DB
-	})
-	defer s.cli.Close()
-	_, err = s.cli.Ping(ctx).Result()
+	cli := newClient(s.cc, s.tlsConf)
+	defer cli.Close()
+	_, err = cli.Ping(ctx).Result()
 	return err
 }
 
@@ -66,20 +69,22 @@ func (s *lookupSource) Provision(ctx api.StreamContext, props map[string]any) er
 func (s *lookupSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
 	logger := ctx.GetLogger()
 	logger.Debug("Opening redis lookup source")
-
-	s.cli = redis.NewClient(&redis.Options{
-		Addr:     s.c.Addr,
-		Username: s.c.Username,
-		Password: s.c.Password,
-		DB:       s.db, // use default DB
-	})
-	_, err := s.cli.Ping(ctx).Result()
+	var err error
+	// Connection pool will handle status change. Connectors with the same connection configuration share the client.
+	s.cw, err = connection.FetchConnection(ctx, buildConnID(s.cc), "redis", s.connProps, sch)
 	if err != nil {
-		sch(api.ConnectionDisconnected, err.Error())
 		return err
 	}
-	sch(api.ConnectionConnected, "")
-	return nil
+	conn, err := s.cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("redis client not ready: %v", err)
+	}
+	c, ok := conn.(*Connection)
+	if !ok {
+		return fmt.Errorf("should use redis connection")
+	}
+	s.conn = c
+	return err
 }
 
 func (s *lookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string, values []any) ([]map[string]any, error) {
@@ -87,9 +92,39 @@ func (s *lookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string,
 	if len(keys) != 1 {
 		return nil, fmt.Errorf("redis lookup only support one key, but got %v", keys)
 	}
-	v := fmt.Sprintf("%v", values[0])
-	if s.c.DataType == "string" {
-		res, err := s.cli.Get(ctx, v).Result()
+	// A list of values are looked up by MGET for string type
+	if vs, ok := values[0].([]any); ok && s.c.DataType == "string" {
+		rkeys := make([]string, 0, len(vs))
+		for _, v := range vs {
+			rkeys = append(rkeys, s.c.KeyPrefix+fmt.Sprintf("%v", v))
+		}
+		if len(rkeys) == 0 {
+			return []map[string]any{}, nil
+		}
+		res, err := s.mget(ctx, rkeys)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]map[string]any, 0, len(res))
+		for _, r := range res {
+			// missing key returns nil
+			str, ok := r.(string)
+			if !ok {
+				continue
+			}
+			m := make(map[string]any)
+			err = json.Unmarshal(cast.StringToBytes(str), &m)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, m)
+		}
+		return ret, nil
+	}
+	v := s.c.KeyPrefix + fmt.Sprintf("%v", values[0])
+	switch s.c.DataType {
+	case "string":
+		res, err := s.conn.Client().Get(ctx, v).Result()
 		if err != nil {
 			if err == redis.Nil {
 				return []map[string]any{}, nil
@@ -102,8 +137,24 @@ func (s *lookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string,
 			return nil, err
 		}
 		return []map[string]any{m}, nil
-	} else {
-		res, err := s.cli.LRange(ctx, v, 0, -1).Result()
+	case "hash":
+		res, err := s.conn.Client().HGetAll(ctx, v).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return []map[string]any{}, nil
+			}
+			return nil, err
+		}
+		if len(res) == 0 {
+			return []map[string]any{}, nil
+		}
+		m := make(map[string]any, len(res))
+		for k, r := range res {
+			m[k] = r
+		}
+		return []map[string]any{m}, nil
+	default:
+		res, err := s.conn.Client().LRange(ctx, v, 0, -1).Result()
 		if err != nil {
 			if err == redis.Nil {
 				return []map[string]any{}, nil
@@ -123,17 +174,45 @@ func (s *lookupSource) Lookup(ctx api.StreamContext, _ []string, keys []string,
 	}
 }
 
+// mget gets the values of the keys, the value of a missing key is nil. The keys of a cluster may be in different slots
+// which MGET does not support, so they are read by a pipeline of GET instead.
+func (s *lookupSource) mget(ctx api.StreamContext, keys []string) ([]any, error) {
+	cli := s.conn.Client()
+	if _, ok := cli.(*redis.ClusterClient); !ok {
+		return cli.MGet(ctx, keys...).Result()
+	}
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := cli.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, k := range keys {
+			cmds[i] = p.Get(ctx, k)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	res := make([]any, len(keys))
+	for i, cmd := range cmds {
+		v, err := cmd.Result()
+		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
 func (s *lookupSource) Validate(props map[string]any) error {
 	cfg := &conf{}
 	err := cast.MapToStruct(props, cfg)
 	if err != nil {
 		return err
 	}
-	if cfg.Addr == "" {
-		return errors.New("redis addr is null")
-	}
-	if cfg.DataType != "string" && cfg.DataType != "list" {
-		return errors.New("redis dataType must be string or list")
+	if cfg.DataType != "string" && cfg.DataType != "list" && cfg.DataType != "hash" {
+		return errors.New("redis dataType must be string, list or hash")
 	}
 	if cfg.DB == "/$$TEST_CONNECTION$$" {
 		cfg.DB = "0"
@@ -145,18 +224,33 @@ func (s *lookupSource) Validate(props map[string]any) error {
 	if s.db < 0 || s.db > 15 {
 		return fmt.Errorf("redis lookup source db should be in range 0-15")
 	}
+	// The db is defined by the datasource
+	connProps := make(map[string]any, len(props)+1)
+	for k, v := range props {
+		connProps[k] = v
+	}
+	connProps["db"] = s.db
+	s.cc, s.tlsConf, err = validateConnConf(connProps)
+	if err != nil {
+		return err
+	}
+	s.connProps = connProps
 	s.c = cfg
 	return nil
 }
 
 func (s *lookupSource) Open(ctx api.StreamContext) error {
-	ctx.GetLogger().Infof("Opening redis lookup source with conf %v", s.c)
+	// Do not log the password
+	ctx.GetLogger().Infof("Opening redis lookup source %s with db %s and data type %s", s.c.Addr, s.c.DB, s.c.DataType)
 	return nil
 }
 
 func (s *lookupSource) Close(ctx api.StreamContext) error {
 	ctx.GetLogger().Infof("Closing redis lookup source")
-	return s.cli.Close()
+	if s.cw != nil {
+		return connection.DetachConnection(ctx, s.cw.ID)
+	}
+	return nil
 }
 
 func GetLookupSource() api.Source {
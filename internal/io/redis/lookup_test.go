@@ -24,10 +24,14 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/modules"
 )
 
 func init() {
+	connection.InitConnectionManager4Test()
+	modules.RegisterConnection("redis", CreateConnection)
 	s, err := miniredis.Run()
 	if err != nil {
 		panic(err)
@@ -41,6 +45,8 @@ func init() {
 	s.Lpush("group1", `{"id":2,"name":"Susan"}`)
 	s.Lpush("group2", `{"id":3,"name":"Nancy"}`)
 	s.Lpush("group3", `{"id":4,"name":"Tom"}`)
+	// Mock hash data
+	s.HSet("device:1", "id", "1", "name", "sensor1")
 	mr = s
 }
 
@@ -132,6 +138,76 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestHash(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "tt")
+	ls := GetLookupSource()
+	err := ls.Provision(ctx, map[string]any{"addr": addr, "datatype": "hash", "datasource": "0", "keyPrefix": "device:"})
+	require.NoError(t, err)
+	err = ls.Connect(ctx, func(status string, message string) {
+		// do nothing
+	})
+	require.NoError(t, err)
+	defer ls.Close(ctx)
+	tests := []struct {
+		value  any
+		result []map[string]any
+	}{
+		{
+			value: 1,
+			result: []map[string]any{
+				{"id": "1", "name": "sensor1"},
+			},
+		}, {
+			value:  2,
+			result: []map[string]any{},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, err := ls.(api.LookupSource).Lookup(ctx, []string{}, []string{"id"}, []any{tt.value})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, actual)
+		})
+	}
+}
+
+func TestMGet(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "tt")
+	ls := GetLookupSource()
+	err := ls.Provision(ctx, map[string]any{"addr": addr, "datatype": "string", "datasource": "0"})
+	require.NoError(t, err)
+	err = ls.Connect(ctx, func(status string, message string) {
+		// do nothing
+	})
+	require.NoError(t, err)
+	defer ls.Close(ctx)
+	actual, err := ls.(api.LookupSource).Lookup(ctx, []string{}, []string{"id"}, []any{[]any{1, 3, 2}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"id": float64(1), "name": "John", "address": float64(34), "mobile": "334433"},
+		{"id": float64(2), "name": "Susan", "address": float64(22), "mobile": "666433"},
+	}, actual)
+}
+
+func TestMGetCluster(t *testing.T) {
+	ctx := mockContext.NewMockContext("test", "tt")
+	ls := GetLookupSource()
+	// the keys are in different slots
+	err := ls.Provision(ctx, map[string]any{"mode": "cluster", "addrs": addr, "datatype": "string", "datasource": "0"})
+	require.NoError(t, err)
+	err = ls.Connect(ctx, func(status string, message string) {
+		// do nothing
+	})
+	require.NoError(t, err)
+	defer ls.Close(ctx)
+	actual, err := ls.(api.LookupSource).Lookup(ctx, []string{}, []string{"id"}, []any{[]any{1, 3, 2}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"id": float64(1), "name": "John", "address": float64(34), "mobile": "334433"},
+		{"id": float64(2), "name": "Susan", "address": float64(22), "mobile": "666433"},
+	}, actual)
+}
+
 func TestLookupSourceDB(t *testing.T) {
 	ctx := mockContext.NewMockContext("test", "tt")
 	s := &lookupSource{}
@@ -0,0 +1,70 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/lf-edge/ekuiper/v2/metrics"
+)
+
+const (
+	// The status of the delete commands which remove something or nothing
+	LblDeleteRemoved = "removed"
+	LblDeleteNoop    = "noop"
+)
+
+var (
+	RedisSinkCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kuiper",
+		Subsystem: "redis_sink",
+		Name:      "counter",
+		Help:      "counter of Redis Sink commands",
+	}, []string{metrics.LblType, metrics.LblStatusType, metrics.LblRuleIDType, metrics.LblOpIDType})
+
+	RedisSinkCommandDurationHist = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "kuiper",
+		Subsystem: "redis_sink",
+		Name:      "command_duration_hist",
+		Help:      "Sink Historgram Duration of Redis commands",
+		Buckets:   prometheus.ExponentialBuckets(10, 2, 20), // 10us ~ 5s
+	}, []string{metrics.LblType, metrics.LblRuleIDType, metrics.LblOpIDType})
+
+	RedisSinkDeleteCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kuiper",
+		Subsystem: "redis_sink",
+		Name:      "delete_counter",
+		Help:      "counter of Redis Sink delete commands by whether anything is removed",
+	}, []string{metrics.LblType, metrics.LblStatusType, metrics.LblRuleIDType, metrics.LblOpIDType})
+)
+
+func init() {
+	prometheus.MustRegister(RedisSinkCounter)
+	prometheus.MustRegister(RedisSinkCommandDurationHist)
+	prometheus.MustRegister(RedisSinkDeleteCounter)
+}
+
+// observeCmd records the result and latency of the command by its operation name such as set and lpush.
+// It returns the error of the command.
+func observeCmd(ctx api.StreamContext, cmd redis.Cmder, start time.Time) error {
+	err := cmd.Err()
+	RedisSinkCounter.WithLabelValues(cmd.Name(), metrics.GetStatusValue(err), ctx.GetRuleId(), ctx.GetOpId()).Inc()
+	RedisSinkCommandDurationHist.WithLabelValues(cmd.Name(), ctx.GetRuleId(), ctx.GetOpId()).Observe(float64(time.Since(start).Microseconds()))
+	return err
+}
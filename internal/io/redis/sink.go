@@ -15,27 +15,32 @@
 package redis
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/tracenode"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/transform"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
 )
 
 type config struct {
-	// host:port address.
-	Addr     string `json:"addr,omitempty"`
-	Username string `json:"username,omitempty"`
-	// Optional password. Must match the password specified in the
-	Password string `json:"password,omitempty"`
-	// Database to be selected after connecting to the server.
-	Db int `json:"db,omitempty"`
 	// key of field
 	Field string `json:"field,omitempty"`
 	// key define
@@ -43,15 +48,71 @@ type config struct {
 	KeyType      string            `json:"keyType,omitempty"`
 	DataType     string            `json:"dataType,omitempty"`
 	Expiration   cast.DurationConf `json:"expiration,omitempty"`
+	KeepTTL      bool              `json:"keepTTL"`
 	RowkindField string            `json:"rowkindField"`
 	DataTemplate string            `json:"dataTemplate"`
 	Fields       []string          `json:"fields"`
 	DataField    string            `json:"dataField"`
+	// StrictFields reports an error if any of the fields does not exist instead of skipping it
+	StrictFields bool `json:"strictFields"`
+	// KeyPrefix is prepended to all the keys. It could be a template of the data like {{.tenant}}:
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	// ListDirection is the end of the list to push and pop, could be left or right
+	ListDirection string `json:"listDirection,omitempty"`
+	// MaxListLength caps the list length by trimming the list after each push. 0 means unlimited
+	MaxListLength int `json:"maxListLength,omitempty"`
+	// MaxLen caps the stream length by trimming the stream on each XADD. 0 means unlimited
+	MaxLen int64 `json:"maxLen,omitempty"`
+	// ApproxMaxLen trims the stream by ~ which is more efficient but may keep a few more entries
+	ApproxMaxLen bool `json:"approxMaxLen,omitempty"`
+	// StreamIdField is the field of the entry id to be deleted by XDEL for the delete rowkind
+	StreamIdField string `json:"streamIdField,omitempty"`
+	// Transactional wraps all the commands of a tuple in a MULTI/EXEC transaction
+	Transactional bool `json:"transactional,omitempty"`
+	// BatchPipeline sends all the commands of a batch in one pipeline
+	BatchPipeline bool `json:"batchPipeline"`
+	// WriteMode is the condition to set the string value, could be always, ifNotExists or ifExists
+	WriteMode string `json:"writeMode,omitempty"`
+	// WriteOp is the operation to write the value, could be set or incr. The incr operation adds the integer value to
+	// the existing value by INCRBY for string data type or HINCRBY for hash data type
+	WriteOp string `json:"writeOp,omitempty"`
+	// FailOnSkip reports an error when the conditional write is skipped
+	FailOnSkip bool `json:"failOnSkip"`
+	// Idempotent records the idempotency key of each written tuple so that the replayed tuple is not written again
+	Idempotent bool `json:"idempotent"`
+	// DedupField is the field used as the idempotency key. If not set, the hash of the tuple is used
+	DedupField string `json:"dedupField"`
+	// DedupKeyPrefix is prepended to the idempotency key to build the redis key which records it
+	DedupKeyPrefix string `json:"dedupKeyPrefix"`
+	// DedupTTL is the expiration of the idempotency key record. 0 means never expire
+	DedupTTL cast.DurationConf `json:"dedupTTL"`
+	// DeleteAck reports whether each delete removes anything by metrics and debug log. Popping an absent list is a
+	// no-op delete instead of an error
+	DeleteAck bool `json:"deleteAck"`
 }
 
+const (
+	writeModeAlways      = "always"
+	writeModeIfNotExists = "ifNotExists"
+	writeModeIfExists    = "ifExists"
+)
+
+const (
+	writeOpSet  = "set"
+	writeOpIncr = "incr"
+)
+
 type RedisSink struct {
-	c   *config
-	cli *redis.Client
+	c       *config
+	cc      *connConf
+	tlsConf *tls.Config
+	props   map[string]any
+	cw      *connection.ConnWrapper
+	conn    *Connection
+	// prefixTp is the compiled key prefix if it is a template
+	prefixTp *template.Template
+	// dataTp is the compiled dataTemplate to render the stored value
+	dataTp *template.Template
 }
 
 func (r *RedisSink) Provision(_ api.StreamContext, props map[string]any) error {
@@ -61,29 +122,35 @@ func (r *RedisSink) Provision(_ api.StreamContext, props map[string]any) error {
 func (r *RedisSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
 	logger := ctx.GetLogger()
 	logger.Debug("Opening redis sink")
-
-	r.cli = redis.NewClient(&redis.Options{
-		Addr:     r.c.Addr,
-		Username: r.c.Username,
-		Password: r.c.Password,
-		DB:       r.c.Db, // use default DB
-	})
-	_, err := r.cli.Ping(ctx).Result()
+	var err error
+	// Connection pool will handle status change. Sinks with the same connection configuration share the client.
+	r.cw, err = connection.FetchConnection(ctx, buildConnID(r.cc), "redis", r.props, sch)
 	if err != nil {
-		sch(api.ConnectionDisconnected, err.Error())
 		return err
 	}
-	sch(api.ConnectionConnected, "")
-	return nil
+	conn, err := r.cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("redis client not ready: %v", err)
+	}
+	c, ok := conn.(*Connection)
+	if !ok {
+		return fmt.Errorf("should use redis connection")
+	}
+	r.conn = c
+	return err
 }
 
 func (r *RedisSink) Validate(props map[string]any) error {
-	c := &config{DataType: "string", Expiration: -1, KeyType: "single"}
+	c := &config{DataType: "string", Expiration: -1, KeyType: "single", ListDirection: "left", BatchPipeline: true, WriteMode: writeModeAlways, WriteOp: writeOpSet, DedupKeyPrefix: "dedup:", DedupTTL: cast.DurationConf(24 * time.Hour)}
 	err := cast.MapToStruct(props, c)
 	if err != nil {
 		return err
 	}
-	if c.Db < 0 || c.Db > 15 {
+	cc, tlsConf, err := validateConnConf(props)
+	if err != nil {
+		return err
+	}
+	if cc.Db < 0 || cc.Db > 15 {
 		return fmt.Errorf("redisSink db should be in range 0-15")
 	}
 	if c.KeyType == "single" && c.Key == "" && c.Field == "" {
@@ -92,10 +159,63 @@ func (r *RedisSink) Validate(props map[string]any) error {
 	if c.KeyType != "single" && c.KeyType != "multiple" {
 		return errors.New("KeyType only support single or multiple")
 	}
-	if c.DataType != "string" && c.DataType != "list" {
-		return errors.New("redis sink only support string or list data type")
+	if c.DataType != "string" && c.DataType != "list" && c.DataType != "hash" && c.DataType != "stream" {
+		return errors.New("redis sink only support string, list, hash or stream data type")
+	}
+	if c.DataType == "stream" && c.KeyType != "single" {
+		return errors.New("stream data type only support single keyType")
+	}
+	if c.MaxLen < 0 {
+		return errors.New("maxLen must not be negative")
+	}
+	if c.ListDirection != "left" && c.ListDirection != "right" {
+		return errors.New("listDirection only support left or right")
+	}
+	if c.MaxListLength < 0 {
+		return errors.New("maxListLength must not be negative")
+	}
+	switch c.WriteMode {
+	case writeModeAlways:
+	case writeModeIfNotExists, writeModeIfExists:
+		if c.DataType != "string" {
+			return errors.New("writeMode only support string data type")
+		}
+	default:
+		return errors.New("writeMode only support always, ifNotExists or ifExists")
+	}
+	switch c.WriteOp {
+	case writeOpSet:
+	case writeOpIncr:
+		if c.DataType != "string" && c.DataType != "hash" {
+			return errors.New("writeOp incr only support string or hash data type")
+		}
+		if c.WriteMode != writeModeAlways {
+			return errors.New("writeOp incr cannot be used with writeMode ifNotExists or ifExists")
+		}
+	default:
+		return errors.New("writeOp only support set or incr")
+	}
+	if c.Idempotent && c.DedupTTL < 0 {
+		return errors.New("dedupTTL must not be negative")
+	}
+	r.prefixTp = nil
+	if strings.Contains(c.KeyPrefix, "{{") {
+		r.prefixTp, err = transform.GenTp(c.KeyPrefix)
+		if err != nil {
+			return fmt.Errorf("invalid keyPrefix template %s: %v", c.KeyPrefix, err)
+		}
+	}
+	r.dataTp = nil
+	if c.DataTemplate != "" {
+		r.dataTp, err = transform.GenTp(c.DataTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid dataTemplate %s: %v", c.DataTemplate, err)
+		}
 	}
 	r.c = c
+	r.cc = cc
+	r.tlsConf = tlsConf
+	r.props = props
 	return nil
 }
 
@@ -103,12 +223,7 @@ func (r *RedisSink) Ping(ctx api.StreamContext, props map[string]any) error {
 	if err := r.Validate(props); err != nil {
 		return err
 	}
-	cli := redis.NewClient(&redis.Options{
-		Addr:     r.c.Addr,
-		Username: r.c.Username,
-		Password: r.c.Password,
-		DB:       r.c.Db, // use default DB
-	})
+	cli := newClient(r.cc, r.tlsConf)
 	_, err := cli.Ping(ctx).Result()
 	defer func() {
 		cli.Close()
@@ -117,96 +232,263 @@ func (r *RedisSink) Ping(ctx api.StreamContext, props map[string]any) error {
 }
 
 func (r *RedisSink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
-	return r.save(ctx, item.ToMap())
+	if err := r.checkConnected(ctx); err != nil {
+		return err
+	}
+	traced, span := tracenode.TraceExternalCall(ctx, item, "redis")
+	err := r.collect(ctx, item)
+	if traced {
+		tracenode.EndExternalCall(span, err)
+	}
+	return err
+}
+
+func (r *RedisSink) collect(ctx api.StreamContext, item api.MessageTuple) error {
+	var err error
+	switch {
+	case r.c.Idempotent:
+		err = r.saveOnce(ctx, item.ToMap())
+	case r.c.Transactional:
+		err = r.saveTx(ctx, item.ToMap())
+	default:
+		err = r.save(ctx, r.conn.Client(), item.ToMap())
+	}
+	return structuredErr(err)
 }
 
 func (r *RedisSink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
-	// TODO handle partial error
+	if err := r.checkConnected(ctx); err != nil {
+		return err
+	}
+	traced, span := tracenode.TraceExternalCall(ctx, items, "redis")
+	err := structuredErr(r.collectList(ctx, items))
+	if traced {
+		tracenode.EndExternalCall(span, err)
+	}
+	return err
+}
+
+// checkConnected fails fast when the health check finds the connection dropped. The connection error is retryable,
+// so the data is resent by the cache after the reconnection.
+func (r *RedisSink) checkConnected(ctx api.StreamContext) error {
+	if !r.conn.IsConnected() {
+		return errorx.NewConnectionError(fmt.Errorf("redis connection %s is disconnected", r.conn.GetId(ctx)))
+	}
+	return nil
+}
+
+func (r *RedisSink) collectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	if r.c.Idempotent {
+		// Stop at the first failure, the written tuples are skipped when the list is retried
+		var err error
+		items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+			err = r.saveOnce(ctx, tuple.ToMap())
+			return err == nil
+		})
+		return err
+	}
+	if r.c.Transactional {
+		// Each tuple is saved in its own transaction
+		var errs []error
+		items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+			err := r.saveTx(ctx, tuple.ToMap())
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return true
+		})
+		return errors.Join(errs...)
+	}
+	if !r.c.BatchPipeline {
+		items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+			err := r.save(ctx, r.conn.Client(), tuple.ToMap())
+			if err != nil {
+				ctx.GetLogger().Error(err)
+			}
+			return true
+		})
+		return nil
+	}
+	var (
+		errs       []error
+		failedKeys []string
+	)
+	pipe := r.conn.Client().Pipeline()
 	items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
-		err := r.save(ctx, tuple.ToMap())
+		err := r.save(ctx, pipe, tuple.ToMap())
 		if err != nil {
-			ctx.GetLogger().Error(err)
+			errs = append(errs, err)
 		}
 		return true
 	})
+	start := time.Now()
+	// The error of each command is set even if the whole pipeline fails
+	cmds, _ := pipe.Exec(ctx)
+	for _, cmd := range cmds {
+		// All the commands in the pipeline share the latency of the pipeline
+		_ = observeCmd(ctx, cmd, start)
+		if r.ackDelete(ctx, cmd) == nil {
+			continue
+		}
+		err := r.checkSkip(cmd)
+		if err == nil {
+			continue
+		}
+		key := cmdKey(cmd)
+		failedKeys = append(failedKeys, key)
+		errs = append(errs, fmt.Errorf("%s %s error, %w", cmd.Name(), key, err))
+	}
+	if len(errs) > 0 {
+		if len(failedKeys) > 0 {
+			return fmt.Errorf("redis sink failed to write keys %v: %w", failedKeys, errors.Join(errs...))
+		}
+		return errors.Join(errs...)
+	}
 	return nil
 }
 
 func (r *RedisSink) Close(ctx api.StreamContext) error {
 	ctx.GetLogger().Infof("Closing redis sink")
-	err := r.cli.Close()
+	if r.cw != nil {
+		return connection.DetachConnection(ctx, r.cw.ID)
+	}
+	return nil
+}
+
+// saveTx writes all the commands of the data in a transaction so that the data is written as a whole
+func (r *RedisSink) saveTx(ctx api.StreamContext, data map[string]any) error {
+	return r.execTx(ctx, r.conn.Client().TxPipeline(), data)
+}
+
+// saveOnce writes the data only if its idempotency key is not recorded. The key is recorded by SETNX on its own
+// because it may be in another hash slot than the data keys in cluster mode. The record is removed if the write
+// fails, so that the retried data is written again.
+func (r *RedisSink) saveOnce(ctx api.StreamContext, data map[string]any) error {
+	id, err := util.IdempotencyKey(data, r.c.DedupField)
+	if err != nil {
+		return err
+	}
+	dedupKey := r.c.DedupKeyPrefix + id
+	start := time.Now()
+	cmd := r.conn.Client().SetNX(ctx, dedupKey, 1, time.Duration(r.c.DedupTTL))
+	if err := observeCmd(ctx, cmd, start); err != nil {
+		return err
+	}
+	if !cmd.Val() {
+		ctx.GetLogger().Debugf("skip the duplicate data with dedup key %s", dedupKey)
+		return nil
+	}
+	err = r.execTx(ctx, r.conn.Client().TxPipeline(), data)
+	if err != nil {
+		if derr := r.conn.Client().Del(ctx, dedupKey).Err(); derr != nil {
+			ctx.GetLogger().Warnf("fail to remove dedup key %s: %v", dedupKey, derr)
+		}
+	}
 	return err
 }
 
-func (r *RedisSink) save(ctx api.StreamContext, data map[string]any) error {
+// execTx queues the commands of the data in the transaction pipeline and executes it
+func (r *RedisSink) execTx(ctx api.StreamContext, pipe redis.Pipeliner, data map[string]any) error {
+	err := r.save(ctx, pipe, data)
+	if err != nil {
+		pipe.Discard()
+		return err
+	}
+	start := time.Now()
+	cmds, err := pipe.Exec(ctx)
+	var cmdErr error
+	for _, cmd := range cmds {
+		_ = observeCmd(ctx, cmd, start)
+		if r.ackDelete(ctx, cmd) != nil && cmdErr == nil {
+			cmdErr = r.checkSkip(cmd)
+		}
+	}
+	if errors.Is(err, redis.Nil) {
+		// The first failed command may be a skipped conditional write or a no-op pop, check the others
+		err = cmdErr
+	}
+	if err != nil {
+		return fmt.Errorf("redis sink transaction failed for data %v: %w", data, err)
+	}
+	return nil
+}
+
+// save writes the data by the cli which could be the client or a pipeline. When using a pipeline,
+// the commands are only queued and the errors are returned when executing the pipeline.
+func (r *RedisSink) save(ctx api.StreamContext, cli redis.Cmdable, data map[string]any) error {
 	logger := ctx.GetLogger()
+	// get action type
+	rowkind, err := r.getRowkind(data)
+	if err != nil {
+		return err
+	}
+	prefix, err := r.getKeyPrefix(data)
+	if err != nil {
+		return err
+	}
+	// payload is the data to be stored, the key and rowkind are still read from the original data
+	payload, sv, err := r.transformData(data)
+	if err != nil {
+		return errorx.NewSerializationError(err)
+	}
+	if r.c.DataType == "hash" {
+		return r.saveHash(ctx, cli, data, payload, rowkind, prefix)
+	}
+	if r.c.DataType == "stream" {
+		return r.saveStream(ctx, cli, data, payload, rowkind, prefix)
+	}
 	// prepare key value pairs
 	values := make(map[string]string)
 	if r.c.KeyType == "multiple" {
-		for key, val := range data {
+		for key, val := range payload {
 			v, _ := cast.ToString(val, cast.CONVERT_ALL)
-			values[key] = v
+			values[prefix+key] = v
 		}
 	} else {
-		jsonBytes, err := json.Marshal(data)
+		key, err := r.getKey(data)
 		if err != nil {
 			return err
 		}
-		val := string(jsonBytes)
-		key := r.c.Key
-		if r.c.Field != "" {
-			keyval, ok := data[r.c.Field]
-			if !ok {
-				return fmt.Errorf("field %s does not exist in data %v", r.c.Field, data)
-			}
-			key, err = cast.ToString(keyval, cast.CONVERT_ALL)
-			if err != nil {
-				return fmt.Errorf("key must be string or convertible to string, but got %v", keyval)
-			}
-		}
-		values[key] = val
-	}
-	// get action type
-	rowkind := ast.RowkindUpsert
-	if r.c.RowkindField != "" {
-		c, ok := data[r.c.RowkindField]
-		if ok {
-			rowkind, ok = c.(string)
-			if !ok {
-				return fmt.Errorf("rowkind field %s is not a string in data %v", r.c.RowkindField, data)
-			}
-			if rowkind != ast.RowkindInsert && rowkind != ast.RowkindUpdate && rowkind != ast.RowkindDelete && rowkind != ast.RowkindUpsert {
-				return fmt.Errorf("invalid rowkind %s", rowkind)
-			}
-		}
+		values[prefix+key] = sv
 	}
 	// set key value pairs
 	for key, val := range values {
 		var err error
+		start := time.Now()
 		switch rowkind {
 		case ast.RowkindInsert, ast.RowkindUpdate, ast.RowkindUpsert:
 			if r.c.DataType == "list" {
-				err = r.cli.LPush(ctx, key, val).Err()
+				err = r.pushList(ctx, cli, key, val, start)
 				if err != nil {
-					return fmt.Errorf("lpush %s:%s error, %v", key, val, err)
+					return err
 				}
 				logger.Debugf("push redis list success, key:%s data: %v", key, val)
+			} else if r.c.WriteOp == writeOpIncr {
+				err = r.incrString(ctx, cli, key, val, rowkind, start)
+				if err != nil {
+					return fmt.Errorf("incrby %s:%s error, %w", key, val, err)
+				}
 			} else {
-				err = r.cli.Set(ctx, key, val, time.Duration(r.c.Expiration)).Err()
+				err = r.setString(ctx, cli, key, val, rowkind, start)
 				if err != nil {
-					return fmt.Errorf("set %s:%s error, %v", key, val, err)
+					return fmt.Errorf("set %s:%s error, %w", key, val, err)
 				}
-				logger.Debugf("set redis string success, key:%s data: %s", key, val)
 			}
 		case ast.RowkindDelete:
 			if r.c.DataType == "list" {
-				err = r.cli.LPop(ctx, key).Err()
+				var cmd redis.Cmder
+				if r.c.ListDirection == "right" {
+					cmd = cli.RPop(ctx, key)
+				} else {
+					cmd = cli.LPop(ctx, key)
+				}
+				err = r.execDelete(ctx, cli, cmd, start)
 				if err != nil {
-					return fmt.Errorf("lpop %s error, %v", key, err)
+					return fmt.Errorf("%s %s error, %w", cmd.Name(), key, err)
 				}
 				logger.Debugf("pop redis list success, key:%s data: %v", key, val)
 			} else {
-				err = r.cli.Del(ctx, key).Err()
+				err = r.execDelete(ctx, cli, cli.Del(ctx, key), start)
 				if err != nil {
 					logger.Error(err)
 					return err
@@ -221,6 +503,540 @@ func (r *RedisSink) save(ctx api.StreamContext, data map[string]any) error {
 	return nil
 }
 
+// setString sets the string value by the write mode. A skipped conditional write is not an error unless failOnSkip is set.
+func (r *RedisSink) setString(ctx api.StreamContext, cli redis.Cmdable, key string, val string, rowkind string, start time.Time) error {
+	logger := ctx.GetLogger()
+	keepTTL := r.c.KeepTTL && rowkind != ast.RowkindInsert
+	var cmd *redis.StatusCmd
+	switch {
+	case r.c.WriteMode != writeModeAlways:
+		args := redis.SetArgs{Mode: "NX"}
+		if r.c.WriteMode == writeModeIfExists {
+			args.Mode = "XX"
+		}
+		// Align with Set that the default expiration -1 keeps the ttl
+		if keepTTL || r.c.Expiration < 0 {
+			args.KeepTTL = true
+		} else if r.c.Expiration > 0 {
+			args.TTL = time.Duration(r.c.Expiration)
+		}
+		cmd = cli.SetArgs(ctx, key, val, args)
+	case keepTTL:
+		// Keep the ttl of the existing key, only set expiration if the key has no ttl yet
+		cmd = cli.SetArgs(ctx, key, val, redis.SetArgs{KeepTTL: true})
+	default:
+		cmd = cli.Set(ctx, key, val, time.Duration(r.c.Expiration))
+	}
+	if err := execCmd(ctx, cli, cmd, start); err != nil {
+		if err = r.checkSkip(cmd); err != nil {
+			return err
+		}
+		logger.Debugf("set redis string skipped by writeMode %s, key:%s", r.c.WriteMode, key)
+		return nil
+	}
+	if keepTTL && r.c.Expiration > 0 {
+		err := execCmd(ctx, cli, cli.ExpireNX(ctx, key, time.Duration(r.c.Expiration)), time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	logger.Debugf("set redis string success, key:%s data: %s", key, val)
+	return nil
+}
+
+// incrString adds the integer value to the existing value of the key. The key which does not exist is set to 0 before
+// the increment and the expiration is applied after it, since INCRBY does not change the ttl.
+func (r *RedisSink) incrString(ctx api.StreamContext, cli redis.Cmdable, key string, val string, rowkind string, start time.Time) error {
+	n, err := toIncrement(val)
+	if err != nil {
+		return err
+	}
+	if err = execCmd(ctx, cli, cli.IncrBy(ctx, key, n), start); err != nil {
+		return err
+	}
+	if r.c.Expiration > 0 {
+		var cmd redis.Cmder
+		if r.c.KeepTTL && rowkind != ast.RowkindInsert {
+			cmd = cli.ExpireNX(ctx, key, time.Duration(r.c.Expiration))
+		} else {
+			cmd = cli.Expire(ctx, key, time.Duration(r.c.Expiration))
+		}
+		if err = execCmd(ctx, cli, cmd, time.Now()); err != nil {
+			return err
+		}
+	}
+	ctx.GetLogger().Debugf("incr redis string success, key:%s data: %s", key, val)
+	return nil
+}
+
+// checkSkip returns nil if the command is a skipped conditional write and failOnSkip is not set.
+// Otherwise, it returns the command error.
+func (r *RedisSink) checkSkip(cmd redis.Cmder) error {
+	err := cmd.Err()
+	if !errors.Is(err, redis.Nil) || r.c.WriteMode == writeModeAlways || cmd.Name() != "set" {
+		return err
+	}
+	if !r.c.FailOnSkip {
+		return nil
+	}
+	if r.c.WriteMode == writeModeIfNotExists {
+		return fmt.Errorf("write of key %s is skipped because the key already exists", cmdKey(cmd))
+	}
+	return fmt.Errorf("write of key %s is skipped because the key does not exist", cmdKey(cmd))
+}
+
+func cmdKey(cmd redis.Cmder) string {
+	key := ""
+	if args := cmd.Args(); len(args) > 1 {
+		key, _ = cast.ToString(args[1], cast.CONVERT_ALL)
+	}
+	return key
+}
+
+// pushList pushes the value to the configured end of the list and trims the list to the max length
+func (r *RedisSink) pushList(ctx api.StreamContext, cli redis.Cmdable, key string, val string, start time.Time) error {
+	var cmd redis.Cmder
+	if r.c.ListDirection == "right" {
+		cmd = cli.RPush(ctx, key, val)
+	} else {
+		cmd = cli.LPush(ctx, key, val)
+	}
+	err := execCmd(ctx, cli, cmd, start)
+	if err != nil {
+		return fmt.Errorf("%s %s:%s error, %w", cmd.Name(), key, val, err)
+	}
+	if r.c.MaxListLength > 0 {
+		// keep the newest elements which are at the pushed end
+		startIdx, stopIdx := int64(0), int64(r.c.MaxListLength-1)
+		if r.c.ListDirection == "right" {
+			startIdx, stopIdx = int64(-r.c.MaxListLength), -1
+		}
+		err = execCmd(ctx, cli, cli.LTrim(ctx, key, startIdx, stopIdx), time.Now())
+		if err != nil {
+			return fmt.Errorf("ltrim %s error, %w", key, err)
+		}
+	}
+	return nil
+}
+
+// saveHash stores the payload as redis hashes. For single key type, each column of the payload
+// is saved as a field of the hash. For multiple key type, each column is a hash key
+// whose value must be a map of the hash fields. The key is read from the original data.
+func (r *RedisSink) saveHash(ctx api.StreamContext, cli redis.Cmdable, data map[string]any, payload map[string]any, rowkind string, prefix string) error {
+	logger := ctx.GetLogger()
+	// prepare hash key and fields pairs
+	values := make(map[string]map[string]any)
+	if r.c.KeyType == "multiple" {
+		for key, val := range payload {
+			if key == r.c.RowkindField {
+				continue
+			}
+			m, ok := val.(map[string]any)
+			if !ok {
+				return fmt.Errorf("value of key %s must be a map for hash data type, but got %v", key, val)
+			}
+			values[prefix+key] = m
+		}
+	} else {
+		key, err := r.getKey(data)
+		if err != nil {
+			return err
+		}
+		// The rowkind field is the action instead of a hash field
+		if _, ok := payload[r.c.RowkindField]; ok && r.c.RowkindField != "" {
+			fields := make(map[string]any, len(payload)-1)
+			for f, v := range payload {
+				if f != r.c.RowkindField {
+					fields[f] = v
+				}
+			}
+			payload = fields
+		}
+		values[prefix+key] = payload
+	}
+	for key, fields := range values {
+		start := time.Now()
+		switch rowkind {
+		case ast.RowkindInsert, ast.RowkindUpdate, ast.RowkindUpsert:
+			if len(fields) == 0 {
+				continue
+			}
+			if r.c.WriteOp == writeOpIncr {
+				if err := r.incrHash(ctx, cli, key, fields, start); err != nil {
+					return err
+				}
+				continue
+			}
+			hv := make(map[string]any, len(fields))
+			for f, v := range fields {
+				fv, err := toHashValue(v)
+				if err != nil {
+					return fmt.Errorf("field %s of key %s cannot be converted to string, %v", f, key, err)
+				}
+				hv[f] = fv
+			}
+			err := execCmd(ctx, cli, cli.HSet(ctx, key, hv), start)
+			if err != nil {
+				return fmt.Errorf("hset %s:%v error, %w", key, hv, err)
+			}
+			logger.Debugf("set redis hash success, key:%s data: %v", key, hv)
+		case ast.RowkindDelete:
+			// For multiple key type, only delete the specified fields if any
+			if r.c.KeyType == "multiple" && len(fields) > 0 {
+				hf := make([]string, 0, len(fields))
+				for f := range fields {
+					hf = append(hf, f)
+				}
+				err := r.execDelete(ctx, cli, cli.HDel(ctx, key, hf...), start)
+				if err != nil {
+					return fmt.Errorf("hdel %s:%v error, %w", key, hf, err)
+				}
+				logger.Debugf("delete redis hash fields success, key:%s fields: %v", key, hf)
+			} else {
+				err := r.execDelete(ctx, cli, cli.Del(ctx, key), start)
+				if err != nil {
+					return fmt.Errorf("del %s error, %w", key, err)
+				}
+				logger.Debugf("delete redis hash success, key:%s", key)
+			}
+		default:
+			// never happen
+			logger.Errorf("unexpected rowkind %s", rowkind)
+		}
+	}
+	return nil
+}
+
+// incrHash adds the integer value of each field to the hash field. All the values are validated before any command is
+// sent, so a tuple with a non-numeric field does not update the hash partially.
+func (r *RedisSink) incrHash(ctx api.StreamContext, cli redis.Cmdable, key string, fields map[string]any, start time.Time) error {
+	increments := make(map[string]int64, len(fields))
+	for f, v := range fields {
+		n, err := toIncrement(v)
+		if err != nil {
+			return fmt.Errorf("field %s of key %s cannot be incremented, %v", f, key, err)
+		}
+		increments[f] = n
+	}
+	for f, n := range increments {
+		err := execCmd(ctx, cli, cli.HIncrBy(ctx, key, f, n), start)
+		if err != nil {
+			return fmt.Errorf("hincrby %s:%s:%d error, %w", key, f, n, err)
+		}
+	}
+	ctx.GetLogger().Debugf("incr redis hash success, key:%s data: %v", key, increments)
+	return nil
+}
+
+// saveStream appends the payload as an entry of the redis stream. Each column of the payload is a field of the entry.
+// The delete rowkind removes the entry whose id is read from the streamIdField, or it is ignored if the field is not set.
+func (r *RedisSink) saveStream(ctx api.StreamContext, cli redis.Cmdable, data map[string]any, payload map[string]any, rowkind string, prefix string) error {
+	logger := ctx.GetLogger()
+	key, err := r.getKey(data)
+	if err != nil {
+		return err
+	}
+	key = prefix + key
+	start := time.Now()
+	switch rowkind {
+	case ast.RowkindInsert, ast.RowkindUpdate, ast.RowkindUpsert:
+		if len(payload) == 0 {
+			return nil
+		}
+		values := make(map[string]any, len(payload))
+		for f, v := range payload {
+			fv, err := toHashValue(v)
+			if err != nil {
+				return fmt.Errorf("field %s of stream %s cannot be converted to string, %v", f, key, err)
+			}
+			values[f] = fv
+		}
+		args := &redis.XAddArgs{
+			Stream: key,
+			MaxLen: r.c.MaxLen,
+			Approx: r.c.ApproxMaxLen,
+			Values: values,
+		}
+		err = execCmd(ctx, cli, cli.XAdd(ctx, args), start)
+		if err != nil {
+			return fmt.Errorf("xadd %s:%v error, %w", key, values, err)
+		}
+		logger.Debugf("add redis stream entry success, key:%s data: %v", key, values)
+	case ast.RowkindDelete:
+		if r.c.StreamIdField == "" {
+			logger.Debugf("ignore the delete of stream %s since streamIdField is not set", key)
+			return nil
+		}
+		idv, ok := data[r.c.StreamIdField]
+		if !ok {
+			return fmt.Errorf("streamIdField %s does not exist in data %v", r.c.StreamIdField, data)
+		}
+		id, err := cast.ToString(idv, cast.CONVERT_ALL)
+		if err != nil {
+			return fmt.Errorf("stream id must be string or convertible to string, but got %v", idv)
+		}
+		err = r.execDelete(ctx, cli, cli.XDel(ctx, key, id), start)
+		if err != nil {
+			return fmt.Errorf("xdel %s:%s error, %w", key, id, err)
+		}
+		logger.Debugf("delete redis stream entry success, key:%s id: %s", key, id)
+	default:
+		// never happen
+		logger.Errorf("unexpected rowkind %s", rowkind)
+	}
+	return nil
+}
+
+// getKey returns the redis key for single key type
+func (r *RedisSink) getKey(data map[string]any) (string, error) {
+	key := r.c.Key
+	if r.c.Field != "" {
+		keyval, ok := data[r.c.Field]
+		if !ok {
+			return "", fmt.Errorf("field %s does not exist in data %v", r.c.Field, data)
+		}
+		var err error
+		key, err = cast.ToString(keyval, cast.CONVERT_ALL)
+		if err != nil {
+			return "", fmt.Errorf("key must be string or convertible to string, but got %v", keyval)
+		}
+	}
+	return key, nil
+}
+
+// getKeyPrefix returns the key prefix. If the prefix is a template, it is expanded by the data.
+func (r *RedisSink) getKeyPrefix(data map[string]any) (string, error) {
+	if r.prefixTp == nil {
+		return r.c.KeyPrefix, nil
+	}
+	var sb strings.Builder
+	err := r.prefixTp.Execute(&sb, data)
+	if err != nil {
+		return "", fmt.Errorf("fail to expand keyPrefix template %s: %v", r.c.KeyPrefix, err)
+	}
+	return sb.String(), nil
+}
+
+// transformData selects the dataField and fields of the data and renders it by the dataTemplate. It returns the map payload
+// for hash data type and multiple keyType, which are split into fields or keys. Otherwise, it returns the string value.
+func (r *RedisSink) transformData(data map[string]any) (map[string]any, string, error) {
+	var selected any = data
+	if r.c.DataField != "" {
+		v, ok := data[r.c.DataField]
+		if !ok {
+			return nil, "", fmt.Errorf("dataField %s does not exist in data %v", r.c.DataField, data)
+		}
+		selected = v
+	}
+	if len(r.c.Fields) > 0 {
+		m, ok := selected.(map[string]any)
+		if !ok {
+			return nil, "", fmt.Errorf("fields can only be selected from an object, but got %v", selected)
+		}
+		projected := make(map[string]any, len(r.c.Fields))
+		for _, f := range r.c.Fields {
+			v, ok := m[f]
+			if !ok {
+				if r.c.StrictFields {
+					return nil, "", fmt.Errorf("field %s does not exist in data %v", f, m)
+				}
+				continue
+			}
+			projected[f] = v
+		}
+		selected = projected
+	}
+	asMap := r.c.DataType == "hash" || r.c.DataType == "stream" || r.c.KeyType == "multiple"
+	if r.dataTp != nil {
+		rendered, err := r.renderData(selected)
+		if err != nil {
+			return nil, "", err
+		}
+		if !asMap {
+			return nil, rendered, nil
+		}
+		// The rendered value must be an object to be split into multiple keys or hash fields
+		payload := make(map[string]any)
+		if err = json.Unmarshal(cast.StringToBytes(rendered), &payload); err != nil {
+			return nil, "", fmt.Errorf("the result of dataTemplate %s must be a json object: %v", rendered, err)
+		}
+		return payload, "", nil
+	}
+	if asMap {
+		m, ok := selected.(map[string]any)
+		if !ok {
+			return nil, "", fmt.Errorf("dataField %s must be an object for hash data type or multiple keyType, but got %v", r.c.DataField, selected)
+		}
+		return m, "", nil
+	}
+	// Nested values are json encoded and scalars are converted to string
+	sv, err := toHashValue(selected)
+	if err != nil {
+		return nil, "", fmt.Errorf("dataField %s cannot be converted to string, %v", r.c.DataField, err)
+	}
+	return nil, sv, nil
+}
+
+// renderData renders the dataTemplate with the data
+func (r *RedisSink) renderData(data any) (string, error) {
+	var sb strings.Builder
+	err := r.dataTp.Execute(&sb, data)
+	if err != nil {
+		return "", fmt.Errorf("fail to render dataTemplate %s: %v", r.c.DataTemplate, err)
+	}
+	return sb.String(), nil
+}
+
+// redis server errors which are temporary and could succeed after retry
+var transientPrefixes = []string{"LOADING", "BUSY", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN", "READONLY"}
+
+// structuredErr categorizes the error of the collect to let the runtime decide whether to retry it. The network
+// failures are connection errors, the timeouts and the temporary server states are transient errors. The serialization
+// error of the data is kept, and any other error such as the missing key field or the command rejected by redis is a
+// validation error. All the errors of a batch are checked so that the batch is retried if any of them is retryable.
+func structuredErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	var (
+		nerr net.Error
+		rerr redis.Error
+	)
+	switch {
+	case errors.As(err, &nerr) && nerr.Timeout(), errors.Is(err, context.DeadlineExceeded):
+		return errorx.NewTransientError(err)
+	case errors.As(err, &nerr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, redis.ErrClosed):
+		return errorx.NewConnectionError(err)
+	case errors.As(err, &rerr) && isTransientReply(rerr):
+		return errorx.NewTransientError(err)
+	}
+	if _, ok := errorx.GetCategory(err); ok {
+		return err
+	}
+	return errorx.NewValidationError(err)
+}
+
+func isTransientReply(err redis.Error) bool {
+	msg := err.Error()
+	for _, p := range transientPrefixes {
+		if strings.HasPrefix(msg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// execCmd observes the command if it is executed by the client. Commands queued in a pipeline
+// are observed when the pipeline is executed.
+func execCmd(ctx api.StreamContext, cli redis.Cmdable, cmd redis.Cmder, start time.Time) error {
+	if _, ok := cli.(redis.Pipeliner); ok {
+		return cmd.Err()
+	}
+	return observeCmd(ctx, cmd, start)
+}
+
+// execDelete executes the delete command and acknowledges it. The command in a pipeline is acknowledged after the
+// pipeline is executed.
+func (r *RedisSink) execDelete(ctx api.StreamContext, cli redis.Cmdable, cmd redis.Cmder, start time.Time) error {
+	err := execCmd(ctx, cli, cmd, start)
+	if _, ok := cli.(redis.Pipeliner); ok {
+		return err
+	}
+	return r.ackDelete(ctx, cmd)
+}
+
+// ackDelete reports whether the executed delete command removes anything if deleteAck is set. It returns the error of
+// the command except that popping an absent list is not an error when deleteAck is set.
+func (r *RedisSink) ackDelete(ctx api.StreamContext, cmd redis.Cmder) error {
+	err := cmd.Err()
+	if !r.c.DeleteAck {
+		return err
+	}
+	var removed bool
+	switch c := cmd.(type) {
+	case *redis.IntCmd:
+		if n := c.Name(); n != "del" && n != "hdel" && n != "xdel" {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		removed = c.Val() > 0
+	case *redis.StringCmd:
+		if n := c.Name(); n != "lpop" && n != "rpop" {
+			return err
+		}
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		removed = err == nil
+		err = nil
+	default:
+		return err
+	}
+	status := LblDeleteNoop
+	if removed {
+		status = LblDeleteRemoved
+		ctx.GetLogger().Debugf("redis sink %s key %s removed", cmd.Name(), cmdKey(cmd))
+	} else {
+		ctx.GetLogger().Debugf("redis sink %s key %s is a no-op since nothing is removed", cmd.Name(), cmdKey(cmd))
+	}
+	RedisSinkDeleteCounter.WithLabelValues(cmd.Name(), status, ctx.GetRuleId(), ctx.GetOpId()).Inc()
+	return err
+}
+
+func (r *RedisSink) getRowkind(data map[string]any) (string, error) {
+	rowkind := ast.RowkindUpsert
+	if r.c.RowkindField != "" {
+		c, ok := data[r.c.RowkindField]
+		if ok {
+			rowkind, ok = c.(string)
+			if !ok {
+				return "", fmt.Errorf("rowkind field %s is not a string in data %v", r.c.RowkindField, data)
+			}
+			if rowkind != ast.RowkindInsert && rowkind != ast.RowkindUpdate && rowkind != ast.RowkindDelete && rowkind != ast.RowkindUpsert {
+				return "", fmt.Errorf("invalid rowkind %s", rowkind)
+			}
+		}
+	}
+	return rowkind, nil
+}
+
+// toHashValue converts the value to string to be saved as a hash field. Nested values are json encoded.
+func toHashValue(v any) (string, error) {
+	switch v.(type) {
+	case map[string]any, []any, []map[string]any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return cast.ToString(v, cast.CONVERT_ALL)
+	}
+}
+
+// toIncrement converts the value to the integer increment. Integral floats are allowed since json numbers are decoded
+// as float64, while other values such as fractions, booleans or non-numeric strings are rejected.
+func toIncrement(v any) (int64, error) {
+	if s, ok := v.(string); ok {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %s is not an integer", s)
+		}
+		return n, nil
+	}
+	n, err := cast.ToInt64(v, cast.STRICT)
+	if err != nil {
+		return 0, fmt.Errorf("value %v is not an integer", v)
+	}
+	return n, nil
+}
+
+func (r *RedisSink) Info() model.SinkInfo {
+	return model.SinkInfo{HasTransform: true}
+}
+
 func GetSink() api.Sink {
 	return &RedisSink{}
 }
@@ -228,4 +1044,5 @@ func GetSink() api.Sink {
 var (
 	_ api.TupleCollector = &RedisSink{}
 	_ util.PingableConn  = &RedisSink{}
+	_ model.SinkInfoNode = &RedisSink{}
 )
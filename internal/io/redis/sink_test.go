@@ -15,14 +15,29 @@
 package redis
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/metrics"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 func TestSink(t *testing.T) {
@@ -391,6 +406,901 @@ func TestUpdateList(t *testing.T) {
 	}
 }
 
+func TestSinkListDirection(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		n     string
+		props map[string]any
+		d     []map[string]any
+		k     string
+		v     []string
+	}{
+		{
+			n:     "left capped",
+			props: map[string]any{"maxListLength": 2},
+			d:     []map[string]any{{"id": "testListLeft", "v": 1}, {"id": "testListLeft", "v": 2}, {"id": "testListLeft", "v": 3}},
+			k:     "testListLeft",
+			v:     []string{`{"id":"testListLeft","v":3}`, `{"id":"testListLeft","v":2}`},
+		},
+		{
+			n:     "right capped",
+			props: map[string]any{"listDirection": "right", "maxListLength": 2},
+			d:     []map[string]any{{"id": "testListRight", "v": 1}, {"id": "testListRight", "v": 2}, {"id": "testListRight", "v": 3}},
+			k:     "testListRight",
+			v:     []string{`{"id":"testListRight","v":2}`, `{"id":"testListRight","v":3}`},
+		},
+		{
+			n:     "right delete",
+			props: map[string]any{"listDirection": "right"},
+			d:     []map[string]any{{"id": "testListRightDel", "v": 1}, {"id": "testListRightDel", "v": 2}, {"id": "testListRightDel", "action": "delete"}},
+			k:     "testListRightDel",
+			v:     []string{`{"id":"testListRightDel","v":1}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.n, func(t *testing.T) {
+			props := map[string]any{"addr": addr, "field": "id", "datatype": "list", "rowkindField": "action"}
+			for k, v := range tt.props {
+				props[k] = v
+			}
+			s := &RedisSink{}
+			require.NoError(t, s.Provision(ctx, props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer s.Close(ctx)
+			for _, d := range tt.d {
+				require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: d}))
+			}
+			r, err := mr.List(tt.k)
+			require.NoError(t, err)
+			assert.Equal(t, tt.v, r)
+		})
+	}
+	t.Run("invalid direction", func(t *testing.T) {
+		s := &RedisSink{}
+		err := s.Validate(map[string]any{"addr": addr, "field": "id", "datatype": "list", "listDirection": "up"})
+		assert.EqualError(t, err, "listDirection only support left or right")
+	})
+}
+
+func TestSinkHash(t *testing.T) {
+	s := &RedisSink{}
+	ctx := mockContext.NewMockContext("testSink", "op")
+	err := s.Provision(ctx, map[string]any{
+		"addr":         addr,
+		"field":        "id",
+		"datatype":     "hash",
+		"rowkindField": "action",
+	})
+	assert.NoError(t, err)
+	err = s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	})
+	assert.NoError(t, err)
+	tests := []struct {
+		n string
+		c map[string]any
+		d any
+		k string
+		v map[string]string
+	}{
+		{
+			n: "upsert single",
+			d: map[string]any{"id": "testHash1", "name": "Susan", "age": 20, "tags": []any{"a", "b"}},
+			k: "testHash1",
+			v: map[string]string{"id": "testHash1", "name": "Susan", "age": "20", "tags": `["a","b"]`},
+		},
+		{
+			n: "update single",
+			d: map[string]any{"action": "update", "id": "testHash1", "name": "John"},
+			k: "testHash1",
+			v: map[string]string{"id": "testHash1", "name": "John", "age": "20", "tags": `["a","b"]`},
+		},
+		{
+			n: "delete single",
+			d: map[string]any{"action": "delete", "id": "testHash1"},
+			k: "testHash1",
+			v: nil,
+		},
+		{
+			n: "multiple actions",
+			d: []map[string]any{
+				{"action": "insert", "id": "testHash2", "name": "Susan"},
+				{"action": "delete", "id": "testHash2"},
+				{"action": "insert", "id": "testHash2", "name": "Bob"},
+			},
+			k: "testHash2",
+			v: map[string]string{"id": "testHash2", "name": "Bob"},
+		},
+		{
+			n: "upsert multiple",
+			c: map[string]any{"keyType": "multiple"},
+			d: map[string]any{"testHash3": map[string]any{"temperature": 23.5, "humidity": 40}},
+			k: "testHash3",
+			v: map[string]string{"temperature": "23.5", "humidity": "40"},
+		},
+		{
+			n: "delete multiple fields",
+			c: map[string]any{"keyType": "multiple"},
+			d: map[string]any{"action": "delete", "testHash3": map[string]any{"humidity": nil}},
+			k: "testHash3",
+			v: map[string]string{"temperature": "23.5"},
+		},
+		{
+			n: "delete multiple key",
+			c: map[string]any{"keyType": "multiple"},
+			d: map[string]any{"action": "delete", "testHash3": map[string]any{}},
+			k: "testHash3",
+			v: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.n, func(t *testing.T) {
+			if tt.c != nil {
+				err = cast.MapToStruct(tt.c, s.c)
+				assert.NoError(t, err)
+			}
+			switch dd := tt.d.(type) {
+			case map[string]any:
+				err = s.Collect(ctx, &xsql.Tuple{
+					Message: dd,
+				})
+			case []map[string]any:
+				result := &xsql.WindowTuples{
+					Content: make([]xsql.Row, 0, len(dd)),
+				}
+				for _, m := range dd {
+					result.Content = append(result.Content, &xsql.Tuple{
+						Message: m,
+					})
+				}
+				err = s.CollectList(ctx, result)
+			}
+			assert.NoError(t, err)
+			if tt.v == nil {
+				assert.False(t, mr.Exists(tt.k))
+				return
+			}
+			fields, err := mr.HKeys(tt.k)
+			assert.NoError(t, err)
+			assert.Len(t, fields, len(tt.v))
+			for f, v := range tt.v {
+				assert.Equal(t, v, mr.HGet(tt.k, f))
+			}
+		})
+	}
+}
+
+func TestSinkHashInvalid(t *testing.T) {
+	s := &RedisSink{}
+	ctx := mockContext.NewMockContext("testSink", "op")
+	err := s.Provision(ctx, map[string]any{
+		"addr":     addr,
+		"keyType":  "multiple",
+		"datatype": "hash",
+	})
+	assert.NoError(t, err)
+	err = s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	})
+	assert.NoError(t, err)
+	err = s.Collect(ctx, &xsql.Tuple{
+		Message: map[string]any{"testHash4": 1},
+	})
+	assert.EqualError(t, err, "value of key testHash4 must be a map for hash data type, but got 1")
+}
+
+func TestSinkStream(t *testing.T) {
+	s := &RedisSink{}
+	ctx := mockContext.NewMockContext("testSink", "op")
+	err := s.Provision(ctx, map[string]any{
+		"addr":          addr,
+		"key":           "testStream",
+		"datatype":      "stream",
+		"rowkindField":  "action",
+		"streamIdField": "eid",
+		"maxLen":        2,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, s.Collect(ctx, &xsql.Tuple{
+			Message: map[string]any{"id": i, "tags": []any{"a"}},
+		}))
+	}
+	entries, err := mr.Stream("testStream")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.ElementsMatch(t, []string{"id", "2", "tags", `["a"]`}, entries[0].Values)
+	assert.ElementsMatch(t, []string{"id", "3", "tags", `["a"]`}, entries[1].Values)
+	// delete by the id field
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{
+		Message: map[string]any{"action": "delete", "eid": entries[0].ID},
+	}))
+	entries, err = mr.Stream("testStream")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.ElementsMatch(t, []string{"id", "3", "tags", `["a"]`}, entries[0].Values)
+	err = s.Collect(ctx, &xsql.Tuple{
+		Message: map[string]any{"action": "delete"},
+	})
+	assert.EqualError(t, err, "streamIdField eid does not exist in data map[action:delete]")
+	// delete is ignored without the id field
+	s.c.StreamIdField = ""
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{
+		Message: map[string]any{"action": "delete"},
+	}))
+	entries, err = mr.Stream("testStream")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestSinkStreamInvalid(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		props map[string]any
+		err   string
+	}{
+		{
+			props: map[string]any{"addr": addr, "keyType": "multiple", "datatype": "stream"},
+			err:   "stream data type only support single keyType",
+		},
+		{
+			props: map[string]any{"addr": addr, "key": "testStream", "datatype": "stream", "maxLen": -1},
+			err:   "maxLen must not be negative",
+		},
+		{
+			props: map[string]any{"addr": addr, "key": "testStream", "datatype": "set"},
+			err:   "redis sink only support string, list, hash or stream data type",
+		},
+	}
+	for _, tt := range tests {
+		s := &RedisSink{}
+		assert.EqualError(t, s.Provision(ctx, tt.props), tt.err)
+	}
+}
+
+func TestSinkPipelinePartialError(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	require.NoError(t, mr.Set("testPipeStr", "abc"))
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Message: map[string]any{"id": "testPipeStr", "name": "Susan"}},
+			&xsql.Tuple{Message: map[string]any{"id": "testPipeList", "name": "Bob"}},
+		},
+	}
+	t.Run("pipeline", func(t *testing.T) {
+		s := &RedisSink{}
+		err := s.Provision(ctx, map[string]any{
+			"addr":     addr,
+			"field":    "id",
+			"datatype": "list",
+		})
+		require.NoError(t, err)
+		require.True(t, s.c.BatchPipeline)
+		err = s.Connect(ctx, func(status string, message string) {
+			// do nothing
+		})
+		require.NoError(t, err)
+		defer s.Close(ctx)
+		err = s.CollectList(ctx, data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "redis sink failed to write keys [testPipeStr]")
+		r, err := mr.Lpop("testPipeList")
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"testPipeList","name":"Bob"}`, r)
+	})
+	t.Run("no pipeline", func(t *testing.T) {
+		s := &RedisSink{}
+		err := s.Provision(ctx, map[string]any{
+			"addr":          addr,
+			"field":         "id",
+			"datatype":      "list",
+			"batchPipeline": false,
+		})
+		require.NoError(t, err)
+		err = s.Connect(ctx, func(status string, message string) {
+			// do nothing
+		})
+		require.NoError(t, err)
+		defer s.Close(ctx)
+		err = s.CollectList(ctx, data)
+		require.NoError(t, err)
+		r, err := mr.Lpop("testPipeList")
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"testPipeList","name":"Bob"}`, r)
+	})
+}
+
+func TestSinkKeyPrefix(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		n     string
+		props map[string]any
+		d     map[string]any
+		k     string
+		v     string
+	}{
+		{
+			n:     "single",
+			props: map[string]any{"addr": addr, "field": "id", "keyPrefix": "ns:"},
+			d:     map[string]any{"id": "testPrefix1", "name": "Susan"},
+			k:     "ns:testPrefix1",
+			v:     `{"id":"testPrefix1","name":"Susan"}`,
+		},
+		{
+			n:     "multiple",
+			props: map[string]any{"addr": addr, "keyType": "multiple", "keyPrefix": "ns:"},
+			d:     map[string]any{"testPrefix2": "abc"},
+			k:     "ns:testPrefix2",
+			v:     "abc",
+		},
+		{
+			n:     "template",
+			props: map[string]any{"addr": addr, "field": "id", "keyPrefix": "{{.tenant}}:"},
+			d:     map[string]any{"id": "testPrefix3", "tenant": "t1"},
+			k:     "t1:testPrefix3",
+			v:     `{"id":"testPrefix3","tenant":"t1"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.n, func(t *testing.T) {
+			s := &RedisSink{}
+			require.NoError(t, s.Provision(ctx, tt.props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer s.Close(ctx)
+			require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: tt.d}))
+			r, err := mr.Get(tt.k)
+			require.NoError(t, err)
+			assert.Equal(t, tt.v, r)
+		})
+	}
+	t.Run("hash", func(t *testing.T) {
+		s := &RedisSink{}
+		require.NoError(t, s.Provision(ctx, map[string]any{"addr": addr, "field": "id", "datatype": "hash", "keyPrefix": "{{.tenant}}:"}))
+		require.NoError(t, s.Connect(ctx, func(status string, message string) {
+			// do nothing
+		}))
+		defer s.Close(ctx)
+		require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "testPrefix4", "tenant": "t2"}}))
+		assert.Equal(t, "testPrefix4", mr.HGet("t2:testPrefix4", "id"))
+	})
+	t.Run("invalid template", func(t *testing.T) {
+		s := &RedisSink{}
+		err := s.Validate(map[string]any{"addr": addr, "field": "id", "keyPrefix": "{{.tenant"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid keyPrefix template")
+	})
+}
+
+func TestSinkTransactional(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	s := &RedisSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":          addr,
+		"keyType":       "multiple",
+		"datatype":      "list",
+		"transactional": true,
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+	err := s.CollectList(ctx, &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Message: map[string]any{"testTxA": "a1", "testTxB": "b1"}},
+			&xsql.Tuple{Message: map[string]any{"testTxA": "a2", "testTxB": "b2"}},
+		},
+	})
+	require.NoError(t, err)
+	r, err := mr.List("testTxA")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a2", "a1"}, r)
+	r, err = mr.List("testTxB")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b2", "b1"}, r)
+	// the error of the transaction is returned with the tuple
+	require.NoError(t, mr.Set("testTxStr", "abc"))
+	err = s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"testTxStr": "c1"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "redis sink transaction failed for data map[testTxStr:c1]")
+}
+
+func TestSinkIdempotent(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	s := &RedisSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":       addr,
+		"field":      "key",
+		"datatype":   "list",
+		"idempotent": true,
+		"dedupField": "id",
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+	require.NoError(t, mr.Set("testIdemStr", "abc"))
+	data := &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Message: map[string]any{"id": 1, "key": "testIdemList"}},
+			&xsql.Tuple{Message: map[string]any{"id": 2, "key": "testIdemStr"}},
+			&xsql.Tuple{Message: map[string]any{"id": 3, "key": "testIdemList"}},
+		},
+	}
+	// partial delivery, the second tuple fails on the wrong type
+	err := s.CollectList(ctx, data)
+	require.Error(t, err)
+	r, err := mr.List("testIdemList")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"id":1,"key":"testIdemList"}`}, r)
+	assert.False(t, mr.Exists("dedup:2"))
+	assert.Equal(t, 24*time.Hour, mr.TTL("dedup:1"))
+	// retry the whole list, the written tuple is skipped
+	mr.Del("testIdemStr")
+	require.NoError(t, s.CollectList(ctx, data))
+	r, err = mr.List("testIdemList")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"id":3,"key":"testIdemList"}`, `{"id":1,"key":"testIdemList"}`}, r)
+	r, err = mr.List("testIdemStr")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"id":2,"key":"testIdemStr"}`}, r)
+
+	t.Run("hash of tuple", func(t *testing.T) {
+		s := &RedisSink{}
+		require.NoError(t, s.Provision(ctx, map[string]any{
+			"addr":           addr,
+			"key":            "testIdemHash",
+			"datatype":       "list",
+			"idempotent":     true,
+			"dedupKeyPrefix": "ns:dedup:",
+			"dedupTTL":       "0s",
+		}))
+		require.NoError(t, s.Connect(ctx, func(status string, message string) {
+			// do nothing
+		}))
+		defer s.Close(ctx)
+		tuple := &xsql.Tuple{Message: map[string]any{"a": 1, "b": "x"}}
+		require.NoError(t, s.Collect(ctx, tuple))
+		require.NoError(t, s.Collect(ctx, tuple))
+		require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"a": 2, "b": "x"}}))
+		r, err := mr.List("testIdemHash")
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"a":2,"b":"x"}`, `{"a":1,"b":"x"}`}, r)
+		keys := mr.Keys()
+		var dedupKeys []string
+		for _, k := range keys {
+			if strings.HasPrefix(k, "ns:dedup:") {
+				dedupKeys = append(dedupKeys, k)
+				assert.Equal(t, time.Duration(0), mr.TTL(k))
+			}
+		}
+		assert.Len(t, dedupKeys, 2)
+	})
+
+	t.Run("missing dedup field", func(t *testing.T) {
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"key": "testIdemList"}})
+		assert.EqualError(t, err, "dedup field id does not exist in data map[key:testIdemList]")
+	})
+}
+
+func TestSinkWriteMode(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		name       string
+		writeMode  string
+		failOnSkip bool
+		err        string
+		exp        map[string]string
+	}{
+		{
+			name:      "always",
+			writeMode: "always",
+			exp:       map[string]string{"wmExist": `{"id":"wmExist","name":"new"}`, "wmNew": `{"id":"wmNew","name":"new"}`},
+		},
+		{
+			name:      "if not exists",
+			writeMode: "ifNotExists",
+			exp:       map[string]string{"wmExist": `old`, "wmNew": `{"id":"wmNew","name":"new"}`},
+		},
+		{
+			name:      "if exists",
+			writeMode: "ifExists",
+			exp:       map[string]string{"wmExist": `{"id":"wmExist","name":"new"}`},
+		},
+		{
+			name:       "if not exists fail on skip",
+			writeMode:  "ifNotExists",
+			failOnSkip: true,
+			err:        "write of key wmExist is skipped because the key already exists",
+			exp:        map[string]string{"wmExist": `old`, "wmNew": `{"id":"wmNew","name":"new"}`},
+		},
+		{
+			name:       "if exists fail on skip",
+			writeMode:  "ifExists",
+			failOnSkip: true,
+			err:        "write of key wmNew is skipped because the key does not exist",
+			exp:        map[string]string{"wmExist": `{"id":"wmExist","name":"new"}`},
+		},
+	}
+	for _, tt := range tests {
+		for _, pipeline := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s pipeline %v", tt.name, pipeline), func(t *testing.T) {
+				require.NoError(t, mr.Set("wmExist", "old"))
+				defer func() {
+					mr.Del("wmExist")
+					mr.Del("wmNew")
+				}()
+				s := &RedisSink{}
+				err := s.Provision(ctx, map[string]any{
+					"addr":          addr,
+					"field":         "id",
+					"writeMode":     tt.writeMode,
+					"failOnSkip":    tt.failOnSkip,
+					"batchPipeline": pipeline,
+				})
+				require.NoError(t, err)
+				require.NoError(t, s.Connect(ctx, func(status string, message string) {
+					// do nothing
+				}))
+				defer s.Close(ctx)
+				if pipeline {
+					err = s.CollectList(ctx, &xsql.WindowTuples{Content: []xsql.Row{
+						&xsql.Tuple{Message: map[string]any{"id": "wmExist", "name": "new"}},
+						&xsql.Tuple{Message: map[string]any{"id": "wmNew", "name": "new"}},
+					}})
+				} else {
+					err = errors.Join(
+						s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "wmExist", "name": "new"}}),
+						s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "wmNew", "name": "new"}}),
+					)
+				}
+				if tt.err != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), tt.err)
+				} else {
+					require.NoError(t, err)
+				}
+				for _, k := range []string{"wmExist", "wmNew"} {
+					v, err := mr.Get(k)
+					if exp, ok := tt.exp[k]; ok {
+						require.NoError(t, err)
+						assert.Equal(t, exp, v)
+					} else {
+						assert.Error(t, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSinkIncr(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	for _, pipeline := range []bool{false, true} {
+		t.Run(fmt.Sprintf("string pipeline %v", pipeline), func(t *testing.T) {
+			require.NoError(t, mr.Set("incrExist", "10"))
+			defer func() {
+				mr.Del("incrExist")
+				mr.Del("incrNew")
+			}()
+			s := &RedisSink{}
+			require.NoError(t, s.Provision(ctx, map[string]any{
+				"addr":          addr,
+				"field":         "id",
+				"dataField":     "count",
+				"writeOp":       "incr",
+				"batchPipeline": pipeline,
+			}))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer s.Close(ctx)
+			tuples := []xsql.Row{
+				&xsql.Tuple{Message: map[string]any{"id": "incrExist", "count": 5}},
+				&xsql.Tuple{Message: map[string]any{"id": "incrNew", "count": float64(3)}},
+				&xsql.Tuple{Message: map[string]any{"id": "incrExist", "count": -2}},
+				&xsql.Tuple{Message: map[string]any{"id": "incrNew", "count": "abc"}},
+				&xsql.Tuple{Message: map[string]any{"id": "incrNew", "count": 1.5}},
+			}
+			var err error
+			if pipeline {
+				err = s.CollectList(ctx, &xsql.WindowTuples{Content: tuples})
+			} else {
+				errs := make([]error, 0, len(tuples))
+				for _, tuple := range tuples {
+					errs = append(errs, s.Collect(ctx, tuple.(*xsql.Tuple)))
+				}
+				err = errors.Join(errs...)
+			}
+			// the invalid tuples fail while the others are still written
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "value abc is not an integer")
+			assert.Contains(t, err.Error(), "value 1.5 is not an integer")
+			v, err := mr.Get("incrExist")
+			require.NoError(t, err)
+			assert.Equal(t, "13", v)
+			v, err = mr.Get("incrNew")
+			require.NoError(t, err)
+			assert.Equal(t, "3", v)
+		})
+	}
+	t.Run("string expiration", func(t *testing.T) {
+		defer mr.Del("incrTTL")
+		s := &RedisSink{}
+		require.NoError(t, s.Provision(ctx, map[string]any{
+			"addr":       addr,
+			"key":        "incrTTL",
+			"dataField":  "count",
+			"writeOp":    "incr",
+			"expiration": "10s",
+		}))
+		require.NoError(t, s.Connect(ctx, func(status string, message string) {
+			// do nothing
+		}))
+		defer s.Close(ctx)
+		require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"count": 2}}))
+		assert.Equal(t, 10*time.Second, mr.TTL("incrTTL"))
+	})
+	t.Run("hash", func(t *testing.T) {
+		mr.HSet("incrHash", "pv", "100")
+		defer mr.Del("incrHash")
+		s := &RedisSink{}
+		require.NoError(t, s.Provision(ctx, map[string]any{
+			"addr":     addr,
+			"field":    "id",
+			"dataType": "hash",
+			"fields":   []any{"pv", "uv"},
+			"writeOp":  "incr",
+		}))
+		require.NoError(t, s.Connect(ctx, func(status string, message string) {
+			// do nothing
+		}))
+		defer s.Close(ctx)
+		require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "incrHash", "pv": 5, "uv": 1}}))
+		require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "incrHash", "pv": 2, "uv": 1}}))
+		// the invalid tuple does not update the hash partially
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "incrHash", "pv": 1, "uv": true}})
+		assert.EqualError(t, err, "field uv of key incrHash cannot be incremented, value true is not an integer")
+		assert.Equal(t, "107", mr.HGet("incrHash", "pv"))
+		assert.Equal(t, "2", mr.HGet("incrHash", "uv"))
+	})
+}
+
+func TestSinkDataTemplate(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		name  string
+		props map[string]any
+		data  map[string]any
+		key   string
+		exp   any
+		err   string
+	}{
+		{
+			name:  "single string",
+			props: map[string]any{"field": "id", "dataTemplate": "{{.name}}:{{.age}}"},
+			data:  map[string]any{"id": "dtStr", "name": "Susan", "age": 10},
+			key:   "dtStr",
+			exp:   "Susan:10",
+		},
+		{
+			name:  "single list",
+			props: map[string]any{"field": "id", "dataType": "list", "dataTemplate": "{{.name}}"},
+			data:  map[string]any{"id": "dtList", "name": "Susan"},
+			key:   "dtList",
+			exp:   []string{"Susan"},
+		},
+		{
+			name:  "multiple string",
+			props: map[string]any{"keyType": "multiple", "dataTemplate": `{"dtMul":"{{.name}}"}`},
+			data:  map[string]any{"id": "dtMul", "name": "Susan"},
+			key:   "dtMul",
+			exp:   "Susan",
+		},
+		{
+			name:  "single hash",
+			props: map[string]any{"field": "id", "dataType": "hash", "dataTemplate": `{"n":"{{.name}}"}`},
+			data:  map[string]any{"id": "dtHash", "name": "Susan"},
+			key:   "dtHash",
+			exp:   map[string]string{"n": "Susan"},
+		},
+		{
+			name:  "hash not object",
+			props: map[string]any{"field": "id", "dataType": "hash", "dataTemplate": `{{.name}}`},
+			data:  map[string]any{"id": "dtHashErr", "name": "Susan"},
+			err:   "the result of dataTemplate Susan must be a json object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RedisSink{}
+			tt.props["addr"] = addr
+			require.NoError(t, s.Provision(ctx, tt.props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer s.Close(ctx)
+			err := s.Collect(ctx, &xsql.Tuple{Message: tt.data})
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			defer mr.Del(tt.key)
+			switch exp := tt.exp.(type) {
+			case string:
+				v, err := mr.Get(tt.key)
+				require.NoError(t, err)
+				assert.Equal(t, exp, v)
+			case []string:
+				v, err := mr.List(tt.key)
+				require.NoError(t, err)
+				assert.Equal(t, exp, v)
+			case map[string]string:
+				assert.Equal(t, exp["n"], mr.HGet(tt.key, "n"))
+			}
+		})
+	}
+	s := &RedisSink{}
+	assert.ErrorContains(t, s.Provision(ctx, map[string]any{"addr": addr, "key": "k", "dataTemplate": "{{.name"}), "invalid dataTemplate {{.name")
+	assert.Equal(t, model.SinkInfo{HasTransform: true}, s.Info())
+}
+
+func TestSinkDataField(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		name  string
+		props map[string]any
+		data  map[string]any
+		key   string
+		exp   any
+		err   string
+	}{
+		{
+			name:  "object",
+			props: map[string]any{"field": "id", "dataField": "payload"},
+			data:  map[string]any{"id": "dfObj", "payload": map[string]any{"temperature": 23.5}},
+			key:   "dfObj",
+			exp:   `{"temperature":23.5}`,
+		},
+		{
+			name:  "array",
+			props: map[string]any{"field": "id", "dataField": "payload"},
+			data:  map[string]any{"id": "dfArr", "payload": []any{1, 2}},
+			key:   "dfArr",
+			exp:   `[1,2]`,
+		},
+		{
+			name:  "scalar",
+			props: map[string]any{"field": "id", "dataField": "payload"},
+			data:  map[string]any{"id": "dfScalar", "payload": 23.5},
+			key:   "dfScalar",
+			exp:   `23.5`,
+		},
+		{
+			name:  "with template",
+			props: map[string]any{"field": "id", "dataField": "payload", "dataTemplate": "t={{.temperature}}"},
+			data:  map[string]any{"id": "dfTp", "payload": map[string]any{"temperature": 23.5}},
+			key:   "dfTp",
+			exp:   `t=23.5`,
+		},
+		{
+			name:  "hash",
+			props: map[string]any{"field": "id", "dataField": "payload", "dataType": "hash"},
+			data:  map[string]any{"id": "dfHash", "payload": map[string]any{"n": "Susan"}},
+			key:   "dfHash",
+			exp:   map[string]string{"n": "Susan"},
+		},
+		{
+			name:  "hash scalar",
+			props: map[string]any{"field": "id", "dataField": "payload", "dataType": "hash"},
+			data:  map[string]any{"id": "dfHashErr", "payload": 1},
+			err:   "dataField payload must be an object for hash data type or multiple keyType, but got 1",
+		},
+		{
+			name:  "missing",
+			props: map[string]any{"field": "id", "dataField": "payload"},
+			data:  map[string]any{"id": "dfMissing"},
+			err:   "dataField payload does not exist in data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RedisSink{}
+			tt.props["addr"] = addr
+			require.NoError(t, s.Provision(ctx, tt.props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer s.Close(ctx)
+			err := s.Collect(ctx, &xsql.Tuple{Message: tt.data})
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			defer mr.Del(tt.key)
+			switch exp := tt.exp.(type) {
+			case string:
+				v, err := mr.Get(tt.key)
+				require.NoError(t, err)
+				assert.Equal(t, exp, v)
+			case map[string]string:
+				assert.Equal(t, exp["n"], mr.HGet(tt.key, "n"))
+			}
+		})
+	}
+}
+
+func TestSinkFields(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		name  string
+		props map[string]any
+		data  map[string]any
+		exp   map[string]string
+		err   string
+	}{
+		{
+			name:  "single",
+			props: map[string]any{"field": "id", "fields": []string{"name", "notexist"}},
+			data:  map[string]any{"id": "fdSingle", "name": "Susan", "internal": 1},
+			exp:   map[string]string{"fdSingle": `{"name":"Susan"}`},
+		},
+		{
+			name:  "multiple",
+			props: map[string]any{"keyType": "multiple", "fields": []string{"fdMul1"}},
+			data:  map[string]any{"fdMul1": "a", "fdMul2": "b"},
+			exp:   map[string]string{"fdMul1": "a"},
+		},
+		{
+			name:  "with data field",
+			props: map[string]any{"field": "id", "dataField": "payload", "fields": []string{"t"}},
+			data:  map[string]any{"id": "fdDataField", "payload": map[string]any{"t": 1, "h": 2}},
+			exp:   map[string]string{"fdDataField": `{"t":1}`},
+		},
+		{
+			name:  "strict",
+			props: map[string]any{"field": "id", "fields": []string{"name", "notexist"}, "strictFields": true},
+			data:  map[string]any{"id": "fdStrict", "name": "Susan"},
+			err:   "field notexist does not exist in data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RedisSink{}
+			tt.props["addr"] = addr
+			require.NoError(t, s.Provision(ctx, tt.props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer s.Close(ctx)
+			err := s.Collect(ctx, &xsql.Tuple{Message: tt.data})
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			for k, exp := range tt.exp {
+				v, err := mr.Get(k)
+				require.NoError(t, err)
+				assert.Equal(t, exp, v)
+				mr.Del(k)
+			}
+			assert.False(t, mr.Exists("fdMul2"))
+		})
+	}
+}
+
 func TestRedisSink_Configure(t *testing.T) {
 	type args struct {
 		props map[string]any
@@ -435,6 +1345,161 @@ func TestRedisSink_Configure(t *testing.T) {
 			}},
 			wantErr: true,
 		},
+		{
+			name: "mode do not support",
+			args: args{map[string]any{
+				"addr": addr,
+				"key":  "test",
+				"mode": "ttt",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "cluster mode",
+			args: args{map[string]any{
+				"mode":  "cluster",
+				"addrs": "127.0.0.1:7000,127.0.0.1:7001",
+				"key":   "test",
+			}},
+			wantErr: false,
+		},
+		{
+			name: "cluster mode missing addrs",
+			args: args{map[string]any{
+				"mode": "cluster",
+				"addr": addr,
+				"key":  "test",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "cluster mode with db",
+			args: args{map[string]any{
+				"mode":  "cluster",
+				"addrs": "127.0.0.1:7000",
+				"db":    1,
+				"key":   "test",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "sentinel mode",
+			args: args{map[string]any{
+				"mode":       "sentinel",
+				"addrs":      "127.0.0.1:26379, 127.0.0.1:26380",
+				"masterName": "mymaster",
+				"key":        "test",
+			}},
+			wantErr: false,
+		},
+		{
+			name: "sentinel mode missing master name",
+			args: args{map[string]any{
+				"mode":  "sentinel",
+				"addrs": "127.0.0.1:26379",
+				"key":   "test",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid write mode",
+			args: args{map[string]any{
+				"addr":      addr,
+				"key":       "test",
+				"writeMode": "ifAbsent",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "negative dedup ttl",
+			args: args{map[string]any{
+				"addr":       addr,
+				"key":        "test",
+				"idempotent": true,
+				"dedupTTL":   "-1s",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "write mode with list data type",
+			args: args{map[string]any{
+				"addr":      addr,
+				"key":       "test",
+				"dataType":  "list",
+				"writeMode": "ifExists",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid write op",
+			args: args{map[string]any{
+				"addr":    addr,
+				"key":     "test",
+				"writeOp": "append",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "incr with list data type",
+			args: args{map[string]any{
+				"addr":     addr,
+				"key":      "test",
+				"dataType": "list",
+				"writeOp":  "incr",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid protocol",
+			args: args{map[string]any{
+				"addr":     addr,
+				"key":      "test",
+				"protocol": 4,
+			}},
+			wantErr: true,
+		},
+		{
+			name: "min idle conns larger than pool size",
+			args: args{map[string]any{
+				"addr":         addr,
+				"key":          "test",
+				"poolSize":     2,
+				"minIdleConns": 3,
+			}},
+			wantErr: true,
+		},
+		{
+			name: "zero read timeout",
+			args: args{map[string]any{
+				"addr":        addr,
+				"key":         "test",
+				"readTimeout": "0s",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "resp2 with pool",
+			args: args{map[string]any{
+				"addr":         addr,
+				"key":          "test",
+				"protocol":     2,
+				"poolSize":     20,
+				"minIdleConns": 5,
+				"readTimeout":  "1s",
+				"writeTimeout": "2s",
+			}},
+			wantErr: false,
+		},
+		{
+			name: "incr with conditional write mode",
+			args: args{map[string]any{
+				"addr":      addr,
+				"key":       "test",
+				"writeOp":   "incr",
+				"writeMode": "ifNotExists",
+			}},
+			wantErr: true,
+		},
 	}
 	ctx := mockContext.NewMockContext("TestConfigure", "op")
 	for _, tt := range tests {
@@ -455,3 +1520,459 @@ func TestRedisSink(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, "redisSink db should be in range 0-15", err.Error())
 }
+
+func TestSinkTLS(t *testing.T) {
+	tests := []struct {
+		name     string
+		props    map[string]any
+		err      string
+		tls      bool
+		insecure bool
+	}{
+		{
+			name:  "no tls",
+			props: map[string]any{"addr": addr, "key": "test"},
+		},
+		{
+			name:  "tls enabled",
+			props: map[string]any{"addr": addr, "key": "test", "tlsEnabled": true},
+			tls:   true,
+		},
+		{
+			name:     "insecure skip verify",
+			props:    map[string]any{"addr": addr, "key": "test", "insecureSkipVerify": true},
+			tls:      true,
+			insecure: true,
+		},
+		{
+			name:  "cert without key",
+			props: map[string]any{"addr": addr, "key": "test", "certificationPath": "/tmp/cert.pem"},
+			err:   "private key is required when redis certification is set",
+		},
+		{
+			name:  "cert file not found",
+			props: map[string]any{"addr": addr, "key": "test", "certificationPath": "/tmp/notexist.pem", "privateKeyPath": "/tmp/notexist.key"},
+			err:   "error configuring tls",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RedisSink{}
+			err := s.Validate(tt.props)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			if !tt.tls {
+				assert.Nil(t, s.tlsConf)
+				return
+			}
+			require.NotNil(t, s.tlsConf)
+			assert.Equal(t, tt.insecure, s.tlsConf.InsecureSkipVerify)
+		})
+	}
+}
+
+func TestSinkMode(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		check func(t *testing.T, cli redis.UniversalClient)
+	}{
+		{
+			name:  "single",
+			props: map[string]any{"addr": addr, "key": "test"},
+			check: func(t *testing.T, cli redis.UniversalClient) {
+				c, ok := cli.(*redis.Client)
+				require.True(t, ok)
+				assert.Equal(t, addr, c.Options().Addr)
+			},
+		},
+		{
+			name:  "cluster",
+			props: map[string]any{"mode": "cluster", "addrs": "127.0.0.1:7000, 127.0.0.1:7001", "key": "test"},
+			check: func(t *testing.T, cli redis.UniversalClient) {
+				c, ok := cli.(*redis.ClusterClient)
+				require.True(t, ok)
+				assert.Equal(t, []string{"127.0.0.1:7000", "127.0.0.1:7001"}, c.Options().Addrs)
+			},
+		},
+		{
+			name:  "sentinel",
+			props: map[string]any{"mode": "sentinel", "addrs": "127.0.0.1:26379", "masterName": "mymaster", "key": "test"},
+			check: func(t *testing.T, cli redis.UniversalClient) {
+				_, ok := cli.(*redis.Client)
+				require.True(t, ok)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RedisSink{}
+			require.NoError(t, s.Validate(tt.props))
+			cli := newClient(s.cc, s.tlsConf)
+			defer cli.Close()
+			tt.check(t, cli)
+		})
+	}
+}
+
+func TestSinkKeepTTL(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	tests := []struct {
+		name    string
+		keepTTL bool
+		ttl     time.Duration
+	}{
+		{
+			name:    "keep ttl",
+			keepTTL: true,
+			ttl:     6 * time.Second,
+		},
+		{
+			name:    "reset ttl",
+			keepTTL: false,
+			ttl:     10 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &RedisSink{}
+			err := s.Provision(ctx, map[string]any{
+				"addr":         addr,
+				"field":        "id",
+				"rowkindField": "action",
+				"expiration":   "10s",
+				"keepTTL":      tt.keepTTL,
+			})
+			require.NoError(t, err)
+			err = s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			})
+			require.NoError(t, err)
+			defer s.Close(ctx)
+			// insert always sets the expiration
+			err = s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"action": "insert", "id": "testTTL", "name": "Susan"}})
+			require.NoError(t, err)
+			assert.Equal(t, 10*time.Second, mr.TTL("testTTL"))
+			mr.FastForward(4 * time.Second)
+			err = s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"action": "update", "id": "testTTL", "name": "John"}})
+			require.NoError(t, err)
+			assert.Equal(t, tt.ttl, mr.TTL("testTTL"))
+			r, err := mr.Get("testTTL")
+			require.NoError(t, err)
+			assert.Equal(t, `{"action":"update","id":"testTTL","name":"John"}`, r)
+			// upsert a new key still sets the expiration
+			err = s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "testTTLNew", "name": "Bob"}})
+			require.NoError(t, err)
+			assert.Equal(t, 10*time.Second, mr.TTL("testTTLNew"))
+			mr.Del("testTTL")
+			mr.Del("testTTLNew")
+		})
+	}
+}
+
+func TestSinkSharedConnection(t *testing.T) {
+	ctx := mockContext.NewMockContext("testShared", "op")
+	props := map[string]any{"addr": addr, "key": "testShared"}
+	s1 := &RedisSink{}
+	require.NoError(t, s1.Provision(ctx, props))
+	require.NoError(t, s1.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	s2 := &RedisSink{}
+	require.NoError(t, s2.Provision(ctx, props))
+	require.NoError(t, s2.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	s3 := &RedisSink{}
+	require.NoError(t, s3.Provision(ctx, map[string]any{"addr": addr, "key": "testShared", "db": 1}))
+	require.NoError(t, s3.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	// same configuration shares the client
+	assert.True(t, s1.conn == s2.conn)
+	assert.False(t, s1.conn == s3.conn)
+	// the client is still available for other users after closing
+	require.NoError(t, s1.Close(ctx))
+	require.NoError(t, s2.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 1}}))
+	r, err := mr.Get("testShared")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1}`, r)
+	require.NoError(t, s2.Close(ctx))
+	require.NoError(t, s3.Close(ctx))
+}
+
+func TestSinkMetrics(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSinkMetrics", "op")
+	require.NoError(t, mr.Set("testMetricsStr", "abc"))
+	s := &RedisSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":         addr,
+		"field":        "id",
+		"datatype":     "list",
+		"rowkindField": "action",
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "testMetricsList"}}))
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "testMetricsList", "action": "delete"}}))
+	// pipeline with one failed command
+	err := s.CollectList(ctx, &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Message: map[string]any{"id": "testMetricsStr"}},
+			&xsql.Tuple{Message: map[string]any{"id": "testMetricsList"}},
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(RedisSinkCounter.WithLabelValues("lpush", metrics.LblSuccess, "testSinkMetrics", "op")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(RedisSinkCounter.WithLabelValues("lpush", metrics.LblException, "testSinkMetrics", "op")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(RedisSinkCounter.WithLabelValues("lpop", metrics.LblSuccess, "testSinkMetrics", "op")))
+	m := &dto.Metric{}
+	require.NoError(t, RedisSinkCommandDurationHist.WithLabelValues("lpush", "testSinkMetrics", "op").(prometheus.Metric).Write(m))
+	assert.Equal(t, uint64(3), m.GetHistogram().GetSampleCount())
+}
+
+func TestSinkDeleteAck(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSinkDeleteAck", "op")
+	require.NoError(t, mr.Set("ackStr", "abc"))
+	s := &RedisSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":         addr,
+		"field":        "id",
+		"rowkindField": "action",
+		"deleteAck":    true,
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "ackStr", "action": "delete"}}))
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "ackStr", "action": "delete"}}))
+	assert.Equal(t, float64(1), testutil.ToFloat64(RedisSinkDeleteCounter.WithLabelValues("del", LblDeleteRemoved, "testSinkDeleteAck", "op")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(RedisSinkDeleteCounter.WithLabelValues("del", LblDeleteNoop, "testSinkDeleteAck", "op")))
+
+	// Popping the absent list is a no-op instead of an error
+	ctx = mockContext.NewMockContext("testSinkDeleteAckList", "op")
+	l := &RedisSink{}
+	require.NoError(t, l.Provision(ctx, map[string]any{
+		"addr":         addr,
+		"field":        "id",
+		"datatype":     "list",
+		"rowkindField": "action",
+		"deleteAck":    true,
+	}))
+	require.NoError(t, l.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer l.Close(ctx)
+	require.NoError(t, l.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "ackList", "action": "delete"}}))
+	// acknowledged after the pipeline is executed
+	require.NoError(t, l.CollectList(ctx, &xsql.WindowTuples{
+		Content: []xsql.Row{
+			&xsql.Tuple{Message: map[string]any{"id": "ackList"}},
+			&xsql.Tuple{Message: map[string]any{"id": "ackList", "action": "delete"}},
+			&xsql.Tuple{Message: map[string]any{"id": "ackList", "action": "delete"}},
+		},
+	}))
+	assert.Equal(t, float64(1), testutil.ToFloat64(RedisSinkDeleteCounter.WithLabelValues("lpop", LblDeleteRemoved, "testSinkDeleteAckList", "op")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(RedisSinkDeleteCounter.WithLabelValues("lpop", LblDeleteNoop, "testSinkDeleteAckList", "op")))
+
+	// Without deleteAck, popping the absent list is still an error
+	ctx = mockContext.NewMockContext("testSinkNoDeleteAck", "op")
+	n := &RedisSink{}
+	require.NoError(t, n.Provision(ctx, map[string]any{
+		"addr":         addr,
+		"field":        "id",
+		"datatype":     "list",
+		"rowkindField": "action",
+	}))
+	require.NoError(t, n.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer n.Close(ctx)
+	require.Error(t, n.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "ackList", "action": "delete"}}))
+	assert.Equal(t, float64(0), testutil.ToFloat64(RedisSinkDeleteCounter.WithLabelValues("lpop", LblDeleteNoop, "testSinkNoDeleteAck", "op")))
+}
+
+func TestSinkStructuredError(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	require.NoError(t, mr.Set("errString", "abc"))
+	defer mr.Del("errString")
+	s := &RedisSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":      addr,
+		"field":     "id",
+		"dataField": "count",
+		"writeOp":   "incr",
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer s.Close(ctx)
+	tests := []struct {
+		name     string
+		data     map[string]any
+		category errorx.Category
+	}{
+		{
+			name:     "missing key field",
+			data:     map[string]any{"count": 1},
+			category: errorx.CategoryValidation,
+		},
+		{
+			name:     "missing data field",
+			data:     map[string]any{"id": "errString"},
+			category: errorx.CategorySerialization,
+		},
+		{
+			name:     "rejected by redis",
+			data:     map[string]any{"id": "errString", "count": 1},
+			category: errorx.CategoryValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Collect(ctx, &xsql.Tuple{Message: tt.data})
+			require.Error(t, err)
+			c, ok := errorx.GetCategory(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.category, c)
+			assert.False(t, errorx.IsRetryable(err))
+		})
+	}
+	t.Run("connection", func(t *testing.T) {
+		down, err := miniredis.Run()
+		require.NoError(t, err)
+		ds := &RedisSink{}
+		require.NoError(t, ds.Provision(ctx, map[string]any{
+			"addr": down.Addr(),
+			"key":  "test",
+		}))
+		require.NoError(t, ds.Connect(ctx, func(status string, message string) {
+			// do nothing
+		}))
+		defer ds.Close(ctx)
+		down.Close()
+		err = ds.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 1}})
+		require.Error(t, err)
+		c, ok := errorx.GetCategory(err)
+		require.True(t, ok)
+		assert.Equal(t, errorx.CategoryConnection, c)
+		assert.True(t, errorx.IsRetryable(err))
+		assert.True(t, errorx.IsIOError(err))
+	})
+	t.Run("transient reply", func(t *testing.T) {
+		mr.SetError("LOADING Redis is loading the dataset in memory")
+		defer mr.SetError("")
+		err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": "errNew", "count": 1}})
+		require.Error(t, err)
+		c, _ := errorx.GetCategory(err)
+		assert.Equal(t, errorx.CategoryTransient, c)
+		assert.True(t, errorx.IsRetryable(err))
+	})
+}
+
+func TestSinkReconnect(t *testing.T) {
+	ctx := mockContext.NewMockContext("testReconnect", "op")
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+	var (
+		mu       sync.Mutex
+		statuses []string
+	)
+	lastStatus := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(statuses) == 0 {
+			return ""
+		}
+		return statuses[len(statuses)-1]
+	}
+	s := &RedisSink{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":                server.Addr(),
+		"key":                 "testReconnect",
+		"healthCheckInterval": "50ms",
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, status)
+	}))
+	defer s.Close(ctx)
+	assert.Equal(t, api.ConnectionConnected, lastStatus())
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 1}}))
+	// the health check finds the connection dropped
+	server.Close()
+	assert.Eventually(t, func() bool {
+		timex.Add(50 * time.Millisecond)
+		return lastStatus() == api.ConnectionDisconnected
+	}, 2*time.Second, 20*time.Millisecond)
+	err = s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 2}})
+	require.Error(t, err)
+	assert.True(t, errorx.IsRetryable(err))
+	assert.Contains(t, err.Error(), "is disconnected")
+	// the client reconnects after the server is back
+	require.NoError(t, server.Restart())
+	assert.Eventually(t, func() bool {
+		timex.Add(50 * time.Millisecond)
+		return lastStatus() == api.ConnectionConnected
+	}, 2*time.Second, 20*time.Millisecond)
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 3}}))
+	r, err := server.Get("testReconnect")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":3}`, r)
+	// transitions are notified only once
+	mu.Lock()
+	assert.Equal(t, []string{api.ConnectionConnected, api.ConnectionDisconnected, api.ConnectionConnected}, statuses[len(statuses)-3:])
+	mu.Unlock()
+}
+
+func TestConnectionRedial(t *testing.T) {
+	ctx := mockContext.NewMockContext("testRedial", "op")
+	conn := &Connection{}
+	require.NoError(t, conn.Provision(ctx, "testRedial", map[string]any{"addr": addr, "healthCheckInterval": "0s"}))
+	require.NoError(t, conn.Dial(ctx))
+	old := conn.Client()
+	require.NoError(t, conn.Dial(ctx))
+	defer conn.Close(ctx)
+	// the previous client is closed once it is replaced
+	assert.ErrorIs(t, old.Ping(ctx).Err(), redis.ErrClosed)
+	assert.NoError(t, conn.Client().Ping(ctx).Err())
+}
+
+func TestSinkPoolOptions(t *testing.T) {
+	c, tlsConf, err := validateConnConf(map[string]any{"addr": addr})
+	require.NoError(t, err)
+	opts := newClient(c, tlsConf).(*redis.Client).Options()
+	require.Equal(t, 3, opts.Protocol)
+	require.Equal(t, 3*time.Second, opts.ReadTimeout)
+	require.Equal(t, 3*time.Second, opts.WriteTimeout)
+
+	c, tlsConf, err = validateConnConf(map[string]any{
+		"addr":         addr,
+		"protocol":     2,
+		"poolSize":     20,
+		"minIdleConns": 5,
+		"readTimeout":  "1s",
+		"writeTimeout": "2s",
+	})
+	require.NoError(t, err)
+	opts = newClient(c, tlsConf).(*redis.Client).Options()
+	require.Equal(t, 2, opts.Protocol)
+	require.Equal(t, 20, opts.PoolSize)
+	require.Equal(t, 5, opts.MinIdleConns)
+	require.Equal(t, time.Second, opts.ReadTimeout)
+	require.Equal(t, 2*time.Second, opts.WriteTimeout)
+
+	_, _, err = validateConnConf(map[string]any{"addr": addr, "protocol": 1})
+	require.EqualError(t, err, "redis protocol only support 2 or 3, but got 1")
+	_, _, err = validateConnConf(map[string]any{"addr": addr, "poolSize": 1, "minIdleConns": 2})
+	require.EqualError(t, err, "minIdleConns 2 must not be larger than poolSize 1")
+}
@@ -0,0 +1,317 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/connection"
+)
+
+type sourceConf struct {
+	// host:port address.
+	Addr     string `json:"addr,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Optional password. Must match the password specified in the
+	Password string `json:"password,omitempty"`
+	DataType string `json:"dataType,omitempty"`
+	DB       string `json:"datasource,omitempty"`
+	// single means each key is a row, multiple means all keys are combined into one row
+	KeyType string `json:"keyType,omitempty"`
+	// The pattern to scan the keys, it is only used when keys is not set
+	KeyPattern string `json:"keyPattern,omitempty"`
+	// The keys to read
+	Keys []string `json:"keys,omitempty"`
+	// The count hint of each scan
+	ScanCount int64 `json:"scanCount,omitempty"`
+}
+
+// source reads the values of the keys in an interval. The keys are either configured or scanned by the key pattern.
+type source struct {
+	c         *sourceConf
+	db        int
+	cc        *connConf
+	tlsConf   *tls.Config
+	connProps map[string]any
+	cw        *connection.ConnWrapper
+	conn      *Connection
+}
+
+func (s *source) Provision(_ api.StreamContext, props map[string]any) error {
+	return s.Validate(props)
+}
+
+func (s *source) Validate(props map[string]any) error {
+	cfg := &sourceConf{DataType: "string", KeyType: "single", KeyPattern: "*", ScanCount: 100}
+	err := cast.MapToStruct(props, cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.DataType != "string" && cfg.DataType != "list" && cfg.DataType != "hash" {
+		return errors.New("redis dataType must be string, list or hash")
+	}
+	if cfg.KeyType != "single" && cfg.KeyType != "multiple" {
+		return errors.New("redis keyType must be single or multiple")
+	}
+	if len(cfg.Keys) == 0 && cfg.KeyPattern == "" {
+		return errors.New("redis source must have keys or keyPattern")
+	}
+	if cfg.DB == "" || cfg.DB == "/$$TEST_CONNECTION$$" {
+		cfg.DB = "0"
+	}
+	s.db, err = strconv.Atoi(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("datasource %s is invalid", cfg.DB)
+	}
+	if s.db < 0 || s.db > 15 {
+		return fmt.Errorf("redis source db should be in range 0-15")
+	}
+	// The db is defined by the datasource
+	connProps := make(map[string]any, len(props)+1)
+	for k, v := range props {
+		connProps[k] = v
+	}
+	connProps["db"] = s.db
+	s.cc, s.tlsConf, err = validateConnConf(connProps)
+	if err != nil {
+		return err
+	}
+	s.connProps = connProps
+	s.c = cfg
+	return nil
+}
+
+func (s *source) Ping(ctx api.StreamContext, props map[string]any) error {
+	if err := s.Validate(props); err != nil {
+		return err
+	}
+	cli := newClient(s.cc, s.tlsConf)
+	defer cli.Close()
+	return cli.Ping(ctx).Err()
+}
+
+func (s *source) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Debug("Opening redis source")
+	var err error
+	// Connection pool will handle status change. Connectors with the same connection configuration share the client.
+	s.cw, err = connection.FetchConnection(ctx, buildConnID(s.cc), "redis", s.connProps, sch)
+	if err != nil {
+		return err
+	}
+	conn, err := s.cw.Wait(ctx)
+	if conn == nil {
+		return fmt.Errorf("redis client not ready: %v", err)
+	}
+	c, ok := conn.(*Connection)
+	if !ok {
+		return fmt.Errorf("should use redis connection")
+	}
+	s.conn = c
+	return err
+}
+
+func (s *source) Pull(ctx api.StreamContext, trigger time.Time, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	keys, err := s.getKeys(ctx)
+	if err != nil {
+		ingestError(ctx, err)
+		return
+	}
+	ctx.GetLogger().Debugf("redis source pull keys %v", keys)
+	var combined map[string]any
+	if s.c.KeyType == "multiple" {
+		combined = make(map[string]any, len(keys))
+	}
+	for _, key := range keys {
+		if combined != nil {
+			v, found, err := s.readRaw(ctx, key)
+			if err != nil {
+				ingestError(ctx, fmt.Errorf("read redis key %s error: %v", key, err))
+				continue
+			}
+			if found {
+				combined[key] = v
+			}
+			continue
+		}
+		rows, err := s.readRows(ctx, key)
+		if err != nil {
+			ingestError(ctx, fmt.Errorf("read redis key %s error: %v", key, err))
+			continue
+		}
+		for _, row := range rows {
+			ingest(ctx, row, map[string]any{"key": key}, trigger)
+		}
+	}
+	if len(combined) > 0 {
+		ingest(ctx, combined, nil, trigger)
+	}
+}
+
+// getKeys returns the configured keys or scans the keys by the pattern
+func (s *source) getKeys(ctx api.StreamContext) ([]string, error) {
+	if len(s.c.Keys) > 0 {
+		return s.c.Keys, nil
+	}
+	var (
+		keys []string
+		mu   sync.Mutex
+	)
+	scan := func(ctx context.Context, cli redis.Cmdable) error {
+		iter := cli.Scan(ctx, 0, s.c.KeyPattern, s.c.ScanCount).Iterator()
+		for iter.Next(ctx) {
+			mu.Lock()
+			keys = append(keys, iter.Val())
+			mu.Unlock()
+		}
+		return iter.Err()
+	}
+	var err error
+	// The keys are distributed in the master nodes in cluster mode
+	if cc, ok := s.conn.Client().(*redis.ClusterClient); ok {
+		err = cc.ForEachMaster(ctx, func(ctx context.Context, cli *redis.Client) error {
+			return scan(ctx, cli)
+		})
+	} else {
+		err = scan(ctx, s.conn.Client())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan redis keys by pattern %s error: %v", s.c.KeyPattern, err)
+	}
+	return keys, nil
+}
+
+// readRows reads the value of the key and decodes it as rows
+func (s *source) readRows(ctx api.StreamContext, key string) ([]map[string]any, error) {
+	switch s.c.DataType {
+	case "list":
+		res, err := s.conn.Client().LRange(ctx, key, 0, -1).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return nil, nil
+			}
+			return nil, err
+		}
+		ret := make([]map[string]any, 0, len(res))
+		for _, r := range res {
+			m := make(map[string]any)
+			err = json.Unmarshal(cast.StringToBytes(r), &m)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, m)
+		}
+		return ret, nil
+	case "hash":
+		res, err := s.conn.Client().HGetAll(ctx, key).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if len(res) == 0 {
+			return nil, nil
+		}
+		m := make(map[string]any, len(res))
+		for k, v := range res {
+			m[k] = v
+		}
+		return []map[string]any{m}, nil
+	default:
+		res, err := s.conn.Client().Get(ctx, key).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return nil, nil
+			}
+			return nil, err
+		}
+		m := make(map[string]any)
+		err = json.Unmarshal(cast.StringToBytes(res), &m)
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]any{m}, nil
+	}
+}
+
+// readRaw reads the value of the key without decoding for multiple key type
+func (s *source) readRaw(ctx api.StreamContext, key string) (any, bool, error) {
+	var (
+		v   any
+		err error
+	)
+	switch s.c.DataType {
+	case "list":
+		var res []string
+		res, err = s.conn.Client().LRange(ctx, key, 0, -1).Result()
+		if err == nil && len(res) == 0 {
+			return nil, false, nil
+		}
+		l := make([]any, len(res))
+		for i, r := range res {
+			l[i] = r
+		}
+		v = l
+	case "hash":
+		var res map[string]string
+		res, err = s.conn.Client().HGetAll(ctx, key).Result()
+		if err == nil && len(res) == 0 {
+			return nil, false, nil
+		}
+		m := make(map[string]any, len(res))
+		for k, r := range res {
+			m[k] = r
+		}
+		v = m
+	default:
+		v, err = s.conn.Client().Get(ctx, key).Result()
+	}
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+func (s *source) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing redis source")
+	if s.cw != nil {
+		return connection.DetachConnection(ctx, s.cw.ID)
+	}
+	return nil
+}
+
+func GetSource() api.Source {
+	return &source{}
+}
+
+var (
+	_ api.PullTupleSource = &source{}
+	_ util.PingableConn   = &source{}
+)
@@ -0,0 +1,164 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestSourcePull(t *testing.T) {
+	require.NoError(t, mr.Set("srcStr:1", `{"id":1,"name":"John"}`))
+	require.NoError(t, mr.Set("srcStr:2", `{"id":2,"name":"Susan"}`))
+	_, err := mr.Lpush("srcList:1", `{"id":3,"name":"Nancy"}`)
+	require.NoError(t, err)
+	_, err = mr.Lpush("srcList:1", `{"id":4,"name":"Tom"}`)
+	require.NoError(t, err)
+	mr.HSet("srcHash:1", "id", "5", "name", "Bob")
+	tests := []struct {
+		name  string
+		props map[string]any
+		exp   []any
+		keys  []string
+	}{
+		{
+			name:  "scan string",
+			props: map[string]any{"addr": addr, "keyPattern": "srcStr:*"},
+			exp: []any{
+				map[string]any{"id": float64(1), "name": "John"},
+				map[string]any{"id": float64(2), "name": "Susan"},
+			},
+			keys: []string{"srcStr:1", "srcStr:2"},
+		},
+		{
+			name:  "keys string",
+			props: map[string]any{"addr": addr, "keys": []string{"srcStr:2", "srcStr:notexist"}},
+			exp: []any{
+				map[string]any{"id": float64(2), "name": "Susan"},
+			},
+			keys: []string{"srcStr:2"},
+		},
+		{
+			name:  "scan list",
+			props: map[string]any{"addr": addr, "dataType": "list", "keyPattern": "srcList:*"},
+			exp: []any{
+				map[string]any{"id": float64(4), "name": "Tom"},
+				map[string]any{"id": float64(3), "name": "Nancy"},
+			},
+			keys: []string{"srcList:1", "srcList:1"},
+		},
+		{
+			name:  "scan hash",
+			props: map[string]any{"addr": addr, "dataType": "hash", "keyPattern": "srcHash:*"},
+			exp: []any{
+				map[string]any{"id": "5", "name": "Bob"},
+			},
+			keys: []string{"srcHash:1"},
+		},
+		{
+			name:  "multiple string",
+			props: map[string]any{"addr": addr, "keyType": "multiple", "keyPattern": "srcStr:*"},
+			exp: []any{
+				map[string]any{"srcStr:1": `{"id":1,"name":"John"}`, "srcStr:2": `{"id":2,"name":"Susan"}`},
+			},
+		},
+		{
+			name:  "multiple list",
+			props: map[string]any{"addr": addr, "keyType": "multiple", "dataType": "list", "keys": []string{"srcList:1", "srcList:notexist"}},
+			exp: []any{
+				map[string]any{"srcList:1": []any{`{"id":4,"name":"Tom"}`, `{"id":3,"name":"Nancy"}`}},
+			},
+		},
+	}
+	ctx := mockContext.NewMockContext("testSource", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GetSource().(*source)
+			require.NoError(t, s.Provision(ctx, tt.props))
+			require.NoError(t, s.Connect(ctx, func(status string, message string) {
+				// do nothing
+			}))
+			defer s.Close(ctx)
+			var (
+				result []any
+				keys   []string
+			)
+			s.Pull(ctx, time.Now(), func(ctx api.StreamContext, data any, meta map[string]any, ts time.Time) {
+				result = append(result, data)
+				if meta != nil {
+					keys = append(keys, meta["key"].(string))
+				}
+			}, func(ctx api.StreamContext, err error) {
+				t.Error(err)
+			})
+			// scan order is not guaranteed
+			if tt.keys != nil {
+				assert.ElementsMatch(t, tt.keys, keys)
+			}
+			assert.ElementsMatch(t, tt.exp, result)
+		})
+	}
+}
+
+func TestSourceValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "invalid data type",
+			props: map[string]any{"addr": addr, "dataType": "stream"},
+			err:   "redis dataType must be string, list or hash",
+		},
+		{
+			name:  "invalid key type",
+			props: map[string]any{"addr": addr, "keyType": "ttt"},
+			err:   "redis keyType must be single or multiple",
+		},
+		{
+			name:  "missing key pattern",
+			props: map[string]any{"addr": addr, "keyPattern": ""},
+			err:   "redis source must have keys or keyPattern",
+		},
+		{
+			name:  "invalid db",
+			props: map[string]any{"addr": addr, "datasource": "16"},
+			err:   "redis source db should be in range 0-15",
+		},
+		{
+			name:  "cluster without addrs",
+			props: map[string]any{"addr": addr, "mode": "cluster"},
+			err:   "addrs is required when redis mode is cluster",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &source{}
+			err := s.Validate(tt.props)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+	// addr is not required in cluster and sentinel mode
+	s := &source{}
+	require.NoError(t, s.Validate(map[string]any{"mode": "cluster", "addrs": addr}))
+	require.NoError(t, s.Validate(map[string]any{"mode": "sentinel", "addrs": addr, "masterName": "master"}))
+}
@@ -0,0 +1,367 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+type streamConf struct {
+	// The stream to read. If not set, the datasource of the stream definition is used
+	Stream     string `json:"stream,omitempty"`
+	DataSource string `json:"datasource,omitempty"`
+	// The consumer group and the consumer name in the group
+	Group    string `json:"group,omitempty"`
+	Consumer string `json:"consumer,omitempty"`
+	// The id to start reading when creating the group, $ means only the new entries
+	StartID string `json:"startID,omitempty"`
+	// The max count of entries of each read
+	BatchCount int64 `json:"batchCount,omitempty"`
+	// How long to block in each read
+	Block cast.DurationConf `json:"block,omitempty"`
+	// ClaimPending claims the pending entries of the dead consumers by XAUTOCLAIM on startup
+	ClaimPending bool `json:"claimPending,omitempty"`
+	// The min idle time of the pending entries to be claimed
+	ClaimMinIdle cast.DurationConf `json:"claimMinIdle,omitempty"`
+}
+
+// streamSource reads the entries of a redis stream by a consumer group. If checkpoint is enabled, the entries are
+// acknowledged by XACK when the checkpoint covering them completes, so the entries which are not checkpointed due to a
+// crash are still pending and are read again when the consumer restarts. Otherwise, each entry is acknowledged right
+// after it is ingested into the rule.
+type streamSource struct {
+	c       *streamConf
+	cc      *connConf
+	tlsConf *tls.Config
+	cli     redis.UniversalClient
+	// checkpointAck defers the acknowledgement until the checkpoint completes
+	checkpointAck bool
+	mu            sync.Mutex
+	// pending are the ids of the ingested entries which are not acknowledged yet
+	pending []string
+}
+
+func (s *streamSource) Provision(ctx api.StreamContext, props map[string]any) error {
+	if err := s.Validate(props); err != nil {
+		return err
+	}
+	if s.c.Consumer == "" {
+		s.c.Consumer = fmt.Sprintf("%s_%s_%d", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	}
+	return nil
+}
+
+func (s *streamSource) Validate(props map[string]any) error {
+	c := &streamConf{StartID: "$", BatchCount: 10, Block: cast.DurationConf(time.Second), ClaimMinIdle: cast.DurationConf(time.Minute)}
+	err := cast.MapToStruct(props, c)
+	if err != nil {
+		return err
+	}
+	if c.Stream == "" && c.DataSource != "/$$TEST_CONNECTION$$" {
+		c.Stream = c.DataSource
+	}
+	if c.Stream == "" {
+		return errors.New("redis stream is required")
+	}
+	if c.Group == "" {
+		return errors.New("redis stream group is required")
+	}
+	if c.BatchCount <= 0 {
+		return errors.New("batchCount must be greater than 0")
+	}
+	if c.Block <= 0 {
+		return errors.New("block must be greater than 0")
+	}
+	if c.ClaimMinIdle < 0 {
+		return errors.New("claimMinIdle must not be negative")
+	}
+	cc, tlsConf, err := validateConnConf(props)
+	if err != nil {
+		return err
+	}
+	if cc.Db < 0 || cc.Db > 15 {
+		return fmt.Errorf("redis stream db should be in range 0-15")
+	}
+	s.c = c
+	s.cc = cc
+	s.tlsConf = tlsConf
+	return nil
+}
+
+func (s *streamSource) Ping(ctx api.StreamContext, props map[string]any) error {
+	if err := s.Validate(props); err != nil {
+		return err
+	}
+	cli := newClient(s.cc, s.tlsConf)
+	defer cli.Close()
+	return cli.Ping(ctx).Err()
+}
+
+func (s *streamSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Debug("Opening redis stream source")
+	// The blocking read holds the connection, so the client is not shared in the connection pool
+	s.cli = newClient(s.cc, s.tlsConf)
+	err := s.cli.Ping(ctx).Err()
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *streamSource) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) error {
+	err := s.cli.XGroupCreateMkStream(ctx, s.c.Stream, s.c.Group, s.c.StartID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("create redis stream group %s error: %v", s.c.Group, err)
+	}
+	go func() {
+		err := infra.SafeRun(func() error {
+			s.run(ctx, ingest, ingestError)
+			return nil
+		})
+		if err != nil {
+			ingestError(ctx, err)
+		}
+	}()
+	return nil
+}
+
+func (s *streamSource) run(ctx api.StreamContext, ingest api.TupleIngest, ingestError api.ErrorIngest) {
+	// Read the entries delivered to this consumer but not acknowledged before the restart
+	for ctx.Err() == nil {
+		n, err := s.read(ctx, "0", 0, ingest)
+		if err != nil {
+			ingestError(ctx, err)
+			break
+		}
+		if n == 0 {
+			break
+		}
+	}
+	if s.c.ClaimPending {
+		if err := s.claim(ctx, ingest); err != nil {
+			ingestError(ctx, err)
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			ctx.GetLogger().Infof("redis stream source %s exit", s.c.Stream)
+			return
+		default:
+		}
+		_, err := s.read(ctx, ">", time.Duration(s.c.Block), ingest)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			ingestError(ctx, err)
+			// Avoid busy loop when the server is unavailable
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(s.c.Block)):
+			}
+		}
+	}
+}
+
+// read reads the entries after the id and ingests them. The read returns immediately if block is 0.
+func (s *streamSource) read(ctx api.StreamContext, id string, block time.Duration, ingest api.TupleIngest) (int, error) {
+	args := &redis.XReadGroupArgs{
+		Group:    s.c.Group,
+		Consumer: s.c.Consumer,
+		Streams:  []string{s.c.Stream, id},
+		Count:    s.c.BatchCount,
+		Block:    block,
+	}
+	if block == 0 {
+		// Negative value means no BLOCK argument
+		args.Block = -1
+	}
+	res, err := s.cli.XReadGroup(ctx, args).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read redis stream %s error: %v", s.c.Stream, err)
+	}
+	n := 0
+	for _, st := range res {
+		n += len(st.Messages)
+		for _, msg := range st.Messages {
+			if err := s.ingestEntry(ctx, msg, ingest); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// claim takes over the pending entries of the dead consumers which are idle longer than claimMinIdle
+func (s *streamSource) claim(ctx api.StreamContext, ingest api.TupleIngest) error {
+	start := "0-0"
+	for ctx.Err() == nil {
+		msgs, next, err := s.cli.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   s.c.Stream,
+			Group:    s.c.Group,
+			Consumer: s.c.Consumer,
+			MinIdle:  time.Duration(s.c.ClaimMinIdle),
+			Start:    start,
+			Count:    s.c.BatchCount,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("claim pending entries of redis stream %s error: %v", s.c.Stream, err)
+		}
+		if len(msgs) > 0 {
+			ctx.GetLogger().Infof("claimed %d pending entries of redis stream %s", len(msgs), s.c.Stream)
+		}
+		for _, msg := range msgs {
+			if err := s.ingestEntry(ctx, msg, ingest); err != nil {
+				return err
+			}
+		}
+		if next == "0-0" || next == "" {
+			return nil
+		}
+		start = next
+	}
+	return nil
+}
+
+// ingestEntry ingests the fields of the entry and acknowledges it, or keeps it pending until the checkpoint completes
+func (s *streamSource) ingestEntry(ctx api.StreamContext, msg redis.XMessage, ingest api.TupleIngest) error {
+	// The entry was deleted after it was delivered, only acknowledge it
+	if len(msg.Values) > 0 {
+		ingest(ctx, msg.Values, map[string]any{"stream": s.c.Stream, "id": msg.ID}, timex.GetNow())
+		if s.checkpointAck {
+			// Add the id after the entry is sent out so that a checkpoint never covers the entry not sent yet
+			s.mu.Lock()
+			s.pending = append(s.pending, msg.ID)
+			s.mu.Unlock()
+			return nil
+		}
+	}
+	err := s.cli.XAck(ctx, s.c.Stream, s.c.Group, msg.ID).Err()
+	if err != nil {
+		return fmt.Errorf("ack redis stream %s entry %s error: %v", s.c.Stream, msg.ID, err)
+	}
+	return nil
+}
+
+// Rewind does nothing. The entries not acknowledged by the completed checkpoints are still pending and are read again
+// on startup.
+func (s *streamSource) Rewind(offset any) error {
+	return nil
+}
+
+func (s *streamSource) ResetOffset(input map[string]any) error {
+	return errors.New("redis stream source not support reset offset")
+}
+
+// GetOffset returns the ids of the ingested entries which are not acknowledged yet
+func (s *streamSource) GetOffset() (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, len(s.pending))
+	copy(ids, s.pending)
+	return ids, nil
+}
+
+func (s *streamSource) EnableCheckpointCommit() {
+	s.checkpointAck = true
+}
+
+// CommitOffset acknowledges the entries of a completed checkpoint
+func (s *streamSource) CommitOffset(ctx api.StreamContext, offset any) error {
+	ids, err := toEntryIDs(offset)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := s.cli.XAck(ctx, s.c.Stream, s.c.Group, ids...).Err(); err != nil {
+		return fmt.Errorf("ack redis stream %s entries error: %v", s.c.Stream, err)
+	}
+	acked := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		acked[id] = struct{}{}
+	}
+	s.mu.Lock()
+	rest := s.pending[:0]
+	for _, id := range s.pending {
+		if _, ok := acked[id]; !ok {
+			rest = append(rest, id)
+		}
+	}
+	s.pending = rest
+	s.mu.Unlock()
+	return nil
+}
+
+// toEntryIDs converts the offset of a checkpoint to the entry ids
+func toEntryIDs(offset any) ([]string, error) {
+	switch v := offset.(type) {
+	case []string:
+		return v, nil
+	case []any:
+		ids := make([]string, 0, len(v))
+		for _, id := range v {
+			s, ok := id.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid redis stream entry id %v", id)
+			}
+			ids = append(ids, s)
+		}
+		return ids, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%v can't be set as redis stream offset", offset)
+	}
+}
+
+func (s *streamSource) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing redis stream source")
+	if s.cli != nil {
+		return s.cli.Close()
+	}
+	return nil
+}
+
+func GetStreamSource() api.Source {
+	return &streamSource{}
+}
+
+var (
+	_ api.TupleSource       = &streamSource{}
+	_ util.PingableConn     = &streamSource{}
+	_ model.OffsetCommitter = &streamSource{}
+)
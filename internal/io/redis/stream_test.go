@@ -0,0 +1,197 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestStreamSourceConfig(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "missing stream",
+			props: map[string]any{"addr": addr, "group": "g1"},
+			err:   "redis stream is required",
+		},
+		{
+			name:  "missing group",
+			props: map[string]any{"addr": addr, "datasource": "s1"},
+			err:   "redis stream group is required",
+		},
+		{
+			name:  "invalid batchCount",
+			props: map[string]any{"addr": addr, "stream": "s1", "group": "g1", "batchCount": 0},
+			err:   "batchCount must be greater than 0",
+		},
+		{
+			name:  "invalid block",
+			props: map[string]any{"addr": addr, "stream": "s1", "group": "g1", "block": "0s"},
+			err:   "block must be greater than 0",
+		},
+		{
+			name:  "invalid db",
+			props: map[string]any{"addr": addr, "stream": "s1", "group": "g1", "db": 16},
+			err:   "redis stream db should be in range 0-15",
+		},
+	}
+	ctx := mockContext.NewMockContext("testStream", "op")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := GetStreamSource()
+			assert.EqualError(t, s.Provision(ctx, tt.props), tt.err)
+		})
+	}
+	s := &streamSource{}
+	require.NoError(t, s.Provision(ctx, map[string]any{"addr": addr, "datasource": "s1", "group": "g1"}))
+	assert.Equal(t, "s1", s.c.Stream)
+	assert.Equal(t, "testStream_op_0", s.c.Consumer)
+}
+
+type streamRecv struct {
+	sync.Mutex
+	data []map[string]any
+	ids  []string
+}
+
+func (r *streamRecv) ingest(_ api.StreamContext, data any, meta map[string]any, _ time.Time) {
+	r.Lock()
+	defer r.Unlock()
+	r.data = append(r.data, data.(map[string]any))
+	r.ids = append(r.ids, meta["id"].(string))
+}
+
+func (r *streamRecv) len() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.data)
+}
+
+func TestStreamSource(t *testing.T) {
+	const stream = "testStreamSrc"
+	cli := redis.NewClient(&redis.Options{Addr: addr})
+	defer cli.Close()
+	bg := context.Background()
+	for _, id := range []string{"1-1", "1-2", "1-3"} {
+		require.NoError(t, cli.XAdd(bg, &redis.XAddArgs{Stream: stream, ID: id, Values: map[string]any{"id": id}}).Err())
+	}
+	require.NoError(t, cli.XGroupCreate(bg, stream, "g1", "0").Err())
+	// 1-1 is delivered to this consumer and 1-2 to a dead consumer before the source starts, neither is acknowledged
+	require.NoError(t, cli.XReadGroup(bg, &redis.XReadGroupArgs{Group: "g1", Consumer: "c1", Streams: []string{stream, ">"}, Count: 1, Block: -1}).Err())
+	require.NoError(t, cli.XReadGroup(bg, &redis.XReadGroupArgs{Group: "g1", Consumer: "dead", Streams: []string{stream, ">"}, Count: 1, Block: -1}).Err())
+
+	ctx, cancel := mockContext.NewMockContext("testStream", "op").WithCancel()
+	s := &streamSource{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":         addr,
+		"stream":       stream,
+		"group":        "g1",
+		"consumer":     "c1",
+		"claimPending": true,
+		"claimMinIdle": "0s",
+		"block":        "100ms",
+	}))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer func() {
+		cancel()
+		_ = s.Close(ctx)
+	}()
+	r := &streamRecv{}
+	require.NoError(t, s.Subscribe(ctx, r.ingest, func(ctx api.StreamContext, err error) {
+		assert.NoError(t, err)
+	}))
+	require.Eventually(t, func() bool { return r.len() == 3 }, 5*time.Second, 10*time.Millisecond)
+	// pending of the consumer first, then the claimed and the new
+	assert.Equal(t, []string{"1-1", "1-2", "1-3"}, r.ids)
+	assert.Equal(t, map[string]any{"id": "1-1"}, r.data[0])
+
+	require.NoError(t, cli.XAdd(bg, &redis.XAddArgs{Stream: stream, ID: "1-4", Values: map[string]any{"id": "1-4"}}).Err())
+	require.Eventually(t, func() bool { return r.len() == 4 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "1-4", r.ids[3])
+	// all entries are acknowledged
+	require.Eventually(t, func() bool {
+		p, err := cli.XPending(bg, stream, "g1").Result()
+		return err == nil && p.Count == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStreamSourceCheckpointAck(t *testing.T) {
+	const stream = "testStreamCkpt"
+	cli := redis.NewClient(&redis.Options{Addr: addr})
+	defer cli.Close()
+	bg := context.Background()
+	ctx, cancel := mockContext.NewMockContext("testStreamCkpt", "op").WithCancel()
+	s := &streamSource{}
+	require.NoError(t, s.Provision(ctx, map[string]any{
+		"addr":    addr,
+		"stream":  stream,
+		"group":   "g1",
+		"startID": "0",
+		"block":   "100ms",
+	}))
+	s.EnableCheckpointCommit()
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer func() {
+		cancel()
+		_ = s.Close(ctx)
+	}()
+	for _, id := range []string{"1-1", "1-2"} {
+		require.NoError(t, cli.XAdd(bg, &redis.XAddArgs{Stream: stream, ID: id, Values: map[string]any{"id": id}}).Err())
+	}
+	r := &streamRecv{}
+	require.NoError(t, s.Subscribe(ctx, r.ingest, func(ctx api.StreamContext, err error) {
+		assert.NoError(t, err)
+	}))
+	require.Eventually(t, func() bool { return r.len() == 2 }, 5*time.Second, 10*time.Millisecond)
+	// the ingested entries are not acknowledged before the checkpoint completes
+	offset, err := s.GetOffset()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1-1", "1-2"}, offset)
+	p, err := cli.XPending(bg, stream, "g1").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), p.Count)
+
+	require.NoError(t, cli.XAdd(bg, &redis.XAddArgs{Stream: stream, ID: "1-3", Values: map[string]any{"id": "1-3"}}).Err())
+	require.Eventually(t, func() bool { return r.len() == 3 }, 5*time.Second, 10*time.Millisecond)
+	// the checkpoint taken before 1-3 completes, the restored state is a list of any
+	require.NoError(t, s.CommitOffset(ctx, []any{"1-1", "1-2"}))
+	p, err = cli.XPending(bg, stream, "g1").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), p.Count)
+	assert.Equal(t, "1-3", p.Lower)
+	offset, err = s.GetOffset()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1-3"}, offset)
+
+	assert.Error(t, s.CommitOffset(ctx, 1))
+	assert.Error(t, s.ResetOffset(nil))
+}
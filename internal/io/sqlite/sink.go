@@ -0,0 +1,376 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	// introduce sqlite
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+)
+
+type config struct {
+	// Path is the database file. A relative path is relative to the data directory
+	Path      string `json:"path"`
+	TableName string `json:"tableName"`
+	// DDL creates the table when connecting. If not set, the table is created by the fields of the first tuple
+	// if it does not exist
+	DDL string `json:"ddl"`
+	// KeyField is the primary key to upsert, update or delete the row
+	KeyField     string `json:"keyField"`
+	RowkindField string `json:"rowkindField"`
+	// BusyTimeout is how long to wait for the lock of the database held by other connections
+	BusyTimeout cast.DurationConf `json:"busyTimeout"`
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+type sqliteSink struct {
+	c  *config
+	db *sql.DB
+	// columns of the table, empty if the table is not created yet
+	columns map[string]struct{}
+}
+
+func (s *sqliteSink) Provision(_ api.StreamContext, props map[string]any) error {
+	c := &config{BusyTimeout: cast.DurationConf(5 * time.Second)}
+	err := cast.MapToStruct(props, c)
+	if err != nil {
+		return err
+	}
+	if c.Path == "" {
+		return errors.New("path is required")
+	}
+	if c.TableName == "" {
+		return errors.New("tableName is required")
+	}
+	if c.RowkindField != "" && c.KeyField == "" {
+		return errors.New("keyField is required when rowkindField is set")
+	}
+	if c.BusyTimeout < 0 {
+		return errors.New("busyTimeout must not be negative")
+	}
+	if !filepath.IsAbs(c.Path) {
+		dataDir, err := conf.GetDataLoc()
+		if err != nil {
+			return err
+		}
+		c.Path = filepath.Join(dataDir, c.Path)
+	}
+	s.c = c
+	return nil
+}
+
+func (s *sqliteSink) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
+	ctx.GetLogger().Infof("Opening sqlite sink %s", s.c.Path)
+	err := s.open()
+	if err != nil {
+		sch(api.ConnectionDisconnected, err.Error())
+		return err
+	}
+	sch(api.ConnectionConnected, "")
+	return nil
+}
+
+func (s *sqliteSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.c.Path), os.ModePerm); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", connectionString(s.c.Path, time.Duration(s.c.BusyTimeout)))
+	if err != nil {
+		return err
+	}
+	// Sqlite only allows one writer, serialize the writes in the sink instead of failing with busy
+	db.SetMaxOpenConns(1)
+	s.db = db
+	if s.c.DDL != "" {
+		if _, err := db.Exec(s.c.DDL); err != nil {
+			return fmt.Errorf("fail to run ddl: %v", err)
+		}
+	}
+	return s.loadColumns()
+}
+
+func connectionString(p string, busyTimeout time.Duration) string {
+	return fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(%d)", p, busyTimeout.Milliseconds())
+}
+
+func (s *sqliteSink) loadColumns() error {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT name FROM pragma_table_info(%s)", quoteString(s.c.TableName)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	s.columns = make(map[string]struct{})
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		s.columns[name] = struct{}{}
+	}
+	return rows.Err()
+}
+
+func (s *sqliteSink) Collect(ctx api.StreamContext, item api.MessageTuple) error {
+	return wrapErr(s.save(ctx, s.db, item.ToMap()))
+}
+
+// CollectList writes all the tuples in one transaction
+func (s *sqliteSink) CollectList(ctx api.StreamContext, items api.MessageTupleList) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return wrapErr(err)
+	}
+	items.RangeOfTuples(func(_ int, tuple api.MessageTuple) bool {
+		err = s.save(ctx, tx, tuple.ToMap())
+		return err == nil
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		// The inferred table is rolled back too
+		s.reloadColumns(ctx)
+		return wrapErr(err)
+	}
+	return wrapErr(tx.Commit())
+}
+
+// wrapErr marks the busy, locked and I/O errors of sqlite as IOErr so that they can be retried. Other errors such as
+// the constraint violation or invalid data fail the same way when retrying, so they are returned as is.
+func wrapErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *sqlite.Error
+	if errors.As(err, &se) {
+		// The extended result code keeps the primary code in the lower 8 bits
+		switch se.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_IOERR:
+			return errorx.NewIOErr(err.Error())
+		}
+	}
+	return err
+}
+
+func (s *sqliteSink) reloadColumns(ctx api.StreamContext) {
+	if err := s.loadColumns(); err != nil {
+		ctx.GetLogger().Warnf("fail to load the columns of table %s: %v", s.c.TableName, err)
+	}
+}
+
+func (s *sqliteSink) save(ctx api.StreamContext, db execer, data map[string]any) error {
+	rowkind, err := s.getRowkind(data)
+	if err != nil {
+		return err
+	}
+	if len(s.columns) == 0 {
+		if err := s.createTable(ctx, db, data); err != nil {
+			return err
+		}
+	}
+	var cols []string
+	var vals []any
+	for k, v := range data {
+		if _, ok := s.columns[k]; !ok {
+			continue
+		}
+		sv, err := toSqlValue(v)
+		if err != nil {
+			return fmt.Errorf("invalid value of field %s: %v", k, err)
+		}
+		cols = append(cols, k)
+		vals = append(vals, sv)
+	}
+	if len(cols) == 0 {
+		ctx.GetLogger().Debugf("skip the data without any column of table %s: %v", s.c.TableName, data)
+		return nil
+	}
+	var (
+		stmt string
+		args []any
+	)
+	switch rowkind {
+	case ast.RowkindInsert:
+		stmt, args = s.insertStmt(cols, vals, false)
+	case ast.RowkindUpsert:
+		stmt, args = s.insertStmt(cols, vals, true)
+	case ast.RowkindUpdate:
+		key, ok := data[s.c.KeyField]
+		if !ok {
+			return fmt.Errorf("field %s does not exist in data %v", s.c.KeyField, data)
+		}
+		sets := make([]string, 0, len(cols))
+		for i, col := range cols {
+			sets = append(sets, quoteIdent(col)+" = ?")
+			args = append(args, vals[i])
+		}
+		stmt = fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?", quoteIdent(s.c.TableName), strings.Join(sets, ", "), quoteIdent(s.c.KeyField))
+		args = append(args, key)
+	case ast.RowkindDelete:
+		key, ok := data[s.c.KeyField]
+		if !ok {
+			return fmt.Errorf("field %s does not exist in data %v", s.c.KeyField, data)
+		}
+		stmt = fmt.Sprintf("DELETE FROM %s WHERE %s = ?", quoteIdent(s.c.TableName), quoteIdent(s.c.KeyField))
+		args = []any{key}
+	}
+	ctx.GetLogger().Debugf("sqlite sink exec %s with %v", stmt, args)
+	_, err = db.Exec(stmt, args...)
+	return err
+}
+
+func (s *sqliteSink) insertStmt(cols []string, vals []any, upsert bool) (string, []any) {
+	quoted := make([]string, len(cols))
+	placeholders := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = quoteIdent(col)
+		placeholders[i] = "?"
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(s.c.TableName), strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+	if upsert {
+		var sets []string
+		for i, col := range cols {
+			if col != s.c.KeyField {
+				sets = append(sets, fmt.Sprintf("%s = excluded.%s", quoted[i], quoted[i]))
+			}
+		}
+		if len(sets) > 0 {
+			stmt += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", quoteIdent(s.c.KeyField), strings.Join(sets, ", "))
+		} else {
+			stmt += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", quoteIdent(s.c.KeyField))
+		}
+	}
+	return stmt, vals
+}
+
+// createTable infers the column types by the values of the data. The key field is the primary key
+func (s *sqliteSink) createTable(ctx api.StreamContext, db execer, data map[string]any) error {
+	names := make([]string, 0, len(data))
+	for k := range data {
+		if k != s.c.RowkindField {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	if s.c.KeyField != "" {
+		if _, ok := data[s.c.KeyField]; !ok {
+			return fmt.Errorf("field %s does not exist in data %v", s.c.KeyField, data)
+		}
+	}
+	defs := make([]string, 0, len(names))
+	for _, name := range names {
+		d := quoteIdent(name) + " " + columnType(data[name])
+		if name == s.c.KeyField {
+			d += " PRIMARY KEY"
+		}
+		defs = append(defs, d)
+	}
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quoteIdent(s.c.TableName), strings.Join(defs, ", "))
+	ctx.GetLogger().Infof("sqlite sink create table: %s", stmt)
+	if _, err := db.Exec(stmt); err != nil {
+		return fmt.Errorf("fail to create table %s: %w", s.c.TableName, err)
+	}
+	s.columns = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		s.columns[name] = struct{}{}
+	}
+	return nil
+}
+
+func (s *sqliteSink) getRowkind(data map[string]any) (string, error) {
+	rowkind := ast.RowkindInsert
+	if s.c.KeyField != "" {
+		rowkind = ast.RowkindUpsert
+	}
+	if s.c.RowkindField != "" {
+		c, ok := data[s.c.RowkindField]
+		if ok {
+			rowkind, ok = c.(string)
+			if !ok {
+				return "", fmt.Errorf("rowkind field %s is not a string in data %v", s.c.RowkindField, data)
+			}
+			if rowkind != ast.RowkindInsert && rowkind != ast.RowkindUpdate && rowkind != ast.RowkindDelete && rowkind != ast.RowkindUpsert {
+				return "", fmt.Errorf("invalid rowkind %s", rowkind)
+			}
+		}
+	}
+	return rowkind, nil
+}
+
+func (s *sqliteSink) Close(ctx api.StreamContext) error {
+	ctx.GetLogger().Infof("Closing sqlite sink")
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+func columnType(v any) string {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, bool:
+		return "INTEGER"
+	case float32, float64:
+		return "REAL"
+	case []byte:
+		return "BLOB"
+	default:
+		return "TEXT"
+	}
+}
+
+// toSqlValue encodes the nested values as json text
+func toSqlValue(v any) (any, error) {
+	switch v.(type) {
+	case map[string]any, []any, []map[string]any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	default:
+		return v, nil
+	}
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteString(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+func GetSink() api.Sink {
+	return &sqliteSink{}
+}
+
+var _ api.TupleCollector = &sqliteSink{}
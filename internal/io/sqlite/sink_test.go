@@ -0,0 +1,187 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestProvision(t *testing.T) {
+	ctx := mockContext.NewMockContext("testProvision", "op")
+	tests := []struct {
+		name  string
+		props map[string]any
+		err   string
+	}{
+		{
+			name:  "no path",
+			props: map[string]any{"tableName": "t"},
+			err:   "path is required",
+		},
+		{
+			name:  "no table",
+			props: map[string]any{"path": "a.db"},
+			err:   "tableName is required",
+		},
+		{
+			name:  "rowkind without key",
+			props: map[string]any{"path": "a.db", "tableName": "t", "rowkindField": "action"},
+			err:   "keyField is required when rowkindField is set",
+		},
+		{
+			name:  "negative busy timeout",
+			props: map[string]any{"path": "a.db", "tableName": "t", "busyTimeout": -1},
+			err:   "busyTimeout must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &sqliteSink{}
+			assert.EqualError(t, s.Provision(ctx, tt.props), tt.err)
+		})
+	}
+}
+
+func newTestSink(t *testing.T, props map[string]any) *sqliteSink {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	props["path"] = filepath.Join(t.TempDir(), "sink.db")
+	s := &sqliteSink{}
+	require.NoError(t, s.Provision(ctx, props))
+	require.NoError(t, s.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	t.Cleanup(func() {
+		_ = s.Close(ctx)
+	})
+	return s
+}
+
+func queryAll(t *testing.T, s *sqliteSink, stmt string) [][]any {
+	rows, err := s.db.Query(stmt)
+	require.NoError(t, err)
+	defer rows.Close()
+	cols, err := rows.Columns()
+	require.NoError(t, err)
+	var result [][]any
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		require.NoError(t, rows.Scan(ptrs...))
+		result = append(result, vals)
+	}
+	require.NoError(t, rows.Err())
+	return result
+}
+
+func TestInferTable(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	s := newTestSink(t, map[string]any{"tableName": "result"})
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": int64(1), "temp": 20.5, "name": "a", "tags": []any{"x"}}}))
+	// The fields not in the table are ignored
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": int64(2), "temp": 21.0, "other": 1}}))
+	assert.Equal(t, [][]any{
+		{"id", "INTEGER"},
+		{"name", "TEXT"},
+		{"tags", "TEXT"},
+		{"temp", "REAL"},
+	}, queryAll(t, s, "SELECT name, type FROM pragma_table_info('result')"))
+	assert.Equal(t, [][]any{
+		{int64(1), "a", `["x"]`, 20.5},
+		{int64(2), nil, nil, 21.0},
+	}, queryAll(t, s, "SELECT id, name, tags, temp FROM result ORDER BY id"))
+}
+
+func TestRowkind(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	s := newTestSink(t, map[string]any{
+		"tableName":    "result",
+		"ddl":          "CREATE TABLE IF NOT EXISTS result (id INTEGER PRIMARY KEY, temp REAL, name TEXT)",
+		"keyField":     "id",
+		"rowkindField": "action",
+	})
+	w := &xsql.WindowTuples{}
+	for _, m := range []map[string]any{
+		{"id": 1, "temp": 20.5, "name": "a"},
+		{"id": 2, "temp": 21.5, "name": "b", "action": "insert"},
+		{"id": 3, "temp": 22.5, "name": "c"},
+		// upsert by default
+		{"id": 1, "temp": 23.5},
+		{"id": 2, "name": "bb", "action": "update"},
+		{"id": 3, "action": "delete"},
+	} {
+		w.Content = append(w.Content, &xsql.Tuple{Message: m})
+	}
+	require.NoError(t, s.CollectList(ctx, w))
+	assert.Equal(t, [][]any{
+		{int64(1), 23.5, "a"},
+		{int64(2), 21.5, "bb"},
+	}, queryAll(t, s, "SELECT id, temp, name FROM result ORDER BY id"))
+
+	// The whole batch is rolled back if any tuple fails
+	w = &xsql.WindowTuples{Content: []xsql.Row{
+		&xsql.Tuple{Message: map[string]any{"id": 4, "temp": 1.0}},
+		&xsql.Tuple{Message: map[string]any{"id": 5, "action": "drop"}},
+	}}
+	assert.EqualError(t, s.CollectList(ctx, w), "invalid rowkind drop")
+	assert.Equal(t, [][]any{{int64(2)}}, queryAll(t, s, "SELECT count(*) FROM result"))
+}
+
+func TestInsertConflict(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	s := newTestSink(t, map[string]any{
+		"tableName": "result",
+		"ddl":       "CREATE TABLE IF NOT EXISTS result (id INTEGER PRIMARY KEY, temp REAL)",
+	})
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 1, "temp": 20.5}}))
+	// Insert only without a key field
+	err := s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 1, "temp": 21.5}})
+	assert.Error(t, err)
+	// The constraint violation is not retryable
+	assert.False(t, errorx.IsIOError(err))
+}
+
+func TestBusy(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "op")
+	s := newTestSink(t, map[string]any{
+		"tableName":   "result",
+		"ddl":         "CREATE TABLE IF NOT EXISTS result (id INTEGER PRIMARY KEY, temp REAL)",
+		"busyTimeout": "0s",
+	})
+	// Another connection holds the write lock
+	db, err := sql.Open("sqlite", connectionString(s.c.Path, 0))
+	require.NoError(t, err)
+	defer db.Close()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	_, err = tx.Exec("INSERT INTO result (id, temp) VALUES (1, 20.5)")
+	require.NoError(t, err)
+	err = s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 2, "temp": 21.5}})
+	assert.Error(t, err)
+	assert.True(t, errorx.IsIOError(err))
+	require.NoError(t, tx.Rollback())
+	require.NoError(t, s.Collect(ctx, &xsql.Tuple{Message: map[string]any{"id": 2, "temp": 21.5}}))
+}
@@ -27,10 +27,18 @@ import (
 )
 
 type WebsocketSink struct {
-	cw    *connection.ConnWrapper
-	cfg   *WebsocketConfig
-	props map[string]any
-	topic string
+	cw      *connection.ConnWrapper
+	cfg     *WebsocketConfig
+	sinkCfg *websocketSinkConf
+	props   map[string]any
+	topic   string
+}
+
+type websocketSinkConf struct {
+	// MessageType is the frame type to send, could be text or binary
+	MessageType string `json:"messageType"`
+	// SendError reports an error if any connection drops the message because it is too slow to consume
+	SendError bool `json:"sendError"`
 }
 
 func (w *WebsocketSink) Provision(ctx api.StreamContext, configs map[string]any) error {
@@ -42,7 +50,15 @@ func (w *WebsocketSink) Provision(ctx api.StreamContext, configs map[string]any)
 	if !strings.HasPrefix(cfg.Endpoint, "/") {
 		return fmt.Errorf("websocket endpoint should start with /")
 	}
+	sinkCfg := &websocketSinkConf{MessageType: "text"}
+	if err := cast.MapToStruct(configs, sinkCfg); err != nil {
+		return err
+	}
+	if sinkCfg.MessageType != "text" && sinkCfg.MessageType != "binary" {
+		return fmt.Errorf("websocket messageType must be text or binary")
+	}
 	w.cfg = cfg
+	w.sinkCfg = sinkCfg
 	w.props = configs
 	return nil
 }
@@ -77,7 +93,14 @@ func (w *WebsocketSink) Collect(ctx api.StreamContext, item api.RawTuple) error
 }
 
 func (w *WebsocketSink) collect(ctx api.StreamContext, data []byte) error {
-	pubsub.ProduceAny(ctx, w.topic, data)
+	var msg any = data
+	if w.sinkCfg.MessageType == "binary" {
+		msg = httpserver.BinaryMessage(data)
+	}
+	dropped := pubsub.TryProduceAny(ctx, w.topic, msg)
+	if dropped > 0 && w.sinkCfg.SendError {
+		return fmt.Errorf("websocket message dropped by %d slow connections", dropped)
+	}
 	return nil
 }
 
@@ -68,3 +68,37 @@ func TestWebsocketSink(t *testing.T) {
 	<-assertCh
 	ws.Close(ctx)
 }
+
+func TestWebsocketSinkBinary(t *testing.T) {
+	connection.InitConnectionManager4Test()
+	ip := "127.0.0.1"
+	port := 10082
+	endpoint := "/e2"
+	httpserver.InitGlobalServerManager(ip, port, nil)
+	defer httpserver.ShutDown()
+	ctx := mockContext.NewMockContext("1", "2")
+	ws := &WebsocketSink{}
+	require.EqualError(t, ws.Provision(ctx, map[string]any{
+		"datasource":  endpoint,
+		"messageType": "json",
+	}), "websocket messageType must be text or binary")
+	require.NoError(t, ws.Provision(ctx, map[string]any{
+		"datasource":  endpoint,
+		"messageType": "binary",
+	}))
+	require.NoError(t, ws.Connect(ctx, func(status string, message string) {
+		// do nothing
+	}))
+	defer ws.Close(ctx)
+	expData := []byte{0x01, 0x02, 0x03}
+	conn, err := testx.CreateWebsocketClient(ip, port, endpoint)
+	require.NoError(t, err)
+	defer conn.Close()
+	// wait connection registered
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, ws.collect(ctx, expData))
+	msgTyp, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.BinaryMessage, msgTyp)
+	require.Equal(t, expData, data)
+}
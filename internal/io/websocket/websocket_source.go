@@ -77,7 +77,7 @@ func (w *WebsocketSource) Connect(ctx api.StreamContext, sc api.StatusChangeHand
 }
 
 func (w *WebsocketSource) Subscribe(ctx api.StreamContext, ingest api.BytesIngest, ingestError api.ErrorIngest) error {
-	ch := pubsub.CreateSub(w.topic, nil, w.sourceID, 1024)
+	ch := pubsub.CreateSub(w.topic, w.sourceID, 1024)
 	go func() {
 		for {
 			select {
@@ -0,0 +1,195 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deadletter persists the results which the sinks permanently fail to deliver so that they can be inspected
+// and replayed to the sinks later.
+package deadletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+)
+
+const table = "deadLetter"
+
+// Entry is a result which the sink fails to deliver
+type Entry struct {
+	Id     string `json:"id"`
+	RuleId string `json:"ruleId"`
+	// Sink is the name of the sink node which fails to deliver
+	Sink  string `json:"sink"`
+	Error string `json:"error"`
+	// Timestamp is the time in milliseconds when the delivery fails
+	Timestamp int64 `json:"timestamp"`
+	// Raw is the encoded payload of the bytes sink
+	Raw []byte `json:"raw,omitempty"`
+	// Messages are the results of the tuple sink
+	Messages []map[string]any `json:"messages,omitempty"`
+	// IsList indicates the messages are sent as a list
+	IsList bool `json:"isList,omitempty"`
+	// Props are the dynamic props of the result such as the topic
+	Props map[string]string `json:"props,omitempty"`
+}
+
+// ReplayFunc sends the entry to the sink again
+type ReplayFunc func(e *Entry) error
+
+// replayers are the running sinks which accept the replayed entries, keyed by rule id and sink name
+var replayers sync.Map
+
+func getDb() (kv.KeyValue, error) {
+	d, err := store.GetKV(table)
+	if err != nil {
+		return nil, fmt.Errorf("can not initialize store for the dead letters at path '%s': %v", table, err)
+	}
+	return d, nil
+}
+
+// Save persists the entry. The id is generated if not set.
+func Save(e *Entry) error {
+	d, err := getDb()
+	if err != nil {
+		return err
+	}
+	if e.Id == "" {
+		e.Id = uuid.New().String()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return d.Set(e.Id, string(b))
+}
+
+// List returns the entries of the rule sorted by the time of failure
+func List(ruleId string) ([]*Entry, error) {
+	d, err := getDb()
+	if err != nil {
+		return nil, err
+	}
+	all, err := d.All()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Entry, 0)
+	for id, s := range all {
+		e := &Entry{}
+		if err := json.Unmarshal(cast.StringToBytes(s), e); err != nil {
+			return nil, fmt.Errorf("parse the dead letter %s error: %v", id, err)
+		}
+		if e.RuleId == ruleId {
+			result = append(result, e)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Timestamp == result[j].Timestamp {
+			return result[i].Id < result[j].Id
+		}
+		return result[i].Timestamp < result[j].Timestamp
+	})
+	return result, nil
+}
+
+// Get returns the entry of the rule
+func Get(ruleId, id string) (*Entry, error) {
+	d, err := getDb()
+	if err != nil {
+		return nil, err
+	}
+	var s string
+	f, _ := d.Get(id, &s)
+	e := &Entry{}
+	if f {
+		if err := json.Unmarshal(cast.StringToBytes(s), e); err != nil {
+			return nil, fmt.Errorf("parse the dead letter %s error: %v", id, err)
+		}
+	}
+	if !f || e.RuleId != ruleId {
+		return nil, errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("dead letter %s of rule %s is not found", id, ruleId))
+	}
+	return e, nil
+}
+
+// Delete removes the entry of the rule
+func Delete(ruleId, id string) error {
+	if _, err := Get(ruleId, id); err != nil {
+		return err
+	}
+	d, err := getDb()
+	if err != nil {
+		return err
+	}
+	return d.Delete(id)
+}
+
+// DeleteAll removes all the entries of the rule and returns the number of the removed entries
+func DeleteAll(ruleId string) (int, error) {
+	entries, err := List(ruleId)
+	if err != nil {
+		return 0, err
+	}
+	d, err := getDb()
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		if err := d.Delete(e.Id); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), nil
+}
+
+// Register makes the running sink accept the replayed entries
+func Register(ruleId, sink string, f ReplayFunc) {
+	replayers.Store(replayKey(ruleId, sink), f)
+}
+
+// Unregister is called when the sink stops
+func Unregister(ruleId, sink string) {
+	replayers.Delete(replayKey(ruleId, sink))
+}
+
+// Replay sends the entry to the sink of the running rule again. The entry is removed once it is delivered.
+func Replay(ruleId, id string) error {
+	e, err := Get(ruleId, id)
+	if err != nil {
+		return err
+	}
+	f, ok := replayers.Load(replayKey(ruleId, e.Sink))
+	if !ok {
+		return fmt.Errorf("sink %s of rule %s is not running, start the rule to replay the dead letter", e.Sink, ruleId)
+	}
+	if err := f.(ReplayFunc)(e); err != nil {
+		return fmt.Errorf("replay the dead letter %s error: %v", id, err)
+	}
+	d, err := getDb()
+	if err != nil {
+		return err
+	}
+	return d.Delete(id)
+}
+
+func replayKey(ruleId, sink string) string {
+	return ruleId + "/" + sink
+}
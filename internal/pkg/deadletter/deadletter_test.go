@@ -0,0 +1,83 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadletter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/testx"
+)
+
+func init() {
+	testx.InitEnv("deadletter")
+}
+
+func TestDeadLetter(t *testing.T) {
+	require.NoError(t, Save(&Entry{RuleId: "dlRule", Sink: "mqtt_0", Error: "timeout", Timestamp: 2, Raw: []byte("b")}))
+	require.NoError(t, Save(&Entry{RuleId: "dlRule", Sink: "mqtt_0", Error: "timeout", Timestamp: 1, Messages: []map[string]any{{"a": 1.0}}, Props: map[string]string{"{{.topic}}": "t1"}}))
+	require.NoError(t, Save(&Entry{RuleId: "dlOther", Sink: "mqtt_0", Error: "timeout", Timestamp: 3}))
+
+	entries, err := List("dlRule")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(1), entries[0].Timestamp)
+	assert.Equal(t, []map[string]any{{"a": 1.0}}, entries[0].Messages)
+	assert.Equal(t, map[string]string{"{{.topic}}": "t1"}, entries[0].Props)
+	assert.Equal(t, []byte("b"), entries[1].Raw)
+
+	e, err := Get("dlRule", entries[1].Id)
+	require.NoError(t, err)
+	assert.Equal(t, entries[1], e)
+	_, err = Get("dlOther", entries[1].Id)
+	assert.EqualError(t, err, "dead letter "+entries[1].Id+" of rule dlOther is not found")
+
+	// replay without the running sink
+	err = Replay("dlRule", entries[0].Id)
+	assert.EqualError(t, err, "sink mqtt_0 of rule dlRule is not running, start the rule to replay the dead letter")
+
+	var replayed []*Entry
+	fail := true
+	Register("dlRule", "mqtt_0", func(e *Entry) error {
+		if fail {
+			return errors.New("still down")
+		}
+		replayed = append(replayed, e)
+		return nil
+	})
+	defer Unregister("dlRule", "mqtt_0")
+	err = Replay("dlRule", entries[0].Id)
+	assert.EqualError(t, err, "replay the dead letter "+entries[0].Id+" error: still down")
+	_, err = Get("dlRule", entries[0].Id)
+	require.NoError(t, err)
+
+	fail = false
+	require.NoError(t, Replay("dlRule", entries[0].Id))
+	assert.Equal(t, []*Entry{entries[0]}, replayed)
+	_, err = Get("dlRule", entries[0].Id)
+	require.Error(t, err)
+
+	require.NoError(t, Delete("dlRule", entries[1].Id))
+	entries, err = List("dlRule")
+	require.NoError(t, err)
+	assert.Len(t, entries, 0)
+
+	n, err := DeleteAll("dlOther")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
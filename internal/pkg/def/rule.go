@@ -44,6 +44,50 @@ type RuleOption struct {
 	NotifySub                 bool                     `json:"notifySub,omitempty" yaml:"notifySub,omitempty"`
 	DisableBufferFullDiscard  bool                     `json:"disableBufferFullDiscard,omitempty" yaml:"disableBufferFullDiscard,omitempty"`
 	EnableSaveStateBeforeStop bool                     `json:"enableSaveStateBeforeStop,omitempty" yaml:"enableSaveStateBeforeStop,omitempty"`
+	// WindowAlign decides how the time window boundaries are aligned. The value can be natural(default) or epoch
+	WindowAlign string `json:"windowAlign,omitempty" yaml:"windowAlign,omitempty"`
+	// SkipPartialWindow drops the first processing time tumbling window if the rule starts in the middle of it
+	SkipPartialWindow bool `json:"skipPartialWindow,omitempty" yaml:"skipPartialWindow,omitempty"`
+	// GracefulStop drains the in-flight data and the pending windows before stopping or updating the rule
+	GracefulStop bool `json:"gracefulStop,omitempty" yaml:"gracefulStop,omitempty"`
+	// DrainTimeout is the max time to wait for the drain
+	DrainTimeout cast.DurationConf `json:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty"`
+	// WindowFill emits the rows of the groups which have no data in a time window
+	WindowFill *WindowFill `json:"windowFill,omitempty" yaml:"windowFill,omitempty"`
+	// ResourceLimit limits the rows buffered by the rule to avoid starving the other rules
+	ResourceLimit *ResourceLimit `json:"resourceLimit,omitempty" yaml:"resourceLimit,omitempty"`
+	// StrictGroupBy requires the non-aggregate select fields to be the grouping keys of the GROUP BY clause
+	StrictGroupBy bool `json:"strictGroupBy,omitempty" yaml:"strictGroupBy,omitempty"`
+	// SubQuery configures how the IN subqueries materialize the values of the lookup tables
+	SubQuery *SubQueryOption `json:"subQuery,omitempty" yaml:"subQuery,omitempty"`
+	// DistinctCapacity is the max number of the rows remembered by SELECT DISTINCT of the non-window query
+	DistinctCapacity int `json:"distinctCapacity,omitempty" yaml:"distinctCapacity,omitempty"`
+}
+
+// SubQueryOption defines the refresh and memory bound of the materialized values of the IN subqueries
+type SubQueryOption struct {
+	// RefreshInterval is the max age of the values before they are reloaded from the table
+	RefreshInterval cast.DurationConf `json:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty"`
+	// MaxValues is the max number of the distinct values of a subquery
+	MaxValues int `json:"maxValues,omitempty" yaml:"maxValues,omitempty"`
+}
+
+// ResourceLimit defines the limits of the rule state and what to do when a limit is hit
+type ResourceLimit struct {
+	// MaxWindowRows is the max number of the rows buffered by a window. 0 means unlimited
+	MaxWindowRows int `json:"maxWindowRows" yaml:"maxWindowRows"`
+	// Policy decides which row to shed when the limit is hit, the value can be dropNew(default) or dropOldest
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// WindowFill defines how to fill the gaps of the time windows
+type WindowFill struct {
+	// How to fill the fields of the missing rows, the value can be null, zero or locf
+	Fill string `json:"fill" yaml:"fill"`
+	// The output fields which identify a group, usually the group by dimensions
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+	// The enumerated key values of the groups to fill. If not set, all the seen groups are filled
+	KeyValues []map[string]any `json:"keyValues,omitempty" yaml:"keyValues,omitempty"`
 }
 
 type PlanOptimizeStrategy struct {
@@ -162,3 +206,26 @@ const (
 )
 
 type Qos int
+
+const (
+	// WindowAlignNatural aligns the window boundaries to the natural time unit in the local timezone
+	WindowAlignNatural = "natural"
+	// WindowAlignEpoch aligns the window boundaries to the multiples of the window length since the unix epoch
+	WindowAlignEpoch = "epoch"
+)
+
+const (
+	// WindowFillNull fills the missing rows with nil values
+	WindowFillNull = "null"
+	// WindowFillZero fills the missing rows with the zero value of the field type
+	WindowFillZero = "zero"
+	// WindowFillLocf fills the missing rows with the last observation of the group
+	WindowFillLocf = "locf"
+)
+
+const (
+	// LimitPolicyDropNew drops the incoming row when the limit is hit
+	LimitPolicyDropNew = "dropNew"
+	// LimitPolicyDropOldest drops the earliest buffered row to accept the incoming row when the limit is hit
+	LimitPolicyDropOldest = "dropOldest"
+)
@@ -27,6 +27,8 @@ type Config struct {
 	Redis        RedisConfig
 	Sqlite       SqliteConfig
 	Fdb          FdbConfig
+	// The store of the rule checkpoints. If the type is empty, the checkpoints are saved in the store of Type
+	CheckpointStore CheckpointStoreConfig
 }
 
 type RedisConfig struct {
@@ -46,3 +48,19 @@ type FdbConfig struct {
 	APIVersion int
 	Timeout    int64
 }
+
+type CheckpointStoreConfig struct {
+	Type string
+	S3   S3Config
+}
+
+type S3Config struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	Prefix       string
+	UsePathStyle bool
+	Timeout      time.Duration
+}
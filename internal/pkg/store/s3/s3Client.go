@@ -0,0 +1,126 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build s3store || !core
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store/definition"
+)
+
+// objectClient is the object operations used by the checkpoint store
+type objectClient interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns false if the object does not exist
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Copy copies the object in the server side
+	Copy(ctx context.Context, src string, dst string) error
+	Delete(ctx context.Context, key string) error
+	// List returns the keys of all the objects with the prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+type s3Client struct {
+	cli    *s3.Client
+	bucket string
+}
+
+func newS3Client(c definition.S3Config) *s3Client {
+	opts := s3.Options{
+		Region:       c.Region,
+		UsePathStyle: c.UsePathStyle,
+		Credentials:  credentials.NewStaticCredentialsProvider(c.AccessKey, c.SecretKey, ""),
+	}
+	if c.Endpoint != "" {
+		opts.BaseEndpoint = aws.String(c.Endpoint)
+	}
+	return &s3Client{cli: s3.New(opts), bucket: c.Bucket}
+}
+
+func (c *s3Client) Put(ctx context.Context, key string, data []byte) error {
+	_, err := c.cli.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	return err
+}
+
+func (c *s3Client) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	out, err := c.cli.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nk *types.NoSuchKey
+		if errors.As(err, &nk) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (c *s3Client) Copy(ctx context.Context, src string, dst string) error {
+	_, err := c.cli.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(c.bucket),
+		Key:        aws.String(dst),
+		CopySource: aws.String(c.bucket + "/" + url.PathEscape(src)),
+	})
+	return err
+}
+
+func (c *s3Client) Delete(ctx context.Context, key string) error {
+	_, err := c.cli.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (c *s3Client) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	p := s3.NewListObjectsV2Paginator(c.cli, &s3.ListObjectsV2Input{
+		Bucket: aws.String(c.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build s3store || !core
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	kvEncoding "github.com/lf-edge/ekuiper/v2/internal/pkg/store/encoding"
+)
+
+const tmpDir = "tmp/"
+
+var errCorrupted = errors.New("checkpoint is corrupted")
+
+// ts saves each checkpoint as an object named by the zero padded checkpoint id under $prefix/$table/. The object is
+// uploaded to the tmp dir first and then copied to the checkpoint key, so an interrupted upload never overwrites or
+// appears as a checkpoint. Each object ends with the crc32 checksum of the content to detect the broken objects.
+type ts struct {
+	cli     objectClient
+	base    string
+	timeout time.Duration
+	last    int64
+}
+
+func createS3Ts(cli objectClient, prefix string, table string, timeout time.Duration) (*ts, error) {
+	t := &ts{
+		cli:     cli,
+		base:    path.Join(prefix, table) + "/",
+		timeout: timeout,
+	}
+	ctx, cancel := t.context()
+	defer cancel()
+	keys, err := t.listKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list checkpoints of %s error: %v", table, err)
+	}
+	if len(keys) > 0 {
+		t.last = keys[len(keys)-1]
+	}
+	// Remove the temporary objects left by the interrupted uploads
+	if err := t.deleteTemp(ctx); err != nil {
+		return nil, fmt.Errorf("clean temporary checkpoints of %s error: %v", table, err)
+	}
+	return t, nil
+}
+
+func (t *ts) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), t.timeout)
+}
+
+func (t *ts) objectKey(key int64) string {
+	return fmt.Sprintf("%s%020d", t.base, key)
+}
+
+// listKeys returns the sorted checkpoint ids in the store
+func (t *ts) listKeys(ctx context.Context) ([]int64, error) {
+	objects, err := t.cli.List(ctx, t.base)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]int64, 0, len(objects))
+	for _, o := range objects {
+		name := strings.TrimPrefix(o, t.base)
+		if strings.Contains(name, "/") {
+			continue
+		}
+		k, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys, nil
+}
+
+func (t *ts) deleteTemp(ctx context.Context) error {
+	objects, err := t.cli.List(ctx, t.base+tmpDir)
+	if err != nil {
+		return err
+	}
+	for _, o := range objects {
+		if err := t.cli.Delete(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ts) Set(key int64, value interface{}) (bool, error) {
+	if key <= t.last {
+		return false, nil
+	}
+	b, err := kvEncoding.Encode(value)
+	if err != nil {
+		return false, err
+	}
+	data := binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b))
+	ctx, cancel := t.context()
+	defer cancel()
+	tmp := fmt.Sprintf("%s%s%020d-%d", t.base, tmpDir, key, time.Now().UnixNano())
+	if err := t.cli.Put(ctx, tmp, data); err != nil {
+		_ = t.cli.Delete(ctx, tmp)
+		return false, fmt.Errorf("upload checkpoint %d error: %v", key, err)
+	}
+	err = t.cli.Copy(ctx, tmp, t.objectKey(key))
+	_ = t.cli.Delete(ctx, tmp)
+	if err != nil {
+		return false, fmt.Errorf("commit checkpoint %d error: %v", key, err)
+	}
+	t.last = key
+	return true, nil
+}
+
+func (t *ts) Get(key int64, value interface{}) (bool, error) {
+	ctx, cancel := t.context()
+	defer cancel()
+	data, ok, err := t.cli.Get(ctx, t.objectKey(key))
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := decode(data, value); err != nil {
+		return false, fmt.Errorf("read checkpoint %d error: %w", key, err)
+	}
+	return true, nil
+}
+
+// Last returns the latest checkpoint which is complete. The broken checkpoints are skipped so that the rule can recover
+// from the previous one.
+func (t *ts) Last(value interface{}) (int64, error) {
+	ctx, cancel := t.context()
+	defer cancel()
+	keys, err := t.listKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		ok, err := t.Get(keys[i], value)
+		if errors.Is(err, errCorrupted) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return keys[i], nil
+		}
+	}
+	return 0, nil
+}
+
+func (t *ts) Delete(key int64) error {
+	ctx, cancel := t.context()
+	defer cancel()
+	return t.cli.Delete(ctx, t.objectKey(key))
+}
+
+func (t *ts) DeleteBefore(key int64) error {
+	ctx, cancel := t.context()
+	defer cancel()
+	keys, err := t.listKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if k >= key {
+			break
+		}
+		if err := t.cli.Delete(ctx, t.objectKey(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *ts) Close() error {
+	return nil
+}
+
+func (t *ts) Drop() error {
+	ctx, cancel := t.context()
+	defer cancel()
+	objects, err := t.cli.List(ctx, t.base)
+	if err != nil {
+		return err
+	}
+	for _, o := range objects {
+		if err := t.cli.Delete(ctx, o); err != nil {
+			return err
+		}
+	}
+	t.last = 0
+	return nil
+}
+
+func decode(data []byte, value interface{}) error {
+	if len(data) < 4 {
+		return errCorrupted
+	}
+	b := data[:len(data)-4]
+	if binary.BigEndian.Uint32(data[len(data)-4:]) != crc32.ChecksumIEEE(b) {
+		return errCorrupted
+	}
+	return gob.NewDecoder(bytes.NewBuffer(b)).Decode(value)
+}
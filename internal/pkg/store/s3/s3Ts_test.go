@@ -0,0 +1,221 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build s3store || !core
+
+package s3
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store/test/common"
+)
+
+// memClient is the in memory object storage. The failures can be injected for each operation.
+type memClient struct {
+	sync.Mutex
+	objects map[string][]byte
+	// If set, the Put uploads only half of the data and returns the error
+	putErr    error
+	copyErr   error
+	deleteErr error
+}
+
+func newMemClient() *memClient {
+	return &memClient{objects: make(map[string][]byte)}
+}
+
+func (c *memClient) Put(_ context.Context, key string, data []byte) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.putErr != nil {
+		c.objects[key] = data[:len(data)/2]
+		return c.putErr
+	}
+	c.objects[key] = data
+	return nil
+}
+
+func (c *memClient) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.Lock()
+	defer c.Unlock()
+	data, ok := c.objects[key]
+	return data, ok, nil
+}
+
+func (c *memClient) Copy(_ context.Context, src string, dst string) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.copyErr != nil {
+		return c.copyErr
+	}
+	data, ok := c.objects[src]
+	if !ok {
+		return errors.New("no such key")
+	}
+	c.objects[dst] = data
+	return nil
+}
+
+func (c *memClient) Delete(_ context.Context, key string) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.deleteErr != nil {
+		return c.deleteErr
+	}
+	delete(c.objects, key)
+	return nil
+}
+
+func (c *memClient) List(_ context.Context, prefix string) ([]string, error) {
+	c.Lock()
+	defer c.Unlock()
+	var keys []string
+	for k := range c.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (c *memClient) keys() []string {
+	keys, _ := c.List(context.Background(), "")
+	return keys
+}
+
+func setupS3Ts(t *testing.T, cli objectClient) *ts {
+	ks, err := createS3Ts(cli, "ckpt", "rule1", time.Second)
+	require.NoError(t, err)
+	return ks
+}
+
+func TestS3TsSet(t *testing.T) {
+	common.TestTsSet(setupS3Ts(t, newMemClient()), t)
+}
+
+func TestS3TsLast(t *testing.T) {
+	common.TestTsLast(setupS3Ts(t, newMemClient()), t)
+}
+
+func TestS3TsGet(t *testing.T) {
+	common.TestTsGet(setupS3Ts(t, newMemClient()), t)
+}
+
+func TestS3TsDelete(t *testing.T) {
+	common.TestTsDelete(setupS3Ts(t, newMemClient()), t)
+}
+
+func TestS3TsDeleteBefore(t *testing.T) {
+	common.TestTsDeleteBefore(setupS3Ts(t, newMemClient()), t)
+}
+
+func TestS3TsRecover(t *testing.T) {
+	cli := newMemClient()
+	ks := setupS3Ts(t, cli)
+	for i, k := range []int64{1000, 2000} {
+		ok, err := ks.Set(k, map[string]any{"ci": i})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	assert.Equal(t, []string{"ckpt/rule1/00000000000000001000", "ckpt/rule1/00000000000000002000"}, cli.keys())
+	// A new instance after the container replacement
+	ks = setupS3Ts(t, cli)
+	var v map[string]any
+	k, err := ks.Last(&v)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), k)
+	assert.Equal(t, map[string]any{"ci": 1}, v)
+	ok, err := ks.Set(1500, "old")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, ks.Drop())
+	assert.Empty(t, cli.keys())
+}
+
+func TestS3TsPartialUpload(t *testing.T) {
+	cli := newMemClient()
+	ks := setupS3Ts(t, cli)
+	_, err := ks.Set(1000, "bar1")
+	require.NoError(t, err)
+	// The upload is interrupted, and the temp object cannot be removed
+	cli.putErr = errors.New("connection reset")
+	cli.deleteErr = errors.New("connection reset")
+	_, err = ks.Set(2000, "bar2")
+	assert.EqualError(t, err, "upload checkpoint 2000 error: connection reset")
+	keys := cli.keys()
+	require.Len(t, keys, 2)
+	assert.True(t, strings.HasPrefix(keys[1], "ckpt/rule1/tmp/00000000000000002000-"))
+	// The partial upload is never seen as a checkpoint
+	var v string
+	k, err := ks.Last(&v)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), k)
+	assert.Equal(t, "bar1", v)
+	found, err := ks.Get(2000, &v)
+	require.NoError(t, err)
+	assert.False(t, found)
+	// The temp object is removed after restart
+	cli.putErr = nil
+	cli.deleteErr = nil
+	ks = setupS3Ts(t, cli)
+	assert.Equal(t, []string{"ckpt/rule1/00000000000000001000"}, cli.keys())
+	ok, err := ks.Set(2000, "bar2")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestS3TsCommitFail(t *testing.T) {
+	cli := newMemClient()
+	ks := setupS3Ts(t, cli)
+	cli.copyErr = errors.New("internal error")
+	_, err := ks.Set(1000, "bar1")
+	assert.EqualError(t, err, "commit checkpoint 1000 error: internal error")
+	assert.Empty(t, cli.keys())
+	// Retry the same checkpoint
+	cli.copyErr = nil
+	ok, err := ks.Set(1000, "bar1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestS3TsCorrupted(t *testing.T) {
+	cli := newMemClient()
+	ks := setupS3Ts(t, cli)
+	for _, k := range []int64{1000, 2000} {
+		_, err := ks.Set(k, "bar")
+		require.NoError(t, err)
+	}
+	// Truncate the latest checkpoint as if the storage does not write atomically
+	key := "ckpt/rule1/00000000000000002000"
+	cli.objects[key] = cli.objects[key][:3]
+	var v string
+	_, err := ks.Get(2000, &v)
+	assert.ErrorIs(t, err, errCorrupted)
+	k, err := ks.Last(&v)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), k)
+	assert.Equal(t, "bar", v)
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build s3store || !core
+
+package s3
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store/definition"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+)
+
+type TsBuilder struct {
+	cli     objectClient
+	prefix  string
+	timeout time.Duration
+}
+
+func (b TsBuilder) CreateTs(table string) (kv.Tskv, error) {
+	return createS3Ts(b.cli, b.prefix, table, b.timeout)
+}
+
+// BuildCheckpointStore builds the store to save the rule checkpoints into the S3 compatible object storage
+func BuildCheckpointStore(c definition.Config) (definition.TsBuilder, error) {
+	sc := c.CheckpointStore.S3
+	if sc.Bucket == "" {
+		return nil, errors.New("s3 checkpoint store bucket is required")
+	}
+	if sc.AccessKey == "" || sc.SecretKey == "" {
+		return nil, errors.New("s3 checkpoint store accessKey and secretKey are required")
+	}
+	if sc.Region == "" {
+		sc.Region = "us-east-1"
+	}
+	if sc.Timeout <= 0 {
+		sc.Timeout = 10 * time.Second
+	}
+	return TsBuilder{
+		cli:     newS3Client(sc),
+		prefix:  strings.Trim(sc.Prefix, "/"),
+		timeout: sc.Timeout,
+	}, nil
+}
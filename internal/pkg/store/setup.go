@@ -27,6 +27,8 @@ type StoreConf struct {
 	RedisConfig  definition.RedisConfig
 	SqliteConfig definition.SqliteConfig
 	FdbConfig    definition.FdbConfig
+	// CheckpointConfig is the store of the rule checkpoints
+	CheckpointConfig definition.CheckpointStoreConfig
 }
 
 func SetupDefault(dataDir string) error {
@@ -51,6 +53,8 @@ func SetupWithConfig(sc *StoreConf) error {
 		Redis:        sc.RedisConfig,
 		Sqlite:       sc.SqliteConfig,
 		Fdb:          sc.FdbConfig,
+
+		CheckpointStore: sc.CheckpointConfig,
 	}
 	return Setup(c)
 }
@@ -71,6 +75,14 @@ func Setup(config definition.Config) error {
 		return err
 	}
 	extStateStores = s
+	checkpointStores = nil
+	if config.CheckpointStore.Type != "" {
+		s, err = newCheckpointStores(config)
+		if err != nil {
+			return err
+		}
+		checkpointStores = s
+	}
 	db, err := sqldb.BuildSqliteStore(config, "trace.db")
 	if err != nil {
 		return err
@@ -27,6 +27,8 @@ import (
 
 type StoreCreator func(conf definition.Config, name string) (definition.StoreBuilder, definition.TsBuilder, error)
 
+type CheckpointStoreCreator func(conf definition.Config) (definition.TsBuilder, error)
+
 var (
 	storeBuilders = map[string]StoreCreator{
 		"sqlite": sql.BuildStores,
@@ -34,6 +36,10 @@ var (
 	globalStores   *stores = nil
 	cacheStores    *stores = nil
 	extStateStores *stores = nil
+	// The store of the checkpoints if it is different from the global stores
+	checkpointStores *stores = nil
+	// The creators of the stores which only save the checkpoints
+	checkpointBuilders = map[string]CheckpointStoreCreator{}
 
 	TraceStores sql.Database
 )
@@ -86,6 +92,23 @@ func newExtStateStores(c definition.Config, name string) (*stores, error) {
 	}
 }
 
+func newCheckpointStores(c definition.Config) (*stores, error) {
+	storeType := c.CheckpointStore.Type
+	if builder, ok := checkpointBuilders[storeType]; ok {
+		tsBuilder, err := builder(c)
+		if err != nil {
+			return nil, err
+		}
+		return &stores{
+			ts:        make(map[string]kv.Tskv),
+			mu:        sync.Mutex{},
+			tsBuilder: tsBuilder,
+		}, nil
+	} else {
+		return nil, fmt.Errorf("unknown checkpointStore type: %s", storeType)
+	}
+}
+
 func (s *stores) GetKV(table string) (kv.KeyValue, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -168,6 +191,22 @@ func DropTS(table string) error {
 	return nil
 }
 
+// GetCheckpointTS returns the store to save the checkpoints of a rule
+func GetCheckpointTS(table string) (kv.Tskv, error) {
+	if checkpointStores != nil {
+		return checkpointStores.GetTS(table)
+	}
+	return GetTS(table)
+}
+
+func DropCheckpointTS(table string) error {
+	if checkpointStores != nil {
+		checkpointStores.DropTS(table)
+		return nil
+	}
+	return DropTS(table)
+}
+
 func DropKV(table string) error {
 	if globalStores == nil {
 		return fmt.Errorf("global stores are not initialized")
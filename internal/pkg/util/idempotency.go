@@ -0,0 +1,52 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+// IdempotencyKey returns the key to identify the data when it is replayed. If the field is set, the value of the field
+// is used. Otherwise, the key is the sha256 hash of the json encoded data whose map keys are sorted, so the same data
+// always has the same key.
+func IdempotencyKey(data map[string]any, field string) (string, error) {
+	if field != "" {
+		v, ok := data[field]
+		if !ok || v == nil {
+			return "", fmt.Errorf("dedup field %s does not exist in data %v", field, data)
+		}
+		s, err := cast.ToString(v, cast.CONVERT_ALL)
+		if err != nil {
+			return "", fmt.Errorf("dedup field %s must be convertible to string, but got %v", field, v)
+		}
+		return s, nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("fail to hash the data for dedup: %v", err)
+	}
+	return HashKey(b), nil
+}
+
+// HashKey returns the hex encoded sha256 hash of the payload
+func HashKey(payload []byte) string {
+	h := sha256.Sum256(payload)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	k, err := IdempotencyKey(map[string]any{"id": 12, "a": 1}, "id")
+	require.NoError(t, err)
+	assert.Equal(t, "12", k)
+	_, err = IdempotencyKey(map[string]any{"a": 1}, "id")
+	assert.EqualError(t, err, "dedup field id does not exist in data map[a:1]")
+
+	k1, err := IdempotencyKey(map[string]any{"a": 1, "b": "x", "c": map[string]any{"d": 1.5, "e": true}}, "")
+	require.NoError(t, err)
+	k2, err := IdempotencyKey(map[string]any{"c": map[string]any{"e": true, "d": 1.5}, "b": "x", "a": 1}, "")
+	require.NoError(t, err)
+	assert.Equal(t, k1, k2)
+	assert.Len(t, k1, 64)
+	k3, err := IdempotencyKey(map[string]any{"a": 2, "b": "x", "c": map[string]any{"d": 1.5, "e": true}}, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, k1, k3)
+}
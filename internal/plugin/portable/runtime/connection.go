@@ -250,6 +250,28 @@ func CreateSinkAckChannel(ctx api.StreamContext) (DataInChannel, error) {
 	return sock, nil
 }
 
+// CreateSourceAckChannel creates the channel to acknowledge the received data to the source plugin. The plugin waits
+// for the ack before sending the next data, so that the plugin can be back pressured.
+func CreateSourceAckChannel(ctx api.StreamContext) (DataOutChannel, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = push.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new push socket: %s", err)
+	}
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionSendDeadline: conf.Config.Portable.SendTimeout,
+		mangos.OptionMaxRecvSize:  0,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_ack.ipc", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if err = listenWithRetry(sock, url); err != nil {
+		return nil, fmt.Errorf("can't listen on push socket for %s: %s", url, err.Error())
+	}
+	conf.Log.Infof("source ack channel created: %s", url)
+	return sock, nil
+}
+
 func CreateControlChannel(pluginName string) (ControlChannel, error) {
 	failpoint.Inject("CreateControlChannelErr", func() {
 		failpoint.Return(nil, errors.New("CreateControlChannelErr"))
@@ -239,6 +239,36 @@ func TestDataOut(t *testing.T) {
 	}
 }
 
+func TestSourceAck(t *testing.T) {
+	ctx := context.DefaultContext{}
+	sctx := ctx.WithMeta("rule1", "op1", &state.MemoryStore{}).WithInstance(1)
+	ch, err := CreateSourceAckChannel(sctx)
+	if err != nil {
+		t.Fatalf("create channel error %v", err)
+	}
+	defer ch.Close()
+	client, err := createMockSourceAckChannel(sctx)
+	if err != nil {
+		t.Fatalf("create client error %v", err)
+	}
+	defer client.Close()
+	for c := 0; c < 3; c++ {
+		err := ch.Send(sourceAck)
+		if err != nil {
+			t.Errorf("send ack %d error %v", c, err)
+			return
+		}
+		msg, err := client.Recv()
+		if err != nil {
+			t.Errorf("receive ack %d error %v", c, err)
+			return
+		}
+		if !reflect.DeepEqual(msg, sourceAck) {
+			t.Errorf("receive %s but expect %s", msg, sourceAck)
+		}
+	}
+}
+
 type mockControlClient struct {
 	sock mangos.Socket
 }
@@ -318,3 +348,21 @@ func createMockSinkChannel(ctx api.StreamContext) (mangos.Socket, error) {
 	}
 	return sock, nil
 }
+
+func createMockSourceAckChannel(ctx api.StreamContext) (mangos.Socket, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = pull.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new pull socket: %s", err)
+	}
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionRecvDeadline: 1000 * time.Millisecond,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_ack.ipc", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if err = sock.Dial(url); err != nil {
+		return nil, fmt.Errorf("can't dial on pull socket: %s", err.Error())
+	}
+	return sock, nil
+}
@@ -176,6 +176,13 @@ func (i *PluginIns) Stop() error {
 	return err
 }
 
+// IsRunning returns false if the plugin process exits unintentionally
+func (i *PluginIns) IsRunning() bool {
+	i.RLock()
+	defer i.RUnlock()
+	return i.process != nil
+}
+
 func (i *PluginIns) GetStatus() *PluginStatus {
 	i.RLock()
 	defer i.RUnlock()
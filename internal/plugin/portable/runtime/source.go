@@ -22,32 +22,55 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"go.nanomsg.org/mangos/v3"
 
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 // Error handling: wrap all error in a function to handle
 
+type sourceConf struct {
+	// RequireAck makes the plugin wait for the ack of each sent data
+	RequireAck bool `json:"requireAck"`
+}
+
+// PortableSource receives the data pushed by the plugin asynchronously. The plugin sends the data or error message to
+// the data channel as they arrive. If the plugin process exits unexpectedly, the source restarts the plugin and the
+// symbol is started again by replaying the start command.
 type PortableSource struct {
 	symbolName string
 	reg        *PluginMeta
 	clean      func() error
 	dataCh     DataInChannel
+	ackCh      DataOutChannel
+	c          *sourceConf
+	ins        *PluginIns
+	sch        api.StatusChangeHandler
 
 	topic string
 	props map[string]any
 }
 
+// messageWrapper is the message sent by the plugin. It is either a data message with message and meta or an error message.
 type messageWrapper struct {
 	Message map[string]any `json:"message"`
 	Meta    map[string]any `json:"meta"`
+	Error   string         `json:"error"`
 }
 
+var sourceAck = []byte("{}")
+
 func (ps *PortableSource) Provision(ctx api.StreamContext, configs map[string]any) error {
 	ps.props = configs
+	c := &sourceConf{}
+	err := cast.MapToStruct(configs, c)
+	if err != nil {
+		return err
+	}
+	ps.c = c
 	return nil
 }
 
-func (ps *PortableSource) Connect(ctx api.StreamContext, _ api.StatusChangeHandler) error {
+func (ps *PortableSource) Connect(ctx api.StreamContext, sch api.StatusChangeHandler) error {
 	ctx.GetLogger().Infof("Start running portable source %s with datasource %s and conf %+v", ps.symbolName, ps.topic, ps.props)
 	pm := GetPluginInsManager()
 	ins, err := pm.GetOrStartProcess(ps.reg, PortbleConf)
@@ -61,6 +84,14 @@ func (ps *PortableSource) Connect(ctx api.StreamContext, _ api.StatusChangeHandl
 	if err != nil {
 		return err
 	}
+	var ackCh DataOutChannel
+	if ps.c != nil && ps.c.RequireAck {
+		ackCh, err = CreateSourceAckChannel(ctx)
+		if err != nil {
+			_ = dataCh.Close()
+			return err
+		}
+	}
 
 	// Control: send message to plugin to ask starting symbol
 	c := &Control{
@@ -78,20 +109,36 @@ func (ps *PortableSource) Connect(ctx api.StreamContext, _ api.StatusChangeHandl
 	if err != nil {
 		ctx.GetLogger().Error(err)
 		_ = dataCh.Close()
+		if ackCh != nil {
+			_ = ackCh.Close()
+		}
 		return err
 	}
 	ps.dataCh = dataCh
+	ps.ackCh = ackCh
+	ps.ins = ins
+	ps.sch = sch
 	ps.clean = func() error {
 		ctx.GetLogger().Info("clean up source")
 		err1 := dataCh.Close()
-		err2 := ins.StopSymbol(ctx, c)
+		err2 := ps.ins.StopSymbol(ctx, c)
+		var err3 error
+		if ackCh != nil {
+			err3 = ackCh.Close()
+		}
 		if err1 != nil {
 			err1 = fmt.Errorf("%s:%v", "dataCh", err1)
 		}
 		if err2 != nil {
 			err2 = fmt.Errorf("%s:%v", "symbol", err2)
 		}
-		return errors.Join(err1, err2)
+		if err3 != nil {
+			err3 = fmt.Errorf("%s:%v", "ackCh", err3)
+		}
+		return errors.Join(err1, err2, err3)
+	}
+	if sch != nil {
+		sch(api.ConnectionConnected, "")
 	}
 	return nil
 }
@@ -107,6 +154,7 @@ func (ps *PortableSource) Subscribe(ctx api.StreamContext, ingest api.TupleInges
 			return nil
 		case mangos.ErrRecvTimeout:
 			ctx.GetLogger().Debug("source receive timeout, retry")
+			ps.checkPlugin(ctx)
 		case nil:
 			// do nothing
 		default:
@@ -126,14 +174,52 @@ func (ps *PortableSource) Subscribe(ctx api.StreamContext, ingest api.TupleInges
 					e = fmt.Errorf("Invalid data format, cannot decode %s to json format with error %s", string(msg), e)
 					ctx.GetLogger().Error(e)
 					ingestError(ctx, e)
+					ps.ack(ctx)
 					continue
 				}
-				ingest(ctx, result.Message, result.Meta, rcvTime)
+				if result.Error != "" {
+					ingestError(ctx, errors.New(result.Error))
+				} else {
+					ingest(ctx, result.Message, result.Meta, rcvTime)
+				}
+				ps.ack(ctx)
 			}
 		}
 	}
 }
 
+// ack informs the plugin that the data is ingested so that it can send the next one
+func (ps *PortableSource) ack(ctx api.StreamContext) {
+	if ps.ackCh == nil {
+		return
+	}
+	if err := ps.ackCh.Send(sourceAck); err != nil {
+		ctx.GetLogger().Warnf("send ack to source plugin %s error: %v", ps.symbolName, err)
+	}
+}
+
+// checkPlugin restarts the plugin process if it exits. The start commands of the running symbols are replayed after
+// the restart, so the plugin will resubscribe and reconnect to the data channel.
+func (ps *PortableSource) checkPlugin(ctx api.StreamContext) {
+	if ps.ins == nil || ps.ins.IsRunning() {
+		return
+	}
+	ctx.GetLogger().Warnf("plugin %s of source %s exits, restarting", ps.reg.Name, ps.symbolName)
+	if ps.sch != nil {
+		ps.sch(api.ConnectionDisconnected, fmt.Sprintf("plugin %s exits", ps.reg.Name))
+	}
+	ins, err := GetPluginInsManager().GetOrStartProcess(ps.reg, PortbleConf)
+	if err != nil {
+		ctx.GetLogger().Errorf("restart plugin %s error: %v", ps.reg.Name, err)
+		return
+	}
+	ps.ins = ins
+	ctx.GetLogger().Infof("plugin %s restarted", ps.reg.Name)
+	if ps.sch != nil {
+		ps.sch(api.ConnectionConnected, "")
+	}
+}
+
 func NewPortableSource(symbolName string, reg *PluginMeta) *PortableSource {
 	return &PortableSource{
 		symbolName: symbolName,
@@ -261,7 +261,7 @@ func (p *RuleProcessor) ExecDrop(name string) error {
 }
 
 func cleanCheckpoint(name string) error {
-	err := store.DropTS(name)
+	err := store.DropCheckpointTS(name)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,247 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+	"github.com/lf-edge/ekuiper/v2/pkg/validate"
+)
+
+// placeholderRegex matches the ${param} placeholders in the template
+var placeholderRegex = regexp.MustCompile(`\$\{(\w+)}`)
+
+// RuleTemplate is a rule definition with ${param} placeholders in its string values
+type RuleTemplate struct {
+	Id string `json:"id"`
+	// Params are the default values of the parameters
+	Params map[string]any `json:"params,omitempty"`
+	Rule   map[string]any `json:"rule"`
+}
+
+// RuleTemplateInstance records the template and the parameters of a rule instantiated from the template
+type RuleTemplateInstance struct {
+	TemplateId string         `json:"templateId"`
+	Params     map[string]any `json:"params"`
+}
+
+type RuleTemplateProcessor struct {
+	db         kv.KeyValue
+	instanceDb kv.KeyValue
+}
+
+func NewRuleTemplateProcessor() *RuleTemplateProcessor {
+	db, err := store.GetKV("ruleTemplate")
+	if err != nil {
+		panic(fmt.Sprintf("Can not initialize store for the rule template processor at path 'ruleTemplate': %v", err))
+	}
+	instanceDb, err := store.GetKV("ruleTemplateInstance")
+	if err != nil {
+		panic(fmt.Sprintf("Can not initialize store for the rule template processor at path 'ruleTemplateInstance': %v", err))
+	}
+	return &RuleTemplateProcessor{
+		db:         db,
+		instanceDb: instanceDb,
+	}
+}
+
+// ExecCreate validates and saves the template, returns the template id
+func (p *RuleTemplateProcessor) ExecCreate(tplJson string) (string, error) {
+	tpl, err := parseRuleTemplate("", tplJson)
+	if err != nil {
+		return "", err
+	}
+	if err := p.db.Setnx(tpl.Id, tplJson); err != nil {
+		return "", fmt.Errorf("Rule template %s already exists.", tpl.Id)
+	}
+	return tpl.Id, nil
+}
+
+// ExecUpdate replaces the template. The rules instantiated from it are not changed.
+func (p *RuleTemplateProcessor) ExecUpdate(id, tplJson string) (*RuleTemplate, error) {
+	if _, err := p.GetTemplate(id); err != nil {
+		return nil, err
+	}
+	tpl, err := parseRuleTemplate(id, tplJson)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.db.Set(id, tplJson); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+func (p *RuleTemplateProcessor) ExecDrop(id string) error {
+	if err := p.db.Delete(id); err != nil {
+		return errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("Rule template %s is not found.", id))
+	}
+	return nil
+}
+
+func (p *RuleTemplateProcessor) GetTemplateJson(id string) (string, error) {
+	var s string
+	f, _ := p.db.Get(id, &s)
+	if !f {
+		return "", errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("Rule template %s is not found.", id))
+	}
+	return s, nil
+}
+
+func (p *RuleTemplateProcessor) GetTemplate(id string) (*RuleTemplate, error) {
+	s, err := p.GetTemplateJson(id)
+	if err != nil {
+		return nil, err
+	}
+	return parseRuleTemplate(id, s)
+}
+
+func (p *RuleTemplateProcessor) GetAllTemplates() ([]string, error) {
+	return p.db.Keys()
+}
+
+// SaveInstance records the rule is instantiated from the template with the params
+func (p *RuleTemplateProcessor) SaveInstance(ruleId, tplId string, params map[string]any) error {
+	b, err := json.Marshal(&RuleTemplateInstance{TemplateId: tplId, Params: params})
+	if err != nil {
+		return err
+	}
+	return p.instanceDb.Set(ruleId, string(b))
+}
+
+// DropInstance removes the template record of the rule. It is a no-op if the rule is not instantiated from a template.
+func (p *RuleTemplateProcessor) DropInstance(ruleId string) {
+	_ = p.instanceDb.Delete(ruleId)
+}
+
+// GetInstances returns the rules instantiated from the template with their params
+func (p *RuleTemplateProcessor) GetInstances(tplId string) (map[string]map[string]any, error) {
+	all, err := p.instanceDb.All()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]map[string]any)
+	for ruleId, s := range all {
+		ins := &RuleTemplateInstance{}
+		if err := json.Unmarshal(cast.StringToBytes(s), ins); err != nil {
+			return nil, fmt.Errorf("parse the template instance of rule %s error: %v", ruleId, err)
+		}
+		if ins.TemplateId == tplId {
+			result[ruleId] = ins.Params
+		}
+	}
+	return result, nil
+}
+
+func parseRuleTemplate(id, tplJson string) (*RuleTemplate, error) {
+	tpl := &RuleTemplate{Id: id}
+	if err := json.Unmarshal(cast.StringToBytes(tplJson), tpl); err != nil {
+		return nil, fmt.Errorf("Parse rule template %s error : %s.", tplJson, err)
+	}
+	if tpl.Id == "" {
+		return nil, fmt.Errorf("Missing rule template id.")
+	}
+	if id != "" && id != tpl.Id {
+		return nil, fmt.Errorf("Rule template id is not consistent.")
+	}
+	if err := validate.ValidateID(tpl.Id); err != nil {
+		return nil, err
+	}
+	if len(tpl.Rule) == 0 {
+		return nil, fmt.Errorf("Missing the rule of rule template %s.", tpl.Id)
+	}
+	return tpl, nil
+}
+
+// Instantiate replaces the placeholders of the rule by the params or the default params, then returns the rule id and
+// the rule json. If a string value is exactly a placeholder, it is replaced by the param value with its type, so that
+// the numeric options could be parameterized too.
+func (t *RuleTemplate) Instantiate(params map[string]any) (string, string, error) {
+	merged := make(map[string]any, len(t.Params)+len(params))
+	for k, v := range t.Params {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	var missing map[string]struct{}
+	r := instantiateValue(t.Rule, merged, func(name string) {
+		if missing == nil {
+			missing = make(map[string]struct{})
+		}
+		missing[name] = struct{}{}
+	})
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", "", fmt.Errorf("missing parameters %v of rule template %s", names, t.Id)
+	}
+	ruleMap := r.(map[string]any)
+	ruleId, _ := ruleMap["id"].(string)
+	if ruleId == "" {
+		return "", "", fmt.Errorf("missing the rule id of rule template %s, set the id in the rule or by a parameter", t.Id)
+	}
+	b, err := json.Marshal(ruleMap)
+	if err != nil {
+		return "", "", err
+	}
+	return ruleId, string(b), nil
+}
+
+func instantiateValue(v any, params map[string]any, onMissing func(string)) any {
+	switch vt := v.(type) {
+	case string:
+		if m := placeholderRegex.FindStringSubmatch(vt); m != nil && m[0] == vt {
+			p, ok := params[m[1]]
+			if !ok {
+				onMissing(m[1])
+			}
+			return p
+		}
+		return placeholderRegex.ReplaceAllStringFunc(vt, func(s string) string {
+			name := s[2 : len(s)-1]
+			p, ok := params[name]
+			if !ok {
+				onMissing(name)
+				return s
+			}
+			return cast.ToStringAlways(p)
+		})
+	case map[string]any:
+		r := make(map[string]any, len(vt))
+		for k, e := range vt {
+			r[k] = instantiateValue(e, params, onMissing)
+		}
+		return r
+	case []any:
+		r := make([]any, len(vt))
+		for i, e := range vt {
+			r[i] = instantiateValue(e, params, onMissing)
+		}
+		return r
+	default:
+		return v
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuleTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		json string
+		err  string
+	}{
+		{
+			name: "missing id",
+			json: `{"rule":{"id":"r1"}}`,
+			err:  "Missing rule template id.",
+		},
+		{
+			name: "inconsistent id",
+			id:   "tpl2",
+			json: `{"id":"tpl1","rule":{"id":"r1"}}`,
+			err:  "Rule template id is not consistent.",
+		},
+		{
+			name: "missing rule",
+			json: `{"id":"tpl1"}`,
+			err:  "Missing the rule of rule template tpl1.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRuleTemplate(tt.id, tt.json)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRuleTemplateInstantiate(t *testing.T) {
+	tpl, err := parseRuleTemplate("", `{
+		"id": "tpl1",
+		"params": {"threshold": 30, "topic": "alert"},
+		"rule": {
+			"id": "rule_${device}",
+			"sql": "SELECT * FROM demo WHERE device = \"${device}\" AND temperature > ${threshold}",
+			"actions": [{"mqtt": {"server": "tcp://127.0.0.1:1883", "topic": "${topic}/${device}"}}],
+			"options": {"bufferLength": "${bufferLength}"}
+		}
+	}`)
+	require.NoError(t, err)
+	id, ruleJson, err := tpl.Instantiate(map[string]any{"device": "d1", "bufferLength": 512, "threshold": 40.5})
+	require.NoError(t, err)
+	assert.Equal(t, "rule_d1", id)
+	assert.JSONEq(t, `{
+		"id": "rule_d1",
+		"sql": "SELECT * FROM demo WHERE device = \"d1\" AND temperature > 40.5",
+		"actions": [{"mqtt": {"server": "tcp://127.0.0.1:1883", "topic": "alert/d1"}}],
+		"options": {"bufferLength": 512}
+	}`, ruleJson)
+	_, _, err = tpl.Instantiate(map[string]any{})
+	assert.EqualError(t, err, "missing parameters [bufferLength device] of rule template tpl1")
+
+	tpl, err = parseRuleTemplate("", `{"id":"tpl2","rule":{"sql":"SELECT * FROM demo"}}`)
+	require.NoError(t, err)
+	_, _, err = tpl.Instantiate(nil)
+	assert.EqualError(t, err, "missing the rule id of rule template tpl2, set the id in the rule or by a parameter")
+}
+
+func TestRuleTemplateInstances(t *testing.T) {
+	p := NewRuleTemplateProcessor()
+	require.NoError(t, p.SaveInstance("r1", "tplInstance", map[string]any{"device": "d1"}))
+	require.NoError(t, p.SaveInstance("r2", "tplInstance", map[string]any{"device": "d2"}))
+	require.NoError(t, p.SaveInstance("r3", "tplOther", map[string]any{"device": "d3"}))
+	p.DropInstance("r2")
+	instances, err := p.GetInstances("tplInstance")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]any{"r1": {"device": "d1"}}, instances)
+	p.DropInstance("r1")
+	p.DropInstance("r3")
+}
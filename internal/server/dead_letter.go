@@ -0,0 +1,114 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/deadletter"
+)
+
+// deadLetterResult is the result of a dead letter replayed by the api
+type deadLetterResult struct {
+	Id    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// deadLettersHandler lists or deletes all the dead letters of the rule
+func deadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	name := mux.Vars(r)["name"]
+	switch r.Method {
+	case http.MethodGet:
+		entries, err := deadletter.List(name)
+		if err != nil {
+			handleError(w, err, "Show dead letters error", logger)
+			return
+		}
+		jsonResponse(entries, w, logger)
+	case http.MethodDelete:
+		n, err := deadletter.DeleteAll(name)
+		if err != nil {
+			handleError(w, err, "Delete dead letters error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "%d dead letters of rule %s are deleted.", n, name)
+	}
+}
+
+func deadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	vars := mux.Vars(r)
+	name, id := vars["name"], vars["id"]
+	switch r.Method {
+	case http.MethodGet:
+		e, err := deadletter.Get(name, id)
+		if err != nil {
+			handleError(w, err, "Describe dead letter error", logger)
+			return
+		}
+		jsonResponse(e, w, logger)
+	case http.MethodDelete:
+		if err := deadletter.Delete(name, id); err != nil {
+			handleError(w, err, "Delete dead letter error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "Dead letter %s is deleted.", id)
+	}
+}
+
+// replayDeadLetterHandler sends the dead letter to the sink of the running rule again
+func replayDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	vars := mux.Vars(r)
+	name, id := vars["name"], vars["id"]
+	if err := deadletter.Replay(name, id); err != nil {
+		handleError(w, err, "Replay dead letter error", logger)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "Dead letter %s is replayed.", id)
+}
+
+// replayDeadLettersHandler replays all the dead letters of the rule in the order of failure. The replayed ones are
+// removed and the failed ones are kept with the errors in the results.
+func replayDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	name := mux.Vars(r)["name"]
+	entries, err := deadletter.List(name)
+	if err != nil {
+		handleError(w, err, "Replay dead letters error", logger)
+		return
+	}
+	results := make([]deadLetterResult, 0, len(entries))
+	code := http.StatusOK
+	for _, e := range entries {
+		res := deadLetterResult{Id: e.Id}
+		if err := deadletter.Replay(name, e.Id); err != nil {
+			res.Error = err.Error()
+			code = http.StatusBadRequest
+		}
+		results = append(results, res)
+	}
+	w.Header().Add(ContentType, ContentTypeJSON)
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(results)
+}
@@ -190,12 +190,20 @@ func createRestServer(ip string, port int, needToken bool) *http.Server {
 	r.HandleFunc("/rules/{name}/stop", stopRuleHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/restart", restartRuleHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/topo", getTopoRuleHandler).Methods(http.MethodGet)
+	r.HandleFunc("/rules/{name}/topo/metrics", getTopoMetricsRuleHandler).Methods(http.MethodGet)
 	r.HandleFunc("/rules/{name}/trace/start", enableRuleTraceHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/trace/stop", disableRuleTraceHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/usage/cpu", rulesTopCpuUsageHandler).Methods(http.MethodGet)
 	r.HandleFunc("/rules/validate", validateRuleHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/reset_state", ruleStateHandler).Methods(http.MethodPut)
 	r.HandleFunc("/rules/{name}/explain", explainRuleHandler).Methods(http.MethodGet)
+	r.HandleFunc("/rules/{name}/deadletters", deadLettersHandler).Methods(http.MethodGet, http.MethodDelete)
+	r.HandleFunc("/rules/{name}/deadletters/replay", replayDeadLettersHandler).Methods(http.MethodPost)
+	r.HandleFunc("/rules/{name}/deadletters/{id}", deadLetterHandler).Methods(http.MethodGet, http.MethodDelete)
+	r.HandleFunc("/rules/{name}/deadletters/{id}/replay", replayDeadLetterHandler).Methods(http.MethodPost)
+	r.HandleFunc("/ruletemplates", ruleTemplatesHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/ruletemplates/{id}", ruleTemplateHandler).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
+	r.HandleFunc("/ruletemplates/{id}/instances", ruleTemplateInstancesHandler).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/ruleset/export", exportHandler).Methods(http.MethodPost)
 	r.HandleFunc("/ruleset/import", importHandler).Methods(http.MethodPost)
 	r.HandleFunc("/configs", configurationUpdateHandler).Methods(http.MethodPatch)
@@ -802,8 +810,9 @@ func stopRuleHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	vars := mux.Vars(r)
 	name := vars["name"]
+	graceful, _ := strconv.ParseBool(r.URL.Query().Get("graceful"))
 
-	err := registry.StopRule(name)
+	err := registry.stopRule(name, graceful)
 	if err != nil {
 		handleError(w, err, "stop rule error", logger)
 		return
@@ -885,6 +894,21 @@ func getTopoRuleHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(content))
 }
 
+// get the backpressure state of each node in the topo of a rule
+func getTopoMetricsRuleHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	content, err := registry.GetRuleTopoMetrics(name)
+	if err != nil {
+		handleError(w, err, "get rule topo metrics error", logger)
+		return
+	}
+	w.Header().Set(ContentType, ContentTypeJSON)
+	w.Write([]byte(content))
+}
+
 // validate a rule
 func validateRuleHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
@@ -893,14 +917,26 @@ func validateRuleHandler(w http.ResponseWriter, r *http.Request) {
 		handleError(w, err, "Invalid body", logger)
 		return
 	}
-	sources, validate, err := registry.ValidateRule("", string(body))
-	if !validate {
+	ping := false
+	if p := r.URL.Query().Get("ping"); p != "" {
+		ping, err = strconv.ParseBool(p)
+		if err != nil {
+			handleError(w, fmt.Errorf("invalid ping parameter %s: %v", p, err), "", logger)
+			return
+		}
+	}
+	sources, errs := registry.DryRunRule("", string(body), ping)
+	resp := make(map[string]interface{})
+	if len(errs) > 0 {
+		resp["valid"] = false
+		resp["errors"] = errs
+		bs, _ := json.Marshal(resp)
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnprocessableEntity)
-		w.Write([]byte(err.Error()))
+		w.Write(bs)
 		return
 	}
-	resp := make(map[string]interface{})
-	resp["valid"] = validate
+	resp["valid"] = true
 	resp["sources"] = sources
 	bs, _ := json.Marshal(resp)
 	w.WriteHeader(http.StatusOK)
@@ -34,6 +34,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/io/http/httpserver"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/deadletter"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
 	"github.com/lf-edge/ekuiper/v2/internal/processor"
@@ -49,6 +50,7 @@ func init() {
 	streamProcessor = processor.NewStreamProcessor()
 	ruleProcessor = processor.NewRuleProcessor()
 	rulesetProcessor = processor.NewRulesetProcessor(ruleProcessor, streamProcessor)
+	ruleTemplateProcessor = processor.NewRuleTemplateProcessor()
 	registry = &RuleRegistry{internal: make(map[string]*rule.State)}
 	uploadsDb, _ = store.GetKV("uploads")
 	uploadsStatusDb, _ = store.GetKV("uploadsStatusDb")
@@ -86,12 +88,20 @@ func (suite *RestTestSuite) SetupTest() {
 	r.HandleFunc("/rules/{name}/stop", stopRuleHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/restart", restartRuleHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/topo", getTopoRuleHandler).Methods(http.MethodGet)
+	r.HandleFunc("/rules/{name}/topo/metrics", getTopoMetricsRuleHandler).Methods(http.MethodGet)
 	r.HandleFunc("/rules/{name}/reset_state", ruleStateHandler).Methods(http.MethodPut)
 	r.HandleFunc("/rules/{name}/explain", explainRuleHandler).Methods(http.MethodGet)
+	r.HandleFunc("/rules/{name}/deadletters", deadLettersHandler).Methods(http.MethodGet, http.MethodDelete)
+	r.HandleFunc("/rules/{name}/deadletters/replay", replayDeadLettersHandler).Methods(http.MethodPost)
+	r.HandleFunc("/rules/{name}/deadletters/{id}", deadLetterHandler).Methods(http.MethodGet, http.MethodDelete)
+	r.HandleFunc("/rules/{name}/deadletters/{id}/replay", replayDeadLetterHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/trace/start", enableRuleTraceHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/{name}/trace/stop", disableRuleTraceHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/validate", validateRuleHandler).Methods(http.MethodPost)
 	r.HandleFunc("/rules/status/all", getAllRuleStatusHandler).Methods(http.MethodGet)
+	r.HandleFunc("/ruletemplates", ruleTemplatesHandler).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/ruletemplates/{id}", ruleTemplateHandler).Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
+	r.HandleFunc("/ruletemplates/{id}/instances", ruleTemplateInstancesHandler).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/ruleset/export", exportHandler).Methods(http.MethodPost)
 	r.HandleFunc("/ruleset/import", importHandler).Methods(http.MethodPost)
 	r.HandleFunc("/configs", configurationUpdateHandler).Methods(http.MethodPatch)
@@ -348,7 +358,18 @@ func (suite *RestTestSuite) Test_rulesManageHandler() {
 	w2 = httptest.NewRecorder()
 	suite.r.ServeHTTP(w2, req2)
 	returnVal, _ = io.ReadAll(w2.Result().Body)
-	expect = `invalid rule json: Missing rule actions.`
+	expect = `{"errors":[{"type":"rule","message":"invalid rule json: Missing rule actions."}],"valid":false}`
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w2.Code)
+	assert.Equal(suite.T(), expect, string(returnVal))
+
+	// validate a rule with the sql error
+	ruleJson = `{"id": "rule321", "sql": "select * from alert union alert2", "actions": [{"log": {}}]}`
+	buf2 = bytes.NewBuffer([]byte(ruleJson))
+	req2, _ = http.NewRequest(http.MethodPost, "http://localhost:8080/rules/validate", buf2)
+	w2 = httptest.NewRecorder()
+	suite.r.ServeHTTP(w2, req2)
+	returnVal, _ = io.ReadAll(w2.Result().Body)
+	expect = `{"errors":[{"type":"sql","message":"found \"alert2\", expected ALL. Only UNION ALL is supported because the events of streams cannot be deduplicated.","line":1,"column":27}],"valid":false}`
 	assert.Equal(suite.T(), http.StatusUnprocessableEntity, w2.Code)
 	assert.Equal(suite.T(), expect, string(returnVal))
 
@@ -438,6 +459,25 @@ func (suite *RestTestSuite) Test_rulesManageHandler() {
 	expect = "{\"sources\":[\"source_alert\"],\"edges\":{\"op_2_decoder\":[\"op_3_project\"],\"op_3_project\":[\"op_nop_0_0_transform\"],\"op_nop_0_0_transform\":[\"op_nop_0_1_encode\"],\"op_nop_0_1_encode\":[\"sink_nop_0\"],\"source_alert\":[\"op_2_decoder\"]}}"
 	assert.Equal(suite.T(), expect, string(returnVal))
 
+	// get rule topo metrics, the rule is started asynchronously
+	require.Eventually(suite.T(), func() bool {
+		req1, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/rules/rule321/topo/metrics", bytes.NewBufferString("any"))
+		w1 = httptest.NewRecorder()
+		suite.r.ServeHTTP(w1, req1)
+		return w1.Code == http.StatusOK
+	}, 5*time.Second, 100*time.Millisecond)
+	returnVal, _ = io.ReadAll(w1.Result().Body)
+	bp := make(map[string]map[string]any)
+	require.NoError(suite.T(), json.Unmarshal(returnVal, &bp))
+	require.Contains(suite.T(), bp, "source_alert")
+	require.Contains(suite.T(), bp, "sink_nop_0")
+	assert.Equal(suite.T(), false, bp["sink_nop_0"]["blocked"])
+
+	req1, _ = http.NewRequest(http.MethodGet, "http://localhost:8080/rules/non-existence-rule/topo/metrics", bytes.NewBufferString("any"))
+	w1 = httptest.NewRecorder()
+	suite.r.ServeHTTP(w1, req1)
+	assert.Equal(suite.T(), http.StatusNotFound, w1.Code)
+
 	// start rule
 	req1, _ = http.NewRequest(http.MethodPost, "http://localhost:8080/rules/rule321/start", bytes.NewBufferString("any"))
 	w1 = httptest.NewRecorder()
@@ -910,6 +950,55 @@ func (suite *RestTestSuite) TestCreateDuplicateRule() {
 	require.Equal(suite.T(), `{"error":1000,"message":"rule test12345 already exists"}`+"\n", string(returnVal))
 }
 
+func (suite *RestTestSuite) TestRuleTemplate() {
+	send := func(method, url, body string) (int, string) {
+		req, _ := http.NewRequest(method, "http://localhost:8080"+url, bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		suite.r.ServeHTTP(w, req)
+		b, _ := io.ReadAll(w.Result().Body)
+		return w.Code, string(b)
+	}
+	code, _ := send(http.MethodPost, "/streams", `{"sql":"CREATE stream tplDemo() WITH (DATASOURCE=\"0\", TYPE=\"mqtt\")"}`)
+	require.Equal(suite.T(), http.StatusCreated, code)
+
+	code, body := send(http.MethodPost, "/ruletemplates", `{"id":"tpl1","params":{"threshold":30},"rule":{"id":"tpl_${device}","triggered":false,"sql":"SELECT * FROM tplDemo WHERE device = \"${device}\" AND temperature > ${threshold}","actions":[{"log":{}}],"options":{"bufferLength":"${bufferLength}"}}}`)
+	require.Equal(suite.T(), http.StatusCreated, code, body)
+	code, body = send(http.MethodGet, "/ruletemplates", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), `["tpl1"]`, body)
+
+	code, body = send(http.MethodPost, "/ruletemplates/tpl1/instances", `[{"device":"d1","bufferLength":512},{"device":"d2","threshold":40,"bufferLength":1024},{"bufferLength":1024}]`)
+	require.Equal(suite.T(), http.StatusBadRequest, code)
+	require.Equal(suite.T(), `[{"id":"tpl_d1"},{"id":"tpl_d2"},{"id":"","error":"missing parameters [device] of rule template tpl1"}]`+"\n", body)
+	r, err := ruleProcessor.GetRuleById("tpl_d2")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), `SELECT * FROM tplDemo WHERE device = "d2" AND temperature > 40`, r.Sql)
+	require.Equal(suite.T(), 1024, r.Options.BufferLength)
+	code, body = send(http.MethodGet, "/ruletemplates/tpl1/instances", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), `{"tpl_d1":{"bufferLength":512,"device":"d1"},"tpl_d2":{"bufferLength":1024,"device":"d2","threshold":40}}`, body)
+
+	// update the template and its rules
+	code, body = send(http.MethodPut, "/ruletemplates/tpl1", `{"id":"tpl1","params":{"threshold":50},"rule":{"id":"tpl_${device}","triggered":false,"sql":"SELECT * FROM tplDemo WHERE device = \"${device}\" AND temperature >= ${threshold}","actions":[{"log":{}}],"options":{"bufferLength":"${bufferLength}"}}}`)
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), `[{"id":"tpl_d1"},{"id":"tpl_d2"}]`+"\n", body)
+	r, err = ruleProcessor.GetRuleById("tpl_d1")
+	require.NoError(suite.T(), err)
+	require.Equal(suite.T(), `SELECT * FROM tplDemo WHERE device = "d1" AND temperature >= 50`, r.Sql)
+
+	// the deleted rule is not an instance anymore
+	code, _ = send(http.MethodDelete, "/rules/tpl_d1", "")
+	require.Equal(suite.T(), http.StatusOK, code)
+	code, body = send(http.MethodDelete, "/ruletemplates/tpl1", "")
+	require.Equal(suite.T(), http.StatusBadRequest, code)
+	require.Contains(suite.T(), body, "rule template tpl1 is used by 1 rules")
+	code, body = send(http.MethodDelete, "/ruletemplates/tpl1?deleteRules=true", "")
+	require.Equal(suite.T(), http.StatusOK, code, body)
+	require.False(suite.T(), ruleProcessor.ExecExists("tpl_d2"))
+	code, _ = send(http.MethodGet, "/ruletemplates/tpl1", "")
+	require.Equal(suite.T(), http.StatusNotFound, code)
+}
+
 func (suite *RestTestSuite) TestGetAllRuleStatus() {
 	buf1 := bytes.NewBuffer([]byte(`{"sql":"CREATE stream demo456() WITH (DATASOURCE=\"0\", TYPE=\"mqtt\")"}`))
 	req1, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/streams", buf1)
@@ -1001,3 +1090,42 @@ func (suite *RestTestSuite) TestWaitStopRule() {
 	require.True(suite.T(), end.Sub(now) >= 300*time.Millisecond)
 	waitAllRuleStop()
 }
+
+func (suite *RestTestSuite) TestDeadLetters() {
+	send := func(method, url string) (int, string) {
+		req, _ := http.NewRequest(method, "http://localhost:8080"+url, bytes.NewBufferString(""))
+		w := httptest.NewRecorder()
+		suite.r.ServeHTTP(w, req)
+		b, _ := io.ReadAll(w.Result().Body)
+		return w.Code, string(b)
+	}
+	e := &deadletter.Entry{RuleId: "dlRest", Sink: "mqtt_0", Error: "connection lost", Timestamp: 1, Raw: []byte("hello")}
+	require.NoError(suite.T(), deadletter.Save(e))
+	code, body := send(http.MethodGet, "/rules/dlRest/deadletters")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), `[{"id":"`+e.Id+`","ruleId":"dlRest","sink":"mqtt_0","error":"connection lost","timestamp":1,"raw":"aGVsbG8="}]`, body)
+	code, _ = send(http.MethodGet, "/rules/dlRest/deadletters/"+e.Id)
+	require.Equal(suite.T(), http.StatusOK, code)
+	code, _ = send(http.MethodGet, "/rules/dlRest/deadletters/notexist")
+	require.Equal(suite.T(), http.StatusNotFound, code)
+
+	// the rule is not running
+	code, body = send(http.MethodPost, "/rules/dlRest/deadletters/"+e.Id+"/replay")
+	require.Equal(suite.T(), http.StatusBadRequest, code, body)
+	var replayed []*deadletter.Entry
+	deadletter.Register("dlRest", "mqtt_0", func(e *deadletter.Entry) error {
+		replayed = append(replayed, e)
+		return nil
+	})
+	defer deadletter.Unregister("dlRest", "mqtt_0")
+	code, body = send(http.MethodPost, "/rules/dlRest/deadletters/replay")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), `[{"id":"`+e.Id+`"}]`+"\n", body)
+	require.Len(suite.T(), replayed, 1)
+	require.Equal(suite.T(), []byte("hello"), replayed[0].Raw)
+
+	require.NoError(suite.T(), deadletter.Save(&deadletter.Entry{RuleId: "dlRest", Sink: "mqtt_0", Timestamp: 2}))
+	code, body = send(http.MethodDelete, "/rules/dlRest/deadletters")
+	require.Equal(suite.T(), http.StatusOK, code)
+	require.Equal(suite.T(), "1 dead letters of rule dlRest are deleted.", body)
+}
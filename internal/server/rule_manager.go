@@ -23,7 +23,6 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
-	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/rule"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -208,6 +207,9 @@ func (rr *RuleRegistry) DeleteRule(name string) error {
 		}
 		deleteRuleMetrics(name)
 	}
+	if err == nil {
+		ruleTemplateProcessor.DropInstance(name)
+	}
 	return err
 }
 
@@ -233,12 +235,21 @@ func (rr *RuleRegistry) StartRule(name string) error {
 }
 
 func (rr *RuleRegistry) StopRule(name string) error {
+	return rr.stopRule(name, false)
+}
+
+// stopRule stops the rule. If graceful is true, the rule is drained before stopping.
+func (rr *RuleRegistry) stopRule(name string, graceful bool) error {
 	if rs, ok := registry.load(name); ok {
 		err := rr.updateTrigger(name, false)
 		if err != nil {
 			conf.Log.Warnf("stop rule update db status error: %s", err.Error())
 		}
-		rs.Stop()
+		if graceful {
+			rs.GracefulStop()
+		} else {
+			rs.Stop()
+		}
 	} else {
 		return errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("Rule %s is not found in registry, please check if it is created", name))
 	}
@@ -345,34 +356,61 @@ func (rr *RuleRegistry) GetRuleTopo(name string) (string, error) {
 	}
 }
 
+func (rr *RuleRegistry) GetRuleTopoMetrics(name string) (string, error) {
+	if rs, ok := registry.load(name); ok {
+		bp, err := rs.GetBackpressure()
+		if err != nil {
+			return "", errorx.New(fmt.Sprintf("Fail to get rule %s's topo metrics, make sure the rule is running", name))
+		}
+		bs, err := json.Marshal(bp)
+		if err != nil {
+			return "", errorx.New(fmt.Sprintf("Fail to encode rule %s's topo metrics", name))
+		} else {
+			return string(bs), nil
+		}
+	} else {
+		return "", errorx.NewWithCode(errorx.NOT_FOUND, fmt.Sprintf("Rule %s is not found", name))
+	}
+}
+
 func (rr *RuleRegistry) ValidateRule(name, ruleJson string) ([]string, bool, error) {
+	sources, errs := rr.DryRunRule(name, ruleJson, false)
+	if len(errs) > 0 {
+		return nil, false, errs[0]
+	}
+	return sources, true, nil
+}
+
+// DryRunRule validates the rule by planning it without running and returns the referred sources or all the errors
+// found. The connections of the sources and sinks are only tested if ping is true.
+func (rr *RuleRegistry) DryRunRule(name, ruleJson string, ping bool) ([]string, []*planner.ValidateError) {
 	// Validate the ruleDef json
 	ruleDef, err := ruleProcessor.GetRuleByJson(name, ruleJson)
 	if err != nil {
-		return nil, false, fmt.Errorf("invalid rule json: %v", err)
+		// Report the SQL error with its position if the rule json is only invalid for its SQL
+		r := &def.Rule{}
+		if json.Unmarshal([]byte(ruleJson), r) == nil && r.Sql != "" {
+			if verr := planner.ValidateSql(r.Sql); verr != nil {
+				return nil, []*planner.ValidateError{verr}
+			}
+		}
+		return nil, []*planner.ValidateError{{Type: planner.ValidateTypeRule, Message: fmt.Sprintf("invalid rule json: %v", err)}}
 	}
 	var sources []string
 	if len(ruleDef.Sql) > 0 {
-		stmt, _ := xsql.GetStatementFromSql(ruleDef.Sql)
-		s, err := store.GetKV("stream")
-		if err != nil {
-			return nil, false, err
-		}
-		sources = xsql.GetStreams(stmt)
-		for _, result := range sources {
-			_, err := xsql.GetDataSource(s, result)
-			if err != nil {
-				return nil, false, err
-			}
+		if errs := planner.ValidateRule(ruleDef, ping); len(errs) > 0 {
+			return nil, errs
 		}
+		stmt, _ := xsql.GetStatementFromSql(ruleDef.Sql)
+		sources = append(xsql.GetStreams(stmt), xsql.GetUnionStreams(stmt)...)
 	} else if ruleDef.Graph != nil {
 		tp, err := planner.PlanByGraph(ruleDef)
 		if err != nil {
-			return nil, false, fmt.Errorf("invalid ruleDef graph: %v", err)
+			return nil, []*planner.ValidateError{{Type: planner.ValidateTypeRule, Message: fmt.Sprintf("invalid ruleDef graph: %v", err)}}
 		}
 		sources = tp.GetTopo().Sources
 	}
-	return sources, true, nil
+	return sources, nil
 }
 
 /// Rule Scheduler internal API
@@ -422,8 +460,9 @@ func getRuleExceptionStatus(name string) (ruleExceptionStatus, error) {
 					v := values[i].(int64)
 					if v > s.lastExceptionTime {
 						s.lastExceptionTime = v
-						total, last := getTargetException(keys, values, key[:strings.Index(key, "_last_exception_time")])
+						total, last, code := getTargetException(keys, values, key[:strings.Index(key, "_last_exception_time")])
 						s.LastException = last
+						s.LastExceptionCode = code
 						s.ExceptionsTotal = total
 					}
 				}
@@ -433,8 +472,8 @@ func getRuleExceptionStatus(name string) (ruleExceptionStatus, error) {
 	return s, nil
 }
 
-func getTargetException(keys []string, values []any, prefix string) (int64, string) {
-	var t int64
+func getTargetException(keys []string, values []any, prefix string) (int64, string, int64) {
+	var t, code int64
 	lastException := ""
 	for i, key := range keys {
 		if key == fmt.Sprintf("%s_exceptions_total", prefix) {
@@ -445,13 +484,18 @@ func getTargetException(keys []string, values []any, prefix string) (int64, stri
 			lastException = values[i].(string)
 			continue
 		}
+		if key == fmt.Sprintf("%s_last_exception_code", prefix) {
+			code = values[i].(int64)
+			continue
+		}
 	}
-	return t, lastException
+	return t, lastException, code
 }
 
 type ruleExceptionStatus struct {
 	Status            string `json:"status"`
 	LastException     string `json:"last_exception"`
+	LastExceptionCode int64  `json:"last_exception_code,omitempty"`
 	ExceptionsTotal   int64  `json:"exceptions_total"`
 	lastExceptionTime int64
 }
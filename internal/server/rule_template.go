@@ -0,0 +1,228 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ruleTemplateResult is the result of a rule created, updated or deleted by the template
+type ruleTemplateResult struct {
+	Id    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+func ruleTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	switch r.Method {
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		id, err := ruleTemplateProcessor.ExecCreate(string(body))
+		if err != nil {
+			handleError(w, err, "Create rule template error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "Rule template %s was created successfully.", id)
+	case http.MethodGet:
+		ids, err := ruleTemplateProcessor.GetAllTemplates()
+		if err != nil {
+			handleError(w, err, "Show rule templates error", logger)
+			return
+		}
+		sort.Strings(ids)
+		jsonResponse(ids, w, logger)
+	}
+}
+
+func ruleTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	id := mux.Vars(r)["id"]
+	switch r.Method {
+	case http.MethodGet:
+		tpl, err := ruleTemplateProcessor.GetTemplateJson(id)
+		if err != nil {
+			handleError(w, err, "Describe rule template error", logger)
+			return
+		}
+		w.Header().Add(ContentType, ContentTypeJSON)
+		w.Write([]byte(tpl))
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, err, "Invalid body", logger)
+			return
+		}
+		if _, err := ruleTemplateProcessor.ExecUpdate(id, string(body)); err != nil {
+			handleError(w, err, "Update rule template error", logger)
+			return
+		}
+		// Update the rules instantiated from the template by their params
+		results, ok := updateTemplateRules(id)
+		writeTemplateResults(w, results, ok, http.StatusOK)
+	case http.MethodDelete:
+		instances, err := ruleTemplateProcessor.GetInstances(id)
+		if err != nil {
+			handleError(w, err, "Delete rule template error", logger)
+			return
+		}
+		deleteRules, _ := strconv.ParseBool(r.URL.Query().Get("deleteRules"))
+		if len(instances) > 0 && !deleteRules {
+			handleError(w, fmt.Errorf("rule template %s is used by %d rules, delete them by the deleteRules parameter", id, len(instances)), "Delete rule template error", logger)
+			return
+		}
+		results, ok := deleteTemplateRules(instances)
+		if !ok {
+			writeTemplateResults(w, results, ok, http.StatusOK)
+			return
+		}
+		if err := ruleTemplateProcessor.ExecDrop(id); err != nil {
+			handleError(w, err, "Delete rule template error", logger)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "Rule template %s is dropped.", id)
+	}
+}
+
+// ruleTemplateInstancesHandler instantiates the rules from the template with a list of param sets, lists the rules
+// instantiated from the template or deletes all of them
+func ruleTemplateInstancesHandler(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	id := mux.Vars(r)["id"]
+	switch r.Method {
+	case http.MethodPost:
+		var paramsList []map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&paramsList); err != nil {
+			handleError(w, err, "Invalid body: the body must be a list of params", logger)
+			return
+		}
+		tpl, err := ruleTemplateProcessor.GetTemplate(id)
+		if err != nil {
+			handleError(w, err, "Instantiate rule template error", logger)
+			return
+		}
+		results := make([]ruleTemplateResult, 0, len(paramsList))
+		ok := true
+		for _, params := range paramsList {
+			ruleId, ruleJson, err := tpl.Instantiate(params)
+			if err == nil {
+				ruleId, err = registry.CreateRule(ruleId, ruleJson)
+			}
+			if err == nil {
+				err = ruleTemplateProcessor.SaveInstance(ruleId, id, params)
+				if err != nil {
+					// Roll back the rule so that no rule is left without its template instance
+					if e := registry.DeleteRule(ruleId); e != nil {
+						logger.Warnf("delete rule %s after saving its template instance failed: %v", ruleId, e)
+					}
+				}
+			}
+			results = append(results, templateResult(ruleId, err))
+			ok = ok && err == nil
+		}
+		writeTemplateResults(w, results, ok, http.StatusCreated)
+	case http.MethodGet:
+		instances, err := ruleTemplateProcessor.GetInstances(id)
+		if err != nil {
+			handleError(w, err, "Show rule template instances error", logger)
+			return
+		}
+		jsonResponse(instances, w, logger)
+	case http.MethodDelete:
+		instances, err := ruleTemplateProcessor.GetInstances(id)
+		if err != nil {
+			handleError(w, err, "Delete rule template instances error", logger)
+			return
+		}
+		results, ok := deleteTemplateRules(instances)
+		writeTemplateResults(w, results, ok, http.StatusOK)
+	}
+}
+
+func updateTemplateRules(tplId string) ([]ruleTemplateResult, bool) {
+	instances, err := ruleTemplateProcessor.GetInstances(tplId)
+	if err != nil {
+		return []ruleTemplateResult{templateResult("", err)}, false
+	}
+	tpl, err := ruleTemplateProcessor.GetTemplate(tplId)
+	if err != nil {
+		return []ruleTemplateResult{templateResult("", err)}, false
+	}
+	results := make([]ruleTemplateResult, 0, len(instances))
+	ok := true
+	for _, ruleId := range sortedKeys(instances) {
+		newId, ruleJson, err := tpl.Instantiate(instances[ruleId])
+		if err == nil && newId != ruleId {
+			err = fmt.Errorf("the rule id is changed to %s by the template, the rule id cannot be updated", newId)
+		}
+		if err == nil {
+			err = registry.UpdateRule(ruleId, ruleJson)
+		}
+		results = append(results, templateResult(ruleId, err))
+		ok = ok && err == nil
+	}
+	return results, ok
+}
+
+func deleteTemplateRules(instances map[string]map[string]any) ([]ruleTemplateResult, bool) {
+	results := make([]ruleTemplateResult, 0, len(instances))
+	ok := true
+	for _, ruleId := range sortedKeys(instances) {
+		err := registry.DeleteRule(ruleId)
+		results = append(results, templateResult(ruleId, err))
+		ok = ok && err == nil
+	}
+	return results, ok
+}
+
+func templateResult(ruleId string, err error) ruleTemplateResult {
+	r := ruleTemplateResult{Id: ruleId}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// writeTemplateResults responds the result of each rule. If any of them fails, respond bad request so that the
+// client could check the errors in the results.
+func writeTemplateResults(w http.ResponseWriter, results []ruleTemplateResult, ok bool, code int) {
+	if !ok {
+		code = http.StatusBadRequest
+	}
+	w.Header().Add(ContentType, ContentTypeJSON)
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(results)
+}
+
+func sortedKeys(m map[string]map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -60,6 +60,7 @@ var (
 	ruleProcessor          *processor.RuleProcessor
 	streamProcessor        *processor.StreamProcessor
 	rulesetProcessor       *processor.RulesetProcessor
+	ruleTemplateProcessor  *processor.RuleTemplateProcessor
 	ruleMigrationProcessor *RuleMigrationProcessor
 	stopSignal             chan struct{}
 	cpuProfiler            = &ekuiperProfile{}
@@ -130,6 +131,19 @@ func getStoreConfigByKuiperConfig(c *conf.KuiperConf) (*store.StoreConf, error)
 		FdbConfig: definition.FdbConfig{
 			Path: c.Store.Fdb.Path,
 		},
+		CheckpointConfig: definition.CheckpointStoreConfig{
+			Type: c.Store.CheckpointStore.Type,
+			S3: definition.S3Config{
+				Endpoint:     c.Store.CheckpointStore.S3.Endpoint,
+				Region:       c.Store.CheckpointStore.S3.Region,
+				Bucket:       c.Store.CheckpointStore.S3.Bucket,
+				AccessKey:    c.Store.CheckpointStore.S3.AccessKey,
+				SecretKey:    c.Store.CheckpointStore.S3.SecretKey,
+				Prefix:       c.Store.CheckpointStore.S3.Prefix,
+				UsePathStyle: c.Store.CheckpointStore.S3.UsePathStyle,
+				Timeout:      time.Duration(c.Store.CheckpointStore.S3.Timeout),
+			},
+		},
 	}
 	return sc, nil
 }
@@ -191,6 +205,7 @@ func StartUp(Version string) {
 	ruleProcessor = processor.NewRuleProcessor()
 	streamProcessor = processor.NewStreamProcessor()
 	rulesetProcessor = processor.NewRulesetProcessor(ruleProcessor, streamProcessor)
+	ruleTemplateProcessor = processor.NewRuleTemplateProcessor()
 	ruleMigrationProcessor = NewRuleMigrationProcessor(ruleProcessor, streamProcessor)
 	sysMetrics = NewMetrics()
 
@@ -52,7 +52,7 @@ func tracerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	enableRemoteCollector := req.Action == "start"
-	if err := tracer.SetTracer(&tracer.TracerConfig{EnableRemoteCollector: enableRemoteCollector, ServiceName: req.ServiceName, RemoteEndpoint: req.CollectorUrl}); err != nil {
+	if err := tracer.SetTracer(&tracer.TracerConfig{EnableRemoteCollector: enableRemoteCollector, ServiceName: req.ServiceName, RemoteEndpoint: req.CollectorUrl, SamplingRate: req.SamplingRate}); err != nil {
 		handleError(w, err, "", logger)
 		return
 	}
@@ -61,9 +61,10 @@ func tracerHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 type SetTracerRequest struct {
-	ServiceName  string `json:"service_name"`
-	Action       string `json:"action"`
-	CollectorUrl string `json:"collector_url"`
+	ServiceName  string  `json:"service_name"`
+	Action       string  `json:"action"`
+	CollectorUrl string  `json:"collector_url"`
+	SamplingRate float64 `json:"sampling_rate"`
 }
 
 func getTraceIDByRuleID(w http.ResponseWriter, r *http.Request) {
@@ -94,6 +94,7 @@ type Coordinator struct {
 	toBeClean               int
 	tasksToTrigger          []Responder
 	tasksToWaitFor          []Responder
+	sourceTasks             []StreamTask
 	sinkTasks               []SinkTask
 	pendingCheckpoints      *sync.Map
 	completedCheckpoints    *checkpointStore
@@ -142,6 +143,7 @@ func NewCoordinator(ruleId string, sources []StreamTask, operators []NonSourceTa
 	}
 	return &Coordinator{
 		tasksToTrigger:     sourceResponders,
+		sourceTasks:        sources,
 		tasksToWaitFor:     allResponders,
 		sinkTasks:          sinks,
 		pendingCheckpoints: new(sync.Map),
@@ -303,6 +305,11 @@ func (c *Coordinator) complete(checkpointId int64) {
 		}
 		c.completedCheckpoints.add(ccp.(*pendingCheckpoint).finalize())
 		c.pendingCheckpoints.Delete(checkpointId)
+		for _, t := range c.sourceTasks {
+			if l, ok := t.(CheckpointListener); ok {
+				l.NotifyCheckpointComplete(checkpointId)
+			}
+		}
 		// Drop the previous pendingCheckpoints
 		c.pendingCheckpoints.Range(func(a1 interface{}, a2 interface{}) bool {
 			cid := a1.(int64)
@@ -46,6 +46,15 @@ type SinkTask interface {
 	NonSourceTask
 }
 
+// CheckpointListener is a task which has side effects to commit only after the checkpoint completes, such as the source
+// which commits the offsets to the external system
+type CheckpointListener interface {
+	// SnapshotCheckpoint is called after the task takes the snapshot of the checkpoint
+	SnapshotCheckpoint(checkpointId int64)
+	// NotifyCheckpointComplete is called after the checkpoint is acknowledged by all the tasks and saved
+	NotifyCheckpointComplete(checkpointId int64)
+}
+
 type BufferOrEvent struct {
 	Data    interface{}
 	Channel string
@@ -64,6 +64,9 @@ func (re *ResponderExecutor) TriggerCheckpoint(checkpointId int64) error {
 	if err != nil {
 		return err
 	}
+	if l, ok := re.task.(CheckpointListener); ok {
+		l.SnapshotCheckpoint(checkpointId)
+	}
 	go infra.SafeRun(func() error {
 		state := ACK
 		err := sctx.SaveState(checkpointId)
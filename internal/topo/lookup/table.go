@@ -78,7 +78,11 @@ func CreateInstance(name string, sourceType string, options *ast.Options) error
 		return err
 	}
 	ctx.GetLogger().Debugf("lookup source %s is created", sourceType)
-	err = ns.Provision(ctx, props)
+	resolved, err := conf.ResolveProps(props)
+	if err != nil {
+		return err
+	}
+	err = ns.Provision(ctx, resolved)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,132 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// ValueSet materializes the values of the IN subquery from a lookup table. The values are loaded on the first use and
+// reloaded when they are older than the refresh interval, so the table is scanned at most once per interval instead of
+// once per event.
+type ValueSet struct {
+	table     string
+	field     ast.Expr
+	cond      ast.Expr
+	refresh   time.Duration
+	maxValues int
+
+	mu       sync.Mutex
+	values   []any
+	loaded   bool
+	loadedAt time.Time
+}
+
+func NewValueSet(sq *ast.SubQuery, refresh time.Duration, maxValues int) *ValueSet {
+	return &ValueSet{
+		table:     sq.Table,
+		field:     sq.Field,
+		cond:      sq.Condition,
+		refresh:   refresh,
+		maxValues: maxValues,
+	}
+}
+
+// Values returns the cached values, and reloads them from the table if expired
+func (s *ValueSet) Values() ([]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := timex.GetNow()
+	if s.loaded && now.Sub(s.loadedAt) < s.refresh {
+		return s.values, nil
+	}
+	values, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.values = values
+	s.loaded = true
+	s.loadedAt = now
+	return values, nil
+}
+
+// load scans the table and evaluates the subquery on each row. The duplicated values are only kept once.
+func (s *ValueSet) load() ([]any, error) {
+	ls, err := Attach(s.table)
+	if err != nil {
+		return nil, err
+	}
+	defer Detach(s.table)
+	scanner, ok := ls.(model.ScanLookupSource)
+	if !ok {
+		return nil, fmt.Errorf("lookup table %s does not support IN subquery", s.table)
+	}
+	ctx := kctx.WithValue(kctx.Background(), kctx.LoggerKey, conf.Log.WithField("table", s.table))
+	rows, err := scanner.Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("scan lookup table %s for IN subquery error: %v", s.table, err)
+	}
+	fv, _ := xsql.NewFunctionValuersForOp(ctx)
+	var (
+		values  = make([]any, 0)
+		seen    = make(map[any]struct{})
+		seenNil bool
+	)
+	for _, row := range rows {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(xsql.Message(row), fv)}
+		if s.cond != nil {
+			switch r := ve.Eval(s.cond).(type) {
+			case error:
+				return nil, fmt.Errorf("evaluate the condition of IN subquery on table %s error: %v", s.table, r)
+			case bool:
+				if !r {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		v := ve.Eval(s.field)
+		if e, ok := v.(error); ok {
+			return nil, fmt.Errorf("evaluate the field of IN subquery on table %s error: %v", s.table, e)
+		}
+		if v == nil {
+			if seenNil {
+				continue
+			}
+			seenNil = true
+		} else if reflect.TypeOf(v).Comparable() {
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			seen[v] = struct{}{}
+		}
+		if len(values) >= s.maxValues {
+			return nil, fmt.Errorf("the values of IN subquery on table %s exceed the limit %d", s.table, s.maxValues)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
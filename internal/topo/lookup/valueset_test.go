@@ -0,0 +1,104 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lookup
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func TestValueSet(t *testing.T) {
+	require.NoError(t, CreateInstance("denylist", "memory", &ast.Options{
+		DATASOURCE: "denylistTopic",
+		TYPE:       "memory",
+		KIND:       "lookup",
+		KEY:        "id",
+	}))
+	defer DropInstance("denylist")
+	// wait for the table to subscribe
+	time.Sleep(100 * time.Millisecond)
+	ctx := mockContext.NewMockContext("valueset", "test")
+	pubsub.Produce(ctx, "denylistTopic", &xsql.Tuple{Message: map[string]any{"id": "d1", "enabled": true}})
+	pubsub.Produce(ctx, "denylistTopic", &xsql.Tuple{Message: map[string]any{"id": "d2", "enabled": false}})
+	pubsub.Produce(ctx, "denylistTopic", &xsql.Tuple{Message: map[string]any{"id": "d3", "enabled": true}})
+	time.Sleep(100 * time.Millisecond)
+
+	timex.Set(0)
+	vs := NewValueSet(&ast.SubQuery{
+		Table: "denylist",
+		Field: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"},
+		Condition: &ast.BinaryExpr{
+			LHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "enabled"},
+			OP:  ast.EQ,
+			RHS: &ast.BooleanLiteral{Val: true},
+		},
+	}, time.Minute, 10)
+	values, err := vs.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d3"}, sortedStrings(values))
+
+	// the table update is not visible until the refresh interval passes
+	pubsub.Produce(ctx, "denylistTopic", &xsql.Tuple{Message: map[string]any{"id": "d4", "enabled": true}})
+	time.Sleep(100 * time.Millisecond)
+	timex.Add(30 * time.Second)
+	values, err = vs.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d3"}, sortedStrings(values))
+	timex.Add(30 * time.Second)
+	values, err = vs.Values()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d1", "d3", "d4"}, sortedStrings(values))
+
+	// the duplicated values are kept once and counted by the limit
+	limited := NewValueSet(&ast.SubQuery{
+		Table: "denylist",
+		Field: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "enabled"},
+	}, time.Minute, 2)
+	values, err = limited.Values()
+	require.NoError(t, err)
+	assert.Len(t, values, 2)
+	limited = NewValueSet(&ast.SubQuery{
+		Table: "denylist",
+		Field: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"},
+	}, time.Minute, 2)
+	_, err = limited.Values()
+	assert.EqualError(t, err, "the values of IN subquery on table denylist exceed the limit 2")
+
+	notFound := NewValueSet(&ast.SubQuery{
+		Table: "notExist",
+		Field: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"},
+	}, time.Minute, 2)
+	_, err = notFound.Values()
+	assert.EqualError(t, err, "lookup table notExist is not found")
+}
+
+func sortedStrings(values []any) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = v.(string)
+	}
+	sort.Strings(result)
+	return result
+}
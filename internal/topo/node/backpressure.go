@@ -0,0 +1,46 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+// BackpressureState is the backpressure state of a node sampled at the time of request
+type BackpressureState struct {
+	// BufferLength and BufferCapacity are the occupancy of the input buffer. Source nodes have no input buffer.
+	BufferLength   int   `json:"bufferLength"`
+	BufferCapacity int   `json:"bufferCapacity"`
+	Processed      int64 `json:"processed"`
+	// Blocked is true if the node is waiting for the downstream buffer which is full.
+	// It can only be true when the rule option disableBufferFullDiscard is set.
+	Blocked bool `json:"blocked"`
+}
+
+func (o *defaultNode) GetBackpressure() *BackpressureState {
+	s := &BackpressureState{
+		Blocked: o.blocked.Load(),
+	}
+	metrics := o.GetMetrics()
+	if len(metrics) > 2 {
+		if p, ok := metrics[2].(int64); ok {
+			s.Processed = p
+		}
+	}
+	return s
+}
+
+func (o *defaultSinkNode) GetBackpressure() *BackpressureState {
+	s := o.defaultNode.GetBackpressure()
+	s.BufferLength = len(o.input)
+	s.BufferCapacity = cap(o.input)
+	return s
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/pingcap/failpoint"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
@@ -137,6 +138,11 @@ func (b *BatchOp) ingest(ctx api.StreamContext, item any, checkSize bool) {
 	// For batching operator, do not end the span immediately so set it to nil
 	b.span = nil
 	b.onProcessEnd(ctx)
+	b.updateBufferLength()
+}
+
+// updateBufferLength reports the fill level of the current batch plus the pending inputs as the buffer length metric
+func (b *BatchOp) updateBufferLength() {
 	b.statManager.SetBufferLength(int64(len(b.input) + b.currIndex))
 }
 
@@ -155,6 +161,7 @@ func (b *BatchOp) send(ctx api.StreamContext) {
 		Content: make([]xsql.Row, 0, b.batchSize),
 	}
 	b.currIndex = 0
+	b.updateBufferLength()
 }
 
 func (b *BatchOp) runWithBatchSize(ctx api.StreamContext, errCh chan<- error) {
@@ -204,7 +211,7 @@ func (b *BatchOp) runWithTicker(ctx api.StreamContext, errCh chan<- error) {
 }
 
 func (b *BatchOp) handleNextWindowTupleSpan(ctx api.StreamContext) {
-	traced, spanCtx, span := tracenode.StartTraceBackground(ctx, "batch_op")
+	traced, spanCtx, span := tracenode.StartTraceBackground(ctx, "batch_op", trace.WithAttributes(attribute.String(tracenode.OpTypeKey, b.opType)))
 	if traced {
 		b.nextSpanCtx = spanCtx
 		b.nextSpan = span
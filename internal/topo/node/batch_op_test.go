@@ -108,3 +108,28 @@ func TestBatchOpSendEmpty(t *testing.T) {
 	op.send(mockContext.NewMockContext("1", "2"))
 	failpoint.Disable("github.com/lf-edge/ekuiper/v2/internal/topo/node/injectPanic")
 }
+
+func TestBatchOpBufferLength(t *testing.T) {
+	op, err := NewBatchOp("test", &def.RuleOption{BufferLength: 10, SendError: true}, 3, time.Second)
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx := mockContext.NewMockContext("test1", "batch_test")
+	op.prepareExec(ctx, make(chan error), "op")
+	for i := 0; i < 2; i++ {
+		op.ingest(ctx, &xsql.Tuple{Emitter: "test", Message: map[string]any{"b": i}}, true)
+	}
+	assert.Equal(t, int64(2), op.statManager.GetMetrics()[4])
+	// linger flush resets the fill level
+	op.send(ctx)
+	assert.Equal(t, int64(0), op.statManager.GetMetrics()[4])
+	w := (<-out).(*xsql.WindowTuples)
+	assert.Len(t, w.Content, 2)
+	// drain flushes the partial batch before passing the EOF
+	op.ingest(ctx, &xsql.Tuple{Emitter: "test", Message: map[string]any{"b": 3}}, true)
+	op.ingest(ctx, xsql.EOFDrain, true)
+	w = (<-out).(*xsql.WindowTuples)
+	assert.Len(t, w.Content, 1)
+	assert.Equal(t, xsql.EOFDrain, <-out)
+	assert.Equal(t, int64(0), op.statManager.GetMetrics()[4])
+}
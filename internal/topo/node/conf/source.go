@@ -83,6 +83,11 @@ func GetSourceConf(sourceType string, options *ast.Options) map[string]interface
 	props["delimiter"] = options.DELIMITER
 	props["retainSize"] = options.RETAIN_SIZE
 	props["strictValidation"] = options.STRICT_VALIDATION
+	props["validationPolicy"] = options.VALIDATION_POLICY
+	// The decode error topic could also be set in the source configuration
+	if options.DECODE_ERROR_TOPIC != "" {
+		props["decodeErrorTopic"] = options.DECODE_ERROR_TOPIC
+	}
 	props["timestamp"] = options.TIMESTAMP
 	props["timestampFormat"] = options.TIMESTAMP_FORMAT
 	conf.Log.Infof("get conf for %s with conf key %s: %v", sourceType, confkey, printable(props))
@@ -92,7 +97,7 @@ func GetSourceConf(sourceType string, options *ast.Options) map[string]interface
 func printable(m map[string]interface{}) map[string]interface{} {
 	printableMap := make(map[string]interface{})
 	for k, v := range m {
-		if strings.EqualFold(k, "password") {
+		if strings.EqualFold(k, "password") || strings.EqualFold(k, "clientSecret") {
 			printableMap[k] = "*"
 		} else {
 			if vm, ok := v.(map[string]interface{}); ok {
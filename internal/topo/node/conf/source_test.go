@@ -68,6 +68,7 @@ func TestGetSourceConf(t *testing.T) {
 				"strictValidation":   false,
 				"timestamp":          "",
 				"timestampFormat":    "",
+				"validationPolicy":   "",
 			},
 		},
 		{
@@ -93,6 +94,7 @@ func TestGetSourceConf(t *testing.T) {
 				"strictValidation":   false,
 				"timestamp":          "",
 				"timestampFormat":    "",
+				"validationPolicy":   "",
 			},
 		},
 		{
@@ -118,6 +120,7 @@ func TestGetSourceConf(t *testing.T) {
 				"strictValidation":   false,
 				"timestamp":          "",
 				"timestampFormat":    "",
+				"validationPolicy":   "",
 				"connectionSelector": "test11",
 				"a":                  1,
 			},
@@ -47,6 +47,27 @@ type MetricNode interface {
 	RemoveMetrics(ruleId string)
 }
 
+// ExtraMetricNode is the node which reports the node specific metrics besides the common metrics.
+// The keys are the metric names without the node prefix.
+type ExtraMetricNode interface {
+	GetExtraMetrics() ([]string, []any)
+}
+
+// BackpressureNode is the node which can report its buffer occupancy and blocking state
+type BackpressureNode interface {
+	GetBackpressure() *BackpressureState
+}
+
+// DrainableNode is the source node which can stop ingesting and send out EOF to drain the rule gracefully
+type DrainableNode interface {
+	Drain(ctx api.StreamContext)
+}
+
+// DrainedNode is the sink node which notifies when it has received the EOF from all the inputs
+type DrainedNode interface {
+	Drained() <-chan struct{}
+}
+
 type OperatorNode interface {
 	DataSinkNode
 	Emitter
@@ -23,12 +23,15 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/converter"
 	schemaLayer "github.com/lf-edge/ekuiper/v2/internal/converter/schema"
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/metrics"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 // DecodeOp manages the format decoding (employ schema) and sending frequency (for batch decode, like a json array)
@@ -54,6 +57,10 @@ type dconf struct {
 	PayloadFormat     string            `json:"payloadFormat"`
 	PayloadSchemaId   string            `json:"payloadSchemaId"`
 	PayloadDelimiter  string            `json:"payloadDelimiter"`
+	// Keep the raw payload for the dead letter of the schema validation
+	ValidationPolicy string `json:"validationPolicy"`
+	// The memory topic to forward the undecodable payloads instead of sending the error
+	DecodeErrorTopic string `json:"decodeErrorTopic"`
 }
 
 func NewDecodeOp(ctx api.StreamContext, forPayload bool, name, StreamName string, rOpt *def.RuleOption, schema map[string]*ast.JsonStreamField, props map[string]any) (*DecodeOp, error) {
@@ -139,17 +146,17 @@ func (o *DecodeOp) Worker(ctx api.StreamContext, item any) []any {
 	case *xsql.RawTuple:
 		result, err := o.converter.Decode(ctx, d.Raw())
 		if err != nil {
-			return []any{err}
+			return o.decodeError(ctx, d.Raw(), d.Emitter, d.Metadata, err)
 		}
 
 		switch r := result.(type) {
 		case map[string]interface{}:
-			tuple := toTupleFromRawTuple(ctx, r, d)
+			tuple := o.toTuple(ctx, r, d)
 			return []any{tuple}
 		case []map[string]interface{}:
 			rr := make([]any, len(r))
 			for i, v := range r {
-				tuple := toTupleFromRawTuple(ctx, v, d)
+				tuple := o.toTuple(ctx, v, d)
 				rr[i] = tuple
 			}
 			return rr
@@ -157,7 +164,7 @@ func (o *DecodeOp) Worker(ctx api.StreamContext, item any) []any {
 			rr := make([]any, len(r))
 			for i, v := range r {
 				if vc, ok := v.(map[string]interface{}); ok {
-					rr[i] = toTupleFromRawTuple(ctx, vc, d)
+					rr[i] = o.toTuple(ctx, vc, d)
 				} else {
 					rr[i] = fmt.Errorf("only map[string]any inside a list is supported but got: %v", v)
 				}
@@ -235,7 +242,7 @@ func (o *DecodeOp) PayloadDecodeWorker(ctx api.StreamContext, item any) []any {
 		}
 		result, err := o.converter.Decode(ctx, raw)
 		if err != nil {
-			return []any{err}
+			return o.decodeError(ctx, raw, d.Emitter, d.Metadata, err)
 		}
 		return transTuple(d, result)
 	default:
@@ -378,6 +385,34 @@ func mergeTuple(ctx api.StreamContext, d *xsql.Tuple, result any) {
 	}
 }
 
+// decodeError forwards the undecodable payload to the decode error topic if set. Otherwise, the error is sent down.
+func (o *DecodeOp) decodeError(ctx api.StreamContext, raw []byte, emitter string, meta xsql.Metadata, err error) []any {
+	if o.c.DecodeErrorTopic == "" {
+		return []any{err}
+	}
+	ctx.GetLogger().Debugf("forward the payload which fails to decode to %s: %v", o.c.DecodeErrorTopic, err)
+	pubsub.Produce(ctx, o.c.DecodeErrorTopic, &xsql.Tuple{
+		Message: map[string]any{
+			"payload": raw,
+			"error":   err.Error(),
+			"stream":  emitter,
+			"ruleId":  ctx.GetRuleId(),
+		},
+		Metadata:  meta,
+		Timestamp: timex.GetNow(),
+	})
+	metrics.SourceDecodeErrorCounter.WithLabelValues(ctx.GetRuleId(), ctx.GetOpId()).Inc()
+	return nil
+}
+
+func (o *DecodeOp) toTuple(ctx api.StreamContext, v map[string]any, d *xsql.RawTuple) *xsql.Tuple {
+	t := toTupleFromRawTuple(ctx, v, d)
+	if o.c.ValidationPolicy == ast.ValidationPolicyDeadLetter {
+		t.Rawdata = d.Raw()
+	}
+	return t
+}
+
 func toTupleFromRawTuple(ctx api.StreamContext, v map[string]any, d *xsql.RawTuple) *xsql.Tuple {
 	t := &xsql.Tuple{
 		Ctx:       d.Ctx,
@@ -399,6 +434,7 @@ func cloneTuple(d *xsql.Tuple) *xsql.Tuple {
 		Metadata:  d.Metadata,
 		Timestamp: d.Timestamp,
 		Emitter:   d.Emitter,
+		Rawdata:   d.Rawdata,
 	}
 }
 
@@ -21,11 +21,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/metrics"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
@@ -249,6 +252,35 @@ func TestValidate(t *testing.T) {
 	assert.EqualError(t, err, "cannot get converter from format test, schemaId : format type test not supported")
 }
 
+func TestDecodeErrorTopic(t *testing.T) {
+	ctx := mockContext.NewMockContext("test1", "decode_test")
+	topic := "testDecode/undecodable"
+	ch := pubsub.CreateSub(topic, "test", 10)
+	defer pubsub.CloseSourceConsumerChannel(topic, "test")
+	op, err := NewDecodeOp(ctx, false, "test", "streamName", &def.RuleOption{BufferLength: 10, SendError: true}, nil, map[string]any{
+		"decodeErrorTopic": topic,
+	})
+	require.NoError(t, err)
+	r := op.Worker(ctx, &xsql.RawTuple{Emitter: "test", Rawdata: []byte(`{"a":`), Timestamp: time.UnixMilli(111), Metadata: map[string]any{"topic": "demo"}})
+	assert.Nil(t, r)
+	// The decoded data is not affected
+	r = op.Worker(ctx, &xsql.RawTuple{Emitter: "test", Rawdata: []byte(`{"a":1}`), Timestamp: time.UnixMilli(111), Metadata: map[string]any{"topic": "demo"}})
+	assert.Equal(t, []any{&xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 1.0}, Timestamp: time.UnixMilli(111), Metadata: map[string]any{"topic": "demo"}}}, r)
+	select {
+	case d := <-ch:
+		tuple, ok := d.(*xsql.Tuple)
+		require.True(t, ok)
+		assert.Equal(t, []byte(`{"a":`), tuple.Message["payload"])
+		assert.Equal(t, "test", tuple.Message["stream"])
+		assert.Equal(t, "test1", tuple.Message["ruleId"])
+		assert.NotEmpty(t, tuple.Message["error"])
+		assert.Equal(t, xsql.Metadata{"topic": "demo"}, tuple.Metadata)
+	case <-time.After(time.Second):
+		t.Fatal("undecodable payload is not received")
+	}
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.SourceDecodeErrorCounter.WithLabelValues("test1", "decode_test")))
+}
+
 func TestPayloadDecodeWithSchema(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -64,7 +64,7 @@ func (w *EventTimeTrigger) getNextWindow(inputs []*xsql.Tuple, current time.Time
 			if nextTs == timex.Maxtime {
 				return nextTs
 			}
-			return getAlignedWindowEndTime(nextTs, w.window.RawInterval, w.window.TimeUnit)
+			return getWindowEndTime(nextTs, w.window)
 		}
 	case ast.SLIDING_WINDOW:
 		nextTs := getEarliestEventTs(inputs, current, watermark)
@@ -78,7 +78,7 @@ func (w *EventTimeTrigger) getNextSessionWindow(inputs []*xsql.Tuple, now time.T
 	if len(inputs) > 0 {
 		timeout, duration := w.window.Interval, w.window.Length
 		et := inputs[0].Timestamp
-		tick := getAlignedWindowEndTime(et, w.window.RawInterval, w.window.TimeUnit)
+		tick := getWindowEndTime(et, w.window)
 		p := time.Time{}
 		ticked := false
 		for _, tuple := range inputs {
@@ -126,6 +126,10 @@ func (o *WindowOperator) execEventWindow(ctx api.StreamContext, inputs []*xsql.T
 			case *xsql.WatermarkTuple:
 				ctx.GetLogger().Debug("WatermarkTuple", d.GetTimestamp())
 				watermarkTs := d.GetTimestamp()
+				if o.window.isPartitionedSession() {
+					inputs, _ = o.closeSessions(ctx, inputs, watermarkTs)
+					break
+				}
 				if o.window.Type == ast.SLIDING_WINDOW {
 					for len(o.delayTS) > 0 && (watermarkTs.After(o.delayTS[0]) || watermarkTs.Equal(o.delayTS[0])) {
 						inputs = o.scan(inputs, o.delayTS[0], ctx)
@@ -183,6 +187,13 @@ func (o *WindowOperator) execEventWindow(ctx api.StreamContext, inputs []*xsql.T
 				if o.triggerTime.IsZero() {
 					o.triggerTime = d.Timestamp
 				}
+				var accepted bool
+				if inputs, accepted = o.limitInputs(ctx, inputs); !accepted {
+					o.handleTraceDiscardTuple(ctx, []*xsql.Tuple{d})
+					o.span = nil
+					o.onProcessEnd(ctx)
+					break
+				}
 				if o.window.Type == ast.SLIDING_WINDOW && o.isMatchCondition(ctx, d) {
 					o.triggerTS = append(o.triggerTS, d.Timestamp)
 				}
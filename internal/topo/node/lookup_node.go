@@ -25,6 +25,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/lookup"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/lookup/cache"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node/tracenode"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
@@ -57,6 +58,8 @@ type LookupNode struct {
 	vals     []ast.Expr
 	fields   []string
 	keys     []string
+	// srcType is the type of the lookup source, which is traced as the external system
+	srcType string
 	// If lookupByteSource, the decoders are needed
 	isBytesLookup  bool
 	formatDecoder  message.Converter
@@ -80,6 +83,9 @@ func NewLookupNode(ctx api.StreamContext, name string, isBytesLookup bool, field
 		isBytesLookup: isBytesLookup,
 	}
 	n.defaultSinkNode = newDefaultSinkNode(name, options)
+	if srcOptions != nil {
+		n.srcType = srcOptions.TYPE
+	}
 	if isBytesLookup {
 		sc := &srcConf{}
 		e := cast.MapToStruct(props, sc)
@@ -237,6 +243,18 @@ func (n *LookupNode) lookup(ctx api.StreamContext, d xsql.Row, fv *xsql.Function
 	if e != nil {
 		return e
 	} else {
+		// EXISTS and NOT EXISTS only check whether the key is found, the original row is sent without merging
+		switch n.joinType {
+		case ast.SEMI_JOIN, ast.ANTI_JOIN:
+			if (len(r) > 0) == (n.joinType == ast.SEMI_JOIN) {
+				merged := &xsql.JoinTuple{}
+				merged.AddTuple(d)
+				tuples.Content = append(tuples.Content, merged)
+			} else {
+				ctx.GetLogger().Debugf("Lookup Node %s filter out tuple %s", n.name, d)
+			}
+			return nil
+		}
 		if len(r) == 0 {
 			if n.joinType == ast.LEFT_JOIN {
 				merged := &xsql.JoinTuple{}
@@ -264,7 +282,21 @@ func (n *LookupNode) lookup(ctx api.StreamContext, d xsql.Row, fv *xsql.Function
 	}
 }
 
+// doLookup reads the external source. The read is traced as the child span of the processing tuple.
 func (n *LookupNode) doLookup(ctx api.StreamContext, ns api.Source, cvs []any) ([]map[string]any, error) {
+	var parent api.StreamContext
+	if n.span != nil {
+		parent = n.spanCtx
+	}
+	traced, span := tracenode.TraceExternalCallFrom(ctx, parent, n.srcType)
+	r, err := n.readSource(ctx, ns, cvs)
+	if traced {
+		tracenode.EndExternalCall(span, err)
+	}
+	return r, err
+}
+
+func (n *LookupNode) readSource(ctx api.StreamContext, ns api.Source, cvs []any) ([]map[string]any, error) {
 	if n.isBytesLookup {
 		rawRows, err := ns.(api.LookupBytesSource).Lookup(ctx, n.fields, n.keys, cvs)
 		if err != nil {
@@ -607,6 +607,72 @@ func TestLookupInner(t *testing.T) {
 	}
 }
 
+func TestLookupExists(t *testing.T) {
+	found := &xsql.Tuple{Emitter: "stream1", Message: map[string]any{"a": 2}}
+	notFound := &xsql.Tuple{Emitter: "stream1", Message: map[string]any{"a": "empty"}}
+	wrong := &xsql.Tuple{Emitter: "stream1", Message: map[string]any{"a": "wrong"}}
+	tests := []struct {
+		name     string
+		joinType ast.JoinType
+		// the output of found, notFound and wrong in order, the nil output is skipped
+		result []any
+	}{
+		{
+			name:     "testExists",
+			joinType: ast.SEMI_JOIN,
+			result: []any{
+				&xsql.JoinTuples{Content: []*xsql.JoinTuple{{Tuples: []xsql.Row{found}}}},
+				errors.New("mock lookup error"),
+			},
+		},
+		{
+			name:     "testNotExists",
+			joinType: ast.ANTI_JOIN,
+			result: []any{
+				&xsql.JoinTuples{Content: []*xsql.JoinTuple{{Tuples: []xsql.Row{notFound}}}},
+				errors.New("mock lookup error"),
+			},
+		},
+	}
+	modules.RegisterLookupSource("mock", func() api.Source {
+		return &MockLookupBytes{}
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := mockContext.NewMockContext(tt.name, "test").WithCancel()
+			defer cancel()
+			op, err := NewLookupNode(ctx, tt.name, true, []string{"id"}, []string{"id"}, tt.joinType, []ast.Expr{&ast.FieldRef{
+				StreamName: "",
+				Name:       "a",
+			}}, &ast.Options{TYPE: "mock", FORMAT: "json"}, &def.RuleOption{BufferLength: 10, SendError: true}, map[string]any{})
+			assert.NoError(t, err)
+			out := make(chan any, 100)
+			assert.NoError(t, op.AddOutput(out, "test"))
+			err = lookup.CreateInstance(tt.name, "mock", &ast.Options{
+				DATASOURCE: tt.name,
+				TYPE:       "mock",
+				KIND:       "lookup",
+				KEY:        "id",
+			})
+			assert.NoError(t, err)
+			errCh := make(chan error)
+			op.Exec(ctx, errCh)
+			op.input <- found
+			op.input <- notFound
+			// the lookup error must not be taken as the key is not found
+			op.input <- wrong
+			for _, exp := range tt.result {
+				assert.Equal(t, exp, <-out)
+			}
+			select {
+			case r := <-out:
+				t.Errorf("unexpected output %v", r)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
 func TestLookupPayload(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -19,6 +19,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
@@ -30,9 +31,33 @@ func TestGetMetrics(t *testing.T) {
 	sm.SetBufferLength(20)
 	a := sm.GetMetrics()
 	e := []any{
-		int64(1), int64(0), int64(0), int64(0), int64(20), "current time", int64(0), "", int64(0),
+		int64(1), int64(0), int64(0), int64(0), int64(20), "current time", int64(0), "", int64(0), int64(0),
 	}
 	assert.Equal(t, e[:5], a[:5])
 	assert.NotEqual(t, "", a[5])
 	assert.Equal(t, e[6:], a[6:])
 }
+
+func TestLastExceptionCode(t *testing.T) {
+	ctx := mockContext.NewMockContext("rule1", "op1")
+	sm := NewStatManager(ctx, "op")
+	sm.IncTotalExceptions("server busy")
+	sm.SetLastExceptionCode(int64(errorx.TransientErr))
+	a := sm.GetMetrics()
+	assert.Len(t, a, 10)
+	assert.Equal(t, int64(1), a[6])
+	assert.Equal(t, "server busy", a[7])
+	assert.Equal(t, int64(errorx.TransientErr), a[9])
+	// the code is reset by the next exception
+	sm.IncTotalExceptions("plain error")
+	a = sm.GetMetrics()
+	assert.Equal(t, "plain error", a[7])
+	assert.Equal(t, int64(0), a[9])
+	// the code is before the connection metrics so that it has the same position for all nodes
+	sm = NewStatManager(ctx, "sink")
+	sm.IncTotalExceptions("disconnected")
+	sm.SetLastExceptionCode(int64(errorx.IOErr))
+	a = sm.GetMetrics()
+	assert.Len(t, a, len(MetricNames))
+	assert.Equal(t, int64(errorx.IOErr), a[9])
+}
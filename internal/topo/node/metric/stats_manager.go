@@ -36,15 +36,18 @@ const (
 	ConnectionLastDisconnectedTime    = "connection_last_disconnected_time"
 	ConnectionLastDisconnectedMessage = "connection_last_disconnected_message"
 	ConnectionLastTryTime             = "connection_last_try_time"
+	LastExceptionCode                 = "last_exception_code"
 )
 
-var MetricNames = []string{RecordsInTotal, RecordsOutTotal, MessagesProcessedTotal, ProcessLatencyUs, BufferLength, LastInvocation, ExceptionsTotal, LastException, LastExceptionTime, ConnectionStatus, ConnectionLastConnectedTime, ConnectionLastDisconnectedTime, ConnectionLastDisconnectedMessage, ConnectionLastTryTime}
+var MetricNames = []string{RecordsInTotal, RecordsOutTotal, MessagesProcessedTotal, ProcessLatencyUs, BufferLength, LastInvocation, ExceptionsTotal, LastException, LastExceptionTime, LastExceptionCode, ConnectionStatus, ConnectionLastConnectedTime, ConnectionLastDisconnectedTime, ConnectionLastDisconnectedMessage, ConnectionLastTryTime}
 
 type StatManager interface {
 	IncTotalRecordsIn()
 	IncTotalRecordsOut()
 	IncTotalMessagesProcessed(n int64)
 	IncTotalExceptions(err string)
+	// SetLastExceptionCode sets the error code of the last exception. It is reset by IncTotalExceptions.
+	SetLastExceptionCode(code int64)
 	ProcessTimeStart()
 	ProcessTimeEnd()
 	SetBufferLength(l int64)
@@ -69,6 +72,7 @@ type DefaultStatManager struct {
 	totalExceptions   int64
 	lastException     string
 	lastExceptionTime time.Time
+	lastExceptionCode int64
 
 	connectionState *ConnectionStatManager
 	// configs
@@ -137,6 +141,11 @@ func (sm *DefaultStatManager) IncTotalExceptions(err string) {
 	sm.processTimeStart = t
 	sm.lastException = err
 	sm.lastExceptionTime = time.Now()
+	sm.lastExceptionCode = 0
+}
+
+func (sm *DefaultStatManager) SetLastExceptionCode(code int64) {
+	sm.lastExceptionCode = code
 }
 
 func (sm *DefaultStatManager) ProcessTimeStart() {
@@ -162,9 +171,9 @@ func (sm *DefaultStatManager) SetProcessTimeStart(t time.Time) {
 func (sm *DefaultStatManager) GetMetrics() []any {
 	var result []any
 	if sm.connectionState != nil {
-		result = make([]any, 14)
+		result = make([]any, 15)
 	} else {
-		result = make([]any, 9)
+		result = make([]any, 10)
 	}
 	copy(result, []any{
 		sm.totalRecordsIn,
@@ -176,6 +185,7 @@ func (sm *DefaultStatManager) GetMetrics() []any {
 		sm.totalExceptions,
 		sm.lastException,
 		int64(0),
+		sm.lastExceptionCode,
 	})
 
 	if !sm.lastInvocation.IsZero() {
@@ -185,22 +195,22 @@ func (sm *DefaultStatManager) GetMetrics() []any {
 		result[8] = sm.lastExceptionTime.UnixMilli()
 	}
 	if sm.connectionState != nil {
-		result[9] = sm.connectionState.connStatus
+		result[10] = sm.connectionState.connStatus
 		if !sm.connectionState.lastConnectedTime.IsZero() {
-			result[10] = sm.connectionState.lastConnectedTime.UnixMilli()
+			result[11] = sm.connectionState.lastConnectedTime.UnixMilli()
 		} else {
-			result[10] = int64(0)
+			result[11] = int64(0)
 		}
 		if !sm.connectionState.lastDisconnectTime.IsZero() {
-			result[11] = sm.connectionState.lastDisconnectTime.UnixMilli()
+			result[12] = sm.connectionState.lastDisconnectTime.UnixMilli()
 		} else {
-			result[11] = int64(0)
+			result[12] = int64(0)
 		}
-		result[12] = sm.connectionState.lastDisconnect
+		result[13] = sm.connectionState.lastDisconnect
 		if !sm.connectionState.lastTryTime.IsZero() {
-			result[13] = sm.connectionState.lastTryTime.UnixMilli()
+			result[14] = sm.connectionState.lastTryTime.UnixMilli()
 		} else {
-			result[13] = int64(0)
+			result[14] = int64(0)
 		}
 	}
 	return result
@@ -18,8 +18,10 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
@@ -37,6 +39,7 @@ import (
 
 type defaultNode struct {
 	name        string
+	opType      string
 	concurrency int
 	sendError   bool
 	statManager metric.StatManager
@@ -51,6 +54,8 @@ type defaultNode struct {
 	spanCtx                  api.StreamContext
 	disableBufferFullDiscard bool
 	isStatManagerHostBySink  bool
+	// blocked is set when the node is waiting for the downstream buffer to have room
+	blocked atomic.Bool
 }
 
 func newDefaultNode(name string, options *def.RuleOption) *defaultNode {
@@ -156,7 +161,15 @@ func (o *defaultNode) doBroadcast(val any) {
 			select {
 			case out <- val:
 				continue
+			default:
+			}
+			o.blocked.Store(true)
+			select {
+			case out <- val:
+				o.blocked.Store(false)
+				continue
 			case <-o.ctx.Done():
+				o.blocked.Store(false)
 				return
 			}
 		}
@@ -215,6 +228,7 @@ func (o *defaultSinkNode) SetBarrierHandler(bh checkpoint.BarrierHandler) {
 
 func (o *defaultNode) prepareExec(ctx api.StreamContext, errCh chan<- error, opType string) {
 	ctx.GetLogger().Infof("%s started", o.name)
+	o.opType = opType
 	o.statManager = metric.NewStatManager(ctx, opType)
 	o.ctx = ctx
 	wg := ctx.Value(context.RuleWaitGroupKey)
@@ -291,7 +305,7 @@ func (o *defaultNode) onProcessStart(ctx api.StreamContext, val any) {
 	o.statManager.ProcessTimeStart()
 	// Source just pass nil val so that no trace. The trace will start after extracting trace id
 	if val != nil {
-		traced, spanCtx, span := tracenode.TraceInput(ctx, val, o.name)
+		traced, spanCtx, span := tracenode.TraceInput(ctx, val, o.name, trace.WithAttributes(attribute.String(tracenode.OpTypeKey, o.opType)))
 		if traced {
 			tracenode.RecordRowOrCollection(val, span)
 			o.span = span
@@ -328,6 +342,9 @@ func (o *defaultNode) onErrorOpt(ctx api.StreamContext, err error, sendOut bool)
 	}
 	if !o.isStatManagerHostBySink {
 		o.statManager.IncTotalExceptions(err.Error())
+		if code, ok := errorx.GetCode(err); ok {
+			o.statManager.SetLastExceptionCode(int64(code))
+		}
 	}
 	if o.span != nil {
 		o.span.RecordError(err)
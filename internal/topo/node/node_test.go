@@ -16,10 +16,12 @@ package node
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
 func TestOutputs(t *testing.T) {
@@ -34,3 +36,29 @@ func TestOutputs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(n.outputs))
 }
+
+func TestBackpressure(t *testing.T) {
+	n := newDefaultSinkNode("test", &def.RuleOption{BufferLength: 2, DisableBufferFullDiscard: true})
+	ctx, cancel := mockContext.NewMockContext("test", "test").WithCancel()
+	defer cancel()
+	n.ctx = ctx
+	out := make(chan any, 1)
+	assert.NoError(t, n.AddOutput(out, "rule.1_test"))
+	n.input <- 1
+	bp := n.GetBackpressure()
+	assert.Equal(t, &BackpressureState{BufferLength: 1, BufferCapacity: 2}, bp)
+
+	n.Broadcast(1)
+	assert.False(t, n.GetBackpressure().Blocked)
+	done := make(chan struct{})
+	go func() {
+		n.Broadcast(2)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		return n.GetBackpressure().Blocked
+	}, time.Second, 10*time.Millisecond)
+	<-out
+	<-done
+	assert.False(t, n.GetBackpressure().Blocked)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/failpoint"
 
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/checkpoint"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 )
@@ -30,6 +31,12 @@ type UnOperation interface {
 	Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) interface{}
 }
 
+// UnFlusher is implemented by the unary operations which hold the rows. The held rows are sent out before the
+// watermark or the EOF is sent out.
+type UnFlusher interface {
+	Flush(ctx api.StreamContext, item any) []xsql.Row
+}
+
 // UnFunc implements UnOperation as type func (context.Context, interface{})
 type UnFunc func(api.StreamContext, interface{}) interface{}
 
@@ -99,6 +106,7 @@ func (o *UnaryOperator) doOp(ctx api.StreamContext, errCh chan<- error) {
 		select {
 		// process incoming item
 		case item := <-o.input:
+			o.flush(exeCtx, item)
 			data, processed := o.commonIngest(ctx, item)
 			if processed {
 				break
@@ -129,3 +137,21 @@ func (o *UnaryOperator) doOp(ctx api.StreamContext, errCh chan<- error) {
 		}
 	}
 }
+
+// flush sends out the rows held by the operation if the item is a watermark or an EOF
+func (o *UnaryOperator) flush(ctx api.StreamContext, item any) {
+	f, ok := o.op.(UnFlusher)
+	if !ok {
+		return
+	}
+	if b, ok := item.(*checkpoint.BufferOrEvent); ok {
+		item = b.Data
+	}
+	switch item.(type) {
+	case *xsql.WatermarkTuple, xsql.EOFTuple:
+		for _, r := range f.Flush(ctx, item) {
+			o.Broadcast(r)
+			o.onSend(ctx, r)
+		}
+	}
+}
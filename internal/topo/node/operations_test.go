@@ -0,0 +1,72 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+// holdOp holds all the rows until the watermark or the EOF
+type holdOp struct {
+	held []xsql.Row
+}
+
+func (h *holdOp) Apply(_ api.StreamContext, data any, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) any {
+	h.held = append(h.held, data.(xsql.Row))
+	return nil
+}
+
+func (h *holdOp) Flush(_ api.StreamContext, _ any) []xsql.Row {
+	rows := h.held
+	h.held = nil
+	return rows
+}
+
+func TestUnaryFlush(t *testing.T) {
+	op := New("test", &def.RuleOption{BufferLength: 10})
+	op.SetOperation(&holdOp{})
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("testFlush", "op").WithCancel()
+	defer cancel()
+	op.Exec(ctx, make(chan error, 10))
+	t1 := &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 1}}
+	t2 := &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 2}}
+	wm := &xsql.WatermarkTuple{Timestamp: time.UnixMilli(1000)}
+	op.input <- t1
+	op.input <- wm
+	op.input <- t2
+	op.input <- xsql.EOFDrain
+	// the held rows are sent out before the watermark and the EOF
+	var result []any
+	for i := 0; i < 4; i++ {
+		select {
+		case r := <-out:
+			result = append(result, r)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	assert.Equal(t, []any{t1, wm, t2, xsql.EOFDrain}, result)
+}
@@ -16,10 +16,13 @@ package node
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
+	"github.com/lf-edge/ekuiper/v2/internal/compressor"
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/transform"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 )
 
@@ -40,6 +43,22 @@ type SinkConf struct {
 	Encryption     string            `json:"encryption"`
 	EncProps       map[string]any    `json:"encProps"`
 	HasHeader      bool              `json:"hasHeader"`
+	// BinaryField is the field to write verbatim for the binary format
+	BinaryField string `json:"binaryField"`
+	// When is the condition to route the results to this sink. The sink receives all the results if not set.
+	When string `json:"when"`
+	// RouteDefault makes the sink receive the results which do not meet the when condition of any other sink
+	RouteDefault bool `json:"routeDefault"`
+	// Ordered guarantees the results are delivered in the order they arrive. A failed send blocks the following results
+	// until it succeeds or fails with an unrecoverable error.
+	Ordered bool `json:"ordered"`
+	// ProcessedAtField is the field to stamp the time in milliseconds when the result is processed by the sink
+	ProcessedAtField string `json:"processedAtField"`
+	// Reshape restructures the result rows before the data template and fields are applied
+	Reshape *transform.Reshape `json:"reshape"`
+	// FieldMapping renames the result fields, and NameCase converts the other field names to the case
+	FieldMapping map[string]string `json:"fieldMapping"`
+	NameCase     string            `json:"nameCase"`
 	conf.SinkConf
 }
 
@@ -63,6 +82,9 @@ func ParseConf(logger api.Logger, props map[string]any) (*SinkConf, error) {
 	if sconf.Format == "" {
 		sconf.Format = "json"
 	}
+	if sconf.Compression == compressor.NONE {
+		sconf.Compression = ""
+	}
 	err = cast.MapToStruct(props, &sconf.SinkConf)
 	if err != nil {
 		return nil, fmt.Errorf("read properties %v to cache conf fail with error: %v", props, err)
@@ -78,6 +100,32 @@ func ParseConf(logger api.Logger, props map[string]any) (*SinkConf, error) {
 	if sconf.LingerInterval < 0 {
 		return nil, fmt.Errorf("invalid lingerInterval %v, must be positive", sconf.LingerInterval)
 	}
+	if sconf.When != "" && sconf.RouteDefault {
+		return nil, fmt.Errorf("when and routeDefault cannot be set together")
+	}
+	if sconf.Ordered {
+		if sconf.Concurrency > 1 {
+			return nil, fmt.Errorf("concurrency must be 1 in ordered mode but found %d", sconf.Concurrency)
+		}
+		// The resent results in the alter queue are delivered along with the newer results
+		if sconf.EnableCache && sconf.ResendAlterQueue {
+			return nil, fmt.Errorf("resendAlterQueue cannot be enabled in ordered mode")
+		}
+		// Retry in place instead of dropping the failed result and sending the next
+		if sconf.ResendInterval == 0 {
+			sconf.ResendInterval = cast.DurationConf(100 * time.Millisecond)
+		}
+	}
+	if sconf.Reshape != nil {
+		if err := sconf.Reshape.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid reshape: %v", err)
+		}
+	}
+	naming := &transform.FieldNaming{Mapping: sconf.FieldMapping, Case: sconf.NameCase}
+	if err := naming.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid field naming: %v", err)
+	}
+	sconf.NameCase = naming.Case
 	err = sconf.SinkConf.Validate()
 	if err != nil {
 		return nil, fmt.Errorf("invalid cache properties: %v", err)
@@ -0,0 +1,108 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+// closeSessions emits the sessions of the partitioned session window which end before or at now. The tuples of each
+// group key are split into sessions by the gaps not less than the timeout, and a session ends at the timestamp of its
+// last tuple plus the timeout. The closed sessions are emitted in the order of their end time. It returns the tuples of
+// the open sessions and the earliest end time of them, which is timex.Maxtime if there is no open session.
+func (o *WindowOperator) closeSessions(ctx api.StreamContext, inputs []*xsql.Tuple, now time.Time) ([]*xsql.Tuple, time.Time) {
+	log := ctx.GetLogger()
+	timeout := o.window.Interval
+	fv, _ := xsql.NewFunctionValuersForOp(ctx)
+	var keys []string
+	groups := make(map[string][]*xsql.Tuple)
+	for _, tuple := range inputs {
+		key := calDimension(fv, o.window.Dimensions, tuple)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], tuple)
+	}
+	type session struct {
+		end     time.Time
+		results *xsql.WindowTuples
+	}
+	var (
+		closed    []session
+		discarded []*xsql.Tuple
+		rest      = make([]*xsql.Tuple, 0, len(inputs))
+		next      = timex.Maxtime
+	)
+	for _, key := range keys {
+		tuples := groups[key]
+		// An out of order tuple extends the session it falls in or merges the adjacent sessions
+		sort.SliceStable(tuples, func(i, j int) bool {
+			return tuples[i].Timestamp.Before(tuples[j].Timestamp)
+		})
+		start := 0
+		for i := 1; i <= len(tuples); i++ {
+			if i < len(tuples) && tuples[i].Timestamp.Sub(tuples[i-1].Timestamp) < timeout {
+				continue
+			}
+			end := tuples[i-1].Timestamp.Add(timeout)
+			if end.After(now) {
+				// The following sessions of the key may still be merged, keep them all
+				rest = append(rest, tuples[start:]...)
+				if end.Before(next) {
+					next = end
+				}
+				break
+			}
+			content := make([]xsql.Row, 0, i-start)
+			for _, tuple := range tuples[start:i] {
+				content = append(content, tuple)
+			}
+			discarded = append(discarded, tuples[start:i]...)
+			log.Debugf("session of %s closed at %d with %d tuples", key, end.UnixMilli(), len(content))
+			closed = append(closed, session{end: end, results: &xsql.WindowTuples{
+				Content:     content,
+				WindowRange: xsql.NewWindowRange(tuples[start].Timestamp.UnixMilli(), end.UnixMilli()),
+			}})
+			start = i
+		}
+	}
+	if len(closed) > 0 {
+		o.statManager.ProcessTimeStart()
+		sort.SliceStable(closed, func(i, j int) bool {
+			return closed[i].end.Before(closed[j].end)
+		})
+		for _, c := range closed {
+			results := c.results
+			o.handleTraceEmitTuple(ctx, results)
+			log.Debugf("Sent: %v", results)
+			o.Broadcast(results)
+			o.onSend(ctx, results)
+		}
+		o.handleTraceDiscardTuple(ctx, discarded)
+		o.statManager.ProcessTimeEnd()
+	}
+	// Keep the inputs in timestamp order so that the oldest row is dropped first by the limit
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].Timestamp.Before(rest[j].Timestamp)
+	})
+	_ = ctx.PutState(WindowInputsKey, rest)
+	return rest, next
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
+)
+
+func newSessionOp(t *testing.T, isEventTime bool) (*WindowOperator, chan any) {
+	op, err := NewWindowOp("test", WindowConfig{
+		Type:     ast.SESSION_WINDOW,
+		Interval: 2 * time.Second,
+		TimeUnit: ast.SS,
+		Dimensions: ast.Dimensions{
+			{Expr: &ast.FieldRef{Name: "id", StreamName: ast.DefaultStream}},
+		},
+	}, &def.RuleOption{BufferLength: 10, IsEventTime: isEventTime})
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("testSession", "window").WithCancel()
+	t.Cleanup(cancel)
+	op.Exec(ctx, make(chan error, 10))
+	time.Sleep(10 * time.Millisecond)
+	return op, out
+}
+
+func receiveSession(t *testing.T, out chan any) *xsql.WindowTuples {
+	select {
+	case r := <-out:
+		wt, ok := r.(*xsql.WindowTuples)
+		require.True(t, ok)
+		return wt
+	case <-time.After(time.Second):
+		t.Fatal("session is not closed")
+		return nil
+	}
+}
+
+func assertNoSession(t *testing.T, out chan any) {
+	select {
+	case r := <-out:
+		t.Fatalf("unexpected output %v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPartitionedSessionGap(t *testing.T) {
+	op, out := newSessionOp(t, false)
+	start := timex.GetNow()
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 1}, Timestamp: timex.GetNow()}
+	time.Sleep(10 * time.Millisecond)
+	timex.Add(time.Second)
+	time.Sleep(10 * time.Millisecond)
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 2, "v": 2}, Timestamp: timex.GetNow()}
+	time.Sleep(10 * time.Millisecond)
+	timex.Add(500 * time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	// extends the session of id 1 which would timeout at 2s
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 3}, Timestamp: timex.GetNow()}
+	time.Sleep(10 * time.Millisecond)
+	timex.Add(time.Second)
+	assertNoSession(t, out)
+	// id 2 times out at 3s while id 1 is still open
+	timex.Add(500 * time.Millisecond)
+	wt := receiveSession(t, out)
+	assert.Equal(t, []map[string]any{{"id": 2, "v": 2}}, wt.ToMaps())
+	assert.Equal(t, xsql.NewWindowRange(start.Add(time.Second).UnixMilli(), start.Add(3*time.Second).UnixMilli()), wt.WindowRange)
+	assertNoSession(t, out)
+	timex.Add(500 * time.Millisecond)
+	wt = receiveSession(t, out)
+	assert.Equal(t, []map[string]any{{"id": 1, "v": 1}, {"id": 1, "v": 3}}, wt.ToMaps())
+	assert.Equal(t, xsql.NewWindowRange(start.UnixMilli(), start.Add(3500*time.Millisecond).UnixMilli()), wt.WindowRange)
+	// the expired groups are not kept, no more session is emitted
+	timex.Add(5 * time.Second)
+	assertNoSession(t, out)
+	// a new session of the expired group
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 4}, Timestamp: timex.GetNow()}
+	time.Sleep(10 * time.Millisecond)
+	timex.Add(2 * time.Second)
+	wt = receiveSession(t, out)
+	assert.Equal(t, []map[string]any{{"id": 1, "v": 4}}, wt.ToMaps())
+}
+
+func TestPartitionedSessionFlushOnDrain(t *testing.T) {
+	op, out := newSessionOp(t, false)
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1}, Timestamp: timex.GetNow()}
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 2}, Timestamp: timex.GetNow()}
+	op.input <- xsql.EOFDrain
+	keys := make([]any, 0, 2)
+	for i := 0; i < 2; i++ {
+		wt := receiveSession(t, out)
+		require.Len(t, wt.Content, 1)
+		keys = append(keys, wt.ToMaps()[0]["id"])
+	}
+	assert.ElementsMatch(t, []any{1, 2}, keys)
+	select {
+	case r := <-out:
+		assert.Equal(t, xsql.EOFDrain, r)
+	case <-time.After(time.Second):
+		t.Fatal("EOF is not sent out")
+	}
+}
+
+func TestPartitionedSessionEventTime(t *testing.T) {
+	op, out := newSessionOp(t, true)
+	ts := func(milli int64) time.Time {
+		return time.UnixMilli(milli)
+	}
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 1}, Timestamp: ts(1000)}
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 2, "v": 2}, Timestamp: ts(1500)}
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 3}, Timestamp: ts(3500)}
+	// out of order, it fills the gap and merges the two sessions of id 1
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 4}, Timestamp: ts(2200)}
+	op.input <- &xsql.WatermarkTuple{Timestamp: ts(3000)}
+	assertNoSession(t, out)
+	op.input <- &xsql.WatermarkTuple{Timestamp: ts(4000)}
+	wt := receiveSession(t, out)
+	assert.Equal(t, []map[string]any{{"id": 2, "v": 2}}, wt.ToMaps())
+	assert.Equal(t, xsql.NewWindowRange(1500, 3500), wt.WindowRange)
+	assertNoSession(t, out)
+	op.input <- &xsql.WatermarkTuple{Timestamp: ts(6000)}
+	wt = receiveSession(t, out)
+	assert.Equal(t, []map[string]any{{"id": 1, "v": 1}, {"id": 1, "v": 4}, {"id": 1, "v": 3}}, wt.ToMaps())
+	assert.Equal(t, xsql.NewWindowRange(1000, 5500), wt.WindowRange)
+	// the expired groups are not kept, no more session is emitted
+	op.input <- &xsql.WatermarkTuple{Timestamp: ts(10000)}
+	assertNoSession(t, out)
+}
+
+func TestPartitionedSessionEventTimeSplit(t *testing.T) {
+	op, out := newSessionOp(t, true)
+	// the gap is not less than the timeout, so the events of the same group are in two sessions
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 1}, Timestamp: time.UnixMilli(1000)}
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"id": 1, "v": 2}, Timestamp: time.UnixMilli(3000)}
+	op.input <- &xsql.WatermarkTuple{Timestamp: time.UnixMilli(5000)}
+	wt := receiveSession(t, out)
+	assert.Equal(t, []map[string]any{{"id": 1, "v": 1}}, wt.ToMaps())
+	assert.Equal(t, xsql.NewWindowRange(1000, 3000), wt.WindowRange)
+	wt = receiveSession(t, out)
+	assert.Equal(t, []map[string]any{{"id": 1, "v": 2}}, wt.ToMaps())
+	assert.Equal(t, xsql.NewWindowRange(3000, 5000), wt.WindowRange)
+}
@@ -16,6 +16,7 @@ package node
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -23,6 +24,7 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/deadletter"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -40,10 +42,21 @@ type SinkNode struct {
 	sink           api.Sink
 	eoflimit       int
 	currentEof     int
+	drained        chan struct{} // closed when receiving all the EOF
 	resendInterval time.Duration
 	doCollect      func(ctx api.StreamContext, sink api.Sink, data any) error
 	// channel for resend
 	resendOut chan<- any
+	// deadLetter saves the results which fail to deliver permanently
+	deadLetter bool
+	// replayCh receives the dead letters replayed by the api
+	replayCh chan *replayRequest
+}
+
+// replayRequest is a dead letter to be sent by the sink loop, the result is sent back by the channel
+type replayRequest struct {
+	data   any
+	result chan error
 }
 
 // Caching:
@@ -63,7 +76,10 @@ func newSinkNode(ctx api.StreamContext, name string, rOpt def.RuleOption, eoflim
 	return &SinkNode{
 		defaultSinkNode: newDefaultSinkNode(name, &rOpt),
 		eoflimit:        eoflimit,
+		drained:         make(chan struct{}),
 		resendInterval:  retry,
+		deadLetter:      sc.DeadLetter,
+		replayCh:        make(chan *replayRequest),
 	}
 }
 
@@ -86,7 +102,13 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 			if err != nil {
 				infra.DrainError(ctx, err, errCh)
 			}
+			if s.deadLetter {
+				deadletter.Register(ctx.GetRuleId(), s.name, s.replayFunc(ctx))
+			}
 			defer func() {
+				if s.deadLetter {
+					deadletter.Unregister(ctx.GetRuleId(), s.name)
+				}
 				s.sink.Close(ctx)
 				s.Close()
 			}()
@@ -95,6 +117,12 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 				select {
 				case <-ctx.Done():
 					return nil
+				case req := <-s.replayCh:
+					err := s.doCollect(ctx, s.sink, req.data)
+					if err == nil {
+						s.onSend(ctx, req.data)
+					}
+					req.result <- err
 				case d := <-s.input:
 					data, processed := s.ingest(ctx, d)
 					if processed {
@@ -116,12 +144,13 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 								}
 							})
 						} else if s.resendInterval > 0 {
-							if !errorx.IsIOError(err) {
-								ctx.GetLogger().Errorf("no io error %v, drop %v", err, data)
+							if !errorx.IsRetryable(err) {
+								ctx.GetLogger().Errorf("no retryable error %v, drop %v", err, data)
+								s.saveDeadLetter(ctx, data, err)
 							} else {
 								ticker := timex.GetTicker(s.resendInterval)
 								defer ticker.Stop()
-								for err != nil && errorx.IsIOError(err) {
+								for err != nil && errorx.IsRetryable(err) {
 									ctx.GetLogger().Debugf("wait resending %v", data)
 									select {
 									case <-ctx.Done():
@@ -136,9 +165,12 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 									ctx.GetLogger().Debugf("resend success %v", data)
 									s.onSend(ctx, data)
 								} else {
-									ctx.GetLogger().Debugf("no io error %v", err)
+									ctx.GetLogger().Debugf("no retryable error %v", err)
+									s.saveDeadLetter(ctx, data, err)
 								}
 							}
+						} else {
+							s.saveDeadLetter(ctx, data, err)
 						}
 					} else {
 						s.onSend(ctx, data)
@@ -154,13 +186,86 @@ func (s *SinkNode) Exec(ctx api.StreamContext, errCh chan<- error) {
 	}()
 }
 
+// saveDeadLetter persists the result which fails to deliver permanently if the dead letter is enabled
+func (s *SinkNode) saveDeadLetter(ctx api.StreamContext, data any, err error) {
+	if !s.deadLetter {
+		return
+	}
+	e := &deadletter.Entry{
+		RuleId:    ctx.GetRuleId(),
+		Sink:      s.name,
+		Error:     err.Error(),
+		Timestamp: timex.GetNowInMilli(),
+	}
+	switch d := data.(type) {
+	case api.RawTuple:
+		e.Raw = d.Raw()
+	case api.MessageTupleList:
+		e.Messages = d.ToMaps()
+		e.IsList = true
+	case api.MessageTuple:
+		e.Messages = []map[string]any{d.ToMap()}
+	default:
+		// the errors sent to the sink are not kept
+		return
+	}
+	if dp, ok := data.(api.HasDynamicProps); ok {
+		e.Props = dp.AllProps()
+	}
+	if err := deadletter.Save(e); err != nil {
+		ctx.GetLogger().Errorf("save dead letter of %v error: %v", data, err)
+		return
+	}
+	ctx.GetLogger().Infof("save dead letter %s for error %v", e.Id, err)
+}
+
+// replayFunc returns the function to send the dead letter in the sink loop and wait for the result
+func (s *SinkNode) replayFunc(ctx api.StreamContext) deadletter.ReplayFunc {
+	return func(e *deadletter.Entry) error {
+		req := &replayRequest{data: fromDeadLetter(e), result: make(chan error, 1)}
+		select {
+		case s.replayCh <- req:
+		case <-ctx.Done():
+			return errors.New("the rule is stopped")
+		}
+		select {
+		case err := <-req.result:
+			return err
+		case <-ctx.Done():
+			return errors.New("the rule is stopped")
+		}
+	}
+}
+
+// fromDeadLetter converts the dead letter back to the data received by the sink
+func fromDeadLetter(e *deadletter.Entry) any {
+	now := timex.GetNow()
+	if e.Raw != nil {
+		return &xsql.RawTuple{Rawdata: e.Raw, Props: e.Props, Timestamp: now}
+	}
+	tuples := make([]api.MessageTuple, len(e.Messages))
+	for i, m := range e.Messages {
+		tuples[i] = &xsql.Tuple{Message: m, Props: e.Props, Timestamp: now}
+	}
+	if e.IsList || len(tuples) != 1 {
+		return &xsql.TransformedTupleList{Content: tuples, Maps: e.Messages, Props: e.Props}
+	}
+	return tuples[0]
+}
+
 func (s *SinkNode) SetResendOutput(output chan<- any) {
 	s.resendOut = output
 }
 
+// Drained returns the channel which is closed after the sink receives the EOF from all the inputs
+func (s *SinkNode) Drained() <-chan struct{} {
+	return s.drained
+}
+
 func (s *SinkNode) connectionStatusChange(status string, message string) {
 	if status == api.ConnectionDisconnected {
 		s.statManager.IncTotalExceptions(message)
+		s.statManager.SetLastExceptionCode(int64(errorx.IOErr))
 	}
 	s.statManager.SetConnectionState(status, message)
 }
@@ -182,6 +287,7 @@ func (s *SinkNode) ingest(ctx api.StreamContext, item any) (any, bool) {
 	case xsql.EOFTuple:
 		s.currentEof++
 		if s.eoflimit == s.currentEof {
+			close(s.drained)
 			infra.DrainError(ctx, errorx.NewEOF(), s.ctrlCh)
 		}
 		return nil, true
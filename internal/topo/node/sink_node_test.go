@@ -20,8 +20,10 @@ import (
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/deadletter"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
@@ -98,6 +100,28 @@ func TestNewSinkNode(t *testing.T) {
 	}
 }
 
+func TestSinkDrained(t *testing.T) {
+	ctx := mockContext.NewMockContext("testSink", "sink")
+	n := newSinkNode(ctx, "test", def.RuleOption{BufferLength: 1024}, 2, &conf.SinkConf{MemoryCacheThreshold: 10}, false)
+	errCh := make(chan error, 2)
+	n.prepareExec(ctx, errCh, "sink")
+	_, processed := n.ingest(ctx, xsql.EOFDrain)
+	assert.True(t, processed)
+	select {
+	case <-n.Drained():
+		t.Fatal("should not be drained before receiving all EOF")
+	default:
+	}
+	_, processed = n.ingest(ctx, xsql.EOFDrain)
+	assert.True(t, processed)
+	select {
+	case <-n.Drained():
+	default:
+		t.Fatal("should be drained")
+	}
+	assert.True(t, errorx.IsEOF(<-errCh))
+}
+
 func TestRetry(t *testing.T) {
 	ctx, cancel := mockContext.NewMockContext("resendout", "sink").WithCancel()
 	s := &mockResendSink{failTimes: 2}
@@ -163,6 +187,99 @@ func TestResendOut(t *testing.T) {
 	assert.True(t, got)
 }
 
+func TestOrderedSink(t *testing.T) {
+	conf.InitConf()
+	ctx, cancel := mockContext.NewMockContext("ordered", "sink").WithCancel()
+	defer cancel()
+	sc, err := ParseConf(ctx.GetLogger(), map[string]any{"ordered": true})
+	assert.NoError(t, err)
+	assert.Equal(t, cast.DurationConf(100*time.Millisecond), sc.ResendInterval)
+	s := &mockOrderedSink{failTimes: 2, out: make(chan any, 2)}
+	n, err := NewBytesSinkNode(ctx, "ordered_sink", s, def.RuleOption{
+		BufferLength: 1024,
+	}, 1, &sc.SinkConf, false)
+	assert.NoError(t, err)
+	errCh := make(chan error, 1)
+	n.Exec(ctx, errCh)
+	first := &xsql.RawTuple{Timestamp: time.UnixMilli(1)}
+	second := &xsql.RawTuple{Timestamp: time.UnixMilli(2)}
+	n.input <- first
+	n.input <- second
+	var got []any
+	for len(got) < 2 {
+		select {
+		case d := <-s.out:
+			got = append(got, d)
+		case e := <-errCh:
+			assert.NoError(t, e)
+		default:
+			timex.Add(50 * time.Millisecond)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	// the first one is retried until success before sending the second one
+	assert.Equal(t, []any{first, second}, got)
+}
+
+func TestDeadLetterSink(t *testing.T) {
+	conf.InitConf()
+	ctx, cancel := mockContext.NewMockContext("deadLetterRule", "sink").WithCancel()
+	defer cancel()
+	s := &mockOrderedSink{failTimes: 1, out: make(chan any, 1)}
+	n, err := NewBytesSinkNode(ctx, "dl_sink", s, def.RuleOption{
+		BufferLength: 1024,
+	}, 1, &conf.SinkConf{DeadLetter: true}, false)
+	require.NoError(t, err)
+	errCh := make(chan error, 1)
+	n.Exec(ctx, errCh)
+	n.input <- &xsql.RawTuple{Rawdata: []byte("hello"), Props: map[string]string{"{{.topic}}": "t1"}, Timestamp: time.UnixMilli(1)}
+	var entries []*deadletter.Entry
+	require.Eventually(t, func() bool {
+		entries, err = deadletter.List("deadLetterRule")
+		return err == nil && len(entries) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	e := entries[0]
+	assert.Equal(t, "dl_sink", e.Sink)
+	assert.Equal(t, "fake error", e.Error)
+	assert.Equal(t, []byte("hello"), e.Raw)
+	assert.Equal(t, map[string]string{"{{.topic}}": "t1"}, e.Props)
+
+	// replay to the running sink which is recovered
+	require.NoError(t, deadletter.Replay("deadLetterRule", e.Id))
+	d := <-s.out
+	assert.Equal(t, []byte("hello"), d.(api.RawTuple).Raw())
+	assert.Equal(t, map[string]string{"{{.topic}}": "t1"}, d.(api.HasDynamicProps).AllProps())
+	entries, err = deadletter.List("deadLetterRule")
+	require.NoError(t, err)
+	assert.Len(t, entries, 0)
+}
+
+type mockOrderedSink struct {
+	failTimes int
+	out       chan any
+}
+
+func (m *mockOrderedSink) Provision(ctx api.StreamContext, configs map[string]any) error {
+	return nil
+}
+
+func (m *mockOrderedSink) Close(ctx api.StreamContext) error {
+	return nil
+}
+
+func (m *mockOrderedSink) Connect(ctx api.StreamContext, _ api.StatusChangeHandler) error {
+	return nil
+}
+
+func (m *mockOrderedSink) Collect(ctx api.StreamContext, item api.RawTuple) error {
+	if m.failTimes > 0 {
+		m.failTimes--
+		return errorx.NewIOErr("fake error")
+	}
+	m.out <- item
+	return nil
+}
+
 type mockResendSink struct {
 	failTimes int
 	val       any
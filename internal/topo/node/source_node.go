@@ -16,12 +16,14 @@ package node
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/sig"
@@ -40,9 +42,23 @@ import (
 type SourceNode struct {
 	*defaultNode
 
-	s         api.Source
+	s api.Source
+	// readers are the parallel instances of the partitioned source, the first one is s
+	readers   []api.Source
 	interval  time.Duration
 	notifySub bool
+	// ingestMu serializes the ingestion of the parallel readers
+	ingestMu sync.Mutex
+	// eofCount is the number of the readers which have sent EOF
+	eofCount int
+	// drainMu guards the ingestion against the drain so that the EOF is the last message sent out
+	drainMu  sync.RWMutex
+	draining bool
+	// committers commit the offsets of the completed checkpoints if checkpoint is enabled, one for each reader
+	committers []model.OffsetCommitter
+	// pendingOffsets are the offsets of the checkpoints which are not completed yet, [checkpointId]offsets of committers
+	pendingMu      sync.Mutex
+	pendingOffsets map[int64][]any
 }
 
 type sourceConf struct {
@@ -51,28 +67,88 @@ type sourceConf struct {
 
 // NewSourceNode creates a SourceConnectorNode
 func NewSourceNode(ctx api.StreamContext, name string, ss api.Source, props map[string]any, rOpt *def.RuleOption) (*SourceNode, error) {
-	err := ss.Provision(ctx, props)
+	ss, resolved, err := provisionSource(ctx, name, ss, props)
 	if err != nil {
 		return nil, err
 	}
-	ctx.GetLogger().Infof("provision source %s with props %+v", name, props)
+	return newSourceNode(name, []api.Source{ss}, resolved, rOpt)
+}
+
+// NewPartitionedSourceNode creates a SourceNode which reads the partitioned source by concurrency parallel readers.
+// Each reader is a new source instance provisioned with the props of its partition. All readers feed the same node,
+// so the data is only ordered inside each partition and the metrics of the node are the aggregation of all readers.
+// The first reader is ss and the others are created by newSource.
+func NewPartitionedSourceNode(ctx api.StreamContext, name string, ss api.Source, newSource func() (api.Source, error), props map[string]any, rOpt *def.RuleOption, concurrency int) (*SourceNode, error) {
+	readers := make([]api.Source, 0, concurrency)
+	var resolved map[string]any
+	for i := 0; i < concurrency; i++ {
+		if i > 0 {
+			var err error
+			ss, err = newSource()
+			if err != nil {
+				return nil, err
+			}
+		}
+		ps, ok := ss.(model.PartitionedSource)
+		if !ok {
+			return nil, fmt.Errorf("source %s does not support concurrency", name)
+		}
+		pp, err := ps.PartitionProps(props, i, concurrency)
+		if err != nil {
+			return nil, err
+		}
+		reader, r, err := provisionSource(ctx, fmt.Sprintf("%s partition %d", name, i), ss, pp)
+		if err != nil {
+			return nil, err
+		}
+		resolved = r
+		readers = append(readers, reader)
+	}
+	return newSourceNode(name, readers, resolved, rOpt)
+}
+
+func provisionSource(ctx api.StreamContext, name string, ss api.Source, props map[string]any) (api.Source, map[string]any, error) {
+	// Only log the unresolved props to avoid exposing the secrets
+	resolved, err := conf.ResolveProps(props)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = ss.Provision(ctx, resolved)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx.GetLogger().Infof("provision source %s with props %+v", name, conf.Printable(props))
 	if sit, ok := ss.(model.InfoNode); ok {
 		ss = sit.TransformType()
 	}
+	return ss, resolved, nil
+}
+
+func newSourceNode(name string, readers []api.Source, resolved map[string]any, rOpt *def.RuleOption) (*SourceNode, error) {
 	cc := &sourceConf{}
-	err = cast.MapToStruct(props, cc)
+	err := cast.MapToStruct(resolved, cc)
 	if err != nil {
 		return nil, err
 	}
 	m := &SourceNode{
 		defaultNode: newDefaultNode(name, rOpt),
-		s:           ss,
+		s:           readers[0],
+		readers:     readers,
 		interval:    time.Duration(cc.Interval),
 		notifySub:   rOpt.NotifySub,
 	}
-	switch st := ss.(type) {
-	case api.Bounded:
-		st.SetEofIngest(m.ingestEof)
+	for _, ss := range readers {
+		switch st := ss.(type) {
+		case api.Bounded:
+			st.SetEofIngest(m.ingestEof)
+		}
+		if oc, ok := ss.(model.OffsetCommitter); ok && rOpt.Qos >= def.AtLeastOnce {
+			oc.EnableCheckpointCommit()
+			m.committers = append(m.committers, oc)
+		}
+	}
+	if len(m.committers) > 0 {
+		m.pendingOffsets = make(map[int64][]any)
 	}
 	return m, nil
 }
@@ -84,6 +160,12 @@ func (m *SourceNode) Open(ctx api.StreamContext, ctrlCh chan<- error) {
 }
 
 func (m *SourceNode) ingestBytes(ctx api.StreamContext, data []byte, meta map[string]any, ts time.Time) {
+	if !m.acquireIngest(ctx) {
+		return
+	}
+	defer m.drainMu.RUnlock()
+	m.ingestMu.Lock()
+	defer m.ingestMu.Unlock()
 	ctx.GetLogger().Debugf("source connector %s receive data %+v", m.name, data)
 	m.onProcessStart(ctx, nil)
 	if meta == nil {
@@ -107,7 +189,7 @@ func (m *SourceNode) traceStart(ctx api.StreamContext, meta map[string]any, tupl
 		span     trace.Span
 	)
 	setType := false
-	opts := make([]trace.SpanStartOption, 0)
+	opts := []trace.SpanStartOption{trace.WithAttributes(attribute.String(tracenode.OpTypeKey, m.opType))}
 	rawKind, ok := meta["sourceKind"]
 	if ok {
 		kind, ok := rawKind.(string)
@@ -138,6 +220,12 @@ func (m *SourceNode) traceStart(ctx api.StreamContext, meta map[string]any, tupl
 }
 
 func (m *SourceNode) ingestAnyTuple(ctx api.StreamContext, data any, meta map[string]any, ts time.Time) {
+	if !m.acquireIngest(ctx) {
+		return
+	}
+	defer m.drainMu.RUnlock()
+	m.ingestMu.Lock()
+	defer m.ingestMu.Unlock()
 	ctx.GetLogger().Debugf("source connector %s receive data %+v", m.name, data)
 	m.onProcessStart(ctx, nil)
 	if meta == nil {
@@ -197,7 +285,7 @@ func (m *SourceNode) ingestMap(t map[string]any, meta map[string]any, ts time.Ti
 func (m *SourceNode) ingestTuple(t *xsql.Tuple, ts time.Time) {
 	tuple := &xsql.Tuple{Emitter: m.name, Message: t.Message, Timestamp: ts, Metadata: t.Metadata, Ctx: t.Ctx}
 	// If receiving tuple, its source is still in the system. So continue tracing
-	traced, spanCtx, span := tracenode.TraceInput(m.ctx, tuple, m.name)
+	traced, spanCtx, span := tracenode.TraceInput(m.ctx, tuple, m.name, trace.WithAttributes(attribute.String(tracenode.OpTypeKey, m.opType)))
 	if traced {
 		tracenode.RecordRowOrCollection(tuple, span)
 		m.span = span
@@ -208,14 +296,56 @@ func (m *SourceNode) ingestTuple(t *xsql.Tuple, ts time.Time) {
 }
 
 func (m *SourceNode) ingestError(ctx api.StreamContext, err error) {
+	m.ingestMu.Lock()
+	defer m.ingestMu.Unlock()
 	m.onError(ctx, err)
 }
 
 func (m *SourceNode) ingestEof(ctx api.StreamContext) {
+	m.drainMu.RLock()
+	defer m.drainMu.RUnlock()
+	// The EOF is already sent out by the drain
+	if m.draining {
+		return
+	}
+	// Only send out EOF when all the parallel readers reach the end
+	m.ingestMu.Lock()
+	m.eofCount++
+	done := m.eofCount >= len(m.readers)
+	m.ingestMu.Unlock()
+	if !done {
+		return
+	}
 	ctx.GetLogger().Infof("send out EOF")
 	m.Broadcast(xsql.EOFTuple(0))
 }
 
+// acquireIngest returns false if the source is draining. In that case, it holds the caller until the rule is closed
+// so that the source does not consume more data and the offset does not move forward. If it returns true, the caller
+// must release the read lock after ingesting.
+func (m *SourceNode) acquireIngest(ctx api.StreamContext) bool {
+	m.drainMu.RLock()
+	if m.draining {
+		m.drainMu.RUnlock()
+		<-ctx.Done()
+		return false
+	}
+	return true
+}
+
+// Drain stops ingesting new data and sends out EOF so that the downstream nodes can flush the pending data.
+// It waits for the ongoing ingestion to finish so the EOF is always the last message of the source.
+func (m *SourceNode) Drain(ctx api.StreamContext) {
+	m.drainMu.Lock()
+	defer m.drainMu.Unlock()
+	if m.draining {
+		return
+	}
+	m.draining = true
+	ctx.GetLogger().Infof("source %s is draining, send out EOF", m.name)
+	m.Broadcast(xsql.EOFDrain)
+}
+
 // GetSource only used for test
 func (m *SourceNode) GetSource() api.Source {
 	return m.s
@@ -226,15 +356,16 @@ const (
 )
 
 func (m *SourceNode) Rewind(ctx api.StreamContext) error {
-	s := m.s
-	if rw, ok := s.(api.Rewindable); ok {
-		if offset, err := ctx.GetState(OffsetKey); err != nil {
-			return err
-		} else if offset != nil {
-			ctx.GetLogger().Infof("Source rewind from %v", offset)
-			err = rw.Rewind(offset)
-			if err != nil {
+	for i, s := range m.readers {
+		if rw, ok := s.(api.Rewindable); ok {
+			if offset, err := ctx.GetState(offsetKey(i)); err != nil {
 				return err
+			} else if offset != nil {
+				ctx.GetLogger().Infof("Source rewind from %v", offset)
+				err = rw.Rewind(offset)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -242,21 +373,80 @@ func (m *SourceNode) Rewind(ctx api.StreamContext) error {
 }
 
 func (m *SourceNode) updateState(ctx api.StreamContext) error {
-	s := m.s
-	if rw, ok := s.(api.Rewindable); ok {
-		state, err := rw.GetOffset()
-		if err != nil {
-			return err
+	for i, s := range m.readers {
+		if rw, ok := s.(api.Rewindable); ok {
+			state, err := rw.GetOffset()
+			if err != nil {
+				return err
+			}
+			if err := ctx.PutState(offsetKey(i), state); err != nil {
+				return err
+			}
 		}
-		return ctx.PutState(OffsetKey, state)
 	}
 	return nil
 }
 
+// offsetKey is the state key of the offset of the reader. The first reader uses the same key as the source without
+// concurrency so that the state is kept after changing the concurrency.
+func offsetKey(reader int) string {
+	if reader == 0 {
+		return OffsetKey
+	}
+	return fmt.Sprintf("%s_%d", OffsetKey, reader)
+}
+
+// SnapshotCheckpoint records the offset of the checkpoint to commit it when the checkpoint completes
+func (m *SourceNode) SnapshotCheckpoint(checkpointId int64) {
+	if len(m.committers) == 0 {
+		return
+	}
+	offsets := make([]any, len(m.committers))
+	for i, c := range m.committers {
+		offset, err := c.GetOffset()
+		if err != nil {
+			m.ctx.GetLogger().Warnf("fail to get the offset of checkpoint %d: %v", checkpointId, err)
+			return
+		}
+		offsets[i] = offset
+	}
+	m.pendingMu.Lock()
+	m.pendingOffsets[checkpointId] = offsets
+	m.pendingMu.Unlock()
+}
+
+// NotifyCheckpointComplete commits the offset of the completed checkpoint. The earlier pending checkpoints are
+// discarded because the offset of the completed one covers them.
+func (m *SourceNode) NotifyCheckpointComplete(checkpointId int64) {
+	if len(m.committers) == 0 {
+		return
+	}
+	m.pendingMu.Lock()
+	offsets, ok := m.pendingOffsets[checkpointId]
+	for id := range m.pendingOffsets {
+		if id <= checkpointId {
+			delete(m.pendingOffsets, id)
+		}
+	}
+	m.pendingMu.Unlock()
+	if !ok {
+		return
+	}
+	for i, c := range m.committers {
+		if err := c.CommitOffset(m.ctx, offsets[i]); err != nil {
+			m.ctx.GetLogger().Warnf("fail to commit the offset %v of checkpoint %d: %v", offsets[i], checkpointId, err)
+		} else {
+			m.ctx.GetLogger().Debugf("commit the offset %v of checkpoint %d", offsets[i], checkpointId)
+		}
+	}
+}
+
 // Run Subscribe could be a long-running function
 func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 	defer func() {
-		m.s.Close(ctx)
+		for _, s := range m.readers {
+			_ = s.Close(ctx)
+		}
 		m.Close()
 		if m.notifySub {
 			sig.Ctrl.Rem(m.name)
@@ -264,22 +454,26 @@ func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 	}()
 	poe := infra.SafeRun(func() error {
 		// Blocking and wait for connection. The connect will call the dial and retry if fails
-		err := m.s.Connect(ctx, m.connectionStatusChange)
-		if err != nil {
-			return err
+		for _, s := range m.readers {
+			err := s.Connect(ctx, m.connectionStatusChange)
+			if err != nil {
+				return err
+			}
 		}
 		if err := m.Rewind(ctx); err != nil {
 			return err
 		}
-		switch ss := m.s.(type) {
-		case api.BytesSource:
-			err = ss.Subscribe(ctx, m.ingestBytes, m.ingestError)
-		case api.TupleSource:
-			err = ss.Subscribe(ctx, m.ingestAnyTuple, m.ingestError)
-		case api.PullBytesSource, api.PullTupleSource:
-			err = m.runPull(ctx)
+		// Subscribe may block until the rule stops, so the parallel readers subscribe in their own goroutines
+		for _, s := range m.readers[1:] {
+			go func(s api.Source) {
+				if err := infra.SafeRun(func() error {
+					return m.subscribe(ctx, s)
+				}); err != nil {
+					infra.DrainError(ctx, err, ctrlCh)
+				}
+			}(s)
 		}
-		if err != nil {
+		if err := m.subscribe(ctx, m.s); err != nil {
 			return err
 		}
 		if m.notifySub {
@@ -293,8 +487,20 @@ func (m *SourceNode) Run(ctx api.StreamContext, ctrlCh chan<- error) {
 	<-ctx.Done()
 }
 
-func (m *SourceNode) runPull(ctx api.StreamContext) error {
-	err := m.doPull(ctx, timex.GetNow())
+func (m *SourceNode) subscribe(ctx api.StreamContext, s api.Source) error {
+	switch ss := s.(type) {
+	case api.BytesSource:
+		return ss.Subscribe(ctx, m.ingestBytes, m.ingestError)
+	case api.TupleSource:
+		return ss.Subscribe(ctx, m.ingestAnyTuple, m.ingestError)
+	case api.PullBytesSource, api.PullTupleSource:
+		return m.runPull(ctx, s)
+	}
+	return nil
+}
+
+func (m *SourceNode) runPull(ctx api.StreamContext, s api.Source) error {
+	err := m.doPull(ctx, s, timex.GetNow())
 	if err != nil {
 		return err
 	}
@@ -306,7 +512,7 @@ func (m *SourceNode) runPull(ctx api.StreamContext) error {
 				select {
 				case tc := <-ticker.C:
 					ctx.GetLogger().Debugf("source pull at %v", tc.UnixMilli())
-					e := m.doPull(ctx, tc)
+					e := m.doPull(ctx, s, tc)
 					if e != nil {
 						m.ingestError(ctx, e)
 					}
@@ -319,9 +525,9 @@ func (m *SourceNode) runPull(ctx api.StreamContext) error {
 	return nil
 }
 
-func (m *SourceNode) doPull(ctx api.StreamContext, tc time.Time) error {
+func (m *SourceNode) doPull(ctx api.StreamContext, s api.Source, tc time.Time) error {
 	return infra.SafeRun(func() error {
-		switch ss := m.s.(type) {
+		switch ss := s.(type) {
 		case api.PullBytesSource:
 			ss.Pull(ctx, tc, m.ingestBytes, m.ingestError)
 		case api.PullTupleSource:
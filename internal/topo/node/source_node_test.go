@@ -260,6 +260,38 @@ func TestPull(t *testing.T) {
 	assert.Equal(t, expects, actual)
 }
 
+func TestSourceDrain(t *testing.T) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "src1").WithCancel()
+	scn, err := NewSourceNode(ctx, "mock_connector", &MockSourceConnector{}, map[string]any{"datasource": "demo"}, &def.RuleOption{
+		BufferLength: 1024,
+	})
+	require.NoError(t, err)
+	result := make(chan any, 10)
+	require.NoError(t, scn.AddOutput(result, "testResult"))
+	scn.prepareExec(ctx, make(chan error, 2), "source")
+	scn.ingestBytes(ctx, []byte("hello"), nil, timex.GetNow())
+	scn.Drain(ctx)
+	// drain only once
+	scn.Drain(ctx)
+	// the EOF of bounded source is ignored after draining
+	scn.ingestEof(ctx)
+	done := make(chan struct{})
+	go func() {
+		scn.ingestBytes(ctx, []byte("world"), nil, timex.GetNow())
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("ingest should be blocked after draining")
+	case <-time.After(50 * time.Millisecond):
+	}
+	cancel()
+	<-done
+	require.Len(t, result, 2)
+	assert.Equal(t, []byte("hello"), (<-result).(*xsql.RawTuple).Rawdata)
+	assert.Equal(t, xsql.EOFDrain, <-result)
+}
+
 type MockSourceConnector struct {
 	data       [][]byte
 	topic      string
@@ -437,3 +469,167 @@ func TestMockRewind(t *testing.T) {
 	v, _ := ctx.GetState(OffsetKey)
 	require.Equal(t, 11, v)
 }
+
+type MockCommitSource struct {
+	MockRewindSource
+	enabled   bool
+	committed []any
+}
+
+func (m *MockCommitSource) EnableCheckpointCommit() {
+	m.enabled = true
+}
+
+func (m *MockCommitSource) CommitOffset(_ api.StreamContext, offset any) error {
+	m.committed = append(m.committed, offset)
+	return nil
+}
+
+func TestCheckpointCommit(t *testing.T) {
+	src := &MockCommitSource{MockRewindSource: MockRewindSource{notify: make(chan struct{})}}
+	ctx := mockContext.NewMockContext("rule1", "src1")
+	errCh := make(chan error)
+	scn, err := NewSourceNode(ctx, "mock_connector", src, map[string]any{"datasource": "demo"}, &def.RuleOption{
+		BufferLength: 1024,
+		SendError:    true,
+		Qos:          def.AtLeastOnce,
+	})
+	require.NoError(t, err)
+	require.True(t, src.enabled)
+	scn.Open(ctx, errCh)
+	src.state = 3
+	scn.SnapshotCheckpoint(1)
+	src.state = 5
+	scn.SnapshotCheckpoint(2)
+	src.state = 8
+	scn.SnapshotCheckpoint(3)
+	scn.NotifyCheckpointComplete(2)
+	require.Equal(t, []any{5}, src.committed)
+	// The earlier checkpoint is covered by the completed one
+	scn.NotifyCheckpointComplete(1)
+	require.Equal(t, []any{5}, src.committed)
+	// Crash before checkpoint 3 completes, the offset 8 is never committed so that the data after 5 is replayed
+	require.Equal(t, map[int64][]any{3: {8}}, scn.pendingOffsets)
+
+	// Without checkpoint, the source commits by itself
+	src = &MockCommitSource{MockRewindSource: MockRewindSource{notify: make(chan struct{})}}
+	scn, err = NewSourceNode(ctx, "mock_connector", src, map[string]any{"datasource": "demo"}, &def.RuleOption{
+		BufferLength: 1024,
+		SendError:    true,
+	})
+	require.NoError(t, err)
+	require.False(t, src.enabled)
+	scn.Open(ctx, errCh)
+	scn.SnapshotCheckpoint(1)
+	scn.NotifyCheckpointComplete(1)
+	require.Empty(t, src.committed)
+}
+
+type MockPartitionSource struct {
+	start int
+	count int
+	// blocking makes Subscribe block until the rule stops like the kafka source
+	blocking bool
+	eof      api.EOFIngest
+}
+
+func (m *MockPartitionSource) PartitionProps(props map[string]any, index int, _ int) (map[string]any, error) {
+	result := make(map[string]any, len(props)+1)
+	for k, v := range props {
+		result[k] = v
+	}
+	result["start"] = index * props["count"].(int)
+	return result, nil
+}
+
+func (m *MockPartitionSource) Provision(_ api.StreamContext, configs map[string]any) error {
+	m.start = configs["start"].(int)
+	m.count = configs["count"].(int)
+	m.blocking, _ = configs["blocking"].(bool)
+	return nil
+}
+
+func (m *MockPartitionSource) Close(_ api.StreamContext) error {
+	return nil
+}
+
+func (m *MockPartitionSource) Connect(_ api.StreamContext, _ api.StatusChangeHandler) error {
+	return nil
+}
+
+func (m *MockPartitionSource) Subscribe(ctx api.StreamContext, ingest api.TupleIngest, _ api.ErrorIngest) error {
+	produce := func() {
+		for i := m.start; i < m.start+m.count; i++ {
+			ingest(ctx, map[string]any{"key": i}, nil, time.Now())
+		}
+		m.eof(ctx)
+	}
+	if m.blocking {
+		produce()
+		<-ctx.Done()
+		return nil
+	}
+	go produce()
+	return nil
+}
+
+func (m *MockPartitionSource) SetEofIngest(eof api.EOFIngest) {
+	m.eof = eof
+}
+
+func TestPartitionedSourceNode(t *testing.T) {
+	for _, blocking := range []bool{false, true} {
+		t.Run(fmt.Sprintf("blocking %v", blocking), func(t *testing.T) {
+			testPartitionedSourceNode(t, blocking)
+		})
+	}
+	ctx := mockContext.NewMockContext("rule1", "src1")
+	_, err := NewPartitionedSourceNode(ctx, "mock_connector", &MockSourceConnector{}, func() (api.Source, error) {
+		return &MockSourceConnector{}, nil
+	}, map[string]any{}, &def.RuleOption{}, 2)
+	require.EqualError(t, err, "source mock_connector does not support concurrency")
+}
+
+// testPartitionedSourceNode checks that every reader ingests, even if the Subscribe of the readers blocks
+func testPartitionedSourceNode(t *testing.T, blocking bool) {
+	ctx, cancel := mockContext.NewMockContext("rule1", "src1").WithCancel()
+	defer cancel()
+	errCh := make(chan error)
+	scn, err := NewPartitionedSourceNode(ctx, "mock_connector", &MockPartitionSource{}, func() (api.Source, error) {
+		return &MockPartitionSource{}, nil
+	}, map[string]any{"count": 100, "blocking": blocking}, &def.RuleOption{
+		BufferLength: 1024,
+		SendError:    true,
+	}, 4)
+	require.NoError(t, err)
+	require.Len(t, scn.readers, 4)
+	result := make(chan any, 1024)
+	require.NoError(t, scn.AddOutput(result, "testResult"))
+	scn.Open(ctx, errCh)
+	received := make(map[int]int)
+	timeout := time.After(10 * time.Second)
+	for done := false; !done; {
+		select {
+		case d := <-result:
+			switch dt := d.(type) {
+			case *xsql.Tuple:
+				assert.Equal(t, "mock_connector", dt.Emitter)
+				received[dt.Message["key"].(int)]++
+			case xsql.EOFTuple:
+				done = true
+			}
+		case <-timeout:
+			require.Fail(t, "timeout")
+		}
+	}
+	// EOF is sent out after all the readers end, no tuple is lost or duplicated
+	require.Len(t, received, 400)
+	for i := 0; i < 400; i++ {
+		require.Equal(t, 1, received[i], "key %d", i)
+	}
+	select {
+	case d := <-result:
+		require.Fail(t, "receive data after EOF", "%v", d)
+	default:
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"golang.org/x/time/rate"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/pkg/infra"
+)
+
+const (
+	ThrottleRateLimit  = "rate_limit"
+	ThrottleActualRate = "actual_rate"
+	// the period to calculate the actual rate
+	throttleRatePeriod = time.Second
+)
+
+// ThrottleOp paces the events by token bucket. Different from RateLimitOp, it does not drop the events but
+// waits for the token so that the events are emitted at most at the configured rate.
+// The waiting only blocks this node, the upstream nodes are back pressured by the buffer.
+// Input: any
+// Output: any as it is
+// Concurrency: false
+type ThrottleOp struct {
+	*defaultSinkNode
+	limit   float64
+	burst   int
+	limiter *rate.Limiter
+	// state for the actual rate
+	rateLock    sync.Mutex
+	periodStart time.Time
+	periodCount int64
+	actualRate  float64
+}
+
+// NewThrottleOp creates the throttle op. The limit is the events per second and the burst is the bucket size.
+// If the burst is not set, it defaults to the limit so that at most one second of events can be sent in a burst.
+func NewThrottleOp(name string, rOpt *def.RuleOption, limit float64, burst int) (*ThrottleOp, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("rateLimit must be greater than 0")
+	}
+	if burst < 0 {
+		return nil, fmt.Errorf("rateBurst must be greater than or equal to 0")
+	}
+	if burst == 0 {
+		burst = int(math.Ceil(limit))
+	}
+	return &ThrottleOp{
+		defaultSinkNode: newDefaultSinkNode(name, rOpt),
+		limit:           limit,
+		burst:           burst,
+		limiter:         rate.NewLimiter(rate.Limit(limit), burst),
+	}, nil
+}
+
+func (o *ThrottleOp) Exec(ctx api.StreamContext, errCh chan<- error) {
+	o.prepareExec(ctx, errCh, "op")
+	o.rateLock.Lock()
+	o.periodStart = time.Now()
+	o.rateLock.Unlock()
+	go func() {
+		defer func() {
+			o.Close()
+		}()
+		err := infra.SafeRun(func() error {
+			runWithOrder(ctx, o.defaultSinkNode, o.concurrency, o.Worker)
+			return nil
+		})
+		if err != nil {
+			infra.DrainError(ctx, err, errCh)
+		}
+	}()
+}
+
+func (o *ThrottleOp) Worker(ctx api.StreamContext, item any) []any {
+	// Wait returns error when the rule is stopped
+	if err := o.limiter.Wait(ctx); err != nil {
+		ctx.GetLogger().Debugf("throttle stop waiting: %v", err)
+		return nil
+	}
+	o.rateLock.Lock()
+	o.periodCount++
+	now := time.Now()
+	if elapsed := now.Sub(o.periodStart); elapsed >= throttleRatePeriod {
+		o.actualRate = float64(o.periodCount) / elapsed.Seconds()
+		o.periodStart = now
+		o.periodCount = 0
+	}
+	o.rateLock.Unlock()
+	return []any{item}
+}
+
+// GetExtraMetrics returns the configured rate and the actual rate in the last period
+func (o *ThrottleOp) GetExtraMetrics() ([]string, []any) {
+	o.rateLock.Lock()
+	defer o.rateLock.Unlock()
+	actual := o.actualRate
+	// No event in the last periods, the rate is decreasing
+	if elapsed := time.Since(o.periodStart); elapsed >= 2*throttleRatePeriod {
+		actual = float64(o.periodCount) / elapsed.Seconds()
+	}
+	return []string{ThrottleRateLimit, ThrottleActualRate}, []any{o.limit, math.Round(actual*100) / 100}
+}
+
+var _ ExtraMetricNode = &ThrottleOp{}
@@ -0,0 +1,90 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestNewThrottleOp(t *testing.T) {
+	_, err := NewThrottleOp("test", &def.RuleOption{}, -1, 0)
+	assert.EqualError(t, err, "rateLimit must be greater than 0")
+	_, err = NewThrottleOp("test", &def.RuleOption{}, 10, -1)
+	assert.EqualError(t, err, "rateBurst must be greater than or equal to 0")
+	op, err := NewThrottleOp("test", &def.RuleOption{}, 2.5, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, op.burst)
+}
+
+func TestThrottleOp_Exec(t *testing.T) {
+	op, err := NewThrottleOp("test", &def.RuleOption{BufferLength: 10, SendError: true}, 20, 2)
+	require.NoError(t, err)
+	out := make(chan any, 100)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("test1", "throttle_test").WithCancel()
+	defer cancel()
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": i}}
+	}
+	// error is not throttled
+	op.input <- errors.New("go through error")
+	for i := 0; i < 6; i++ {
+		r := <-out
+		assert.Equal(t, &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": i}}, r)
+	}
+	// 2 events are sent by burst, the other 4 events are paced at 20 per second
+	assert.True(t, time.Since(start) >= 190*time.Millisecond, "throttle should take at least 200ms")
+	r := <-out
+	assert.EqualError(t, r.(error), "go through error")
+
+	keys, values := op.GetExtraMetrics()
+	assert.Equal(t, []string{ThrottleRateLimit, ThrottleActualRate}, keys)
+	assert.Equal(t, float64(20), values[0])
+}
+
+func TestThrottleOp_Cancel(t *testing.T) {
+	op, err := NewThrottleOp("test", &def.RuleOption{BufferLength: 10, SendError: true}, 0.1, 1)
+	require.NoError(t, err)
+	out := make(chan any, 100)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("test1", "throttle_test").WithCancel()
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 1}}
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 2}}
+	r := <-out
+	assert.Equal(t, &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 1}}, r)
+	// The second event waits for 10s, cancel should stop waiting immediately
+	cancel()
+	select {
+	case r = <-out:
+		t.Errorf("should not receive %v after cancel", r)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
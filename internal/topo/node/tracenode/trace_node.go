@@ -23,6 +23,7 @@ import (
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
@@ -33,20 +34,28 @@ import (
 )
 
 const (
-	DataKey = "data"
-	RuleKey = "rule"
+	DataKey           = "data"
+	RuleKey           = "rule"
+	OpTypeKey         = "operator.type"
+	BatchSizeKey      = "batch.size"
+	ExternalSystemKey = "external.system"
 )
 
 func RecordRowOrCollection(input interface{}, span trace.Span) {
+	// The span sampled out is never exported, skip the serialization of the data
+	if !span.IsRecording() {
+		return
+	}
 	switch d := input.(type) {
 	case xsql.Row:
-		span.SetAttributes(attribute.String(DataKey, ToStringRow(d)))
+		span.SetAttributes(attribute.Int(BatchSizeKey, 1), attribute.String(DataKey, ToStringRow(d)))
 	case api.MessageTupleList:
+		span.SetAttributes(attribute.Int(BatchSizeKey, d.Len()))
 		if d.Len() > 0 {
 			span.SetAttributes(attribute.String(DataKey, ToStringCollection(d)))
 		}
 	case *xsql.RawTuple:
-		span.SetAttributes(attribute.String(DataKey, base64.StdEncoding.EncodeToString(d.Raw())))
+		span.SetAttributes(attribute.Int(BatchSizeKey, 1), attribute.String(DataKey, base64.StdEncoding.EncodeToString(d.Raw())))
 	default:
 		conf.Log.Errorf("RecordRowOrCollection got unexpected input type: %T", d)
 	}
@@ -99,6 +108,40 @@ func StartTraceByID(ctx api.StreamContext, parentId string, opts ...trace.SpanSt
 	return true, ingestCtx, span
 }
 
+// TraceExternalCall starts a client span for the call to the external system such as redis or http. The span is the
+// child of the traced input, and must be ended by EndExternalCall.
+func TraceExternalCall(ctx api.StreamContext, d any, system string) (bool, trace.Span) {
+	traced, _, span := TraceInput(ctx, d, fmt.Sprintf("%s_%s", ctx.GetOpId(), system), externalCallOpts(system)...)
+	return traced, span
+}
+
+// TraceExternalCallFrom starts a client span for the call to the external system as the child of the parent span
+// context. It is used when the call is not bound to an input, such as the lookup of the table.
+func TraceExternalCallFrom(ctx api.StreamContext, parent api.StreamContext, system string) (bool, trace.Span) {
+	if !ctx.IsTraceEnabled() || parent == nil || !hasTraceContext(parent) {
+		return false, nil
+	}
+	_, span := tracer.GetTracer().Start(parent, fmt.Sprintf("%s_%s", ctx.GetOpId(), system), externalCallOpts(system)...)
+	span.SetAttributes(attribute.String(RuleKey, ctx.GetRuleId()))
+	return true, span
+}
+
+// EndExternalCall records the error of the external call if any and ends the span
+func EndExternalCall(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func externalCallOpts(system string) []trace.SpanStartOption {
+	return []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String(ExternalSystemKey, system)),
+	}
+}
+
 func ToStringRow(r xsql.Row) string {
 	d := r.Clone().ToMap()
 	b, _ := json.Marshal(d)
@@ -40,6 +40,10 @@ type TransformOp struct {
 	fields      []string
 	sendSingle  bool
 	omitIfEmpty bool
+	// processedAt is the field name to stamp the processing time
+	processedAt string
+	reshape     *transform.Reshape
+	naming      *transform.FieldNaming
 	// If the result format is text, the dataTemplate should be used to format the data and skip the encode step. Otherwise, the text must be unmarshall back to map
 	isTextFormat bool
 	dt           *template.Template
@@ -57,9 +61,15 @@ func NewTransformOp(name string, rOpt *def.RuleOption, sc *SinkConf, templates [
 		fields:          sc.Fields,
 		sendSingle:      sc.SendSingle,
 		omitIfEmpty:     sc.Omitempty,
+		processedAt:     sc.ProcessedAtField,
+		reshape:         sc.Reshape,
 		isTextFormat:    xsql.IsTextFormat(sc.Format),
 		templates:       map[string]*template.Template{},
 	}
+	naming := &transform.FieldNaming{Mapping: sc.FieldMapping, Case: sc.NameCase}
+	if !naming.IsEmpty() {
+		o.naming = naming
+	}
 	if sc.DataTemplate != "" {
 		temp, err := transform.GenTp(sc.DataTemplate)
 		if err != nil {
@@ -104,6 +114,23 @@ func (t *TransformOp) Worker(ctx api.StreamContext, item any) []any {
 		ctx.GetLogger().Debugf("receive empty result %v in sink, dropped", outs)
 		return nil
 	}
+	if t.reshape != nil {
+		for i, out := range outs {
+			outs[i] = t.reshape.Apply(out)
+		}
+	}
+	if t.naming != nil {
+		for i, out := range outs {
+			renamed, err := t.naming.Apply(out)
+			if err != nil {
+				return []any{err}
+			}
+			outs[i] = renamed
+		}
+	}
+	if t.processedAt != "" {
+		outs = stampProcessedAt(outs, t.processedAt, timex.GetNowInMilli())
+	}
 	// MessageTuple or SinkTupleList
 	var spanCtx api.StreamContext
 	if input, ok := item.(xsql.HasTracerCtx); ok {
@@ -222,6 +249,21 @@ func (t *TransformOp) calculateProps(data any) (map[string]string, error) {
 	return result, nil
 }
 
+// stampProcessedAt sets the processed time to the copies of the results, because the result maps may be shared by
+// other sinks
+func stampProcessedAt(outs []map[string]any, field string, ts int64) []map[string]any {
+	result := make([]map[string]any, len(outs))
+	for i, out := range outs {
+		m := make(map[string]any, len(out)+1)
+		for k, v := range out {
+			m[k] = v
+		}
+		m[field] = ts
+		result[i] = m
+	}
+	return result
+}
+
 func itemToMap(item interface{}) []map[string]any {
 	var outs []map[string]any
 	switch val := item.(type) {
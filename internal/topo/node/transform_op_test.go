@@ -22,7 +22,9 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/transform"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
@@ -235,6 +237,65 @@ func TestTransformRun(t *testing.T) {
 				&xsql.RawTuple{Rawdata: []byte(`{"ab":3,"bb":4}`), Timestamp: timex.GetNow(), Props: map[string]string{"{{.a}}": "3"}},
 			},
 		},
+		{
+			name: "processed at",
+			sc: &SinkConf{
+				Format:           "json",
+				SendSingle:       true,
+				ProcessedAtField: "processedAt",
+			},
+			cases: []any{commonCases[0], commonCases[3]},
+			expects: []any{
+				&xsql.Tuple{Message: map[string]any{"a": 1, "b": 2, "processedAt": int64(0)}, Timestamp: time.UnixMilli(0)},
+				&xsql.Tuple{Message: map[string]any{"a": 1, "b": 2, "processedAt": int64(0)}, Timestamp: time.UnixMilli(0)},
+			},
+		},
+		{
+			name: "reshape",
+			sc: &SinkConf{
+				Format:     "json",
+				SendSingle: true,
+				Reshape: &transform.Reshape{
+					Flatten:          []string{"data"},
+					FlattenSeparator: "_",
+					Rename:           map[string]string{"data_a": "a"},
+					Drop:             []string{"data_sourceConf"},
+					Set:              map[string]any{"kind": "device"},
+				},
+			},
+			cases: []any{commonCases[2], commonCases[0]},
+			expects: []any{
+				&xsql.Tuple{Message: map[string]any{"a": 5, "data_b": 6, "kind": "device"}, Timestamp: time.UnixMilli(0)},
+				&xsql.Tuple{Message: map[string]any{"a": 1, "b": 2, "kind": "device"}, Timestamp: time.UnixMilli(0)},
+			},
+		},
+		{
+			name: "field naming",
+			sc: &SinkConf{
+				Format:           "json",
+				SendSingle:       true,
+				ProcessedAtField: "processedAt",
+				FieldMapping:     map[string]string{"a": "valueA"},
+				NameCase:         transform.NameCaseUpper,
+			},
+			cases: []any{commonCases[2], commonCases[0]},
+			expects: []any{
+				&xsql.Tuple{Message: map[string]any{"DATA": map[string]any{"A": 5, "B": 6, "SOURCECONF": "world"}, "processedAt": int64(0)}, Timestamp: time.UnixMilli(0)},
+				&xsql.Tuple{Message: map[string]any{"valueA": 1, "B": 2, "processedAt": int64(0)}, Timestamp: time.UnixMilli(0)},
+			},
+		},
+		{
+			name: "field naming collision",
+			sc: &SinkConf{
+				Format:     "json",
+				SendSingle: true,
+				NameCase:   transform.NameCaseLower,
+			},
+			cases: []any{&xsql.Tuple{Emitter: "test", Message: map[string]any{"ID": 1, "id": 2}, Timestamp: time.UnixMilli(0)}},
+			expects: []any{
+				errors.New("fields ID and id are both renamed to id"),
+			},
+		},
 	}
 	for _, tt := range testcases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -258,3 +319,38 @@ func TestTransformRun(t *testing.T) {
 		})
 	}
 }
+
+func TestReshapeConf(t *testing.T) {
+	conf.InitConf()
+	logger := mockContext.NewMockContext("reshape", "sink").GetLogger()
+	sc, err := ParseConf(logger, map[string]any{
+		"reshape": map[string]any{
+			"flatten": []any{"data"},
+			"drop":    []any{"b"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &transform.Reshape{Flatten: []string{"data"}, FlattenSeparator: "_", Drop: []string{"b"}}, sc.Reshape)
+	_, err = ParseConf(logger, map[string]any{
+		"reshape": map[string]any{
+			"rename": map[string]any{"a": "b"},
+			"drop":   []any{"b"},
+		},
+	})
+	assert.EqualError(t, err, "invalid reshape: field a is renamed to b which is dropped")
+}
+
+func TestFieldNamingConf(t *testing.T) {
+	conf.InitConf()
+	logger := mockContext.NewMockContext("naming", "sink").GetLogger()
+	sc, err := ParseConf(logger, map[string]any{
+		"fieldMapping": map[string]any{"a": "b"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b"}, sc.FieldMapping)
+	assert.Equal(t, transform.NameCaseNone, sc.NameCase)
+	_, err = ParseConf(logger, map[string]any{
+		"nameCase": "pascal",
+	})
+	assert.EqualError(t, err, "invalid field naming: invalid nameCase pascal, must be one of none, snake, camel, upper and lower")
+}
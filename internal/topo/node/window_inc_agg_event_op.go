@@ -126,7 +126,7 @@ func (ho *HoppingWindowIncAggEventOp) calIncAggWindowInEvent(ctx api.StreamConte
 }
 
 func (ho *HoppingWindowIncAggEventOp) triggerWindow(ctx api.StreamContext, now time.Time) {
-	next := getAlignedWindowEndTime(now, ho.op.windowConfig.RawInterval, ho.op.windowConfig.TimeUnit)
+	next := getWindowEndTime(now, ho.op.windowConfig)
 	if ho.NextTriggerWindowTime.Before(now) {
 		ho.NextTriggerWindowTime = next
 		ho.CurrWindowList = append(ho.CurrWindowList, newIncAggWindow(ctx, next.Add(-ho.op.Interval)))
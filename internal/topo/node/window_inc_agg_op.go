@@ -60,6 +60,9 @@ func NewWindowIncAggOp(name string, w *WindowConfig, dimensions ast.Dimensions,
 	o := new(WindowIncAggOperator)
 	o.defaultSinkNode = newDefaultSinkNode(name, options)
 	o.windowConfig = w
+	if err := setWindowAlign(w, options); err != nil {
+		return nil, err
+	}
 	o.Dimensions = dimensions
 	o.aggFields = aggFields
 	switch w.Type {
@@ -365,10 +368,11 @@ func (to *TumblingWindowIncAggOp) exec(ctx api.StreamContext, errCh chan<- error
 		}
 	}()
 	now := timex.GetNow()
+	var firstTime time.Time
 	if !EnableAlignWindow {
 		to.ticker = timex.GetTicker(to.Interval)
 	} else {
-		_, to.FirstTimer = getFirstTimer(ctx, to.windowConfig.RawInterval, to.windowConfig.TimeUnit)
+		firstTime, to.FirstTimer = getFirstTimer(ctx, to.windowConfig)
 		if to.CurrWindow == nil {
 			to.CurrWindow = newIncAggWindow(ctx, now)
 		}
@@ -403,7 +407,12 @@ func (to *TumblingWindowIncAggOp) exec(ctx api.StreamContext, errCh chan<- error
 				to.FirstTimer.Stop()
 				to.FirstTimer = nil
 				if to.CurrWindow != nil {
-					to.emit(ctx, errCh, now)
+					if to.windowConfig.SkipPartial && firstTime.Sub(to.CurrWindow.StartTime) < to.windowConfig.Length {
+						ctx.GetLogger().Infof("skip the partial window starts at %d", to.CurrWindow.StartTime.UnixMilli())
+						to.CurrWindow = nil
+					} else {
+						to.emit(ctx, errCh, now)
+					}
 				}
 				to.ticker = timex.GetTicker(to.Interval)
 				to.PutState(ctx)
@@ -675,7 +684,7 @@ func (ho *HoppingWindowIncAggOp) exec(ctx api.StreamContext, errCh chan<- error)
 		ho.ticker = timex.GetTicker(ho.Interval)
 		ho.newIncWindow(ctx, now)
 	} else {
-		_, ho.FirstTimer = getFirstTimer(ctx, ho.windowConfig.RawInterval, ho.windowConfig.TimeUnit)
+		_, ho.FirstTimer = getFirstTimer(ctx, ho.windowConfig)
 		ho.CurrWindowList = append(ho.CurrWindowList, newIncAggWindow(ctx, now))
 	}
 	fv, _ := xsql.NewFunctionValuersForOp(ctx)
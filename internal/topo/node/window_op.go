@@ -23,6 +23,7 @@ import (
 
 	"github.com/benbjohnson/clock"
 	"github.com/lf-edge/ekuiper/contract/v2/api"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
@@ -48,6 +49,60 @@ type WindowConfig struct {
 	CountInterval int
 	RawInterval   int
 	TimeUnit      ast.Token
+	// AlignEpoch aligns the time window to the multiples of the window interval since the unix epoch
+	AlignEpoch bool
+	// SkipPartial drops the first processing time tumbling window if it does not cover the whole length
+	SkipPartial bool
+	// MaxRows is the max number of the buffered rows. 0 means unlimited
+	MaxRows int
+	// LimitPolicy decides which row to drop when the buffered rows reach MaxRows
+	LimitPolicy string
+	// Dimensions are the group keys of the session window without max duration, each group has its own sessions
+	Dimensions ast.Dimensions
+}
+
+// isPartitionedSession returns true for the session window without max duration. The Interval is the timeout and the
+// sessions are tracked per group key instead of the whole stream.
+func (w *WindowConfig) isPartitionedSession() bool {
+	return w.Type == ast.SESSION_WINDOW && w.Length == 0
+}
+
+// setWindowAlign sets the window alignment from the rule options
+func setWindowAlign(w *WindowConfig, options *def.RuleOption) error {
+	switch options.WindowAlign {
+	case "", def.WindowAlignNatural:
+	case def.WindowAlignEpoch:
+		w.AlignEpoch = true
+	default:
+		return fmt.Errorf("invalid windowAlign %s, must be %s or %s", options.WindowAlign, def.WindowAlignNatural, def.WindowAlignEpoch)
+	}
+	w.SkipPartial = options.SkipPartialWindow
+	return nil
+}
+
+// setWindowLimit sets the max buffered rows from the rule resource limit
+func setWindowLimit(w *WindowConfig, options *def.RuleOption) error {
+	l := options.ResourceLimit
+	if l == nil {
+		return nil
+	}
+	if l.MaxWindowRows < 0 {
+		return fmt.Errorf("invalid resourceLimit maxWindowRows %d, must not be negative", l.MaxWindowRows)
+	}
+	switch l.Policy {
+	case "", def.LimitPolicyDropNew:
+		w.LimitPolicy = def.LimitPolicyDropNew
+	case def.LimitPolicyDropOldest:
+		w.LimitPolicy = def.LimitPolicyDropOldest
+	default:
+		return fmt.Errorf("invalid resourceLimit policy %s, must be %s or %s", l.Policy, def.LimitPolicyDropNew, def.LimitPolicyDropOldest)
+	}
+	// The count window never triggers if it cannot buffer a whole window
+	if w.Type == ast.COUNT_WINDOW && l.MaxWindowRows > 0 && l.MaxWindowRows < w.CountLength {
+		return fmt.Errorf("resourceLimit maxWindowRows %d is less than the count window length %d", l.MaxWindowRows, w.CountLength)
+	}
+	w.MaxRows = l.MaxWindowRows
+	return nil
 }
 
 type WindowOperator struct {
@@ -91,6 +146,12 @@ func NewWindowOp(name string, w WindowConfig, options *def.RuleOption) (*WindowO
 	o.defaultSinkNode = newDefaultSinkNode(name, options)
 	o.isEventTime = options.IsEventTime
 	o.window = &w
+	if err := setWindowAlign(o.window, options); err != nil {
+		return nil, err
+	}
+	if err := setWindowLimit(o.window, options); err != nil {
+		return nil, err
+	}
 	if o.window.CountInterval == 0 && o.window.Type == ast.COUNT_WINDOW {
 		// if no interval value is set, and it's a count window, then set interval to length value.
 		o.window.CountInterval = o.window.CountLength
@@ -191,26 +252,26 @@ func getAlignedWindowEndTime(n time.Time, interval int, timeUnit ast.Token) time
 		return time.Date(n.Year(), n.Month(), n.Day()+interval, 0, 0, 0, 0, n.Location())
 	case ast.HH:
 		gap := interval
-		if n.Hour() > interval {
+		if n.Hour() >= interval {
 			gap = interval * (n.Hour()/interval + 1)
 		}
 		return time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, n.Location()).Add(time.Duration(gap) * time.Hour)
 	case ast.MI:
 		gap := interval
-		if n.Minute() > interval {
+		if n.Minute() >= interval {
 			gap = interval * (n.Minute()/interval + 1)
 		}
 		return time.Date(n.Year(), n.Month(), n.Day(), n.Hour(), 0, 0, 0, n.Location()).Add(time.Duration(gap) * time.Minute)
 	case ast.SS:
 		gap := interval
-		if n.Second() > interval {
+		if n.Second() >= interval {
 			gap = interval * (n.Second()/interval + 1)
 		}
 		return time.Date(n.Year(), n.Month(), n.Day(), n.Hour(), n.Minute(), 0, 0, n.Location()).Add(time.Duration(gap) * time.Second)
 	case ast.MS:
 		milli := n.Nanosecond() / int(time.Millisecond)
 		gap := interval
-		if milli > interval {
+		if milli >= interval {
 			gap = interval * (milli/interval + 1)
 		}
 		return time.Date(n.Year(), n.Month(), n.Day(), n.Hour(), n.Minute(), n.Second(), 0, n.Location()).Add(time.Duration(gap) * time.Millisecond)
@@ -220,14 +281,48 @@ func getAlignedWindowEndTime(n time.Time, interval int, timeUnit ast.Token) time
 	}
 }
 
-func getFirstTimer(ctx api.StreamContext, rawInerval int, timeUnit ast.Token) (time.Time, *clock.Timer) {
-	next := getAlignedWindowEndTime(timex.GetNow(), rawInerval, timeUnit)
+// getEpochAlignedWindowEndTime returns the next multiple of the interval since the unix epoch. Unlike the natural
+// alignment, the boundaries are continuous across the time unit, e.g. a 7 minutes window does not restart at each hour.
+func getEpochAlignedWindowEndTime(n time.Time, interval int, timeUnit ast.Token) time.Time {
+	var unit time.Duration
+	switch timeUnit {
+	case ast.DD:
+		unit = 24 * time.Hour
+	case ast.HH:
+		unit = time.Hour
+	case ast.MI:
+		unit = time.Minute
+	case ast.SS:
+		unit = time.Second
+	case ast.MS:
+		unit = time.Millisecond
+	default: // should never happen
+		conf.Log.Errorf("invalid time unit %s", timeUnit)
+		return n
+	}
+	d := (time.Duration(interval) * unit).Milliseconds()
+	if d <= 0 {
+		return n
+	}
+	return time.UnixMilli((n.UnixMilli()/d + 1) * d).In(n.Location())
+}
+
+func getWindowEndTime(n time.Time, w *WindowConfig) time.Time {
+	if w.AlignEpoch {
+		return getEpochAlignedWindowEndTime(n, w.RawInterval, w.TimeUnit)
+	}
+	return getAlignedWindowEndTime(n, w.RawInterval, w.TimeUnit)
+}
+
+func getFirstTimer(ctx api.StreamContext, w *WindowConfig) (time.Time, *clock.Timer) {
+	next := getWindowEndTime(timex.GetNow(), w)
 	ctx.GetLogger().Infof("align window timer to %v(%d)", next, next.UnixMilli())
 	return next, timex.GetTimerByTime(next)
 }
 
 func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*xsql.Tuple, errCh chan<- error) {
 	log := ctx.GetLogger()
+	startTime := timex.GetNow()
 	var (
 		timeoutTicker *clock.Timer
 		// The first ticker to align the first window to the nature time
@@ -241,15 +336,18 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 	switch o.window.Type {
 	case ast.NOT_WINDOW:
 	case ast.TUMBLING_WINDOW:
-		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+		firstTime, firstTicker = getFirstTimer(ctx, o.window)
 		o.interval = o.window.Length
 	case ast.HOPPING_WINDOW:
-		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+		firstTime, firstTicker = getFirstTimer(ctx, o.window)
 		o.interval = o.window.Interval
 	case ast.SLIDING_WINDOW:
 		o.interval = o.window.Length
 	case ast.SESSION_WINDOW:
-		firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+		// The partitioned session window is only triggered by the timeout of each group
+		if !o.window.isPartitionedSession() {
+			firstTime, firstTicker = getFirstTimer(ctx, o.window)
+		}
 		o.interval = o.window.Interval
 	case ast.COUNT_WINDOW:
 		o.interval = o.window.Interval
@@ -317,6 +415,15 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 			}
 		}
 	}
+	if o.window.isPartitionedSession() && len(inputs) > 0 {
+		// resume the timeout of the restored sessions
+		var next time.Time
+		inputs, next = o.closeSessions(ctx, inputs, timex.GetNow())
+		if next.Before(timex.Maxtime) {
+			timeoutTicker = timex.GetTimerByTime(next)
+			timeout = timeoutTicker.C
+		}
+	}
 	delayCh := make(chan time.Time, 100)
 	for {
 		select {
@@ -328,6 +435,16 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 			_ = ctx.PutState(MsgCountKey, o.msgCount)
 		// process incoming item
 		case item := <-o.input:
+			item, processed := o.preprocess(ctx, item)
+			if processed {
+				break
+			}
+			// Emit the pending window before passing on the EOF so that the data is not lost when draining
+			if eof, ok := item.(xsql.EOFTuple); ok && eof == xsql.EOFDrain {
+				inputs = o.flush(ctx, inputs)
+				o.Broadcast(eof)
+				break
+			}
 			data, processed := o.commonIngest(ctx, item)
 			if processed {
 				break
@@ -336,6 +453,10 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 			switch d := data.(type) {
 			case *xsql.Tuple:
 				log.Debugf("Event window receive tuple %s", d.Message)
+				var accepted bool
+				if inputs, accepted = o.limitInputs(ctx, inputs); !accepted {
+					break
+				}
 				o.handleTraceIngestTuple(ctx, d)
 				inputs = append(inputs, d)
 				switch o.window.Type {
@@ -362,6 +483,14 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 						inputs = o.gcInputs(inputs, d.Timestamp.Add(1), ctx)
 					}
 				case ast.SESSION_WINDOW:
+					if o.window.isPartitionedSession() {
+						// The timer fires at the earliest possible end, the extended sessions are checked again then
+						if timeoutTicker == nil {
+							timeoutTicker = timex.GetTimerByTime(d.Timestamp.Add(o.window.Interval))
+							timeout = timeoutTicker.C
+						}
+						break
+					}
 					if timeoutTicker != nil {
 						timeoutTicker.Stop()
 						timeoutTicker.Reset(o.window.Interval)
@@ -415,7 +544,11 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 			firstTicker.Stop()
 			o.setupTicker()
 			c = o.ticker.C
-			inputs = o.tick(ctx, inputs, firstTime, log)
+			if o.window.SkipPartial && o.window.Type == ast.TUMBLING_WINDOW && firstTime.Sub(startTime) < o.window.Length {
+				inputs = o.skipPartial(ctx, inputs, firstTime)
+			} else {
+				inputs = o.tick(ctx, inputs, firstTime, log)
+			}
 			nextTime = firstTime
 		case now := <-c:
 			nextTime = nextTime.Add(o.duration)
@@ -426,10 +559,20 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 			} else {
 				log.Infof("Skip the tick at %v(%d) since it's too late", now, now.UnixMilli())
 				o.ticker.Stop()
-				firstTime, firstTicker = getFirstTimer(ctx, o.window.RawInterval, o.window.TimeUnit)
+				firstTime, firstTicker = getFirstTimer(ctx, o.window)
 				firstC = firstTicker.C
 			}
 		case now := <-timeout:
+			if o.window.isPartitionedSession() {
+				var next time.Time
+				inputs, next = o.closeSessions(ctx, inputs, now)
+				timeoutTicker = nil
+				if next.Before(timex.Maxtime) {
+					timeoutTicker = timex.GetTimerByTime(next)
+					timeout = timeoutTicker.C
+				}
+				break
+			}
 			if len(inputs) > 0 {
 				o.statManager.ProcessTimeStart()
 				log.Debugf("triggered by timeout")
@@ -454,6 +597,48 @@ func (o *WindowOperator) execProcessingWindow(ctx api.StreamContext, inputs []*x
 	}
 }
 
+// limitInputs sheds a row by the limit policy if the buffered rows reach the limit. It returns false if the incoming
+// row is dropped. The shedding is reported as an exception of the operator.
+func (o *WindowOperator) limitInputs(ctx api.StreamContext, inputs []*xsql.Tuple) ([]*xsql.Tuple, bool) {
+	if o.window.MaxRows <= 0 || len(inputs) < o.window.MaxRows {
+		return inputs, true
+	}
+	if o.window.LimitPolicy == def.LimitPolicyDropOldest {
+		o.onErrorOpt(ctx, fmt.Errorf("window rows reach the limit %d, drop the oldest row", o.window.MaxRows), false)
+		o.handleTraceDiscardTuple(ctx, inputs[:1])
+		return inputs[1:], true
+	}
+	o.onErrorOpt(ctx, fmt.Errorf("window rows reach the limit %d, drop the new row", o.window.MaxRows), false)
+	return inputs, false
+}
+
+// flush triggers the time window which has pending inputs immediately
+func (o *WindowOperator) flush(ctx api.StreamContext, inputs []*xsql.Tuple) []*xsql.Tuple {
+	if len(inputs) == 0 {
+		return inputs
+	}
+	if o.window.isPartitionedSession() {
+		ctx.GetLogger().Infof("flush sessions with %d inputs", len(inputs))
+		inputs, _ = o.closeSessions(ctx, inputs, timex.Maxtime)
+		return inputs
+	}
+	switch o.window.Type {
+	case ast.TUMBLING_WINDOW, ast.HOPPING_WINDOW, ast.SESSION_WINDOW:
+		ctx.GetLogger().Infof("flush window with %d inputs", len(inputs))
+		// The right boundary is exclusive, make sure all the inputs are included
+		n := timex.GetNow()
+		if last := inputs[len(inputs)-1].Timestamp; !last.Before(n) {
+			n = last.Add(time.Millisecond)
+		}
+		o.statManager.ProcessTimeStart()
+		inputs = o.scan(inputs, n, ctx)
+		o.statManager.ProcessTimeEnd()
+		_ = ctx.PutState(WindowInputsKey, inputs)
+		_ = ctx.PutState(TriggerTimeKey, o.triggerTime)
+	}
+	return inputs
+}
+
 func (o *WindowOperator) setupTicker() {
 	switch o.window.Type {
 	case ast.TUMBLING_WINDOW:
@@ -484,6 +669,23 @@ func (o *WindowOperator) tick(ctx api.StreamContext, inputs []*xsql.Tuple, n tim
 	return inputs
 }
 
+// skipPartial drops the tuples of the first window which opens before the rule starts. The trigger time is updated so
+// that the next window is a complete one.
+func (o *WindowOperator) skipPartial(ctx api.StreamContext, inputs []*xsql.Tuple, n time.Time) []*xsql.Tuple {
+	inputs, discarded, content := o.handleInputs(ctx, inputs, n)
+	ctx.GetLogger().Infof("skip the partial window ends at %d with %d tuples", n.UnixMilli(), len(content))
+	for _, row := range content {
+		if t, ok := row.(*xsql.Tuple); ok {
+			discarded = append(discarded, t)
+		}
+	}
+	o.handleTraceDiscardTuple(ctx, discarded)
+	o.triggerTime = n
+	_ = ctx.PutState(WindowInputsKey, inputs)
+	_ = ctx.PutState(TriggerTimeKey, o.triggerTime)
+	return inputs
+}
+
 type TupleList struct {
 	tuples []*xsql.Tuple
 	index  int // Current index
@@ -751,7 +953,7 @@ func (o *WindowOperator) handleTraceEmitTuple(ctx api.StreamContext, wt *xsql.Wi
 }
 
 func (o *WindowOperator) handleNextWindowTupleSpan(ctx api.StreamContext) {
-	traced, spanCtx, span := tracenode.StartTraceBackground(ctx, "window_op")
+	traced, spanCtx, span := tracenode.StartTraceBackground(ctx, "window_op", trace.WithAttributes(attribute.String(tracenode.OpTypeKey, o.opType)))
 	if traced {
 		o.nextSpanCtx = spanCtx
 		o.nextSpan = span
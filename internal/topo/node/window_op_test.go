@@ -20,11 +20,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 var fivet = []*xsql.Tuple{
@@ -126,6 +130,241 @@ func TestTime(t *testing.T) {
 	}
 }
 
+func TestAlignedTimeOnBoundary(t *testing.T) {
+	n := time.Date(2022, 7, 19, 16, 15, 30, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 7, 19, 16, 30, 0, 0, time.UTC), getAlignedWindowEndTime(n, 15, ast.MI))
+	n = time.Date(2022, 7, 19, 16, 15, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 7, 19, 16, 30, 0, 0, time.UTC), getAlignedWindowEndTime(n, 15, ast.MI))
+	n = time.Date(2022, 7, 19, 2, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 7, 19, 4, 0, 0, 0, time.UTC), getAlignedWindowEndTime(n, 2, ast.HH))
+}
+
+func TestEpochAlignedTime(t *testing.T) {
+	tests := []struct {
+		interval int
+		unit     ast.Token
+		end      int64
+	}{
+		{
+			interval: 500,
+			unit:     ast.MS,
+			end:      1658218371500,
+		}, {
+			interval: 40,
+			unit:     ast.SS,
+			end:      1658218400000,
+		}, {
+			interval: 15,
+			unit:     ast.MI,
+			end:      1658218500000,
+		}, {
+			interval: 7, // not aligned to the hour
+			unit:     ast.MI,
+			end:      1658218380000,
+		}, {
+			interval: 1,
+			unit:     ast.HH,
+			end:      1658221200000,
+		}, {
+			interval: 1, // UTC midnight
+			unit:     ast.DD,
+			end:      1658275200000,
+		},
+	}
+	for _, tt := range tests {
+		ae := getEpochAlignedWindowEndTime(time.UnixMilli(1658218371337), tt.interval, tt.unit)
+		assert.Equal(t, tt.end, ae.UnixMilli(), "interval %d unit %s", tt.interval, tt.unit)
+	}
+	// the boundary is continuous across the restart
+	assert.Equal(t, int64(1658218800000), getEpochAlignedWindowEndTime(time.UnixMilli(1658218380000), 7, ast.MI).UnixMilli())
+}
+
+func TestSetWindowAlign(t *testing.T) {
+	w := &WindowConfig{}
+	require.NoError(t, setWindowAlign(w, &def.RuleOption{}))
+	assert.False(t, w.AlignEpoch)
+	require.NoError(t, setWindowAlign(w, &def.RuleOption{WindowAlign: "epoch", SkipPartialWindow: true}))
+	assert.True(t, w.AlignEpoch)
+	assert.True(t, w.SkipPartial)
+	_, err := NewWindowOp("test", WindowConfig{Type: ast.TUMBLING_WINDOW}, &def.RuleOption{WindowAlign: "rule"})
+	assert.EqualError(t, err, "invalid windowAlign rule, must be natural or epoch")
+}
+
+// advanceToOffset moves the mock clock forward to the next time which is at the offset of the interval. The clock
+// is shared by the tests of the package, so it must not be moved backward or jump far.
+func advanceToOffset(interval, offset int64) int64 {
+	now := timex.GetNowInMilli()
+	timex.Add(time.Duration(((offset-now%interval)%interval+interval)%interval) * time.Millisecond)
+	return timex.GetNowInMilli()
+}
+
+func TestSkipPartialWindow(t *testing.T) {
+	start := advanceToOffset(10000, 1337)
+	op, err := NewWindowOp("test", WindowConfig{
+		Type:        ast.TUMBLING_WINDOW,
+		Length:      10 * time.Second,
+		Interval:    10 * time.Second,
+		RawInterval: 10,
+		TimeUnit:    ast.SS,
+	}, &def.RuleOption{BufferLength: 10, WindowAlign: "epoch", SkipPartialWindow: true})
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("testSkipPartial", "window").WithCancel()
+	defer cancel()
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+	time.Sleep(10 * time.Millisecond)
+	// in the partial window which starts before the rule
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 1}, Timestamp: timex.GetNow()}
+	time.Sleep(10 * time.Millisecond)
+	timex.Add(8663 * time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 2}, Timestamp: timex.GetNow()}
+	time.Sleep(10 * time.Millisecond)
+	timex.Add(10 * time.Second)
+	select {
+	case r := <-out:
+		wt, ok := r.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Len(t, wt.Content, 1)
+		assert.Equal(t, map[string]any{"a": 2}, wt.Content[0].ToMap())
+		assert.Equal(t, xsql.NewWindowRange(start-1337+10000, start-1337+20000), wt.WindowRange)
+	case <-time.After(time.Second):
+		t.Fatal("window is not triggered")
+	}
+}
+
+func TestWindowFlushOnDrain(t *testing.T) {
+	op, err := NewWindowOp("test", WindowConfig{
+		Type:        ast.TUMBLING_WINDOW,
+		Length:      10 * time.Second,
+		Interval:    10 * time.Second,
+		RawInterval: 10,
+		TimeUnit:    ast.SS,
+	}, &def.RuleOption{BufferLength: 10})
+	require.NoError(t, err)
+	out := make(chan any, 10)
+	require.NoError(t, op.AddOutput(out, "test"))
+	ctx, cancel := mockContext.NewMockContext("testFlushOnDrain", "window").WithCancel()
+	defer cancel()
+	errCh := make(chan error)
+	op.Exec(ctx, errCh)
+	time.Sleep(10 * time.Millisecond)
+	op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": 1}, Timestamp: timex.GetNow()}
+	// EOF of the bounded source does not trigger the window
+	op.input <- xsql.EOFBounded
+	select {
+	case r := <-out:
+		assert.Equal(t, xsql.EOFBounded, r)
+	case <-time.After(time.Second):
+		t.Fatal("EOF is not sent out")
+	}
+	op.input <- xsql.EOFDrain
+	select {
+	case r := <-out:
+		wt, ok := r.(*xsql.WindowTuples)
+		require.True(t, ok)
+		require.Len(t, wt.Content, 1)
+		assert.Equal(t, map[string]any{"a": 1}, wt.Content[0].ToMap())
+	case <-time.After(time.Second):
+		t.Fatal("window is not flushed")
+	}
+	select {
+	case r := <-out:
+		assert.Equal(t, xsql.EOFDrain, r)
+	case <-time.After(time.Second):
+		t.Fatal("EOF is not sent out")
+	}
+}
+
+func TestSetWindowLimit(t *testing.T) {
+	w := &WindowConfig{Type: ast.TUMBLING_WINDOW}
+	require.NoError(t, setWindowLimit(w, &def.RuleOption{}))
+	assert.Equal(t, 0, w.MaxRows)
+	require.NoError(t, setWindowLimit(w, &def.RuleOption{ResourceLimit: &def.ResourceLimit{MaxWindowRows: 10}}))
+	assert.Equal(t, 10, w.MaxRows)
+	assert.Equal(t, def.LimitPolicyDropNew, w.LimitPolicy)
+	tests := []struct {
+		name  string
+		w     WindowConfig
+		limit *def.ResourceLimit
+		err   string
+	}{
+		{
+			name:  "negative",
+			w:     WindowConfig{Type: ast.TUMBLING_WINDOW},
+			limit: &def.ResourceLimit{MaxWindowRows: -1},
+			err:   "invalid resourceLimit maxWindowRows -1, must not be negative",
+		},
+		{
+			name:  "invalid policy",
+			w:     WindowConfig{Type: ast.TUMBLING_WINDOW},
+			limit: &def.ResourceLimit{MaxWindowRows: 10, Policy: "block"},
+			err:   "invalid resourceLimit policy block, must be dropNew or dropOldest",
+		},
+		{
+			name:  "less than count",
+			w:     WindowConfig{Type: ast.COUNT_WINDOW, CountLength: 20},
+			limit: &def.ResourceLimit{MaxWindowRows: 10},
+			err:   "resourceLimit maxWindowRows 10 is less than the count window length 20",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWindowOp("test", tt.w, &def.RuleOption{ResourceLimit: tt.limit})
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestWindowRowsLimit(t *testing.T) {
+	tests := []struct {
+		policy string
+		result []map[string]any
+	}{
+		{
+			policy: def.LimitPolicyDropNew,
+			result: []map[string]any{{"a": 1}, {"a": 2}},
+		},
+		{
+			policy: def.LimitPolicyDropOldest,
+			result: []map[string]any{{"a": 2}, {"a": 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			op, err := NewWindowOp("test", WindowConfig{
+				Type:        ast.TUMBLING_WINDOW,
+				Length:      10 * time.Second,
+				Interval:    10 * time.Second,
+				RawInterval: 10,
+				TimeUnit:    ast.SS,
+			}, &def.RuleOption{BufferLength: 10, ResourceLimit: &def.ResourceLimit{MaxWindowRows: 2, Policy: tt.policy}})
+			require.NoError(t, err)
+			out := make(chan any, 10)
+			require.NoError(t, op.AddOutput(out, "test"))
+			ctx, cancel := mockContext.NewMockContext("testRowsLimit", "window").WithCancel()
+			defer cancel()
+			errCh := make(chan error)
+			op.Exec(ctx, errCh)
+			time.Sleep(10 * time.Millisecond)
+			for i := 1; i <= 3; i++ {
+				op.input <- &xsql.Tuple{Emitter: "test", Message: map[string]any{"a": i}, Timestamp: timex.GetNow()}
+			}
+			op.input <- xsql.EOFDrain
+			select {
+			case r := <-out:
+				wt, ok := r.(*xsql.WindowTuples)
+				require.True(t, ok)
+				assert.Equal(t, tt.result, wt.ToMaps())
+			case <-time.After(time.Second):
+				t.Fatal("window is not flushed")
+			}
+		})
+	}
+}
+
 func TestNewTupleList(t *testing.T) {
 	_, e := NewTupleList(nil, 0)
 	es1 := "Window size should not be less than zero."
@@ -25,6 +25,8 @@ import (
 
 type AggregateOp struct {
 	Dimensions ast.Dimensions
+	// KeepEmpty emits an empty set for the window without data so that the gaps can be filled
+	KeepEmpty bool
 }
 
 // Apply
@@ -70,6 +72,8 @@ func (p *AggregateOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fu
 					g = append(g, v)
 				}
 				grouped = &xsql.GroupedTuplesSet{Groups: g}
+			} else if p.KeepEmpty {
+				grouped = &xsql.GroupedTuplesSet{WindowRange: wr}
 			} else {
 				grouped = nil
 			}
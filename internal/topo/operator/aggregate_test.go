@@ -21,6 +21,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
@@ -501,3 +504,61 @@ func TestAggregatePlanError(t *testing.T) {
 		}
 	}
 }
+
+func TestAggregatePlanKeepEmpty(t *testing.T) {
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT abc FROM src1 GROUP BY abc, TUMBLINGWINDOW(ss, 10)")).Parse()
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), context.LoggerKey, conf.Log)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	empty := &xsql.WindowTuples{WindowRange: xsql.NewWindowRange(0, 10)}
+	pp := &AggregateOp{Dimensions: stmt.Dimensions.GetGroups()}
+	assert.Nil(t, pp.Apply(ctx, empty, fv, afv))
+	pp.KeepEmpty = true
+	assert.Equal(t, &xsql.GroupedTuplesSet{WindowRange: xsql.NewWindowRange(0, 10)}, pp.Apply(ctx, empty, fv, afv))
+}
+
+func TestAggregatePlanExpression(t *testing.T) {
+	tests := []struct {
+		sql    string
+		groups [][]int
+	}{
+		{
+			sql:    "SELECT floor(temp / 10) AS bucket, count(*) FROM src1 GROUP BY floor(temp / 10), TUMBLINGWINDOW(ss, 10)",
+			groups: [][]int{{1, 3}, {2}},
+		},
+		{
+			sql:    "SELECT substring(id, 0, 3), avg(temp) FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10), substring(id, 0, 3)",
+			groups: [][]int{{1, 2}, {3}},
+		},
+	}
+	ctx := context.WithValue(context.Background(), context.LoggerKey, conf.Log)
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			data := &xsql.WindowTuples{
+				Content: []xsql.Row{
+					&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"seq": 1, "id": "dev01", "temp": 12}},
+					&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"seq": 2, "id": "dev02", "temp": 25}},
+					&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"seq": 3, "id": "vlv01", "temp": 18}},
+				},
+				WindowRange: xsql.NewWindowRange(0, 10),
+			}
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			pp := &AggregateOp{Dimensions: stmt.Dimensions.GetGroups()}
+			result := pp.Apply(ctx, data, fv, afv)
+			gr, ok := result.(*xsql.GroupedTuplesSet)
+			require.True(t, ok, "result is %v", result)
+			groups := make([][]int, 0, len(gr.Groups))
+			for _, g := range gr.Groups {
+				seqs := make([]int, 0, len(g.Content))
+				for _, r := range g.Content {
+					v, _ := r.Value("seq", "")
+					seqs = append(seqs, v.(int))
+				}
+				groups = append(groups, seqs)
+			}
+			assert.ElementsMatch(t, tt.groups, groups)
+		})
+	}
+}
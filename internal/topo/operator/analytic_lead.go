@@ -0,0 +1,234 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+const leadFunc = "lead"
+
+// leadRow is a row held until the values of all its lead functions are resolved
+type leadRow struct {
+	seq     int64
+	row     xsql.Row
+	pending int
+	emitted bool
+}
+
+// leadEntry is a row in the lookahead queue of a lead function with its default value
+type leadEntry struct {
+	row *leadRow
+	dft any
+}
+
+type leadQueue struct {
+	offset  int
+	entries []*leadEntry
+}
+
+// leadBuffer holds the rows for the lead functions. Each lead function keeps a queue for each partition with at most
+// offset entries. When a row arrives, the front row of the full queue is resolved by the value of the new row, which
+// is the offset-th following row of it.
+type leadBuffer struct {
+	calls []*ast.Call
+	// queues of each lead function keyed by the partition key
+	queues []map[string]*leadQueue
+	// held are the rows not sent out yet keyed by the arrival sequence
+	held map[int64]*leadRow
+	seq  int64
+}
+
+func newLeadBuffer(calls []*ast.Call) *leadBuffer {
+	b := &leadBuffer{
+		calls:  calls,
+		queues: make([]map[string]*leadQueue, len(calls)),
+		held:   make(map[int64]*leadRow),
+	}
+	for i := range b.queues {
+		b.queues[i] = make(map[string]*leadQueue)
+	}
+	return b
+}
+
+// add adds the row to the lookahead queues and returns the rows resolved in the arrival order
+func (b *leadBuffer) add(ve *xsql.ValuerEval, row xsql.Row) ([]xsql.Row, error) {
+	args := make([]*leadArgs, len(b.calls))
+	for i, call := range b.calls {
+		a, err := evalLeadArgs(call, ve)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = a
+	}
+	r := &leadRow{seq: b.seq, row: row, pending: len(b.calls)}
+	b.seq++
+	b.held[r.seq] = r
+	var resolved []*leadRow
+	for i, call := range b.calls {
+		a := args[i]
+		// The row which does not meet the WHEN condition gets the default value and does not move the queue
+		if !a.valid {
+			if b.setValue(r, call, a.dft) {
+				resolved = append(resolved, r)
+			}
+			continue
+		}
+		q, ok := b.queues[i][a.key]
+		if !ok {
+			q = &leadQueue{offset: a.offset}
+			b.queues[i][a.key] = q
+		}
+		q.entries = append(q.entries, &leadEntry{row: r, dft: a.dft})
+		if len(q.entries) > q.offset {
+			front := q.entries[0]
+			q.entries = q.entries[1:]
+			if b.setValue(front.row, call, a.value) {
+				resolved = append(resolved, front.row)
+			}
+		}
+		if len(q.entries) == 0 {
+			delete(b.queues[i], a.key)
+		}
+	}
+	return toRows(resolved), nil
+}
+
+// setValue sets the value of a lead function to the row and returns true if all values of the row are resolved
+func (b *leadBuffer) setValue(r *leadRow, call *ast.Call, value any) bool {
+	if r.emitted {
+		return false
+	}
+	r.row.Set(call.CachedField, value)
+	r.pending--
+	if r.pending > 0 {
+		return false
+	}
+	r.emitted = true
+	delete(b.held, r.seq)
+	return true
+}
+
+// flush resolves the held rows by the default values of the unresolved lead functions and returns them in the arrival
+// order. If before is not zero, only the rows not after it are flushed and their values still count as the following
+// rows of the others. Otherwise, all rows are flushed and the queues are cleared.
+func (b *leadBuffer) flush(before time.Time) []xsql.Row {
+	var flushed []*leadRow
+	for _, r := range b.held {
+		if notAfter(r.row, before) {
+			flushed = append(flushed, r)
+		}
+	}
+	if len(flushed) == 0 {
+		return nil
+	}
+	for i, call := range b.calls {
+		for key, q := range b.queues[i] {
+			for _, e := range q.entries {
+				if !e.row.emitted && notAfter(e.row.row, before) {
+					e.row.row.Set(call.CachedField, e.dft)
+				}
+			}
+			if before.IsZero() {
+				delete(b.queues[i], key)
+			}
+		}
+	}
+	for _, r := range flushed {
+		r.emitted = true
+		delete(b.held, r.seq)
+	}
+	return toRows(flushed)
+}
+
+func notAfter(row xsql.Row, before time.Time) bool {
+	if before.IsZero() {
+		return true
+	}
+	if e, ok := row.(xsql.Event); ok {
+		return !e.GetTimestamp().After(before)
+	}
+	return true
+}
+
+func toRows(rows []*leadRow) []xsql.Row {
+	if len(rows) == 0 {
+		return nil
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].seq < rows[j].seq
+	})
+	result := make([]xsql.Row, len(rows))
+	for i, r := range rows {
+		result[i] = r.row
+	}
+	return result
+}
+
+type leadArgs struct {
+	value  any
+	offset int
+	dft    any
+	valid  bool
+	key    string
+}
+
+// evalLeadArgs evaluates lead(expr, [offset], [default value]) OVER (PARTITION BY ... WHEN ...) of the row
+func evalLeadArgs(call *ast.Call, ve *xsql.ValuerEval) (*leadArgs, error) {
+	a := &leadArgs{offset: 1, valid: true, key: "self"}
+	a.value = ve.Eval(call.Args[0])
+	if e, ok := a.value.(error); ok {
+		return nil, e
+	}
+	if len(call.Args) >= 2 {
+		o := ve.Eval(call.Args[1])
+		offset, err := cast.ToInt(o, cast.STRICT)
+		if err != nil {
+			return nil, fmt.Errorf("error converting second arg %v to int: %v", o, err)
+		}
+		if offset < 0 {
+			return nil, fmt.Errorf("the offset should not be a negative integer")
+		}
+		a.offset = offset
+	}
+	if len(call.Args) >= 3 {
+		a.dft = ve.Eval(call.Args[2])
+		if e, ok := a.dft.(error); ok {
+			return nil, e
+		}
+	}
+	if call.WhenExpr != nil {
+		if w, ok := ve.Eval(call.WhenExpr).(bool); ok {
+			a.valid = w
+		}
+	}
+	if call.Partition != nil && len(call.Partition.Exprs) > 0 {
+		a.key = ""
+		for _, pe := range call.Partition.Exprs {
+			pv := ve.Eval(pe)
+			if e, ok := pv.(error); ok {
+				return nil, e
+			}
+			a.key += fmt.Sprintf("%v", pv)
+		}
+	}
+	return a, nil
+}
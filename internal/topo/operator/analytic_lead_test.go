@@ -0,0 +1,167 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func leadCall(id int, field string, args []ast.Expr, partition string) *ast.Call {
+	c := &ast.Call{
+		Name:        "lead",
+		FuncId:      id,
+		Args:        append([]ast.Expr{&ast.FieldRef{Name: field}}, args...),
+		CachedField: "$$a_lead_" + strconv.Itoa(id),
+	}
+	if partition != "" {
+		c.Partition = &ast.PartitionExpr{Exprs: []ast.Expr{&ast.FieldRef{Name: partition}}}
+	}
+	return c
+}
+
+func leadTuple(id string, v any, ts int64) *xsql.Tuple {
+	return &xsql.Tuple{Emitter: "test", Message: xsql.Message{"id": id, "v": v}, Timestamp: time.UnixMilli(ts)}
+}
+
+// applyLead applies the tuple and returns the values of the lead function of the rows sent out as [v, lead]
+func applyLead(t *testing.T, op *AnalyticFuncsOp, d any, field string) [][]any {
+	ctx := mockContext.NewMockContext("testLead", "analytic")
+	fv, afv := xsql.NewFunctionValuersForOp(ctx)
+	var rows []xsql.Row
+	switch d.(type) {
+	case *xsql.WatermarkTuple, xsql.EOFTuple:
+		rows = op.Flush(ctx, d)
+	default:
+		r := op.Apply(ctx, d, fv, afv)
+		switch rt := r.(type) {
+		case nil:
+		case []xsql.Row:
+			rows = rt
+		default:
+			t.Fatalf("unexpected result %v", r)
+		}
+	}
+	return leadValues(rows, field)
+}
+
+func leadValues(rows []xsql.Row, field string) [][]any {
+	var result [][]any
+	for _, r := range rows {
+		v, _ := r.Value("v", "")
+		lv, _ := r.Value(field, "")
+		result = append(result, []any{v, lv})
+	}
+	return result
+}
+
+func TestLeadPartition(t *testing.T) {
+	op := &AnalyticFuncsOp{FieldFuncs: []*ast.Call{
+		leadCall(0, "v", []ast.Expr{&ast.IntegerLiteral{Val: 1}, &ast.StringLiteral{Val: "none"}}, "id"),
+	}}
+	f := "$$a_lead_0"
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", "a1", 1), f))
+	// the other partition does not resolve the held row
+	assert.Nil(t, applyLead(t, op, leadTuple("d2", "b1", 2), f))
+	assert.Equal(t, [][]any{{"a1", "a2"}}, applyLead(t, op, leadTuple("d1", "a2", 3), f))
+	assert.Equal(t, [][]any{{"b1", "b2"}}, applyLead(t, op, leadTuple("d2", "b2", 4), f))
+	assert.Equal(t, [][]any{{"a2", "a3"}}, applyLead(t, op, leadTuple("d1", "a3", 5), f))
+	// the last row of each partition gets the default value at the EOF in the arrival order
+	assert.Equal(t, [][]any{{"b2", "none"}, {"a3", "none"}}, applyLead(t, op, xsql.EOFDrain, f))
+	assert.Nil(t, applyLead(t, op, xsql.EOFDrain, f))
+}
+
+func TestLeadOffset(t *testing.T) {
+	op := &AnalyticFuncsOp{FieldFuncs: []*ast.Call{
+		leadCall(0, "v", nil, ""),
+		leadCall(1, "v", []ast.Expr{&ast.IntegerLiteral{Val: 2}, &ast.IntegerLiteral{Val: 0}}, ""),
+	}}
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", 1, 1), "$$a_lead_1"))
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", 2, 2), "$$a_lead_1"))
+	// the row is sent out when the values of all the lead functions are resolved
+	ctx := mockContext.NewMockContext("testLead", "analytic")
+	fv, afv := xsql.NewFunctionValuersForOp(ctx)
+	rows := op.Apply(ctx, leadTuple("d1", 3, 3), fv, afv).([]xsql.Row)
+	require.Len(t, rows, 1)
+	assert.Equal(t, [][]any{{1, 2}}, leadValues(rows, "$$a_lead_0"))
+	assert.Equal(t, [][]any{{1, 3}}, leadValues(rows, "$$a_lead_1"))
+	rows = op.Flush(ctx, xsql.EOFBounded)
+	assert.Equal(t, [][]any{{2, 3}, {3, nil}}, leadValues(rows, "$$a_lead_0"))
+	assert.Equal(t, [][]any{{2, int64(0)}, {3, int64(0)}}, leadValues(rows, "$$a_lead_1"))
+}
+
+func TestLeadWhen(t *testing.T) {
+	c := leadCall(0, "v", []ast.Expr{&ast.IntegerLiteral{Val: 1}, &ast.IntegerLiteral{Val: -1}}, "id")
+	c.WhenExpr = &ast.BinaryExpr{OP: ast.GT, LHS: &ast.FieldRef{Name: "v"}, RHS: &ast.IntegerLiteral{Val: 0}}
+	op := &AnalyticFuncsOp{Funcs: []*ast.Call{c}}
+	f := "$$a_lead_0"
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", 1, 1), f))
+	// the row not meeting the condition gets the default value and is not a following row
+	assert.Equal(t, [][]any{{0, int64(-1)}}, applyLead(t, op, leadTuple("d1", 0, 2), f))
+	assert.Equal(t, [][]any{{1, 2}}, applyLead(t, op, leadTuple("d1", 2, 3), f))
+}
+
+func TestLeadWatermark(t *testing.T) {
+	op := &AnalyticFuncsOp{FieldFuncs: []*ast.Call{
+		leadCall(0, "v", []ast.Expr{&ast.IntegerLiteral{Val: 1}, &ast.IntegerLiteral{Val: 0}}, "id"),
+	}}
+	f := "$$a_lead_0"
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", 1, 1000), f))
+	assert.Nil(t, applyLead(t, op, leadTuple("d2", 2, 1500), f))
+	assert.Equal(t, [][]any{{2, 3}}, applyLead(t, op, leadTuple("d2", 3, 2500), f))
+	// the rows not after the watermark are flushed so that they are not late for the window
+	assert.Equal(t, [][]any{{1, int64(0)}}, applyLead(t, op, &xsql.WatermarkTuple{Timestamp: time.UnixMilli(2000)}, f))
+	assert.Nil(t, applyLead(t, op, &xsql.WatermarkTuple{Timestamp: time.UnixMilli(2000)}, f))
+	// the flushed row is not sent again
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", 4, 3000), f))
+	assert.Equal(t, [][]any{{3, int64(0)}, {4, int64(0)}}, applyLead(t, op, xsql.EOFDrain, f))
+}
+
+func TestLeadWatermarkOutOfOrder(t *testing.T) {
+	op := &AnalyticFuncsOp{FieldFuncs: []*ast.Call{
+		leadCall(0, "v", []ast.Expr{&ast.IntegerLiteral{Val: 2}, &ast.IntegerLiteral{Val: 0}}, ""),
+	}}
+	f := "$$a_lead_0"
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", 1, 3000), f))
+	assert.Nil(t, applyLead(t, op, leadTuple("d1", 2, 1800), f))
+	assert.Equal(t, [][]any{{2, int64(0)}}, applyLead(t, op, &xsql.WatermarkTuple{Timestamp: time.UnixMilli(2000)}, f))
+	// the flushed row still counts as a following row
+	assert.Equal(t, [][]any{{1, 3}}, applyLead(t, op, leadTuple("d1", 3, 3100), f))
+}
+
+func TestLeadCollection(t *testing.T) {
+	op := &AnalyticFuncsOp{FieldFuncs: []*ast.Call{
+		leadCall(0, "v", []ast.Expr{&ast.IntegerLiteral{Val: 1}, &ast.StringLiteral{Val: "none"}}, "id"),
+	}}
+	ctx := mockContext.NewMockContext("testLead", "analytic")
+	fv, afv := xsql.NewFunctionValuersForOp(ctx)
+	wt := &xsql.WindowTuples{Content: []xsql.Row{
+		leadTuple("d1", "a1", 1), leadTuple("d2", "b1", 2), leadTuple("d1", "a2", 3),
+	}}
+	r := op.Apply(ctx, wt, fv, afv)
+	require.Equal(t, wt, r)
+	// the rows of which the following rows are not in the window get the default value
+	assert.Equal(t, [][]any{{"a1", "a2"}, {"b1", "none"}, {"a2", "none"}}, leadValues(wt.Content, "$$a_lead_0"))
+	// the window does not hold rows for the streaming rows
+	assert.Nil(t, op.Flush(ctx, xsql.EOFDrain))
+}
@@ -16,6 +16,7 @@ package operator
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
@@ -26,11 +27,34 @@ import (
 type AnalyticFuncsOp struct {
 	Funcs      []*ast.Call
 	FieldFuncs []*ast.Call
+	// leads holds the rows until the following rows of the lead functions arrive, nil if there is no lead function
+	leads    *leadBuffer
+	leadInit bool
+}
+
+func (p *AnalyticFuncsOp) getLeads() *leadBuffer {
+	if !p.leadInit {
+		p.leadInit = true
+		var calls []*ast.Call
+		for _, call := range append(p.FieldFuncs, p.Funcs...) {
+			if call.Name == leadFunc {
+				calls = append(calls, call)
+			}
+		}
+		if len(calls) > 0 {
+			p.leads = newLeadBuffer(calls)
+		}
+	}
+	return p.leads
 }
 
 func (p *AnalyticFuncsOp) evalTupleFunc(calls []*ast.Call, ve *xsql.ValuerEval, input xsql.Row) (xsql.Row, error) {
 	for _, call := range calls {
 		f := call
+		// lead is evaluated by the lookahead buffer
+		if f.Name == leadFunc {
+			continue
+		}
 		result := ve.Eval(f)
 		if e, ok := result.(error); ok {
 			return nil, e
@@ -45,6 +69,9 @@ func (p *AnalyticFuncsOp) evalCollectionFunc(calls []*ast.Call, fv *xsql.Functio
 		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(row, &xsql.WindowRangeValuer{WindowRange: input.GetWindowRange()}, fv, &xsql.WildcardValuer{Data: row})}
 		for _, call := range calls {
 			f := call
+			if f.Name == leadFunc {
+				continue
+			}
 			result := ve.Eval(f)
 			if e, ok := result.(error); ok {
 				return false, e
@@ -59,6 +86,25 @@ func (p *AnalyticFuncsOp) evalCollectionFunc(calls []*ast.Call, fv *xsql.Functio
 	return input, nil
 }
 
+// evalCollectionLead evaluates the lead functions within the collection. The rows of which the following rows are not
+// in the collection get the default values.
+func (p *AnalyticFuncsOp) evalCollectionLead(fv *xsql.FunctionValuer, input xsql.Collection) (xsql.Collection, error) {
+	if p.getLeads() == nil {
+		return input, nil
+	}
+	leads := newLeadBuffer(p.leads.calls)
+	err := input.RangeSet(func(_ int, row xsql.Row) (bool, error) {
+		ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(row, &xsql.WindowRangeValuer{WindowRange: input.GetWindowRange()}, fv, &xsql.WildcardValuer{Data: row})}
+		_, err := leads.add(ve, row)
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	leads.flush(time.Time{})
+	return input, nil
+}
+
 func (p *AnalyticFuncsOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) (got interface{}) {
 	ctx.GetLogger().Debugf("AnalyticFuncsOp receive: %v", data)
 	var err error
@@ -75,6 +121,16 @@ func (p *AnalyticFuncsOp) Apply(ctx api.StreamContext, data interface{}, fv *xsq
 		if err != nil {
 			return err
 		}
+		if leads := p.getLeads(); leads != nil {
+			rows, err := leads.add(ve, input)
+			if err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return rows
+		}
 		data = input
 	case xsql.Collection:
 		input, err = p.evalCollectionFunc(p.FieldFuncs, fv, input)
@@ -85,9 +141,29 @@ func (p *AnalyticFuncsOp) Apply(ctx api.StreamContext, data interface{}, fv *xsq
 		if err != nil {
 			return err
 		}
+		input, err = p.evalCollectionLead(fv, input)
+		if err != nil {
+			return err
+		}
 		data = input
 	default:
 		return fmt.Errorf("run analytic funcs op error: invalid input %[1]T(%[1]v)", input)
 	}
 	return data
 }
+
+// Flush sends out the rows held by the lead functions with the default values. The rows not after the watermark are
+// flushed so that they are not late for the window, and all rows are flushed at the EOF.
+func (p *AnalyticFuncsOp) Flush(_ api.StreamContext, item any) []xsql.Row {
+	leads := p.getLeads()
+	if leads == nil {
+		return nil
+	}
+	switch d := item.(type) {
+	case *xsql.WatermarkTuple:
+		return leads.flush(d.Timestamp)
+	case xsql.EOFTuple:
+		return leads.flush(time.Time{})
+	}
+	return nil
+}
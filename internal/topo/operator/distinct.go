@@ -0,0 +1,69 @@
+// Copyright 2022-2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+)
+
+// distinctSet remembers the hashes of the emitted rows of SELECT DISTINCT. To bound the memory, the oldest hash is
+// evicted when the capacity is reached, so a duplicate row may be emitted again after a long time.
+type distinctSet struct {
+	seen map[uint64]struct{}
+	ring []uint64
+	next int
+}
+
+func newDistinctSet(capacity int) *distinctSet {
+	return &distinctSet{
+		seen: make(map[uint64]struct{}, capacity),
+		ring: make([]uint64, 0, capacity),
+	}
+}
+
+// add returns false if the hash is remembered. Otherwise, it remembers the hash and returns true.
+func (s *distinctSet) add(h uint64) bool {
+	if _, ok := s.seen[h]; ok {
+		return false
+	}
+	if len(s.ring) < cap(s.ring) {
+		s.ring = append(s.ring, h)
+	} else {
+		delete(s.seen, s.ring[s.next])
+		s.ring[s.next] = h
+		s.next = (s.next + 1) % len(s.ring)
+	}
+	s.seen[h] = struct{}{}
+	return true
+}
+
+func (s *distinctSet) len() int {
+	return len(s.seen)
+}
+
+// hashRow hashes the projected columns. The keys of the map are sorted by the json encoding, so the same row always
+// has the same hash.
+func hashRow(m map[string]any) uint64 {
+	h := fnv.New64a()
+	b, err := json.Marshal(m)
+	if err != nil {
+		// The fmt package also prints the map sorted by keys
+		b = []byte(fmt.Sprintf("%v", m))
+	}
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
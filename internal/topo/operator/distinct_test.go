@@ -0,0 +1,132 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+func TestDistinctSetBounded(t *testing.T) {
+	s := newDistinctSet(1000)
+	for i := 0; i < 100000; i++ {
+		require.True(t, s.add(uint64(i)))
+		require.LessOrEqual(t, s.len(), 1000)
+	}
+	assert.Equal(t, 1000, s.len())
+	// the latest hashes are remembered
+	assert.False(t, s.add(99999))
+	assert.False(t, s.add(99000))
+	// the oldest hash is evicted
+	assert.True(t, s.add(0))
+	assert.True(t, s.add(98999))
+}
+
+func TestHashRow(t *testing.T) {
+	assert.Equal(t, hashRow(map[string]any{"a": 1, "b": "x"}), hashRow(map[string]any{"b": "x", "a": 1}))
+	assert.NotEqual(t, hashRow(map[string]any{"a": 1}), hashRow(map[string]any{"a": "1"}))
+	assert.NotEqual(t, hashRow(map[string]any{"a": 1}), hashRow(map[string]any{"a": 1, "b": nil}))
+}
+
+func TestProjectPlan_Distinct(t *testing.T) {
+	window := func() *xsql.WindowTuples {
+		return &xsql.WindowTuples{
+			Content: []xsql.Row{
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 1, "f1": "v1"}},
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 2, "f1": "v2"}},
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 3, "f1": "v1"}},
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 4, "f1": "v3"}},
+				&xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": 5, "f1": "v2"}},
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		sql    string
+		limit  int
+		result []map[string]any
+	}{
+		{
+			name: "dedup window",
+			sql:  "SELECT DISTINCT f1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)",
+			result: []map[string]any{
+				{"f1": "v1"}, {"f1": "v2"}, {"f1": "v3"},
+			},
+		},
+		{
+			name: "no duplicate",
+			sql:  "SELECT DISTINCT id1, f1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10)",
+			result: []map[string]any{
+				{"id1": 1, "f1": "v1"}, {"id1": 2, "f1": "v2"}, {"id1": 3, "f1": "v1"}, {"id1": 4, "f1": "v3"}, {"id1": 5, "f1": "v2"},
+			},
+		},
+		{
+			name:  "limit counts the distinct rows",
+			sql:   "SELECT DISTINCT f1 FROM src1 GROUP BY TUMBLINGWINDOW(ss, 10) LIMIT 2",
+			limit: 2,
+			result: []map[string]any{
+				{"f1": "v1"}, {"f1": "v2"},
+			},
+		},
+	}
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_Distinct")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			require.True(t, stmt.Distinct)
+			pp := &ProjectOp{Distinct: true, EnableLimit: tt.limit > 0, LimitCount: tt.limit}
+			parseStmt(pp, stmt.Fields)
+			fv, afv := xsql.NewFunctionValuersForOp(nil)
+			// each window is deduplicated separately
+			for i := 0; i < 2; i++ {
+				result, err := parseResult(pp.Apply(ctx, window(), fv, afv), pp.IsAggregate)
+				require.NoError(t, err)
+				assert.Equal(t, tt.result, result)
+			}
+		})
+	}
+}
+
+func TestProjectPlan_DistinctStream(t *testing.T) {
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT DISTINCT f1 FROM src1")).Parse()
+	require.NoError(t, err)
+	pp := &ProjectOp{Distinct: true, DistinctCapacity: 2}
+	parseStmt(pp, stmt.Fields)
+	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_DistinctStream")
+	ctx := context.WithValue(context.Background(), context.LoggerKey, contextLogger)
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+	inputs := []string{"v1", "v2", "v1", "v3", "v2", "v1"}
+	// v1 is evicted by v3, so it is emitted again
+	expected := []any{"v1", "v2", nil, "v3", nil, "v1"}
+	for i, f1 := range inputs {
+		result := pp.Apply(ctx, &xsql.Tuple{Emitter: "src1", Message: xsql.Message{"id1": i, "f1": f1}}, fv, afv)
+		if expected[i] == nil {
+			assert.Nil(t, result, "%d", i)
+			continue
+		}
+		r, ok := result.(xsql.Row)
+		require.True(t, ok, "%d", i)
+		assert.Equal(t, map[string]any{"f1": expected[i]}, r.ToMap(), "%d", i)
+	}
+}
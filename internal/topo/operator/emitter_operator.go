@@ -22,10 +22,16 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 )
 
+// OriginMetaKey is the metadata key of the stream where the tuple of UNION ALL comes from
+const OriginMetaKey = "sourceStream"
+
 // EmitterOp set the emitter to the stream name
-// It is only planned after shared connection node in which the emitter is not determined in the source side
+// It is planned after shared connection node in which the emitter is not determined in the source side,
+// or after the streams of UNION ALL to merge them into the first stream.
 type EmitterOp struct {
 	Emitter string
+	// Origin is set to the metadata if not empty, so that the merged tuples can tell which stream they come from
+	Origin string
 }
 
 func (p *EmitterOp) Apply(ctx api.StreamContext, data any, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) any {
@@ -33,11 +39,27 @@ func (p *EmitterOp) Apply(ctx api.StreamContext, data any, _ *xsql.FunctionValue
 	switch input := data.(type) {
 	case *xsql.RawTuple:
 		input.Emitter = p.Emitter
+		if p.Origin != "" {
+			input.Metadata = p.withOrigin(input.Metadata)
+		}
 		return input
 	case *xsql.Tuple:
 		input.Emitter = p.Emitter
+		if p.Origin != "" {
+			input.Metadata = p.withOrigin(input.Metadata)
+		}
 		return input
 	default:
 		return fmt.Errorf("run emitter op error: invalid input %[1]T(%[1]v)", input)
 	}
 }
+
+// withOrigin copies the metadata because it may be shared by the rules of a shared stream
+func (p *EmitterOp) withOrigin(meta xsql.Metadata) xsql.Metadata {
+	result := make(xsql.Metadata, len(meta)+1)
+	for k, v := range meta {
+		result[k] = v
+	}
+	result[OriginMetaKey] = p.Origin
+	return result
+}
@@ -72,3 +72,19 @@ func TestEmitterOpApply(t *testing.T) {
 		})
 	}
 }
+
+func TestEmitterOpOrigin(t *testing.T) {
+	ctx := mockContext.NewMockContext("testOp", "emitter")
+	pp := &EmitterOp{Emitter: "demo", Origin: "demo2"}
+	meta := xsql.Metadata{"topic": "t2"}
+	result := pp.Apply(ctx, &xsql.Tuple{Emitter: "demo2", Message: xsql.Message{"a": int64(6)}, Metadata: meta}, nil, nil)
+	assert.Equal(t, &xsql.Tuple{
+		Emitter:  "demo",
+		Message:  xsql.Message{"a": int64(6)},
+		Metadata: xsql.Metadata{"topic": "t2", OriginMetaKey: "demo2"},
+	}, result)
+	// the original metadata is not changed
+	assert.Equal(t, xsql.Metadata{"topic": "t2"}, meta)
+	result = pp.Apply(ctx, &xsql.RawTuple{Emitter: "demo2"}, nil, nil)
+	assert.Equal(t, &xsql.RawTuple{Emitter: "demo", Metadata: xsql.Metadata{OriginMetaKey: "demo2"}}, result)
+}
@@ -147,6 +147,27 @@ func TestMathAndConversionFunc_Apply1(t *testing.T) {
 			result: nil,
 		},
 
+		{
+			sql: "SELECT shiftleft(1,4) AS a, shiftright(16,2) AS b FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: nil,
+			},
+			result: []map[string]interface{}{{
+				"a": 16,
+				"b": 4,
+			}},
+		},
+
+		{
+			sql: "SELECT shiftleft(1.5,2) AS a FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: nil,
+			},
+			result: nil,
+		},
+
 		{
 			sql: "SELECT bitor(1,1) AS a FROM test",
 			data: &xsql.Tuple{
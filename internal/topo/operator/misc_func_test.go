@@ -132,6 +132,39 @@ func TestMiscFunc_Apply1(t *testing.T) {
 				"a": strings.ToLower("414FA339"),
 			}},
 		},
+		{
+			sql: "SELECT murmur3(a) AS a, murmur3(b) AS b FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "The quick brown fox jumps over the lazy dog",
+					"b": "abc",
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": int64(0x2e4ff723),
+				"b": int64(0xb3dd93fa),
+			}},
+		},
+		{
+			// The test vectors of the murmur2 in Kafka, converted to positive
+			sql: "SELECT murmur2(a) AS a, murmur2(b) AS b, murmur2(c) AS c, murmur2(d) % 3 AS d FROM test",
+			data: &xsql.Tuple{
+				Emitter: "test",
+				Message: xsql.Message{
+					"a": "21",
+					"b": "foobar",
+					"c": []byte("abc"),
+					"d": 21,
+				},
+			},
+			result: []map[string]interface{}{{
+				"a": int64(-973932308 & 0x7fffffff),
+				"b": int64(-790332482 & 0x7fffffff),
+				"c": int64(479470107),
+				"d": int64(1173551340 % 3),
+			}},
+		},
 
 		{
 			sql: "SELECT mqtt(topic) AS a FROM test",
@@ -15,15 +15,18 @@
 package operator
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/metrics"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
-	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 // Preprocessor only planned when
@@ -39,11 +42,15 @@ type Preprocessor struct {
 	timestampField string
 	checkSchema    bool
 	isBinary       bool
+	// How to deal with the tuple which fails the validation, error by default
+	validationPolicy string
+	deadletterTopic  string
 }
 
-func NewPreprocessor(isSchemaless bool, fields map[string]*ast.JsonStreamField, _ bool, _ []string, iet bool, timestampField string, timestampFormat string, isBinary bool, strictValidation bool) (*Preprocessor, error) {
+func NewPreprocessor(isSchemaless bool, fields map[string]*ast.JsonStreamField, _ bool, _ []string, iet bool, timestampField string, timestampFormat string, isBinary bool, strictValidation bool, validationPolicy string, deadletterTopic string) (*Preprocessor, error) {
 	p := &Preprocessor{
 		isEventTime: iet, timestampField: timestampField, isBinary: isBinary,
+		validationPolicy: validationPolicy, deadletterTopic: deadletterTopic,
 	}
 	p.defaultFieldProcessor = defaultFieldProcessor{
 		timestampFormat: timestampFormat,
@@ -73,12 +80,18 @@ func (p *Preprocessor) Apply(ctx api.StreamContext, data interface{}, _ *xsql.Fu
 		if !p.isBinary {
 			err := p.validateAndConvert(tuple)
 			if err != nil {
-				return fmt.Errorf("error in preprocessor: %s", err)
+				return p.reject(ctx, tuple, err)
 			}
 		} else {
+			// The binary payload is decoded into the only field which may be renamed by binaryField
 			for name := range p.streamFields {
-				tuple.Message[name] = tuple.Message[message.DefaultField]
-				delete(tuple.Message, message.DefaultField)
+				for k, v := range tuple.Message {
+					if k != name {
+						tuple.Message[name] = v
+						delete(tuple.Message, k)
+					}
+					break
+				}
 				break
 			}
 		}
@@ -88,6 +101,7 @@ func (p *Preprocessor) Apply(ctx api.StreamContext, data interface{}, _ *xsql.Fu
 			if ts, err := cast.InterfaceToTime(t, p.timestampFormat); err != nil {
 				return fmt.Errorf("cannot convert timestamp field %s to timestamp with error %v", p.timestampField, err)
 			} else {
+				tuple.IngestTime = tuple.Timestamp
 				tuple.Timestamp = ts
 				log.Debugf("preprocessor calculate timestamp %d", tuple.Timestamp.UnixMilli())
 			}
@@ -107,3 +121,32 @@ func (p *Preprocessor) Apply(ctx api.StreamContext, data interface{}, _ *xsql.Fu
 	//}
 	return tuple
 }
+
+// reject deals with the tuple which fails the validation by the validation policy. For drop and deadletter policy, the
+// tuple is skipped and counted so that the rule continues.
+func (p *Preprocessor) reject(ctx api.StreamContext, tuple *xsql.Tuple, err error) any {
+	switch p.validationPolicy {
+	case ast.ValidationPolicyDrop:
+		ctx.GetLogger().Warnf("drop the tuple which fails the validation: %v", err)
+	case ast.ValidationPolicyDeadLetter:
+		payload := tuple.Rawdata
+		if payload == nil {
+			// The source ingests the decoded map directly
+			payload, _ = json.Marshal(tuple.Message)
+		}
+		pubsub.Produce(ctx, p.deadletterTopic, &xsql.Tuple{
+			Message: map[string]any{
+				"payload": payload,
+				"error":   err.Error(),
+				"stream":  tuple.Emitter,
+				"ruleId":  ctx.GetRuleId(),
+			},
+			Metadata:  tuple.Metadata,
+			Timestamp: timex.GetNow(),
+		})
+	default:
+		return fmt.Errorf("error in preprocessor: %s", err)
+	}
+	metrics.SourceValidationRejectCounter.WithLabelValues(p.validationPolicy, ctx.GetRuleId(), ctx.GetOpId()).Inc()
+	return nil
+}
@@ -28,12 +28,14 @@ import (
 
 	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/converter"
+	"github.com/lf-edge/ekuiper/v2/internal/io/memory/pubsub"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/context"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/topotest/mocknode"
 	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/message"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
 )
 
 func TestPreprocessor_Apply(t *testing.T) {
@@ -695,7 +697,7 @@ func TestPreprocessorTime_Apply(t *testing.T) {
 		if tt.stmt.Options != nil {
 			timestampFormat = tt.stmt.Options.TIMESTAMP_FORMAT
 		}
-		pp, e := NewPreprocessor(false, tt.stmt.StreamFields.ToJsonSchema(), false, nil, false, "", timestampFormat, false, true)
+		pp, e := NewPreprocessor(false, tt.stmt.StreamFields.ToJsonSchema(), false, nil, false, "", timestampFormat, false, true, "", "")
 		assert.NoError(t, e)
 		dm := make(map[string]interface{})
 		if e := json.Unmarshal(tt.data, &dm); e != nil {
@@ -1072,3 +1074,54 @@ func TestPreprocessorForBinary(t *testing.T) {
 
 	}
 }
+
+func TestPreprocessorValidationPolicy(t *testing.T) {
+	sf := ast.StreamFields{
+		{Name: "abc", FieldType: &ast.BasicType{Type: ast.BIGINT}},
+	}
+	fields := sf.ToJsonSchema()
+	ctx := mockContext.NewMockContext("testValidation", "op")
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+
+	pp, err := NewPreprocessor(false, fields, false, nil, false, "", "", false, true, ast.ValidationPolicyError, "")
+	require.NoError(t, err)
+	result := pp.Apply(ctx, &xsql.Tuple{Message: map[string]any{"abc": "a"}}, fv, afv)
+	assert.EqualError(t, result.(error), "error in preprocessor: field abc type mismatch: cannot convert string(a) to int64")
+
+	pp, err = NewPreprocessor(false, fields, false, nil, false, "", "", false, true, ast.ValidationPolicyDrop, "")
+	require.NoError(t, err)
+	assert.Nil(t, pp.Apply(ctx, &xsql.Tuple{Message: map[string]any{"def": 1}}, fv, afv))
+	// The good tuple is converted as usual
+	assert.Equal(t, &xsql.Tuple{Message: map[string]any{"abc": int64(1)}}, pp.Apply(ctx, &xsql.Tuple{Message: map[string]any{"abc": 1.0}}, fv, afv))
+
+	topic := "testValidation/rejected"
+	ch := pubsub.CreateSub(topic, "test", 10)
+	defer pubsub.CloseSourceConsumerChannel(topic, "test")
+	pp, err = NewPreprocessor(false, fields, false, nil, false, "", "", false, true, ast.ValidationPolicyDeadLetter, topic)
+	require.NoError(t, err)
+	assert.Nil(t, pp.Apply(ctx, &xsql.Tuple{Emitter: "demo", Message: map[string]any{"abc": "a"}, Rawdata: []byte(`{"abc":"a"}`)}, fv, afv))
+	// Without raw data, the message is encoded as the payload
+	assert.Nil(t, pp.Apply(ctx, &xsql.Tuple{Emitter: "demo", Message: map[string]any{"def": 1}}, fv, afv))
+	exp := []map[string]any{
+		{
+			"payload": []byte(`{"abc":"a"}`),
+			"error":   "field abc type mismatch: cannot convert string(a) to int64",
+			"stream":  "demo",
+			"ruleId":  "testValidation",
+		},
+		{
+			"payload": []byte(`{"def":1}`),
+			"error":   "field abc is not found",
+			"stream":  "demo",
+			"ruleId":  "testValidation",
+		},
+	}
+	for _, e := range exp {
+		select {
+		case d := <-ch:
+			assert.Equal(t, e, d.(pubsub.MemTuple).ToMap())
+		case <-time.After(time.Second):
+			t.Fatal("dead letter is not received")
+		}
+	}
+}
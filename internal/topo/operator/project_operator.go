@@ -37,12 +37,18 @@ type ProjectOp struct {
 	IsAggregate      bool // Whether the project is used in an aggregate context. This is set by planner by analyzing the SQL query
 	EnableLimit      bool
 	LimitCount       int
+	// Distinct suppresses the duplicate rows in each collection such as a window
+	Distinct bool
+	// DistinctCapacity is the max number of the rows remembered across the events. It is 0 for the window query
+	DistinctCapacity int
 
 	SendMeta bool
 	SendNil  bool
 
 	kvs   []interface{}
 	alias []interface{}
+	// seen remembers the emitted rows of the non-window distinct query
+	seen *distinctSet
 }
 
 // Apply
@@ -64,6 +70,10 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 		if err := pp.project(input, ve); err != nil {
 			return fmt.Errorf("run Select error: %s", err)
 		} else {
+			if pp.Distinct && pp.DistinctCapacity > 0 && !pp.remember(hashRow(input.ToMap())) {
+				log.Debugf("project plan drops the duplicate row %v", input)
+				return nil
+			}
 			if pp.SendMeta {
 				if md, ok := input.(xsql.MetaData); ok {
 					metadata := md.MetaData()
@@ -78,7 +88,7 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 		if pp.IsAggregate {
 			input.SetIsAgg(true)
 			err = input.GroupRange(func(i int, aggRow xsql.CollectionRow) (bool, error) {
-				if pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount {
+				if pp.limited(i) {
 					return false, nil
 				}
 				ve := pp.getVE(aggRow, aggRow, input.GetWindowRange(), fv, afv)
@@ -89,7 +99,7 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 			})
 		} else {
 			err = input.RangeSet(func(i int, row xsql.Row) (bool, error) {
-				if pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount {
+				if pp.limited(i) {
 					return false, nil
 				}
 				aggData, ok := input.(xsql.AggregateData)
@@ -106,12 +116,59 @@ func (pp *ProjectOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.Fun
 		if err != nil {
 			return err
 		}
+		if pp.Distinct {
+			result := pp.distinct(input)
+			if result.Len() == 0 && pp.DistinctCapacity > 0 {
+				return nil
+			}
+			return result
+		}
 	default:
 		return fmt.Errorf("run Select error: invalid input %[1]T(%[1]v)", input)
 	}
 	return data
 }
 
+// limited returns true if the ith row exceeds the limit. For the distinct query, the limit is applied after the
+// duplicate rows are removed.
+func (pp *ProjectOp) limited(i int) bool {
+	return !pp.Distinct && pp.EnableLimit && pp.LimitCount > 0 && i >= pp.LimitCount
+}
+
+// distinct keeps the first occurrence of each row in the order of the collection, so the rows sorted by ORDER BY
+// are still sorted. The limit counts the distinct rows.
+func (pp *ProjectOp) distinct(input xsql.Collection) xsql.Collection {
+	maps := input.ToMaps()
+	sel := make([]int, 0, len(maps))
+	inCollection := make(map[uint64]struct{}, len(maps))
+	for i, m := range maps {
+		if pp.EnableLimit && pp.LimitCount > 0 && len(sel) >= pp.LimitCount {
+			break
+		}
+		h := hashRow(m)
+		if _, ok := inCollection[h]; ok {
+			continue
+		}
+		inCollection[h] = struct{}{}
+		if pp.DistinctCapacity > 0 && !pp.remember(h) {
+			continue
+		}
+		sel = append(sel, i)
+	}
+	if len(sel) == len(maps) {
+		return input
+	}
+	return input.Filter(sel)
+}
+
+// remember returns false if the row has been emitted by the non-window query
+func (pp *ProjectOp) remember(h uint64) bool {
+	if pp.seen == nil {
+		pp.seen = newDistinctSet(pp.DistinctCapacity)
+	}
+	return pp.seen.add(h)
+}
+
 func (pp *ProjectOp) getVE(tuple xsql.RawRow, agg xsql.AggregateData, wr *xsql.WindowRange, fv *xsql.FunctionValuer, afv *xsql.AggregateFunctionValuer) *xsql.ValuerEval {
 	afv.SetData(agg)
 	if pp.IsAggregate {
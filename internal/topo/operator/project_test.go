@@ -2399,6 +2399,38 @@ func TestProjectPlan_AggFuncs(t *testing.T) {
 				"all": 3,
 			}},
 		},
+		// 23 dedup in each group
+		{
+			sql: "SELECT dedup(a, 1) as r1 FROM test GROUP BY TumblingWindow(ss, 10), b",
+			data: &xsql.GroupedTuplesSet{
+				Groups: []*xsql.GroupedTuples{
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "x"}},
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 2, "b": "x"}},
+						},
+					},
+					{
+						Content: []xsql.Row{
+							&xsql.Tuple{Emitter: "test", Message: xsql.Message{"a": 1, "b": "y"}},
+						},
+					},
+				},
+			},
+			result: []map[string]interface{}{
+				{
+					"r1": []interface{}{
+						map[string]interface{}{"a": 1, "b": "x"},
+						map[string]interface{}{"a": 2, "b": "x"},
+					},
+				}, {
+					"r1": []interface{}{
+						map[string]interface{}{"a": 1, "b": "y"},
+					},
+				},
+			},
+		},
 	}
 	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
 	contextLogger := conf.Log.WithField("rule", "TestProjectPlan_AggFuncs")
@@ -124,6 +124,10 @@ func (ps *ProjectSetOperator) handleSRFRow(ctx api.StreamContext, row xsql.Row)
 	if !ok {
 		return nil, fmt.Errorf("can't find the result from the %v function", srfName)
 	}
+	// null array is the same as empty array which produces no row
+	if aValue == nil {
+		aValue = []interface{}{}
+	}
 	aValues, ok := aValue.([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("the argument for the %v function should be array", srfName)
@@ -0,0 +1,88 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// RouteDefaultOp passes the rows which do not meet any of the conditions. It is the filter of the default sink which
+// receives the results not routed to any other sink.
+type RouteDefaultOp struct {
+	Conditions []ast.Expr
+}
+
+func (p *RouteDefaultOp) Apply(ctx api.StreamContext, data interface{}, fv *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	log := ctx.GetLogger()
+	log.Debugf("route default plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Row:
+		matched, err := p.matchAny(input, fv)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return input
+		}
+	case xsql.Collection:
+		var sel []int
+		err := input.Range(func(i int, r xsql.ReadonlyRow) (bool, error) {
+			matched, err := p.matchAny(r, fv)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				sel = append(sel, i)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		r := input.Filter(sel)
+		if r.Len() > 0 {
+			return r
+		}
+	default:
+		return fmt.Errorf("run route error: invalid input %[1]T(%[1]v)", input)
+	}
+	return nil
+}
+
+// matchAny returns true if any condition is true. A nil result is false like the filter
+func (p *RouteDefaultOp) matchAny(row xsql.ReadonlyRow, fv *xsql.FunctionValuer) (bool, error) {
+	ve := &xsql.ValuerEval{Valuer: xsql.MultiValuer(row, fv)}
+	for _, c := range p.Conditions {
+		switch r := ve.Eval(c).(type) {
+		case error:
+			return false, fmt.Errorf("run route error: %s", r)
+		case bool:
+			if r {
+				return true, nil
+			}
+		case nil:
+		default:
+			return false, fmt.Errorf("run route error: invalid condition that returns non-bool value %[1]T(%[1]v)", r)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func TestRouteDefault(t *testing.T) {
+	ctx := mockContext.NewMockContext("testRoute", "op")
+	var conds []ast.Expr
+	for _, c := range []string{"severity > 3", "type = \"alarm\""} {
+		cond, err := xsql.NewParser(strings.NewReader("where " + c)).ParseCondition()
+		require.NoError(t, err)
+		conds = append(conds, cond)
+	}
+	op := &RouteDefaultOp{Conditions: conds}
+	fv, afv := xsql.NewFunctionValuersForOp(nil)
+
+	tests := []struct {
+		data   any
+		result any
+	}{
+		{
+			data:   &xsql.Tuple{Message: map[string]any{"severity": 5, "type": "info"}},
+			result: nil,
+		},
+		{
+			data:   &xsql.Tuple{Message: map[string]any{"severity": 1, "type": "alarm"}},
+			result: nil,
+		},
+		{
+			data:   &xsql.Tuple{Message: map[string]any{"severity": 1, "type": "info"}},
+			result: &xsql.Tuple{Message: map[string]any{"severity": 1, "type": "info"}},
+		},
+		// The null condition does not match
+		{
+			data:   &xsql.Tuple{Message: map[string]any{"other": 1}},
+			result: &xsql.Tuple{Message: map[string]any{"other": 1}},
+		},
+		{
+			data: &xsql.WindowTuples{Content: []xsql.Row{
+				&xsql.Tuple{Message: map[string]any{"severity": 5}},
+				&xsql.Tuple{Message: map[string]any{"severity": 2}},
+			}},
+			result: &xsql.WindowTuples{Content: []xsql.Row{
+				&xsql.Tuple{Message: map[string]any{"severity": 2}},
+			}},
+		},
+		{
+			data:   &xsql.Tuple{Message: map[string]any{"severity": "high"}},
+			result: errors.New("run route error: invalid operation string(high) > int64(3)"),
+		},
+	}
+	for i, tt := range tests {
+		r := op.Apply(ctx, tt.data, fv, afv)
+		assert.Equal(t, tt.result, r, "case %d", i)
+	}
+}
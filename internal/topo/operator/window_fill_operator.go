@@ -0,0 +1,175 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+// WindowFillOp emits a row for each group which has no data in the window. It runs after the projection so that the
+// rows are filled by the output fields.
+// The groups to fill are the enumerated KeyValues or all the groups seen before.
+type WindowFillOp struct {
+	Fill      string
+	Keys      []string
+	KeyValues []map[string]any
+	// The output fields of window_start() and window_end() which are set by the window range instead of filling
+	WindowStartFields []string
+	WindowEndFields   []string
+
+	// The group keys by the order they are seen
+	groups []string
+	// The key values of each group
+	groupKeys map[string]map[string]any
+	// The last row of each group for locf
+	last map[string]map[string]any
+	// The last row of any group to know the output fields and their types
+	template map[string]any
+}
+
+func (p *WindowFillOp) Apply(ctx api.StreamContext, data interface{}, _ *xsql.FunctionValuer, _ *xsql.AggregateFunctionValuer) interface{} {
+	log := ctx.GetLogger()
+	log.Debugf("window fill plan receive %v", data)
+	switch input := data.(type) {
+	case error:
+		return input
+	case xsql.Collection:
+		p.init()
+		var rows []map[string]any
+		// An empty window still has one aggregate row if there is no group by. It is a gap to fill.
+		if input.Len() > 0 {
+			rows = input.ToMaps()
+		}
+		seen := make(map[string]struct{}, len(rows))
+		for _, row := range rows {
+			k, kv := p.groupKey(row)
+			seen[k] = struct{}{}
+			p.addGroup(k, kv)
+			last := make(map[string]any, len(row))
+			for f, v := range row {
+				last[f] = v
+			}
+			p.last[k] = last
+			p.template = last
+		}
+		wr := input.GetWindowRange()
+		gs, isGrouped := input.(*xsql.GroupedTuplesSet)
+		if wr == nil && isGrouped && len(gs.Groups) > 0 {
+			wr = gs.Groups[0].WindowRange
+		}
+		var filled []xsql.Row
+		for _, k := range p.groups {
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			filled = append(filled, &xsql.Tuple{Message: p.fillRow(k, wr)})
+		}
+		if len(filled) == 0 {
+			// The empty set is only emitted by the aggregation for filling
+			if isGrouped && len(gs.Groups) == 0 {
+				return nil
+			}
+			return input
+		}
+		log.Debugf("window fill %d groups", len(filled))
+		content := make([]xsql.Row, 0, len(rows)+len(filled))
+		for _, row := range rows {
+			content = append(content, &xsql.Tuple{Message: row})
+		}
+		return &xsql.WindowTuples{
+			Content:     append(content, filled...),
+			WindowRange: wr,
+		}
+	default:
+		return input
+	}
+}
+
+func (p *WindowFillOp) init() {
+	if p.groupKeys != nil {
+		return
+	}
+	p.groupKeys = make(map[string]map[string]any)
+	p.last = make(map[string]map[string]any)
+	for _, kv := range p.KeyValues {
+		k, v := p.groupKey(kv)
+		p.addGroup(k, v)
+	}
+}
+
+func (p *WindowFillOp) groupKey(row map[string]any) (string, map[string]any) {
+	kv := make(map[string]any, len(p.Keys))
+	vals := make([]any, len(p.Keys))
+	for i, f := range p.Keys {
+		vals[i] = row[f]
+		kv[f] = row[f]
+	}
+	return fmt.Sprintf("%#v", vals), kv
+}
+
+func (p *WindowFillOp) addGroup(k string, kv map[string]any) {
+	if _, ok := p.groupKeys[k]; !ok {
+		p.groups = append(p.groups, k)
+		p.groupKeys[k] = kv
+	}
+}
+
+func (p *WindowFillOp) fillRow(k string, wr *xsql.WindowRange) map[string]any {
+	row := make(map[string]any, len(p.template))
+	last := p.last[k]
+	for f, v := range p.template {
+		switch p.Fill {
+		case def.WindowFillLocf:
+			row[f] = last[f]
+		case def.WindowFillZero:
+			row[f] = zeroOf(v)
+		default:
+			row[f] = nil
+		}
+	}
+	for f, v := range p.groupKeys[k] {
+		row[f] = v
+	}
+	if wr != nil {
+		for _, f := range p.WindowStartFields {
+			row[f], _ = wr.FuncValue("window_start")
+		}
+		for _, f := range p.WindowEndFields {
+			row[f], _ = wr.FuncValue("window_end")
+		}
+	}
+	return row
+}
+
+// zeroOf returns the zero value of the numeric type, or nil for the other types
+func zeroOf(v any) any {
+	switch v.(type) {
+	case int:
+		return 0
+	case int64:
+		return int64(0)
+	case float64:
+		return float64(0)
+	case float32:
+		return float32(0)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	mockContext "github.com/lf-edge/ekuiper/v2/pkg/mock/context"
+)
+
+func newFillWindow(start, end int64, rows ...map[string]any) *xsql.WindowTuples {
+	w := &xsql.WindowTuples{WindowRange: xsql.NewWindowRange(start, end)}
+	for _, r := range rows {
+		w.Content = append(w.Content, &xsql.Tuple{Message: r})
+	}
+	return w
+}
+
+func TestWindowFillLocf(t *testing.T) {
+	ctx := mockContext.NewMockContext("testFill", "op")
+	op := &WindowFillOp{Fill: def.WindowFillLocf, Keys: []string{"id"}, WindowStartFields: []string{"ws"}}
+	tests := []struct {
+		data   *xsql.WindowTuples
+		result []map[string]any
+	}{
+		{
+			data: newFillWindow(0, 10,
+				map[string]any{"id": 1, "avg": 10.5, "ws": int64(0)},
+				map[string]any{"id": 2, "avg": 20.0, "ws": int64(0)},
+			),
+			result: []map[string]any{
+				{"id": 1, "avg": 10.5, "ws": int64(0)},
+				{"id": 2, "avg": 20.0, "ws": int64(0)},
+			},
+		},
+		{
+			data: newFillWindow(10, 20,
+				map[string]any{"id": 1, "avg": 11.0, "ws": int64(10)},
+			),
+			result: []map[string]any{
+				{"id": 1, "avg": 11.0, "ws": int64(10)},
+				{"id": 2, "avg": 20.0, "ws": int64(10)},
+			},
+		},
+		// The last values are carried forward across the empty windows
+		{
+			data: newFillWindow(20, 30),
+			result: []map[string]any{
+				{"id": 1, "avg": 11.0, "ws": int64(20)},
+				{"id": 2, "avg": 20.0, "ws": int64(20)},
+			},
+		},
+		{
+			data: newFillWindow(30, 40),
+			result: []map[string]any{
+				{"id": 1, "avg": 11.0, "ws": int64(30)},
+				{"id": 2, "avg": 20.0, "ws": int64(30)},
+			},
+		},
+		{
+			data: newFillWindow(40, 50,
+				map[string]any{"id": 2, "avg": 21.0, "ws": int64(40)},
+			),
+			result: []map[string]any{
+				{"id": 2, "avg": 21.0, "ws": int64(40)},
+				{"id": 1, "avg": 11.0, "ws": int64(40)},
+			},
+		},
+	}
+	for i, tt := range tests {
+		r := op.Apply(ctx, tt.data, nil, nil)
+		c, ok := r.(xsql.Collection)
+		require.True(t, ok, "case %d", i)
+		assert.Equal(t, tt.result, c.ToMaps(), "case %d", i)
+	}
+}
+
+func TestWindowFillEnumerated(t *testing.T) {
+	ctx := mockContext.NewMockContext("testFill", "op")
+	op := &WindowFillOp{Fill: def.WindowFillZero, Keys: []string{"id"}, KeyValues: []map[string]any{{"id": "a"}, {"id": "b"}}}
+	// Only the keys are known before any data
+	r := op.Apply(ctx, newFillWindow(0, 10), nil, nil)
+	assert.Equal(t, []map[string]any{{"id": "a"}, {"id": "b"}}, r.(xsql.Collection).ToMaps())
+
+	r = op.Apply(ctx, newFillWindow(10, 20, map[string]any{"id": "b", "c": int64(3), "avg": 1.5, "name": "n"}), nil, nil)
+	assert.Equal(t, []map[string]any{
+		{"id": "b", "c": int64(3), "avg": 1.5, "name": "n"},
+		{"id": "a", "c": int64(0), "avg": float64(0), "name": nil},
+	}, r.(xsql.Collection).ToMaps())
+}
+
+func TestWindowFillNoGroup(t *testing.T) {
+	ctx := mockContext.NewMockContext("testFill", "op")
+	op := &WindowFillOp{Fill: def.WindowFillNull}
+	empty := newFillWindow(0, 10)
+	// Nothing to fill before any data
+	assert.Equal(t, empty, op.Apply(ctx, empty, nil, nil))
+
+	w := newFillWindow(10, 20, map[string]any{"c": int64(3)})
+	assert.Equal(t, w, op.Apply(ctx, w, nil, nil))
+
+	// The aggregate row of the empty window is replaced
+	empty = newFillWindow(20, 30)
+	empty.SetIsAgg(true)
+	r := op.Apply(ctx, empty, nil, nil)
+	assert.Equal(t, []map[string]any{{"c": nil}}, r.(xsql.Collection).ToMaps())
+}
@@ -168,6 +168,9 @@ func decorateStmt(s *ast.SelectStatement, store kv.KeyValue, opt *def.RuleOption
 		return nil, nil, nil, walkErr
 	}
 	walkErr = validate(s)
+	if walkErr == nil && opt.StrictGroupBy {
+		walkErr = validateGroupFields(s)
+	}
 	// Collect all analytic function calls so that we can let them run firstly
 	ast.WalkFunc(s, func(n ast.Node) bool {
 		switch f := n.(type) {
@@ -425,7 +428,7 @@ func (c *aggFuncChecker) validate(s *ast.SelectStatement) (err error) {
 	if xsql.IsAggregate(s.Condition) {
 		return fmt.Errorf("Not allowed to call aggregate functions in WHERE clause: %s.", s.Condition)
 	}
-	if !allAggregate(s.Having) {
+	if !allAggregate(s.Having, groupKeys(s.Dimensions)) {
 		return fmt.Errorf("Not allowed to call non-aggregate functions in HAVING clause: %s.", s.Having)
 	}
 	for _, d := range s.Dimensions {
@@ -473,21 +476,65 @@ func (c *groupChecker) validate(s *ast.SelectStatement) error {
 	return nil
 }
 
+// validateGroupFields checks the non-aggregate select fields are the grouping keys or are computed by the grouping
+// keys, so that their values are the same in a group
+func validateGroupFields(s *ast.SelectStatement) error {
+	keys := groupKeys(s.Dimensions)
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, f := range s.Fields {
+		name := f.AName
+		if name == "" {
+			name = f.Name
+		}
+		expr := f.Expr
+		switch et := expr.(type) {
+		case *ast.Wildcard:
+			return fmt.Errorf("select field * is not allowed with GROUP BY, select the grouping keys or the aggregates")
+		case *ast.FieldRef:
+			if _, ok := keys[et.String()]; !ok && et.IsAlias() {
+				expr = et.AliasRef.Expression
+			}
+		}
+		if !allAggregate(expr, keys) {
+			return fmt.Errorf("select field %s must be an aggregate or appear in the GROUP BY clause", name)
+		}
+	}
+	return nil
+}
+
 // file-private functions below
 // allAggregate checks if all expressions of binary expression are aggregate
-func allAggregate(expr ast.Expr) (r bool) {
+// allAggregate checks whether all the values of the expression are the same in a group, which means they are
+// aggregates or the grouping keys
+func allAggregate(expr ast.Expr, keys map[string]struct{}) (r bool) {
 	r = true
 	ast.WalkFunc(expr, func(n ast.Node) bool {
+		if _, ok := keys[expr.String()]; ok {
+			return false
+		}
 		switch f := expr.(type) {
 		case *ast.BinaryExpr:
 			switch f.OP {
 			case ast.SUBSET, ast.ARROW:
 				// do nothing
 			default:
-				r = allAggregate(f.LHS) && allAggregate(f.RHS)
+				r = allAggregate(f.LHS, keys) && allAggregate(f.RHS, keys)
 				return false
 			}
-		case *ast.Call, *ast.FieldRef:
+		case *ast.Call:
+			if !xsql.IsAggregate(f) {
+				// The non-aggregate function of the grouping keys is still the same in a group
+				for _, arg := range f.Args {
+					if !allAggregate(arg, keys) {
+						r = false
+						break
+					}
+				}
+				return false
+			}
+		case *ast.FieldRef:
 			if !xsql.IsAggregate(f) {
 				r = false
 				return false
@@ -498,6 +545,19 @@ func allAggregate(expr ast.Expr) (r bool) {
 	return
 }
 
+// groupKeys returns the grouping expressions of the GROUP BY clause except the window
+func groupKeys(dimensions ast.Dimensions) map[string]struct{} {
+	groups := dimensions.GetGroups()
+	if len(groups) == 0 {
+		return nil
+	}
+	keys := make(map[string]struct{}, len(groups))
+	for _, d := range groups {
+		keys[d.Expr.String()] = struct{}{}
+	}
+	return keys
+}
+
 func convertStreamInfo(streamStmt *ast.StreamStmt) (*streamInfo, error) {
 	ss := streamStmt.StreamFields
 	var err error
@@ -141,6 +141,22 @@ var tests = []struct {
 		sql: "select a + 1 as b, b * 2 as c, c + 1 as a from src1",
 		r:   newErrorStruct("select fields have cycled alias"),
 	},
+	{
+		sql: `SELECT name, count(*) FROM src1 WHERE temp > 20 GROUP BY name, TumblingWindow(ss, 10) HAVING name = "dname" AND count(*) > 1`,
+		r:   newErrorStruct(""),
+	},
+	{
+		sql: `SELECT count(*) FROM src1 GROUP BY name, TumblingWindow(ss, 10) HAVING lower(name) = "dname" AND avg(temp) > 10`,
+		r:   newErrorStruct(""),
+	},
+	{
+		sql: `SELECT count(*) FROM src1 GROUP BY temp % 10, TumblingWindow(ss, 10) HAVING temp % 10 = 1`,
+		r:   newErrorStruct(""),
+	},
+	{
+		sql: `SELECT count(*) FROM src1 GROUP BY name, TumblingWindow(ss, 10) HAVING temp > 20`,
+		r:   newErrorStruct("Not allowed to call non-aggregate functions in HAVING clause: binaryExpr:{ src1.temp > 20 }."),
+	},
 	//{ // 19 already captured in parser
 	//	sql: `SELECT * FROM src1 GROUP BY SlidingWindow(ss,5) Over (WHEN abs(sum(a)) > 1) HAVING last_agg_hit_count() < 3`,
 	//	r:   newErrorStruct("error compile sql: Not allowed to call aggregate functions in GROUP BY clause."),
@@ -511,3 +527,57 @@ func TestValidateStmt(t *testing.T) {
 	err = validate(stmt)
 	require.Error(t, err)
 }
+
+func TestStrictGroupBy(t *testing.T) {
+	store, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM src1 (id1 BIGINT, temp BIGINT, name string) WITH (DATASOURCE="src1", FORMAT="json", KEY="ts");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.Set("src1", string(s)))
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{
+			sql: "SELECT floor(temp / 10) AS bucket, count(*) AS c FROM src1 GROUP BY floor(temp / 10), TumblingWindow(ss, 10)",
+		},
+		{
+			sql: "SELECT substring(name, 0, 3) AS prefix, floor(temp / 10) * 10 AS low, avg(temp) AS a, window_end() AS we FROM src1 GROUP BY substring(name, 0, 3), floor(temp / 10), TumblingWindow(ss, 10)",
+		},
+		{
+			sql: "SELECT name, count(*) AS c FROM src1 GROUP BY TumblingWindow(ss, 10)",
+		},
+		{
+			sql: "SELECT name, count(*) AS c FROM src1 GROUP BY floor(temp / 10), TumblingWindow(ss, 10)",
+			err: "select field name must be an aggregate or appear in the GROUP BY clause",
+		},
+		{
+			sql: "SELECT temp + 1 AS t, count(*) AS c FROM src1 GROUP BY floor(temp / 10), TumblingWindow(ss, 10)",
+			err: "select field t must be an aggregate or appear in the GROUP BY clause",
+		},
+		{
+			sql: "SELECT * FROM src1 GROUP BY name, TumblingWindow(ss, 10)",
+			err: "select field * is not allowed with GROUP BY, select the grouping keys or the aggregates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			_, err = createLogicalPlan(stmt, &def.RuleOption{StrictGroupBy: true}, store)
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+			// Without the strict mode, the non-aggregate fields are allowed
+			stmt, err = xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			_, err = createLogicalPlan(stmt, &def.RuleOption{}, store)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -50,6 +50,10 @@ type DataSourcePlan struct {
 	pruneFields []string
 	// inRuleTest means whether in the rule test mode
 	inRuleTest bool
+	// isUnion means the stream is merged with other streams by UNION ALL
+	isUnion bool
+	// unionOf is the first stream of UNION ALL which this stream is merged into. The fields are referred by its name.
+	unionOf ast.StreamName
 }
 
 func (p DataSourcePlan) Init() *DataSourcePlan {
@@ -141,7 +145,8 @@ func (p *DataSourcePlan) BuildExplainInfo() {
 
 // PushDownPredicate Presume no children for data source
 func (p *DataSourcePlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
-	if p.streamStmt.StreamType == ast.TypeTable {
+	// The condition of UNION ALL must apply to all the merged streams, so keep it above them
+	if p.streamStmt.StreamType == ast.TypeTable || p.isUnion {
 		return condition, p.self
 	}
 	owned, other := p.extract(condition)
@@ -166,7 +171,7 @@ func (p *DataSourcePlan) extract(expr ast.Expr) (ast.Expr, ast.Expr) {
 	case 0:
 		return expr, nil
 	case 1:
-		if s[0] == p.name || s[0] == ast.DefaultStream {
+		if p.ownStream(s[0]) || s[0] == ast.DefaultStream {
 			return expr, nil
 		} else {
 			return nil, expr
@@ -230,7 +235,7 @@ func (p *DataSourcePlan) PruneColumns(fields []ast.Expr) error {
 						if p.allMeta {
 							break
 						}
-						if c.StreamName == ast.DefaultStream || c.StreamName == p.name {
+						if c.StreamName == ast.DefaultStream || p.ownStream(c.StreamName) {
 							if c.Name == "*" {
 								p.allMeta = true
 								p.metaMap = nil
@@ -256,9 +261,9 @@ func (p *DataSourcePlan) PruneColumns(fields []ast.Expr) error {
 				p.pruneFields = append(p.pruneFields, replace.AName)
 			}
 		case *ast.FieldRef:
-			if !p.isWildCard && (f.StreamName == ast.DefaultStream || f.StreamName == p.name) {
+			if !p.isWildCard && (f.StreamName == ast.DefaultStream || p.ownStream(f.StreamName)) {
 				if _, ok := p.fields[f.Name]; !ok {
-					sf, err := p.getField(f.Name, f.StreamName == p.name)
+					sf, err := p.getField(f.Name, p.ownStream(f.StreamName))
 					if err != nil {
 						return err
 					}
@@ -271,7 +276,7 @@ func (p *DataSourcePlan) PruneColumns(fields []ast.Expr) error {
 			if p.allMeta {
 				break
 			}
-			if f.StreamName == ast.DefaultStream || f.StreamName == p.name {
+			if f.StreamName == ast.DefaultStream || p.ownStream(f.StreamName) {
 				if f.Name == "*" {
 					p.allMeta = true
 					p.metaMap = nil
@@ -281,7 +286,7 @@ func (p *DataSourcePlan) PruneColumns(fields []ast.Expr) error {
 			}
 		case *ast.SortField:
 			if !p.isWildCard {
-				sf, err := p.getField(f.Name, f.StreamName == p.name)
+				sf, err := p.getField(f.Name, p.ownStream(f.StreamName))
 				if err != nil {
 					return err
 				}
@@ -375,6 +380,11 @@ func markPruneJSONStreamField(cur interface{}, field *ast.JsonStreamField) {
 	}
 }
 
+// ownStream checks if the stream name of the field reference is this stream or the stream it is merged into
+func (p *DataSourcePlan) ownStream(name ast.StreamName) bool {
+	return name == p.name || (p.unionOf != "" && name == p.unionOf)
+}
+
 func (p *DataSourcePlan) getField(name string, strict bool) (*ast.JsonStreamField, error) {
 	for col, alias := range p.colAliasMapping {
 		if name == alias {
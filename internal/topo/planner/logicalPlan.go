@@ -155,6 +155,7 @@ const (
 	PROJECTSET    PlanType = "ProjectSetPlan"
 	WINDOW        PlanType = "WindowPlan"
 	WINDOWFUNC    PlanType = "WindowFuncPlan"
+	WINDOWFILL    PlanType = "WindowFillPlan"
 	WATERMARK     PlanType = "WatermarkPlan"
 	IncAggWindow  PlanType = "IncAggWindowPlan"
 )
@@ -61,6 +61,11 @@ func (p *LookupPlan) BuildExplainInfo() {
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
 
+// isSemi returns whether the lookup is an EXISTS or NOT EXISTS check
+func (p *LookupPlan) isSemi() bool {
+	return p.joinExpr.JoinType == ast.SEMI_JOIN || p.joinExpr.JoinType == ast.ANTI_JOIN
+}
+
 // PushDownPredicate do not deal with conditions, push down or return up
 func (p *LookupPlan) PushDownPredicate(condition ast.Expr) (ast.Expr, LogicalPlan) {
 	a := combine(condition, p.conditions)
@@ -228,7 +233,11 @@ func (p *LookupPlan) PruneColumns(fields []ast.Expr) error {
 		}
 		newFields = append(newFields, field)
 	}
-	if !isWildcard {
+	if p.isSemi() {
+		// The existence check does not need any field of the table but the keys
+		p.fields = append([]string(nil), p.keys...)
+		sort.Strings(p.fields)
+	} else if !isWildcard {
 		p.fields = make([]string, 0, len(fieldMap))
 		for k := range fieldMap {
 			p.fields = append(p.fields, k)
@@ -245,3 +245,58 @@ func TestExplainPushAlias(t *testing.T) {
 		require.Equal(t, tc.explain, explain, tc.sql)
 	}
 }
+
+func TestExplainDistinct(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	require.NoError(t, prepareStream())
+
+	testcases := []struct {
+		sql     string
+		explain string
+	}{
+		{
+			sql: `select distinct a from stream`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ stream.a ], Distinct:true"}
+	{"op":"DataSourcePlan_1","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+		{
+			sql: `select distinct a from stream group by countwindow(2)`,
+			explain: `{"op":"ProjectPlan_0","info":"Fields:[ stream.a ], Distinct:true"}
+	{"op":"WindowPlan_1","info":"{ length:2, windowType:COUNT_WINDOW, limit: 0 }"}
+			{"op":"DataSourcePlan_2","info":"StreamName: stream, StreamFields:[ a ]"}`,
+		},
+	}
+	for _, tc := range testcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		p, err := createLogicalPlan(stmt, &def.RuleOption{}, kv)
+		require.NoError(t, err)
+		explain, err := ExplainFromLogicalPlan(p, "")
+		require.NoError(t, err)
+		require.Equal(t, tc.explain, explain, tc.sql)
+	}
+
+	errcases := []struct {
+		sql string
+		opt *def.RuleOption
+		err string
+	}{
+		{
+			sql: `select distinct unnest(a) from stream`,
+			opt: &def.RuleOption{},
+			err: "SELECT DISTINCT cannot be used with set-returning functions",
+		},
+		{
+			sql: `select distinct a from stream`,
+			opt: &def.RuleOption{DistinctCapacity: -1},
+			err: "distinctCapacity must not be negative",
+		},
+	}
+	for _, tc := range errcases {
+		stmt, err := xsql.NewParser(strings.NewReader(tc.sql)).Parse()
+		require.NoError(t, err)
+		_, err = createLogicalPlan(stmt, tc.opt, kv)
+		require.EqualError(t, err, tc.err, tc.sql)
+	}
+}
@@ -77,7 +77,8 @@ func PlanSQLWithSourcesAndSinks(rule *def.Rule, mockSourcesProp map[string]map[s
 	if err != nil {
 		return nil, err
 	}
-	tp, err := createTopo(rule, lp, mockSourcesProp, streamsFromStmt)
+	// The merged streams of UNION ALL are also the sources of the rule
+	tp, err := createTopo(rule, lp, mockSourcesProp, append(streamsFromStmt, xsql.GetUnionStreams(stmt)...))
 	if err != nil {
 		return nil, err
 	}
@@ -226,6 +227,19 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 		} else {
 			newIndex += indexInc
 		}
+		// Merge the streams of UNION ALL into the first stream and tag the origin
+		if t.isUnion {
+			if onode, ok := op.(node.OperatorNode); ok {
+				tp.AddOperator(inputs, onode)
+			}
+			inputs = []node.Emitter{op}
+			emitter := t.name
+			if t.unionOf != "" {
+				emitter = t.unionOf
+			}
+			newIndex++
+			op = Transform(&operator.EmitterOp{Emitter: string(emitter), Origin: string(t.name)}, fmt.Sprintf("%d_union", newIndex), options)
+		}
 	case *WatermarkPlan:
 		op = node.NewWatermarkOp(fmt.Sprintf("%d_watermark", newIndex), t.SendWatermark, t.Emitters, options)
 	case *AnalyticFuncsPlan:
@@ -283,6 +297,7 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 			TimeUnit:         t.timeUnit,
 			TriggerCondition: t.triggerCondition,
 			StateFuncs:       t.stateFuncs,
+			Dimensions:       t.dimensions,
 		}, options)
 		if err != nil {
 			return nil, 0, err
@@ -299,18 +314,20 @@ func buildOps(lp LogicalPlan, tp *topo.Topo, options *def.RuleOption, sources ma
 		t.ExtractStateFunc()
 		op = Transform(&operator.FilterOp{Condition: t.condition, StateFuncs: t.stateFuncs}, fmt.Sprintf("%d_filter", newIndex), options)
 	case *AggregatePlan:
-		op = Transform(&operator.AggregateOp{Dimensions: t.dimensions}, fmt.Sprintf("%d_aggregate", newIndex), options)
+		op = Transform(&operator.AggregateOp{Dimensions: t.dimensions, KeepEmpty: t.keepEmpty}, fmt.Sprintf("%d_aggregate", newIndex), options)
 	case *HavingPlan:
 		t.ExtractStateFunc()
 		op = Transform((&operator.HavingOp{Condition: t.condition, StateFuncs: t.stateFuncs, IsIncAgg: t.IsIncAgg}), fmt.Sprintf("%d_having", newIndex), options)
 	case *OrderPlan:
 		op = Transform(&operator.OrderOp{SortFields: t.SortFields}, fmt.Sprintf("%d_order", newIndex), options)
 	case *ProjectPlan:
-		op = Transform(&operator.ProjectOp{ColNames: t.colNames, AliasNames: t.aliasNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, ExprNames: t.exprNames, SendMeta: t.sendMeta, SendNil: t.sendNil, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_project", newIndex), options)
+		op = Transform(&operator.ProjectOp{ColNames: t.colNames, AliasNames: t.aliasNames, AliasFields: t.aliasFields, ExprFields: t.exprFields, ExceptNames: t.exceptNames, IsAggregate: t.isAggregate, AllWildcard: t.allWildcard, WildcardEmitters: t.wildcardEmitters, ExprNames: t.exprNames, SendMeta: t.sendMeta, SendNil: t.sendNil, LimitCount: t.limitCount, EnableLimit: t.enableLimit, Distinct: t.distinct, DistinctCapacity: t.distinctCapacity}, fmt.Sprintf("%d_project", newIndex), options)
 	case *ProjectSetPlan:
 		op = Transform(&operator.ProjectSetOperator{SrfMapping: t.SrfMapping, LimitCount: t.limitCount, EnableLimit: t.enableLimit}, fmt.Sprintf("%d_projectset", newIndex), options)
 	case *WindowFuncPlan:
 		op = Transform(&operator.WindowFuncOperator{WindowFuncField: t.windowFuncField}, fmt.Sprintf("%d_windowFunc", newIndex), options)
+	case *WindowFillPlan:
+		op = Transform(&operator.WindowFillOp{Fill: t.fill, Keys: t.keys, KeyValues: t.keyValues, WindowStartFields: t.windowStartFields, WindowEndFields: t.windowEndFields}, fmt.Sprintf("%d_windowFill", newIndex), options)
 	default:
 		err = fmt.Errorf("unknown logical plan %v", t)
 	}
@@ -344,6 +361,24 @@ func CreateLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 	return createLogicalPlan(stmt, opt, store)
 }
 
+// defaultDistinctCapacity is the default max number of the rows remembered by SELECT DISTINCT of the non-window query
+const defaultDistinctCapacity = 10000
+
+// getDistinctCapacity returns the max number of the rows remembered by SELECT DISTINCT across the events. It is 0 if
+// the rows are only deduplicated in each window.
+func getDistinctCapacity(stmt *ast.SelectStatement, opt *def.RuleOption, hasWindow bool) (int, error) {
+	if opt.DistinctCapacity < 0 {
+		return 0, errors.New("distinctCapacity must not be negative")
+	}
+	if !stmt.Distinct || hasWindow {
+		return 0, nil
+	}
+	if opt.DistinctCapacity == 0 {
+		return defaultDistinctCapacity, nil
+	}
+	return opt.DistinctCapacity, nil
+}
+
 func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue) (lp LogicalPlan, err error) {
 	defer func() {
 		if err != nil {
@@ -408,6 +443,15 @@ func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 			}
 		}
 	}
+	if len(stmt.Unions) > 0 {
+		children, err = planUnions(stmt, streamStmts, children, opt, store)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := planSubQueries(stmt, opt, store); err != nil {
+		return nil, err
+	}
 	hasWindow := dimensions != nil && dimensions.GetWindow() != nil
 	if opt.IsEventTime {
 		p = WatermarkPlan{
@@ -480,6 +524,9 @@ func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 				if w.TriggerCondition != nil {
 					wp.triggerCondition = w.TriggerCondition
 				}
+				if w.WindowType == ast.SESSION_WINDOW {
+					wp.dimensions = dimensions.GetGroups()
+				}
 				// TODO calculate limit
 				// TODO incremental aggregate
 				wp.SetChildren(children)
@@ -489,6 +536,13 @@ func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 		}
 	}
 	if stmt.Joins != nil {
+		for _, join := range stmt.Joins {
+			if join.JoinType == ast.SEMI_JOIN || join.JoinType == ast.ANTI_JOIN {
+				if _, ok := lookupTableChildren[join.Name]; !ok {
+					return nil, fmt.Errorf("EXISTS is only supported for lookup table, but %s is not", join.Name)
+				}
+			}
+		}
 		if len(lookupTableChildren) == 0 && len(scanTableChildren) == 0 && w == nil {
 			return nil, errors.New("a time window or count window is required to join multiple streams")
 		}
@@ -503,6 +557,9 @@ func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 					if !lookupPlan.validateAndExtractCondition() {
 						return nil, fmt.Errorf("join condition %s is invalid, at least one equi-join predicate is required", join.Expr)
 					}
+					if lookupPlan.isSemi() && lookupPlan.conditions != nil {
+						return nil, fmt.Errorf("EXISTS condition %s is invalid, only equi predicates of the lookup table are supported", join.Expr)
+					}
 					p = lookupPlan.Init()
 					p.SetChildren(children)
 					children = []LogicalPlan{p}
@@ -546,6 +603,7 @@ func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 		if ds != nil && len(ds) > 0 {
 			p = AggregatePlan{
 				dimensions: ds,
+				keepEmpty:  opt.WindowFill != nil,
 			}.Init()
 			p.SetChildren(children)
 			children = []LogicalPlan{p}
@@ -576,6 +634,13 @@ func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 		children = []LogicalPlan{p}
 	}
 	srfMapping := extractSRFMapping(stmt)
+	if stmt.Distinct && len(srfMapping) > 0 {
+		return nil, errors.New("SELECT DISTINCT cannot be used with set-returning functions")
+	}
+	distinctCap, err := getDistinctCapacity(stmt, opt, hasWindow)
+	if err != nil {
+		return nil, err
+	}
 	if stmt.Fields != nil {
 		// extract dedup trigger op
 		fields := make([]ast.Field, 0, len(stmt.Fields))
@@ -631,10 +696,21 @@ func createLogicalPlan(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.
 			sendNil:     opt.SendNil,
 			enableLimit: enableLimit,
 			limitCount:  limitCount,
+			distinct:    stmt.Distinct,
+			// The rows are only deduplicated in each window for the window query
+			distinctCapacity: distinctCap,
 		}.Init()
 		p.SetChildren(children)
 		children = []LogicalPlan{p}
 	}
+	if opt.WindowFill != nil {
+		p, err = newWindowFillPlan(stmt, opt.WindowFill)
+		if err != nil {
+			return nil, err
+		}
+		p.SetChildren(children)
+		children = []LogicalPlan{p}
+	}
 
 	if len(srfMapping) > 0 {
 		enableLimit := false
@@ -764,7 +840,8 @@ func extractNodeIncAgg(node ast.Node, index *int) ([]*ast.Field, bool) {
 		case *ast.Call:
 			if f.FuncType == ast.FuncTypeAgg {
 				hasAgg = true
-				if !function.IsSupportedIncAgg(f.Name) {
+				// DISTINCT and the ignoreNull argument of collect are not supported in incremental aggregation
+				if !function.IsSupportedIncAgg(f.Name) || f.Distinct || len(f.Args) > 1 && f.Name == "collect" {
 					canIncAgg = false
 					return false
 				}
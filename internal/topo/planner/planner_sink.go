@@ -17,15 +17,20 @@ package planner
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
-	"github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
+	nodeConf "github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/operator"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/model"
 )
 
@@ -39,7 +44,7 @@ func buildActions(tp *topo.Topo, rule *def.Rule, inputs []node.Emitter, streamCo
 			if !ok {
 				return fmt.Errorf("expect map[string]interface{} type for the action properties, but found %v", action)
 			}
-			props, err := conf.OverwriteByConnectionConf(name, props)
+			props, err := nodeConf.OverwriteByConnectionConf(name, props)
 			if err != nil {
 				return err
 			}
@@ -75,25 +80,40 @@ func PlanSinkOps(tp *topo.Topo, inputs []node.Emitter, cn node.CompNode) {
 	}
 }
 
-func SinkToComp(tp *topo.Topo, sinkType string, sinkName string, props map[string]any, rule *def.Rule, streamCount int) (node.CompNode, error) {
+func SinkToComp(tp *topo.Topo, sinkType string, sinkName string, rawProps map[string]any, rule *def.Rule, streamCount int) (node.CompNode, error) {
 	s, _ := io.Sink(sinkType)
 	if s == nil {
 		return nil, fmt.Errorf("sink %s is not defined", sinkType)
 	}
+	// Only log the raw props to avoid exposing the secrets
+	props, err := conf.ResolveProps(rawProps)
+	if err != nil {
+		return nil, err
+	}
 	commonConf, err := node.ParseConf(tp.GetContext().GetLogger(), props)
 	if err != nil {
 		return nil, fmt.Errorf("fail to parse sink configuration: %v", err)
 	}
 	templates := findTemplateProps(props)
+	if ts, ok := s.(model.TemplateSink); ok {
+		templates = append(templates, ts.Templates(props)...)
+	}
+	routeOp, err := planRoute(sinkName, rule, commonConf)
+	if err != nil {
+		return nil, err
+	}
 	// Split sink node
 	sinkOps, err := splitSink(tp, s, sinkName, rule.Options, commonConf, templates)
 	if err != nil {
 		return nil, err
 	}
+	if routeOp != nil {
+		sinkOps = append([]node.TopNode{routeOp}, sinkOps...)
+	}
 	if err = s.Provision(tp.GetContext(), props); err != nil {
 		return nil, err
 	}
-	tp.GetContext().GetLogger().Infof("provision sink %s with props %+v", sinkName, props)
+	tp.GetContext().GetLogger().Infof("provision sink %s with props %+v", sinkName, conf.Printable(rawProps))
 
 	result := &SinkCompNode{
 		name:  sinkName,
@@ -123,7 +143,7 @@ func SinkToComp(tp *topo.Topo, sinkType string, sinkName string, props map[strin
 		if err = s.Provision(tp.GetContext(), props); err != nil {
 			return nil, err
 		}
-		tp.GetContext().GetLogger().Infof("provision sink %s with props %+v", sinkName, props)
+		tp.GetContext().GetLogger().Infof("provision sink %s with props %+v", sinkName, conf.Printable(rawProps))
 
 		cacheOp, err := node.NewCacheOp(tp.GetContext(), fmt.Sprintf("%s_cache", sinkName), rule.Options, &commonConf.SinkConf)
 		if err != nil {
@@ -149,6 +169,50 @@ func SinkToComp(tp *topo.Topo, sinkType string, sinkName string, props map[strin
 	return result, nil
 }
 
+// planRoute creates the filter of the sink by its routing condition. The default sink filters out the results which
+// meet the condition of any other action.
+func planRoute(sinkName string, rule *def.Rule, sc *node.SinkConf) (node.TopNode, error) {
+	switch {
+	case sc.When != "":
+		cond, err := parseWhen(sc.When)
+		if err != nil {
+			return nil, err
+		}
+		return Transform(&operator.FilterOp{Condition: cond}, fmt.Sprintf("%s_when", sinkName), rule.Options), nil
+	case sc.RouteDefault:
+		var conds []ast.Expr
+		for _, m := range rule.Actions {
+			for _, action := range m {
+				props, ok := action.(map[string]any)
+				if !ok {
+					continue
+				}
+				if w, ok := props["when"].(string); ok && w != "" {
+					cond, err := parseWhen(w)
+					if err != nil {
+						return nil, err
+					}
+					conds = append(conds, cond)
+				}
+			}
+		}
+		return Transform(&operator.RouteDefaultOp{Conditions: conds}, fmt.Sprintf("%s_default", sinkName), rule.Options), nil
+	default:
+		return nil, nil
+	}
+}
+
+func parseWhen(when string) (ast.Expr, error) {
+	cond, err := xsql.NewParser(strings.NewReader("where " + when)).ParseCondition()
+	if err != nil {
+		return nil, fmt.Errorf("invalid when condition %s: %v", when, err)
+	}
+	if cond == nil {
+		return nil, fmt.Errorf("invalid when condition %s", when)
+	}
+	return cond, nil
+}
+
 func findTemplateProps(props map[string]any) []string {
 	var result []string
 	re := regexp.MustCompile(`{{(.*?)}}`)
@@ -187,7 +251,15 @@ func splitSink(tp *topo.Topo, s api.Sink, sinkName string, options *def.RuleOpti
 	}
 	// Transform enabled
 	// Currently, the row to map is done here and is required. TODO: eliminate map and this could become optional
-	transformOp, err := node.NewTransformOp(fmt.Sprintf("%s_%d_transform", sinkName, index), options, sc, templates)
+	tsc := sc
+	if sinkInfo.HasTransform {
+		c := *sc
+		c.DataTemplate = ""
+		c.DataField = ""
+		c.Fields = nil
+		tsc = &c
+	}
+	transformOp, err := node.NewTransformOp(fmt.Sprintf("%s_%d_transform", sinkName, index), options, tsc, templates)
 	if err != nil {
 		return nil, err
 	}
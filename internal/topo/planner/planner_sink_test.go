@@ -57,6 +57,51 @@ func TestSinkPlan(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "conditional routing sink plan",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"when": "severity > 3",
+						},
+					},
+					{
+						"log": map[string]any{
+							"routeDefault": true,
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			topo: &def.PrintableTopo{
+				Sources: []string{"source_src1"},
+				Edges: map[string][]any{
+					"source_src1": {
+						"op_log_0_when",
+						"op_log_1_default",
+					},
+					"op_log_0_when": {
+						"op_log_0_0_transform",
+					},
+					"op_log_0_0_transform": {
+						"op_log_0_1_encode",
+					},
+					"op_log_0_1_encode": {
+						"sink_log_0",
+					},
+					"op_log_1_default": {
+						"op_log_1_0_transform",
+					},
+					"op_log_1_0_transform": {
+						"op_log_1_1_encode",
+					},
+					"op_log_1_1_encode": {
+						"sink_log_1",
+					},
+				},
+			},
+		},
 		{
 			name: "batch sink plan",
 			rule: &def.Rule{
@@ -323,6 +368,66 @@ func TestSinkPlanError(t *testing.T) {
 			},
 			err: "fail to parse sink configuration: invalid lingerInterval -1000000, must be positive",
 		},
+		{
+			name: "invalid when",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"when": "severity >",
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			err: "invalid when condition severity >: found \"EOF\", expected expression.",
+		},
+		{
+			name: "when with routeDefault",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"when":         "severity > 3",
+							"routeDefault": true,
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			err: "fail to parse sink configuration: when and routeDefault cannot be set together",
+		},
+		{
+			name: "ordered with concurrency",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"ordered":     true,
+							"concurrency": 2,
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			err: "fail to parse sink configuration: concurrency must be 1 in ordered mode but found 2",
+		},
+		{
+			name: "ordered with resendAlterQueue",
+			rule: &def.Rule{
+				Actions: []map[string]any{
+					{
+						"log": map[string]any{
+							"ordered":          true,
+							"enableCache":      true,
+							"resendAlterQueue": true,
+						},
+					},
+				},
+				Options: defaultOption,
+			},
+			err: "fail to parse sink configuration: resendAlterQueue cannot be enabled in ordered mode",
+		},
 		{
 			name: "invalid dataTemplate",
 			rule: &def.Rule{
@@ -20,6 +20,7 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 
 	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
@@ -37,16 +38,8 @@ func transformSourceNode(ctx api.StreamContext, t *DataSourcePlan, mockSourcesPr
 		t.streamStmt.Options.TYPE = "simulator"
 		t.inRuleTest = true
 	}
-	strType := t.streamStmt.Options.TYPE
-	if strType == "" {
-		switch t.streamStmt.StreamType {
-		case ast.TypeStream:
-			strType = "mqtt"
-		case ast.TypeTable:
-			strType = "file"
-		}
-		t.streamStmt.Options.TYPE = strType
-	}
+	strType := getSourceType(t.streamStmt)
+	t.streamStmt.Options.TYPE = strType
 	si, err := io.Source(strType)
 	if err != nil {
 		return nil, nil, 0, err
@@ -55,8 +48,10 @@ func transformSourceNode(ctx api.StreamContext, t *DataSourcePlan, mockSourcesPr
 		return nil, nil, 0, fmt.Errorf("source type %s not found", strType)
 	}
 	var pp node.UnOperation
-	if t.iet || (!isSchemaless && (t.streamStmt.Options.STRICT_VALIDATION || t.isBinary)) {
-		pp, err = operator.NewPreprocessor(isSchemaless, t.streamFields, t.allMeta, t.metaFields, t.iet, t.timestampField, t.timestampFormat, t.isBinary, t.streamStmt.Options.STRICT_VALIDATION)
+	// Setting the validation policy also turns on the validation
+	strictValidation := t.streamStmt.Options.STRICT_VALIDATION || t.streamStmt.Options.VALIDATION_POLICY != ""
+	if t.iet || (!isSchemaless && (strictValidation || t.isBinary)) {
+		pp, err = operator.NewPreprocessor(isSchemaless, t.streamFields, t.allMeta, t.metaFields, t.iet, t.timestampField, t.timestampFormat, t.isBinary, strictValidation, t.streamStmt.Options.VALIDATION_POLICY, t.streamStmt.Options.DEADLETTER_TOPIC)
 		if err != nil {
 			return nil, nil, 0, err
 		}
@@ -64,6 +59,20 @@ func transformSourceNode(ctx api.StreamContext, t *DataSourcePlan, mockSourcesPr
 	return splitSource(ctx, t, si, options, mockProps, index, ruleId, pp)
 }
 
+// getSourceType returns the source type of the stream, which is mqtt for streams and file for tables by default
+func getSourceType(stmt *ast.StreamStmt) string {
+	if stmt.Options.TYPE != "" {
+		return stmt.Options.TYPE
+	}
+	switch stmt.StreamType {
+	case ast.TypeStream:
+		return "mqtt"
+	case ast.TypeTable:
+		return "file"
+	}
+	return ""
+}
+
 func splitSource(ctx api.StreamContext, t *DataSourcePlan, ss api.Source, options *def.RuleOption, mockProps map[string]any, index int, ruleId string, pp node.UnOperation) (node.DataSourceNode, []node.OperatorNode, int, error) {
 	// Get all props
 	props := nodeConf.GetSourceConf(t.streamStmt.Options.TYPE, t.streamStmt.Options)
@@ -91,12 +100,21 @@ func splitSource(ctx api.StreamContext, t *DataSourcePlan, ss api.Source, option
 	var ops []node.OperatorNode
 	// If having unique connection id AND unique sub id for each connection, need to share the sub node
 	if conId == "" {
-		srcConnNode, err = node.NewSourceNode(ctx, string(t.name), ss, props, options)
+		if sp.Concurrency > 1 {
+			srcConnNode, err = node.NewPartitionedSourceNode(ctx, string(t.name), ss, func() (api.Source, error) {
+				return io.Source(getSourceType(t.streamStmt))
+			}, props, options, sp.Concurrency)
+		} else {
+			srcConnNode, err = node.NewSourceNode(ctx, string(t.name), ss, props, options)
+		}
 		if err != nil {
 			return nil, nil, 0, err
 		}
 		index++
 	} else { // connection selector is set as a one node sub_topo
+		if sp.Concurrency > 1 {
+			return nil, nil, 0, fmt.Errorf("concurrency is not supported for the source with the shared connection %s", conId)
+		}
 		subId := t.streamStmt.Options.DATASOURCE
 		if hasSubId {
 			subId = us.SubId(props)
@@ -187,6 +205,15 @@ func splitSource(ctx api.StreamContext, t *DataSourcePlan, ss api.Source, option
 		ops = append(ops, payloadDecodeNode)
 	}
 
+	if featureSet.needThrottle {
+		tOp, err := node.NewThrottleOp(fmt.Sprintf("%d_throttle", index), options, sp.RateLimit, sp.RateBurst)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		index++
+		ops = append(ops, tOp)
+	}
+
 	// Create the preprocessor node if needed
 	if pp != nil {
 		ops = append(ops, Transform(pp, fmt.Sprintf("%d_preprocessor", index), options))
@@ -220,6 +247,11 @@ type SourcePropsForSplit struct {
 	MergeField string `json:"mergeField"`
 	Merger     string `json:"merger"`
 	Format     string `json:"format"`
+	// the events per second and the bucket size of the throttle
+	RateLimit float64 `json:"rateLimit"`
+	RateBurst int     `json:"rateBurst"`
+	// the number of the parallel readers of the partitioned source
+	Concurrency int `json:"concurrency"`
 }
 
 type traits struct {
@@ -231,6 +263,8 @@ type traits struct {
 	needRatelimit bool
 	// rate limit merge will plan after decompress
 	needRatelimitMerge bool
+	// throttle will plan after decode to pace the decoded events
+	needThrottle bool
 }
 
 // function to return if a sub node is needed
@@ -258,6 +292,7 @@ func checkFeatures(ss api.Source, sp *SourcePropsForSplit, props map[string]any)
 		needCompression:   sp.Decompression != "" && (!info.HasCompress || info.NeedBatchDecode),
 		needDecode:        info.NeedDecode,
 		needPayloadDecode: sp.PayloadFormat != "",
+		needThrottle:      sp.RateLimit != 0,
 	}
 	if sp.Interval > 0 {
 		switch ss.(type) {
@@ -320,7 +355,10 @@ func planLookupSource(ctx api.StreamContext, t *LookupPlan, ruleOption *def.Rule
 	if si == nil {
 		return nil, fmt.Errorf("lookup source type %s not found", t.options.TYPE)
 	}
-	props := nodeConf.GetSourceConf(t.options.TYPE, t.options)
+	props, err := conf.ResolveProps(nodeConf.GetSourceConf(t.options.TYPE, t.options))
+	if err != nil {
+		return nil, err
+	}
 	switch si.(type) {
 	case api.LookupSource:
 		return node.NewLookupNode(ctx, t.joinExpr.Name, false, t.fields, t.keys, t.joinExpr.JoinType, t.valvars, t.options, ruleOption, props)
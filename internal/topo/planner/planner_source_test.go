@@ -58,6 +58,7 @@ func TestPlanTopo(t *testing.T) {
 		"filesrc2": `CREATE STREAM fs2 () WITH (FORMAT="delimited", TYPE="file",CONF_KEY="csv");`,
 		"filesrc3": `CREATE STREAM fs3 () WITH (FORMAT="json",TYPE="file",CONF_KEY="json");`,
 		"neuron1":  `CREATE STREAM neuron1 () WITH (FORMAT="json", TYPE="neuron",CONF_KEY="tcp");`,
+		"src6":     `CREATE STREAM src6 () WITH (DATASOURCE="src6", FORMAT="json", TYPE="mqtt");`,
 	}
 	for name, sql := range streamSqls {
 		s, err := json.Marshal(&xsql.StreamInfo{
@@ -180,6 +181,42 @@ func TestPlanTopo(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "testUnion",
+			sql:  `SELECT * FROM src1 UNION ALL src6`,
+			topo: &def.PrintableTopo{
+				Sources: []string{"source_src1", "source_src6"},
+				Edges: map[string][]any{
+					"source_src1": {
+						"op_2_decoder",
+					},
+					"op_2_decoder": {
+						"op_3_union",
+					},
+					"op_3_union": {
+						"op_7_project",
+					},
+					"source_src6": {
+						"op_5_decoder",
+					},
+					"op_5_decoder": {
+						"op_6_union",
+					},
+					"op_6_union": {
+						"op_7_project",
+					},
+					"op_7_project": {
+						"op_logToMemory_0_0_transform",
+					},
+					"op_logToMemory_0_0_transform": {
+						"op_logToMemory_0_1_encode",
+					},
+					"op_logToMemory_0_1_encode": {
+						"sink_logToMemory_0",
+					},
+				},
+			},
+		},
 		{
 			name: "testSharedMqttSplit",
 			sql:  `SELECT * FROM src2`,
@@ -0,0 +1,94 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/lookup"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+	"github.com/lf-edge/ekuiper/v2/pkg/model"
+)
+
+const (
+	defaultSubQueryRefreshInterval = time.Minute
+	defaultSubQueryMaxValues       = 10000
+)
+
+// planSubQueries sets the loaders of the IN subqueries in the condition. Each subquery materializes the values of a
+// lookup table which supports scanning.
+func planSubQueries(stmt *ast.SelectStatement, opt *def.RuleOption, store kv.KeyValue) error {
+	if stmt.Condition == nil {
+		return nil
+	}
+	var subQueries []*ast.SubQuery
+	ast.WalkFunc(stmt.Condition, func(n ast.Node) bool {
+		if vs, ok := n.(*ast.ValueSetExpr); ok && vs.SubQuery != nil {
+			subQueries = append(subQueries, vs.SubQuery)
+		}
+		return true
+	})
+	if len(subQueries) == 0 {
+		return nil
+	}
+	refresh, maxValues, err := subQueryOption(opt)
+	if err != nil {
+		return err
+	}
+	for _, sq := range subQueries {
+		streamStmt, err := xsql.GetDataSource(store, sq.Table)
+		if err != nil {
+			return fmt.Errorf("fail to get table %s of IN subquery, please check if table is created", sq.Table)
+		}
+		if streamStmt.StreamType != ast.TypeTable || streamStmt.Options.KIND != ast.StreamKindLookup {
+			return fmt.Errorf("IN subquery only supports lookup table, but %s is not a lookup table", sq.Table)
+		}
+		ls, err := io.LookupSource(getSourceType(streamStmt))
+		if err != nil {
+			return err
+		}
+		if _, ok := ls.(model.ScanLookupSource); !ok {
+			return fmt.Errorf("lookup table %s of type %s does not support IN subquery", sq.Table, getSourceType(streamStmt))
+		}
+		sq.Loader = lookup.NewValueSet(sq, refresh, maxValues)
+	}
+	return nil
+}
+
+func subQueryOption(opt *def.RuleOption) (time.Duration, int, error) {
+	refresh, maxValues := defaultSubQueryRefreshInterval, defaultSubQueryMaxValues
+	if opt.SubQuery == nil {
+		return refresh, maxValues, nil
+	}
+	if opt.SubQuery.RefreshInterval < 0 {
+		return 0, 0, errors.New("subQuery refreshInterval must not be negative")
+	}
+	if opt.SubQuery.RefreshInterval > 0 {
+		refresh = time.Duration(opt.SubQuery.RefreshInterval)
+	}
+	if opt.SubQuery.MaxValues < 0 {
+		return 0, 0, errors.New("subQuery maxValues must not be negative")
+	}
+	if opt.SubQuery.MaxValues > 0 {
+		maxValues = opt.SubQuery.MaxValues
+	}
+	return refresh, maxValues, nil
+}
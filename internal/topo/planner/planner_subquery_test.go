@@ -0,0 +1,117 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/lookup"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/cast"
+)
+
+func TestPlanSubQueries(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	streamSqls := map[string]string{
+		"sqStream":      `CREATE STREAM sqStream (id STRING, temp BIGINT) WITH (DATASOURCE="sqTopic");`,
+		"sqDenylist":    `CREATE TABLE sqDenylist (id STRING, enabled BOOLEAN) WITH (DATASOURCE="sqDenylist", TYPE="memory", KIND="lookup", KEY="id");`,
+		"sqHttpTable":   `CREATE TABLE sqHttpTable (id STRING) WITH (DATASOURCE="/ids", TYPE="httppull", KIND="lookup");`,
+		"sqScanTable":   `CREATE TABLE sqScanTable (id STRING) WITH (DATASOURCE="sqScanTable", TYPE="file");`,
+		"sqOtherStream": `CREATE STREAM sqOtherStream (id STRING) WITH (DATASOURCE="sqOtherTopic");`,
+	}
+	for name, sql := range streamSqls {
+		st := ast.TypeStream
+		if strings.Contains(sql, "TABLE") {
+			st = ast.TypeTable
+		}
+		s, err := json.Marshal(&xsql.StreamInfo{
+			StreamType: st,
+			Statement:  sql,
+		})
+		require.NoError(t, err)
+		require.NoError(t, kv.Set(name, string(s)))
+	}
+
+	stmt, err := xsql.NewParser(strings.NewReader(`SELECT * FROM sqStream WHERE id NOT IN (SELECT id FROM sqDenylist WHERE enabled = true) AND temp > 20`)).Parse()
+	require.NoError(t, err)
+	_, err = createLogicalPlan(stmt, &def.RuleOption{
+		SubQuery: &def.SubQueryOption{RefreshInterval: cast.DurationConf(10 * time.Second)},
+	}, kv)
+	require.NoError(t, err)
+	var sq *ast.SubQuery
+	ast.WalkFunc(stmt.Condition, func(n ast.Node) bool {
+		if vs, ok := n.(*ast.ValueSetExpr); ok && vs.SubQuery != nil {
+			sq = vs.SubQuery
+		}
+		return true
+	})
+	require.NotNil(t, sq)
+	assert.IsType(t, &lookup.ValueSet{}, sq.Loader)
+
+	tests := []struct {
+		sql string
+		opt *def.RuleOption
+		err string
+	}{
+		{
+			sql: `SELECT * FROM sqStream WHERE id IN (SELECT id FROM sqScanTable)`,
+			opt: &def.RuleOption{},
+			err: "IN subquery only supports lookup table, but sqScanTable is not a lookup table",
+		},
+		{
+			sql: `SELECT * FROM sqStream WHERE id IN (SELECT id FROM sqOtherStream)`,
+			opt: &def.RuleOption{},
+			err: "IN subquery only supports lookup table, but sqOtherStream is not a lookup table",
+		},
+		{
+			sql: `SELECT * FROM sqStream WHERE id IN (SELECT id FROM sqHttpTable)`,
+			opt: &def.RuleOption{},
+			err: "lookup table sqHttpTable of type httppull does not support IN subquery",
+		},
+		{
+			sql: `SELECT * FROM sqStream WHERE id IN (SELECT id FROM sqNotExist)`,
+			opt: &def.RuleOption{},
+			err: "fail to get table sqNotExist of IN subquery, please check if table is created",
+		},
+		{
+			sql: `SELECT * FROM sqStream WHERE id IN (SELECT id FROM sqDenylist)`,
+			opt: &def.RuleOption{SubQuery: &def.SubQueryOption{RefreshInterval: cast.DurationConf(-time.Second)}},
+			err: "subQuery refreshInterval must not be negative",
+		},
+		{
+			sql: `SELECT * FROM sqStream WHERE id IN (SELECT id FROM sqDenylist)`,
+			opt: &def.RuleOption{SubQuery: &def.SubQueryOption{MaxValues: -1}},
+			err: "subQuery maxValues must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			_, err = createLogicalPlan(stmt, tt.opt, kv)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
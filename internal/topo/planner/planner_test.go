@@ -4486,6 +4486,79 @@ func Test_createLogicalPlan4Lookup(t *testing.T) {
 				sendMeta:    false,
 			}.Init(),
 		},
+		{ // 5
+			sql: `SELECT src1.a FROM src1 WHERE EXISTS (SELECT * FROM table1 WHERE table1.id = src1.id)`,
+			p: ProjectPlan{
+				baseLogicalPlan: baseLogicalPlan{
+					children: []LogicalPlan{
+						LookupPlan{
+							baseLogicalPlan: baseLogicalPlan{
+								children: []LogicalPlan{
+									DataSourcePlan{
+										baseLogicalPlan: baseLogicalPlan{},
+										name:            "src1",
+										streamFields: map[string]*ast.JsonStreamField{
+											"a":  nil,
+											"id": nil,
+										},
+										isSchemaless: true,
+										streamStmt:   streams["src1"],
+										metaFields:   []string{},
+										pruneFields:  []string{},
+									}.Init(),
+								},
+							},
+							joinExpr: ast.Join{
+								Name:     "table1",
+								Alias:    "",
+								JoinType: ast.SEMI_JOIN,
+								Expr: &ast.BinaryExpr{
+									OP: ast.EQ,
+									LHS: &ast.FieldRef{
+										StreamName: "table1",
+										Name:       "id",
+									},
+									RHS: &ast.FieldRef{
+										StreamName: "src1",
+										Name:       "id",
+									},
+								},
+							},
+							keys:   []string{"id"},
+							fields: []string{"id"},
+							valvars: []ast.Expr{
+								&ast.FieldRef{
+									StreamName: "src1",
+									Name:       "id",
+								},
+							},
+							options: &ast.Options{
+								DATASOURCE: "table1",
+								TYPE:       "sql",
+								KIND:       "lookup",
+							},
+							conditions: nil,
+						}.Init(),
+					},
+				},
+				fields: []ast.Field{
+					{
+						Expr: &ast.FieldRef{
+							StreamName: "src1",
+							Name:       "a",
+						},
+						Name:  "a",
+						AName: "",
+					},
+				},
+				isAggregate: false,
+				sendMeta:    false,
+			}.Init(),
+		},
+		{ // 6
+			sql: `SELECT src1.a FROM src1 WHERE NOT EXISTS (SELECT * FROM table1 WHERE table1.id = src1.id AND table1.b > 20)`,
+			err: "EXISTS condition binaryExpr:{ binaryExpr:{ table1.id = src1.id } AND binaryExpr:{ table1.b > 20 } } is invalid, only equi predicates of the lookup table are supported",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.sql, func(t *testing.T) {
@@ -0,0 +1,108 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+	"github.com/lf-edge/ekuiper/v2/pkg/kv"
+)
+
+// planUnions creates the data source plans of the streams merged into the first stream by UNION ALL.
+// The fields are bound to the first stream, so the merged streams must have the same schema.
+func planUnions(stmt *ast.SelectStatement, streamStmts []*streamInfo, children []LogicalPlan, opt *def.RuleOption, store kv.KeyValue) ([]LogicalPlan, error) {
+	if len(stmt.Joins) > 0 {
+		return nil, errors.New("UNION ALL cannot be used with JOIN")
+	}
+	first := streamStmts[0]
+	if first.stmt.StreamType != ast.TypeStream || len(children) != 1 {
+		return nil, fmt.Errorf("UNION ALL is only supported for streams, but %s is not a stream", first.stmt.Name)
+	}
+	fp := children[0].(*DataSourcePlan)
+	fp.isUnion = true
+	names := map[string]struct{}{string(first.stmt.Name): {}}
+	for _, name := range xsql.GetUnionStreams(stmt) {
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf("stream %s is merged more than once by UNION ALL", name)
+		}
+		names[name] = struct{}{}
+		streamStmt, err := xsql.GetDataSource(store, name)
+		if err != nil {
+			return nil, fmt.Errorf("fail to get stream %s, please check if stream is created", name)
+		}
+		if streamStmt.StreamType != ast.TypeStream {
+			return nil, fmt.Errorf("UNION ALL is only supported for streams, but %s is not a stream", name)
+		}
+		si, err := convertStreamInfo(streamStmt)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkUnionSchema(first, si); err != nil {
+			return nil, fmt.Errorf("stream %s of UNION ALL is not compatible with stream %s: %v", name, first.stmt.Name, err)
+		}
+		p := DataSourcePlan{
+			name:            si.stmt.Name,
+			streamStmt:      si.stmt,
+			streamFields:    si.schema.ToJsonSchema(),
+			isSchemaless:    si.schema == nil,
+			iet:             opt.IsEventTime,
+			allMeta:         opt.SendMetaToSink,
+			colAliasMapping: fp.colAliasMapping,
+			isUnion:         true,
+			unionOf:         first.stmt.Name,
+		}.Init()
+		children = append(children, p)
+	}
+	return children, nil
+}
+
+// checkUnionSchema checks if the merged stream has the same fields and types as the first stream
+func checkUnionSchema(first *streamInfo, si *streamInfo) error {
+	if (first.schema == nil) != (si.schema == nil) {
+		return errors.New("the streams must be both schemaless or both defined with the same schema")
+	}
+	if first.schema == nil {
+		return nil
+	}
+	fs, ss := first.schema.ToJsonSchema(), si.schema.ToJsonSchema()
+	names := make([]string, 0, len(fs))
+	for name := range fs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sf, ok := ss[name]
+		if !ok {
+			return fmt.Errorf("field %s is not found", name)
+		}
+		if !reflect.DeepEqual(fs[name], sf) {
+			return fmt.Errorf("field %s has a different type", name)
+		}
+	}
+	if len(ss) != len(fs) {
+		for name := range ss {
+			if _, ok := fs[name]; !ok {
+				return fmt.Errorf("field %s is not defined in stream %s", name, first.stmt.Name)
+			}
+		}
+	}
+	return nil
+}
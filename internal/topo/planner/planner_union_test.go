@@ -0,0 +1,123 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestPlanUnion(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	streamSqls := map[string]string{
+		"union1":     `CREATE STREAM union1 (a BIGINT, b BIGINT) WITH (DATASOURCE="topic1");`,
+		"union2":     `CREATE STREAM union2 (a BIGINT, b BIGINT) WITH (DATASOURCE="topic2");`,
+		"union3":     `CREATE STREAM union3 (a STRING, b BIGINT) WITH (DATASOURCE="topic3");`,
+		"union4":     `CREATE STREAM union4 (a BIGINT) WITH (DATASOURCE="topic4");`,
+		"union5":     `CREATE STREAM union5 (a BIGINT, b BIGINT, c BIGINT) WITH (DATASOURCE="topic5");`,
+		"union6":     `CREATE STREAM union6 () WITH (DATASOURCE="topic6");`,
+		"unionTable": `CREATE TABLE unionTable (a BIGINT, b BIGINT) WITH (DATASOURCE="table1", TYPE="file");`,
+	}
+	for name, sql := range streamSqls {
+		st := ast.TypeStream
+		if strings.Contains(sql, "TABLE") {
+			st = ast.TypeTable
+		}
+		s, err := json.Marshal(&xsql.StreamInfo{
+			StreamType: st,
+			Statement:  sql,
+		})
+		require.NoError(t, err)
+		require.NoError(t, kv.Set(name, string(s)))
+	}
+
+	stmt, err := xsql.NewParser(strings.NewReader(`SELECT a FROM union1 UNION ALL union2 WHERE b > 1`)).Parse()
+	require.NoError(t, err)
+	p, err := createLogicalPlan(stmt, &def.RuleOption{}, kv)
+	require.NoError(t, err)
+	// the condition is kept above the merged streams
+	require.Len(t, p.Children(), 1)
+	fp, ok := p.Children()[0].(*FilterPlan)
+	require.True(t, ok)
+	require.Len(t, fp.Children(), 2)
+	for i, name := range []ast.StreamName{"union1", "union2"} {
+		dp, ok := fp.Children()[i].(*DataSourcePlan)
+		require.True(t, ok)
+		assert.Equal(t, name, dp.name)
+		assert.True(t, dp.isUnion)
+		assert.Len(t, dp.streamFields, 2)
+	}
+	assert.Equal(t, ast.StreamName("union1"), fp.Children()[1].(*DataSourcePlan).unionOf)
+
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{
+			sql: `SELECT * FROM union1 UNION ALL union3`,
+			err: "stream union3 of UNION ALL is not compatible with stream union1: field a has a different type",
+		},
+		{
+			sql: `SELECT * FROM union1 UNION ALL union4`,
+			err: "stream union4 of UNION ALL is not compatible with stream union1: field b is not found",
+		},
+		{
+			sql: `SELECT * FROM union1 UNION ALL union5`,
+			err: "stream union5 of UNION ALL is not compatible with stream union1: field c is not defined in stream union1",
+		},
+		{
+			sql: `SELECT * FROM union1 UNION ALL union6`,
+			err: "stream union6 of UNION ALL is not compatible with stream union1: the streams must be both schemaless or both defined with the same schema",
+		},
+		{
+			sql: `SELECT * FROM union1 UNION ALL union2 UNION ALL union2`,
+			err: "stream union2 is merged more than once by UNION ALL",
+		},
+		{
+			sql: `SELECT * FROM union1 UNION ALL unionTable`,
+			err: "UNION ALL is only supported for streams, but unionTable is not a stream",
+		},
+		{
+			sql: `SELECT * FROM unionTable UNION ALL union1`,
+			err: "UNION ALL is only supported for streams, but unionTable is not a stream",
+		},
+		{
+			sql: `SELECT * FROM union1 UNION ALL union2 INNER JOIN unionTable ON union1.a = unionTable.a`,
+			err: "UNION ALL cannot be used with JOIN",
+		},
+		{
+			sql: `SELECT * FROM union1 UNION ALL unionNotExist`,
+			err: "fail to get stream unionNotExist, please check if stream is created",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			_, err = createLogicalPlan(stmt, &def.RuleOption{}, kv)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
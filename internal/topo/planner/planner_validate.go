@@ -0,0 +1,206 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/contract/v2/api"
+
+	"github.com/lf-edge/ekuiper/v2/internal/binder/io"
+	"github.com/lf-edge/ekuiper/v2/internal/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	store2 "github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/util"
+	"github.com/lf-edge/ekuiper/v2/internal/topo"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
+	nodeConf "github.com/lf-edge/ekuiper/v2/internal/topo/node/conf"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+// The parts of the rule where the validation errors are found
+const (
+	ValidateTypeRule   = "rule"
+	ValidateTypeSql    = "sql"
+	ValidateTypePlan   = "plan"
+	ValidateTypeSource = "source"
+	ValidateTypeSink   = "sink"
+)
+
+// ValidateError is an error of the rule found by ValidateRule
+type ValidateError struct {
+	Type string `json:"type"`
+	// Name is the stream or the sink name of the error
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+	// Line and Column are the position of the SQL parse error
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+func (e *ValidateError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%s %s: %s", e.Type, e.Name, e.Message)
+	}
+	return e.Message
+}
+
+// ValidateSql validates the SQL alone. It returns the parse error with its position if the SQL is invalid.
+func ValidateSql(sql string) *ValidateError {
+	_, verr := parseSql(sql)
+	return verr
+}
+
+func parseSql(sql string) (*ast.SelectStatement, *ValidateError) {
+	parser := xsql.NewParser(strings.NewReader(sql))
+	st, err := xsql.Language.Parse(parser)
+	if err != nil {
+		line, col := parser.Pos()
+		return nil, &ValidateError{Type: ValidateTypeSql, Message: err.Error(), Line: line, Column: col}
+	}
+	stmt, ok := st.(*ast.SelectStatement)
+	if !ok {
+		return nil, &ValidateError{Type: ValidateTypeSql, Message: fmt.Sprintf("SQL %s is not a select statement.", sql)}
+	}
+	if err := validateStmt(stmt); err != nil {
+		return nil, &ValidateError{Type: ValidateTypeSql, Message: err.Error()}
+	}
+	return stmt, nil
+}
+
+// ValidateRule dry runs the SQL rule. It parses the SQL, creates the logical plan, provisions all the sources and
+// sinks to validate their props and builds the physical plan without running the rule. No connection is opened unless ping is true.
+func ValidateRule(rule *def.Rule, ping bool) []*ValidateError {
+	stmt, verr := parseSql(rule.Sql)
+	if verr != nil {
+		return []*ValidateError{verr}
+	}
+	if rule.Options.SendMetaToSink && (len(xsql.GetStreams(stmt)) > 1 || stmt.Dimensions != nil) {
+		return []*ValidateError{{Type: ValidateTypePlan, Message: "Invalid option sendMetaToSink, it can not be applied to window"}}
+	}
+	store, err := store2.GetKV("stream")
+	if err != nil {
+		return []*ValidateError{{Type: ValidateTypePlan, Message: err.Error()}}
+	}
+	lp, err := createLogicalPlan(stmt, rule.Options, store)
+	if err != nil {
+		return []*ValidateError{{Type: ValidateTypePlan, Message: err.Error()}}
+	}
+	// The topo is only used to provide the context, it is never opened
+	tp, err := topo.NewWithNameAndOptions(rule.Id, rule.Options)
+	if err != nil {
+		return []*ValidateError{{Type: ValidateTypePlan, Message: err.Error()}}
+	}
+	ctx := tp.GetContext()
+	var errs []*ValidateError
+	walkPlan(lp, func(p LogicalPlan) {
+		switch t := p.(type) {
+		case *DataSourcePlan:
+			if err := validateSource(ctx, t, rule.Options, ping); err != nil {
+				errs = append(errs, &ValidateError{Type: ValidateTypeSource, Name: string(t.name), Message: err.Error()})
+			}
+		case *LookupPlan:
+			if _, err := planLookupSource(ctx, t, rule.Options); err != nil {
+				errs = append(errs, &ValidateError{Type: ValidateTypeSource, Name: t.joinExpr.Name, Message: err.Error()})
+			}
+		}
+	})
+	for i, m := range rule.Actions {
+		for name, action := range m {
+			sinkName := fmt.Sprintf("%s_%d", name, i)
+			if err := validateSink(tp, rule, name, sinkName, action, ping); err != nil {
+				errs = append(errs, &ValidateError{Type: ValidateTypeSink, Name: sinkName, Message: err.Error()})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	// Build the physical plan to validate the operators. The sources are mocked so that no shared sub topo is created.
+	mocks := make(map[string]map[string]any)
+	walkPlan(lp, func(p LogicalPlan) {
+		if t, ok := p.(*DataSourcePlan); ok {
+			mocks[string(t.name)] = map[string]any{}
+		}
+	})
+	if _, err := createTopo(rule, lp, mocks, xsql.GetStreams(stmt)); err != nil {
+		return []*ValidateError{{Type: ValidateTypePlan, Message: err.Error()}}
+	}
+	return nil
+}
+
+func walkPlan(p LogicalPlan, f func(p LogicalPlan)) {
+	f(p)
+	for _, c := range p.Children() {
+		walkPlan(c, f)
+	}
+}
+
+// validateSource provisions a new source instance without adding it to the shared sub topo
+func validateSource(ctx api.StreamContext, t *DataSourcePlan, options *def.RuleOption, ping bool) error {
+	strType := getSourceType(t.streamStmt)
+	si, err := io.Source(strType)
+	if err != nil {
+		return err
+	}
+	if si == nil {
+		return fmt.Errorf("source type %s not found", strType)
+	}
+	props := nodeConf.GetSourceConf(strType, t.streamStmt.Options)
+	if _, err := node.NewSourceNode(ctx, string(t.name), si, props, options); err != nil {
+		return err
+	}
+	if ping {
+		return pingConn(ctx, si, props)
+	}
+	return nil
+}
+
+func validateSink(tp *topo.Topo, rule *def.Rule, sinkType, sinkName string, action any, ping bool) error {
+	props, ok := action.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expect map[string]interface{} type for the action properties, but found %v", action)
+	}
+	props, err := nodeConf.OverwriteByConnectionConf(sinkType, props)
+	if err != nil {
+		return err
+	}
+	if _, err := SinkToComp(tp, sinkType, sinkName, props, rule, 1); err != nil {
+		return err
+	}
+	if ping {
+		s, _ := io.Sink(sinkType)
+		return pingConn(tp.GetContext(), s, props)
+	}
+	return nil
+}
+
+// pingConn tests the connection if the source or sink supports it
+func pingConn(ctx api.StreamContext, n any, props map[string]any) error {
+	pingAble, ok := n.(util.PingableConn)
+	if !ok {
+		return nil
+	}
+	resolved, err := conf.ResolveProps(props)
+	if err != nil {
+		return err
+	}
+	if err := pingAble.Ping(ctx, resolved); err != nil {
+		return fmt.Errorf("ping connection error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/store"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+func TestValidateRule(t *testing.T) {
+	kv, err := store.GetKV("stream")
+	require.NoError(t, err)
+	s, err := json.Marshal(&xsql.StreamInfo{
+		StreamType: ast.TypeStream,
+		Statement:  `CREATE STREAM validate1 (a BIGINT) WITH (DATASOURCE="vtopic", TYPE="memory");`,
+	})
+	require.NoError(t, err)
+	require.NoError(t, kv.Set("validate1", string(s)))
+	// The logical plan is valid but the window op cannot be built
+	alignOption := *defaultOption
+	alignOption.WindowAlign = "bad"
+
+	tests := []struct {
+		name string
+		rule *def.Rule
+		errs []*ValidateError
+	}{
+		{
+			name: "valid",
+			rule: &def.Rule{
+				Sql:     "SELECT a FROM validate1",
+				Actions: []map[string]any{{"memory": map[string]any{"topic": "vout"}}},
+				Options: defaultOption,
+			},
+		},
+		{
+			name: "sql error",
+			rule: &def.Rule{
+				Sql:     "SELECT a FROM validate1\nWHER a > 1",
+				Actions: []map[string]any{{"log": map[string]any{}}},
+				Options: defaultOption,
+			},
+			errs: []*ValidateError{{Type: ValidateTypeSql, Message: `found ">", expected EOF.`, Line: 2, Column: 8}},
+		},
+		{
+			name: "stream not found",
+			rule: &def.Rule{
+				Sql:     "SELECT a FROM validateNotExist",
+				Actions: []map[string]any{{"log": map[string]any{}}},
+				Options: defaultOption,
+			},
+			errs: []*ValidateError{{Type: ValidateTypePlan, Message: "fail to get stream validateNotExist, please check if stream is created"}},
+		},
+		{
+			name: "sink errors",
+			rule: &def.Rule{
+				Sql: "SELECT a FROM validate1",
+				Actions: []map[string]any{
+					{"memory": map[string]any{"topic": "vout/#"}},
+					{"notExist": map[string]any{}},
+				},
+				Options: defaultOption,
+			},
+			errs: []*ValidateError{
+				{Type: ValidateTypeSink, Name: "memory_0", Message: "invalid memory topic vout/#: wildcard found"},
+				{Type: ValidateTypeSink, Name: "notExist_1", Message: "sink notExist is not defined"},
+			},
+		},
+		{
+			name: "op error",
+			rule: &def.Rule{
+				Sql:     "SELECT count(*) FROM validate1 GROUP BY TumblingWindow(ss, 10)",
+				Actions: []map[string]any{{"log": map[string]any{}}},
+				Options: &alignOption,
+			},
+			errs: []*ValidateError{{Type: ValidateTypePlan, Message: "invalid windowAlign bad, must be natural or epoch"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Id = "validate_" + tt.name
+			errs := ValidateRule(tt.rule, false)
+			assert.Equal(t, tt.errs, errs)
+		})
+	}
+}
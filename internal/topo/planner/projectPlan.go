@@ -36,6 +36,8 @@ type ProjectPlan struct {
 	exprFields       ast.Fields
 	enableLimit      bool
 	limitCount       int
+	distinct         bool
+	distinctCapacity int
 }
 
 func (p ProjectPlan) Init() *ProjectPlan {
@@ -88,6 +90,9 @@ func (p *ProjectPlan) BuildExplainInfo() {
 	if p.enableLimit {
 		info += ", Limit:" + strconv.Itoa(p.limitCount)
 	}
+	if p.distinct {
+		info += ", Distinct:true"
+	}
 	p.baseLogicalPlan.ExplainInfo.Info = info
 }
 
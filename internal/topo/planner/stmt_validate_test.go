@@ -68,6 +68,18 @@ func TestStmtValidate(t *testing.T) {
 		{
 			sql: "select a[2:1] from src1",
 		},
+		{
+			sql: "select CASE WHEN a > 1 THEN 'high' ELSE 0 END from src1",
+		},
+		{
+			sql: "select CASE a WHEN 'x' THEN 1 WHEN true THEN 2 END from src1",
+		},
+		{
+			sql: "select CASE 1 WHEN 'x' THEN 1 END from src1",
+		},
+		{
+			sql: "select CASE WHEN a > 1 THEN (CASE WHEN b > 1 THEN 1 ELSE true END) END from src1",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.sql, func(t *testing.T) {
@@ -76,4 +88,14 @@ func TestStmtValidate(t *testing.T) {
 			require.Error(t, validateStmt(stmt))
 		})
 	}
+	for _, sql := range []string{
+		"select CASE WHEN a > 1 THEN 1 WHEN a > 0 THEN 0.5 ELSE b END from src1",
+		"select CASE a WHEN 1 THEN 'one' WHEN 2.0 THEN 'two' ELSE c END from src1",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			stmt, err := xsql.GetStatementFromSql(sql)
+			require.NoError(t, err)
+			require.NoError(t, validateStmt(stmt))
+		})
+	}
 }
@@ -0,0 +1,84 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/pkg/ast"
+)
+
+type WindowFillPlan struct {
+	baseLogicalPlan
+	fill              string
+	keys              []string
+	keyValues         []map[string]any
+	windowStartFields []string
+	windowEndFields   []string
+}
+
+func newWindowFillPlan(stmt *ast.SelectStatement, wf *def.WindowFill) (*WindowFillPlan, error) {
+	switch wf.Fill {
+	case def.WindowFillNull, def.WindowFillZero, def.WindowFillLocf:
+	default:
+		return nil, fmt.Errorf("invalid windowFill fill %s, must be %s, %s or %s", wf.Fill, def.WindowFillNull, def.WindowFillZero, def.WindowFillLocf)
+	}
+	var w *ast.Window
+	if stmt.Dimensions != nil {
+		w = stmt.Dimensions.GetWindow()
+	}
+	if w == nil || (w.WindowType != ast.TUMBLING_WINDOW && w.WindowType != ast.HOPPING_WINDOW) {
+		return nil, fmt.Errorf("windowFill only supports tumbling and hopping window")
+	}
+	if len(wf.KeyValues) > 0 && len(wf.Keys) == 0 {
+		return nil, fmt.Errorf("windowFill keys are required when keyValues are set")
+	}
+	p := WindowFillPlan{
+		fill:      wf.Fill,
+		keys:      wf.Keys,
+		keyValues: wf.KeyValues,
+	}
+	for _, field := range stmt.Fields {
+		expr := field.Expr
+		if ref, ok := expr.(*ast.FieldRef); ok && ref.AliasRef != nil {
+			expr = ref.AliasRef.Expression
+		}
+		if c, ok := expr.(*ast.Call); ok {
+			switch strings.ToLower(c.Name) {
+			case "window_start":
+				p.windowStartFields = append(p.windowStartFields, field.GetName())
+			case "window_end":
+				p.windowEndFields = append(p.windowEndFields, field.GetName())
+			}
+		}
+	}
+	return p.Init(), nil
+}
+
+func (p WindowFillPlan) Init() *WindowFillPlan {
+	p.baseLogicalPlan.self = &p
+	p.baseLogicalPlan.setPlanType(WINDOWFILL)
+	return &p
+}
+
+func (p *WindowFillPlan) BuildExplainInfo() {
+	info := "fill:" + p.fill
+	if len(p.keys) > 0 {
+		info += ", keys:[ " + strings.Join(p.keys, ", ") + " ]"
+	}
+	p.baseLogicalPlan.ExplainInfo.Info = info
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lf-edge/ekuiper/v2/internal/pkg/def"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
+)
+
+func TestNewWindowFillPlan(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		fill *def.WindowFill
+		err  string
+	}{
+		{
+			name: "invalid fill",
+			sql:  "SELECT id, avg(temp) FROM src1 GROUP BY id, TUMBLINGWINDOW(ss, 10)",
+			fill: &def.WindowFill{Fill: "last"},
+			err:  "invalid windowFill fill last, must be null, zero or locf",
+		},
+		{
+			name: "no window",
+			sql:  "SELECT id FROM src1",
+			fill: &def.WindowFill{Fill: "null"},
+			err:  "windowFill only supports tumbling and hopping window",
+		},
+		{
+			name: "sliding window",
+			sql:  "SELECT id, avg(temp) FROM src1 GROUP BY id, SLIDINGWINDOW(ss, 10)",
+			fill: &def.WindowFill{Fill: "null"},
+			err:  "windowFill only supports tumbling and hopping window",
+		},
+		{
+			name: "key values without keys",
+			sql:  "SELECT id, avg(temp) FROM src1 GROUP BY id, TUMBLINGWINDOW(ss, 10)",
+			fill: &def.WindowFill{Fill: "zero", KeyValues: []map[string]any{{"id": 1}}},
+			err:  "windowFill keys are required when keyValues are set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := xsql.NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			_, err = newWindowFillPlan(stmt, tt.fill)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+
+	stmt, err := xsql.NewParser(strings.NewReader("SELECT id, avg(temp), window_start() AS ws, window_end() FROM src1 GROUP BY id, HOPPINGWINDOW(ss, 10, 5)")).Parse()
+	require.NoError(t, err)
+	p, err := newWindowFillPlan(stmt, &def.WindowFill{Fill: "locf", Keys: []string{"id"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ws"}, p.windowStartFields)
+	assert.Equal(t, []string{"window_end"}, p.windowEndFields)
+	assert.Equal(t, []string{"id"}, p.keys)
+}
@@ -32,6 +32,8 @@ type WindowPlan struct {
 	timeUnit         ast.Token
 	limit            int // If limit is not positive, there will be no limit
 	isEventTime      bool
+	// dimensions are the group keys to track the sessions of the session window without max duration
+	dimensions ast.Dimensions
 
 	stateFuncs []*ast.Call
 }
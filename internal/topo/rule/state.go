@@ -34,6 +34,7 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/pkg/schedule"
 	"github.com/lf-edge/ekuiper/v2/internal/topo"
 	kctx "github.com/lf-edge/ekuiper/v2/internal/topo/context"
+	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/planner"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/errorx"
@@ -375,6 +376,15 @@ func (s *State) triggerAction(action ActionSignal) bool {
 // Stop run stop action or add the stop action to queue
 // regSchedule: whether need to handle scheduler. If call externally, set it to true
 func (s *State) Stop() {
+	s.stop(false)
+}
+
+// GracefulStop drains the rule before stopping even if the gracefulStop option is not set
+func (s *State) GracefulStop() {
+	s.stop(true)
+}
+
+func (s *State) stop(graceful bool) {
 	defer s.nextAction()
 	s.logger.Debug("stop RunState")
 	done := s.triggerAction(ActionSignalStop)
@@ -383,7 +393,7 @@ func (s *State) Stop() {
 	}
 	// do stop, stopping action and starting action are mutual exclusive. No concurrent problem here
 	s.logger.Infof("stopping rule %s", s.Rule.Id)
-	err := s.doStop()
+	err := s.doStop(graceful || s.Rule.Options.GracefulStop)
 	if err == nil {
 		err = errors.New("canceled manually")
 	}
@@ -401,7 +411,7 @@ func (s *State) ScheduleStop() {
 	}
 	// do stop, stopping action and starting action are mutual exclusive. No concurrent problem here
 	s.logger.Infof("schedule to stop rule %s", s.Rule.Id)
-	err := s.doStop()
+	err := s.doStop(s.Rule.Options.GracefulStop)
 	// currentState may be accessed concurrently
 	if schedule.IsAfterTimeRanges(timex.GetNow(), s.Rule.Options.CronDatetimeRange) {
 		s.transit(ScheduledStop, errors.New("schedule terminated"))
@@ -458,17 +468,22 @@ func (s *State) doStart() error {
 	return err
 }
 
-func (s *State) doStop() error {
+// doStop cancels the topo. If graceful is true, drain the topo before cancelling so that the in-flight data are not lost.
+func (s *State) doStop(graceful bool) error {
 	if s.cancelRetry != nil {
 		s.cancelRetry()
 	}
-	if s.topology != nil {
-		e := s.topology.GetContext().Err()
-		s.topoGraph = s.topology.GetTopo()
-		keys, values := s.topology.GetMetrics()
+	// The topo may be reset by the runTopo when draining, so keep a reference here
+	if tp := s.topology; tp != nil {
+		e := tp.GetContext().Err()
+		if graceful && tp.HasOpen() && !tp.Drain() {
+			s.logger.Warnf("rule %s is not drained completely, stop it anyway", s.Rule.Id)
+		}
+		s.topoGraph = tp.GetTopo()
+		keys, values := tp.GetMetrics()
 		s.stoppedMetrics = []any{keys, values}
-		s.topology.Cancel()
-		s.topology.WaitClose()
+		tp.Cancel()
+		tp.WaitClose()
 		s.topology = nil
 		return e
 	}
@@ -492,7 +507,8 @@ func (s *State) runTopo(ctx context.Context, tp *topo.Topo, rs *def.RestartStrat
 					s.logger.Errorf("closing Rule for error: %v", er)
 					tp.Cancel()
 				} else { // exit normally
-					if errorx.IsEOF(er) {
+					// The EOF sent by the drain means the rule is stopping instead of done
+					if errorx.IsEOF(er) && !tp.WaitDrain() {
 						s.lastWill = "done"
 					}
 					tp.Cancel()
@@ -604,6 +620,15 @@ func (s *State) GetMetrics() ([]string, []any) {
 	return nil, nil
 }
 
+func (s *State) GetBackpressure() (map[string]*node.BackpressureState, error) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.topology != nil {
+		return s.topology.GetBackpressure(), nil
+	}
+	return nil, fmt.Errorf("topo is not initialized, check rule status")
+}
+
 func (s *State) GetStreams() []string {
 	s.RLock()
 	defer s.RUnlock()
@@ -152,6 +152,13 @@ func (s *SrcSubTopo) SubMetrics() (keys []string, values []any) {
 			keys = append(keys, fmt.Sprintf("op_%s_%s_0_%s", s.name, so.GetName(), metric.MetricNames[i]))
 			values = append(values, v)
 		}
+		if en, ok := so.(node.ExtraMetricNode); ok {
+			ekeys, evalues := en.GetExtraMetrics()
+			for i, key := range ekeys {
+				keys = append(keys, fmt.Sprintf("op_%s_%s_0_%s", s.name, so.GetName(), key))
+				values = append(values, evalues[i])
+			}
+		}
 	}
 	return
 }
@@ -35,12 +35,15 @@ import (
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/node/metric"
 	"github.com/lf-edge/ekuiper/v2/internal/topo/state"
+	"github.com/lf-edge/ekuiper/v2/internal/xsql"
 	"github.com/lf-edge/ekuiper/v2/pkg/infra"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
 
 var uid atomic.Uint32
 
+const defaultDrainTimeout = 10 * time.Second
+
 // Topo is the runtime DAG for a rule
 // It only run once. If the rule restarts, another topo is created.
 type Topo struct {
@@ -59,6 +62,11 @@ type Topo struct {
 	topo        *def.PrintableTopo
 	mu          sync.Mutex
 	hasOpened   atomic.Bool
+	// the input channels of the operators linked to the shared sources, which receive the EOF when draining
+	sharedInputs []chan any
+	// closed when the drain finishes, nil if not draining
+	drainDone  chan struct{}
+	stateSaved atomic.Bool
 
 	opsWg *sync.WaitGroup
 }
@@ -109,7 +117,7 @@ func (s *Topo) GetName() string {
 // Cancel may be called multiple times so must be idempotent
 func (s *Topo) Cancel() error {
 	s.hasOpened.Store(false)
-	if s.coordinator.IsActivated() && s.options.EnableSaveStateBeforeStop {
+	if s.coordinator.IsActivated() && s.options.EnableSaveStateBeforeStop && !s.stateSaved.Load() {
 		notify, err := s.coordinator.ForceSaveState()
 		if err != nil {
 			conf.Log.Infof("rule %v duplicated cancel", s.name)
@@ -134,6 +142,90 @@ func (s *Topo) Cancel() error {
 	return nil
 }
 
+// Drain stops the sources from ingesting new data and waits until all sinks receive the EOF, so that the buffered data
+// and the pending windows are flushed before the rule is cancelled. The state is saved after draining if checkpoint is
+// enabled so that the restarted rule continues from the drained position. It returns false if it does not finish in
+// the drainTimeout. The caller must call Cancel afterward.
+func (s *Topo) Drain() bool {
+	s.mu.Lock()
+	if s.drainDone != nil {
+		s.mu.Unlock()
+		return false
+	}
+	done := make(chan struct{})
+	s.drainDone = done
+	s.mu.Unlock()
+	defer close(done)
+
+	timeout := time.Duration(s.options.DrainTimeout)
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+	log := s.ctx.GetLogger()
+	log.Infof("draining rule %s with timeout %v", s.name, timeout)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for _, src := range s.sources {
+		switch rt := src.(type) {
+		case node.MergeableTopo:
+			// The shared source keeps running for other rules, so only detach it from this rule
+			rt.Close(s.ctx, s.name, s.runId)
+		case node.DrainableNode:
+			rt.Drain(s.ctx)
+		}
+	}
+	for _, ch := range s.sharedInputs {
+		select {
+		case ch <- xsql.EOFDrain:
+		case <-timer.C:
+			log.Warnf("rule %s drain timeout when sending EOF", s.name)
+			return false
+		case <-s.ctx.Done():
+			return false
+		}
+	}
+	for _, snk := range s.sinks {
+		if dn, ok := snk.(node.DrainedNode); ok {
+			select {
+			case <-dn.Drained():
+			case <-timer.C:
+				log.Warnf("rule %s drain timeout when waiting sink %s", s.name, snk.GetName())
+				return false
+			case <-s.ctx.Done():
+				return false
+			}
+		}
+	}
+	if s.coordinator.IsActivated() {
+		notify, err := s.coordinator.ForceSaveState()
+		if err != nil {
+			log.Warnf("rule %s save state after drain error: %v", s.name, err)
+			return false
+		}
+		select {
+		case <-notify:
+			s.stateSaved.Store(true)
+		case <-timer.C:
+			log.Warnf("rule %s drain timeout when saving state", s.name)
+			return false
+		}
+	}
+	log.Infof("rule %s drained", s.name)
+	return true
+}
+
+// WaitDrain blocks until the ongoing drain finishes. It returns false if the topo is not draining.
+func (s *Topo) WaitDrain() bool {
+	s.mu.Lock()
+	done := s.drainDone
+	s.mu.Unlock()
+	if done == nil {
+		return false
+	}
+	<-done
+	return true
+}
+
 func (s *Topo) AddSrc(src node.DataSourceNode) *Topo {
 	s.sources = append(s.sources, src)
 	switch rt := src.(type) {
@@ -177,6 +269,7 @@ func (s *Topo) AddOperator(inputs []node.Emitter, operator node.OperatorNode) *T
 		switch rt := input.(type) {
 		case node.MergeableTopo:
 			rt.LinkTopo(s.topo, operator.GetName())
+			s.sharedInputs = append(s.sharedInputs, ch)
 		case node.TopNode:
 			s.addEdge(rt, operator, "op")
 		}
@@ -365,6 +458,12 @@ func (s *Topo) GetMetricsV2() map[string]map[string]any {
 			value := v
 			operatorMetrics[key] = value
 		}
+		if en, ok := so.(node.ExtraMetricNode); ok {
+			ekeys, evalues := en.GetExtraMetrics()
+			for i, key := range ekeys {
+				operatorMetrics["op_"+so.GetName()+"_0_"+key] = evalues[i]
+			}
+		}
 		allMetrics[so.GetName()] = operatorMetrics
 	}
 	for _, sn := range s.sinks {
@@ -398,6 +497,13 @@ func (s *Topo) GetMetrics() (keys []string, values []any) {
 			keys = append(keys, "op_"+so.GetName()+"_0_"+metric.MetricNames[i])
 			values = append(values, v)
 		}
+		if en, ok := so.(node.ExtraMetricNode); ok {
+			ekeys, evalues := en.GetExtraMetrics()
+			for i, key := range ekeys {
+				keys = append(keys, "op_"+so.GetName()+"_0_"+key)
+				values = append(values, evalues[i])
+			}
+		}
 	}
 	for _, sn := range s.sinks {
 		for i, v := range sn.GetMetrics() {
@@ -408,6 +514,27 @@ func (s *Topo) GetMetrics() (keys []string, values []any) {
 	return
 }
 
+// GetBackpressure samples the backpressure state of all nodes. The keys are the same as the node names of the topo graph
+func (s *Topo) GetBackpressure() map[string]*node.BackpressureState {
+	result := make(map[string]*node.BackpressureState, len(s.sources)+len(s.ops)+len(s.sinks))
+	for _, sn := range s.sources {
+		if bn, ok := sn.(node.BackpressureNode); ok {
+			result["source_"+sn.GetName()] = bn.GetBackpressure()
+		}
+	}
+	for _, so := range s.ops {
+		if bn, ok := so.(node.BackpressureNode); ok {
+			result["op_"+so.GetName()] = bn.GetBackpressure()
+		}
+	}
+	for _, sn := range s.sinks {
+		if bn, ok := sn.(node.BackpressureNode); ok {
+			result["sink_"+sn.GetName()] = bn.GetBackpressure()
+		}
+	}
+	return result
+}
+
 func (s *Topo) RemoveMetrics() {
 	conf.Log.Infof("start removing %v metrics", s.name)
 	for _, sn := range s.sources {
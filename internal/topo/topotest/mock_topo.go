@@ -121,7 +121,7 @@ func DoRuleTestWithResultFunc(t *testing.T, tests []RuleTest, opt *def.RuleOptio
 			go sendData(dataLength, datas, tp, POSTLEAP, wait)
 			// Receive data
 			limit := len(tt.R)
-			consumer := pubsub.CreateSub(id, nil, id, limit)
+			consumer := pubsub.CreateSub(id, id, limit)
 			conf.Log.Debugf("test create memory sub %s", id)
 			ticker := time.After(10 * time.Second)
 			sinkResult := make([]any, 0, limit)
@@ -499,7 +499,7 @@ func DoCheckpointRuleTest(t *testing.T, tests []RuleCheckpointTest, opt *def.Rul
 			go sendData(dataLength-tt.PauseSize, [][]*xsql.Tuple{datas[0][tt.PauseSize:]}, tp, POSTLEAP, 10)
 			// Receive data
 			limit := len(tt.R)
-			consumer := pubsub.CreateSub(id, nil, id, limit)
+			consumer := pubsub.CreateSub(id, id, limit)
 			conf.Log.Debugf("test create memory sub %s", id)
 			ticker := time.After(1000 * time.Second)
 			sinkResult := make([]any, 0, limit)
@@ -1140,6 +1140,17 @@ func TestSingleSQL(t *testing.T) {
 				"source_demo_0_records_out_total": int64(5),
 			},
 		},
+		{
+			Name: `TestHavingGroupKey`,
+			Sql:  "SELECT color FROM demo where color != \"red\" GROUP BY COUNTWINDOW(5), color HAVING count(*) > 1 AND color != \"yellow\"",
+			R: [][]map[string]interface{}{
+				{
+					{
+						"color": "blue",
+					},
+				},
+			},
+		},
 		{
 			Name: `TestSingleSQLRule17`,
 			Sql:  `SELECT arr[x:4] as col1 FROM demoArr where x=1`,
@@ -700,6 +700,36 @@ func TestWindow(t *testing.T) {
 			},
 			M: map[string]interface{}{},
 		},
+		{
+			Name: `TestWindowRuleArrayAgg`,
+			Sql:  `SELECT array_agg(color) as colors, collect(DISTINCT size > 2) as big, array_agg(CASE WHEN size > 2 THEN size END, true) as bigSize FROM demo GROUP BY TUMBLINGWINDOW(ss, 1)`,
+			R: [][]map[string]interface{}{
+				{{
+					"colors":  []interface{}{"red", "blue"},
+					"big":     []interface{}{true},
+					"bigSize": []interface{}{3, 6},
+				}},
+				{{
+					"colors":  []interface{}{"blue"},
+					"big":     []interface{}{false},
+					"bigSize": []interface{}{},
+				}},
+				{{
+					"colors":  []interface{}{"yellow"},
+					"big":     []interface{}{true},
+					"bigSize": []interface{}{4},
+				}},
+			},
+			M: map[string]interface{}{
+				"sink_memory_0_0_exceptions_total":  int64(0),
+				"sink_memory_0_0_records_in_total":  int64(3),
+				"sink_memory_0_0_records_out_total": int64(3),
+
+				"source_demo_0_exceptions_total":  int64(0),
+				"source_demo_0_records_in_total":  int64(5),
+				"source_demo_0_records_out_total": int64(5),
+			},
+		},
 	}
 	HandleStream(true, streamList, t)
 	options := []*def.RuleOption{
@@ -0,0 +1,218 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The name cases of the field names
+const (
+	NameCaseNone  = "none"
+	NameCaseSnake = "snake"
+	NameCaseCamel = "camel"
+	NameCaseUpper = "upper"
+	NameCaseLower = "lower"
+)
+
+// FieldNaming renames the fields of each result row. The fields in the mapping are renamed to the mapped names as is.
+// The other fields and the keys of the nested maps are converted to the name case.
+type FieldNaming struct {
+	Mapping map[string]string
+	Case    string
+}
+
+// Validate checks the mapping and the case, and sets the default case
+func (n *FieldNaming) Validate() error {
+	switch n.Case {
+	case "":
+		n.Case = NameCaseNone
+	case NameCaseNone, NameCaseSnake, NameCaseCamel, NameCaseUpper, NameCaseLower:
+	default:
+		return fmt.Errorf("invalid nameCase %s, must be one of none, snake, camel, upper and lower", n.Case)
+	}
+	targets := make(map[string]string, len(n.Mapping))
+	for from, to := range n.Mapping {
+		if from == "" || to == "" {
+			return errors.New("fieldMapping field name must not be empty")
+		}
+		if old, ok := targets[to]; ok {
+			return fmt.Errorf("fields %s are mapped to the same name %s", sortedPair(old, from), to)
+		}
+		targets[to] = from
+	}
+	return nil
+}
+
+// IsEmpty returns true if no field is renamed
+func (n *FieldNaming) IsEmpty() bool {
+	return len(n.Mapping) == 0 && (n.Case == "" || n.Case == NameCaseNone)
+}
+
+// Apply returns the renamed copy of the row. It returns an error if two fields are renamed to the same name, because
+// one of the values would be lost silently.
+func (n *FieldNaming) Apply(m map[string]any) (map[string]any, error) {
+	result := make(map[string]any, len(m))
+	sources := make(map[string]string, len(m))
+	for k, v := range m {
+		name, ok := n.Mapping[k]
+		if !ok {
+			name = n.convert(k)
+		}
+		if old, ok := sources[name]; ok {
+			return nil, fmt.Errorf("fields %s are both renamed to %s", sortedPair(old, k), name)
+		}
+		sources[name] = k
+		nv, err := n.convertValue(v)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = nv
+	}
+	return result, nil
+}
+
+// convertValue converts the keys of the nested maps to the name case
+func (n *FieldNaming) convertValue(v any) (any, error) {
+	if n.Case == "" || n.Case == NameCaseNone {
+		return v, nil
+	}
+	switch vt := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(vt))
+		sources := make(map[string]string, len(vt))
+		for k, nv := range vt {
+			name := n.convert(k)
+			if old, ok := sources[name]; ok {
+				return nil, fmt.Errorf("nested fields %s are both renamed to %s", sortedPair(old, k), name)
+			}
+			sources[name] = k
+			cv, err := n.convertValue(nv)
+			if err != nil {
+				return nil, err
+			}
+			result[name] = cv
+		}
+		return result, nil
+	case []map[string]any:
+		result := make([]map[string]any, len(vt))
+		for i, e := range vt {
+			cv, err := n.convertValue(e)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = cv.(map[string]any)
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(vt))
+		for i, e := range vt {
+			cv, err := n.convertValue(e)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = cv
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+func (n *FieldNaming) convert(name string) string {
+	switch n.Case {
+	case NameCaseSnake:
+		return toSnakeCase(name)
+	case NameCaseCamel:
+		return toCamelCase(name)
+	case NameCaseUpper:
+		return strings.ToUpper(name)
+	case NameCaseLower:
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// toSnakeCase converts such as userId, UserID and user-id to user_id. The leading underscores such as _id are kept.
+func toSnakeCase(name string) string {
+	prefix, words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return prefix + strings.Join(words, "_")
+}
+
+// toCamelCase converts such as user_id, UserID and user-id to userId. The leading underscores such as _id are kept.
+func toCamelCase(name string) string {
+	prefix, words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if i > 0 {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			w = string(r)
+		}
+		sb.WriteString(w)
+	}
+	return sb.String()
+}
+
+// splitWords splits the name by the separators _, - and space, and by the case boundaries. An upper case letter
+// starts a new word after a lower case letter or a digit, or before a lower case letter in an acronym such as the
+// S in HTTPServer. It returns the leading underscores and the words.
+func splitWords(name string) (string, []string) {
+	rest := strings.TrimLeft(name, "_")
+	prefix := name[:len(name)-len(rest)]
+	runes := []rune(rest)
+	var (
+		words []string
+		start = -1
+	)
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return prefix, words
+}
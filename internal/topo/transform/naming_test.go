@@ -0,0 +1,165 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameCase(t *testing.T) {
+	tests := []struct {
+		name  string
+		snake string
+		camel string
+	}{
+		{name: "userId", snake: "user_id", camel: "userId"},
+		{name: "UserID", snake: "user_id", camel: "userId"},
+		{name: "user-id", snake: "user_id", camel: "userId"},
+		{name: "user id", snake: "user_id", camel: "userId"},
+		{name: "user_id", snake: "user_id", camel: "userId"},
+		{name: "HTTPServer", snake: "http_server", camel: "httpServer"},
+		{name: "sensor1Value", snake: "sensor1_value", camel: "sensor1Value"},
+		{name: "_id", snake: "_id", camel: "_id"},
+		{name: "__deviceName", snake: "__device_name", camel: "__deviceName"},
+		{name: "temp", snake: "temp", camel: "temp"},
+		{name: "___", snake: "___", camel: "___"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.snake, toSnakeCase(tt.name))
+			assert.Equal(t, tt.camel, toCamelCase(tt.name))
+		})
+	}
+}
+
+func TestFieldNamingApply(t *testing.T) {
+	input := map[string]any{
+		"deviceId": 1,
+		"tempC":    23.5,
+		"loc":      map[string]any{"geoLat": 1.1, "geoLng": 2.2},
+		"readings": []any{map[string]any{"rawValue": 1}, 2},
+	}
+	tests := []struct {
+		name   string
+		naming *FieldNaming
+		want   map[string]any
+	}{
+		{
+			name:   "mapping only",
+			naming: &FieldNaming{Mapping: map[string]string{"tempC": "temperature", "notExist": "x"}},
+			want: map[string]any{
+				"deviceId":    1,
+				"temperature": 23.5,
+				"loc":         map[string]any{"geoLat": 1.1, "geoLng": 2.2},
+				"readings":    []any{map[string]any{"rawValue": 1}, 2},
+			},
+		},
+		{
+			name:   "snake case with nested",
+			naming: &FieldNaming{Case: NameCaseSnake},
+			want: map[string]any{
+				"device_id": 1,
+				"temp_c":    23.5,
+				"loc":       map[string]any{"geo_lat": 1.1, "geo_lng": 2.2},
+				"readings":  []any{map[string]any{"raw_value": 1}, 2},
+			},
+		},
+		{
+			name:   "mapping is not converted",
+			naming: &FieldNaming{Mapping: map[string]string{"tempC": "Temperature"}, Case: NameCaseUpper},
+			want: map[string]any{
+				"DEVICEID":    1,
+				"Temperature": 23.5,
+				"LOC":         map[string]any{"GEOLAT": 1.1, "GEOLNG": 2.2},
+				"READINGS":    []any{map[string]any{"RAWVALUE": 1}, 2},
+			},
+		},
+		{
+			name:   "lower case",
+			naming: &FieldNaming{Case: NameCaseLower},
+			want: map[string]any{
+				"deviceid": 1,
+				"tempc":    23.5,
+				"loc":      map[string]any{"geolat": 1.1, "geolng": 2.2},
+				"readings": []any{map[string]any{"rawvalue": 1}, 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.naming.Validate())
+			result, err := tt.naming.Apply(input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+	// the input is never modified
+	assert.Equal(t, map[string]any{"geoLat": 1.1, "geoLng": 2.2}, input["loc"])
+
+	n := &FieldNaming{Case: NameCaseCamel}
+	rows, err := n.Apply(map[string]any{"data": []map[string]any{{"user_name": "a"}}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"data": []map[string]any{{"userName": "a"}}}, rows)
+}
+
+func TestFieldNamingCollision(t *testing.T) {
+	_, err := (&FieldNaming{Case: NameCaseSnake}).Apply(map[string]any{"userId": 1, "user_id": 2})
+	assert.EqualError(t, err, "fields userId and user_id are both renamed to user_id")
+	_, err = (&FieldNaming{Mapping: map[string]string{"a": "b"}}).Apply(map[string]any{"a": 1, "b": 2})
+	assert.EqualError(t, err, "fields a and b are both renamed to b")
+	_, err = (&FieldNaming{Case: NameCaseLower}).Apply(map[string]any{"data": map[string]any{"ID": 1, "id": 2}})
+	assert.EqualError(t, err, "nested fields ID and id are both renamed to id")
+}
+
+func TestFieldNamingValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		naming *FieldNaming
+		err    string
+	}{
+		{
+			name:   "invalid case",
+			naming: &FieldNaming{Case: "kebab"},
+			err:    "invalid nameCase kebab, must be one of none, snake, camel, upper and lower",
+		},
+		{
+			name:   "empty source",
+			naming: &FieldNaming{Mapping: map[string]string{"": "a"}},
+			err:    "fieldMapping field name must not be empty",
+		},
+		{
+			name:   "empty target",
+			naming: &FieldNaming{Mapping: map[string]string{"a": ""}},
+			err:    "fieldMapping field name must not be empty",
+		},
+		{
+			name:   "map to the same name",
+			naming: &FieldNaming{Mapping: map[string]string{"a": "c", "b": "c"}},
+			err:    "fields a and b are mapped to the same name c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.naming.Validate(), tt.err)
+		})
+	}
+	n := &FieldNaming{}
+	require.NoError(t, n.Validate())
+	assert.Equal(t, NameCaseNone, n.Case)
+	assert.True(t, n.IsEmpty())
+}
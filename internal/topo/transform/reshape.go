@@ -0,0 +1,145 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FlattenAll flattens all the nested map fields
+const FlattenAll = "*"
+
+// Reshape declares how to restructure each result row without another SQL statement. The steps are applied in the
+// order of flatten, rename, drop and set.
+type Reshape struct {
+	// Flatten lifts the keys of the nested map fields one level up as <field><separator><key>
+	Flatten []string `json:"flatten,omitempty"`
+	// FlattenSeparator joins the names of the nested field and its keys, default to _
+	FlattenSeparator string `json:"flattenSeparator,omitempty"`
+	// Rename maps the old field names to the new names
+	Rename map[string]string `json:"rename,omitempty"`
+	// Drop removes the fields
+	Drop []string `json:"drop,omitempty"`
+	// Set adds the constant fields, the existing fields are overwritten
+	Set map[string]any `json:"set,omitempty"`
+}
+
+// Validate checks the conflicts of the steps and sets the default separator
+func (r *Reshape) Validate() error {
+	if len(r.Flatten) == 0 && len(r.Rename) == 0 && len(r.Drop) == 0 && len(r.Set) == 0 {
+		return errors.New("at least one of flatten, rename, drop and set is required")
+	}
+	if r.FlattenSeparator == "" {
+		r.FlattenSeparator = "_"
+	}
+	flattened := make(map[string]struct{}, len(r.Flatten))
+	for _, f := range r.Flatten {
+		if f == "" {
+			return errors.New("flatten field name must not be empty")
+		}
+		if _, ok := flattened[f]; ok {
+			return fmt.Errorf("field %s is flattened more than once", f)
+		}
+		flattened[f] = struct{}{}
+	}
+	if _, ok := flattened[FlattenAll]; ok && len(r.Flatten) > 1 {
+		return fmt.Errorf("flatten %s must not be used with other fields", FlattenAll)
+	}
+	dropped := make(map[string]struct{}, len(r.Drop))
+	for _, f := range r.Drop {
+		if f == "" {
+			return errors.New("drop field name must not be empty")
+		}
+		dropped[f] = struct{}{}
+	}
+	targets := make(map[string]string, len(r.Rename))
+	for from, to := range r.Rename {
+		if from == "" || to == "" {
+			return errors.New("rename field name must not be empty")
+		}
+		if old, ok := targets[to]; ok {
+			return fmt.Errorf("fields %s are renamed to the same name %s", sortedPair(old, from), to)
+		}
+		targets[to] = from
+		if _, ok := dropped[to]; ok {
+			return fmt.Errorf("field %s is renamed to %s which is dropped", from, to)
+		}
+	}
+	for k := range r.Set {
+		if k == "" {
+			return errors.New("set field name must not be empty")
+		}
+		if _, ok := dropped[k]; ok {
+			return fmt.Errorf("field %s is both set and dropped", k)
+		}
+	}
+	return nil
+}
+
+// Apply returns the reshaped copy of the row. The input row is not modified because it may be shared by other sinks.
+func (r *Reshape) Apply(m map[string]any) map[string]any {
+	result := make(map[string]any, len(m)+len(r.Set))
+	for k, v := range m {
+		result[k] = v
+	}
+	for _, f := range r.Flatten {
+		if f == FlattenAll {
+			for k, v := range m {
+				r.flatten(result, k, v)
+			}
+		} else if v, ok := m[f]; ok {
+			r.flatten(result, f, v)
+		}
+	}
+	if len(r.Rename) > 0 {
+		renamed := make(map[string]any, len(r.Rename))
+		for from, to := range r.Rename {
+			if v, ok := result[from]; ok {
+				renamed[to] = v
+				delete(result, from)
+			}
+		}
+		for k, v := range renamed {
+			result[k] = v
+		}
+	}
+	for _, f := range r.Drop {
+		delete(result, f)
+	}
+	for k, v := range r.Set {
+		result[k] = v
+	}
+	return result
+}
+
+// flatten replaces the nested map field with its keys. The other values are kept as is.
+func (r *Reshape) flatten(result map[string]any, name string, v any) {
+	nested, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	delete(result, name)
+	for k, nv := range nested {
+		result[name+r.FlattenSeparator+k] = nv
+	}
+}
+
+func sortedPair(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + " and " + b
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReshapeApply(t *testing.T) {
+	input := map[string]any{
+		"id":   1,
+		"temp": 23.5,
+		"loc":  map[string]any{"lat": 1.1, "lng": 2.2},
+		"meta": map[string]any{"src": "s1"},
+		"raw":  "abc",
+	}
+	tests := []struct {
+		name    string
+		reshape *Reshape
+		want    map[string]any
+	}{
+		{
+			name:    "rename",
+			reshape: &Reshape{Rename: map[string]string{"temp": "temperature", "notExist": "x"}},
+			want: map[string]any{
+				"id":          1,
+				"temperature": 23.5,
+				"loc":         map[string]any{"lat": 1.1, "lng": 2.2},
+				"meta":        map[string]any{"src": "s1"},
+				"raw":         "abc",
+			},
+		},
+		{
+			name:    "swap",
+			reshape: &Reshape{Rename: map[string]string{"id": "raw", "raw": "id"}},
+			want: map[string]any{
+				"id":   "abc",
+				"raw":  1,
+				"temp": 23.5,
+				"loc":  map[string]any{"lat": 1.1, "lng": 2.2},
+				"meta": map[string]any{"src": "s1"},
+			},
+		},
+		{
+			name:    "drop and set",
+			reshape: &Reshape{Drop: []string{"raw", "meta", "notExist"}, Set: map[string]any{"unit": "C", "id": 2}},
+			want: map[string]any{
+				"id":   2,
+				"temp": 23.5,
+				"loc":  map[string]any{"lat": 1.1, "lng": 2.2},
+				"unit": "C",
+			},
+		},
+		{
+			name:    "flatten one field",
+			reshape: &Reshape{Flatten: []string{"loc", "raw"}, FlattenSeparator: "."},
+			want: map[string]any{
+				"id":      1,
+				"temp":    23.5,
+				"loc.lat": 1.1,
+				"loc.lng": 2.2,
+				"meta":    map[string]any{"src": "s1"},
+				"raw":     "abc",
+			},
+		},
+		{
+			name: "flatten all with rename and drop",
+			reshape: &Reshape{
+				Flatten:          []string{FlattenAll},
+				FlattenSeparator: "_",
+				Rename:           map[string]string{"loc_lat": "lat", "loc_lng": "lng"},
+				Drop:             []string{"meta_src", "raw"},
+			},
+			want: map[string]any{
+				"id":   1,
+				"temp": 23.5,
+				"lat":  1.1,
+				"lng":  2.2,
+			},
+		},
+		{
+			name: "flatten, rename, drop and set",
+			reshape: &Reshape{
+				Flatten:          []string{"meta"},
+				FlattenSeparator: "_",
+				Rename:           map[string]string{"meta_src": "source"},
+				Drop:             []string{"loc"},
+				Set:              map[string]any{"version": 1},
+			},
+			want: map[string]any{
+				"id":      1,
+				"temp":    23.5,
+				"source":  "s1",
+				"raw":     "abc",
+				"version": 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.reshape.Validate())
+			assert.Equal(t, tt.want, tt.reshape.Apply(input))
+		})
+	}
+	// the input is never modified
+	assert.Len(t, input, 5)
+	assert.Equal(t, map[string]any{"lat": 1.1, "lng": 2.2}, input["loc"])
+}
+
+func TestReshapeValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		reshape *Reshape
+		err     string
+	}{
+		{
+			name:    "empty",
+			reshape: &Reshape{},
+			err:     "at least one of flatten, rename, drop and set is required",
+		},
+		{
+			name:    "empty flatten",
+			reshape: &Reshape{Flatten: []string{""}},
+			err:     "flatten field name must not be empty",
+		},
+		{
+			name:    "duplicate flatten",
+			reshape: &Reshape{Flatten: []string{"a", "a"}},
+			err:     "field a is flattened more than once",
+		},
+		{
+			name:    "flatten all with others",
+			reshape: &Reshape{Flatten: []string{"*", "a"}},
+			err:     "flatten * must not be used with other fields",
+		},
+		{
+			name:    "empty drop",
+			reshape: &Reshape{Drop: []string{""}},
+			err:     "drop field name must not be empty",
+		},
+		{
+			name:    "empty rename",
+			reshape: &Reshape{Rename: map[string]string{"a": ""}},
+			err:     "rename field name must not be empty",
+		},
+		{
+			name:    "rename to the same name",
+			reshape: &Reshape{Rename: map[string]string{"a": "c", "b": "c"}},
+			err:     "fields a and b are renamed to the same name c",
+		},
+		{
+			name:    "rename to dropped",
+			reshape: &Reshape{Rename: map[string]string{"a": "b"}, Drop: []string{"b"}},
+			err:     "field a is renamed to b which is dropped",
+		},
+		{
+			name:    "empty set",
+			reshape: &Reshape{Set: map[string]any{"": 1}},
+			err:     "set field name must not be empty",
+		},
+		{
+			name:    "set and drop",
+			reshape: &Reshape{Set: map[string]any{"a": 1}, Drop: []string{"a"}},
+			err:     "field a is both set and dropped",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.reshape.Validate(), tt.err)
+		})
+	}
+	r := &Reshape{Flatten: []string{"a"}}
+	require.NoError(t, r.Validate())
+	assert.Equal(t, "_", r.FlattenSeparator)
+}
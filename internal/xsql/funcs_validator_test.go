@@ -502,6 +502,26 @@ func TestFuncValidator(t *testing.T) {
 			stmt: nil,
 			err:  "validate function deduplicate error: Expect bool type for parameter 2",
 		},
+		{
+			s:    `SELECT unnest(arr, 1) from tbl`,
+			stmt: nil,
+			err:  "validate function unnest error: Expect bool type for parameter 2",
+		},
+		{
+			s:    `SELECT coalesce(a, "none", 0) from tbl`,
+			stmt: nil,
+			err:  "validate function coalesce error: invalid argument 0 of type bigint is not compatible with type string",
+		},
+		{
+			s:    `SELECT nullif(a) from tbl`,
+			stmt: nil,
+			err:  "validate function nullif error: Expect 2 arguments but found 1.",
+		},
+		{
+			s:    `SELECT nullif(1, true) from tbl`,
+			stmt: nil,
+			err:  "validate function nullif error: invalid argument true of type boolean is not compatible with type bigint",
+		},
 	}
 
 	for _, tt := range tests {
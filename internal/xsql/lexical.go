@@ -27,13 +27,21 @@ import (
 type Scanner struct {
 	r   *bufio.Reader
 	buf *bytes.Buffer
+	// line and col are the 1-based position of the next rune to read
+	line, col int
+	// tokLine and tokCol are the position of the last scanned token
+	tokLine, tokCol int
+	// the last read rune and the column before it to support unread
+	lastRune rune
+	lastCol  int
 }
 
 func NewScanner(r io.Reader) *Scanner {
-	return &Scanner{r: bufio.NewReader(r), buf: &bytes.Buffer{}}
+	return &Scanner{r: bufio.NewReader(r), buf: &bytes.Buffer{}, line: 1, col: 1}
 }
 
 func (s *Scanner) Scan() (tok ast.Token, lit string) {
+	s.tokLine, s.tokCol = s.line, s.col
 	ch := s.read()
 	if isWhiteSpace(ch) {
 		// s.unread()
@@ -227,6 +235,10 @@ func (s *Scanner) ScanIdent() (tok ast.Token, lit string) {
 		return ast.OVER, lit
 	case "PARTITION":
 		return ast.PARTITION, lit
+	case "DISTINCT":
+		return ast.DISTINCT, lit
+	case "EXISTS":
+		return ast.EXISTS, lit
 	case "REPLACE":
 		return ast.REPLACE, lit
 	case "EXCEPT":
@@ -409,11 +421,23 @@ func (s *Scanner) read() rune {
 	if err != nil {
 		return eof
 	}
+	s.lastRune, s.lastCol = ch, s.col
+	if ch == '\n' {
+		s.line++
+		s.col = 1
+	} else {
+		s.col++
+	}
 	return ch
 }
 
 func (s *Scanner) unread() {
-	_ = s.r.UnreadRune()
+	if err := s.r.UnreadRune(); err == nil {
+		if s.lastRune == '\n' {
+			s.line--
+		}
+		s.col = s.lastCol
+	}
 }
 
 var eof = rune(0)
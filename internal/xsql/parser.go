@@ -39,12 +39,16 @@ type Parser struct {
 	buf [3]struct {
 		tok ast.Token
 		lit string
+		// the position of the token
+		line, col int
 	}
 	inFunc      string // currently parsing function name
 	f           int    // anonymous field index number
 	fn          int    // function index number
 	clause      string
 	sourceNames []string // source names in the from/join clause
+	// the had_changed calls converted from the CHANGED window triggers, which are partitioned by the dimensions
+	changedTriggers []*ast.Call
 }
 
 func (p *Parser) ParseCondition() (ast.Expr, error) {
@@ -87,11 +91,18 @@ func (p *Parser) scan() (tok ast.Token, lit string) {
 		p.i = (p.i + 1) % len(p.buf)
 		buf := &p.buf[p.i]
 		buf.tok, buf.lit = tok, lit
+		buf.line, buf.col = p.s.tokLine, p.s.tokCol
 	}
 
 	return
 }
 
+// Pos returns the line and column of the current token, which is where the parser stops when it fails
+func (p *Parser) Pos() (int, int) {
+	i := (p.i - p.n + len(p.buf)) % len(p.buf)
+	return p.buf[i].line, p.buf[i].col
+}
+
 func (p *Parser) curr() (ast.Token, string) {
 	i := (p.i - p.n + len(p.buf)) % len(p.buf)
 	buf := &p.buf[i]
@@ -156,6 +167,11 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 		return nil, fmt.Errorf("Found %q, Expected SELECT.\n", lit)
 	}
 	p.clause = "select"
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.DISTINCT {
+		selects.Distinct = true
+	} else {
+		p.unscan()
+	}
 	if fields, err := p.parseFields(); err != nil {
 		return nil, err
 	} else {
@@ -167,6 +183,11 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 	} else {
 		selects.Sources = src
 	}
+	if unions, err := p.parseUnions(); err != nil {
+		return nil, err
+	} else {
+		selects.Unions = unions
+	}
 	p.clause = "join"
 	if joins, err := p.parseJoins(); err != nil {
 		return nil, err
@@ -182,7 +203,9 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 		return nil, err
 	} else {
 		if exp != nil {
-			selects.Condition = exp
+			if selects.Condition, err = extractExists(selects, exp); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -191,6 +214,7 @@ func (p *Parser) Parse() (*ast.SelectStatement, error) {
 		return nil, err
 	} else {
 		selects.Dimensions = dims
+		p.partitionChangedTriggers(dims)
 	}
 	p.clause = "having"
 	if having, err := p.parseHaving(); err != nil {
@@ -243,22 +267,51 @@ func (p *Parser) parseSource() (ast.Sources, error) {
 	return sources, nil
 }
 
+// parseUnions parses the streams merged into the source by UNION ALL
+func (p *Parser) parseUnions() (ast.Sources, error) {
+	var unions ast.Sources
+	for {
+		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, "UNION") {
+			p.unscan()
+			return unions, nil
+		}
+		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, "ALL") {
+			return nil, fmt.Errorf("found %q, expected ALL. Only UNION ALL is supported because the events of streams cannot be deduplicated.", lit)
+		}
+		src, alias, err := p.parseSourceLiteral()
+		if err != nil {
+			return nil, err
+		}
+		if src == "" {
+			return nil, fmt.Errorf("missing the stream name after UNION ALL.")
+		}
+		if alias != "" {
+			return nil, fmt.Errorf("alias %s is not supported for stream %s of UNION ALL.", alias, src)
+		}
+		unions = append(unions, &ast.Table{Name: src})
+	}
+}
+
 // TODO Current func has problems when the source includes white space.
 func (p *Parser) parseSourceLiteral() (string, string, error) {
 	var sourceSeg []string
 	var alias string
+	prev := ast.ILLEGAL
 	for {
 		// HASH, DIV & ADD token is specially support for MQTT topic name patterns.
-		if tok, lit := p.scanIgnoreWhitespace(); tok.AllowedSourceToken() {
+		if tok, lit := p.scanIgnoreWhitespace(); tok.AllowedSourceToken() && !isUnionKeyword(prev, tok, lit) {
 			sourceSeg = append(sourceSeg, lit)
+			prev = tok
 			if tok1, lit1 := p.scanIgnoreWhitespace(); tok1 == ast.AS {
 				if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 == ast.IDENT {
 					alias = lit2
+					prev = tok2
 				} else {
 					return "", "", fmt.Errorf("found %q, expected JOIN key word.", lit)
 				}
-			} else if tok1.AllowedSourceToken() {
+			} else if tok1.AllowedSourceToken() && !isUnionKeyword(prev, tok1, lit1) {
 				sourceSeg = append(sourceSeg, lit1)
+				prev = tok1
 			} else {
 				p.unscan()
 				break
@@ -271,6 +324,12 @@ func (p *Parser) parseSourceLiteral() (string, string, error) {
 	return strings.Join(sourceSeg, ""), alias, nil
 }
 
+// isUnionKeyword checks if the token ends the source by UNION. UNION is not a reserved keyword, so it is only
+// recognized after a stream name or alias to keep the MQTT topics like a/union valid.
+func isUnionKeyword(prev ast.Token, tok ast.Token, lit string) bool {
+	return prev == ast.IDENT && tok == ast.IDENT && strings.EqualFold(lit, "UNION")
+}
+
 func (p *Parser) parseFieldNameSections(isSubField bool) ([]string, error) {
 	var fieldNameSects []string
 	for {
@@ -663,6 +722,13 @@ func (p *Parser) parseUnaryExpr(isSubField bool) (ast.Expr, error) {
 		return p.parseBracketExpr()
 	} else if tok1 == ast.IN {
 		return p.parseValueSetExpr()
+	} else if tok1 == ast.EXISTS {
+		return p.parseExists(false)
+	} else if tok1 == ast.NOT {
+		if tok2, lit2 := p.scanIgnoreWhitespace(); tok2 != ast.EXISTS {
+			return nil, fmt.Errorf("found %q, expected EXISTS.", lit2)
+		}
+		return p.parseExists(true)
 	}
 
 	p.unscan()
@@ -739,6 +805,93 @@ func (p *Parser) parseUnaryExpr(isSubField bool) (ast.Expr, error) {
 	return nil, fmt.Errorf("found %q, expected expression.", lit)
 }
 
+// parseExists parses the [NOT] EXISTS (SELECT * FROM table WHERE condition) subquery. The select fields of the
+// subquery are ignored because the matched rows are never materialized.
+func (p *Parser) parseExists(not bool) (ast.Expr, error) {
+	if p.clause != "where" {
+		return nil, fmt.Errorf("EXISTS is only supported in the WHERE clause")
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+		return nil, fmt.Errorf("found %q, expected ( after EXISTS.", lit)
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.SELECT {
+		return nil, fmt.Errorf("found %q, expected SELECT in EXISTS subquery.", lit)
+	}
+	if _, err := p.parseFields(); err != nil {
+		return nil, err
+	}
+	src, err := p.parseSource()
+	if err != nil {
+		return nil, err
+	}
+	t := src[0].(*ast.Table)
+	if t.Name == "" {
+		return nil, fmt.Errorf("EXISTS subquery requires a lookup table")
+	}
+	// The fields of the table are referred in the subquery condition
+	p.sourceNames = append(p.sourceNames, t.Name)
+	if t.Alias != "" {
+		p.sourceNames = append(p.sourceNames, t.Alias)
+	}
+	cond, err := p.ParseCondition()
+	if err != nil {
+		return nil, err
+	}
+	if cond == nil {
+		return nil, fmt.Errorf("EXISTS subquery requires a WHERE condition to look up the table %s", t.Name)
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.RPAREN {
+		return nil, fmt.Errorf("found %q, expected right paren.", lit)
+	}
+	return &ast.ExistsExpr{Not: not, Table: t.Name, Alias: t.Alias, Expr: cond}, nil
+}
+
+// extractExists moves the EXISTS subqueries of the WHERE condition to the joins of the statement as semi or anti joins,
+// and returns the rest of the condition. Only the EXISTS subqueries combined by AND are supported.
+func extractExists(stmt *ast.SelectStatement, cond ast.Expr) (ast.Expr, error) {
+	switch e := cond.(type) {
+	case *ast.ExistsExpr:
+		jt := ast.SEMI_JOIN
+		if e.Not {
+			jt = ast.ANTI_JOIN
+		}
+		stmt.Joins = append(stmt.Joins, ast.Join{Name: e.Table, Alias: e.Alias, JoinType: jt, Expr: e.Expr})
+		return nil, nil
+	case *ast.ParenExpr:
+		if _, ok := e.Expr.(*ast.ExistsExpr); ok {
+			return extractExists(stmt, e.Expr)
+		}
+	case *ast.BinaryExpr:
+		if e.OP == ast.AND {
+			lhs, err := extractExists(stmt, e.LHS)
+			if err != nil {
+				return nil, err
+			}
+			rhs, err := extractExists(stmt, e.RHS)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case lhs == nil:
+				return rhs, nil
+			case rhs == nil:
+				return lhs, nil
+			default:
+				return &ast.BinaryExpr{OP: ast.AND, LHS: lhs, RHS: rhs}, nil
+			}
+		}
+	}
+	var err error
+	ast.WalkFunc(cond, func(n ast.Node) bool {
+		if _, ok := n.(*ast.ExistsExpr); ok {
+			err = fmt.Errorf("EXISTS can only be combined with other conditions by AND")
+			return false
+		}
+		return true
+	})
+	return cond, err
+}
+
 func (p *Parser) parseValueSetExpr() (ast.Expr, error) {
 	valsetExpr := &ast.ValueSetExpr{
 		LiteralExprs: nil,
@@ -747,6 +900,15 @@ func (p *Parser) parseValueSetExpr() (ast.Expr, error) {
 	// IN ("A", "B") or IN expression
 	tk, _ := p.scanIgnoreWhitespace()
 	if tk == ast.LPAREN {
+		if tk2, _ := p.scanIgnoreWhitespace(); tk2 == ast.SELECT {
+			sq, err := p.parseInSubQuery()
+			if err != nil {
+				return nil, err
+			}
+			valsetExpr.SubQuery = sq
+			return valsetExpr, nil
+		}
+		p.unscan()
 		for {
 			element, err := p.ParseExpr()
 			if err != nil {
@@ -777,6 +939,44 @@ func (p *Parser) parseValueSetExpr() (ast.Expr, error) {
 	}
 }
 
+// parseInSubQuery parses the IN (SELECT field FROM table WHERE condition) subquery after the SELECT keyword. The values
+// of the table are materialized by the planner, so only one field can be selected.
+func (p *Parser) parseInSubQuery() (*ast.SubQuery, error) {
+	if p.clause != "where" {
+		return nil, fmt.Errorf("IN subquery is only supported in the WHERE clause")
+	}
+	fields, err := p.parseFields()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("IN subquery must select exactly one field, but found %d", len(fields))
+	}
+	if _, ok := fields[0].Expr.(*ast.Wildcard); ok {
+		return nil, fmt.Errorf("IN subquery must select exactly one field, but found *")
+	}
+	src, err := p.parseSource()
+	if err != nil {
+		return nil, err
+	}
+	t := src[0].(*ast.Table)
+	if t.Name == "" {
+		return nil, fmt.Errorf("IN subquery requires a lookup table")
+	}
+	p.sourceNames = append(p.sourceNames, t.Name)
+	if t.Alias != "" {
+		p.sourceNames = append(p.sourceNames, t.Alias)
+	}
+	cond, err := p.ParseCondition()
+	if err != nil {
+		return nil, err
+	}
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.RPAREN {
+		return nil, fmt.Errorf("found %q, expected right paren.", lit)
+	}
+	return &ast.SubQuery{Table: t.Name, Alias: t.Alias, Field: fields[0].Expr, Condition: cond}, nil
+}
+
 func (p *Parser) parseBracketExpr() (ast.Expr, error) {
 	tok2, lit2 := p.scanIgnoreWhiteSpaceWithNegativeNum()
 	if tok2 == ast.RBRACKET {
@@ -894,6 +1094,15 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 	if ft == ast.FuncTypeCols && p.clause != "select" {
 		return nil, fmt.Errorf("function %s can only be used inside the select clause", n)
 	}
+	distinct := false
+	if tok, _ := p.scanIgnoreWhitespace(); tok == ast.DISTINCT {
+		if ft != ast.FuncTypeAgg {
+			return nil, fmt.Errorf("DISTINCT can only be used in aggregate functions but found %s", n)
+		}
+		distinct = true
+	} else {
+		p.unscan()
+	}
 	var args []ast.Expr
 	for {
 		if tok, _ := p.scanIgnoreWhitespace(); tok == ast.RPAREN {
@@ -943,10 +1152,10 @@ func (p *Parser) parseCall(n string) (ast.Expr, error) {
 			}
 		}
 		// Add context for some aggregate func
-		if name == "deduplicate" {
+		if name == "deduplicate" || name == "dedup" {
 			args = append([]ast.Expr{&ast.Wildcard{Token: ast.ASTERISK}}, args...)
 		}
-		c := &ast.Call{Name: name, Args: args, FuncId: p.fn, FuncType: ft}
+		c := &ast.Call{Name: name, Args: args, FuncId: p.fn, FuncType: ft, Distinct: distinct}
 		p.fn += 1
 		e := p.parseOver(c)
 		return c, e
@@ -1053,7 +1262,10 @@ func validateWindows(fname string, args []ast.Expr) (ast.WindowType, error) {
 		}
 		return ast.HOPPING_WINDOW, nil
 	case "sessionwindow":
-		if err := validateWindow(fname, 3, args); err != nil {
+		if len(args) != 2 && len(args) != 3 {
+			return ast.SESSION_WINDOW, fmt.Errorf("The arguments for %s should be 2 or 3.\n", fname)
+		}
+		if err := validateWindow(fname, len(args), args); err != nil {
 			return ast.SESSION_WINDOW, err
 		}
 		return ast.SESSION_WINDOW, nil
@@ -1126,8 +1338,14 @@ func (p *Parser) ConvertToWindows(wtype ast.WindowType, args []ast.Expr) (*ast.W
 	} else {
 		return nil, fmt.Errorf("Invalid timeliteral %s", tl.Val)
 	}
-	win.Length = &ast.IntegerLiteral{Val: args[1].(*ast.IntegerLiteral).Val}
 	win.Delay = &ast.IntegerLiteral{Val: 0}
+	// The session window without max duration only has the timeout which is tracked per group
+	if wtype == ast.SESSION_WINDOW && len(args) == 2 {
+		win.Length = &ast.IntegerLiteral{Val: 0}
+		win.Interval = &ast.IntegerLiteral{Val: args[1].(*ast.IntegerLiteral).Val}
+		return win, nil
+	}
+	win.Length = &ast.IntegerLiteral{Val: args[1].(*ast.IntegerLiteral).Val}
 	if len(args) > 2 {
 		if wtype != ast.SLIDING_WINDOW {
 			win.Interval = &ast.IntegerLiteral{Val: args[2].(*ast.IntegerLiteral).Val}
@@ -1551,6 +1769,13 @@ func (p *Parser) parseStreamOptions() (*ast.Options, error) {
 						case ast.KIND:
 							val := strings.ToLower(lit3)
 							opts.KIND = val
+						case ast.VALIDATION_POLICY:
+							switch val := strings.ToLower(lit3); val {
+							case ast.ValidationPolicyError, ast.ValidationPolicyDrop, ast.ValidationPolicyDeadLetter:
+								opts.VALIDATION_POLICY = val
+							default:
+								return nil, fmt.Errorf("found %q, expect error/drop/deadletter value in %s option.", lit3, lit1)
+							}
 						default:
 							f := v.Elem().FieldByName(lit1)
 							if f.IsValid() {
@@ -1582,6 +1807,9 @@ func (p *Parser) parseStreamOptions() (*ast.Options, error) {
 	if opts.KIND == ast.StreamKindLookup && opts.TYPE == "memory" && opts.KEY == "" {
 		return nil, fmt.Errorf("Option \"key\" is required for memory lookup table.")
 	}
+	if opts.VALIDATION_POLICY == ast.ValidationPolicyDeadLetter && opts.DEADLETTER_TOPIC == "" {
+		return nil, fmt.Errorf("Option \"deadletter_topic\" is required for deadletter validation policy.")
+	}
 	return opts, nil
 }
 
@@ -1596,16 +1824,80 @@ func (p *Parser) ParseOver4Window() (ast.Expr, error) {
 	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.WHEN {
 		return nil, fmt.Errorf("Found %q after OVER(, expect WHEN.", lit)
 	}
-	expr, err := p.ParseExpr()
+	var expr ast.Expr
+	c, err := p.parseChangedTrigger()
 	if err != nil {
 		return nil, err
 	}
+	if c != nil {
+		expr = c
+	} else {
+		expr, err = p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+	}
 	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.RPAREN {
 		return nil, fmt.Errorf("Found %q after OVER, expect right parentheses.", lit)
 	}
 	return expr, nil
 }
 
+// parseChangedTrigger parses the CHANGED(field1, field2...) window trigger which triggers only when any of the fields
+// changes. It is converted to the had_changed function which ignores null values.
+func (p *Parser) parseChangedTrigger() (*ast.Call, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != ast.IDENT || !strings.EqualFold(lit, "changed") {
+		p.unscan()
+		return nil, nil
+	}
+	if tok, _ := p.scanIgnoreWhitespace(); tok != ast.LPAREN {
+		p.unscan()
+		p.unscan()
+		return nil, nil
+	}
+	args := []ast.Expr{&ast.BooleanLiteral{Val: true}}
+	for {
+		exp, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := exp.(*ast.FieldRef); !ok {
+			return nil, fmt.Errorf("CHANGED trigger only supports field arguments but got %s", exp)
+		}
+		args = append(args, exp)
+		if tok, lit := p.scanIgnoreWhitespace(); tok != ast.COMMA {
+			if tok != ast.RPAREN {
+				return nil, fmt.Errorf("found %q in CHANGED trigger, expected ).", lit)
+			}
+			break
+		}
+	}
+	c := &ast.Call{Name: "had_changed", Args: args, FuncId: p.fn, FuncType: function.GetFuncType("had_changed")}
+	p.fn += 1
+	p.changedTriggers = append(p.changedTriggers, c)
+	return c, nil
+}
+
+// partitionChangedTriggers partitions the CHANGED triggers by the group by dimensions, so that the previous values are
+// compared in each group.
+func (p *Parser) partitionChangedTriggers(dims ast.Dimensions) {
+	if len(p.changedTriggers) == 0 {
+		return
+	}
+	var exprs []ast.Expr
+	for _, d := range dims {
+		if _, ok := d.Expr.(*ast.Window); !ok {
+			exprs = append(exprs, d.Expr)
+		}
+	}
+	if len(exprs) > 0 {
+		for _, c := range p.changedTriggers {
+			c.Partition = &ast.PartitionExpr{Exprs: exprs}
+		}
+	}
+	p.changedTriggers = nil
+}
+
 // Only support filter on window now
 func (p *Parser) parseFilter() (ast.Expr, error) {
 	if tok, _ := p.scanIgnoreWhitespace(); tok != ast.FILTER {
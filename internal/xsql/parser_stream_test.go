@@ -221,6 +221,49 @@ func TestParser_ParseCreateStream(t *testing.T) {
 			err:  `found "true1", expect TRUE/FALSE value in STRICT_VALIDATION option.`,
 		},
 
+		{
+			s: `CREATE STREAM demo (NAME string)
+				 WITH (DATASOURCE="users", STRICT_VALIDATION="true", VALIDATION_POLICY="DeadLetter", DEADLETTER_TOPIC="demo/rejected");`,
+			stmt: &ast.StreamStmt{
+				Name: ast.StreamName("demo"),
+				StreamFields: []ast.StreamField{
+					{Name: "NAME", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+				},
+				Options: &ast.Options{
+					DATASOURCE:        "users",
+					STRICT_VALIDATION: true,
+					VALIDATION_POLICY: "deadletter",
+					DEADLETTER_TOPIC:  "demo/rejected",
+				},
+			},
+		},
+
+		{
+			s:    `CREATE STREAM demo (NAME string) WITH (DATASOURCE="users", VALIDATION_POLICY="skip");`,
+			stmt: nil,
+			err:  `found "skip", expect error/drop/deadletter value in VALIDATION_POLICY option.`,
+		},
+
+		{
+			s:    `CREATE STREAM demo (NAME string) WITH (DATASOURCE="users", VALIDATION_POLICY="deadletter");`,
+			stmt: nil,
+			err:  `Option "deadletter_topic" is required for deadletter validation policy.`,
+		},
+
+		{
+			s: `CREATE STREAM demo (NAME string) WITH (DATASOURCE="users", DECODE_ERROR_TOPIC="demo/undecodable");`,
+			stmt: &ast.StreamStmt{
+				Name: ast.StreamName("demo"),
+				StreamFields: []ast.StreamField{
+					{Name: "NAME", FieldType: &ast.BasicType{Type: ast.STRINGS}},
+				},
+				Options: &ast.Options{
+					DATASOURCE:         "users",
+					DECODE_ERROR_TOPIC: "demo/undecodable",
+				},
+			},
+		},
+
 		{
 			s: `CREATE STREAM demo (NAME string) WITH (DATASOURCE="users", FORMAT="JSON", KEY="USERID");`,
 			stmt: &ast.StreamStmt{
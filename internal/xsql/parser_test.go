@@ -715,6 +715,31 @@ func TestParser_ParseStatement(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT array_agg(DISTINCT color, true) FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						AName: "",
+						Name:  "array_agg",
+						Expr: &ast.Call{
+							Name:     "array_agg",
+							Args:     []ast.Expr{&ast.FieldRef{Name: "color", StreamName: ast.DefaultStream}, &ast.BooleanLiteral{Val: true}},
+							FuncType: ast.FuncTypeAgg,
+							Distinct: true,
+						},
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+
+		{
+			s:    `SELECT abs(DISTINCT a) FROM tbl`,
+			stmt: nil,
+			err:  "DISTINCT can only be used in aggregate functions but found abs",
+		},
+
 		{
 			s: `SELECT count(* EXCEPT(a, b, c)) FROM tbl`,
 			stmt: &ast.SelectStatement{
@@ -3380,6 +3405,83 @@ func TestParser_ParseWindowsExpr(t *testing.T) {
 				},
 			},
 		},
+		{
+			s: `SELECT f1 FROM tbl GROUP BY SLIDINGWINDOW(ms, 5) OVER (WHEN CHANGED(a, b)), deviceId`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+						Name:  "f1",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{
+						Expr: &ast.Window{
+							WindowType: ast.SLIDING_WINDOW,
+							Length:     &ast.IntegerLiteral{Val: 5},
+							Interval:   &ast.IntegerLiteral{Val: 0},
+							TimeUnit:   &ast.TimeLiteral{Val: ast.MS},
+							TriggerCondition: &ast.Call{
+								Name:     "had_changed",
+								FuncType: ast.FuncTypeScalar,
+								Args: []ast.Expr{
+									&ast.BooleanLiteral{Val: true},
+									&ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+									&ast.FieldRef{Name: "b", StreamName: ast.DefaultStream},
+								},
+								Partition: &ast.PartitionExpr{
+									Exprs: []ast.Expr{
+										&ast.FieldRef{Name: "deviceId", StreamName: ast.DefaultStream},
+									},
+								},
+							},
+							Delay: &ast.IntegerLiteral{Val: 0},
+						},
+					},
+					ast.Dimension{
+						Expr: &ast.FieldRef{Name: "deviceId", StreamName: ast.DefaultStream},
+					},
+				},
+			},
+		},
+		{
+			s: `SELECT f1 FROM tbl GROUP BY SLIDINGWINDOW(ms, 5) OVER (WHEN changed(a))`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+						Name:  "f1",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{
+						Expr: &ast.Window{
+							WindowType: ast.SLIDING_WINDOW,
+							Length:     &ast.IntegerLiteral{Val: 5},
+							Interval:   &ast.IntegerLiteral{Val: 0},
+							TimeUnit:   &ast.TimeLiteral{Val: ast.MS},
+							TriggerCondition: &ast.Call{
+								Name:     "had_changed",
+								FuncType: ast.FuncTypeScalar,
+								Args: []ast.Expr{
+									&ast.BooleanLiteral{Val: true},
+									&ast.FieldRef{Name: "a", StreamName: ast.DefaultStream},
+								},
+							},
+							Delay: &ast.IntegerLiteral{Val: 0},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:   `SELECT f1 FROM tbl GROUP BY SLIDINGWINDOW(ms, 5) OVER (WHEN CHANGED(a + 1))`,
+			err: "CHANGED trigger only supports field arguments but got binaryExpr:{ $$default.a + 1 }",
+		},
 		{
 			s: `SELECT f1 FROM tbl GROUP BY SLIDINGWINDOW(ms, 5)`,
 			stmt: &ast.SelectStatement{
@@ -3479,6 +3581,40 @@ func TestParser_ParseWindowsExpr(t *testing.T) {
 			},
 		},
 
+		{
+			s: `SELECT f1 FROM tbl GROUP BY f1, SESSIONWINDOW(ss, 10)`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+						Name:  "f1",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+				Dimensions: ast.Dimensions{
+					ast.Dimension{
+						Expr: &ast.FieldRef{Name: "f1", StreamName: ast.DefaultStream},
+					},
+					ast.Dimension{
+						Expr: &ast.Window{
+							WindowType: ast.SESSION_WINDOW,
+							Length:     &ast.IntegerLiteral{Val: 0},
+							Interval:   &ast.IntegerLiteral{Val: 10},
+							TimeUnit:   &ast.TimeLiteral{Val: ast.SS},
+							Delay:      &ast.IntegerLiteral{Val: 0},
+						},
+					},
+				},
+			},
+		},
+
+		{
+			s:    `SELECT f1 FROM tbl GROUP BY SESSIONWINDOW(ss, 10, 5, 1)`,
+			stmt: nil,
+			err:  "The arguments for sessionwindow should be 2 or 3.\n",
+		},
+
 		{
 			s: `SELECT f1 FROM tbl GROUP BY SLIDINGWINDOW(ms, 5)`,
 			stmt: &ast.SelectStatement{
@@ -4417,6 +4553,339 @@ func TestParser_ParseJoins(t *testing.T) {
 	}
 }
 
+func TestParser_ParseUnions(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: `SELECT * FROM topic/sensor1 UNION ALL topic/sensor2 union all demo WHERE temperature > 20`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "topic/sensor1"}},
+				Unions:  []ast.Source{&ast.Table{Name: "topic/sensor2"}, &ast.Table{Name: "demo"}},
+				Condition: &ast.BinaryExpr{
+					LHS: &ast.FieldRef{Name: "temperature", StreamName: ast.DefaultStream},
+					OP:  ast.GT,
+					RHS: &ast.IntegerLiteral{Val: 20},
+				},
+			},
+		},
+		{
+			s: `SELECT * FROM topic/union`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "topic/union"}},
+			},
+		},
+		{
+			s:   `SELECT * FROM demo UNION demo2`,
+			err: `found "demo2", expected ALL. Only UNION ALL is supported because the events of streams cannot be deduplicated.`,
+		},
+		{
+			s:   `SELECT * FROM demo UNION ALL demo2 AS t2`,
+			err: "alias t2 is not supported for stream demo2 of UNION ALL.",
+		},
+		{
+			s:   `SELECT * FROM demo UNION ALL`,
+			err: "missing the stream name after UNION ALL.",
+		},
+	}
+
+	fmt.Printf("The test bucket size is %d.\n\n", len(tests))
+	for i, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
+			t.Errorf("%d. %q: error mismatch:\n  exp=%s\n  got=%s\n\n", i, tt.s, tt.err, err)
+		} else if tt.err == "" && !reflect.DeepEqual(tt.stmt, stmt) {
+			t.Errorf("%d. %q\n\nstmt mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.s, tt.stmt, stmt)
+		}
+	}
+}
+
+func TestParser_Pos(t *testing.T) {
+	tests := []struct {
+		s    string
+		err  string
+		line int
+		col  int
+	}{
+		{
+			s:    "SELECT * FROM demo UNION demo2",
+			err:  `found "demo2", expected ALL. Only UNION ALL is supported because the events of streams cannot be deduplicated.`,
+			line: 1,
+			col:  26,
+		},
+		{
+			s:    "SELECT * FROM demo\nWHER a > 1",
+			err:  `found ">", expected EOF.`,
+			line: 2,
+			col:  8,
+		},
+	}
+	for _, tt := range tests {
+		p := NewParser(strings.NewReader(tt.s))
+		_, err := p.Parse()
+		assert.EqualError(t, err, tt.err)
+		line, col := p.Pos()
+		assert.Equal(t, tt.line, line, tt.s)
+		assert.Equal(t, tt.col, col, tt.s)
+	}
+}
+
+func TestParser_ParseExists(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: `SELECT * FROM demo WHERE EXISTS (SELECT * FROM allowlist WHERE allowlist.id = demo.id) AND temp > 20`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+				Joins: []ast.Join{
+					{
+						Name: "allowlist", JoinType: ast.SEMI_JOIN, Expr: &ast.BinaryExpr{
+							LHS: &ast.FieldRef{StreamName: ast.StreamName("allowlist"), Name: "id"},
+							OP:  ast.EQ,
+							RHS: &ast.FieldRef{StreamName: ast.StreamName("demo"), Name: "id"},
+						},
+					},
+				},
+				Condition: &ast.BinaryExpr{
+					LHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "temp"},
+					OP:  ast.GT,
+					RHS: &ast.IntegerLiteral{Val: 20},
+				},
+			},
+		},
+		{
+			s: `SELECT temp FROM demo WHERE NOT EXISTS (SELECT 1 FROM denylist AS d WHERE d.id = id AND d.enabled = true)`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{StreamName: ast.DefaultStream, Name: "temp"},
+						Name:  "temp",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+				Joins: []ast.Join{
+					{
+						Name: "denylist", Alias: "d", JoinType: ast.ANTI_JOIN, Expr: &ast.BinaryExpr{
+							LHS: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{StreamName: ast.StreamName("d"), Name: "id"},
+								OP:  ast.EQ,
+								RHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"},
+							},
+							OP: ast.AND,
+							RHS: &ast.BinaryExpr{
+								LHS: &ast.FieldRef{StreamName: ast.StreamName("d"), Name: "enabled"},
+								OP:  ast.EQ,
+								RHS: &ast.BooleanLiteral{Val: true},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:   `SELECT * FROM demo WHERE EXISTS (SELECT * FROM allowlist WHERE allowlist.id = demo.id) OR temp > 20`,
+			err: "EXISTS can only be combined with other conditions by AND",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE EXISTS (SELECT * FROM allowlist)`,
+			err: "EXISTS subquery requires a WHERE condition to look up the table allowlist",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE NOT temp > 20`,
+			err: "found \"temp\", expected EXISTS.",
+		},
+		{
+			s:   `SELECT EXISTS (SELECT * FROM allowlist WHERE allowlist.id = demo.id) FROM demo`,
+			err: "EXISTS is only supported in the WHERE clause",
+		},
+	}
+
+	for i, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
+			t.Errorf("%d. %q: error mismatch:\n  exp=%s\n  got=%s\n\n", i, tt.s, tt.err, err)
+		} else if tt.err == "" && !reflect.DeepEqual(tt.stmt, stmt) {
+			t.Errorf("%d. %q\n\nstmt mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.s, tt.stmt, stmt)
+		}
+	}
+}
+
+func TestParser_ParseInSubQuery(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: `SELECT * FROM demo WHERE deviceId NOT IN (SELECT id FROM denylist AS d WHERE d.enabled = true) AND temp > 20`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+				Condition: &ast.BinaryExpr{
+					LHS: &ast.BinaryExpr{
+						LHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "deviceId"},
+						OP:  ast.NOTIN,
+						RHS: &ast.ValueSetExpr{
+							SubQuery: &ast.SubQuery{
+								Table: "denylist",
+								Alias: "d",
+								Field: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"},
+								Condition: &ast.BinaryExpr{
+									LHS: &ast.FieldRef{StreamName: ast.StreamName("d"), Name: "enabled"},
+									OP:  ast.EQ,
+									RHS: &ast.BooleanLiteral{Val: true},
+								},
+							},
+						},
+					},
+					OP: ast.AND,
+					RHS: &ast.BinaryExpr{
+						LHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "temp"},
+						OP:  ast.GT,
+						RHS: &ast.IntegerLiteral{Val: 20},
+					},
+				},
+			},
+		},
+		{
+			s: `SELECT temp FROM demo WHERE deviceId IN (SELECT id FROM allowlist)`,
+			stmt: &ast.SelectStatement{
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{StreamName: ast.DefaultStream, Name: "temp"},
+						Name:  "temp",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "demo"}},
+				Condition: &ast.BinaryExpr{
+					LHS: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "deviceId"},
+					OP:  ast.IN,
+					RHS: &ast.ValueSetExpr{
+						SubQuery: &ast.SubQuery{
+							Table: "allowlist",
+							Field: &ast.FieldRef{StreamName: ast.DefaultStream, Name: "id"},
+						},
+					},
+				},
+			},
+		},
+		{
+			s:   `SELECT * FROM demo WHERE deviceId IN (SELECT id, name FROM allowlist)`,
+			err: "IN subquery must select exactly one field, but found 2",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE deviceId IN (SELECT * FROM allowlist)`,
+			err: "IN subquery must select exactly one field, but found *",
+		},
+		{
+			s:   `SELECT deviceId IN (SELECT id FROM allowlist) AS allowed FROM demo`,
+			err: "IN subquery is only supported in the WHERE clause",
+		},
+		{
+			s:   `SELECT * FROM demo WHERE deviceId IN (SELECT id FROM allowlist WHERE enabled = true`,
+			err: "found \"EOF\", expected right paren.",
+		},
+	}
+
+	for i, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
+			t.Errorf("%d. %q: error mismatch:\n  exp=%s\n  got=%s\n\n", i, tt.s, tt.err, err)
+		} else if tt.err == "" && !reflect.DeepEqual(tt.stmt, stmt) {
+			t.Errorf("%d. %q\n\nstmt mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.s, tt.stmt, stmt)
+		}
+	}
+}
+
+func TestParser_ParseDistinct(t *testing.T) {
+	tests := []struct {
+		s    string
+		stmt *ast.SelectStatement
+		err  string
+	}{
+		{
+			s: `SELECT DISTINCT a, b FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Distinct: true,
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.FieldRef{StreamName: ast.DefaultStream, Name: "a"},
+						Name:  "a",
+						AName: "",
+					},
+					{
+						Expr:  &ast.FieldRef{StreamName: ast.DefaultStream, Name: "b"},
+						Name:  "b",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+		{
+			s: `SELECT distinct * FROM tbl`,
+			stmt: &ast.SelectStatement{
+				Distinct: true,
+				Fields: []ast.Field{
+					{
+						Expr:  &ast.Wildcard{Token: ast.ASTERISK},
+						Name:  "*",
+						AName: "",
+					},
+				},
+				Sources: []ast.Source{&ast.Table{Name: "tbl"}},
+			},
+		},
+		{
+			s:   `SELECT DISTINCT FROM tbl`,
+			err: "found \"FROM\", expected expression.",
+		},
+	}
+
+	for i, tt := range tests {
+		stmt, err := NewParser(strings.NewReader(tt.s)).Parse()
+		if !reflect.DeepEqual(tt.err, testx.Errstring(err)) {
+			t.Errorf("%d. %q: error mismatch:\n  exp=%s\n  got=%s\n\n", i, tt.s, tt.err, err)
+		} else if tt.err == "" && !reflect.DeepEqual(tt.stmt, stmt) {
+			t.Errorf("%d. %q\n\nstmt mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", i, tt.s, tt.stmt, stmt)
+		}
+	}
+}
+
 func TestParser_ParseStatements(t *testing.T) {
 	tests := []struct {
 		s     string
@@ -318,6 +318,11 @@ type Tuple struct {
 	Timestamp time.Time
 	Metadata  Metadata // immutable
 	Props     map[string]string
+	// IngestTime is the time when the source ingests the tuple. It is only set when the timestamp is replaced by the
+	// event time of the tuple, otherwise the timestamp is the ingest time.
+	IngestTime time.Time
+	// The original payload, only kept when the rejected tuples are sent to the dead letter topic
+	Rawdata []byte
 
 	AffiliateRow
 	lock      sync.Mutex             // lock for the cachedMap, because it is possible to access by multiple sinks
@@ -467,6 +472,7 @@ func (t *Tuple) Clone() Row {
 	return &Tuple{
 		Emitter:      t.Emitter,
 		Timestamp:    t.Timestamp,
+		IngestTime:   t.IngestTime,
 		Message:      t.Message,
 		Metadata:     t.Metadata,
 		AffiliateRow: t.AffiliateRow.Clone(),
@@ -531,6 +537,11 @@ func (t *Tuple) FuncValue(key string) (interface{}, bool) {
 	switch key {
 	case "event_time":
 		return t.Timestamp.UnixMilli(), true
+	case "ingest_time":
+		if !t.IngestTime.IsZero() {
+			return t.IngestTime.UnixMilli(), true
+		}
+		return t.Timestamp.UnixMilli(), true
 	default:
 		return nil, false
 	}
@@ -28,4 +28,12 @@ func (t *WatermarkTuple) IsWatermark() bool {
 	return true
 }
 
+// EOFTuple is sent out by the source when there is no more data. The value tells why the source ends.
 type EOFTuple int
+
+const (
+	// EOFBounded is sent when the bounded source has read all the data
+	EOFBounded EOFTuple = iota
+	// EOFDrain is sent when the rule is drained before stopping. The operators flush the pending windows when receiving it.
+	EOFDrain
+)
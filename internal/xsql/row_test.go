@@ -20,6 +20,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
 )
@@ -228,3 +229,25 @@ func TestRow(t *testing.T) {
 		}
 	}
 }
+
+func TestTupleTimeFuncValue(t *testing.T) {
+	tuple := &Tuple{Timestamp: time.UnixMilli(1000)}
+	v, ok := tuple.FuncValue("ingest_time")
+	if !ok || v != int64(1000) {
+		t.Errorf("ingest_time mismatch, got %v", v)
+	}
+	// the timestamp is replaced by the event time
+	tuple.IngestTime = tuple.Timestamp
+	tuple.Timestamp = time.UnixMilli(500)
+	v, ok = tuple.FuncValue("ingest_time")
+	if !ok || v != int64(1000) {
+		t.Errorf("ingest_time mismatch, got %v", v)
+	}
+	v, ok = tuple.FuncValue("event_time")
+	if !ok || v != int64(500) {
+		t.Errorf("event_time mismatch, got %v", v)
+	}
+	if c, ok := tuple.Clone().(*Tuple); !ok || !c.IngestTime.Equal(tuple.IngestTime) {
+		t.Errorf("ingest time is not cloned")
+	}
+}
@@ -42,6 +42,19 @@ func GetStreams(stmt *ast.SelectStatement) (result []string) {
 	return
 }
 
+// GetUnionStreams returns the streams merged into the first source by UNION ALL
+func GetUnionStreams(stmt *ast.SelectStatement) (result []string) {
+	if stmt == nil {
+		return nil
+	}
+	for _, source := range stmt.Unions {
+		if s, ok := source.(*ast.Table); ok {
+			result = append(result, s.Name)
+		}
+	}
+	return
+}
+
 func GetStatementFromSql(sql string) (stmt *ast.SelectStatement, err error) {
 	defer func() {
 		if err != nil {
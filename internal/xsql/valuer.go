@@ -32,6 +32,7 @@ var (
 		"window_start": true,
 		"window_end":   true,
 		"event_time":   true,
+		"ingest_time":  true,
 	}
 	// ImplicitStateFuncs is a set of functions that read/update global state implicitly.
 	ImplicitStateFuncs = map[string]bool{
@@ -339,6 +340,9 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 								}
 							}
 						}
+						if expr.Distinct {
+							args = distinctAggArgs(args)
+						}
 					case ast.FuncTypeScalar, ast.FuncTypeSrf:
 						args = make([]interface{}, len(expr.Args))
 						for i, arg := range expr.Args {
@@ -346,6 +350,11 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 							if _, ok := args[i].(error); ok {
 								return args[i]
 							}
+							// short-circuit, the arguments after the first non-null one are not evaluated
+							if expr.Name == "coalesce" && args[i] != nil {
+								args = args[:i+1]
+								break
+							}
 						}
 					case ast.FuncTypeCols:
 						var keys []string
@@ -411,6 +420,12 @@ func (v *ValuerEval) Eval(expr ast.Expr) interface{} {
 					}
 				}
 				val, _ := valuer.Call(expr.Name, expr.FuncId, args)
+				// unify the integer result when other arguments are float
+				if i, ok := val.(int64); ok && expr.Name == "coalesce" {
+					if at, _ := ast.ArgsType(expr.Args); at == ast.FLOAT {
+						return float64(i)
+					}
+				}
 				return val
 			}
 		}
@@ -588,6 +603,15 @@ func (v *ValuerEval) evalBinaryExpr(expr *ast.BinaryExpr) interface{} {
 }
 
 func (v *ValuerEval) evalCase(expr *ast.CaseExpr) interface{} {
+	r := v.evalCaseResult(expr)
+	// unify the integer result when other branches are float
+	if i, ok := r.(int64); ok && expr.ResultType() == ast.FLOAT {
+		return float64(i)
+	}
+	return r
+}
+
+func (v *ValuerEval) evalCaseResult(expr *ast.CaseExpr) interface{} {
 	if expr.Value != nil { // compare value to all when clause
 		ev := v.Eval(expr.Value)
 		for _, w := range expr.WhenClauses {
@@ -629,6 +653,17 @@ func (v *ValuerEval) evalValueSet(expr *ast.ValueSetExpr) interface{} {
 		return valueSet
 	}
 
+	if expr.SubQuery != nil {
+		if expr.SubQuery.Loader == nil {
+			return fmt.Errorf("IN subquery on table %s is not planned", expr.SubQuery.Table)
+		}
+		values, err := expr.SubQuery.Loader.Values()
+		if err != nil {
+			return err
+		}
+		return values
+	}
+
 	value := v.Eval(expr.ArrayExpr)
 	if isSliceOrArray(value) {
 		return value
@@ -1208,3 +1243,38 @@ func isFloat(para interface{}) bool {
 	}
 	return false
 }
+
+// distinctAggArgs removes the rows with duplicate values of the first argument. The values of the other arguments in the
+// same row are removed together so that all the arguments are still aligned.
+func distinctAggArgs(args []interface{}) []interface{} {
+	arg0, ok := args[0].([]interface{})
+	if !ok {
+		return args
+	}
+	keys := make(map[string]struct{}, len(arg0))
+	indexes := make([]int, 0, len(arg0))
+	for i, val := range arg0 {
+		key := fmt.Sprintf("%v", val)
+		if _, ok := keys[key]; !ok {
+			keys[key] = struct{}{}
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == len(arg0) {
+		return args
+	}
+	result := make([]interface{}, len(args))
+	for i, arg := range args {
+		l, ok := arg.([]interface{})
+		if !ok || len(l) != len(arg0) {
+			result[i] = arg
+			continue
+		}
+		nl := make([]interface{}, len(indexes))
+		for j, index := range indexes {
+			nl[j] = l[index]
+		}
+		result[i] = nl
+	}
+	return result
+}
@@ -21,6 +21,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/lf-edge/ekuiper/v2/pkg/ast"
 	"github.com/lf-edge/ekuiper/v2/pkg/cast"
 	"github.com/lf-edge/ekuiper/v2/pkg/timex"
@@ -352,6 +355,110 @@ func TestCase(t *testing.T) {
 	}
 }
 
+func TestCaseTypeUnify(t *testing.T) {
+	tests := []struct {
+		sql string
+		m   Message
+		r   interface{}
+	}{
+		{
+			sql: "select CASE WHEN a > 70 THEN 1 ELSE 0.5 END as t from src",
+			m:   Message{"a": int64(72)},
+			r:   float64(1),
+		},
+		{
+			sql: "select CASE WHEN a > 70 THEN 1 ELSE 0.5 END as t from src",
+			m:   Message{"a": int64(32)},
+			r:   0.5,
+		},
+		{
+			sql: "select CASE a WHEN 72 THEN 1 WHEN 32 THEN 2 END as t from src",
+			m:   Message{"a": int64(32)},
+			r:   int64(2),
+		},
+		{
+			sql: "select CASE WHEN a > 70 THEN 1 ELSE b END as t from src",
+			m:   Message{"a": int64(72), "b": 0.5},
+			r:   int64(1),
+		},
+		{
+			// short-circuit, the later branches are not evaluated
+			sql: "select CASE WHEN a > 70 THEN 1 WHEN b > 1 THEN 2 END as t from src",
+			m:   Message{"a": int64(72), "b": "invalid"},
+			r:   int64(1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			tuple := &Tuple{Emitter: "src", Message: tt.m, Timestamp: timex.GetNow()}
+			ve := &ValuerEval{Valuer: MultiValuer(tuple)}
+			assert.Equal(t, tt.r, ve.Eval(stmt.Fields[0].Expr))
+		})
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		sql string
+		m   Message
+		r   interface{}
+	}{
+		{
+			sql: "select coalesce(a, b, 0) as t from src",
+			m:   Message{"b": int64(2)},
+			r:   int64(2),
+		},
+		{
+			sql: "select coalesce(a, b) as t from src",
+			m:   Message{},
+			r:   nil,
+		},
+		{
+			// short-circuit, the later arguments are not evaluated
+			sql: "select coalesce(a, b + 1) as t from src",
+			m:   Message{"a": int64(1), "b": "invalid"},
+			r:   int64(1),
+		},
+		{
+			sql: "select coalesce(a, b + 1) as t from src",
+			m:   Message{"b": "invalid"},
+			r:   errors.New("invalid operation string(invalid) + int64(1)"),
+		},
+		{
+			sql: "select coalesce(a, 1, 0.5) as t from src",
+			m:   Message{},
+			r:   float64(1),
+		},
+		{
+			sql: "select nullif(a, 0) as t from src",
+			m:   Message{"a": 0.0},
+			r:   nil,
+		},
+		{
+			sql: "select nullif(a, 0) as t from src",
+			m:   Message{"a": int64(3)},
+			r:   int64(3),
+		},
+		{
+			sql: "select coalesce(nullif(a, \"\"), \"unknown\") as t from src",
+			m:   Message{"a": ""},
+			r:   "unknown",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d %s", i, tt.sql), func(t *testing.T) {
+			stmt, err := NewParser(strings.NewReader(tt.sql)).Parse()
+			require.NoError(t, err)
+			fv, _ := NewFunctionValuersForOp(nil)
+			tuple := &Tuple{Emitter: "src", Message: tt.m, Timestamp: timex.GetNow()}
+			ve := &ValuerEval{Valuer: MultiValuer(tuple, fv)}
+			assert.Equal(t, tt.r, ve.Eval(stmt.Fields[0].Expr))
+		})
+	}
+}
+
 func TestArray(t *testing.T) {
 	data := []struct {
 		m Message
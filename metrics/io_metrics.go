@@ -31,9 +31,25 @@ var (
 		Help:      "Historgram Duration of IO",
 		Buckets:   prometheus.ExponentialBuckets(10, 2, 20), // 10us ~ 5s
 	}, []string{LblType, LblIOType, LblRuleIDType, LblOpIDType})
+
+	SourceValidationRejectCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kuiper",
+		Subsystem: "source_validation",
+		Name:      "reject_counter",
+		Help:      "counter of the tuples rejected by the schema validation of source",
+	}, []string{LblType, LblRuleIDType, LblOpIDType})
+
+	SourceDecodeErrorCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kuiper",
+		Subsystem: "source_decode",
+		Name:      "deadletter_counter",
+		Help:      "counter of the payloads which fail to decode and are sent to the decode error topic",
+	}, []string{LblRuleIDType, LblOpIDType})
 )
 
 func init() {
 	prometheus.MustRegister(IOCounter)
 	prometheus.MustRegister(IODurationHist)
+	prometheus.MustRegister(SourceValidationRejectCounter)
+	prometheus.MustRegister(SourceDecodeErrorCounter)
 }
@@ -228,6 +228,8 @@ type Call struct {
 
 	// This is used for window functions.
 	SortFields SortFields
+	// This is used for aggregate functions to only aggregate the distinct values such as array_agg(DISTINCT col).
+	Distinct bool
 }
 
 func (c *Call) expr()    {}
@@ -249,7 +251,11 @@ func (c *Call) String() string {
 	if c.WhenExpr != nil {
 		when += ", when:{ " + c.WhenExpr.String() + " }"
 	}
-	return "Call:{ name:" + c.Name + args + when + " }"
+	distinct := ""
+	if c.Distinct {
+		distinct = ", distinct:true"
+	}
+	return "Call:{ name:" + c.Name + distinct + args + when + " }"
 }
 
 type PartitionExpr struct {
@@ -350,9 +356,123 @@ func (c *CaseExpr) String() string {
 	return "caseExprValue:{ " + v + w + " }"
 }
 
+// ValidateExpr checks the types of the literal branches. The THEN and ELSE results must be of compatible types, and
+// for the simple form the case value and the WHEN values must be comparable.
+func (c *CaseExpr) ValidateExpr() error {
+	if len(c.WhenClauses) == 0 {
+		return errors.New("invalid CASE expression, at least one WHEN clause is required")
+	}
+	if c.Value != nil {
+		vt := staticType(c.Value)
+		for _, w := range c.WhenClauses {
+			ut, ok := unifyType(vt, staticType(w.Expr))
+			if !ok {
+				return fmt.Errorf("invalid CASE expression, WHEN value %s is not comparable with type %s", w.Expr, vt)
+			}
+			vt = ut
+		}
+	}
+	_, err := c.resultType()
+	return err
+}
+
+// ResultType returns the type of the results inferred from the literal branches. If the branches are integer and float,
+// the result type is float. It returns UNKNOWN if the type of any branch cannot be inferred statically.
+func (c *CaseExpr) ResultType() DataType {
+	rt, err := c.resultType()
+	if err != nil {
+		return UNKNOWN
+	}
+	return rt
+}
+
+func (c *CaseExpr) resultType() (DataType, error) {
+	results := make([]Expr, 0, len(c.WhenClauses)+1)
+	for _, w := range c.WhenClauses {
+		results = append(results, w.Result)
+	}
+	if c.ElseClause != nil {
+		results = append(results, c.ElseClause)
+	}
+	rt, err := commonType(results)
+	if err != nil {
+		return UNKNOWN, fmt.Errorf("invalid CASE expression, result %v", err)
+	}
+	return rt, nil
+}
+
+// ArgsType returns the common type of the function arguments inferred from the literals. If the arguments are integer
+// and float, the type is float. The arguments whose type cannot be inferred statically such as the field references are
+// skipped. It returns UNKNOWN if no argument type can be inferred.
+func ArgsType(args []Expr) (DataType, error) {
+	known := make([]Expr, 0, len(args))
+	for _, arg := range args {
+		if staticType(arg) != UNKNOWN {
+			known = append(known, arg)
+		}
+	}
+	return commonType(known)
+}
+
+func commonType(exprs []Expr) (DataType, error) {
+	rt := UNKNOWN
+	known := true
+	for _, e := range exprs {
+		t := staticType(e)
+		if t == UNKNOWN {
+			known = false
+			continue
+		}
+		ut, ok := unifyType(rt, t)
+		if !ok {
+			return UNKNOWN, fmt.Errorf("%s of type %s is not compatible with type %s", e, t, rt)
+		}
+		rt = ut
+	}
+	if !known {
+		return UNKNOWN, nil
+	}
+	return rt, nil
+}
+
+// staticType returns the type of the expression if it can be inferred without data
+func staticType(e Expr) DataType {
+	switch ex := e.(type) {
+	case *IntegerLiteral:
+		return BIGINT
+	case *NumberLiteral:
+		return FLOAT
+	case *StringLiteral:
+		return STRINGS
+	case *BooleanLiteral:
+		return BOOLEAN
+	case *ParenExpr:
+		return staticType(ex.Expr)
+	case *CaseExpr:
+		return ex.ResultType()
+	default:
+		return UNKNOWN
+	}
+}
+
+// unifyType returns the common type of the two types. UNKNOWN is compatible with any type.
+func unifyType(a, b DataType) (DataType, bool) {
+	switch {
+	case a == UNKNOWN:
+		return b, true
+	case b == UNKNOWN, a == b:
+		return a, true
+	case (a == BIGINT && b == FLOAT) || (a == FLOAT && b == BIGINT):
+		return FLOAT, true
+	default:
+		return UNKNOWN, false
+	}
+}
+
 type ValueSetExpr struct {
 	LiteralExprs []Expr // ("A", "B", "C") or (1, 2, 3)
 	ArrayExpr    Expr
+	SubQuery     *SubQuery // (SELECT id FROM table)
 }
 
 func (c *ValueSetExpr) expr() {}
@@ -376,9 +496,39 @@ func (c *ValueSetExpr) String() string {
 		}
 		a += "arrayExpr:{ " + c.ArrayExpr.String() + " }"
 	}
+	if c.SubQuery != nil {
+		if le != "" || a != "" {
+			a += ", "
+		}
+		a += c.SubQuery.String()
+	}
 	return "valueSetExpr:{ " + le + a + " }"
 }
 
+// ValueLoader provides the materialized values of the subquery at runtime
+type ValueLoader interface {
+	Values() ([]any, error)
+}
+
+// SubQuery is the IN (SELECT field FROM table WHERE condition) subquery over a lookup table. The subquery is not
+// correlated, so the field and condition only refer to the table.
+type SubQuery struct {
+	Table     string
+	Alias     string
+	Field     Expr
+	Condition Expr
+	// Loader is set by the planner to load and cache the values of the table
+	Loader ValueLoader
+}
+
+func (s *SubQuery) String() string {
+	cond := ""
+	if s.Condition != nil {
+		cond = ", condition:" + s.Condition.String()
+	}
+	return "subQuery:{ table:" + s.Table + ", field:" + s.Field.String() + cond + " }"
+}
+
 type BetweenExpr struct {
 	Lower  Expr
 	Higher Expr
@@ -401,6 +551,29 @@ func (b *BetweenExpr) String() string {
 	return "betweenExpr:{ " + low + high + " }"
 }
 
+// ExistsExpr is the [NOT] EXISTS subquery over a lookup table. It is only a parse-time node, the parser turns it into
+// a semi or anti lookup join.
+type ExistsExpr struct {
+	Not   bool
+	Table string
+	Alias string
+	Expr  Expr
+}
+
+func (e *ExistsExpr) expr() {}
+func (e *ExistsExpr) node() {}
+func (e *ExistsExpr) String() string {
+	op := "exists"
+	if e.Not {
+		op = "notExists"
+	}
+	cond := ""
+	if e.Expr != nil {
+		cond = e.Expr.String()
+	}
+	return op + ":{ table:" + e.Table + ", expr:" + cond + " }"
+}
+
 type LimitExpr struct {
 	LimitCount *IntegerLiteral
 }
@@ -34,6 +34,13 @@ const (
 	StreamKindScan   = "scan"
 )
 
+// The policies to deal with the tuples which fail the schema validation
+const (
+	ValidationPolicyError      = "error"
+	ValidationPolicyDrop       = "drop"
+	ValidationPolicyDeadLetter = "deadletter"
+)
+
 type StreamType int
 
 type StreamStmt struct {
@@ -240,6 +247,12 @@ type Options struct {
 	KIND string `json:"kind,omitempty"`
 	// for delimited format only
 	DELIMITER string `json:"delimiter,omitempty"`
+	// How to deal with the tuples which fail the schema validation: error, drop or deadletter
+	VALIDATION_POLICY string `json:"validationPolicy,omitempty"`
+	// The memory topic to publish the rejected tuples when the validation policy is deadletter
+	DEADLETTER_TOPIC string `json:"deadletterTopic,omitempty"`
+	// The memory topic to publish the payloads which fail to decode
+	DECODE_ERROR_TOPIC string `json:"decodeErrorTopic,omitempty"`
 
 	RuleID       string                      `json:"-"`
 	Schema       map[string]*JsonStreamField `json:"-"`
@@ -22,8 +22,10 @@ type Statement interface {
 }
 
 type SelectStatement struct {
+	Distinct   bool // SELECT DISTINCT suppresses the duplicate output rows
 	Fields     Fields
 	Sources    Sources
+	Unions     Sources // the streams merged into the first source by UNION ALL
 	Joins      Joins
 	Condition  Expr
 	Limit      Expr
@@ -119,6 +121,10 @@ const (
 	RIGHT_JOIN
 	FULL_JOIN
 	CROSS_JOIN
+	// SEMI_JOIN and ANTI_JOIN are the lookup joins of EXISTS and NOT EXISTS. They only check the existence of the
+	// matched rows and keep the left row as is.
+	SEMI_JOIN
+	ANTI_JOIN
 )
 
 func (j JoinType) String() string {
@@ -133,6 +139,10 @@ func (j JoinType) String() string {
 		return "FULL_JOIN"
 	case CROSS_JOIN:
 		return "CROSS_JOIN"
+	case SEMI_JOIN:
+		return "SEMI_JOIN"
+	case ANTI_JOIN:
+		return "ANTI_JOIN"
 	default:
 		return ""
 	}
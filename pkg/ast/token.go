@@ -118,6 +118,9 @@ const (
 	MI
 	SS
 	MS
+
+	DISTINCT
+	EXISTS
 )
 
 var Tokens = []string{
@@ -186,6 +189,8 @@ var Tokens = []string{
 	END:       "END",
 	OVER:      "OVER",
 	PARTITION: "PARTITION",
+	DISTINCT:  "DISTINCT",
+	EXISTS:    "EXISTS",
 
 	AND:        "AND",
 	OR:         "OR",
@@ -219,19 +224,22 @@ const (
 	TABLES     = "TABLES"
 	WITH       = "WITH"
 
-	DATASOURCE        = "DATASOURCE"
-	KEY               = "KEY"
-	FORMAT            = "FORMAT"
-	CONF_KEY          = "CONF_KEY"
-	TYPE              = "TYPE"
-	STRICT_VALIDATION = "STRICT_VALIDATION"
-	TIMESTAMP         = "TIMESTAMP"
-	TIMESTAMP_FORMAT  = "TIMESTAMP_FORMAT"
-	RETAIN_SIZE       = "RETAIN_SIZE"
-	SHARED            = "SHARED"
-	SCHEMAID          = "SCHEMAID"
-	KIND              = "KIND"
-	DELIMITER         = "DELIMITER"
+	DATASOURCE         = "DATASOURCE"
+	KEY                = "KEY"
+	FORMAT             = "FORMAT"
+	CONF_KEY           = "CONF_KEY"
+	TYPE               = "TYPE"
+	STRICT_VALIDATION  = "STRICT_VALIDATION"
+	TIMESTAMP          = "TIMESTAMP"
+	TIMESTAMP_FORMAT   = "TIMESTAMP_FORMAT"
+	RETAIN_SIZE        = "RETAIN_SIZE"
+	SHARED             = "SHARED"
+	SCHEMAID           = "SCHEMAID"
+	KIND               = "KIND"
+	DELIMITER          = "DELIMITER"
+	VALIDATION_POLICY  = "VALIDATION_POLICY"
+	DEADLETTER_TOPIC   = "DEADLETTER_TOPIC"
+	DECODE_ERROR_TOPIC = "DECODE_ERROR_TOPIC"
 
 	XBIGINT   = "BIGINT"
 	XFLOAT    = "FLOAT"
@@ -244,19 +252,22 @@ const (
 )
 
 var StreamTokens = map[string]struct{}{
-	DATASOURCE:        {},
-	KEY:               {},
-	FORMAT:            {},
-	CONF_KEY:          {},
-	TYPE:              {},
-	STRICT_VALIDATION: {},
-	TIMESTAMP:         {},
-	TIMESTAMP_FORMAT:  {},
-	RETAIN_SIZE:       {},
-	SHARED:            {},
-	SCHEMAID:          {},
-	KIND:              {},
-	DELIMITER:         {},
+	DATASOURCE:         {},
+	KEY:                {},
+	FORMAT:             {},
+	CONF_KEY:           {},
+	TYPE:               {},
+	STRICT_VALIDATION:  {},
+	TIMESTAMP:          {},
+	TIMESTAMP_FORMAT:   {},
+	RETAIN_SIZE:        {},
+	SHARED:             {},
+	SCHEMAID:           {},
+	KIND:               {},
+	DELIMITER:          {},
+	VALIDATION_POLICY:  {},
+	DEADLETTER_TOPIC:   {},
+	DECODE_ERROR_TOPIC: {},
 }
 
 var StreamDataTypes = map[string]DataType{
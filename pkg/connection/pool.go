@@ -371,7 +371,7 @@ func createConnection(connCtx api.StreamContext, meta *Meta) (modules.Connection
 		}
 		connCtx.GetLogger().Debugf("connection failed: %s, %v", meta.ID, err)
 		meta.NotifyStatus(api.ConnectionDisconnected, err.Error())
-		if errorx.IsIOError(err) {
+		if errorx.IsRetryable(err) {
 			return err
 		}
 		return backoff.Permanent(err)
@@ -0,0 +1,113 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorx
+
+import "errors"
+
+// Category is the kind of the connector error which decides how the runtime handles it
+type Category string
+
+const (
+	// CategoryConnection is the failure to reach the external system, such as a refused or broken connection
+	CategoryConnection Category = "connection"
+	// CategorySerialization is the failure to encode or decode the data
+	CategorySerialization Category = "serialization"
+	// CategoryValidation is the data or the configuration rejected by the connector or the external system
+	CategoryValidation Category = "validation"
+	// CategoryTransient is the temporary failure of the external system, such as a timeout or a busy server
+	CategoryTransient Category = "transient"
+)
+
+// ConnectorError is the structured error of sources and sinks. The code is stable to be handled programmatically,
+// and the message is the same as the wrapped error.
+type ConnectorError struct {
+	code      ErrorCode
+	category  Category
+	retryable bool
+	err       error
+}
+
+// NewConnectorError creates the error with the explicit code, category and retryable flag
+func NewConnectorError(code ErrorCode, category Category, retryable bool, err error) *ConnectorError {
+	return &ConnectorError{code: code, category: category, retryable: retryable, err: err}
+}
+
+// NewConnectionError creates a retryable connection error. It shares the IOErr code so that it is still an io error.
+func NewConnectionError(err error) *ConnectorError {
+	return NewConnectorError(IOErr, CategoryConnection, true, err)
+}
+
+// NewSerializationError creates a non-retryable serialization error
+func NewSerializationError(err error) *ConnectorError {
+	return NewConnectorError(CovnerterErr, CategorySerialization, false, err)
+}
+
+// NewValidationError creates a non-retryable validation error
+func NewValidationError(err error) *ConnectorError {
+	return NewConnectorError(ValidationErr, CategoryValidation, false, err)
+}
+
+// NewTransientError creates a retryable transient error
+func NewTransientError(err error) *ConnectorError {
+	return NewConnectorError(TransientErr, CategoryTransient, true, err)
+}
+
+func (e *ConnectorError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ConnectorError) Unwrap() error {
+	return e.err
+}
+
+func (e *ConnectorError) Code() ErrorCode {
+	return e.code
+}
+
+func (e *ConnectorError) Category() Category {
+	return e.category
+}
+
+func (e *ConnectorError) Retryable() bool {
+	return e.retryable
+}
+
+// GetCategory returns the category of the first connector error in the error chain
+func GetCategory(err error) (Category, bool) {
+	var ce *ConnectorError
+	if errors.As(err, &ce) {
+		return ce.category, true
+	}
+	return "", false
+}
+
+// IsRetryable reports whether the failed operation could be retried. The connector error in the error chain decides
+// it by the retryable flag, otherwise only the io error is retryable.
+func IsRetryable(err error) bool {
+	var ce *ConnectorError
+	if errors.As(err, &ce) {
+		return ce.retryable
+	}
+	return IsIOError(err)
+}
+
+// GetCode returns the code of the error. The connector error is found in the whole error chain.
+func GetCode(err error) (ErrorCode, bool) {
+	var ce *ConnectorError
+	if errors.As(err, &ce) {
+		return ce.code, true
+	}
+	return GetErrorCode(err)
+}
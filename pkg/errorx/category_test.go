@@ -0,0 +1,102 @@
+// Copyright 2024 EMQ Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectorError(t *testing.T) {
+	cause := errors.New("dial tcp: connection refused")
+	tests := []struct {
+		name      string
+		err       *ConnectorError
+		code      ErrorCode
+		category  Category
+		retryable bool
+		isIO      bool
+	}{
+		{
+			name:      "connection",
+			err:       NewConnectionError(cause),
+			code:      IOErr,
+			category:  CategoryConnection,
+			retryable: true,
+			isIO:      true,
+		},
+		{
+			name:     "serialization",
+			err:      NewSerializationError(cause),
+			code:     CovnerterErr,
+			category: CategorySerialization,
+		},
+		{
+			name:     "validation",
+			err:      NewValidationError(cause),
+			code:     ValidationErr,
+			category: CategoryValidation,
+		},
+		{
+			name:      "transient",
+			err:       NewTransientError(cause),
+			code:      TransientErr,
+			category:  CategoryTransient,
+			retryable: true,
+		},
+		{
+			name:     "custom",
+			err:      NewConnectorError(2000, CategoryConnection, false, cause),
+			code:     2000,
+			category: CategoryConnection,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, cause.Error(), tt.err.Error())
+			assert.True(t, errors.Is(tt.err, cause))
+			assert.Equal(t, tt.code, tt.err.Code())
+			assert.Equal(t, tt.category, tt.err.Category())
+			assert.Equal(t, tt.retryable, tt.err.Retryable())
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+			assert.Equal(t, tt.isIO, IsIOError(tt.err))
+			// the structured info is found through the wrapped errors
+			wrapped := errors.Join(errors.New("batch failed"), fmt.Errorf("write error: %w", tt.err))
+			c, ok := GetCategory(wrapped)
+			assert.True(t, ok)
+			assert.Equal(t, tt.category, c)
+			code, ok := GetCode(wrapped)
+			assert.True(t, ok)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.retryable, IsRetryable(wrapped))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(NewIOErr("io error")))
+	assert.False(t, IsRetryable(New("general error")))
+	assert.False(t, IsRetryable(errors.New("plain error")))
+	_, ok := GetCategory(NewIOErr("io error"))
+	assert.False(t, ok)
+	code, ok := GetCode(NewIOErr("io error"))
+	assert.True(t, ok)
+	assert.Equal(t, IOErr, code)
+	_, ok = GetCode(errors.New("plain error"))
+	assert.False(t, ok)
+}
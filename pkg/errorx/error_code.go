@@ -25,6 +25,8 @@ const (
 	IOErr         ErrorCode = 1003
 	CovnerterErr  ErrorCode = 1004
 	EOF           ErrorCode = 1005
+	TransientErr  ErrorCode = 1006
+	ValidationErr ErrorCode = 1007
 
 	// error code for sql
 
@@ -27,6 +27,7 @@ const (
 	FormatDelimited  = "delimited"
 	FormatUrlEncoded = "urlencoded"
 	FormatXML        = "xml"
+	FormatCSV        = "csv"
 	FormatCustom     = "custom"
 
 	DefaultField = "self"
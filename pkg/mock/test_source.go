@@ -58,10 +58,16 @@ func TestSourceConnectorCompare(t *testing.T, r api.Source, props map[string]any
 		// do nothing
 	})
 	assert.NoError(t, err)
-	// Send data
+	// Send data. Wait for the sender to finish before return, so that it does not change the shared mock clock or
+	// the files of the following tests
+	senderDone := make(chan struct{})
 	go func() {
+		defer close(senderDone)
 		sender()
 	}()
+	defer func() {
+		<-senderDone
+	}()
 	time.Sleep(10 * time.Millisecond)
 	// Send and receive data
 	limit := 0
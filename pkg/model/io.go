@@ -50,6 +50,13 @@ type SinkInfoNode interface {
 type SinkInfo struct {
 	HasCompress bool
 	HasBatch    bool
+	// HasTransform means the sink selects the dataField, fields and renders the dataTemplate by itself, so that the transform op will skip them
+	HasTransform bool
+}
+
+// TemplateSink provides the extra prop templates which are calculated by the data like the dynamic props
+type TemplateSink interface {
+	Templates(props map[string]any) []string
 }
 
 type UniqueSub interface {
@@ -59,3 +66,29 @@ type UniqueSub interface {
 type UniqueConn interface {
 	ConnId(props map[string]any) string
 }
+
+// OffsetCommitter is a rewindable source feature to commit the offsets to the external system, such as the consumer
+// group offsets of Kafka. When the rule enables checkpoint, the offsets are only committed after the checkpoint which
+// includes them completes, so that the data after the checkpoint is replayed after a crash.
+type OffsetCommitter interface {
+	api.Rewindable
+	// EnableCheckpointCommit is called before connecting if checkpoint is enabled. The source must not commit the
+	// offsets by itself afterward.
+	EnableCheckpointCommit()
+	// CommitOffset commits the offset returned by GetOffset
+	CommitOffset(ctx api.StreamContext, offset any) error
+}
+
+// PartitionedSource is a source which can be read by multiple parallel readers to scale the ingestion. When the
+// concurrency of the stream is set, a new source instance is created for each reader and provisioned by the props of
+// its partition. The partitions must not overlap so that each message is only read once.
+type PartitionedSource interface {
+	// PartitionProps returns the props of the reader of the partition index of total
+	PartitionProps(props map[string]any, index int, total int) (map[string]any, error)
+}
+
+// ScanLookupSource is a lookup source which can read all the rows of the table, so that the planner can materialize
+// the table such as the values of the IN subquery instead of looking it up for each event.
+type ScanLookupSource interface {
+	Scan(ctx api.StreamContext) ([]map[string]any, error)
+}
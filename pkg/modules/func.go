@@ -18,6 +18,58 @@ import (
 	"github.com/lf-edge/ekuiper/contract/v2/api"
 )
 
+// AggregateFunction is the contract of the user defined aggregate function which calculates the result by a state
+// instead of receiving all the values at once. IsAggregate must return true. For each group of a window, the state is
+// created by Init, then each row of the group is added into the state by Accumulate, and the result is calculated by
+// Finalize. Merge combines two partial states of the same group into one.
+type AggregateFunction interface {
+	api.Function
+	// Init returns the initial state of a group
+	Init(ctx api.FunctionContext) (any, error)
+	// Accumulate adds the arguments of a row into the state and returns the new state
+	Accumulate(ctx api.FunctionContext, state any, args []any) (any, error)
+	// Merge combines two states and returns the combined state
+	Merge(ctx api.FunctionContext, state1 any, state2 any) (any, error)
+	// Finalize returns the result of the state
+	Finalize(ctx api.FunctionContext, state any) (any, error)
+}
+
+// ExecAggregate executes the aggregate function for the arguments of a group. Each argument is the slice of the values
+// of all the rows in the group. If an argument is not a slice, it is used for all the rows.
+func ExecAggregate(ctx api.FunctionContext, f AggregateFunction, args []any) (any, bool) {
+	state, err := f.Init(ctx)
+	if err != nil {
+		return err, false
+	}
+	rows := 0
+	for _, arg := range args {
+		if l, ok := arg.([]any); ok && len(l) > rows {
+			rows = len(l)
+		}
+	}
+	for i := 0; i < rows; i++ {
+		rowArgs := make([]any, len(args))
+		for j, arg := range args {
+			if l, ok := arg.([]any); ok {
+				if i < len(l) {
+					rowArgs[j] = l[i]
+				}
+			} else {
+				rowArgs[j] = arg
+			}
+		}
+		state, err = f.Accumulate(ctx, state, rowArgs)
+		if err != nil {
+			return err, false
+		}
+	}
+	r, err := f.Finalize(ctx, state)
+	if err != nil {
+		return err, false
+	}
+	return r, true
+}
+
 type (
 	NewFuncFunc func() api.Function
 )
@@ -25,6 +25,8 @@ type TracerConfig struct {
 	EnableRemoteCollector bool   `json:"enableRemoteCollector"`
 	ServiceName           string `json:"serviceName"`
 	RemoteEndpoint        string `json:"remoteEndpoint"`
+	// SamplingRate is the ratio of the traces to be sampled. Set it lower to limit the overhead of tracing.
+	SamplingRate float64 `json:"samplingRate"`
 }
 
 func TracerConfigFromConf() *TracerConfig {
@@ -32,6 +34,7 @@ func TracerConfigFromConf() *TracerConfig {
 		EnableRemoteCollector: conf.Config.OpenTelemetry.EnableRemoteCollector,
 		ServiceName:           conf.Config.OpenTelemetry.ServiceName,
 		RemoteEndpoint:        conf.Config.OpenTelemetry.RemoteEndpoint,
+		SamplingRate:          conf.Config.OpenTelemetry.SamplingRate,
 	}
 }
 
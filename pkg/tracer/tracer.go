@@ -17,6 +17,7 @@
 package tracer
 
 import (
+	"fmt"
 	"sync"
 
 	"go.opentelemetry.io/otel"
@@ -45,6 +46,7 @@ type GlobalTracerManager struct {
 	ServiceName          string
 	EnableRemoteEndpoint bool
 	RemoteEndpoint       string
+	SamplingRate         float64
 	SpanExporter         *SpanExporter
 }
 
@@ -64,7 +66,7 @@ func (g *GlobalTracerManager) InitIfNot() {
 	g.Init = true
 }
 
-func (g *GlobalTracerManager) SetTracer(enableRemote bool, serviceName, endpoint string) error {
+func (g *GlobalTracerManager) SetTracer(enableRemote bool, serviceName, endpoint string, samplingRate float64) error {
 	var opts []sdktrace.TracerProviderOption
 	opts = append(opts, sdktrace.WithResource(resource.NewWithAttributes(
 		semconv.SchemaURL,
@@ -75,16 +77,18 @@ func (g *GlobalTracerManager) SetTracer(enableRemote bool, serviceName, endpoint
 	g.ServiceName = serviceName
 	g.EnableRemoteEndpoint = enableRemote
 	g.RemoteEndpoint = endpoint
+	g.SamplingRate = samplingRate
 	exporter, err := NewSpanExporter(enableRemote, endpoint)
 	if err != nil {
 		return err
 	}
 	g.SpanExporter = exporter
-	opts = append(opts, sdktrace.WithBatcher(exporter))
+	// The spans continued from the upstream follow the sampling decision of the parent
+	opts = append(opts, sdktrace.WithBatcher(exporter), sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRate))))
 	tp := sdktrace.NewTracerProvider(opts...)
 	otel.SetTracerProvider(tp)
 	g.Init = true
-	conf.Log.Infof("set tracer success, enableRemote:%v, serviceName:%v, endpoint:%v", enableRemote, serviceName, endpoint)
+	conf.Log.Infof("set tracer success, enableRemote:%v, serviceName:%v, endpoint:%v, samplingRate:%v", enableRemote, serviceName, endpoint, samplingRate)
 	return nil
 }
 
@@ -111,10 +115,16 @@ func GetSpanByTraceID(traceID string) (root *LocalSpan, err error) {
 }
 
 func SetTracer(config *TracerConfig) error {
+	if config.SamplingRate == 0 {
+		config.SamplingRate = conf.Config.OpenTelemetry.SamplingRate
+	}
+	if config.SamplingRate <= 0 || config.SamplingRate > 1 {
+		return fmt.Errorf("samplingRate must be in (0, 1], but got %v", config.SamplingRate)
+	}
 	if err := saveTracerConfig(config); err != nil {
 		return err
 	}
-	return globalTracerManager.SetTracer(config.EnableRemoteCollector, config.ServiceName, config.RemoteEndpoint)
+	return globalTracerManager.SetTracer(config.EnableRemoteCollector, config.ServiceName, config.RemoteEndpoint, config.SamplingRate)
 }
 
 func InitTracer() error {
@@ -122,7 +132,7 @@ func InitTracer() error {
 	if err != nil {
 		return err
 	}
-	return globalTracerManager.SetTracer(tracerConfig.EnableRemoteCollector, tracerConfig.ServiceName, tracerConfig.RemoteEndpoint)
+	return globalTracerManager.SetTracer(tracerConfig.EnableRemoteCollector, tracerConfig.ServiceName, tracerConfig.RemoteEndpoint, tracerConfig.SamplingRate)
 }
 
 func saveTracerConfig(config *TracerConfig) error {
@@ -130,6 +140,7 @@ func saveTracerConfig(config *TracerConfig) error {
 		"enableRemoteCollector": config.EnableRemoteCollector,
 		"serviceName":           config.ServiceName,
 		"remoteEndpoint":        config.RemoteEndpoint,
+		"samplingRate":          config.SamplingRate,
 	})
 }
 
@@ -44,3 +44,18 @@ func TestSetTracer(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, globalTracerManager.SpanExporter)
 }
+
+func TestSetTracerSamplingRate(t *testing.T) {
+	conf.IsTesting = true
+	conf.InitConf()
+	defer SetTracer(&TracerConfig{SamplingRate: 1})
+	require.NoError(t, SetTracer(&TracerConfig{}))
+	require.Equal(t, float64(1), globalTracerManager.SamplingRate)
+	require.NoError(t, SetTracer(&TracerConfig{SamplingRate: 0.1}))
+	require.Equal(t, 0.1, globalTracerManager.SamplingRate)
+	newConfig, err := loadTracerConfig()
+	require.NoError(t, err)
+	require.Equal(t, 0.1, newConfig.SamplingRate)
+	require.EqualError(t, SetTracer(&TracerConfig{SamplingRate: 1.5}), "samplingRate must be in (0, 1], but got 1.5")
+	require.EqualError(t, SetTracer(&TracerConfig{SamplingRate: -1}), "samplingRate must be in (0, 1], but got -1")
+}
@@ -184,6 +184,25 @@ func CreateSinkAckChannel(ctx api.StreamContext) (DataOutChannel, error) {
 	return sock, nil
 }
 
+// CreateSourceAckChannel dials the channel to receive the ack of each sent data from eKuiper when requireAck is set
+func CreateSourceAckChannel(ctx api.StreamContext) (DataInChannel, error) {
+	var (
+		sock mangos.Socket
+		err  error
+	)
+	if sock, err = pull.NewSocket(); err != nil {
+		return nil, fmt.Errorf("can't get new pull socket: %s", err)
+	}
+	setSockOptions(sock, map[string]interface{}{
+		mangos.OptionRecvDeadline: 5000 * time.Millisecond,
+	})
+	url := fmt.Sprintf("ipc:///tmp/%s_%s_%d_ack.ipc", ctx.GetRuleId(), ctx.GetOpId(), ctx.GetInstanceId())
+	if err = sock.DialOptions(url, dialOptions); err != nil {
+		return nil, fmt.Errorf("can't dial on pull socket: %s", err.Error())
+	}
+	return sock, nil
+}
+
 func setSockOptions(sock mangos.Socket, sockOptions map[string]interface{}) {
 	for k, v := range sockOptions {
 		err := sock.SetOption(k, v)
@@ -28,6 +28,7 @@ import (
 type sourceRuntime struct {
 	s      api.Source
 	ch     connection.DataOutChannel
+	ackCh  connection.DataInChannel // only set when requireAck is true
 	ctx    api.StreamContext
 	cancel context2.CancelFunc
 	key    string
@@ -50,11 +51,20 @@ func setupSourceRuntime(con *Control, s api.Source) (*sourceRuntime, error) {
 	if err != nil {
 		return nil, err
 	}
+	var ackCh connection.DataInChannel
+	if ra, ok := con.Config["requireAck"].(bool); ok && ra {
+		ackCh, err = connection.CreateSourceAckChannel(ctx)
+		if err != nil {
+			_ = ch.Close()
+			return nil, err
+		}
+	}
 	ctx.GetLogger().Info("Setup message pipeline, start sending")
 	ctx, cancel := ctx.WithCancel()
 	return &sourceRuntime{
 		s:      s,
 		ch:     ch,
+		ackCh:  ackCh,
 		ctx:    ctx,
 		cancel: cancel,
 		key:    fmt.Sprintf("%s_%s_%d_%s", con.Meta.RuleId, con.Meta.OpId, con.Meta.InstanceId, con.SymbolName),
@@ -74,6 +84,7 @@ func (s *sourceRuntime) run() {
 		case data := <-consumer:
 			s.ctx.GetLogger().Debugf("broadcast data %v", data)
 			broadcast(s.ctx, s.ch, data)
+			s.waitAck()
 		case <-s.ctx.Done():
 			s.s.Close(s.ctx)
 			return
@@ -81,12 +92,25 @@ func (s *sourceRuntime) run() {
 	}
 }
 
+// waitAck blocks until eKuiper acknowledges the data so that the source won't send faster than the rule consumes
+func (s *sourceRuntime) waitAck() {
+	if s.ackCh == nil {
+		return
+	}
+	if _, err := s.ackCh.Recv(); err != nil {
+		s.ctx.GetLogger().Warnf("wait for source ack error: %v", err)
+	}
+}
+
 func (s *sourceRuntime) stop() error {
 	s.cancel()
 	err := s.ch.Close()
 	if err != nil {
 		s.ctx.GetLogger().Info(err)
 	}
+	if s.ackCh != nil {
+		_ = s.ackCh.Close()
+	}
 	s.ctx.GetLogger().Info("closed source data channel")
 	reg.Delete(s.key)
 	return nil
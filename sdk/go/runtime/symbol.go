@@ -40,7 +40,7 @@ func broadcast(ctx api.StreamContext, sock connection.DataOutChannel, data inter
 	)
 	switch dt := data.(type) {
 	case error:
-		result, err = json.Marshal(fmt.Sprintf("{\"error\":\"%v\"}", dt))
+		result, err = json.Marshal(map[string]string{"error": dt.Error()})
 		if err != nil {
 			ctx.GetLogger().Errorf("%v", err)
 			return
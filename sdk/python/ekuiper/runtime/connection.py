@@ -100,6 +100,21 @@ class SinkAckChannel:
         self.sock.close()
 
 
+class SourceAckChannel:
+
+    def __init__(self, meta: dict):
+        s = Pull0(recv_timeout=5000)
+        url = "ipc:///tmp/{}_{}_{}_ack.ipc".format(meta['ruleId'], meta['opId'], meta['instanceId'])
+        logging.info(url)
+        dial_with_retry(s, url)
+        self.sock = s
+
+    def recv(self) -> bytes:
+        return self.sock.recv()
+
+    def close(self):
+        self.sock.close()
+
 def listen_with_retry(sock, url: str):
     sock.recv_max_size = 0
     retry_count = 10
@@ -16,7 +16,9 @@ import json
 import logging
 import sys
 
-from .connection import SourceChannel, SinkAckChannel
+from pynng import Timeout
+
+from .connection import SourceChannel, SinkAckChannel, SourceAckChannel
 from .context import Context
 
 
@@ -27,6 +29,7 @@ class ContextImpl(Context):
         self.opId = meta['opId']
         self.instanceId = meta['instanceId']
         self.emitter = None
+        self.ack_receiver = None
 
     def set_emitter(self, emitter: SourceChannel):
         self.emitter = emitter
@@ -34,6 +37,9 @@ class ContextImpl(Context):
     def set_ack_emitter(self, emitter: SinkAckChannel):
         self.ack_emitter = emitter
 
+    def set_ack_receiver(self, receiver: SourceAckChannel):
+        self.ack_receiver = receiver
+
     def get_rule_id(self) -> str:
         return self.ruleId
 
@@ -49,12 +55,23 @@ class ContextImpl(Context):
     def emit(self, message: dict, meta: dict):
         data = {'message': message, 'meta': meta}
         json_str = json.dumps(data)
-        return self.emitter.send(str.encode(json_str))
+        self.emitter.send(str.encode(json_str))
+        self.wait_ack()
 
     def emit_error(self, error: str):
         data = {'error': error}
         json_str = json.dumps(data)
-        return self.emitter.send(str.encode(json_str))
+        self.emitter.send(str.encode(json_str))
+        self.wait_ack()
+
+    def wait_ack(self):
+        """block until eKuiper ingests the data if requireAck is set"""
+        if self.ack_receiver is None:
+            return
+        try:
+            self.ack_receiver.recv()
+        except Timeout:
+            logging.warning("wait for source ack timeout")
 
     def ack_ok(self):
         data = b'{}'
@@ -16,7 +16,7 @@ import logging
 import traceback
 
 from . import reg
-from .connection import SourceChannel
+from .connection import SourceChannel, SourceAckChannel
 from .symbol import parse_context, SymbolRuntime
 from ..source import Source
 
@@ -34,11 +34,16 @@ class SourceRuntime(SymbolRuntime):
         s.configure(ds, config)
         ch = SourceChannel(ctrl['meta'])
         ctx.set_emitter(ch)
+        ack_ch = None
+        if config.get('requireAck', False):
+            ack_ch = SourceAckChannel(ctrl['meta'])
+            ctx.set_ack_receiver(ack_ch)
         key = f"{ctrl['meta']['ruleId']}_{ctrl['meta']['opId']}" \
               f"_{ctrl['meta']['instanceId']}_{ctrl['symbolName']}"
         self.s = s
         self.ctx = ctx
         self.ch = ch
+        self.ack_ch = ack_ch
         self.running = False
         self.key = key
 
@@ -64,6 +69,8 @@ class SourceRuntime(SymbolRuntime):
         try:
             self.s.close(self.ctx)
             self.ch.close()
+            if self.ack_ch is not None:
+                self.ack_ch.close()
             reg.delete(self.key)
         except Exception:
             logging.error(traceback.format_exc())